@@ -0,0 +1,96 @@
+package shell
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/mcclient/modules"
+	"yunion.io/x/onecloud/pkg/mcclient/options"
+)
+
+func init() {
+	R(&options.BucketListOptions{}, "bucket-list", "List buckets", func(s *mcclient.ClientSession, opts *options.BucketListOptions) error {
+		params, err := options.ListStructToParams(opts)
+		if err != nil {
+			return err
+		}
+		result, err := modules.Buckets.List(s, params)
+		if err != nil {
+			return err
+		}
+		printList(result, modules.Buckets.GetColumns(s))
+		return nil
+	})
+	R(&options.BucketIdOptions{}, "bucket-show", "Show bucket", func(s *mcclient.ClientSession, opts *options.BucketIdOptions) error {
+		bucket, err := modules.Buckets.Get(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(bucket)
+		return nil
+	})
+	R(&options.BucketCreateOptions{}, "bucket-create", "Create bucket", func(s *mcclient.ClientSession, opts *options.BucketCreateOptions) error {
+		params := jsonutils.Marshal(opts)
+		bucket, err := modules.Buckets.Create(s, params)
+		if err != nil {
+			return err
+		}
+		printObject(bucket)
+		return nil
+	})
+	R(&options.BucketIdOptions{}, "bucket-delete", "Delete bucket", func(s *mcclient.ClientSession, opts *options.BucketIdOptions) error {
+		bucket, err := modules.Buckets.Delete(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(bucket)
+		return nil
+	})
+	R(&options.BucketIdOptions{}, "bucket-purge", "Purge bucket", func(s *mcclient.ClientSession, opts *options.BucketIdOptions) error {
+		bucket, err := modules.Buckets.PerformAction(s, opts.ID, "purge", nil)
+		if err != nil {
+			return err
+		}
+		printObject(bucket)
+		return nil
+	})
+	R(&options.BucketAclOptions{}, "bucket-set-acl", "Set canned acl of bucket", func(s *mcclient.ClientSession, opts *options.BucketAclOptions) error {
+		params := jsonutils.NewDict()
+		params.Set("acl", jsonutils.NewString(opts.ACL))
+		bucket, err := modules.Buckets.PerformAction(s, opts.ID, "acl", params)
+		if err != nil {
+			return err
+		}
+		printObject(bucket)
+		return nil
+	})
+	R(&options.BucketListObjectsOptions{}, "bucket-object-list", "List objects in bucket", func(s *mcclient.ClientSession, opts *options.BucketListObjectsOptions) error {
+		params, err := options.StructToParams(opts)
+		if err != nil {
+			return err
+		}
+		result, err := modules.Buckets.GetSpecific(s, opts.ID, "objects", params)
+		if err != nil {
+			return err
+		}
+		objects, err := result.GetArray("objects")
+		if err != nil {
+			return err
+		}
+		printList(&modules.ListResult{Data: objects, Total: len(objects)}, nil)
+		if prefixes, _ := result.GetArray("common_prefixes"); len(prefixes) > 0 {
+			fmt.Println("Common prefixes:")
+			for _, prefix := range prefixes {
+				p, _ := prefix.GetString()
+				fmt.Println("  ", p)
+			}
+		}
+		if truncated, _ := result.Bool("is_truncated"); truncated {
+			marker, _ := result.GetString("next_marker")
+			fmt.Println("Next marker:", marker)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,82 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/mcclient/modules"
+	"yunion.io/x/onecloud/pkg/mcclient/options"
+)
+
+func init() {
+	R(&options.DBInstanceListOptions{}, "dbinstance-list", "List dbinstances", func(s *mcclient.ClientSession, opts *options.DBInstanceListOptions) error {
+		params, err := options.ListStructToParams(opts)
+		if err != nil {
+			return err
+		}
+		result, err := modules.DBInstances.List(s, params)
+		if err != nil {
+			return err
+		}
+		printList(result, modules.DBInstances.GetColumns(s))
+		return nil
+	})
+	R(&options.DBInstanceIdOptions{}, "dbinstance-show", "Show dbinstance", func(s *mcclient.ClientSession, opts *options.DBInstanceIdOptions) error {
+		instance, err := modules.DBInstances.Get(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(instance)
+		return nil
+	})
+	R(&options.DBInstanceIdOptions{}, "dbinstance-delete", "Delete dbinstance", func(s *mcclient.ClientSession, opts *options.DBInstanceIdOptions) error {
+		instance, err := modules.DBInstances.Delete(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(instance)
+		return nil
+	})
+	R(&options.DBInstanceIdOptions{}, "dbinstance-purge", "Purge dbinstance", func(s *mcclient.ClientSession, opts *options.DBInstanceIdOptions) error {
+		instance, err := modules.DBInstances.PerformAction(s, opts.ID, "purge", nil)
+		if err != nil {
+			return err
+		}
+		printObject(instance)
+		return nil
+	})
+	R(&options.DBInstanceIdOptions{}, "dbinstance-start", "Start dbinstance", func(s *mcclient.ClientSession, opts *options.DBInstanceIdOptions) error {
+		instance, err := modules.DBInstances.PerformAction(s, opts.ID, "start", nil)
+		if err != nil {
+			return err
+		}
+		printObject(instance)
+		return nil
+	})
+	R(&options.DBInstanceIdOptions{}, "dbinstance-stop", "Stop dbinstance", func(s *mcclient.ClientSession, opts *options.DBInstanceIdOptions) error {
+		instance, err := modules.DBInstances.PerformAction(s, opts.ID, "stop", nil)
+		if err != nil {
+			return err
+		}
+		printObject(instance)
+		return nil
+	})
+	R(&options.DBInstanceIdOptions{}, "dbinstance-reboot", "Reboot dbinstance", func(s *mcclient.ClientSession, opts *options.DBInstanceIdOptions) error {
+		instance, err := modules.DBInstances.PerformAction(s, opts.ID, "reboot", nil)
+		if err != nil {
+			return err
+		}
+		printObject(instance)
+		return nil
+	})
+	R(&options.DBInstanceIdOptions{}, "dbinstance-backup-list", "List backups of dbinstance", func(s *mcclient.ClientSession, opts *options.DBInstanceIdOptions) error {
+		result, err := modules.DBInstances.GetSpecific(s, opts.ID, "backups", nil)
+		if err != nil {
+			return err
+		}
+		backups, err := result.GetArray("backups")
+		if err != nil {
+			return err
+		}
+		printList(&modules.ListResult{Data: backups, Total: len(backups)}, nil)
+		return nil
+	})
+}
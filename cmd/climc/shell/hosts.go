@@ -23,7 +23,7 @@ func init() {
 		Occupied  bool   `help:"show occupid host" json:"-"`
 		Enabled   bool   `help:"Show enabled host only" json:"-"`
 		Disabled  bool   `help:"Show disabled host only" json:"-"`
-		HostType  string `help:"Host type filter" choices:"baremetal|hypervisor|esxi|kubelet|hyperv|aliyun|azure|qcloud|aws|huawei"`
+		HostType  string `help:"Host type filter" choices:"baremetal|hypervisor|esxi|kubelet|hyperv|aliyun|azure|qcloud|aws|huawei|google"`
 		AnyMac    string `help:"Mac matches one of the host's interface"`
 
 		IsBaremetal *bool `help:"filter host list by is_baremetal=true|false"`
@@ -280,6 +280,36 @@ func init() {
 		return nil
 	})
 
+	R(&HostDetailOptions{}, "host-drain", "Put a kvm host in maintenance and migrate all its guests away", func(s *mcclient.ClientSession, args *HostDetailOptions) error {
+		_, err := modules.Hosts.PerformAction(s, args.ID, "drain", nil)
+		if err != nil {
+			return err
+		}
+		return printHostDrain(s, args.ID)
+	})
+
+	R(&HostDetailOptions{}, "host-drain-status", "Show the migration progress of the guests of a draining host", func(s *mcclient.ClientSession, args *HostDetailOptions) error {
+		return printHostDrain(s, args.ID)
+	})
+
+	R(&HostDetailOptions{}, "host-undrain", "Release a drained host from maintenance", func(s *mcclient.ClientSession, args *HostDetailOptions) error {
+		result, err := modules.Hosts.PerformAction(s, args.ID, "undrain", nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&HostDetailOptions{}, "host-ha-restart", "Fence an offline host by IPMI and restart its servers of ha policy restart on other hosts", func(s *mcclient.ClientSession, args *HostDetailOptions) error {
+		result, err := modules.Hosts.PerformAction(s, args.ID, "ha-restart", nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
 	R(&HostDetailOptions{}, "host-start", "Power on host", func(s *mcclient.ClientSession, args *HostDetailOptions) error {
 		result, err := modules.Hosts.PerformAction(s, args.ID, "start", nil)
 		if err != nil {
@@ -522,3 +552,15 @@ func init() {
 		return nil
 	})
 }
+
+func printHostDrain(s *mcclient.ClientSession, id string) error {
+	result, err := modules.Hosts.GetSpecific(s, id, "drain", nil)
+	if err != nil {
+		return err
+	}
+	status, _ := result.GetString("status")
+	fmt.Println("Drain status:", status)
+	guests, _ := result.GetArray("guests")
+	printList(&modules.ListResult{Data: guests, Total: len(guests)}, []string{"guest_id", "name", "action", "status", "reason"})
+	return nil
+}
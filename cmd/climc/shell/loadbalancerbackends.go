@@ -49,14 +49,18 @@ func init() {
 		return nil
 	})
 	R(&options.LoadbalancerBackendDeleteOptions{}, "lbbackend-delete", "Delete lbbackend", func(s *mcclient.ClientSession, opts *options.LoadbalancerBackendDeleteOptions) error {
-		lbbackend, err := modules.LoadbalancerBackends.Delete(s, opts.ID, nil)
+		params, err := options.StructToParams(opts)
+		if err != nil {
+			return err
+		}
+		lbbackend, err := modules.LoadbalancerBackends.DeleteWithParam(s, opts.ID, params, nil)
 		if err != nil {
 			return err
 		}
 		printObject(lbbackend)
 		return nil
 	})
-	R(&options.LoadbalancerBackendDeleteOptions{}, "lbbackend-purge", "Purge lbbackend", func(s *mcclient.ClientSession, opts *options.LoadbalancerBackendDeleteOptions) error {
+	R(&options.LoadbalancerBackendPurgeOptions{}, "lbbackend-purge", "Purge lbbackend", func(s *mcclient.ClientSession, opts *options.LoadbalancerBackendPurgeOptions) error {
 		lbbackend, err := modules.LoadbalancerBackends.PerformAction(s, opts.ID, "purge", nil)
 		if err != nil {
 			return err
@@ -64,4 +68,20 @@ func init() {
 		printObject(lbbackend)
 		return nil
 	})
+	R(&options.LoadbalancerBackendDrainOptions{}, "lbbackend-drain", "Stop new connections to lbbackend", func(s *mcclient.ClientSession, opts *options.LoadbalancerBackendDrainOptions) error {
+		lbbackend, err := modules.LoadbalancerBackends.PerformAction(s, opts.ID, "drain", nil)
+		if err != nil {
+			return err
+		}
+		printObject(lbbackend)
+		return nil
+	})
+	R(&options.LoadbalancerBackendDrainOptions{}, "lbbackend-undrain", "Resume new connections to lbbackend", func(s *mcclient.ClientSession, opts *options.LoadbalancerBackendDrainOptions) error {
+		lbbackend, err := modules.LoadbalancerBackends.PerformAction(s, opts.ID, "undrain", nil)
+		if err != nil {
+			return err
+		}
+		printObject(lbbackend)
+		return nil
+	})
 }
@@ -85,4 +85,16 @@ func init() {
 		printObject(lblistener)
 		return nil
 	})
+	R(&options.LoadbalancerListenerAccessStatsOptions{}, "lblistener-access-stats", "Show lblistener access stats collected by lbagent", func(s *mcclient.ClientSession, opts *options.LoadbalancerListenerAccessStatsOptions) error {
+		params, err := options.StructToParams(opts)
+		if err != nil {
+			return err
+		}
+		stats, err := modules.LoadbalancerListeners.GetSpecific(s, opts.ID, "access-stats", params)
+		if err != nil {
+			return err
+		}
+		printObject(stats)
+		return nil
+	})
 }
@@ -0,0 +1,133 @@
+package shell
+
+import (
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/mcclient/modules"
+	"yunion.io/x/onecloud/pkg/mcclient/options"
+)
+
+func init() {
+	R(&options.NatGatewayListOptions{}, "natgateway-list", "List nat gateways", func(s *mcclient.ClientSession, opts *options.NatGatewayListOptions) error {
+		params, err := options.ListStructToParams(opts)
+		if err != nil {
+			return err
+		}
+		result, err := modules.NatGateways.List(s, params)
+		if err != nil {
+			return err
+		}
+		printList(result, modules.NatGateways.GetColumns(s))
+		return nil
+	})
+	R(&options.NatGatewayCreateOptions{}, "natgateway-create", "Create nat gateway", func(s *mcclient.ClientSession, opts *options.NatGatewayCreateOptions) error {
+		params := jsonutils.Marshal(opts)
+		natgateway, err := modules.NatGateways.Create(s, params)
+		if err != nil {
+			return err
+		}
+		printObject(natgateway)
+		return nil
+	})
+	R(&options.NatGatewayIdOptions{}, "natgateway-show", "Show nat gateway", func(s *mcclient.ClientSession, opts *options.NatGatewayIdOptions) error {
+		natgateway, err := modules.NatGateways.Get(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(natgateway)
+		return nil
+	})
+	R(&options.NatGatewayIdOptions{}, "natgateway-delete", "Delete nat gateway", func(s *mcclient.ClientSession, opts *options.NatGatewayIdOptions) error {
+		natgateway, err := modules.NatGateways.Delete(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(natgateway)
+		return nil
+	})
+	R(&options.NatGatewayIdOptions{}, "natgateway-purge", "Purge nat gateway", func(s *mcclient.ClientSession, opts *options.NatGatewayIdOptions) error {
+		natgateway, err := modules.NatGateways.PerformAction(s, opts.ID, "purge", nil)
+		if err != nil {
+			return err
+		}
+		printObject(natgateway)
+		return nil
+	})
+
+	R(&options.NatSEntryCreateOptions{}, "nat-sentry-create", "Create snat entry", func(s *mcclient.ClientSession, opts *options.NatSEntryCreateOptions) error {
+		params := jsonutils.Marshal(opts)
+		entry, err := modules.NatSEntries.Create(s, params)
+		if err != nil {
+			return err
+		}
+		printObject(entry)
+		return nil
+	})
+	R(&options.NatSEntryListOptions{}, "nat-sentry-list", "List snat entries", func(s *mcclient.ClientSession, opts *options.NatSEntryListOptions) error {
+		params, err := options.ListStructToParams(opts)
+		if err != nil {
+			return err
+		}
+		result, err := modules.NatSEntries.List(s, params)
+		if err != nil {
+			return err
+		}
+		printList(result, modules.NatSEntries.GetColumns(s))
+		return nil
+	})
+	R(&options.NatEntryIdOptions{}, "nat-sentry-show", "Show snat entry", func(s *mcclient.ClientSession, opts *options.NatEntryIdOptions) error {
+		entry, err := modules.NatSEntries.Get(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(entry)
+		return nil
+	})
+	R(&options.NatEntryIdOptions{}, "nat-sentry-delete", "Delete snat entry", func(s *mcclient.ClientSession, opts *options.NatEntryIdOptions) error {
+		entry, err := modules.NatSEntries.Delete(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(entry)
+		return nil
+	})
+
+	R(&options.NatDEntryCreateOptions{}, "nat-dentry-create", "Create dnat entry", func(s *mcclient.ClientSession, opts *options.NatDEntryCreateOptions) error {
+		params := jsonutils.Marshal(opts)
+		entry, err := modules.NatDEntries.Create(s, params)
+		if err != nil {
+			return err
+		}
+		printObject(entry)
+		return nil
+	})
+	R(&options.NatDEntryListOptions{}, "nat-dentry-list", "List dnat entries", func(s *mcclient.ClientSession, opts *options.NatDEntryListOptions) error {
+		params, err := options.ListStructToParams(opts)
+		if err != nil {
+			return err
+		}
+		result, err := modules.NatDEntries.List(s, params)
+		if err != nil {
+			return err
+		}
+		printList(result, modules.NatDEntries.GetColumns(s))
+		return nil
+	})
+	R(&options.NatEntryIdOptions{}, "nat-dentry-show", "Show dnat entry", func(s *mcclient.ClientSession, opts *options.NatEntryIdOptions) error {
+		entry, err := modules.NatDEntries.Get(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(entry)
+		return nil
+	})
+	R(&options.NatEntryIdOptions{}, "nat-dentry-delete", "Delete dnat entry", func(s *mcclient.ClientSession, opts *options.NatEntryIdOptions) error {
+		entry, err := modules.NatDEntries.Delete(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(entry)
+		return nil
+	})
+}
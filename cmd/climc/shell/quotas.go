@@ -19,11 +19,22 @@ type QuotaBaseOptions struct {
 	IsolatedDevice int64 `help:"Isolated device count" json:"isolated_device,omitzero"`
 	Snapshot       int64 `help:"Snapshot count" json:"snapshot,omitzero"`
 	Image          int64 `help:"Template count" json:"image,omitzero"`
+	Loadbalancer   int64 `help:"Loadbalancer count" json:"loadbalancer,omitzero"`
+	Listener       int64 `help:"Loadbalancer listener count" json:"listener,omitzero"`
+	Network        int64 `help:"Network count" json:"network,omitzero"`
+	Vpc            int64 `help:"Vpc count" json:"vpc,omitzero"`
+}
+
+type QuotaScopeOptions struct {
+	Region string `help:"Region ID of the quota scope" json:"region_id,omitempty"`
+	Zone   string `help:"Zone ID of the quota scope" json:"zone_id,omitempty"`
 }
 
 func init() {
 	type QuotaOptions struct {
 		Tenant string `help:"Tenant name of ID"`
+		QuotaScopeOptions
+		Scopes bool `help:"Show the quotas of all regions and zones as well" json:"scopes,omitzero"`
 	}
 	R(&QuotaOptions{}, "quota", "Show quota for current user or tenant", func(s *mcclient.ClientSession, args *QuotaOptions) error {
 		params := jsonutils.Marshal(args)
@@ -47,6 +58,8 @@ func init() {
 	type QuotaSetOptions struct {
 		Tenant string `help:"Tenant name or ID to set quota" json:"tenant,omitempty"`
 		QuotaBaseOptions
+		QuotaScopeOptions
+		Reset bool `help:"Clear the other limits of the region or zone" json:"reset,omitzero"`
 	}
 	R(&QuotaSetOptions{}, "quota-set", "Set quota for tenant", func(s *mcclient.ClientSession, args *QuotaSetOptions) error {
 		params := jsonutils.Marshal(args)
@@ -61,6 +74,7 @@ func init() {
 	type QuotaCheckOptions struct {
 		TENANT string `help:"Tenant name or ID to check quota" json:"tenant,omitempty"`
 		QuotaBaseOptions
+		QuotaScopeOptions
 	}
 	R(&QuotaCheckOptions{}, "quota-check", "Check quota for tenant", func(s *mcclient.ClientSession, args *QuotaCheckOptions) error {
 		params := jsonutils.Marshal(args)
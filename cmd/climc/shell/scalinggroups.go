@@ -0,0 +1,211 @@
+package shell
+
+import (
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/mcclient/modules"
+	"yunion.io/x/onecloud/pkg/mcclient/options"
+)
+
+func init() {
+	type ServerGroupListOptions struct {
+		options.BaseListOptions
+	}
+	R(&ServerGroupListOptions{}, "server-group-list", "List server groups", func(s *mcclient.ClientSession, args *ServerGroupListOptions) error {
+		params, err := options.ListStructToParams(args)
+		if err != nil {
+			return err
+		}
+		result, err := modules.ServerGroups.List(s, params)
+		if err != nil {
+			return err
+		}
+		printList(result, modules.ServerGroups.GetColumns(s))
+		return nil
+	})
+
+	type ServerGroupScalingOptions struct {
+		Servertemplate           string `help:"Server template to launch the servers of the group"`
+		MinSize                  *int   `help:"Minimal number of servers of the group"`
+		MaxSize                  *int   `help:"Maximal number of servers of the group"`
+		DesiredSize              *int   `help:"Desired number of servers of the group"`
+		CooldownSeconds          *int   `help:"Seconds to wait after a scaling activity before the rules are evaluated again"`
+		LoadbalancerBackendGroup string `help:"Loadbalancer backend group the running servers are added to"`
+		BackendPort              int    `help:"Port of the loadbalancer backends" json:"backend_port,omitzero"`
+		BackendWeight            int    `help:"Weight of the loadbalancer backends" json:"backend_weight,omitzero"`
+	}
+
+	type ServerGroupCreateOptions struct {
+		NAME string `help:"Name of the server group"`
+		ZONE string `help:"Zone of the server group"`
+		ServerGroupScalingOptions
+
+		ScalingEnabled bool   `help:"Enable auto scaling" json:"scaling_enabled,omitfalse"`
+		Desc           string `help:"Description" json:"description"`
+	}
+	R(&ServerGroupCreateOptions{}, "server-group-create", "Create a server group", func(s *mcclient.ClientSession, args *ServerGroupCreateOptions) error {
+		params, err := options.StructToParams(args)
+		if err != nil {
+			return err
+		}
+		scaling, err := options.StructToParams(&args.ServerGroupScalingOptions)
+		if err != nil {
+			return err
+		}
+		params.Update(scaling)
+		result, err := modules.ServerGroups.Create(s, params)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	type ServerGroupUpdateOptions struct {
+		ID   string `help:"ID or name of the server group" json:"-"`
+		Name string `help:"New name of the server group"`
+		ServerGroupScalingOptions
+
+		ScalingEnabled *bool  `help:"Enable or disable auto scaling"`
+		Desc           string `help:"Description" json:"description"`
+	}
+	R(&ServerGroupUpdateOptions{}, "server-group-update", "Update a server group", func(s *mcclient.ClientSession, args *ServerGroupUpdateOptions) error {
+		params, err := options.StructToParams(args)
+		if err != nil {
+			return err
+		}
+		scaling, err := options.StructToParams(&args.ServerGroupScalingOptions)
+		if err != nil {
+			return err
+		}
+		params.Update(scaling)
+		if params.Size() == 0 {
+			return InvalidUpdateError()
+		}
+		result, err := modules.ServerGroups.Update(s, args.ID, params)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	type ServerGroupIdOptions struct {
+		ID string `help:"ID or name of the server group"`
+	}
+	R(&ServerGroupIdOptions{}, "server-group-show", "Show details of a server group", func(s *mcclient.ClientSession, args *ServerGroupIdOptions) error {
+		result, err := modules.ServerGroups.Get(s, args.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&ServerGroupIdOptions{}, "server-group-delete", "Delete a server group", func(s *mcclient.ClientSession, args *ServerGroupIdOptions) error {
+		result, err := modules.ServerGroups.Delete(s, args.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	type ScalingRuleListOptions struct {
+		options.BaseListOptions
+
+		Group string `help:"Filter rules of the server group"`
+	}
+	R(&ScalingRuleListOptions{}, "scaling-rule-list", "List scaling rules", func(s *mcclient.ClientSession, args *ScalingRuleListOptions) error {
+		params, err := options.ListStructToParams(args)
+		if err != nil {
+			return err
+		}
+		result, err := modules.ScalingRules.List(s, params)
+		if err != nil {
+			return err
+		}
+		printList(result, modules.ScalingRules.GetColumns(s))
+		return nil
+	})
+
+	type ScalingRuleCreateOptions struct {
+		NAME        string `help:"Name of the scaling rule"`
+		GROUP       string `help:"Server group the rule applies to"`
+		MEASUREMENT string `help:"Influxdb measurement of the server metric, e.g. vm_cpu"`
+		FIELD       string `help:"Field of the measurement, e.g. usage_active"`
+		OPERATOR    string `help:"Comparison between the metric and the threshold" choices:"gt|ge|lt|le"`
+		THRESHOLD   string `help:"Threshold of the metric"`
+		ACTION      string `help:"Scaling action when the rule matches" choices:"scale_out|scale_in"`
+		Aggregate   string `help:"Function aggregating the metric of the servers" choices:"mean|max|min"`
+		Period      int    `help:"Seconds of metrics to aggregate, default 300" json:"period,omitzero"`
+		Step        int    `help:"Number of servers to add or remove, default 1" json:"step,omitzero"`
+		Disabled    bool   `help:"Create the rule disabled" json:"-"`
+	}
+	R(&ScalingRuleCreateOptions{}, "scaling-rule-create", "Create a scaling rule of a server group", func(s *mcclient.ClientSession, args *ScalingRuleCreateOptions) error {
+		params, err := options.StructToParams(args)
+		if err != nil {
+			return err
+		}
+		if args.Disabled {
+			params.Set("enabled", jsonutils.JSONFalse)
+		}
+		result, err := modules.ScalingRules.Create(s, params)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	type ScalingRuleUpdateOptions struct {
+		ID          string `help:"ID or name of the scaling rule" json:"-"`
+		Name        string `help:"New name of the scaling rule"`
+		Measurement string `help:"Influxdb measurement of the server metric"`
+		Field       string `help:"Field of the measurement"`
+		Operator    string `help:"Comparison between the metric and the threshold" choices:"gt|ge|lt|le"`
+		Threshold   string `help:"Threshold of the metric"`
+		Action      string `help:"Scaling action when the rule matches" choices:"scale_out|scale_in"`
+		Aggregate   string `help:"Function aggregating the metric of the servers" choices:"mean|max|min"`
+		Period      int    `help:"Seconds of metrics to aggregate" json:"period,omitzero"`
+		Step        int    `help:"Number of servers to add or remove" json:"step,omitzero"`
+		Enabled     *bool  `help:"Enable or disable the rule"`
+	}
+	R(&ScalingRuleUpdateOptions{}, "scaling-rule-update", "Update a scaling rule", func(s *mcclient.ClientSession, args *ScalingRuleUpdateOptions) error {
+		params, err := options.StructToParams(args)
+		if err != nil {
+			return err
+		}
+		if params.Size() == 0 {
+			return InvalidUpdateError()
+		}
+		result, err := modules.ScalingRules.Update(s, args.ID, params)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	type ScalingRuleIdOptions struct {
+		ID string `help:"ID or name of the scaling rule"`
+	}
+	R(&ScalingRuleIdOptions{}, "scaling-rule-show", "Show details of a scaling rule", func(s *mcclient.ClientSession, args *ScalingRuleIdOptions) error {
+		result, err := modules.ScalingRules.Get(s, args.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&ScalingRuleIdOptions{}, "scaling-rule-delete", "Delete a scaling rule", func(s *mcclient.ClientSession, args *ScalingRuleIdOptions) error {
+		result, err := modules.ScalingRules.Delete(s, args.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+}
@@ -2,6 +2,7 @@ package shell
 
 import (
 	"fmt"
+	"strings"
 
 	"yunion.io/x/jsonutils"
 
@@ -39,7 +40,13 @@ func init() {
 			if err != nil {
 				return err
 			}
-			fmt.Println(result.YAMLString())
+			if !args.Verbose {
+				fmt.Println(result.YAMLString())
+				return nil
+			}
+			canCreate, _ := result.Bool("can_create")
+			fmt.Println("Can create:", canCreate)
+			printForecastCandidates(result)
 			return nil
 		})
 
@@ -155,3 +162,47 @@ func init() {
 			return nil
 		})
 }
+
+// printForecastCandidates renders a verbose forecast as a table of candidates,
+// one line per failed predicate
+func printForecastCandidates(result jsonutils.JSONObject) {
+	candidates, _ := result.GetArray("candidates")
+	rows := make([]jsonutils.JSONObject, 0, len(candidates))
+	for _, candidate := range candidates {
+		row := jsonutils.NewDict()
+		for _, key := range []string{"id", "name", "host_type", "fit", "capacity", "score"} {
+			if val, _ := candidate.Get(key); val != nil {
+				row.Set(key, val)
+			}
+		}
+		scores, _ := candidate.GetArray("scores")
+		scoreStrs := make([]string, 0, len(scores))
+		for _, score := range scores {
+			name, _ := score.GetString("name")
+			val, _ := score.Int("score")
+			scoreStrs = append(scoreStrs, fmt.Sprintf("%s:%d", name, val))
+		}
+		row.Set("scores", jsonutils.NewString(strings.Join(scoreStrs, ",")))
+
+		failed, _ := candidate.GetArray("failed_predicates")
+		if len(failed) == 0 {
+			rows = append(rows, row)
+			continue
+		}
+		for _, predicate := range failed {
+			line := row.Copy()
+			name, _ := predicate.GetString("predicate")
+			reasons, _ := predicate.GetArray("reasons")
+			msgs := make([]string, 0, len(reasons))
+			for _, reason := range reasons {
+				msg, _ := reason.GetString("message")
+				msgs = append(msgs, msg)
+			}
+			line.Set("predicate", jsonutils.NewString(name))
+			line.Set("reason", jsonutils.NewString(strings.Join(msgs, "; ")))
+			rows = append(rows, line)
+		}
+	}
+	printList(&modules.ListResult{Data: rows, Total: len(rows)},
+		[]string{"id", "name", "host_type", "fit", "capacity", "predicate", "reason", "score", "scores"})
+}
@@ -562,6 +562,25 @@ func init() {
 		return nil
 	})
 
+	type ServerCloneOptions struct {
+		SERVER    string `help:"server ID or Name"`
+		NAME      string `help:"Name of the cloned server"`
+		AutoStart bool   `help:"Start the cloned server after it is created"`
+	}
+	R(&ServerCloneOptions{}, "server-clone", "Clone a server from snapshots of all its disks", func(s *mcclient.ClientSession, args *ServerCloneOptions) error {
+		params := jsonutils.NewDict()
+		params.Set("name", jsonutils.NewString(args.NAME))
+		if args.AutoStart {
+			params.Set("auto_start", jsonutils.JSONTrue)
+		}
+		srv, err := modules.Servers.PerformAction(s, args.SERVER, "clone", params)
+		if err != nil {
+			return err
+		}
+		printObject(srv)
+		return nil
+	})
+
 	type ServerInsertISOOptions struct {
 		ID  string `help:"server ID or Name"`
 		ISO string `help:"Glance image ID of the ISO"`
@@ -0,0 +1,200 @@
+package shell
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/mcclient/modules"
+	"yunion.io/x/onecloud/pkg/mcclient/options"
+)
+
+func readServertemplateContent(path string) (jsonutils.JSONObject, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := jsonutils.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %s", path, err)
+	}
+	return obj, nil
+}
+
+func init() {
+	type ServertemplateListOptions struct {
+		options.BaseListOptions
+	}
+	R(&ServertemplateListOptions{}, "servertemplate-list", "List server templates", func(s *mcclient.ClientSession, args *ServertemplateListOptions) error {
+		params, err := options.ListStructToParams(args)
+		if err != nil {
+			return err
+		}
+		result, err := modules.Servertemplates.List(s, params)
+		if err != nil {
+			return err
+		}
+		printList(result, modules.Servertemplates.GetColumns(s))
+		return nil
+	})
+
+	type ServertemplateCreateOptions struct {
+		NAME   string `help:"Name of the server template"`
+		FILE   string `help:"Path to a JSON file holding the server create parameters"`
+		Public bool   `help:"Make the template visible to all projects"`
+		Desc   string `help:"Description" json:"description"`
+	}
+	R(&ServertemplateCreateOptions{}, "servertemplate-create", "Create a server template", func(s *mcclient.ClientSession, args *ServertemplateCreateOptions) error {
+		content, err := readServertemplateContent(args.FILE)
+		if err != nil {
+			return err
+		}
+		params := jsonutils.NewDict()
+		params.Add(jsonutils.NewString(args.NAME), "name")
+		params.Add(content, "content")
+		if args.Public {
+			params.Add(jsonutils.JSONTrue, "is_public")
+		}
+		if len(args.Desc) > 0 {
+			params.Add(jsonutils.NewString(args.Desc), "description")
+		}
+		result, err := modules.Servertemplates.Create(s, params)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	type ServertemplateUpdateOptions struct {
+		ID   string `help:"ID or name of the server template"`
+		Name string `help:"New name of the server template"`
+		File string `help:"Path to a JSON file holding the new server create parameters, bumps the template version"`
+		Desc string `help:"Description"`
+	}
+	R(&ServertemplateUpdateOptions{}, "servertemplate-update", "Update a server template", func(s *mcclient.ClientSession, args *ServertemplateUpdateOptions) error {
+		params := jsonutils.NewDict()
+		if len(args.Name) > 0 {
+			params.Add(jsonutils.NewString(args.Name), "name")
+		}
+		if len(args.File) > 0 {
+			content, err := readServertemplateContent(args.File)
+			if err != nil {
+				return err
+			}
+			params.Add(content, "content")
+		}
+		if len(args.Desc) > 0 {
+			params.Add(jsonutils.NewString(args.Desc), "description")
+		}
+		if params.Size() == 0 {
+			return InvalidUpdateError()
+		}
+		result, err := modules.Servertemplates.Update(s, args.ID, params)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	type ServertemplateIdOptions struct {
+		ID string `help:"ID or name of the server template"`
+	}
+	R(&ServertemplateIdOptions{}, "servertemplate-show", "Show details of a server template", func(s *mcclient.ClientSession, args *ServertemplateIdOptions) error {
+		result, err := modules.Servertemplates.Get(s, args.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&ServertemplateIdOptions{}, "servertemplate-delete", "Delete a server template", func(s *mcclient.ClientSession, args *ServertemplateIdOptions) error {
+		result, err := modules.Servertemplates.Delete(s, args.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&ServertemplateIdOptions{}, "servertemplate-public", "Make a server template public", func(s *mcclient.ClientSession, args *ServertemplateIdOptions) error {
+		result, err := modules.Servertemplates.PerformAction(s, args.ID, "public", nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&ServertemplateIdOptions{}, "servertemplate-private", "Make a server template private", func(s *mcclient.ClientSession, args *ServertemplateIdOptions) error {
+		result, err := modules.Servertemplates.PerformAction(s, args.ID, "private", nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&ServertemplateIdOptions{}, "servertemplate-versions", "List history versions of a server template", func(s *mcclient.ClientSession, args *ServertemplateIdOptions) error {
+		result, err := modules.Servertemplates.GetSpecific(s, args.ID, "versions", nil)
+		if err != nil {
+			return err
+		}
+		printList(modules.JSON2ListResult(result), []string{"Version", "Created_at", "Content"})
+		return nil
+	})
+
+	type ServertemplateLaunchOptions struct {
+		TEMPLATE        string   `help:"ID or name of the server template"`
+		NAME            string   `help:"Name of the server"`
+		TemplateVersion int      `help:"Launch from a history version of the template"`
+		File            string   `help:"Path to a JSON file holding parameters overriding those of the template"`
+		Disk            []string `help:"Disk descriptions, overrides all disks of the template"`
+		Net             []string `help:"Network descriptions, overrides all networks of the template"`
+		Password        string   `help:"Default user password"`
+		Count           int      `help:"Create multiple servers at once"`
+	}
+	R(&ServertemplateLaunchOptions{}, "servertemplate-launch", "Create servers from a server template", func(s *mcclient.ClientSession, args *ServertemplateLaunchOptions) error {
+		params := jsonutils.NewDict()
+		if len(args.File) > 0 {
+			content, err := readServertemplateContent(args.File)
+			if err != nil {
+				return err
+			}
+			overrides, ok := content.(*jsonutils.JSONDict)
+			if !ok {
+				return fmt.Errorf("%s should contain a JSON dict", args.File)
+			}
+			params = overrides
+		}
+		params.Add(jsonutils.NewString(args.TEMPLATE), "template_id")
+		params.Add(jsonutils.NewString(args.NAME), "name")
+		if args.TemplateVersion > 0 {
+			params.Add(jsonutils.NewInt(int64(args.TemplateVersion)), "template_version")
+		}
+		for i, d := range args.Disk {
+			params.Add(jsonutils.NewString(d), fmt.Sprintf("disk.%d", i))
+		}
+		for i, n := range args.Net {
+			params.Add(jsonutils.NewString(n), fmt.Sprintf("net.%d", i))
+		}
+		if len(args.Password) > 0 {
+			params.Add(jsonutils.NewString(args.Password), "password")
+		}
+		if args.Count > 1 {
+			results := modules.Servers.BatchCreate(s, params, args.Count)
+			printBatchResults(results, modules.Servers.GetColumns(s))
+			return nil
+		}
+		result, err := modules.Servers.Create(s, params)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+}
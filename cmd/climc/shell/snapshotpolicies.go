@@ -0,0 +1,231 @@
+package shell
+
+import (
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/mcclient/modules"
+	"yunion.io/x/onecloud/pkg/mcclient/options"
+)
+
+func init() {
+	type SnapshotPolicyListOptions struct {
+		options.BaseListOptions
+
+		Disk   string `help:"Filter policies bound to the disk"`
+		Server string `help:"Filter policies bound to the server"`
+	}
+	R(&SnapshotPolicyListOptions{}, "snapshot-policy-list", "List snapshot policies", func(s *mcclient.ClientSession, args *SnapshotPolicyListOptions) error {
+		params, err := options.ListStructToParams(args)
+		if err != nil {
+			return err
+		}
+		result, err := modules.SnapshotPolicies.List(s, params)
+		if err != nil {
+			return err
+		}
+		printList(result, modules.SnapshotPolicies.GetColumns(s))
+		return nil
+	})
+
+	type SnapshotPolicyCreateOptions struct {
+		NAME           string `help:"Name of the snapshot policy"`
+		SCHEDULE       string `help:"Cron expression of the schedule, e.g. \"0 2 * * *\" for 02:00 every day"`
+		RetentionCount int    `help:"Keep at most this number of snapshots per disk, 0 means no limit"`
+		RetentionDays  int    `help:"Delete snapshots older than this number of days, 0 means no limit"`
+		Disabled       bool   `help:"Create the policy disabled"`
+		Desc           string `help:"Description" json:"description"`
+	}
+	R(&SnapshotPolicyCreateOptions{}, "snapshot-policy-create", "Create a snapshot policy", func(s *mcclient.ClientSession, args *SnapshotPolicyCreateOptions) error {
+		params := jsonutils.NewDict()
+		params.Add(jsonutils.NewString(args.NAME), "name")
+		params.Add(jsonutils.NewString(args.SCHEDULE), "schedule")
+		if args.RetentionCount > 0 {
+			params.Add(jsonutils.NewInt(int64(args.RetentionCount)), "retention_count")
+		}
+		if args.RetentionDays > 0 {
+			params.Add(jsonutils.NewInt(int64(args.RetentionDays)), "retention_days")
+		}
+		if args.Disabled {
+			params.Add(jsonutils.JSONFalse, "enabled")
+		}
+		if len(args.Desc) > 0 {
+			params.Add(jsonutils.NewString(args.Desc), "description")
+		}
+		result, err := modules.SnapshotPolicies.Create(s, params)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	type SnapshotPolicyUpdateOptions struct {
+		ID             string `help:"ID or name of the snapshot policy"`
+		Name           string `help:"New name of the snapshot policy"`
+		Schedule       string `help:"Cron expression of the schedule"`
+		RetentionCount *int   `help:"Keep at most this number of snapshots per disk, 0 means no limit"`
+		RetentionDays  *int   `help:"Delete snapshots older than this number of days, 0 means no limit"`
+		Desc           string `help:"Description"`
+	}
+	R(&SnapshotPolicyUpdateOptions{}, "snapshot-policy-update", "Update a snapshot policy", func(s *mcclient.ClientSession, args *SnapshotPolicyUpdateOptions) error {
+		params := jsonutils.NewDict()
+		if len(args.Name) > 0 {
+			params.Add(jsonutils.NewString(args.Name), "name")
+		}
+		if len(args.Schedule) > 0 {
+			params.Add(jsonutils.NewString(args.Schedule), "schedule")
+		}
+		if args.RetentionCount != nil {
+			params.Add(jsonutils.NewInt(int64(*args.RetentionCount)), "retention_count")
+		}
+		if args.RetentionDays != nil {
+			params.Add(jsonutils.NewInt(int64(*args.RetentionDays)), "retention_days")
+		}
+		if len(args.Desc) > 0 {
+			params.Add(jsonutils.NewString(args.Desc), "description")
+		}
+		if params.Size() == 0 {
+			return InvalidUpdateError()
+		}
+		result, err := modules.SnapshotPolicies.Update(s, args.ID, params)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	type SnapshotPolicyIdOptions struct {
+		ID string `help:"ID or name of the snapshot policy"`
+	}
+	R(&SnapshotPolicyIdOptions{}, "snapshot-policy-show", "Show details of a snapshot policy", func(s *mcclient.ClientSession, args *SnapshotPolicyIdOptions) error {
+		result, err := modules.SnapshotPolicies.Get(s, args.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&SnapshotPolicyIdOptions{}, "snapshot-policy-delete", "Delete a snapshot policy", func(s *mcclient.ClientSession, args *SnapshotPolicyIdOptions) error {
+		result, err := modules.SnapshotPolicies.Delete(s, args.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&SnapshotPolicyIdOptions{}, "snapshot-policy-enable", "Enable a snapshot policy", func(s *mcclient.ClientSession, args *SnapshotPolicyIdOptions) error {
+		result, err := modules.SnapshotPolicies.PerformAction(s, args.ID, "enable", nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&SnapshotPolicyIdOptions{}, "snapshot-policy-disable", "Disable a snapshot policy", func(s *mcclient.ClientSession, args *SnapshotPolicyIdOptions) error {
+		result, err := modules.SnapshotPolicies.PerformAction(s, args.ID, "disable", nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	type SnapshotPolicyDiskListOptions struct {
+		options.BaseListOptions
+		Policy string `help:"ID or name of snapshot policy"`
+		Disk   string `help:"ID or name of disk"`
+	}
+	R(&SnapshotPolicyDiskListOptions{}, "snapshot-policy-disk-list", "List disks bound to snapshot policies", func(s *mcclient.ClientSession, args *SnapshotPolicyDiskListOptions) error {
+		params, err := args.BaseListOptions.Params()
+		if err != nil {
+			return err
+		}
+		var result *modules.ListResult
+		if len(args.Policy) > 0 {
+			result, err = modules.SnapshotPolicyDisks.ListDescendent(s, args.Policy, params)
+		} else if len(args.Disk) > 0 {
+			result, err = modules.SnapshotPolicyDisks.ListDescendent2(s, args.Disk, params)
+		} else {
+			result, err = modules.SnapshotPolicyDisks.List(s, params)
+		}
+		if err != nil {
+			return err
+		}
+		printList(result, modules.SnapshotPolicyDisks.GetColumns(s))
+		return nil
+	})
+
+	type SnapshotPolicyDiskOptions struct {
+		POLICY string `help:"ID or name of snapshot policy"`
+		DISK   string `help:"ID or name of disk"`
+	}
+	R(&SnapshotPolicyDiskOptions{}, "snapshot-policy-disk-attach", "Bind a disk to a snapshot policy", func(s *mcclient.ClientSession, args *SnapshotPolicyDiskOptions) error {
+		result, err := modules.SnapshotPolicyDisks.Attach(s, args.POLICY, args.DISK, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&SnapshotPolicyDiskOptions{}, "snapshot-policy-disk-detach", "Unbind a disk from a snapshot policy", func(s *mcclient.ClientSession, args *SnapshotPolicyDiskOptions) error {
+		result, err := modules.SnapshotPolicyDisks.Detach(s, args.POLICY, args.DISK, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	type SnapshotPolicyServerListOptions struct {
+		options.BaseListOptions
+		Policy string `help:"ID or name of snapshot policy"`
+		Server string `help:"ID or name of server"`
+	}
+	R(&SnapshotPolicyServerListOptions{}, "snapshot-policy-server-list", "List servers bound to snapshot policies", func(s *mcclient.ClientSession, args *SnapshotPolicyServerListOptions) error {
+		params, err := args.BaseListOptions.Params()
+		if err != nil {
+			return err
+		}
+		var result *modules.ListResult
+		if len(args.Policy) > 0 {
+			result, err = modules.SnapshotPolicyServers.ListDescendent(s, args.Policy, params)
+		} else if len(args.Server) > 0 {
+			result, err = modules.SnapshotPolicyServers.ListDescendent2(s, args.Server, params)
+		} else {
+			result, err = modules.SnapshotPolicyServers.List(s, params)
+		}
+		if err != nil {
+			return err
+		}
+		printList(result, modules.SnapshotPolicyServers.GetColumns(s))
+		return nil
+	})
+
+	type SnapshotPolicyServerOptions struct {
+		POLICY string `help:"ID or name of snapshot policy"`
+		SERVER string `help:"ID or name of server"`
+	}
+	R(&SnapshotPolicyServerOptions{}, "snapshot-policy-server-attach", "Bind all disks of a server to a snapshot policy", func(s *mcclient.ClientSession, args *SnapshotPolicyServerOptions) error {
+		result, err := modules.SnapshotPolicyServers.Attach(s, args.POLICY, args.SERVER, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+
+	R(&SnapshotPolicyServerOptions{}, "snapshot-policy-server-detach", "Unbind a server from a snapshot policy", func(s *mcclient.ClientSession, args *SnapshotPolicyServerOptions) error {
+		result, err := modules.SnapshotPolicyServers.Detach(s, args.POLICY, args.SERVER, nil)
+		if err != nil {
+			return err
+		}
+		printObject(result)
+		return nil
+	})
+}
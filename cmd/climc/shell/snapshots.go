@@ -17,6 +17,7 @@ func init() {
 		Local       *bool  `help:"Show local snapshots"`
 		Share       *bool  `help:"Show shared snapshots"`
 		DiskType    string `help:"Filter by disk type" choices:"sys|data"`
+		Policy      string `help:"Filter snapshots taken by the snapshot policy" json:"snapshotpolicy"`
 	}
 	R(&SnapshotsListOptions{}, "snapshot-list", "Show snapshots", func(s *mcclient.ClientSession, args *SnapshotsListOptions) error {
 		params, err := options.ListStructToParams(args)
@@ -0,0 +1,65 @@
+package shell
+
+import (
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/mcclient/modules"
+	"yunion.io/x/onecloud/pkg/mcclient/options"
+)
+
+func init() {
+	R(&options.VpcPeeringListOptions{}, "vpc-peering-list", "List vpc peerings", func(s *mcclient.ClientSession, opts *options.VpcPeeringListOptions) error {
+		params, err := options.ListStructToParams(opts)
+		if err != nil {
+			return err
+		}
+		result, err := modules.VpcPeerings.List(s, params)
+		if err != nil {
+			return err
+		}
+		printList(result, modules.VpcPeerings.GetColumns(s))
+		return nil
+	})
+	R(&options.VpcPeeringCreateOptions{}, "vpc-peering-create", "Create vpc peering", func(s *mcclient.ClientSession, opts *options.VpcPeeringCreateOptions) error {
+		params := jsonutils.Marshal(opts)
+		peering, err := modules.VpcPeerings.Create(s, params)
+		if err != nil {
+			return err
+		}
+		printObject(peering)
+		return nil
+	})
+	R(&options.VpcPeeringIdOptions{}, "vpc-peering-show", "Show vpc peering", func(s *mcclient.ClientSession, opts *options.VpcPeeringIdOptions) error {
+		peering, err := modules.VpcPeerings.Get(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(peering)
+		return nil
+	})
+	R(&options.VpcPeeringIdOptions{}, "vpc-peering-accept", "Accept vpc peering from the managed peer vpc", func(s *mcclient.ClientSession, opts *options.VpcPeeringIdOptions) error {
+		peering, err := modules.VpcPeerings.PerformAction(s, opts.ID, "accept", nil)
+		if err != nil {
+			return err
+		}
+		printObject(peering)
+		return nil
+	})
+	R(&options.VpcPeeringIdOptions{}, "vpc-peering-delete", "Delete vpc peering", func(s *mcclient.ClientSession, opts *options.VpcPeeringIdOptions) error {
+		peering, err := modules.VpcPeerings.Delete(s, opts.ID, nil)
+		if err != nil {
+			return err
+		}
+		printObject(peering)
+		return nil
+	})
+	R(&options.VpcPeeringIdOptions{}, "vpc-peering-purge", "Purge vpc peering", func(s *mcclient.ClientSession, opts *options.VpcPeeringIdOptions) error {
+		peering, err := modules.VpcPeerings.PerformAction(s, opts.ID, "purge", nil)
+		if err != nil {
+			return err
+		}
+		printObject(peering)
+		return nil
+	})
+}
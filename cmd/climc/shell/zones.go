@@ -143,4 +143,25 @@ func init() {
 		return nil
 	})
 
+	type ZoneRebalanceOptions struct {
+		ID            string `help:"ID or name of zone" json:"-"`
+		MaxMigrations int    `help:"Maximal number of live migrations of the plan, default 10" json:"max_migrations,omitzero"`
+		Threshold     string `help:"Tolerated difference between the load of a host and the average load of the zone, default 0.1"`
+		Execute       bool   `help:"Live migrate the guests of the plan" json:"execute,omitfalse"`
+	}
+	R(&ZoneRebalanceOptions{}, "zone-rebalance", "Show or execute the live migration plan evening out the load of the hosts of a zone", func(s *mcclient.ClientSession, args *ZoneRebalanceOptions) error {
+		params, err := options.StructToParams(args)
+		if err != nil {
+			return err
+		}
+		result, err := modules.Zones.PerformAction(s, args.ID, "rebalance", params)
+		if err != nil {
+			return err
+		}
+		hosts, _ := result.GetArray("hosts_after")
+		printList(&modules.ListResult{Data: hosts, Total: len(hosts)}, []string{"host_id", "name", "cpu_usage", "mem_usage", "storage_usage", "load"})
+		migrations, _ := result.GetArray("migrations")
+		printList(&modules.ListResult{Data: migrations, Total: len(migrations)}, []string{"guest_name", "source_host_name", "target_host_name", "vcpu_count", "vmem_size", "local_disk_size"})
+		return nil
+	})
 }
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"yunion.io/x/log"
+	"yunion.io/x/structarg"
+
+	"yunion.io/x/onecloud/pkg/util/google"
+	_ "yunion.io/x/onecloud/pkg/util/google/shell"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+type BaseOptions struct {
+	Help           bool   `help:"Show help" default:"false"`
+	Debug          bool   `help:"Show debug" default:"false"`
+	ProjectId      string `help:"ProjectId" default:"$GOOGLE_PROJECT"`
+	ClientEmail    string `help:"Client email" default:"$GOOGLE_CLIENT_EMAIL"`
+	PrivateKeyId   string `help:"Private key id" default:"$GOOGLE_PRIVATE_KEY_ID"`
+	PrivateKeyFile string `help:"Private key file" default:"$GOOGLE_PRIVATE_KEY_FILE"`
+	RegionId       string `help:"RegionId" default:"$GOOGLE_REGION"`
+	SUBCOMMAND     string `help:"gcpcli subcommand" subcommand:"true"`
+}
+
+func getSubcommandParser() (*structarg.ArgumentParser, error) {
+	parse, e := structarg.NewArgumentParser(&BaseOptions{},
+		"gcpcli",
+		"Command-line interface to google cloud API.",
+		`See "gcpcli help COMMAND" for help on a specific command.`)
+
+	if e != nil {
+		return nil, e
+	}
+
+	subcmd := parse.GetSubcommand()
+	if subcmd == nil {
+		return nil, fmt.Errorf("No subcommand argument.")
+	}
+	type HelpOptions struct {
+		SUBCOMMAND string `help:"sub-command name"`
+	}
+	shellutils.R(&HelpOptions{}, "help", "Show help of a subcommand", func(args *HelpOptions) error {
+		helpstr, e := subcmd.SubHelpString(args.SUBCOMMAND)
+		if e != nil {
+			return e
+		} else {
+			fmt.Print(helpstr)
+			return nil
+		}
+	})
+	for _, v := range shellutils.CommandTable {
+		_, e := subcmd.AddSubParser(v.Options, v.Command, v.Desc, v.Callback)
+		if e != nil {
+			return nil, e
+		}
+	}
+	return parse, nil
+}
+
+func showErrorAndExit(e error) {
+	log.Errorf("%s", e)
+	os.Exit(1)
+}
+
+func newClient(options *BaseOptions) (*google.SRegion, error) {
+	if len(options.ProjectId) == 0 {
+		return nil, fmt.Errorf("Missing projectId")
+	}
+
+	if len(options.ClientEmail) == 0 {
+		return nil, fmt.Errorf("Missing clientEmail")
+	}
+
+	if len(options.PrivateKeyId) == 0 {
+		return nil, fmt.Errorf("Missing privateKeyId")
+	}
+
+	if len(options.PrivateKeyFile) == 0 {
+		return nil, fmt.Errorf("Missing privateKeyFile")
+	}
+
+	privateKey, err := ioutil.ReadFile(options.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read private key file %s error: %v", options.PrivateKeyFile, err)
+	}
+
+	account := options.ProjectId + "/" + options.ClientEmail
+	secret := options.PrivateKeyId + "/" + string(privateKey)
+
+	cli, err := google.NewGoogleClient("", "", account, secret, options.Debug)
+	if err != nil {
+		return nil, err
+	}
+
+	region := cli.GetRegion(options.RegionId)
+	if region == nil {
+		return nil, fmt.Errorf("No such region %s", options.RegionId)
+	}
+
+	return region, nil
+}
+
+func main() {
+	parser, e := getSubcommandParser()
+	if e != nil {
+		showErrorAndExit(e)
+	}
+	e = parser.ParseArgs(os.Args[1:], false)
+	options := parser.Options().(*BaseOptions)
+
+	if options.Help {
+		fmt.Print(parser.HelpString())
+	} else {
+		subcmd := parser.GetSubcommand()
+		subparser := subcmd.GetSubParser()
+		if e != nil {
+			if subparser != nil {
+				fmt.Print(subparser.Usage())
+			} else {
+				fmt.Print(parser.Usage())
+			}
+			showErrorAndExit(e)
+		} else {
+			suboptions := subparser.Options()
+			if options.SUBCOMMAND == "help" {
+				e = subcmd.Invoke(suboptions)
+			} else {
+				var region *google.SRegion
+				region, e = newClient(options)
+				if e != nil {
+					showErrorAndExit(e)
+				}
+				e = subcmd.Invoke(region, suboptions)
+			}
+			if e != nil {
+				showErrorAndExit(e)
+			}
+		}
+	}
+}
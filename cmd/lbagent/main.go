@@ -30,6 +30,7 @@ func main() {
 	var haproxyHelper *lbagent.HaproxyHelper
 	var apiHelper *lbagent.ApiHelper
 	var haStateWatcher *lbagent.HaStateWatcher
+	var accessLogHelper *lbagent.AccessLogHelper
 	var err error
 	{
 		haStateWatcher, err = lbagent.NewHaStateWatcher(opts)
@@ -43,12 +44,19 @@ func main() {
 			log.Fatalf("init haproxy helper failed: %s", err)
 		}
 	}
+	{
+		accessLogHelper, err = lbagent.NewAccessLogHelper(opts)
+		if err != nil {
+			log.Fatalf("init access log helper failed: %s", err)
+		}
+	}
 	{
 		apiHelper, err = lbagent.NewApiHelper(opts)
 		if err != nil {
 			log.Fatalf("init api helper failed: %s", err)
 		}
 		apiHelper.SetHaStateProvider(haStateWatcher)
+		apiHelper.SetAccessLogHelper(accessLogHelper)
 	}
 
 	{
@@ -57,9 +65,10 @@ func main() {
 		ctx, cancelFunc := context.WithCancel(context.Background())
 		ctx = context.WithValue(ctx, "wg", wg)
 		ctx = context.WithValue(ctx, "cmdChan", cmdChan)
-		wg.Add(3)
+		wg.Add(4)
 		go haStateWatcher.Run(ctx)
 		go haproxyHelper.Run(ctx)
+		go accessLogHelper.Run(ctx)
 		go apiHelper.Run(ctx)
 
 		go func() {
@@ -104,6 +104,8 @@ type ServerConfigs struct {
 	Project      string `json:"project"`
 	Backup       bool   `json:"backup"`
 	Count        int    `json:"count"`
+	// NumaPolicy "none|single_node"
+	NumaPolicy string `json:"numa_policy,omitempty"`
 
 	Disks                []*DiskConfig           `json:"disks"`
 	Networks             []*NetworkConfig        `json:"nets"`
@@ -153,6 +155,8 @@ type ServerCreateInput struct {
 	EipBw              int             `json:"eip_bw,omitzero"`
 	EipChargeType      string          `json:"eip_charge_type,omitempty"`
 	Eip                string          `json:"eip,omitempty"`
+	HaPolicy           string          `json:"ha_policy,omitempty"`
+	HaPriority         int             `json:"ha_priority,omitzero"`
 
 	OsType string `json:"os_type"`
 	// Fill by server
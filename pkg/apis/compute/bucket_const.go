@@ -0,0 +1,10 @@
+package compute
+
+const (
+	BUCKET_STATUS_READY         = "ready"
+	BUCKET_STATUS_CREATING      = "creating"
+	BUCKET_STATUS_CREATE_FAILED = "create_failed"
+	BUCKET_STATUS_DELETING      = "deleting"
+	BUCKET_STATUS_DELETE_FAILED = "delete_failed"
+	BUCKET_STATUS_UNKNOWN       = "unknown"
+)
@@ -0,0 +1,32 @@
+package compute
+
+const (
+	DBINSTANCE_STATUS_RUNNING       = "running"
+	DBINSTANCE_STATUS_READY         = "ready"
+	DBINSTANCE_STATUS_DEPLOYING     = "deploying"
+	DBINSTANCE_STATUS_MAINTENANCE   = "maintenance"
+	DBINSTANCE_STATUS_STARTING      = "starting"
+	DBINSTANCE_STATUS_START_FAILED  = "start_failed"
+	DBINSTANCE_STATUS_STOPPING      = "stopping"
+	DBINSTANCE_STATUS_STOP_FAILED   = "stop_failed"
+	DBINSTANCE_STATUS_REBOOTING     = "rebooting"
+	DBINSTANCE_STATUS_REBOOT_FAILED = "reboot_failed"
+	DBINSTANCE_STATUS_DELETING      = "deleting"
+	DBINSTANCE_STATUS_DELETE_FAILED = "delete_failed"
+	DBINSTANCE_STATUS_UNKNOWN       = "unknown"
+
+	DBINSTANCE_ENGINE_MYSQL      = "MySQL"
+	DBINSTANCE_ENGINE_SQLSERVER  = "SQLServer"
+	DBINSTANCE_ENGINE_POSTGRESQL = "PostgreSQL"
+	DBINSTANCE_ENGINE_MARIADB    = "MariaDB"
+	DBINSTANCE_ENGINE_ORACLE     = "Oracle"
+	DBINSTANCE_ENGINE_PPAS       = "PPAS"
+
+	DBINSTANCE_BACKUP_STATUS_READY    = "ready"
+	DBINSTANCE_BACKUP_STATUS_CREATING = "creating"
+	DBINSTANCE_BACKUP_STATUS_FAILED   = "failed"
+	DBINSTANCE_BACKUP_STATUS_UNKNOWN  = "unknown"
+
+	DBINSTANCE_BACKUP_MODE_AUTOMATED = "automated"
+	DBINSTANCE_BACKUP_MODE_MANUAL    = "manual"
+)
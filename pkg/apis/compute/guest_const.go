@@ -104,6 +104,7 @@ const (
 	HYPERVISOR_AWS       = "aws"
 	HYPERVISOR_HUAWEI    = "huawei"
 	HYPERVISOR_OPENSTACK = "openstack"
+	HYPERVISOR_GOOGLE    = "google"
 
 	//	HYPERVISOR_DEFAULT = HYPERVISOR_KVM
 	HYPERVISOR_DEFAULT = HYPERVISOR_KVM
@@ -122,6 +123,7 @@ var HYPERVISORS = []string{HYPERVISOR_KVM,
 	HYPERVISOR_QCLOUD,
 	HYPERVISOR_HUAWEI,
 	HYPERVISOR_OPENSTACK,
+	HYPERVISOR_GOOGLE,
 }
 
 var PUBLIC_CLOUD_HYPERVISORS = []string{
@@ -131,6 +133,7 @@ var PUBLIC_CLOUD_HYPERVISORS = []string{
 	HYPERVISOR_QCLOUD,
 	HYPERVISOR_HUAWEI,
 	HYPERVISOR_OPENSTACK,
+	HYPERVISOR_GOOGLE,
 }
 
 // var HYPERVISORS = []string{HYPERVISOR_ALIYUN}
@@ -146,6 +149,7 @@ var HYPERVISOR_HOSTTYPE = map[string]string{
 	HYPERVISOR_QCLOUD:    HOST_TYPE_QCLOUD,
 	HYPERVISOR_HUAWEI:    HOST_TYPE_HUAWEI,
 	HYPERVISOR_OPENSTACK: HOST_TYPE_OPENSTACK,
+	HYPERVISOR_GOOGLE:    HOST_TYPE_GOOGLE,
 }
 
 var HOSTTYPE_HYPERVISOR = map[string]string{
@@ -159,4 +163,5 @@ var HOSTTYPE_HYPERVISOR = map[string]string{
 	HOST_TYPE_QCLOUD:     HYPERVISOR_QCLOUD,
 	HOST_TYPE_HUAWEI:     HYPERVISOR_HUAWEI,
 	HOST_TYPE_OPENSTACK:  HYPERVISOR_OPENSTACK,
+	HOST_TYPE_GOOGLE:     HYPERVISOR_GOOGLE,
 }
@@ -0,0 +1,25 @@
+package compute
+
+import (
+	"yunion.io/x/onecloud/pkg/util/choices"
+)
+
+// what the region does with a guest when its host goes offline
+const (
+	GUEST_HA_POLICY_NONE    = "none"
+	GUEST_HA_POLICY_RESTART = "restart"
+)
+
+var GUEST_HA_POLICIES = choices.NewChoices(
+	GUEST_HA_POLICY_NONE,
+	GUEST_HA_POLICY_RESTART,
+)
+
+// status of the high availability handling of an offline host
+const (
+	HOST_HA_STATUS_FENCING    = "fencing"
+	HOST_HA_STATUS_FENCE_FAIL = "fence_fail"
+	HOST_HA_STATUS_RESTARTING = "restarting"
+	HOST_HA_STATUS_RESTARTED  = "restarted"
+	HOST_HA_STATUS_HA_FAIL    = "ha_fail"
+)
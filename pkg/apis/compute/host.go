@@ -0,0 +1,16 @@
+package compute
+
+// HostDrainGuest is the progress of moving a guest off a draining host
+type HostDrainGuest struct {
+	GuestId string `json:"guest_id"`
+	Name    string `json:"name"`
+	// Action is live_migrate for running guests and migrate for stopped ones
+	Action string `json:"action"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type HostDrainProgress struct {
+	Status string           `json:"status"`
+	Guests []HostDrainGuest `json:"guests"`
+}
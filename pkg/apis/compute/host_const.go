@@ -15,6 +15,7 @@ const (
 	HOST_TYPE_AZURE     = "azure"
 	HOST_TYPE_HUAWEI    = "huawei"
 	HOST_TYPE_OPENSTACK = "openstack"
+	HOST_TYPE_GOOGLE    = "google"
 
 	HOST_TYPE_DEFAULT = HOST_TYPE_HYPERVISOR
 
@@ -52,6 +53,22 @@ const (
 	HOST_STATUS_UNKNOWN = BAREMETAL_UNKNOWN
 )
 
+// drain of a kvm host, the host stays in maintenance until it is undrained
+const (
+	HOST_DRAIN_STATUS_DRAINING   = "draining"
+	HOST_DRAIN_STATUS_DRAINED    = "drained"
+	HOST_DRAIN_STATUS_DRAIN_FAIL = "drain_fail"
+
+	HOST_DRAIN_GUEST_PENDING   = "pending"
+	HOST_DRAIN_GUEST_MIGRATING = "migrating"
+	HOST_DRAIN_GUEST_MIGRATED  = "migrated"
+	HOST_DRAIN_GUEST_SKIPPED   = "skipped"
+	HOST_DRAIN_GUEST_FAILED    = "failed"
+
+	HOST_DRAIN_ACTION_LIVE_MIGRATE = "live_migrate"
+	HOST_DRAIN_ACTION_MIGRATE      = "migrate"
+)
+
 const (
 	HostResourceTypeShared         = "shared"
 	HostResourceTypeDefault        = HostResourceTypeShared
@@ -59,6 +76,6 @@ const (
 	HostResourceTypeDedicated      = "dedicated"
 )
 
-var HOST_TYPES = []string{HOST_TYPE_BAREMETAL, HOST_TYPE_HYPERVISOR, HOST_TYPE_ESXI, HOST_TYPE_KUBELET, HOST_TYPE_XEN, HOST_TYPE_ALIYUN, HOST_TYPE_AZURE, HOST_TYPE_AWS, HOST_TYPE_QCLOUD, HOST_TYPE_HUAWEI, HOST_TYPE_OPENSTACK}
+var HOST_TYPES = []string{HOST_TYPE_BAREMETAL, HOST_TYPE_HYPERVISOR, HOST_TYPE_ESXI, HOST_TYPE_KUBELET, HOST_TYPE_XEN, HOST_TYPE_ALIYUN, HOST_TYPE_AZURE, HOST_TYPE_AWS, HOST_TYPE_QCLOUD, HOST_TYPE_HUAWEI, HOST_TYPE_OPENSTACK, HOST_TYPE_GOOGLE}
 
 var NIC_TYPES = []string{NIC_TYPE_IPMI, NIC_TYPE_ADMIN}
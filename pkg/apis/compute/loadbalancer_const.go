@@ -133,6 +133,23 @@ var LB_TLS_CERT_PUBKEY_ALGOS = choices.NewChoices(
 	LB_TLS_CERT_PUBKEY_ALGO_ECDSA,
 )
 
+// Certificates of mode acme are issued and renewed by region through ACME
+// protocol, with http-01 challenges answered by lbagents
+const (
+	LB_CERT_MODE_UPLOAD = "upload"
+	LB_CERT_MODE_ACME   = "acme"
+)
+
+var LB_CERT_MODES = choices.NewChoices(
+	LB_CERT_MODE_UPLOAD,
+	LB_CERT_MODE_ACME,
+)
+
+const (
+	LB_CERT_STATUS_RENEWING     = "renewing"
+	LB_CERT_STATUS_RENEW_FAILED = "renew_failed"
+)
+
 // TODO may want extra for legacy apps
 const (
 	LB_TLS_CIPHER_POLICY_1_0        = "tls_cipher_policy_1_0"
@@ -197,6 +214,47 @@ var LB_HEALTH_CHECK_HTTP_CODES = choices.NewChoices(
 	LB_HEALTH_CHECK_HTTP_CODE_5xx,
 )
 
+const (
+	LB_RULE_ACTION_FORWARD        = "forward"
+	LB_RULE_ACTION_REDIRECT       = "redirect"
+	LB_RULE_ACTION_FIXED_RESPONSE = "fixed_response"
+)
+
+var LB_RULE_ACTIONS = choices.NewChoices(
+	LB_RULE_ACTION_FORWARD,
+	LB_RULE_ACTION_REDIRECT,
+	LB_RULE_ACTION_FIXED_RESPONSE,
+)
+
+const (
+	LB_REDIRECT_SCHEME_HTTP  = "http"
+	LB_REDIRECT_SCHEME_HTTPS = "https"
+
+	LB_REDIRECT_CODE_DEFAULT = 302
+)
+
+var LB_REDIRECT_SCHEMES = choices.NewChoices(
+	LB_REDIRECT_SCHEME_HTTP,
+	LB_REDIRECT_SCHEME_HTTPS,
+)
+
+var LB_REDIRECT_CODES = []int{301, 302, 303, 307, 308}
+
+// status codes haproxy accepts for deny_status
+var LB_FIXED_RESPONSE_CODES = []int{200, 400, 403, 405, 408, 429, 500, 502, 503, 504}
+
+const (
+	LB_HTTP_HEADER_ACTION_SET = "set"
+	LB_HTTP_HEADER_ACTION_ADD = "add"
+	LB_HTTP_HEADER_ACTION_DEL = "del"
+)
+
+var LB_HTTP_HEADER_ACTIONS = choices.NewChoices(
+	LB_HTTP_HEADER_ACTION_SET,
+	LB_HTTP_HEADER_ACTION_ADD,
+	LB_HTTP_HEADER_ACTION_DEL,
+)
+
 const (
 	LB_BOOL_ON  = "on"
 	LB_BOOL_OFF = "off"
@@ -250,6 +308,31 @@ var LB_BACKEND_ROLES = choices.NewChoices(
 	LB_BACKEND_ROLE_SLAVE,
 )
 
+// health status of backends as reported by lbagent
+const (
+	LB_BACKEND_HEALTH_STATUS_UP      = "up"
+	LB_BACKEND_HEALTH_STATUS_DOWN    = "down"
+	LB_BACKEND_HEALTH_STATUS_UNKNOWN = "unknown"
+)
+
+var LB_BACKEND_HEALTH_STATUSES = choices.NewChoices(
+	LB_BACKEND_HEALTH_STATUS_UP,
+	LB_BACKEND_HEALTH_STATUS_DOWN,
+	LB_BACKEND_HEALTH_STATUS_UNKNOWN,
+)
+
+// drain states of backends.  Draining backends receive no new connections,
+// they become drained when lbagent reports no active sessions on them
+const (
+	LB_BACKEND_DRAIN_STATE_NONE     = ""
+	LB_BACKEND_DRAIN_STATE_DRAINING = "draining"
+	LB_BACKEND_DRAIN_STATE_DRAINED  = "drained"
+)
+
+// influxdb measurement of access logs collected by lbagent, tagged by
+// listener_id, backend and server
+const LB_ACCESS_LOG_MEASUREMENT = "lb_access_log"
+
 const (
 	LB_CHARGE_TYPE_BY_TRAFFIC   = "traffic"
 	LB_CHARGE_TYPE_BY_BANDWIDTH = "bandwidth"
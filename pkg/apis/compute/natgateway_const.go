@@ -0,0 +1,38 @@
+package compute
+
+import (
+	"yunion.io/x/onecloud/pkg/util/choices"
+)
+
+const (
+	NAT_STATUS_AVAILABLE     = "available"
+	NAT_STATUS_ALLOCATE      = "allocate"
+	NAT_STATUS_DEPLOYING     = "deploying"
+	NAT_STATUS_CREATE_FAILED = "create_failed"
+	NAT_STATUS_DELETING      = "deleting"
+	NAT_STATUS_DELETED       = "deleted"
+	NAT_STATUS_DELETE_FAILED = "delete_failed"
+	NAT_STATUS_UNKNOWN       = "unknown"
+
+	NAT_SPEC_SMALL  = "small"
+	NAT_SPEC_MIDDLE = "middle"
+	NAT_SPEC_LARGE  = "large"
+	NAT_SPEC_XLARGE = "xlarge"
+
+	NAT_DNAT_PROTOCOL_TCP = "tcp"
+	NAT_DNAT_PROTOCOL_UDP = "udp"
+	NAT_DNAT_PROTOCOL_ANY = "any"
+)
+
+var NAT_SPECS = choices.NewChoices(
+	NAT_SPEC_SMALL,
+	NAT_SPEC_MIDDLE,
+	NAT_SPEC_LARGE,
+	NAT_SPEC_XLARGE,
+)
+
+var NAT_DNAT_PROTOCOLS = choices.NewChoices(
+	NAT_DNAT_PROTOCOL_TCP,
+	NAT_DNAT_PROTOCOL_UDP,
+	NAT_DNAT_PROTOCOL_ANY,
+)
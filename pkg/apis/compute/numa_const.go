@@ -0,0 +1,16 @@
+package compute
+
+import (
+	"yunion.io/x/onecloud/pkg/util/choices"
+)
+
+// how the vcpus and memory of a guest are placed on the numa nodes of its host
+const (
+	GUEST_NUMA_POLICY_NONE        = "none"
+	GUEST_NUMA_POLICY_SINGLE_NODE = "single_node"
+)
+
+var GUEST_NUMA_POLICIES = choices.NewChoices(
+	GUEST_NUMA_POLICY_NONE,
+	GUEST_NUMA_POLICY_SINGLE_NODE,
+)
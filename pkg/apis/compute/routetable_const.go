@@ -0,0 +1,14 @@
+package compute
+
+const (
+	ROUTE_TABLE_STATUS_AVAILABLE     = "available"
+	ROUTE_TABLE_STATUS_UPDATING      = "updating"
+	ROUTE_TABLE_STATUS_UPDATE_FAILED = "update_failed"
+
+	ROUTE_NEXT_HOP_TYPE_INSTANCE          = "Instance"
+	ROUTE_NEXT_HOP_TYPE_NETWORK_INTERFACE = "NetworkInterface"
+	ROUTE_NEXT_HOP_TYPE_VPC_PEERING       = "VpcPeering"
+	ROUTE_NEXT_HOP_TYPE_NAT_GATEWAY       = "NatGateway"
+	ROUTE_NEXT_HOP_TYPE_INTERNET_GATEWAY  = "InternetGateway"
+	ROUTE_NEXT_HOP_TYPE_VPN_GATEWAY       = "VpnGateway"
+)
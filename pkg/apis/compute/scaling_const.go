@@ -0,0 +1,39 @@
+package compute
+
+import (
+	"yunion.io/x/onecloud/pkg/util/choices"
+)
+
+const (
+	SCALING_RULE_READY = "ready"
+
+	SCALING_ACTION_OUT = "scale_out"
+	SCALING_ACTION_IN  = "scale_in"
+)
+
+var SCALING_ACTIONS = choices.NewChoices(
+	SCALING_ACTION_OUT,
+	SCALING_ACTION_IN,
+)
+
+// comparison between the aggregated metric and the threshold of a scaling rule
+const (
+	SCALING_OPERATOR_GT = "gt"
+	SCALING_OPERATOR_GE = "ge"
+	SCALING_OPERATOR_LT = "lt"
+	SCALING_OPERATOR_LE = "le"
+)
+
+var SCALING_OPERATORS = choices.NewChoices(
+	SCALING_OPERATOR_GT,
+	SCALING_OPERATOR_GE,
+	SCALING_OPERATOR_LT,
+	SCALING_OPERATOR_LE,
+)
+
+// influxdb functions to aggregate the metric of the servers of a group over a period
+var SCALING_AGGREGATES = choices.NewChoices(
+	"mean",
+	"max",
+	"min",
+)
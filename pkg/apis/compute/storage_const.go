@@ -42,6 +42,10 @@ const (
 
 	// openstack
 	STORAGE_OPENSTACK_ISCSI = "iscsi"
+
+	// google storage type
+	STORAGE_GOOGLE_PD_STANDARD = "pd-standard" // 标准永久性磁盘
+	STORAGE_GOOGLE_PD_SSD      = "pd-ssd"      // SSD永久性磁盘
 )
 
 const (
@@ -72,6 +76,7 @@ var (
 		STORAGE_LOCAL_BASIC, STORAGE_LOCAL_SSD, STORAGE_CLOUD_BASIC, STORAGE_CLOUD_PREMIUM,
 		STORAGE_HUAWEI_SSD, STORAGE_HUAWEI_SAS, STORAGE_HUAWEI_SATA,
 		STORAGE_OPENSTACK_ISCSI,
+		STORAGE_GOOGLE_PD_STANDARD, STORAGE_GOOGLE_PD_SSD,
 	}
 
 	STORAGE_LIMITED_TYPES = []string{STORAGE_LOCAL, STORAGE_BAREMETAL, STORAGE_NAS, STORAGE_RBD, STORAGE_NFS}
@@ -0,0 +1,15 @@
+package compute
+
+const (
+	VPC_PEERING_STATUS_CREATING       = "creating"
+	VPC_PEERING_STATUS_CREATE_FAILED  = "create_failed"
+	VPC_PEERING_STATUS_PENDING_ACCEPT = "pending_accept"
+	VPC_PEERING_STATUS_ACCEPTING      = "accepting"
+	VPC_PEERING_STATUS_ACCEPT_FAILED  = "accept_failed"
+	VPC_PEERING_STATUS_ACTIVE         = "active"
+	VPC_PEERING_STATUS_REJECTED       = "rejected"
+	VPC_PEERING_STATUS_EXPIRED        = "expired"
+	VPC_PEERING_STATUS_DELETING       = "deleting"
+	VPC_PEERING_STATUS_DELETE_FAILED  = "delete_failed"
+	VPC_PEERING_STATUS_UNKNOWN        = "unknown"
+)
@@ -15,6 +15,9 @@ type ScheduleBaseConfig struct {
 	// usedby test api
 	RecordLog bool `json:"record_to_history"`
 	Details   bool `json:"details"`
+
+	// usedby forecast api, check all predicates and explain every candidate
+	Verbose bool `json:"verbose"`
 }
 
 type ForGuest struct {
@@ -80,3 +83,46 @@ type ScheduleOutput struct {
 
 	Candidates []*CandidateResource `json:"candidates"`
 }
+
+// RebalanceInput used by scheduler rebalance api
+type RebalanceInput struct {
+	apis.Meta
+
+	ZoneId string `json:"zone_id"`
+
+	// MaxMigrations limits the number of live migrations of the plan
+	MaxMigrations int `json:"max_migrations"`
+	// Threshold is the tolerated difference between the load of a host and
+	// the average load of the zone, the load is a ratio between 0 and 1
+	Threshold float64 `json:"threshold"`
+}
+
+type RebalanceHostLoad struct {
+	HostId       string  `json:"host_id"`
+	Name         string  `json:"name"`
+	CpuUsage     float64 `json:"cpu_usage"`
+	MemUsage     float64 `json:"mem_usage"`
+	StorageUsage float64 `json:"storage_usage"`
+	Load         float64 `json:"load"`
+}
+
+type RebalanceMigration struct {
+	GuestId        string `json:"guest_id"`
+	GuestName      string `json:"guest_name"`
+	SourceHostId   string `json:"source_host_id"`
+	SourceHostName string `json:"source_host_name"`
+	TargetHostId   string `json:"target_host_id"`
+	TargetHostName string `json:"target_host_name"`
+	VcpuCount      int    `json:"vcpu_count"`
+	VmemSize       int    `json:"vmem_size"`
+	LocalDiskSize  int    `json:"local_disk_size"`
+}
+
+// RebalanceOutput is the live migration plan evening out the load of the hosts of a zone
+type RebalanceOutput struct {
+	apis.Meta
+
+	Migrations  []*RebalanceMigration `json:"migrations"`
+	HostsBefore []*RebalanceHostLoad  `json:"hosts_before"`
+	HostsAfter  []*RebalanceHostLoad  `json:"hosts_after"`
+}
@@ -862,6 +862,11 @@ func doCreateItem(manager IModelManager, ctx context.Context, userCred mcclient.
 	if err != nil {
 		return nil, httperrors.NewGeneralError(err)
 	}
+	defer func() {
+		if err != nil {
+			manager.OnCreateFailed(ctx, userCred, ownerProjId, query, dataDict)
+		}
+	}()
 	err = jsonutils.CheckRequiredFields(dataDict, createRequireFields(manager, userCred))
 	if err != nil {
 		return nil, httperrors.NewInputParameterError(err.Error())
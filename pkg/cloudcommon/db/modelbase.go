@@ -145,6 +145,10 @@ func (manager *SModelBaseManager) OnCreateComplete(ctx context.Context, items []
 	// do nothing
 }
 
+func (manager *SModelBaseManager) OnCreateFailed(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	// do nothing
+}
+
 func (manager *SModelBaseManager) AllowPerformAction(ctx context.Context, userCred mcclient.TokenCredential, action string, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
 	return false
 }
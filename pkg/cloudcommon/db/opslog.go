@@ -47,6 +47,16 @@ const (
 	ACT_STOP      = "stop"
 	ACT_STOP_FAIL = "stop_fail"
 
+	ACT_REBOOTING   = "rebooting"
+	ACT_REBOOT      = "reboot"
+	ACT_REBOOT_FAIL = "reboot_fail"
+
+	ACT_ACCEPTING   = "accepting"
+	ACT_ACCEPT      = "accept"
+	ACT_ACCEPT_FAIL = "accept_fail"
+
+	ACT_UPDATE_FAIL = "update_fail"
+
 	ACT_RESIZING    = "resizing"
 	ACT_RESIZE      = "resize"
 	ACT_RESIZE_FAIL = "resize_fail"
@@ -55,6 +65,29 @@ const (
 	ACT_MIGRATE      = "migrate"
 	ACT_MIGRATE_FAIL = "migrate_fail"
 
+	ACT_CLONING    = "cloning"
+	ACT_CLONE      = "clone"
+	ACT_CLONE_FAIL = "clone_fail"
+
+	ACT_SCALE_OUT  = "scale_out"
+	ACT_SCALE_IN   = "scale_in"
+	ACT_SCALE_FAIL = "scale_fail"
+
+	ACT_REBALANCING    = "rebalancing"
+	ACT_REBALANCE      = "rebalance"
+	ACT_REBALANCE_FAIL = "rebalance_fail"
+
+	ACT_DRAINING   = "draining"
+	ACT_DRAIN      = "drain"
+	ACT_DRAIN_FAIL = "drain_fail"
+	ACT_UNDRAIN    = "undrain"
+
+	ACT_FENCE           = "fence"
+	ACT_FENCE_FAIL      = "fence_fail"
+	ACT_HA_RESTARTING   = "ha_restarting"
+	ACT_HA_RESTART      = "ha_restart"
+	ACT_HA_RESTART_FAIL = "ha_restart_fail"
+
 	ACT_SPLIT = "net_split"
 	ACT_MERGE = "net_merge"
 
@@ -45,21 +45,21 @@ func AddQuotaHandler(manager *SQuotaManager, prefix string, app *appsrv.Applicat
 		auth.Authenticate(checkQuotaHanlder), nil, "check_quota", nil)
 }
 
-func queryQuota(ctx context.Context, projectId string) (*jsonutils.JSONDict, error) {
+func queryQuota(ctx context.Context, projectId string, scope SQuotaScope) (*jsonutils.JSONDict, error) {
 	ret := jsonutils.NewDict()
 
 	quota := _manager.newQuota()
-	err := _manager.GetQuota(ctx, projectId, quota)
+	err := _manager.GetScopedQuota(ctx, projectId, scope, quota)
 	if err != nil {
 		return nil, err
 	}
 	usage := _manager.newQuota()
-	err = usage.FetchUsage(ctx, projectId)
+	err = _manager.FetchUsage(ctx, projectId, scope, usage)
 	if err != nil {
 		return nil, err
 	}
 	pending := _manager.newQuota()
-	err = _manager.GetPendingUsage(ctx, projectId, pending)
+	err = _manager.GetScopedPendingUsage(ctx, projectId, scope, pending)
 	if err != nil {
 		return nil, err
 	}
@@ -67,13 +67,44 @@ func queryQuota(ctx context.Context, projectId string) (*jsonutils.JSONDict, err
 	ret.Update(quota.ToJSON(""))
 	ret.Update(usage.ToJSON("usage"))
 	ret.Update(pending.ToJSON("pending"))
+	ret.Update(jsonutils.Marshal(scope))
 
 	return ret, nil
 }
 
+// queryScopedQuotas reports the quota and usage of the project in each scope
+// where it has a quota
+func queryScopedQuotas(ctx context.Context, projectId string) (*jsonutils.JSONArray, error) {
+	scopes, err := _manager.GetQuotaScopes(ctx, projectId)
+	if err != nil {
+		return nil, err
+	}
+	ret := jsonutils.NewArray()
+	for _, scope := range scopes {
+		quota, err := queryQuota(ctx, projectId, scope)
+		if err != nil {
+			return nil, err
+		}
+		ret.Add(quota)
+	}
+	return ret, nil
+}
+
+func fetchQuotaScope(data jsonutils.JSONObject) SQuotaScope {
+	scope := SQuotaScope{}
+	if data != nil {
+		scope.RegionId, _ = data.GetString("region_id")
+		scope.ZoneId, _ = data.GetString("zone_id")
+	}
+	return scope
+}
+
 func getQuotaHanlder(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	userCred := auth.FetchUserCredential(ctx, policy.FilterPolicyCredential)
-	params := appctx.AppContextParams(ctx)
+	params, query, _ := appsrv.FetchEnv(ctx, w, r)
+	if query == nil {
+		query = jsonutils.NewDict()
+	}
 
 	projectId := params["<tenantid>"]
 	if len(projectId) == 0 {
@@ -119,12 +150,20 @@ func getQuotaHanlder(ctx context.Context, w http.ResponseWriter, r *http.Request
 		}
 		projectId = tenant.Id
 	}
-	quota, err := queryQuota(ctx, projectId)
-
+	scope := fetchQuotaScope(query)
+	quota, err := queryQuota(ctx, projectId, scope)
 	if err != nil {
 		httperrors.GeneralServerError(w, err)
 		return
 	}
+	if scope.IsGlobal() && jsonutils.QueryBoolean(query, "scopes", false) {
+		scopes, err := queryScopedQuotas(ctx, projectId)
+		if err != nil {
+			httperrors.GeneralServerError(w, err)
+			return
+		}
+		quota.Add(scopes, "scopes")
+	}
 
 	body := jsonutils.NewDict()
 	body.Add(quota, _manager.Keyword())
@@ -177,22 +216,31 @@ func setQuotaHanlder(ctx context.Context, w http.ResponseWriter, r *http.Request
 		httperrors.InvalidInputError(w, "fail to decode body")
 		return
 	}
+	quotaBody, _ := body.Get(_manager.Keyword())
+	scope := fetchQuotaScope(quotaBody)
 	oquota := _manager.newQuota()
-	err = _manager.GetQuota(ctx, projectId, oquota)
+	err = _manager.GetScopedQuota(ctx, projectId, scope, oquota)
 	if err != nil {
 		log.Errorf("get quota fail %s", err)
 		httperrors.GeneralServerError(w, err)
 		return
 	}
+	if !scope.IsGlobal() && jsonutils.QueryBoolean(quotaBody, "reset", false) {
+		// drop the quota of the scope before setting the new one
+		oquota = _manager.newQuota()
+	}
 	oquota.Update(quota)
-	err = _manager.SetQuota(ctx, userCred, projectId, oquota)
+	err = _manager.SetScopedQuota(ctx, userCred, projectId, scope, oquota)
 	if err != nil {
 		log.Errorf("set quota fail %s", err)
 		httperrors.GeneralServerError(w, err)
 		return
 	}
+	ret := jsonutils.NewDict()
+	ret.Update(oquota.ToJSON(""))
+	ret.Update(jsonutils.Marshal(scope))
 	rbody := jsonutils.NewDict()
-	rbody.Add(oquota.ToJSON(""), _manager.Keyword())
+	rbody.Add(ret, _manager.Keyword())
 	appsrv.SendJSON(w, rbody)
 }
 
@@ -244,7 +292,8 @@ func checkQuotaHanlder(ctx context.Context, w http.ResponseWriter, r *http.Reque
 		httperrors.InvalidInputError(w, "fail to decode body")
 		return
 	}
-	used, err := _manager.CheckQuota(ctx, userCred, projectId, quota)
+	quotaBody, _ := body.Get(_manager.Keyword())
+	used, err := _manager.CheckScopedQuota(ctx, userCred, projectId, fetchQuotaScope(quotaBody), quota)
 	if err != nil {
 		httperrors.OutOfQuotaError(w, "Out of quota: %s", err)
 		return
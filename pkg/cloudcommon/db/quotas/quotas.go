@@ -2,6 +2,7 @@ package quotas
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 
 	"yunion.io/x/jsonutils"
@@ -26,6 +27,14 @@ type IQuota interface {
 	ToJSON(prefix string) jsonutils.JSONObject
 }
 
+// IScopedQuota is implemented by the quotas which can also be limited in a
+// cloudregion or a zone
+type IScopedQuota interface {
+	IQuota
+
+	FetchScopedUsage(ctx context.Context, projectId string, scope SQuotaScope) error
+}
+
 type SQuotaManager struct {
 	keyword        string
 	quotaType      reflect.Type
@@ -48,82 +57,189 @@ func (manager *SQuotaManager) newQuota() IQuota {
 	return val.Interface().(IQuota)
 }
 
+// withGlobalScope puts the global scope before the given scopes
+func withGlobalScope(scopes []SQuotaScope) []SQuotaScope {
+	ret := []SQuotaScope{{}}
+	for _, scope := range scopes {
+		if !scope.IsGlobal() {
+			ret = append(ret, scope)
+		}
+	}
+	return ret
+}
+
 func (manager *SQuotaManager) CancelPendingUsage(ctx context.Context, userCred mcclient.TokenCredential, projectId string, localUsage IQuota, cancelUsage IQuota) error {
+	return manager.CancelPendingScopedUsage(ctx, userCred, projectId, nil, localUsage, cancelUsage)
+}
+
+// CancelPendingScopedUsage cancels the pending usage of the project and of
+// the given scopes
+func (manager *SQuotaManager) CancelPendingScopedUsage(ctx context.Context, userCred mcclient.TokenCredential, projectId string, scopes []SQuotaScope, localUsage IQuota, cancelUsage IQuota) error {
 	lockman.LockClass(ctx, manager, projectId)
 	defer lockman.ReleaseClass(ctx, manager, projectId)
 
-	return manager._cancelPendingUsage(ctx, userCred, projectId, localUsage, cancelUsage)
+	for _, scope := range withGlobalScope(scopes) {
+		err := manager._cancelPendingUsage(ctx, userCred, projectId, scope, cancelUsage)
+		if err != nil {
+			return err
+		}
+	}
+	if localUsage != nil {
+		localUsage.Sub(cancelUsage)
+	}
+	return nil
 }
 
-func (manager *SQuotaManager) _cancelPendingUsage(ctx context.Context, userCred mcclient.TokenCredential, projectId string, localUsage IQuota, cancelUsage IQuota) error {
+func (manager *SQuotaManager) _cancelPendingUsage(ctx context.Context, userCred mcclient.TokenCredential, projectId string, scope SQuotaScope, cancelUsage IQuota) error {
 
 	quota := manager.newQuota()
-	err := manager.pendingStore.GetQuota(ctx, projectId, quota)
+	err := manager.pendingStore.GetQuota(ctx, projectId, scope, quota)
 	if err != nil {
 		log.Errorf("%s", err)
 		return err
 	}
 	quota.Sub(cancelUsage)
-	err = manager.pendingStore.SetQuota(ctx, userCred, projectId, quota)
+	err = manager.pendingStore.SetQuota(ctx, userCred, projectId, scope, quota)
 	if err != nil {
 		log.Errorf("%s", err)
 	}
-	if localUsage != nil {
-		localUsage.Sub(cancelUsage)
-	}
 	return err
 }
 
 func (manager *SQuotaManager) GetPendingUsage(ctx context.Context, projectId string, quota IQuota) error {
-	return manager.pendingStore.GetQuota(ctx, projectId, quota)
+	return manager.GetScopedPendingUsage(ctx, projectId, SQuotaScope{}, quota)
+}
+
+func (manager *SQuotaManager) GetScopedPendingUsage(ctx context.Context, projectId string, scope SQuotaScope, quota IQuota) error {
+	return manager.pendingStore.GetQuota(ctx, projectId, scope, quota)
+}
+
+// IQuotaDefaults is implemented by the quotas which got new dimensions, the
+// projects whose quotas were stored before take the system defaults of them
+type IQuotaDefaults interface {
+	// FetchUnsetDefaults fills the new dimensions with the system defaults
+	FetchUnsetDefaults()
 }
 
+// GetQuota returns the global quota of the project, the system defaults are
+// taken if the project has no quota set
 func (manager *SQuotaManager) GetQuota(ctx context.Context, projectId string, quota IQuota) error {
-	err := manager.persistenStore.GetQuota(ctx, projectId, quota)
+	err := manager.persistenStore.GetQuota(ctx, projectId, SQuotaScope{}, quota)
 	if err != nil {
 		return err
 	}
 	if quota.IsEmpty() {
 		quota.FetchSystemQuota()
+	} else if defaults, ok := quota.(IQuotaDefaults); ok {
+		// load the stored quota over the defaults, so that only the
+		// dimensions missing from it keep the defaults and an explicit
+		// zero stays
+		defaults.FetchUnsetDefaults()
+		return manager.persistenStore.GetQuota(ctx, projectId, SQuotaScope{}, quota)
 	}
 	return nil
 }
 
+// GetScopedQuota returns the quota of the project in the scope, which is
+// empty if not set
+func (manager *SQuotaManager) GetScopedQuota(ctx context.Context, projectId string, scope SQuotaScope, quota IQuota) error {
+	if scope.IsGlobal() {
+		return manager.GetQuota(ctx, projectId, quota)
+	}
+	return manager.persistenStore.GetQuota(ctx, projectId, scope, quota)
+}
+
+// GetQuotaScopes returns the scopes where the project has a quota
+func (manager *SQuotaManager) GetQuotaScopes(ctx context.Context, projectId string) ([]SQuotaScope, error) {
+	return manager.persistenStore.GetScopes(ctx, projectId)
+}
+
 func (manager *SQuotaManager) SetQuota(ctx context.Context, userCred mcclient.TokenCredential, projectId string, quota IQuota) error {
+	return manager.SetScopedQuota(ctx, userCred, projectId, SQuotaScope{}, quota)
+}
+
+func (manager *SQuotaManager) SetScopedQuota(ctx context.Context, userCred mcclient.TokenCredential, projectId string, scope SQuotaScope, quota IQuota) error {
 	lockman.LockClass(ctx, manager, projectId)
 	defer lockman.ReleaseClass(ctx, manager, projectId)
 
-	return manager._setQuota(ctx, userCred, projectId, quota)
+	return manager._setQuota(ctx, userCred, projectId, scope, quota)
 }
 
-func (manager *SQuotaManager) _setQuota(ctx context.Context, userCred mcclient.TokenCredential, projectId string, quota IQuota) error {
+func (manager *SQuotaManager) _setQuota(ctx context.Context, userCred mcclient.TokenCredential, projectId string, scope SQuotaScope, quota IQuota) error {
 
-	return manager.persistenStore.SetQuota(ctx, userCred, projectId, quota)
+	return manager.persistenStore.SetQuota(ctx, userCred, projectId, scope, quota)
+}
+
+// FetchUsage fills the quota with the usage of the project in the scope
+func (manager *SQuotaManager) FetchUsage(ctx context.Context, projectId string, scope SQuotaScope, quota IQuota) error {
+	if scope.IsGlobal() {
+		return quota.FetchUsage(ctx, projectId)
+	}
+	scoped, ok := quota.(IScopedQuota)
+	if !ok {
+		return fmt.Errorf("%s can not be limited in %s", manager.keyword, scope.Key())
+	}
+	return scoped.FetchScopedUsage(ctx, projectId, scope)
 }
 
 func (manager *SQuotaManager) CheckQuota(ctx context.Context, userCred mcclient.TokenCredential, projectId string, request IQuota) (IQuota, error) {
 	lockman.LockClass(ctx, manager, projectId)
 	defer lockman.ReleaseClass(ctx, manager, projectId)
 
-	return manager._checkQuota(ctx, userCred, projectId, request)
+	return manager._checkQuota(ctx, userCred, projectId, SQuotaScope{}, request)
 }
 
-func (manager *SQuotaManager) _checkQuota(ctx context.Context, userCred mcclient.TokenCredential, projectId string, request IQuota) (IQuota, error) {
+// CheckScopedQuota checks the request against the global quota of the project
+// and its quota in the scope, it returns the usage after the request
+func (manager *SQuotaManager) CheckScopedQuota(ctx context.Context, userCred mcclient.TokenCredential, projectId string, scope SQuotaScope, request IQuota) (IQuota, error) {
+	lockman.LockClass(ctx, manager, projectId)
+	defer lockman.ReleaseClass(ctx, manager, projectId)
+
+	used, err := manager._checkQuota(ctx, userCred, projectId, SQuotaScope{}, request)
+	if err != nil || scope.IsGlobal() {
+		return used, err
+	}
+	scopedUsed, err := manager._checkQuota(ctx, userCred, projectId, scope, request)
+	if err != nil {
+		return nil, err
+	}
+	if scopedUsed == nil {
+		return used, nil
+	}
+	return scopedUsed, nil
+}
+
+func (manager *SQuotaManager) _checkQuota(ctx context.Context, userCred mcclient.TokenCredential, projectId string, scope SQuotaScope, request IQuota) (IQuota, error) {
 	stored := manager.newQuota()
-	err := manager.GetQuota(ctx, projectId, stored)
+	err := manager.GetScopedQuota(ctx, projectId, scope, stored)
 	if err != nil {
 		log.Errorf("fail to get quota %s", err)
 		return nil, err
 	}
+	if !scope.IsGlobal() {
+		if stored.IsEmpty() {
+			// no limit in the scope
+			return nil, nil
+		}
+		// the dimensions not limited in the scope are bound by the global quota
+		global := manager.newQuota()
+		err = manager.GetQuota(ctx, projectId, global)
+		if err != nil {
+			log.Errorf("fail to get quota %s", err)
+			return nil, err
+		}
+		global.Update(stored)
+		stored = global
+	}
 	used := manager.newQuota()
-	err = used.FetchUsage(ctx, projectId)
+	err = manager.FetchUsage(ctx, projectId, scope, used)
 	if err != nil {
 		log.Errorf("fail to get quota usage %s", err)
 		return nil, err
 	}
 
 	pending := manager.newQuota()
-	err = manager.GetPendingUsage(ctx, projectId, pending)
+	err = manager.GetScopedPendingUsage(ctx, projectId, scope, pending)
 	if err != nil {
 		log.Errorf("fail to get pending usage %s", err)
 		return nil, err
@@ -134,6 +250,9 @@ func (manager *SQuotaManager) _checkQuota(ctx context.Context, userCred mcclient
 
 	err = used.Exceed(request, stored)
 	if err != nil {
+		if !scope.IsGlobal() {
+			return nil, fmt.Errorf("%s in %s", err, scope.Key())
+		}
 		return nil, err
 	}
 
@@ -141,23 +260,39 @@ func (manager *SQuotaManager) _checkQuota(ctx context.Context, userCred mcclient
 }
 
 func (manager *SQuotaManager) CheckSetPendingQuota(ctx context.Context, userCred mcclient.TokenCredential, projectId string, quota IQuota) error {
+	return manager.CheckSetPendingScopedQuota(ctx, userCred, projectId, nil, quota)
+}
+
+// CheckSetPendingScopedQuota checks the request against the global quota of
+// the project and its quotas in the given scopes, then records the request as
+// pending usage of all of them
+func (manager *SQuotaManager) CheckSetPendingScopedQuota(ctx context.Context, userCred mcclient.TokenCredential, projectId string, scopes []SQuotaScope, quota IQuota) error {
 	lockman.LockClass(ctx, manager, projectId)
 	defer lockman.ReleaseClass(ctx, manager, projectId)
 
-	return manager._checkSetPendingQuota(ctx, userCred, projectId, quota)
+	scopes = withGlobalScope(scopes)
+	for _, scope := range scopes {
+		_, err := manager._checkQuota(ctx, userCred, projectId, scope, quota)
+		if err != nil {
+			return err
+		}
+	}
+	for _, scope := range scopes {
+		err := manager._setPendingUsage(ctx, userCred, projectId, scope, quota)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (manager *SQuotaManager) _checkSetPendingQuota(ctx context.Context, userCred mcclient.TokenCredential, projectId string, quota IQuota) error {
-	_, err := manager._checkQuota(ctx, userCred, projectId, quota)
-	if err != nil {
-		return err
-	}
+func (manager *SQuotaManager) _setPendingUsage(ctx context.Context, userCred mcclient.TokenCredential, projectId string, scope SQuotaScope, quota IQuota) error {
 	pending := manager.newQuota()
-	err = manager.pendingStore.GetQuota(ctx, projectId, pending)
+	err := manager.pendingStore.GetQuota(ctx, projectId, scope, pending)
 	if err != nil {
 		log.Errorf("GetQuota fail %s", err)
 		return err
 	}
 	pending.Add(quota)
-	return manager.pendingStore.SetQuota(ctx, userCred, projectId, pending)
+	return manager.pendingStore.SetQuota(ctx, userCred, projectId, scope, pending)
 }
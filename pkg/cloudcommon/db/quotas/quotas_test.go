@@ -0,0 +1,303 @@
+package quotas
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+)
+
+// testUsages is the usage of the test project keyed by scope
+var testUsages = map[string]STestQuota{}
+
+type STestQuota struct {
+	Cpu          int
+	Eip          int
+	Loadbalancer int
+}
+
+func (self *STestQuota) FetchSystemQuota() {
+	self.Cpu = 10
+	self.Eip = 5
+	self.Loadbalancer = 2
+}
+
+func (self *STestQuota) FetchUnsetDefaults() {
+	self.Loadbalancer = 2
+}
+
+func (self *STestQuota) FetchUsage(ctx context.Context, projectId string) error {
+	*self = testUsages[""]
+	return nil
+}
+
+func (self *STestQuota) FetchScopedUsage(ctx context.Context, projectId string, scope SQuotaScope) error {
+	*self = testUsages[scope.Key()]
+	self.Cpu = 0
+	return nil
+}
+
+func (self *STestQuota) Update(quota IQuota) {
+	q := quota.(*STestQuota)
+	if q.Cpu > 0 {
+		self.Cpu = q.Cpu
+	}
+	if q.Eip > 0 {
+		self.Eip = q.Eip
+	}
+	if q.Loadbalancer > 0 {
+		self.Loadbalancer = q.Loadbalancer
+	}
+}
+
+func (self *STestQuota) Add(quota IQuota) {
+	q := quota.(*STestQuota)
+	self.Cpu += q.Cpu
+	self.Eip += q.Eip
+	self.Loadbalancer += q.Loadbalancer
+}
+
+func (self *STestQuota) Sub(quota IQuota) {
+	q := quota.(*STestQuota)
+	self.Cpu -= q.Cpu
+	self.Eip -= q.Eip
+	self.Loadbalancer -= q.Loadbalancer
+}
+
+func (self *STestQuota) Exceed(request IQuota, quota IQuota) error {
+	r := request.(*STestQuota)
+	q := quota.(*STestQuota)
+	if r.Cpu > 0 && self.Cpu > q.Cpu {
+		return fmt.Errorf("out of cpu quota")
+	}
+	if r.Eip > 0 && self.Eip > q.Eip {
+		return fmt.Errorf("out of eip quota")
+	}
+	if r.Loadbalancer > 0 && self.Loadbalancer > q.Loadbalancer {
+		return fmt.Errorf("out of loadbalancer quota")
+	}
+	return nil
+}
+
+func (self *STestQuota) IsEmpty() bool {
+	return self.Cpu <= 0 && self.Eip <= 0 && self.Loadbalancer <= 0
+}
+
+func (self *STestQuota) ToJSON(prefix string) jsonutils.JSONObject {
+	return jsonutils.Marshal(self)
+}
+
+const testProjectId = "p1"
+
+var (
+	testRegion = SQuotaScope{RegionId: "r1"}
+	testZone   = SQuotaScope{ZoneId: "z1"}
+)
+
+func newTestQuotaManager(t *testing.T, stored map[SQuotaScope]STestQuota, usages map[string]STestQuota) *SQuotaManager {
+	lockman.Init(lockman.NewNoopLockManager())
+	testUsages = usages
+	manager := NewQuotaManager("quotas", STestQuota{}, NewMemoryQuotaStore(), NewMemoryQuotaStore())
+	for scope, quota := range stored {
+		quota := quota
+		if err := manager.SetScopedQuota(context.Background(), nil, testProjectId, scope, &quota); err != nil {
+			t.Fatalf("set quota of %q: %s", scope.Key(), err)
+		}
+	}
+	return manager
+}
+
+func TestGetQuota(t *testing.T) {
+	cases := []struct {
+		name   string
+		stored map[SQuotaScope]STestQuota
+		// raw is the stored json of the global quota
+		raw  string
+		want STestQuota
+	}{
+		{
+			name: "not set",
+			want: STestQuota{Cpu: 10, Eip: 5, Loadbalancer: 2},
+		},
+		{
+			// stored before loadbalancer was limited
+			name: "new dimension unset",
+			raw:  `{"cpu": 4, "eip": 0}`,
+			want: STestQuota{Cpu: 4, Loadbalancer: 2},
+		},
+		{
+			name:   "explicit zero kept",
+			stored: map[SQuotaScope]STestQuota{{}: {Cpu: 4}},
+			want:   STestQuota{Cpu: 4},
+		},
+		{
+			name:   "set",
+			stored: map[SQuotaScope]STestQuota{{}: {Cpu: 4, Eip: 1, Loadbalancer: 8}},
+			want:   STestQuota{Cpu: 4, Eip: 1, Loadbalancer: 8},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			manager := newTestQuotaManager(t, c.stored, nil)
+			if len(c.raw) > 0 {
+				store := manager.persistenStore.(*SMemoryQuotaStore)
+				store.store[memoryStoreKey(testProjectId, SQuotaScope{})], _ = jsonutils.ParseString(c.raw)
+			}
+			got := STestQuota{}
+			if err := manager.GetQuota(context.Background(), testProjectId, &got); err != nil {
+				t.Fatalf("get quota: %s", err)
+			}
+			if got != c.want {
+				t.Errorf("want %#v, got %#v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	cases := []struct {
+		name    string
+		stored  map[SQuotaScope]STestQuota
+		usages  map[string]STestQuota
+		pending map[SQuotaScope]STestQuota
+		scope   SQuotaScope
+		request STestQuota
+		// wantUsed is nil when the scope has no limit
+		wantUsed *STestQuota
+		wantErr  string
+	}{
+		{
+			name:     "global",
+			usages:   map[string]STestQuota{"": {Cpu: 4, Eip: 1}},
+			request:  STestQuota{Eip: 1},
+			wantUsed: &STestQuota{Cpu: 4, Eip: 2},
+		},
+		{
+			name:    "global exceeded",
+			usages:  map[string]STestQuota{"": {Eip: 5}},
+			request: STestQuota{Eip: 1},
+			wantErr: "out of eip quota",
+		},
+		{
+			name:    "global pending counted",
+			usages:  map[string]STestQuota{"": {Eip: 3}},
+			pending: map[SQuotaScope]STestQuota{{}: {Eip: 2}},
+			request: STestQuota{Eip: 1},
+			wantErr: "out of eip quota",
+		},
+		{
+			name:    "region not limited",
+			usages:  map[string]STestQuota{"region.r1": {Eip: 100}},
+			scope:   testRegion,
+			request: STestQuota{Eip: 1},
+		},
+		{
+			name:     "region limited",
+			stored:   map[SQuotaScope]STestQuota{testRegion: {Eip: 2}},
+			usages:   map[string]STestQuota{"region.r1": {Eip: 1}},
+			scope:    testRegion,
+			request:  STestQuota{Eip: 1},
+			wantUsed: &STestQuota{Eip: 2},
+		},
+		{
+			name:    "region exceeded",
+			stored:  map[SQuotaScope]STestQuota{testRegion: {Eip: 2}},
+			usages:  map[string]STestQuota{"region.r1": {Eip: 1}},
+			pending: map[SQuotaScope]STestQuota{testRegion: {Eip: 1}},
+			scope:   testRegion,
+			request: STestQuota{Eip: 1},
+			wantErr: "out of eip quota in region.r1",
+		},
+		{
+			// loadbalancer is not set in the region, the global quota applies
+			name:    "region merged with global",
+			stored:  map[SQuotaScope]STestQuota{{}: {Cpu: 10, Eip: 5, Loadbalancer: 3}, testRegion: {Eip: 2}},
+			usages:  map[string]STestQuota{"region.r1": {Loadbalancer: 3}},
+			scope:   testRegion,
+			request: STestQuota{Loadbalancer: 1},
+			wantErr: "out of loadbalancer quota in region.r1",
+		},
+		{
+			name:    "zone exceeded",
+			stored:  map[SQuotaScope]STestQuota{testZone: {Loadbalancer: 1}},
+			usages:  map[string]STestQuota{"zone.z1": {Loadbalancer: 1}},
+			scope:   testZone,
+			request: STestQuota{Loadbalancer: 1},
+			wantErr: "out of loadbalancer quota in zone.z1",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			manager := newTestQuotaManager(t, c.stored, c.usages)
+			for scope, quota := range c.pending {
+				quota := quota
+				manager.pendingStore.SetQuota(ctx, nil, testProjectId, scope, &quota)
+			}
+			used, err := manager._checkQuota(ctx, nil, testProjectId, c.scope, &c.request)
+			if len(c.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("want error %q, got %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if c.wantUsed == nil {
+				if used != nil {
+					t.Errorf("want no limit, got used %#v", used)
+				}
+				return
+			}
+			if used == nil || *used.(*STestQuota) != *c.wantUsed {
+				t.Errorf("want used %#v, got %#v", c.wantUsed, used)
+			}
+		})
+	}
+}
+
+func TestPendingScopedQuota(t *testing.T) {
+	ctx := context.Background()
+	manager := newTestQuotaManager(t,
+		map[SQuotaScope]STestQuota{testRegion: {Eip: 2}},
+		map[string]STestQuota{"": {Eip: 1}, "region.r1": {Eip: 1}},
+	)
+	scopes := []SQuotaScope{testRegion}
+	request := &STestQuota{Eip: 1}
+	if err := manager.CheckSetPendingScopedQuota(ctx, nil, testProjectId, scopes, request); err != nil {
+		t.Fatalf("first request: %s", err)
+	}
+	for _, scope := range []SQuotaScope{{}, testRegion} {
+		pending := STestQuota{}
+		manager.GetScopedPendingUsage(ctx, testProjectId, scope, &pending)
+		if pending.Eip != 1 {
+			t.Errorf("want 1 pending eip in %q, got %d", scope.Key(), pending.Eip)
+		}
+	}
+	// the region quota is used up by the pending one
+	if err := manager.CheckSetPendingScopedQuota(ctx, nil, testProjectId, scopes, request); err == nil {
+		t.Fatalf("second request: want error")
+	}
+	// the zone is not limited and is only bound by the global quota
+	zoneScopes := []SQuotaScope{testZone}
+	if err := manager.CheckSetPendingScopedQuota(ctx, nil, testProjectId, zoneScopes, request); err != nil {
+		t.Fatalf("zone request: %s", err)
+	}
+
+	if err := manager.CancelPendingScopedUsage(ctx, nil, testProjectId, scopes, nil, request); err != nil {
+		t.Fatalf("cancel: %s", err)
+	}
+	want := map[SQuotaScope]int{{}: 1, testRegion: 0, testZone: 1}
+	for scope, eip := range want {
+		pending := STestQuota{}
+		manager.GetScopedPendingUsage(ctx, testProjectId, scope, &pending)
+		if pending.Eip != eip {
+			t.Errorf("want %d pending eip in %q after cancel, got %d", eip, scope.Key(), pending.Eip)
+		}
+	}
+}
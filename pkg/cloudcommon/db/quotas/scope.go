@@ -0,0 +1,46 @@
+package quotas
+
+import (
+	"strings"
+)
+
+const (
+	QUOTA_SCOPE_REGION = "region"
+	QUOTA_SCOPE_ZONE   = "zone"
+)
+
+// SQuotaScope narrows the quota of a project down to a cloudregion or a zone,
+// the zero value is the global quota of the project
+type SQuotaScope struct {
+	RegionId string `json:"region_id,omitempty"`
+	ZoneId   string `json:"zone_id,omitempty"`
+}
+
+func (scope SQuotaScope) IsGlobal() bool {
+	return len(scope.RegionId) == 0 && len(scope.ZoneId) == 0
+}
+
+// Key identifies the scope in the quota stores, e.g. region.<region_id>
+func (scope SQuotaScope) Key() string {
+	if len(scope.ZoneId) > 0 {
+		return KeyName(QUOTA_SCOPE_ZONE, scope.ZoneId)
+	}
+	if len(scope.RegionId) > 0 {
+		return KeyName(QUOTA_SCOPE_REGION, scope.RegionId)
+	}
+	return ""
+}
+
+func ParseQuotaScope(key string) (SQuotaScope, bool) {
+	parts := strings.SplitN(key, ".", 2)
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return SQuotaScope{}, false
+	}
+	switch parts[0] {
+	case QUOTA_SCOPE_REGION:
+		return SQuotaScope{RegionId: parts[1]}, true
+	case QUOTA_SCOPE_ZONE:
+		return SQuotaScope{ZoneId: parts[1]}, true
+	}
+	return SQuotaScope{}, false
+}
@@ -0,0 +1,42 @@
+package quotas
+
+import (
+	"testing"
+)
+
+func TestQuotaScopeKey(t *testing.T) {
+	cases := []struct {
+		scope SQuotaScope
+		key   string
+	}{
+		{SQuotaScope{}, ""},
+		{SQuotaScope{RegionId: "r1"}, "region.r1"},
+		{SQuotaScope{ZoneId: "z1"}, "zone.z1"},
+		{SQuotaScope{RegionId: "r1", ZoneId: "z1"}, "zone.z1"},
+	}
+	for _, c := range cases {
+		if got := c.scope.Key(); got != c.key {
+			t.Errorf("%#v: want key %q, got %q", c.scope, c.key, got)
+		}
+	}
+}
+
+func TestParseQuotaScope(t *testing.T) {
+	cases := []struct {
+		key   string
+		scope SQuotaScope
+		ok    bool
+	}{
+		{"region.r1", SQuotaScope{RegionId: "r1"}, true},
+		{"zone.z1", SQuotaScope{ZoneId: "z1"}, true},
+		{"zone.", SQuotaScope{}, false},
+		{"host.h1", SQuotaScope{}, false},
+		{"", SQuotaScope{}, false},
+	}
+	for _, c := range cases {
+		scope, ok := ParseQuotaScope(c.key)
+		if ok != c.ok || scope != c.scope {
+			t.Errorf("%q: want %#v %v, got %#v %v", c.key, c.scope, c.ok, scope, ok)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package quotas
 
 import (
 	"context"
+	"sort"
+	"strings"
 
 	"yunion.io/x/jsonutils"
 
@@ -10,8 +12,10 @@ import (
 )
 
 type IQuotaStore interface {
-	GetQuota(ctx context.Context, tenantId string, quota IQuota) error
-	SetQuota(ctx context.Context, userCred mcclient.TokenCredential, tenantId string, quota IQuota) error
+	GetQuota(ctx context.Context, tenantId string, scope SQuotaScope, quota IQuota) error
+	SetQuota(ctx context.Context, userCred mcclient.TokenCredential, tenantId string, scope SQuotaScope, quota IQuota) error
+	// GetScopes returns the scopes of the tenant which have a quota
+	GetScopes(ctx context.Context, tenantId string) ([]SQuotaScope, error)
 }
 
 type SMemoryQuotaStore struct {
@@ -24,23 +28,42 @@ func NewMemoryQuotaStore() *SMemoryQuotaStore {
 	}
 }
 
-func (self *SMemoryQuotaStore) GetQuota(ctx context.Context, tenantId string, quota IQuota) error {
-	json, ok := self.store[tenantId]
+func memoryStoreKey(tenantId string, scope SQuotaScope) string {
+	return KeyName(tenantId, scope.Key())
+}
+
+func (self *SMemoryQuotaStore) GetQuota(ctx context.Context, tenantId string, scope SQuotaScope, quota IQuota) error {
+	json, ok := self.store[memoryStoreKey(tenantId, scope)]
 	if ok {
 		return json.Unmarshal(quota)
 	}
 	return nil
 }
 
-func (self *SMemoryQuotaStore) SetQuota(ctx context.Context, userCred mcclient.TokenCredential, tenantId string, quota IQuota) error {
+func (self *SMemoryQuotaStore) SetQuota(ctx context.Context, userCred mcclient.TokenCredential, tenantId string, scope SQuotaScope, quota IQuota) error {
+	key := memoryStoreKey(tenantId, scope)
 	if quota.IsEmpty() {
-		delete(self.store, tenantId)
+		delete(self.store, key)
 	} else {
-		self.store[tenantId] = jsonutils.Marshal(quota)
+		self.store[key] = jsonutils.Marshal(quota)
 	}
 	return nil
 }
 
+func (self *SMemoryQuotaStore) GetScopes(ctx context.Context, tenantId string) ([]SQuotaScope, error) {
+	scopes := make([]SQuotaScope, 0)
+	for key := range self.store {
+		if !strings.HasPrefix(key, tenantId+".") {
+			continue
+		}
+		if scope, ok := ParseQuotaScope(key[len(tenantId)+1:]); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	sortScopes(scopes)
+	return scopes, nil
+}
+
 type SDBQuotaStore struct {
 }
 
@@ -48,12 +71,12 @@ func NewDBQuotaStore() *SDBQuotaStore {
 	return &SDBQuotaStore{}
 }
 
-func (store *SDBQuotaStore) GetQuota(ctx context.Context, tenantId string, quota IQuota) error {
+func (store *SDBQuotaStore) GetQuota(ctx context.Context, tenantId string, scope SQuotaScope, quota IQuota) error {
 	tenant, err := db.TenantCacheManager.FetchTenantById(ctx, tenantId)
 	if err != nil {
 		return err
 	}
-	quotaStr := tenant.GetMetadata(METADATA_KEY, nil)
+	quotaStr := tenant.GetMetadata(KeyName(METADATA_KEY, scope.Key()), nil)
 	quotaJson, _ := jsonutils.ParseString(quotaStr)
 	if quotaJson != nil {
 		return quotaJson.Unmarshal(quota)
@@ -61,11 +84,42 @@ func (store *SDBQuotaStore) GetQuota(ctx context.Context, tenantId string, quota
 	return nil
 }
 
-func (store *SDBQuotaStore) SetQuota(ctx context.Context, userCred mcclient.TokenCredential, tenantId string, quota IQuota) error {
+func (store *SDBQuotaStore) SetQuota(ctx context.Context, userCred mcclient.TokenCredential, tenantId string, scope SQuotaScope, quota IQuota) error {
 	tenant, err := db.TenantCacheManager.FetchTenantById(ctx, tenantId)
 	if err != nil {
 		return err
 	}
+	key := KeyName(METADATA_KEY, scope.Key())
+	if !scope.IsGlobal() && quota.IsEmpty() {
+		// clear the quota of the scope
+		return tenant.SetMetadata(ctx, key, "", userCred)
+	}
 	quotaJson := jsonutils.Marshal(quota)
-	return tenant.SetMetadata(ctx, METADATA_KEY, quotaJson, userCred)
+	return tenant.SetMetadata(ctx, key, quotaJson, userCred)
+}
+
+func (store *SDBQuotaStore) GetScopes(ctx context.Context, tenantId string) ([]SQuotaScope, error) {
+	tenant, err := db.TenantCacheManager.FetchTenantById(ctx, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := tenant.GetAllMetadata(nil)
+	if err != nil {
+		return nil, err
+	}
+	scopes := make([]SQuotaScope, 0)
+	for key := range metadata {
+		if !strings.HasPrefix(key, METADATA_KEY+".") {
+			continue
+		}
+		if scope, ok := ParseQuotaScope(key[len(METADATA_KEY)+1:]); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	sortScopes(scopes)
+	return scopes, nil
+}
+
+func sortScopes(scopes []SQuotaScope) {
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].Key() < scopes[j].Key() })
 }
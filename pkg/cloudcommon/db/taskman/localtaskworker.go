@@ -24,7 +24,14 @@ func Error2TaskData(err error) jsonutils.JSONObject {
 }
 
 func LocalTaskRun(task ITask, proc func() (jsonutils.JSONObject, error)) {
-	localTaskWorkerMan.Run(func() {
+	LocalTaskRunWithWorkers(task, proc, localTaskWorkerMan)
+}
+
+// LocalTaskRunWithWorkers runs proc with the specified workers, which is
+// useful for long running procedures that should not occupy the shared
+// local task workers
+func LocalTaskRunWithWorkers(task ITask, proc func() (jsonutils.JSONObject, error), wm *appsrv.SWorkerManager) {
+	wm.Run(func() {
 
 		log.Debugf("XXXXXXXXXXXXXXXXXXLOCAL TASK RUN STARTXXXXXXXXXXXXXXXXX")
 		defer log.Debugf("XXXXXXXXXXXXXXXXXXLOCAL TASK RUN END  XXXXXXXXXXXXXXXXX")
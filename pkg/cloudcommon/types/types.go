@@ -84,3 +84,14 @@ type SIPMIBootFlags struct {
 	Dev string `json:"dev"`
 	Sol *bool  `json:"sol"`
 }
+
+type SNumaNode struct {
+	NodeId    int   `json:"node_id"`
+	Cpus      []int `json:"cpus"`
+	MemSizeMb int   `json:"mem_size_mb"`
+
+	// free memory usable by guests and free hugepages in MB, refreshed by
+	// the host on every ping
+	FreeMemSizeMb      int `json:"free_mem_size_mb"`
+	FreeHugepageSizeMb int `json:"free_hugepage_size_mb"`
+}
@@ -0,0 +1,44 @@
+package cloudprovider
+
+import (
+	"time"
+)
+
+type TBucketACLType string
+
+const (
+	ACLDefault = TBucketACLType("default")
+
+	ACLPrivate         = TBucketACLType("private")
+	ACLAuthRead        = TBucketACLType("authenticated-read")
+	ACLPublicRead      = TBucketACLType("public-read")
+	ACLPublicReadWrite = TBucketACLType("public-read-write")
+)
+
+type SCloudObject struct {
+	Key          string
+	SizeBytes    int64
+	StorageClass string
+	ETag         string
+	LastModified time.Time
+}
+
+type SListObjectResult struct {
+	Objects        []SCloudObject
+	NextMarker     string
+	CommonPrefixes []string
+	IsTruncated    bool
+}
+
+type ICloudBucket interface {
+	ICloudResource
+
+	GetAcl() TBucketACLType
+	GetLocation() string
+	GetStorageClass() string
+	GetCreateAt() time.Time
+
+	SetAcl(acl TBucketACLType) error
+
+	ListObjects(prefix string, marker string, delimiter string, maxCount int) (SListObjectResult, error)
+}
@@ -0,0 +1,48 @@
+package cloudprovider
+
+import (
+	"time"
+)
+
+type ICloudDBInstance interface {
+	ICloudResource
+	IBillingResource
+
+	GetEngine() string
+	GetEngineVersion() string
+	// 实例规格, 如 rds.mysql.s2.large, db.t2.micro
+	GetInstanceType() string
+	GetVcpuCount() int
+	GetVmemSizeMB() int
+	GetDiskSizeGB() int
+	// 实例系列, 如 basic, ha, finance
+	GetCategory() string
+	GetStorageType() string
+	GetCreateTime() time.Time
+
+	GetPort() int
+	GetConnectionStr() string
+	GetInternalConnectionStr() string
+
+	GetVpcId() string
+	GetNetworkId() string
+	GetZoneId() string
+
+	GetIDBInstanceBackups() ([]ICloudDBInstanceBackup, error)
+
+	Start() error
+	Stop() error
+	Reboot() error
+	Delete() error
+}
+
+type ICloudDBInstanceBackup interface {
+	ICloudResource
+
+	GetDBInstanceId() string
+	GetStartTime() time.Time
+	GetEndTime() time.Time
+	GetBackupSizeMb() int
+	// automated 或 manual
+	GetBackupMode() string
+}
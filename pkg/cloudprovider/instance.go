@@ -27,6 +27,9 @@ type SManagedVMCreateConfig struct {
 	MemoryMB            int
 	ExternalNetworkId   string
 	IpAddr              string
+	IpMask              int8 // 需自行配置网络的平台(如ESXi)使用
+	Gateway             string
+	Dns                 string
 	Description         string
 	SysDisk             SDiskInfo
 	DataDisks           []SDiskInfo
@@ -45,4 +45,12 @@ type SLoadbalancerListenerRule struct {
 	Path             string
 	BackendGroupID   string
 	BackendGroupType string
+
+	Priority          int
+	Action            string
+	RedirectCode      int
+	RedirectScheme    string
+	RedirectHost      string
+	RedirectPath      string
+	FixedResponseCode int
 }
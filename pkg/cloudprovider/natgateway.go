@@ -0,0 +1,29 @@
+package cloudprovider
+
+type SNatGatewayCreateOptions struct {
+	Name string
+	Desc string
+
+	NatSpec   string // api.NAT_SPEC_*
+	NetworkId string // 所在子网外部ID, 华为云和AWS必需
+	EipId     string // 绑定EIP外部ID, AWS必需
+	EipAddr   string // 绑定EIP地址, 腾讯云未指定时自动申请
+}
+
+type SNatSRule struct {
+	Name         string
+	NetworkID    string
+	SourceCIDR   string
+	ExternalIP   string
+	ExternalIPID string
+}
+
+type SNatDRule struct {
+	Name         string
+	Protocol     string
+	InternalIP   string
+	InternalPort int
+	ExternalIP   string
+	ExternalIPID string
+	ExternalPort int
+}
@@ -80,6 +80,14 @@ type ICloudRegion interface {
 
 	GetSkus(zoneId string) ([]ICloudSku, error)
 
+	GetIBuckets() ([]ICloudBucket, error)
+	GetIBucketById(name string) (ICloudBucket, error)
+	CreateIBucket(name string, storageClassStr string, acl string) error
+	DeleteIBucket(name string) error
+
+	GetIDBInstances() ([]ICloudDBInstance, error)
+	GetIDBInstanceById(instanceId string) (ICloudDBInstance, error)
+
 	GetProvider() string
 }
 
@@ -300,6 +308,9 @@ type ICloudRouteTable interface {
 	GetVpcId() string
 	GetType() string
 	GetIRoutes() ([]ICloudRoute, error)
+
+	CreateRoute(route SRouteSet) error
+	RemoveRoute(route SRouteSet) error
 }
 
 type ICloudRoute interface {
@@ -363,6 +374,11 @@ type ICloudVpc interface {
 	GetIWires() ([]ICloudWire, error)
 	GetISecurityGroups() ([]ICloudSecurityGroup, error)
 	GetIRouteTables() ([]ICloudRouteTable, error)
+	GetINatGateways() ([]ICloudNatGateway, error)
+	CreateINatGateway(opts *SNatGatewayCreateOptions) (ICloudNatGateway, error)
+	GetIVpcPeerings() ([]ICloudVpcPeering, error)
+	GetIVpcPeeringById(id string) (ICloudVpcPeering, error)
+	CreateIVpcPeering(opts *SVpcPeeringCreateOptions) (ICloudVpcPeering, error)
 
 	GetManagerId() string
 
@@ -401,6 +417,49 @@ type ICloudNetwork interface {
 	GetAllocTimeoutSeconds() int
 }
 
+type ICloudNatGateway interface {
+	ICloudResource
+	IBillingResource
+
+	GetNatSpec() string
+
+	GetINatSEntries() ([]ICloudNatSEntry, error)
+	GetINatDEntries() ([]ICloudNatDEntry, error)
+
+	GetINatSEntryById(id string) (ICloudNatSEntry, error)
+	GetINatDEntryById(id string) (ICloudNatDEntry, error)
+
+	CreateINatSEntry(rule SNatSRule) (ICloudNatSEntry, error)
+	CreateINatDEntry(rule SNatDRule) (ICloudNatDEntry, error)
+
+	Delete() error
+}
+
+type ICloudNatEntry interface {
+	ICloudResource
+
+	Delete() error
+}
+
+type ICloudNatSEntry interface {
+	ICloudNatEntry
+
+	GetIP() string
+	GetSourceCIDR() string
+	GetNetworkId() string
+}
+
+type ICloudNatDEntry interface {
+	ICloudNatEntry
+
+	GetIpProtocol() string
+	GetExternalIp() string
+	GetExternalPort() int
+
+	GetInternalIp() string
+	GetInternalPort() int
+}
+
 type ICloudHostNetInterface interface {
 	GetDevice() string
 	GetDriver() string
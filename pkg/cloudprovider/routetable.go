@@ -0,0 +1,7 @@
+package cloudprovider
+
+type SRouteSet struct {
+	Destination string // 目标网段
+	NextHopType string // 下一跳类型, 取值参考 api.ROUTE_NEXT_HOP_TYPE_*
+	NextHop     string // 下一跳资源外部ID
+}
@@ -0,0 +1,24 @@
+package cloudprovider
+
+type SVpcPeeringCreateOptions struct {
+	Name string
+	Desc string
+
+	PeerVpcId     string // 对端VPC外部ID
+	PeerAccountId string // 对端账号ID, 为空表示同账号
+	PeerRegionId  string // 对端区域ID, 为空表示同区域
+}
+
+type ICloudVpcPeering interface {
+	ICloudResource
+
+	GetDescription() string
+	GetVpcId() string
+	GetPeerVpcId() string
+	GetPeerAccountId() string
+	GetPeerRegionId() string
+
+	// 跨账号对等连接需由对端账号接受
+	Accept() error
+	Delete() error
+}
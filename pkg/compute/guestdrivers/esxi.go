@@ -12,6 +12,7 @@ import (
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
 	"yunion.io/x/onecloud/pkg/compute/models"
 	"yunion.io/x/onecloud/pkg/compute/options"
+	"yunion.io/x/onecloud/pkg/httperrors"
 	"yunion.io/x/onecloud/pkg/mcclient"
 	"yunion.io/x/onecloud/pkg/util/billing"
 	"yunion.io/x/onecloud/pkg/util/httputils"
@@ -52,8 +53,9 @@ func (self *SESXiGuestDriver) GetDetachDiskStatus() ([]string, error) {
 	return []string{models.VM_READY, models.VM_RUNNING}, nil
 }
 
+// 磁盘随虚拟机存放, 卸载即删除, 不存在可挂载的独立磁盘
 func (self *SESXiGuestDriver) GetAttachDiskStatus() ([]string, error) {
-	return []string{models.VM_READY, models.VM_RUNNING}, nil
+	return nil, httperrors.NewUnsupportOperationError("ESXi guest not support attach detached disk")
 }
 
 func (self *SESXiGuestDriver) GetChangeConfigStatus() ([]string, error) {
@@ -115,8 +117,16 @@ func (self *SESXiGuestDriver) RequestDeployGuestOnHost(ctx context.Context, gues
 	}
 	log.Debugf("RequestDeployGuestOnHost: %s", config)
 
-	if !host.IsEsxiAgentReady() {
-		return fmt.Errorf("No ESXi agent host")
+	action, err := config.GetString("action")
+	if err != nil {
+		return err
+	}
+
+	// 创建及重装系统通过vCenter从模板克隆, 部署在无ESXi agent时通过vCenter自定义规范完成
+	if action != "deploy" || !host.IsEsxiAgentReady() {
+		desc := self.SManagedVirtualizedGuestDriver.GetJsonDescAtHost(ctx, task.GetUserCred(), guest, host)
+		config.Set("desc", desc)
+		return self.remoteDeployGuestOnHost(ctx, guest, host, task, action, config)
 	}
 
 	diskCat := guest.CategorizeDisks()
@@ -0,0 +1,104 @@
+package guestdrivers
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/pkg/utils"
+
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/ansible"
+)
+
+type SGoogleGuestDriver struct {
+	SManagedVirtualizedGuestDriver
+}
+
+func init() {
+	driver := SGoogleGuestDriver{}
+	models.RegisterGuestDriver(&driver)
+}
+
+func (self *SGoogleGuestDriver) GetHypervisor() string {
+	return models.HYPERVISOR_GOOGLE
+}
+
+func (self *SGoogleGuestDriver) GetDefaultSysDiskBackend() string {
+	return models.STORAGE_GOOGLE_PD_STANDARD
+}
+
+func (self *SGoogleGuestDriver) GetMinimalSysDiskSizeGb() int {
+	return 10
+}
+
+func (self *SGoogleGuestDriver) GetStorageTypes() []string {
+	return []string{models.STORAGE_GOOGLE_PD_STANDARD, models.STORAGE_GOOGLE_PD_SSD}
+}
+
+func (self *SGoogleGuestDriver) ChooseHostStorage(host *models.SHost, backend string) *models.SStorage {
+	storages := host.GetAttachedStorages("")
+	for i := 0; i < len(storages); i += 1 {
+		if storages[i].StorageType == backend {
+			return &storages[i]
+		}
+	}
+
+	for _, stype := range self.GetStorageTypes() {
+		for i := 0; i < len(storages); i += 1 {
+			if storages[i].StorageType == stype {
+				return &storages[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (self *SGoogleGuestDriver) GetDetachDiskStatus() ([]string, error) {
+	return []string{models.VM_READY, models.VM_RUNNING}, nil
+}
+
+func (self *SGoogleGuestDriver) GetAttachDiskStatus() ([]string, error) {
+	return []string{models.VM_READY, models.VM_RUNNING}, nil
+}
+
+func (self *SGoogleGuestDriver) GetRebuildRootStatus() ([]string, error) {
+	return []string{}, fmt.Errorf("Google not support rebuild root")
+}
+
+// 调整机型需要实例处于关机状态
+func (self *SGoogleGuestDriver) GetChangeConfigStatus() ([]string, error) {
+	return []string{models.VM_READY}, nil
+}
+
+func (self *SGoogleGuestDriver) GetDeployStatus() ([]string, error) {
+	return []string{models.VM_READY, models.VM_RUNNING}, nil
+}
+
+func (self *SGoogleGuestDriver) RequestDetachDisk(ctx context.Context, guest *models.SGuest, task taskman.ITask) error {
+	return guest.StartSyncTask(ctx, task.GetUserCred(), false, task.GetTaskId())
+}
+
+func (self *SGoogleGuestDriver) ValidateResizeDisk(guest *models.SGuest, disk *models.SDisk, storage *models.SStorage) error {
+	if !utils.IsInStringArray(guest.Status, []string{models.VM_RUNNING, models.VM_READY}) {
+		return fmt.Errorf("Cannot resize disk when guest in status %s", guest.Status)
+	}
+	if !utils.IsInStringArray(storage.StorageType, self.GetStorageTypes()) {
+		return fmt.Errorf("Cannot resize disk with unsupported volumes type %s", storage.StorageType)
+	}
+	return nil
+}
+
+func (self *SGoogleGuestDriver) GetGuestInitialStateAfterCreate() string {
+	return models.VM_RUNNING
+}
+
+func (self *SGoogleGuestDriver) GetGuestInitialStateAfterRebuild() string {
+	return models.VM_RUNNING
+}
+
+// 谷歌云不支持设置密码, 通过ssh-keys元数据为该用户注入公钥
+func (self *SGoogleGuestDriver) GetLinuxDefaultAccount(desc cloudprovider.SManagedVMCreateConfig) string {
+	return ansible.PUBLIC_CLOUD_ANSIBLE_USER
+}
@@ -41,6 +41,9 @@ func (self *SManagedVirtualizedGuestDriver) GetJsonDescAtHost(ctx context.Contex
 		net := nics[0].GetNetwork()
 		config.ExternalNetworkId = net.ExternalId
 		config.IpAddr = nics[0].IpAddr
+		config.IpMask = net.GuestIpMask
+		config.Gateway = net.GuestGateway
+		config.Dns = net.GuestDns
 	}
 
 	disks := guest.GetDisks()
@@ -153,13 +156,17 @@ func (self *SManagedVirtualizedGuestDriver) RequestDeployGuestOnHost(ctx context
 	}
 	log.Debugf("RequestDeployGuestOnHost: %s", config)
 
-	desc := cloudprovider.SManagedVMCreateConfig{}
-	if err := desc.GetConfig(config); err != nil {
+	action, err := config.GetString("action")
+	if err != nil {
 		return err
 	}
 
-	action, err := config.GetString("action")
-	if err != nil {
+	return self.remoteDeployGuestOnHost(ctx, guest, host, task, action, config)
+}
+
+func (self *SManagedVirtualizedGuestDriver) remoteDeployGuestOnHost(ctx context.Context, guest *models.SGuest, host *models.SHost, task taskman.ITask, action string, config *jsonutils.JSONDict) error {
+	desc := cloudprovider.SManagedVMCreateConfig{}
+	if err := desc.GetConfig(config); err != nil {
 		return err
 	}
 
@@ -0,0 +1,40 @@
+package hostdrivers
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/httperrors"
+)
+
+type SGoogleHostDriver struct {
+	SManagedVirtualizationHostDriver
+}
+
+func init() {
+	driver := SGoogleHostDriver{}
+	models.RegisterHostDriver(&driver)
+}
+
+func (self *SGoogleHostDriver) GetHostType() string {
+	return models.HOST_TYPE_GOOGLE
+}
+
+func (self *SGoogleHostDriver) ValidateAttachStorage(host *models.SHost, storage *models.SStorage, data *jsonutils.JSONDict) error {
+	return httperrors.NewUnsupportOperationError("Not support attach storage for %s host", self.GetHostType())
+}
+
+// https://cloud.google.com/compute/docs/disks/#introduction
+func (self *SGoogleHostDriver) ValidateDiskSize(storage *models.SStorage, sizeGb int) error {
+	switch storage.StorageType {
+	case models.STORAGE_GOOGLE_PD_STANDARD, models.STORAGE_GOOGLE_PD_SSD:
+		if sizeGb < 10 || sizeGb > 65536 {
+			return fmt.Errorf("The %s disk size must be in the range of 10G ~ 65536GB", storage.StorageType)
+		}
+	default:
+		return fmt.Errorf("Not support create %s disk", storage.StorageType)
+	}
+	return nil
+}
@@ -0,0 +1,400 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/compare"
+	"yunion.io/x/sqlchemy"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/util/choices"
+)
+
+// bucket名称需全局唯一, 仅允许小写字母、数字和中划线
+var bucketNameReg = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,61}[a-z0-9]$`)
+
+var bucketAclChoices = choices.NewChoices(
+	string(cloudprovider.ACLPrivate),
+	string(cloudprovider.ACLAuthRead),
+	string(cloudprovider.ACLPublicRead),
+	string(cloudprovider.ACLPublicReadWrite),
+)
+
+type SBucketManager struct {
+	db.SVirtualResourceBaseManager
+}
+
+var BucketManager *SBucketManager
+
+func init() {
+	BucketManager = &SBucketManager{
+		SVirtualResourceBaseManager: db.NewVirtualResourceBaseManager(
+			SBucket{},
+			"buckets_tbl",
+			"bucket",
+			"buckets",
+		),
+	}
+}
+
+type SBucket struct {
+	db.SVirtualResourceBase
+	SManagedResourceBase
+
+	CloudregionId string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"required"`
+	StorageClass  string `width:"36" charset:"ascii" nullable:"true" list:"user" create:"optional"`
+	Location      string `width:"36" charset:"ascii" nullable:"true" list:"user"`
+	Acl           string `width:"36" charset:"ascii" nullable:"true" list:"user" create:"optional"`
+}
+
+func (manager *SBucketManager) ListItemFilter(ctx context.Context, q *sqlchemy.SQuery, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*sqlchemy.SQuery, error) {
+	var err error
+	q, err = managedResourceFilterByAccount(q, query, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	q = managedResourceFilterByCloudType(q, query, "", nil)
+
+	q, err = manager.SVirtualResourceBaseManager.ListItemFilter(ctx, q, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	data := query.(*jsonutils.JSONDict)
+	q, err = validators.ApplyModelFilters(q, data, []*validators.ModelFilterOptions{
+		{Key: "cloudregion", ModelKeyword: "cloudregion", ProjectId: userCred.GetProjectId()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (manager *SBucketManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	name, _ := data.GetString("name")
+	if !bucketNameReg.MatchString(name) {
+		return nil, httperrors.NewInputParameterError("invalid bucket name %s", name)
+	}
+	regionV := validators.NewModelIdOrNameValidator("cloudregion", "cloudregion", ownerProjId)
+	managerV := validators.NewModelIdOrNameValidator("manager", "cloudprovider", ownerProjId)
+	keyV := map[string]validators.IValidator{
+		"cloudregion": regionV,
+		"manager":     managerV,
+		"acl":         validators.NewStringChoicesValidator("acl", bucketAclChoices).Optional(true),
+	}
+	for _, v := range keyV {
+		if err := v.Validate(data); err != nil {
+			return nil, err
+		}
+	}
+	region := regionV.Model.(*SCloudregion)
+	provider := managerV.Model.(*SCloudprovider)
+	if CloudproviderRegionManager.FetchByIds(provider.Id, region.Id) == nil {
+		return nil, httperrors.NewInputParameterError("cloudprovider %s not available in region %s", provider.Name, region.Name)
+	}
+	q := manager.Query().Equals("manager_id", provider.Id).Equals("name", name)
+	if q.Count() > 0 {
+		return nil, httperrors.NewDuplicateNameError("bucket", name)
+	}
+	return manager.SVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+}
+
+func (self *SBucket) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	self.SVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
+
+	self.SetStatus(userCred, api.BUCKET_STATUS_CREATING, "")
+	if err := self.StartBucketCreateTask(ctx, userCred, ""); err != nil {
+		log.Errorf("Failed to create bucket error: %v", err)
+	}
+}
+
+func (self *SBucket) StartBucketCreateTask(ctx context.Context, userCred mcclient.TokenCredential, parentTaskId string) error {
+	task, err := taskman.TaskManager.NewTask(ctx, "BucketCreateTask", self, userCred, nil, parentTaskId, "", nil)
+	if err != nil {
+		return err
+	}
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (self *SBucket) GetRegion() *SCloudregion {
+	return CloudregionManager.FetchRegionById(self.CloudregionId)
+}
+
+func (self *SBucket) GetIRegion() (cloudprovider.ICloudRegion, error) {
+	provider, err := self.GetDriver()
+	if err != nil {
+		return nil, err
+	}
+	region := self.GetRegion()
+	if region == nil {
+		return nil, fmt.Errorf("fail to find region for bucket %s", self.Name)
+	}
+	return provider.GetIRegionById(region.GetExternalId())
+}
+
+func (self *SBucket) GetIBucket() (cloudprovider.ICloudBucket, error) {
+	iregion, err := self.GetIRegion()
+	if err != nil {
+		return nil, err
+	}
+	return iregion.GetIBucketById(self.ExternalId)
+}
+
+func (self *SBucket) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSONDict {
+	info := MakeCloudProviderInfo(self.GetRegion(), nil, self.GetCloudprovider())
+	extra.Update(jsonutils.Marshal(&info))
+	return extra
+}
+
+func (self *SBucket) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
+	extra := self.SVirtualResourceBase.GetCustomizeColumns(ctx, userCred, query)
+	return self.getMoreDetails(extra)
+}
+
+func (self *SBucket) GetExtraDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	extra, err := self.SVirtualResourceBase.GetExtraDetails(ctx, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	return self.getMoreDetails(extra), nil
+}
+
+func (self *SBucket) AllowGetDetailsObjects(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowGetSpec(userCred, self, "objects")
+}
+
+func (self *SBucket) GetDetailsObjects(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if self.Status != api.BUCKET_STATUS_READY {
+		return nil, httperrors.NewInvalidStatusError("cannot list objects in status %s", self.Status)
+	}
+	prefix, _ := query.GetString("prefix")
+	marker, _ := query.GetString("marker")
+	delimiter, _ := query.GetString("delimiter")
+	limit, _ := query.Int("limit")
+	if limit <= 0 || limit > 1000 {
+		limit = 50
+	}
+	ibucket, err := self.GetIBucket()
+	if err != nil {
+		return nil, httperrors.NewGeneralError(err)
+	}
+	result, err := ibucket.ListObjects(prefix, marker, delimiter, int(limit))
+	if err != nil {
+		return nil, httperrors.NewGeneralError(err)
+	}
+	return jsonutils.Marshal(result), nil
+}
+
+func (self *SBucket) AllowPerformAcl(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, self, "acl")
+}
+
+func (self *SBucket) PerformAcl(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if self.Status != api.BUCKET_STATUS_READY {
+		return nil, httperrors.NewInvalidStatusError("cannot set acl in status %s", self.Status)
+	}
+	aclV := validators.NewStringChoicesValidator("acl", bucketAclChoices)
+	if err := aclV.Validate(data.(*jsonutils.JSONDict)); err != nil {
+		return nil, err
+	}
+	ibucket, err := self.GetIBucket()
+	if err != nil {
+		return nil, httperrors.NewGeneralError(err)
+	}
+	err = ibucket.SetAcl(cloudprovider.TBucketACLType(aclV.Value))
+	if err != nil {
+		return nil, httperrors.NewGeneralError(err)
+	}
+	diff, err := db.Update(self, func() error {
+		self.Acl = aclV.Value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	db.OpsLog.LogEvent(self, db.ACT_UPDATE, diff, userCred)
+	return nil, nil
+}
+
+func (self *SBucket) AllowPerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return db.IsAdminAllowPerform(userCred, self, "purge")
+}
+
+func (self *SBucket) PerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	provider := self.GetCloudprovider()
+	if provider != nil {
+		if provider.Enabled {
+			return nil, httperrors.NewInvalidStatusError("Cannot purge bucket on enabled cloud provider")
+		}
+	}
+	err := self.RealDelete(ctx, userCred)
+	return nil, err
+}
+
+func (self *SBucket) Delete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	log.Infof("Bucket delete do nothing")
+	return nil
+}
+
+func (self *SBucket) RealDelete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return self.SVirtualResourceBase.Delete(ctx, userCred)
+}
+
+func (self *SBucket) CustomizeDelete(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) error {
+	return self.StartBucketDeleteTask(ctx, userCred, "")
+}
+
+func (self *SBucket) StartBucketDeleteTask(ctx context.Context, userCred mcclient.TokenCredential, parentTaskId string) error {
+	task, err := taskman.TaskManager.NewTask(ctx, "BucketDeleteTask", self, userCred, nil, parentTaskId, "", nil)
+	if err != nil {
+		log.Errorf("newTask BucketDeleteTask fail %s", err)
+		return err
+	}
+	self.SetStatus(userCred, api.BUCKET_STATUS_DELETING, "start to delete")
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (manager *SBucketManager) getBucketsByRegion(region *SCloudregion, provider *SCloudprovider) ([]SBucket, error) {
+	buckets := make([]SBucket, 0)
+	q := manager.Query().Equals("cloudregion_id", region.Id)
+	if provider != nil {
+		q = q.Equals("manager_id", provider.Id)
+	}
+	err := db.FetchModelObjects(manager, q, &buckets)
+	if err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+func (manager *SBucketManager) SyncBuckets(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, region *SCloudregion, buckets []cloudprovider.ICloudBucket) compare.SyncResult {
+	lockman.LockClass(ctx, manager, provider.ProjectId)
+	defer lockman.ReleaseClass(ctx, manager, provider.ProjectId)
+
+	syncResult := compare.SyncResult{}
+
+	dbBuckets, err := manager.getBucketsByRegion(region, provider)
+	if err != nil {
+		syncResult.Error(err)
+		return syncResult
+	}
+
+	for i := range dbBuckets {
+		if taskman.TaskManager.IsInTask(&dbBuckets[i]) {
+			syncResult.Error(fmt.Errorf("object in task"))
+			return syncResult
+		}
+	}
+
+	removed := make([]SBucket, 0)
+	commondb := make([]SBucket, 0)
+	commonext := make([]cloudprovider.ICloudBucket, 0)
+	added := make([]cloudprovider.ICloudBucket, 0)
+
+	err = compare.CompareSets(dbBuckets, buckets, &removed, &commondb, &commonext, &added)
+	if err != nil {
+		syncResult.Error(err)
+		return syncResult
+	}
+
+	for i := 0; i < len(removed); i += 1 {
+		err = removed[i].syncRemoveCloudBucket(ctx, userCred)
+		if err != nil {
+			syncResult.DeleteError(err)
+		} else {
+			syncResult.Delete()
+		}
+	}
+
+	for i := 0; i < len(commondb); i += 1 {
+		err = commondb[i].SyncWithCloudBucket(ctx, userCred, commonext[i])
+		if err != nil {
+			syncResult.UpdateError(err)
+			continue
+		}
+		syncMetadata(ctx, userCred, &commondb[i], commonext[i])
+		syncResult.Update()
+	}
+
+	for i := 0; i < len(added); i += 1 {
+		bucket, err := manager.newFromCloudBucket(ctx, userCred, provider, region, added[i])
+		if err != nil {
+			syncResult.AddError(err)
+			continue
+		}
+		syncMetadata(ctx, userCred, bucket, added[i])
+		syncResult.Add()
+	}
+
+	return syncResult
+}
+
+func (self *SBucket) syncRemoveCloudBucket(ctx context.Context, userCred mcclient.TokenCredential) error {
+	lockman.LockObject(ctx, self)
+	defer lockman.ReleaseObject(ctx, self)
+
+	err := self.ValidateDeleteCondition(ctx)
+	if err != nil {
+		self.SetStatus(userCred, api.BUCKET_STATUS_UNKNOWN, "sync to delete")
+		return err
+	}
+	return self.RealDelete(ctx, userCred)
+}
+
+func (self *SBucket) SyncWithCloudBucket(ctx context.Context, userCred mcclient.TokenCredential, extBucket cloudprovider.ICloudBucket) error {
+	diff, err := db.UpdateWithLock(ctx, self, func() error {
+		self.Status = extBucket.GetStatus()
+		self.Location = extBucket.GetLocation()
+		self.StorageClass = extBucket.GetStorageClass()
+		self.Acl = string(extBucket.GetAcl())
+		self.ExternalId = extBucket.GetGlobalId()
+		self.IsEmulated = extBucket.IsEmulated()
+		return nil
+	})
+	if err != nil {
+		log.Errorf("SyncWithCloudBucket fail %s", err)
+		return err
+	}
+	db.OpsLog.LogSyncUpdate(self, diff, userCred)
+	return nil
+}
+
+func (manager *SBucketManager) newFromCloudBucket(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, region *SCloudregion, extBucket cloudprovider.ICloudBucket) (*SBucket, error) {
+	bucket := SBucket{}
+	bucket.SetModelManager(manager)
+
+	bucket.Name = db.GenerateName(manager, provider.ProjectId, extBucket.GetName())
+	bucket.Status = extBucket.GetStatus()
+	bucket.ExternalId = extBucket.GetGlobalId()
+	bucket.IsEmulated = extBucket.IsEmulated()
+	bucket.ManagerId = provider.Id
+	bucket.CloudregionId = region.Id
+	bucket.Location = extBucket.GetLocation()
+	bucket.StorageClass = extBucket.GetStorageClass()
+	bucket.Acl = string(extBucket.GetAcl())
+	bucket.ProjectId = provider.ProjectId
+	if len(bucket.ProjectId) == 0 {
+		bucket.ProjectId = userCred.GetProjectId()
+	}
+
+	err := manager.TableSpec().Insert(&bucket)
+	if err != nil {
+		log.Errorf("newFromCloudBucket fail %s", err)
+		return nil, err
+	}
+
+	db.OpsLog.LogEvent(&bucket, db.ACT_CREATE, bucket.GetShortDesc(ctx), userCred)
+	return &bucket, nil
+}
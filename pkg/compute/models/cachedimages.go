@@ -14,6 +14,7 @@ import (
 
 	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/quotas"
 	"yunion.io/x/onecloud/pkg/cloudprovider"
 	"yunion.io/x/onecloud/pkg/compute/options"
 	"yunion.io/x/onecloud/pkg/httperrors"
@@ -118,6 +119,43 @@ func (self *SCachedimage) GetOwner() string {
 	return owner
 }
 
+// totalCachedImageCount returns the count of the images of the project cached
+// in the storages of the zone or cloudregion
+func totalCachedImageCount(projectId string, scope quotas.SQuotaScope) int {
+	if scope.IsGlobal() {
+		// the global usage is counted by the image service
+		return 0
+	}
+	storages := StorageManager.Query().SubQuery()
+	cachesQ := storages.Query(storages.Field("storagecache_id"))
+	if len(scope.ZoneId) > 0 {
+		cachesQ = cachesQ.Filter(sqlchemy.Equals(storages.Field("zone_id"), scope.ZoneId))
+	} else {
+		zones := ZoneManager.Query().SubQuery()
+		cachesQ = cachesQ.Join(zones, sqlchemy.Equals(storages.Field("zone_id"), zones.Field("id"))).
+			Filter(sqlchemy.Equals(zones.Field("cloudregion_id"), scope.RegionId))
+	}
+	storagecachedimages := StoragecachedimageManager.Query().SubQuery()
+	subq := storagecachedimages.Query(storagecachedimages.Field("cachedimage_id")).
+		Filter(sqlchemy.In(storagecachedimages.Field("storagecache_id"), cachesQ.SubQuery())).SubQuery()
+	q := CachedimageManager.Query()
+	q = q.Filter(sqlchemy.In(q.Field("id"), subq))
+	images := make([]SCachedimage, 0)
+	err := db.FetchModelObjects(CachedimageManager, q, &images)
+	if err != nil {
+		log.Errorf("fetch cached images of %s fail %s", scope.Key(), err)
+		return 0
+	}
+	count := 0
+	for i := range images {
+		// the owner is only kept in the image info
+		if images[i].Info != nil && images[i].GetOwner() == projectId {
+			count += 1
+		}
+	}
+	return count
+}
+
 func (self *SCachedimage) GetFormat() string {
 	format, _ := self.Info.GetString("disk_format")
 	return format
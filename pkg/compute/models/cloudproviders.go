@@ -47,6 +47,7 @@ const (
 	CLOUD_PROVIDER_AWS       = "Aws"
 	CLOUD_PROVIDER_HUAWEI    = "Huawei"
 	CLOUD_PROVIDER_OPENSTACK = "OpenStack"
+	CLOUD_PROVIDER_GOOGLE    = "Google"
 
 	CLOUD_PROVIDER_HEALTH_NORMAL    = "normal"    // 远端处于健康状态
 	CLOUD_PROVIDER_HEALTH_SUSPENDED = "suspended" // 远端处于冻结状态
@@ -65,6 +66,7 @@ var (
 		CLOUD_PROVIDER_AWS,
 		CLOUD_PROVIDER_HUAWEI,
 		CLOUD_PROVIDER_OPENSTACK,
+		CLOUD_PROVIDER_GOOGLE,
 	}
 )
 
@@ -134,6 +134,42 @@ func syncRegionEips(ctx context.Context, userCred mcclient.TokenCredential, sync
 	// db.OpsLog.LogEvent(provider, db.ACT_SYNC_HOST_COMPLETE, msg, userCred)
 }
 
+func syncRegionBuckets(ctx context.Context, userCred mcclient.TokenCredential, syncResults SSyncResultSet, provider *SCloudprovider, localRegion *SCloudregion, remoteRegion cloudprovider.ICloudRegion, syncRange *SSyncRange) {
+	buckets, err := remoteRegion.GetIBuckets()
+	if err != nil {
+		if err != cloudprovider.ErrNotImplemented && err != cloudprovider.ErrNotSupported {
+			msg := fmt.Sprintf("GetIBuckets for region %s failed %s", remoteRegion.GetName(), err)
+			log.Errorf(msg)
+		}
+		return
+	}
+
+	result := BucketManager.SyncBuckets(ctx, userCred, provider, localRegion, buckets)
+
+	syncResults.Add(BucketManager, result)
+
+	msg := result.Result()
+	log.Infof("SyncBuckets for region %s result: %s", localRegion.Name, msg)
+}
+
+func syncRegionDBInstances(ctx context.Context, userCred mcclient.TokenCredential, syncResults SSyncResultSet, provider *SCloudprovider, localRegion *SCloudregion, remoteRegion cloudprovider.ICloudRegion, syncRange *SSyncRange) {
+	instances, err := remoteRegion.GetIDBInstances()
+	if err != nil {
+		if err != cloudprovider.ErrNotImplemented && err != cloudprovider.ErrNotSupported {
+			msg := fmt.Sprintf("GetIDBInstances for region %s failed %s", remoteRegion.GetName(), err)
+			log.Errorf(msg)
+		}
+		return
+	}
+
+	result := DBInstanceManager.SyncDBInstances(ctx, userCred, provider, localRegion, instances)
+
+	syncResults.Add(DBInstanceManager, result)
+
+	msg := result.Result()
+	log.Infof("SyncDBInstances for region %s result: %s", localRegion.Name, msg)
+}
+
 func syncRegionVPCs(ctx context.Context, userCred mcclient.TokenCredential, syncResults SSyncResultSet, provider *SCloudprovider, localRegion *SCloudregion, remoteRegion cloudprovider.ICloudRegion, syncRange *SSyncRange) {
 	vpcs, err := remoteRegion.GetIVpcs()
 	if err != nil {
@@ -163,6 +199,8 @@ func syncRegionVPCs(ctx context.Context, userCred mcclient.TokenCredential, sync
 			syncVpcWires(ctx, userCred, syncResults, provider, &localVpcs[j], remoteVpcs[j], syncRange)
 			syncVpcSecGroup(ctx, userCred, syncResults, provider, &localVpcs[j], remoteVpcs[j], syncRange)
 			syncVpcRouteTables(ctx, userCred, syncResults, provider, &localVpcs[j], remoteVpcs[j], syncRange)
+			syncVpcNatgateways(ctx, userCred, syncResults, provider, &localVpcs[j], remoteVpcs[j], syncRange)
+			syncVpcPeerings(ctx, userCred, syncResults, provider, &localVpcs[j], remoteVpcs[j], syncRange)
 
 		}()
 	}
@@ -208,6 +246,80 @@ func syncVpcRouteTables(ctx context.Context, userCred mcclient.TokenCredential,
 	}
 }
 
+func syncVpcNatgateways(ctx context.Context, userCred mcclient.TokenCredential, syncResults SSyncResultSet, provider *SCloudprovider, localVpc *SVpc, remoteVpc cloudprovider.ICloudVpc, syncRange *SSyncRange) {
+	natGateways, err := remoteVpc.GetINatGateways()
+	if err != nil {
+		msg := fmt.Sprintf("GetINatGateways for vpc %s failed %s", remoteVpc.GetId(), err)
+		log.Errorf(msg)
+		return
+	}
+	localNatGateways, remoteNatGateways, result := NatGatewayManager.SyncNatGateways(ctx, userCred, provider, localVpc, natGateways)
+
+	syncResults.Add(NatGatewayManager, result)
+
+	msg := result.Result()
+	notes := fmt.Sprintf("SyncNatGateways for VPC %s result: %s", localVpc.Name, msg)
+	log.Infof(notes)
+	if result.IsError() {
+		return
+	}
+
+	for i := 0; i < len(localNatGateways); i++ {
+		func() {
+			lockman.LockObject(ctx, &localNatGateways[i])
+			defer lockman.ReleaseObject(ctx, &localNatGateways[i])
+
+			syncNatSEntries(ctx, userCred, syncResults, provider, &localNatGateways[i], remoteNatGateways[i])
+			syncNatDEntries(ctx, userCred, syncResults, provider, &localNatGateways[i], remoteNatGateways[i])
+		}()
+	}
+}
+
+func syncVpcPeerings(ctx context.Context, userCred mcclient.TokenCredential, syncResults SSyncResultSet, provider *SCloudprovider, localVpc *SVpc, remoteVpc cloudprovider.ICloudVpc, syncRange *SSyncRange) {
+	peerings, err := remoteVpc.GetIVpcPeerings()
+	if err != nil {
+		msg := fmt.Sprintf("GetIVpcPeerings for vpc %s failed %s", remoteVpc.GetId(), err)
+		log.Errorf(msg)
+		return
+	}
+	result := VpcPeeringManager.SyncVpcPeerings(ctx, userCred, provider, localVpc, peerings)
+
+	syncResults.Add(VpcPeeringManager, result)
+
+	msg := result.Result()
+	log.Infof("SyncVpcPeerings for VPC %s result: %s", localVpc.Name, msg)
+}
+
+func syncNatSEntries(ctx context.Context, userCred mcclient.TokenCredential, syncResults SSyncResultSet, provider *SCloudprovider, localNatGateway *SNatGateway, remoteNatGateway cloudprovider.ICloudNatGateway) {
+	sentries, err := remoteNatGateway.GetINatSEntries()
+	if err != nil {
+		msg := fmt.Sprintf("GetINatSEntries for natgateway %s failed %s", remoteNatGateway.GetId(), err)
+		log.Errorf(msg)
+		return
+	}
+	result := NatSEntryManager.SyncNatSEntries(ctx, userCred, provider, localNatGateway, sentries)
+
+	syncResults.Add(NatSEntryManager, result)
+
+	msg := result.Result()
+	log.Infof("SyncNatSEntries for natgateway %s result: %s", localNatGateway.Name, msg)
+}
+
+func syncNatDEntries(ctx context.Context, userCred mcclient.TokenCredential, syncResults SSyncResultSet, provider *SCloudprovider, localNatGateway *SNatGateway, remoteNatGateway cloudprovider.ICloudNatGateway) {
+	dentries, err := remoteNatGateway.GetINatDEntries()
+	if err != nil {
+		msg := fmt.Sprintf("GetINatDEntries for natgateway %s failed %s", remoteNatGateway.GetId(), err)
+		log.Errorf(msg)
+		return
+	}
+	result := NatDEntryManager.SyncNatDEntries(ctx, userCred, provider, localNatGateway, dentries)
+
+	syncResults.Add(NatDEntryManager, result)
+
+	msg := result.Result()
+	log.Infof("SyncNatDEntries for natgateway %s result: %s", localNatGateway.Name, msg)
+}
+
 func syncVpcWires(ctx context.Context, userCred mcclient.TokenCredential, syncResults SSyncResultSet, provider *SCloudprovider, localVpc *SVpc, remoteVpc cloudprovider.ICloudVpc, syncRange *SSyncRange) {
 	wires, err := remoteVpc.GetIWires()
 	if err != nil {
@@ -769,6 +881,10 @@ func syncPublicCloudProviderInfo(
 	syncRegionLoadbalancerCertificates(ctx, userCred, syncResults, provider, localRegion, remoteRegion, syncRange)
 	syncRegionLoadbalancers(ctx, userCred, syncResults, provider, localRegion, remoteRegion, syncRange)
 
+	syncRegionBuckets(ctx, userCred, syncResults, provider, localRegion, remoteRegion, syncRange)
+
+	syncRegionDBInstances(ctx, userCred, syncResults, provider, localRegion, remoteRegion, syncRange)
+
 	log.Debugf("storageCachePairs count %d", len(storageCachePairs))
 	for i := range storageCachePairs {
 		result := storageCachePairs[i].syncCloudImages(ctx, userCred)
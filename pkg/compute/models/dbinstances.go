@@ -0,0 +1,438 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/compare"
+	"yunion.io/x/pkg/utils"
+	"yunion.io/x/sqlchemy"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+type SDBInstanceManager struct {
+	db.SVirtualResourceBaseManager
+}
+
+var DBInstanceManager *SDBInstanceManager
+
+func init() {
+	DBInstanceManager = &SDBInstanceManager{
+		SVirtualResourceBaseManager: db.NewVirtualResourceBaseManager(
+			SDBInstance{},
+			"dbinstances_tbl",
+			"dbinstance",
+			"dbinstances",
+		),
+	}
+}
+
+type SDBInstance struct {
+	db.SVirtualResourceBase
+	SManagedResourceBase
+	SBillingResourceBase
+
+	CloudregionId string `width:"36" charset:"ascii" nullable:"false" list:"user"`
+	ZoneId        string `width:"36" charset:"ascii" nullable:"true" list:"user"`
+	VpcId         string `width:"36" charset:"ascii" nullable:"true" list:"user"`
+	NetworkId     string `width:"36" charset:"ascii" nullable:"true" list:"user"`
+
+	Engine        string `width:"16" charset:"ascii" nullable:"false" list:"user"`
+	EngineVersion string `width:"16" charset:"ascii" nullable:"false" list:"user"`
+	InstanceType  string `width:"64" charset:"ascii" nullable:"true" list:"user"`
+	VcpuCount     int    `nullable:"false" default:"0" list:"user"`
+	VmemSizeMb    int    `nullable:"false" default:"0" list:"user"`
+	DiskSizeGb    int    `nullable:"false" default:"0" list:"user"`
+	Category      string `width:"32" charset:"ascii" nullable:"true" list:"user"`
+	StorageType   string `width:"32" charset:"ascii" nullable:"true" list:"user"`
+
+	Port                  int    `nullable:"false" default:"0" list:"user"`
+	ConnectionStr         string `width:"256" charset:"ascii" nullable:"true" list:"user"`
+	InternalConnectionStr string `width:"256" charset:"ascii" nullable:"true" list:"user"`
+}
+
+func (manager *SDBInstanceManager) ListItemFilter(ctx context.Context, q *sqlchemy.SQuery, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*sqlchemy.SQuery, error) {
+	var err error
+	q, err = managedResourceFilterByAccount(q, query, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	q = managedResourceFilterByCloudType(q, query, "", nil)
+
+	q, err = manager.SVirtualResourceBaseManager.ListItemFilter(ctx, q, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	data := query.(*jsonutils.JSONDict)
+	q, err = validators.ApplyModelFilters(q, data, []*validators.ModelFilterOptions{
+		{Key: "cloudregion", ModelKeyword: "cloudregion", ProjectId: userCred.GetProjectId()},
+		{Key: "zone", ModelKeyword: "zone", ProjectId: userCred.GetProjectId()},
+		{Key: "vpc", ModelKeyword: "vpc", ProjectId: userCred.GetProjectId()},
+		{Key: "network", ModelKeyword: "network", ProjectId: userCred.GetProjectId()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if engine, _ := query.GetString("engine"); len(engine) > 0 {
+		q = q.Equals("engine", engine)
+	}
+	return q, nil
+}
+
+func (manager *SDBInstanceManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Not support create dbinstance")
+}
+
+func (self *SDBInstance) GetRegion() *SCloudregion {
+	return CloudregionManager.FetchRegionById(self.CloudregionId)
+}
+
+func (self *SDBInstance) GetZone() *SZone {
+	if len(self.ZoneId) == 0 {
+		return nil
+	}
+	return ZoneManager.FetchZoneById(self.ZoneId)
+}
+
+func (self *SDBInstance) GetVpc() *SVpc {
+	vpc, err := VpcManager.FetchById(self.VpcId)
+	if err != nil {
+		return nil
+	}
+	return vpc.(*SVpc)
+}
+
+func (self *SDBInstance) GetNetwork() *SNetwork {
+	network, err := NetworkManager.FetchById(self.NetworkId)
+	if err != nil {
+		return nil
+	}
+	return network.(*SNetwork)
+}
+
+func (self *SDBInstance) GetIRegion() (cloudprovider.ICloudRegion, error) {
+	provider, err := self.GetDriver()
+	if err != nil {
+		return nil, err
+	}
+	region := self.GetRegion()
+	if region == nil {
+		return nil, fmt.Errorf("fail to find region for dbinstance %s", self.Name)
+	}
+	return provider.GetIRegionById(region.GetExternalId())
+}
+
+func (self *SDBInstance) GetIDBInstance() (cloudprovider.ICloudDBInstance, error) {
+	iregion, err := self.GetIRegion()
+	if err != nil {
+		return nil, err
+	}
+	return iregion.GetIDBInstanceById(self.ExternalId)
+}
+
+func (self *SDBInstance) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSONDict {
+	info := MakeCloudProviderInfo(self.GetRegion(), self.GetZone(), self.GetCloudprovider())
+	extra.Update(jsonutils.Marshal(&info))
+	if vpc := self.GetVpc(); vpc != nil {
+		extra.Add(jsonutils.NewString(vpc.Name), "vpc")
+	}
+	if network := self.GetNetwork(); network != nil {
+		extra.Add(jsonutils.NewString(network.Name), "network")
+	}
+	return extra
+}
+
+func (self *SDBInstance) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
+	extra := self.SVirtualResourceBase.GetCustomizeColumns(ctx, userCred, query)
+	return self.getMoreDetails(extra)
+}
+
+func (self *SDBInstance) GetExtraDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	extra, err := self.SVirtualResourceBase.GetExtraDetails(ctx, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	return self.getMoreDetails(extra), nil
+}
+
+func (self *SDBInstance) AllowGetDetailsBackups(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowGetSpec(userCred, self, "backups")
+}
+
+// 备份不在本地保存, 每次从云上实时查询
+func (self *SDBInstance) GetDetailsBackups(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	iinstance, err := self.GetIDBInstance()
+	if err != nil {
+		return nil, httperrors.NewGeneralError(err)
+	}
+	ibackups, err := iinstance.GetIDBInstanceBackups()
+	if err != nil {
+		if err == cloudprovider.ErrNotSupported || err == cloudprovider.ErrNotImplemented {
+			return nil, httperrors.NewUnsupportOperationError("dbinstance %s not support list backups", self.Name)
+		}
+		return nil, httperrors.NewGeneralError(err)
+	}
+	backups := jsonutils.NewArray()
+	for _, ibackup := range ibackups {
+		backup := jsonutils.NewDict()
+		backup.Add(jsonutils.NewString(ibackup.GetGlobalId()), "external_id")
+		backup.Add(jsonutils.NewString(ibackup.GetName()), "name")
+		backup.Add(jsonutils.NewString(ibackup.GetStatus()), "status")
+		backup.Add(jsonutils.NewString(ibackup.GetBackupMode()), "backup_mode")
+		backup.Add(jsonutils.NewInt(int64(ibackup.GetBackupSizeMb())), "backup_size_mb")
+		backup.Add(jsonutils.NewTimeString(ibackup.GetStartTime()), "start_time")
+		backup.Add(jsonutils.NewTimeString(ibackup.GetEndTime()), "end_time")
+		backups.Add(backup)
+	}
+	ret := jsonutils.NewDict()
+	ret.Add(backups, "backups")
+	return ret, nil
+}
+
+func (self *SDBInstance) AllowPerformStart(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, self, "start")
+}
+
+func (self *SDBInstance) PerformStart(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if !utils.IsInStringArray(self.Status, []string{api.DBINSTANCE_STATUS_READY, api.DBINSTANCE_STATUS_START_FAILED, api.DBINSTANCE_STATUS_STOP_FAILED}) {
+		return nil, httperrors.NewInvalidStatusError("Cannot start dbinstance in status %s", self.Status)
+	}
+	return nil, self.StartDBInstanceTask(ctx, userCred, "DBInstanceStartTask", api.DBINSTANCE_STATUS_STARTING, "")
+}
+
+func (self *SDBInstance) AllowPerformStop(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, self, "stop")
+}
+
+func (self *SDBInstance) PerformStop(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if !utils.IsInStringArray(self.Status, []string{api.DBINSTANCE_STATUS_RUNNING, api.DBINSTANCE_STATUS_STOP_FAILED}) {
+		return nil, httperrors.NewInvalidStatusError("Cannot stop dbinstance in status %s", self.Status)
+	}
+	return nil, self.StartDBInstanceTask(ctx, userCred, "DBInstanceStopTask", api.DBINSTANCE_STATUS_STOPPING, "")
+}
+
+func (self *SDBInstance) AllowPerformReboot(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, self, "reboot")
+}
+
+func (self *SDBInstance) PerformReboot(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if !utils.IsInStringArray(self.Status, []string{api.DBINSTANCE_STATUS_RUNNING, api.DBINSTANCE_STATUS_REBOOT_FAILED}) {
+		return nil, httperrors.NewInvalidStatusError("Cannot reboot dbinstance in status %s", self.Status)
+	}
+	return nil, self.StartDBInstanceTask(ctx, userCred, "DBInstanceRebootTask", api.DBINSTANCE_STATUS_REBOOTING, "")
+}
+
+func (self *SDBInstance) StartDBInstanceTask(ctx context.Context, userCred mcclient.TokenCredential, taskName string, status string, parentTaskId string) error {
+	task, err := taskman.TaskManager.NewTask(ctx, taskName, self, userCred, nil, parentTaskId, "", nil)
+	if err != nil {
+		log.Errorf("newTask %s fail %s", taskName, err)
+		return err
+	}
+	self.SetStatus(userCred, status, "")
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (self *SDBInstance) AllowPerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return db.IsAdminAllowPerform(userCred, self, "purge")
+}
+
+func (self *SDBInstance) PerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	provider := self.GetCloudprovider()
+	if provider != nil {
+		if provider.Enabled {
+			return nil, httperrors.NewInvalidStatusError("Cannot purge dbinstance on enabled cloud provider")
+		}
+	}
+	err := self.RealDelete(ctx, userCred)
+	return nil, err
+}
+
+func (self *SDBInstance) Delete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	log.Infof("DBInstance delete do nothing")
+	return nil
+}
+
+func (self *SDBInstance) RealDelete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return self.SVirtualResourceBase.Delete(ctx, userCred)
+}
+
+func (self *SDBInstance) CustomizeDelete(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) error {
+	return self.StartDBInstanceTask(ctx, userCred, "DBInstanceDeleteTask", api.DBINSTANCE_STATUS_DELETING, "")
+}
+
+func (manager *SDBInstanceManager) getDBInstancesByRegion(region *SCloudregion, provider *SCloudprovider) ([]SDBInstance, error) {
+	instances := make([]SDBInstance, 0)
+	q := manager.Query().Equals("cloudregion_id", region.Id)
+	if provider != nil {
+		q = q.Equals("manager_id", provider.Id)
+	}
+	err := db.FetchModelObjects(manager, q, &instances)
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+func (manager *SDBInstanceManager) SyncDBInstances(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, region *SCloudregion, instances []cloudprovider.ICloudDBInstance) compare.SyncResult {
+	lockman.LockClass(ctx, manager, provider.ProjectId)
+	defer lockman.ReleaseClass(ctx, manager, provider.ProjectId)
+
+	syncResult := compare.SyncResult{}
+
+	dbInstances, err := manager.getDBInstancesByRegion(region, provider)
+	if err != nil {
+		syncResult.Error(err)
+		return syncResult
+	}
+
+	for i := range dbInstances {
+		if taskman.TaskManager.IsInTask(&dbInstances[i]) {
+			syncResult.Error(fmt.Errorf("object in task"))
+			return syncResult
+		}
+	}
+
+	removed := make([]SDBInstance, 0)
+	commondb := make([]SDBInstance, 0)
+	commonext := make([]cloudprovider.ICloudDBInstance, 0)
+	added := make([]cloudprovider.ICloudDBInstance, 0)
+
+	err = compare.CompareSets(dbInstances, instances, &removed, &commondb, &commonext, &added)
+	if err != nil {
+		syncResult.Error(err)
+		return syncResult
+	}
+
+	for i := 0; i < len(removed); i += 1 {
+		err = removed[i].syncRemoveCloudDBInstance(ctx, userCred)
+		if err != nil {
+			syncResult.DeleteError(err)
+		} else {
+			syncResult.Delete()
+		}
+	}
+
+	for i := 0; i < len(commondb); i += 1 {
+		err = commondb[i].SyncWithCloudDBInstance(ctx, userCred, provider, commonext[i])
+		if err != nil {
+			syncResult.UpdateError(err)
+			continue
+		}
+		syncMetadata(ctx, userCred, &commondb[i], commonext[i])
+		syncResult.Update()
+	}
+
+	for i := 0; i < len(added); i += 1 {
+		instance, err := manager.newFromCloudDBInstance(ctx, userCred, provider, region, added[i])
+		if err != nil {
+			syncResult.AddError(err)
+			continue
+		}
+		syncMetadata(ctx, userCred, instance, added[i])
+		syncResult.Add()
+	}
+
+	return syncResult
+}
+
+func (self *SDBInstance) syncRemoveCloudDBInstance(ctx context.Context, userCred mcclient.TokenCredential) error {
+	lockman.LockObject(ctx, self)
+	defer lockman.ReleaseObject(ctx, self)
+
+	err := self.ValidateDeleteCondition(ctx)
+	if err != nil {
+		self.SetStatus(userCred, api.DBINSTANCE_STATUS_UNKNOWN, "sync to delete")
+		return err
+	}
+	return self.RealDelete(ctx, userCred)
+}
+
+// 云上的vpc, 子网及可用区需先同步到本地, 找不到时置空
+func (self *SDBInstance) syncWithCloud(provider *SCloudprovider, extInstance cloudprovider.ICloudDBInstance) {
+	self.Status = extInstance.GetStatus()
+	self.ExternalId = extInstance.GetGlobalId()
+	self.IsEmulated = extInstance.IsEmulated()
+	self.Engine = extInstance.GetEngine()
+	self.EngineVersion = extInstance.GetEngineVersion()
+	self.InstanceType = extInstance.GetInstanceType()
+	self.VcpuCount = extInstance.GetVcpuCount()
+	self.VmemSizeMb = extInstance.GetVmemSizeMB()
+	self.DiskSizeGb = extInstance.GetDiskSizeGB()
+	self.Category = extInstance.GetCategory()
+	self.StorageType = extInstance.GetStorageType()
+	self.Port = extInstance.GetPort()
+	self.ConnectionStr = extInstance.GetConnectionStr()
+	self.InternalConnectionStr = extInstance.GetInternalConnectionStr()
+
+	self.VpcId = ""
+	if vpcId := extInstance.GetVpcId(); len(vpcId) > 0 {
+		if vpc, err := VpcManager.FetchByExternalId(vpcId); err == nil && vpc != nil {
+			self.VpcId = vpc.GetId()
+		}
+	}
+	self.NetworkId = ""
+	if networkId := extInstance.GetNetworkId(); len(networkId) > 0 {
+		if network, err := NetworkManager.FetchByExternalId(networkId); err == nil && network != nil {
+			self.NetworkId = network.GetId()
+		}
+	}
+	self.ZoneId = ""
+	if zoneId := extInstance.GetZoneId(); len(zoneId) > 0 {
+		if zone, err := ZoneManager.FetchByExternalId(zoneId); err == nil && zone != nil {
+			self.ZoneId = zone.GetId()
+		}
+	}
+
+	factory, _ := provider.GetProviderFactory()
+	if factory != nil && factory.IsSupportPrepaidResources() {
+		self.BillingType = extInstance.GetBillingType()
+		self.ExpiredAt = extInstance.GetExpiredAt()
+	}
+}
+
+func (self *SDBInstance) SyncWithCloudDBInstance(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, extInstance cloudprovider.ICloudDBInstance) error {
+	diff, err := db.UpdateWithLock(ctx, self, func() error {
+		self.syncWithCloud(provider, extInstance)
+		return nil
+	})
+	if err != nil {
+		log.Errorf("SyncWithCloudDBInstance fail %s", err)
+		return err
+	}
+	db.OpsLog.LogSyncUpdate(self, diff, userCred)
+	return nil
+}
+
+func (manager *SDBInstanceManager) newFromCloudDBInstance(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, region *SCloudregion, extInstance cloudprovider.ICloudDBInstance) (*SDBInstance, error) {
+	instance := SDBInstance{}
+	instance.SetModelManager(manager)
+
+	instance.Name = db.GenerateName(manager, provider.ProjectId, extInstance.GetName())
+	instance.ManagerId = provider.Id
+	instance.CloudregionId = region.Id
+	instance.syncWithCloud(provider, extInstance)
+	instance.ProjectId = provider.ProjectId
+	if len(instance.ProjectId) == 0 {
+		instance.ProjectId = userCred.GetProjectId()
+	}
+
+	err := manager.TableSpec().Insert(&instance)
+	if err != nil {
+		log.Errorf("newFromCloudDBInstance fail %s", err)
+		return nil, err
+	}
+
+	db.OpsLog.LogEvent(&instance, db.ACT_CREATE, instance.GetShortDesc(ctx), userCred)
+	return &instance, nil
+}
@@ -523,7 +523,7 @@ func getDiskResourceRequirements(ctx context.Context, userCred mcclient.TokenCre
 
 func (manager *SDiskManager) OnCreateComplete(ctx context.Context, items []db.IModel, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) {
 	pendingUsage := getDiskResourceRequirements(ctx, userCred, data, len(items))
-	RunBatchCreateTask(ctx, items, userCred, data, pendingUsage, "DiskBatchCreateTask")
+	RunBatchCreateTask(ctx, items, userCred, data, pendingUsage, "DiskBatchCreateTask", "")
 }
 
 func (self *SDisk) StartDiskCreateTask(ctx context.Context, userCred mcclient.TokenCredential, rebuild bool, snapshot string, parentTaskId string) error {
@@ -783,7 +783,8 @@ func (self *SDisk) GetZone() *SZone {
 }
 
 func (self *SDisk) PrepareSaveImage(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (string, error) {
-	if zone := self.GetZone(); zone == nil {
+	zone := self.GetZone()
+	if zone == nil {
 		return "", httperrors.NewResourceNotFoundError("No zone for this disk")
 	}
 	data.Add(jsonutils.NewString(self.DiskFormat), "disk_format")
@@ -794,13 +795,13 @@ func (self *SDisk) PrepareSaveImage(ctx context.Context, userCred mcclient.Token
 	} else if imageList.Total > 0 {
 		return "", httperrors.NewConflictError("Duplicate image name %s", name)
 	}
-	/*
-		no need to check quota anymore
-		session := auth.GetSession(userCred, options.Options.Region, "v2")
-		quota := image_models.SQuota{Image: 1}
-		if _, err := modules.ImageQuotas.DoQuotaCheck(session, jsonutils.Marshal(&quota)); err != nil {
-			return "", err
-		}*/
+	// the global image quota is checked by the image service
+	quota := SQuota{Image: 1}
+	for _, scope := range getQuotaScopes(zone.CloudregionId, zone.Id) {
+		if _, err := QuotaManager.CheckScopedQuota(ctx, userCred, userCred.GetProjectId(), scope, &quota); err != nil {
+			return "", httperrors.NewOutOfQuotaError("%s", err)
+		}
+	}
 	data.Add(jsonutils.NewInt(int64(self.DiskSize)), "virtual_size")
 	if result, err := modules.Images.Create(s, data); err != nil {
 		return "", err
@@ -1346,6 +1347,10 @@ func (self *SDisk) RealDelete(ctx context.Context, userCred mcclient.TokenCreden
 			guestdisk.Detach(ctx, userCred)
 		}
 	}
+	err := SnapshotPolicyDiskManager.DetachDisk(ctx, userCred, self.Id)
+	if err != nil {
+		log.Errorf("disk %s detach snapshot policies fail %s", self.Id, err)
+	}
 	return self.SSharableVirtualResourceBase.Delete(ctx, userCred)
 }
 
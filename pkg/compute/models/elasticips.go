@@ -528,7 +528,7 @@ func (manager *SElasticipManager) ValidateCreateData(ctx context.Context, userCr
 
 	//避免参数重名后还有pending.eip残留
 	eipPendingUsage := &SQuota{Eip: 1}
-	err = QuotaManager.CheckSetPendingQuota(ctx, userCred, userCred.GetProjectId(), eipPendingUsage)
+	err = QuotaManager.CheckSetPendingScopedQuota(ctx, userCred, userCred.GetProjectId(), getQuotaScopes(region.GetId(), ""), eipPendingUsage)
 	if err != nil {
 		return nil, httperrors.NewOutOfQuotaError("Out of eip quota: %s", err)
 	}
@@ -539,10 +539,18 @@ func (manager *SElasticipManager) ValidateCreateData(ctx context.Context, userCr
 func (self *SElasticip) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
 	self.SVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
 	eipPendingUsage := &SQuota{Eip: 1}
-	self.startEipAllocateTask(ctx, userCred, nil, eipPendingUsage)
+	self.startEipAllocateTask(ctx, userCred, nil, eipPendingUsage, getQuotaScopes(self.CloudregionId, ""))
 }
 
-func (self *SElasticip) startEipAllocateTask(ctx context.Context, userCred mcclient.TokenCredential, params *jsonutils.JSONDict, pendingUsage quotas.IQuota) error {
+// startEipAllocateTask hands pendingUsage reserved in pendingScopes over to
+// the task, which releases it when the eip is allocated or fails
+func (self *SElasticip) startEipAllocateTask(ctx context.Context, userCred mcclient.TokenCredential, params *jsonutils.JSONDict, pendingUsage quotas.IQuota, pendingScopes []quotas.SQuotaScope) error {
+	if params == nil {
+		params = jsonutils.NewDict()
+	}
+	if len(pendingScopes) > 0 {
+		params.Add(jsonutils.Marshal(pendingScopes), "pending_quota_scopes")
+	}
 	task, err := taskman.TaskManager.NewTask(ctx, "EipAllocateTask", self, userCred, params, "", "", pendingUsage)
 	if err != nil {
 		log.Errorf("newtask EipAllocateTask fail %s", err)
@@ -804,7 +812,7 @@ func (self *SElasticip) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSO
 	return extra
 }
 
-func (manager *SElasticipManager) AllocateEipAndAssociateVM(ctx context.Context, userCred mcclient.TokenCredential, vm *SGuest, bw int, chargeType string, eipPendingUsage quotas.IQuota) error {
+func (manager *SElasticipManager) AllocateEipAndAssociateVM(ctx context.Context, userCred mcclient.TokenCredential, vm *SGuest, bw int, chargeType string, eipPendingUsage quotas.IQuota, pendingScopes []quotas.SQuotaScope) error {
 
 	host := vm.GetHost()
 	region := host.GetRegion()
@@ -839,7 +847,7 @@ func (manager *SElasticipManager) AllocateEipAndAssociateVM(ctx context.Context,
 
 	vm.SetStatus(userCred, VM_ASSOCIATE_EIP, "allocate and associate EIP")
 
-	return eip.startEipAllocateTask(ctx, userCred, params, eipPendingUsage)
+	return eip.startEipAllocateTask(ctx, userCred, params, eipPendingUsage, pendingScopes)
 }
 
 func (self *SElasticip) AllowPerformChangeBandwidth(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
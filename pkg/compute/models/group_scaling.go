@@ -0,0 +1,335 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/utils"
+	"yunion.io/x/sqlchemy"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/compute/options"
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/mcclient/auth"
+	"yunion.io/x/onecloud/pkg/util/influxdb"
+)
+
+var (
+	// servers of a scaling group in these status failed to launch, they are deleted and replaced
+	scalingGuestFailedStatus = []string{
+		VM_SCHEDULE_FAILED, VM_NETWORK_FAILED, VM_DEVICE_FAILED,
+		VM_CREATE_FAILED, VM_DISK_FAILED, VM_DEPLOY_FAILED,
+	}
+	// servers of a scaling group in these status are on the way out, they are not counted
+	scalingGuestDeletingStatus = []string{VM_START_DELETE, VM_DELETING, VM_DELETE_FAIL}
+)
+
+// scalingMetrics fetches the metrics of the servers from influxdb, the client
+// is only set up when a scaling rule is evaluated
+type scalingMetrics struct {
+	client *influxdb.SInfluxdb
+	err    error
+}
+
+func (m *scalingMetrics) query(sql string) ([][]influxdb.SDBResult, error) {
+	if m.client == nil && m.err == nil {
+		urls, err := auth.GetServiceURLs("influxdb", options.Options.Region, "", "internal")
+		if err != nil {
+			m.err = err
+		} else if len(urls) == 0 {
+			m.err = fmt.Errorf("no influxdb service found")
+		} else {
+			m.client = influxdb.NewInfluxdb(urls[0])
+			m.err = m.client.SetDatabase("telegraf")
+		}
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.client.Query(sql)
+}
+
+// ExecuteScalingGroups moves the desired sizes of the scaling groups by their rules,
+// then launches or deletes servers to match the desired sizes
+func (manager *SGroupManager) ExecuteScalingGroups(ctx context.Context, userCred mcclient.TokenCredential, isStart bool) {
+	groups := make([]SGroup, 0)
+	q := manager.Query().IsTrue("scaling_enabled")
+	err := db.FetchModelObjects(manager, q, &groups)
+	if err != nil {
+		log.Errorf("ExecuteScalingGroups fetch groups fail %s", err)
+		return
+	}
+	metrics := &scalingMetrics{}
+	for i := range groups {
+		func() {
+			lockman.LockObject(ctx, &groups[i])
+			defer lockman.ReleaseObject(ctx, &groups[i])
+
+			err := groups[i].executeScaling(ctx, userCred, metrics)
+			if err != nil {
+				log.Errorf("scaling group %s fail: %s", groups[i].Name, err)
+				db.OpsLog.LogEvent(&groups[i], db.ACT_SCALE_FAIL, err.Error(), userCred)
+			}
+		}()
+	}
+}
+
+// getScalingGuests returns the servers of the group which are not being deleted
+func (group *SGroup) getScalingGuests() ([]SGuest, error) {
+	guests := make([]SGuest, 0)
+	q := GuestManager.Query()
+	sq := GroupguestManager.Query("guest_id").Equals("srvtag_id", group.Id).SubQuery()
+	q = q.In("id", sq)
+	q = q.Filter(sqlchemy.OR(sqlchemy.IsNull(q.Field("pending_deleted")), sqlchemy.IsFalse(q.Field("pending_deleted"))))
+	q = q.NotIn("status", scalingGuestDeletingStatus)
+	err := db.FetchModelObjects(GuestManager, q, &guests)
+	if err != nil {
+		return nil, err
+	}
+	return guests, nil
+}
+
+func (group *SGroup) executeScaling(ctx context.Context, userCred mcclient.TokenCredential, metrics *scalingMetrics) error {
+	guests, err := group.getScalingGuests()
+	if err != nil {
+		return err
+	}
+	alive := make([]SGuest, 0, len(guests))
+	for i := range guests {
+		if utils.IsInStringArray(guests[i].Status, scalingGuestFailedStatus) {
+			err = group.removeGuest(ctx, userCred, &guests[i])
+			if err != nil {
+				log.Errorf("remove failed server %s of group %s fail: %s", guests[i].Name, group.Name, err)
+			}
+			continue
+		}
+		alive = append(alive, guests[i])
+	}
+
+	desired := group.DesiredSize
+	if group.LastScaleAt.IsZero() || time.Since(group.LastScaleAt) >= time.Duration(group.CooldownSeconds)*time.Second {
+		desired += group.evaluateScalingRules(alive, metrics)
+	}
+	if desired < group.MinSize {
+		desired = group.MinSize
+	}
+	if desired > group.MaxSize {
+		desired = group.MaxSize
+	}
+	if desired != group.DesiredSize {
+		action := db.ACT_SCALE_OUT
+		if desired < group.DesiredSize {
+			action = db.ACT_SCALE_IN
+		}
+		notes := fmt.Sprintf("desired size %d -> %d", group.DesiredSize, desired)
+		_, err = db.Update(group, func() error {
+			group.DesiredSize = desired
+			group.LastScaleAt = time.Now().UTC()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		db.OpsLog.LogEvent(group, action, notes, userCred)
+	}
+
+	if len(alive) < desired {
+		for i := len(alive); i < desired; i++ {
+			guest, err := group.launchGuest(ctx, userCred)
+			if err != nil {
+				return fmt.Errorf("launch server: %s", err)
+			}
+			alive = append(alive, *guest)
+		}
+	} else if len(alive) > desired {
+		// servers not running yet go first, then the newest ones
+		sort.SliceStable(alive, func(i, j int) bool {
+			iRunning, jRunning := alive[i].Status == VM_RUNNING, alive[j].Status == VM_RUNNING
+			if iRunning != jRunning {
+				return !iRunning
+			}
+			return alive[i].CreatedAt.After(alive[j].CreatedAt)
+		})
+		for i := 0; i < len(alive)-desired; i++ {
+			err = group.removeGuest(ctx, userCred, &alive[i])
+			if err != nil {
+				return fmt.Errorf("remove server %s: %s", alive[i].Name, err)
+			}
+		}
+		alive = alive[len(alive)-desired:]
+	}
+	return group.syncLoadbalancerBackends(ctx, userCred, alive)
+}
+
+// evaluateScalingRules returns the change of the desired size demanded by the rules,
+// scaling out takes precedence over scaling in
+func (group *SGroup) evaluateScalingRules(guests []SGuest, metrics *scalingMetrics) int {
+	guestIds := make([]string, 0, len(guests))
+	for i := range guests {
+		if guests[i].Status == VM_RUNNING {
+			guestIds = append(guestIds, guests[i].Id)
+		}
+	}
+	if len(guestIds) == 0 {
+		return 0
+	}
+	rules, err := group.GetScalingRules()
+	if err != nil {
+		log.Errorf("fetch scaling rules of group %s fail: %s", group.Name, err)
+		return 0
+	}
+	scaleOut, scaleIn := 0, 0
+	for i := range rules {
+		if !rules[i].Enabled {
+			continue
+		}
+		value, ok, err := rules[i].fetchMetric(guestIds, metrics)
+		if err != nil {
+			log.Errorf("fetch metric of scaling rule %s fail: %s", rules[i].Name, err)
+			continue
+		}
+		if !ok || !rules[i].matches(value) {
+			continue
+		}
+		log.Infof("scaling rule %s of group %s matches, %s.%s=%f", rules[i].Name, group.Name, rules[i].Measurement, rules[i].Field, value)
+		switch rules[i].Action {
+		case api.SCALING_ACTION_OUT:
+			if rules[i].Step > scaleOut {
+				scaleOut = rules[i].Step
+			}
+		case api.SCALING_ACTION_IN:
+			if rules[i].Step > scaleIn {
+				scaleIn = rules[i].Step
+			}
+		}
+	}
+	if scaleOut > 0 {
+		return scaleOut
+	}
+	return -scaleIn
+}
+
+// fetchMetric returns the aggregated metric of the rule, ok is false when there is no data
+func (self *SScalingRule) fetchMetric(guestIds []string, metrics *scalingMetrics) (float64, bool, error) {
+	results, err := metrics.query(self.metricQuery(guestIds))
+	if err != nil {
+		return 0, false, err
+	}
+	if len(results) == 0 || len(results[0]) == 0 || len(results[0][0].Values) == 0 || len(results[0][0].Values[0]) < 2 {
+		return 0, false, nil
+	}
+	value, err := results[0][0].Values[0][1].Float()
+	if err != nil {
+		return 0, false, nil
+	}
+	return value, true, nil
+}
+
+// launchGuest creates a server of the group from its servertemplate
+func (group *SGroup) launchGuest(ctx context.Context, userCred mcclient.TokenCredential) (*SGuest, error) {
+	data := jsonutils.NewDict()
+	data.Set("template_id", jsonutils.NewString(group.ServertemplateId))
+	data.Set("generate_name", jsonutils.NewString(group.Name))
+	if len(group.ZoneId) > 0 {
+		data.Set("prefer_zone_id", jsonutils.NewString(group.ZoneId))
+	}
+	model, err := db.DoCreate(GuestManager, ctx, userCred, nil, data, group.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	guest := model.(*SGuest)
+	func() {
+		lockman.LockObject(ctx, guest)
+		defer lockman.ReleaseObject(ctx, guest)
+
+		guest.PostCreate(ctx, userCred, group.ProjectId, nil, data)
+	}()
+
+	joint := SGroupguest{GuestId: guest.Id}
+	joint.SrvtagId = group.Id
+	joint.SetModelManager(GroupguestManager)
+	err = GroupguestManager.TableSpec().Insert(&joint)
+	if err != nil {
+		log.Errorf("join server %s to group %s fail: %s", guest.Name, group.Name, err)
+	}
+	db.OpsLog.LogEvent(guest, db.ACT_CREATE, fmt.Sprintf("launched by scaling group %s", group.Name), userCred)
+
+	input := new(api.ServerCreateInput)
+	data.Unmarshal(input)
+	pendingUsage := getGuestResourceRequirements(ctx, userCred, input, 1, input.Backup)
+	RunBatchCreateTask(ctx, []db.IModel{guest}, userCred, data, pendingUsage, "GuestBatchCreateTask", "")
+	return guest, nil
+}
+
+// removeGuest takes the server out of the loadbalancer and deletes it
+func (group *SGroup) removeGuest(ctx context.Context, userCred mcclient.TokenCredential, guest *SGuest) error {
+	err := guest.ValidateDeleteCondition(ctx)
+	if err != nil {
+		return err
+	}
+	if len(group.LoadbalancerBackendGroupId) > 0 {
+		backends := make([]SLoadbalancerBackend, 0)
+		q := LoadbalancerBackendManager.Query().Equals("backend_group_id", group.LoadbalancerBackendGroupId).Equals("backend_id", guest.Id)
+		err = db.FetchModelObjects(LoadbalancerBackendManager, q, &backends)
+		if err != nil {
+			return err
+		}
+		for i := range backends {
+			backends[i].SetStatus(userCred, api.LB_STATUS_DELETING, "")
+			err = backends[i].StartLoadBalancerBackendDeleteTask(ctx, userCred, jsonutils.NewDict(), "")
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return guest.StartDeleteGuestTask(ctx, userCred, "", false, false)
+}
+
+// syncLoadbalancerBackends adds the running servers of the group to the backend group
+func (group *SGroup) syncLoadbalancerBackends(ctx context.Context, userCred mcclient.TokenCredential, guests []SGuest) error {
+	if len(group.LoadbalancerBackendGroupId) == 0 {
+		return nil
+	}
+	model, err := LoadbalancerBackendGroupManager.FetchById(group.LoadbalancerBackendGroupId)
+	if err != nil {
+		return fmt.Errorf("fetch loadbalancer backend group %s: %s", group.LoadbalancerBackendGroupId, err)
+	}
+	backendGroup := model.(*SLoadbalancerBackendGroup)
+	backends, err := LoadbalancerBackendManager.getLoadbalancerBackendsByLoadbalancerBackendgroup(backendGroup)
+	if err != nil {
+		return err
+	}
+	attached := make(map[string]bool)
+	for i := range backends {
+		attached[backends[i].BackendId] = true
+	}
+	for i := range guests {
+		if guests[i].Status != VM_RUNNING || attached[guests[i].Id] {
+			continue
+		}
+		data := jsonutils.NewDict()
+		data.Set("backend_group", jsonutils.NewString(backendGroup.Id))
+		data.Set("backend_type", jsonutils.NewString(api.LB_BACKEND_GUEST))
+		data.Set("backend", jsonutils.NewString(guests[i].Id))
+		data.Set("port", jsonutils.NewInt(int64(group.BackendPort)))
+		data.Set("weight", jsonutils.NewInt(int64(group.BackendWeight)))
+		backend, err := db.DoCreate(LoadbalancerBackendManager, ctx, userCred, nil, data, group.ProjectId)
+		if err != nil {
+			log.Errorf("add server %s to loadbalancer backend group %s fail: %s", guests[i].Name, backendGroup.Name, err)
+			continue
+		}
+		func() {
+			lockman.LockObject(ctx, backend)
+			defer lockman.ReleaseObject(ctx, backend)
+
+			backend.PostCreate(ctx, userCred, group.ProjectId, nil, data)
+		}()
+	}
+	return nil
+}
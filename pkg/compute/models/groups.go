@@ -1,6 +1,16 @@
 package models
 
-import "yunion.io/x/onecloud/pkg/cloudcommon/db"
+import (
+	"context"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
 
 const (
 	REDIS_TYPE = "REDIS"
@@ -34,6 +44,164 @@ type SGroup struct {
 	ZoneId string `width:"36" charset:"ascii" nullable:"true" list:"user" update:"user" create:"required"` // Column(VARCHAR(36, charset='ascii'), nullable=True)
 
 	SchedStrategy string `width:"16" charset:"ascii" nullable:"true" default:"" list:"user" update:"user" create:"optional"` // Column(VARCHAR(16, charset='ascii'), nullable=True, default='')
+
+	// auto scaling, the servers of the group are launched from the servertemplate and
+	// kept between MinSize and MaxSize, DesiredSize is moved by the scaling rules
+	ScalingEnabled   bool   `nullable:"false" default:"false" list:"user" update:"user" create:"optional"`
+	MinSize          int    `nullable:"false" default:"0" list:"user" update:"user" create:"optional"`
+	MaxSize          int    `nullable:"false" default:"0" list:"user" update:"user" create:"optional"`
+	DesiredSize      int    `nullable:"false" default:"0" list:"user" update:"user" create:"optional"`
+	ServertemplateId string `width:"36" charset:"ascii" nullable:"true" list:"user" update:"user" create:"optional"`
+	// seconds to wait after a scaling activity before the rules are evaluated again
+	CooldownSeconds int       `nullable:"false" default:"300" list:"user" update:"user" create:"optional"`
+	LastScaleAt     time.Time `nullable:"true" list:"user"`
+
+	// running servers of the group are added to the backend group automatically
+	LoadbalancerBackendGroupId string `width:"36" charset:"ascii" nullable:"true" list:"user" update:"user" create:"optional"`
+	BackendPort                int    `nullable:"false" default:"0" list:"user" update:"user" create:"optional"`
+	BackendWeight              int    `nullable:"false" default:"1" list:"user" update:"user" create:"optional"`
+}
+
+func validateGroupScalingData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, data *jsonutils.JSONDict, group *SGroup) error {
+	for _, key := range []string{"servertemplate", "loadbalancer_backend_group"} {
+		idKey := key + "_id"
+		if !data.Contains(key) && data.Contains(idKey) {
+			val, _ := data.Get(idKey)
+			data.Remove(idKey)
+			data.Set(key, val)
+		}
+		// an empty value unsets the reference
+		if val, err := data.GetString(key); err == nil && len(val) == 0 {
+			data.Remove(key)
+			data.Set(idKey, jsonutils.NewString(""))
+		}
+	}
+	keyV := map[string]validators.IValidator{
+		"zone":                       validators.NewModelIdOrNameValidator("zone", "zone", ""),
+		"servertemplate":             validators.NewModelIdOrNameValidator("servertemplate", "servertemplate", ownerProjId),
+		"loadbalancer_backend_group": validators.NewModelIdOrNameValidator("loadbalancer_backend_group", "loadbalancerbackendgroup", ownerProjId),
+		"min_size":                   validators.NewRangeValidator("min_size", 0, 1000),
+		"max_size":                   validators.NewRangeValidator("max_size", 0, 1000),
+		"desired_size":               validators.NewRangeValidator("desired_size", 0, 1000),
+		"cooldown_seconds":           validators.NewRangeValidator("cooldown_seconds", 0, 86400),
+		"backend_port":               validators.NewPortValidator("backend_port"),
+		"backend_weight":             validators.NewRangeValidator("backend_weight", 1, 256),
+	}
+	for _, v := range keyV {
+		v.Optional(true)
+		if err := v.Validate(data); err != nil {
+			return err
+		}
+	}
+
+	// check the result of the change as a whole
+	merged := GroupManager.scalingConfig(group)
+	merged.Update(data)
+	sizes := struct {
+		ScalingEnabled             bool
+		MinSize                    int
+		MaxSize                    int
+		DesiredSize                int
+		ServertemplateId           string
+		LoadbalancerBackendGroupId string
+		BackendPort                int
+	}{}
+	merged.Unmarshal(&sizes)
+	if sizes.MinSize > sizes.MaxSize {
+		return httperrors.NewInputParameterError("min_size %d is larger than max_size %d", sizes.MinSize, sizes.MaxSize)
+	}
+	if data.Contains("desired_size") && (sizes.DesiredSize < sizes.MinSize || sizes.DesiredSize > sizes.MaxSize) {
+		return httperrors.NewInputParameterError("desired_size should be between min_size %d and max_size %d", sizes.MinSize, sizes.MaxSize)
+	}
+	if sizes.ScalingEnabled && len(sizes.ServertemplateId) == 0 {
+		return httperrors.NewMissingParameterError("servertemplate")
+	}
+	if len(sizes.LoadbalancerBackendGroupId) > 0 && sizes.BackendPort == 0 {
+		return httperrors.NewMissingParameterError("backend_port")
+	}
+	return nil
+}
+
+// scalingConfig returns the scaling columns of group, or the defaults when group is nil
+func (manager *SGroupManager) scalingConfig(group *SGroup) *jsonutils.JSONDict {
+	if group == nil {
+		group = &SGroup{}
+	}
+	ret := jsonutils.NewDict()
+	ret.Set("scaling_enabled", jsonutils.NewBool(group.ScalingEnabled))
+	ret.Set("min_size", jsonutils.NewInt(int64(group.MinSize)))
+	ret.Set("max_size", jsonutils.NewInt(int64(group.MaxSize)))
+	ret.Set("desired_size", jsonutils.NewInt(int64(group.DesiredSize)))
+	ret.Set("servertemplate_id", jsonutils.NewString(group.ServertemplateId))
+	ret.Set("loadbalancer_backend_group_id", jsonutils.NewString(group.LoadbalancerBackendGroupId))
+	ret.Set("backend_port", jsonutils.NewInt(int64(group.BackendPort)))
+	return ret
+}
+
+func (manager *SGroupManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	err := validateGroupScalingData(ctx, userCred, ownerProjId, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !data.Contains("desired_size") {
+		minSize, _ := data.Int("min_size")
+		data.Set("desired_size", jsonutils.NewInt(minSize))
+	}
+	return manager.SVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+}
+
+func (group *SGroup) ValidateUpdateData(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	err := validateGroupScalingData(ctx, userCred, group.ProjectId, data, group)
+	if err != nil {
+		return nil, err
+	}
+	return group.SVirtualResourceBase.ValidateUpdateData(ctx, userCred, query, data)
+}
+
+func (group *SGroup) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
+	extra := group.SVirtualResourceBase.GetCustomizeColumns(ctx, userCred, query)
+	return group.getMoreDetails(extra)
+}
+
+func (group *SGroup) GetExtraDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	extra, err := group.SVirtualResourceBase.GetExtraDetails(ctx, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	return group.getMoreDetails(extra), nil
+}
+
+func (group *SGroup) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSONDict {
+	extra.Add(jsonutils.NewInt(int64(group.getGuestCount())), "guest_count")
+	if group.ScalingEnabled {
+		extra.Add(jsonutils.NewInt(int64(ScalingRuleManager.Query().Equals("group_id", group.Id).Count())), "scaling_rule_count")
+	}
+	return extra
+}
+
+func (group *SGroup) getGuestCount() int {
+	return GroupguestManager.Query().Equals("srvtag_id", group.Id).Count()
+}
+
+func (group *SGroup) ValidateDeleteCondition(ctx context.Context) error {
+	if group.ScalingEnabled && group.getGuestCount() > 0 {
+		return httperrors.NewNotEmptyError("scaling group %s still has servers, scale it to 0 first", group.Name)
+	}
+	return group.SVirtualResourceBase.ValidateDeleteCondition(ctx)
+}
+
+func (group *SGroup) CustomizeDelete(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) error {
+	rules, err := group.GetScalingRules()
+	if err != nil {
+		return err
+	}
+	for i := range rules {
+		err = rules[i].Delete(ctx, userCred)
+		if err != nil {
+			return err
+		}
+	}
+	return group.SVirtualResourceBase.CustomizeDelete(ctx, userCred, query, data)
 }
 
 func (group *SGroup) GetNetworks() ([]SGroupnetwork, error) {
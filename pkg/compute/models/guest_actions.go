@@ -245,46 +245,55 @@ func (self *SGuest) AllowPerformLiveMigrate(ctx context.Context, userCred mcclie
 }
 
 func (self *SGuest) PerformLiveMigrate(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	err := self.CheckLiveMigrate(ctx, userCred)
+	if err != nil {
+		return nil, err
+	}
+	var preferHostId string
+	preferHost, _ := data.GetString("prefer_host")
+	if len(preferHost) > 0 {
+		if !db.IsAdminAllowPerform(userCred, self, "assign-host") {
+			return nil, httperrors.NewBadRequestError("Only system admin can assign host")
+		}
+		iHost, _ := HostManager.FetchByIdOrName(userCred, preferHost)
+		if iHost == nil {
+			return nil, httperrors.NewBadRequestError("Host %s not found", preferHost)
+		}
+		host := iHost.(*SHost)
+		preferHostId = host.Id
+	}
+	err = self.StartGuestLiveMigrateTask(ctx, userCred, self.Status, preferHostId, "")
+	return nil, err
+}
+
+// CheckLiveMigrate reports why the guest can not be live migrated
+func (self *SGuest) CheckLiveMigrate(ctx context.Context, userCred mcclient.TokenCredential) error {
 	if self.GetHypervisor() != HYPERVISOR_KVM {
-		return nil, httperrors.NewNotAcceptableError("Not allow for hypervisor %s", self.GetHypervisor())
+		return httperrors.NewNotAcceptableError("Not allow for hypervisor %s", self.GetHypervisor())
 	}
 	imageId := self.GetDisks()[0].GetDisk().TemplateId
 	image, err := CachedimageManager.GetImageById(ctx, userCred, imageId, false)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if image.DiskFormat != "qcow2" {
-		return nil, httperrors.NewBadRequestError("Live migrate only support image format qocw2")
+		return httperrors.NewBadRequestError("Live migrate only support image format qocw2")
 	}
-	if utils.IsInStringArray(self.Status, []string{VM_RUNNING, VM_SUSPEND}) {
-		cdrom := self.getCdrom()
-		if cdrom != nil && len(cdrom.ImageId) > 0 {
-			return nil, httperrors.NewBadRequestError("Cannot migrate with cdrom")
-		}
-		devices := self.GetIsolatedDevices()
-		if devices != nil && len(devices) > 0 {
-			return nil, httperrors.NewBadRequestError("Cannot migrate with isolated devices")
-		}
-		if !self.CheckQemuVersion(self.GetQemuVersion(userCred), "1.1.2") {
-			return nil, httperrors.NewBadRequestError("Cannot do live migrate, too low qemu version")
-		}
-		var preferHostId string
-		preferHost, _ := data.GetString("prefer_host")
-		if len(preferHost) > 0 {
-			if !db.IsAdminAllowPerform(userCred, self, "assign-host") {
-				return nil, httperrors.NewBadRequestError("Only system admin can assign host")
-			}
-			iHost, _ := HostManager.FetchByIdOrName(userCred, preferHost)
-			if iHost == nil {
-				return nil, httperrors.NewBadRequestError("Host %s not found", preferHost)
-			}
-			host := iHost.(*SHost)
-			preferHostId = host.Id
-		}
-		err := self.StartGuestLiveMigrateTask(ctx, userCred, self.Status, preferHostId, "")
-		return nil, err
+	if !utils.IsInStringArray(self.Status, []string{VM_RUNNING, VM_SUSPEND}) {
+		return httperrors.NewBadRequestError("Cannot live migrate in status %s", self.Status)
+	}
+	cdrom := self.getCdrom()
+	if cdrom != nil && len(cdrom.ImageId) > 0 {
+		return httperrors.NewBadRequestError("Cannot migrate with cdrom")
+	}
+	devices := self.GetIsolatedDevices()
+	if devices != nil && len(devices) > 0 {
+		return httperrors.NewBadRequestError("Cannot migrate with isolated devices")
 	}
-	return nil, httperrors.NewBadRequestError("Cannot live migrate in status %s", self.Status)
+	if !self.CheckQemuVersion(self.GetQemuVersion(userCred), "1.1.2") {
+		return httperrors.NewBadRequestError("Cannot do live migrate, too low qemu version")
+	}
+	return nil
 }
 
 func (self *SGuest) StartGuestLiveMigrateTask(ctx context.Context, userCred mcclient.TokenCredential, guestStatus, preferHostId, parentTaskId string) error {
@@ -2006,6 +2015,186 @@ func (self *SGuest) PerformDiskSnapshot(ctx context.Context, userCred mcclient.T
 
 }
 
+func (self *SGuest) AllowPerformClone(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, self, "clone")
+}
+
+// PerformClone takes snapshots of all disks of the guest, then creates a new guest
+// with the same configuration whose disks are created from these snapshots
+func (self *SGuest) PerformClone(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if self.GetHypervisor() != HYPERVISOR_KVM {
+		return nil, httperrors.NewUnsupportOperationError("Cannot clone guest of hypervisor %s", self.GetHypervisor())
+	}
+	if !utils.IsInStringArray(self.Status, []string{VM_RUNNING, VM_READY}) {
+		return nil, httperrors.NewInvalidStatusError("Cannot clone VM in status %s", self.Status)
+	}
+	name, _ := data.GetString("name")
+	if len(name) == 0 {
+		return nil, httperrors.NewMissingParameterError("name")
+	}
+	err := db.NewNameValidator(GuestManager, self.ProjectId, name)
+	if err != nil {
+		return nil, err
+	}
+
+	guestdisks := self.GetDisks()
+	if len(guestdisks) == 0 {
+		return nil, httperrors.NewBadRequestError("Guest %s has no disk", self.Name)
+	}
+	for i := range guestdisks {
+		q := SnapshotManager.Query()
+		cnt := q.Filter(sqlchemy.AND(sqlchemy.Equals(q.Field("disk_id"), guestdisks[i].DiskId),
+			sqlchemy.Equals(q.Field("created_by"), MANUAL),
+			sqlchemy.Equals(q.Field("fake_deleted"), false))).Count()
+		if cnt >= options.Options.DefaultMaxManualSnapshotCount {
+			return nil, httperrors.NewBadRequestError("Disk %s snapshot full, cannot take any more", guestdisks[i].DiskId)
+		}
+		err = ValidateSnapshotName(self.Hypervisor, fmt.Sprintf("%s-%d", name, guestdisks[i].Index), self.ProjectId)
+		if err != nil {
+			return nil, httperrors.NewBadRequestError(err.Error())
+		}
+	}
+
+	pendingUsage := &SQuota{Snapshot: len(guestdisks)}
+	err = QuotaManager.CheckSetPendingQuota(ctx, userCred, self.ProjectId, pendingUsage)
+	if err != nil {
+		return nil, httperrors.NewOutOfQuotaError("Check set pending quota error %s", err)
+	}
+	defer QuotaManager.CancelPendingUsage(ctx, userCred, self.ProjectId, nil, pendingUsage)
+
+	snapshots := make([]*SSnapshot, 0, len(guestdisks))
+	for i := range guestdisks {
+		snapshot, err := SnapshotManager.createSnapshot(ctx, self.ProjectId, MANUAL, "", guestdisks[i].DiskId, "",
+			fmt.Sprintf("%s-%d", name, guestdisks[i].Index))
+		if err != nil {
+			for j := range snapshots {
+				snapshots[j].RealDelete(ctx, userCred)
+			}
+			return nil, httperrors.NewGeneralError(err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	snapshotIds := make([]string, len(snapshots))
+	for i := range snapshots {
+		snapshotIds[i] = snapshots[i].Id
+	}
+
+	params := jsonutils.NewDict()
+	params.Add(jsonutils.NewString(name), "name")
+	params.Add(jsonutils.NewStringArray(snapshotIds), "snapshot_ids")
+	params.Add(jsonutils.NewBool(jsonutils.QueryBoolean(data, "auto_start", false)), "auto_start")
+	return nil, self.StartGuestCloneTask(ctx, userCred, params, "")
+}
+
+func (self *SGuest) StartGuestCloneTask(ctx context.Context, userCred mcclient.TokenCredential, params *jsonutils.JSONDict, parentTaskId string) error {
+	task, err := taskman.TaskManager.NewTask(ctx, "GuestCloneTask", self, userCred, params, parentTaskId, "", nil)
+	if err != nil {
+		return err
+	}
+	db.OpsLog.LogEvent(self, db.ACT_CLONING, params, userCred)
+	task.ScheduleRun(nil)
+	return nil
+}
+
+// GetCloneCreateInput returns the create input of a guest with the same configuration
+// as this one, whose disks are created from the given snapshots, one for each disk
+func (self *SGuest) GetCloneCreateInput(name string, snapshotIds []string, autoStart bool) (*api.ServerCreateInput, error) {
+	guestdisks := self.GetDisks()
+	if len(guestdisks) != len(snapshotIds) {
+		return nil, fmt.Errorf("guest has %d disks but %d snapshots given", len(guestdisks), len(snapshotIds))
+	}
+	resetPassword := false
+	input := &api.ServerCreateInput{
+		ServerConfigs: &api.ServerConfigs{
+			Hypervisor: self.Hypervisor,
+		},
+		Name:             name,
+		VmemSize:         self.VmemSize,
+		VcpuCount:        int(self.VcpuCount),
+		Vga:              self.Vga,
+		Vdi:              self.Vdi,
+		Bios:             self.Bios,
+		BootOrder:        self.BootOrder,
+		ShutdownBehavior: self.ShutdownBehavior,
+		Description:      self.Description,
+		OsType:           self.OsType,
+		Keypair:          self.KeypairId,
+		Secgroup:         self.SecgrpId,
+		ResetPassword:    &resetPassword,
+		AutoStart:        autoStart,
+	}
+	if host := self.GetHost(); host != nil {
+		input.PreferZone = host.ZoneId
+	}
+	for i := range guestdisks {
+		disk := guestdisks[i].GetDisk()
+		if disk == nil {
+			return nil, fmt.Errorf("disk %s of guest %s not found", guestdisks[i].DiskId, self.Name)
+		}
+		diskConf := &api.DiskConfig{
+			Index:      i,
+			SnapshotId: snapshotIds[i],
+			Driver:     guestdisks[i].Driver,
+			Cache:      guestdisks[i].CacheMode,
+			Mountpoint: guestdisks[i].Mountpoint,
+		}
+		// disks can only be created from snapshots on the storage the snapshots reside
+		if storage := disk.GetStorage(); storage != nil && storage.IsLocal() {
+			diskConf.Storage = storage.Id
+		}
+		input.Disks = append(input.Disks, diskConf)
+	}
+	guestnics, err := self.GetNetworks("")
+	if err != nil {
+		return nil, err
+	}
+	for i := range guestnics {
+		if guestnics[i].Virtual {
+			continue
+		}
+		input.Networks = append(input.Networks, &api.NetworkConfig{
+			Index:   len(input.Networks),
+			Network: guestnics[i].NetworkId,
+			Driver:  guestnics[i].Driver,
+			BwLimit: guestnics[i].BwLimit,
+		})
+	}
+	return input, nil
+}
+
+// CreateClone creates the guest described by input in the project of this guest,
+// the creation is carried on by a GuestBatchCreateTask under parentTaskId
+func (self *SGuest) CreateClone(ctx context.Context, userCred mcclient.TokenCredential, input *api.ServerCreateInput, parentTaskId string) (*SGuest, error) {
+	params := input.JSON(input)
+	model, err := db.DoCreate(GuestManager, ctx, userCred, nil, params, self.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	guest := model.(*SGuest)
+	func() {
+		lockman.LockObject(ctx, guest)
+		defer lockman.ReleaseObject(ctx, guest)
+
+		guest.PostCreate(ctx, userCred, self.ProjectId, nil, params)
+	}()
+	for _, secgroup := range self.GetSecgroups() {
+		if secgroup.Id == guest.SecgrpId {
+			continue
+		}
+		_, err := GuestsecgroupManager.newGuestSecgroup(ctx, userCred, guest, &secgroup)
+		if err != nil {
+			log.Errorf("clone secgroup %s to guest %s fail: %s", secgroup.Name, guest.Name, err)
+		}
+	}
+	db.OpsLog.LogEvent(guest, db.ACT_CREATE, fmt.Sprintf("clone of %s", self.Name), userCred)
+
+	createInput := new(api.ServerCreateInput)
+	params.Unmarshal(createInput)
+	pendingUsage := getGuestResourceRequirements(ctx, userCred, createInput, 1, createInput.Backup)
+	RunBatchCreateTask(ctx, []db.IModel{guest}, userCred, params, pendingUsage, "GuestBatchCreateTask", parentTaskId)
+	return guest, nil
+}
+
 func (self *SGuest) AllowPerformSyncstatus(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
 	return self.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, self, "syncstatus")
 }
@@ -2284,14 +2473,15 @@ func (self *SGuest) PerformCreateEip(ctx context.Context, userCred mcclient.Toke
 	}
 
 	eipPendingUsage := &SQuota{Eip: 1}
-	err = QuotaManager.CheckSetPendingQuota(ctx, userCred, userCred.GetProjectId(), eipPendingUsage)
+	scopes := getQuotaScopes(region.Id, "")
+	err = QuotaManager.CheckSetPendingScopedQuota(ctx, userCred, userCred.GetProjectId(), scopes, eipPendingUsage)
 	if err != nil {
 		return nil, httperrors.NewOutOfQuotaError("Out of eip quota: %s", err)
 	}
 
-	err = ElasticipManager.AllocateEipAndAssociateVM(ctx, userCred, self, int(bw), chargeType, eipPendingUsage)
+	err = ElasticipManager.AllocateEipAndAssociateVM(ctx, userCred, self, int(bw), chargeType, eipPendingUsage, scopes)
 	if err != nil {
-		QuotaManager.CancelPendingUsage(ctx, userCred, userCred.GetProjectId(), eipPendingUsage, eipPendingUsage)
+		QuotaManager.CancelPendingScopedUsage(ctx, userCred, userCred.GetProjectId(), scopes, eipPendingUsage, eipPendingUsage)
 		return nil, httperrors.NewGeneralError(err)
 	}
 
@@ -144,6 +144,7 @@ const (
 	HYPERVISOR_AWS       = api.HYPERVISOR_AWS
 	HYPERVISOR_HUAWEI    = api.HYPERVISOR_HUAWEI
 	HYPERVISOR_OPENSTACK = api.HYPERVISOR_OPENSTACK
+	HYPERVISOR_GOOGLE    = api.HYPERVISOR_GOOGLE
 
 	//	HYPERVISOR_DEFAULT = HYPERVISOR_KVM
 	HYPERVISOR_DEFAULT = HYPERVISOR_KVM
@@ -212,6 +213,13 @@ type SGuest struct {
 	Hypervisor string `width:"16" charset:"ascii" nullable:"false" default:"kvm" list:"user" create:"required"` // Column(VARCHAR(16, charset='ascii'), nullable=False, default=HYPERVISOR_DEFAULT)
 
 	InstanceType string `width:"64" charset:"ascii" nullable:"true" list:"user" create:"optional"`
+
+	// restart the guest on another host when its host goes offline
+	HaPolicy   string `width:"16" charset:"ascii" nullable:"false" default:"none" list:"user" update:"user" create:"optional"`
+	HaPriority int    `nullable:"false" default:"0" list:"user" update:"user" create:"optional"`
+
+	// place the vcpus and memory of the guest on numa nodes of its host
+	NumaPolicy string `width:"16" charset:"ascii" nullable:"false" default:"none" list:"user" create:"optional"`
 }
 
 func (manager *SGuestManager) AllowListItems(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
@@ -796,15 +804,61 @@ func (self *SGuest) ValidateUpdateData(ctx context.Context, userCred mcclient.To
 			return nil, httperrors.NewInputParameterError("name is too short")
 		}
 	}
+
+	if haPolicy, _ := data.GetString("ha_policy"); len(haPolicy) > 0 {
+		if !api.GUEST_HA_POLICIES.Has(haPolicy) {
+			return nil, httperrors.NewInputParameterError("invalid ha_policy %s", haPolicy)
+		}
+		if haPolicy != api.GUEST_HA_POLICY_NONE && self.GetHypervisor() != HYPERVISOR_KVM {
+			return nil, httperrors.NewNotAcceptableError("ha_policy %s not supported for hypervisor %s", haPolicy, self.GetHypervisor())
+		}
+	}
 	return self.SVirtualResourceBase.ValidateUpdateData(ctx, userCred, query, data)
 }
 
 func (manager *SGuestManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	if templateId := jsonutils.GetAnyString(data, []string{"template", "template_id"}); len(templateId) > 0 {
+		err := ServertemplateManager.MergeCreateData(ctx, userCred, templateId, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// TODO: 定义 api.ServerCreateInput 的 Unmarshal 函数，直接通过 data.Unmarshal(input) 解析参数
 	input, err := cmdline.FetchServerCreateInputByJSON(data)
 	if err != nil {
 		return nil, err
 	}
+	input, err = manager.validateCreateInput(ctx, userCred, input, data)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = manager.SVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, input.JSON(input))
+	if err != nil {
+		return nil, err
+	}
+	if err := data.Unmarshal(input); err != nil {
+		return nil, err
+	}
+
+	if !input.IsSystem {
+		err = manager.checkCreateQuota(ctx, userCred, ownerProjId, input,
+			input.Backup)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	input.Project = ownerProjId
+	return input.JSON(input), nil
+}
+
+// validateCreateInput checks and normalizes the server specific part of a
+// create request, it does not touch quota so that it can also be used to
+// validate server templates
+func (manager *SGuestManager) validateCreateInput(ctx context.Context, userCred mcclient.TokenCredential, input *api.ServerCreateInput, data *jsonutils.JSONDict) (*api.ServerCreateInput, error) {
+	var err error
 	resetPassword := true
 	if input.ResetPassword != nil {
 		resetPassword = *input.ResetPassword
@@ -1025,6 +1079,24 @@ func (manager *SGuestManager) ValidateCreateData(ctx context.Context, userCred m
 		input.IsolatedDevices[idx] = devConfig
 	}
 
+	if len(input.HaPolicy) > 0 {
+		if !api.GUEST_HA_POLICIES.Has(input.HaPolicy) {
+			return nil, httperrors.NewInputParameterError("invalid ha_policy %s", input.HaPolicy)
+		}
+		if input.HaPolicy != api.GUEST_HA_POLICY_NONE && hypervisor != HYPERVISOR_KVM {
+			return nil, httperrors.NewNotAcceptableError("ha_policy %s not supported for hypervisor %s", input.HaPolicy, hypervisor)
+		}
+	}
+
+	if len(input.NumaPolicy) > 0 {
+		if !api.GUEST_NUMA_POLICIES.Has(input.NumaPolicy) {
+			return nil, httperrors.NewInputParameterError("invalid numa_policy %s", input.NumaPolicy)
+		}
+		if input.NumaPolicy != api.GUEST_NUMA_POLICY_NONE && hypervisor != HYPERVISOR_KVM {
+			return nil, httperrors.NewNotAcceptableError("numa_policy %s not supported for hypervisor %s", input.NumaPolicy, hypervisor)
+		}
+	}
+
 	keypairId := input.Keypair
 	if len(keypairId) > 0 {
 		keypairObj, err := KeypairManager.FetchByIdOrName(userCred, keypairId)
@@ -1090,33 +1162,20 @@ func (manager *SGuestManager) ValidateCreateData(ctx context.Context, userCred m
 
 		}*/
 
-	input, err = GetDriver(hypervisor).ValidateCreateData(ctx, userCred, input)
-	if err != nil {
-		return nil, err
-	}
-
-	data, err = manager.SVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, input.JSON(input))
-	if err != nil {
-		return nil, err
-	}
-	if err := data.Unmarshal(input); err != nil {
-		return nil, err
-	}
-
-	if !input.IsSystem {
-		err = manager.checkCreateQuota(ctx, userCred, ownerProjId, input,
-			input.Backup)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	input.Project = ownerProjId
-	return input.JSON(input), nil
+	return GetDriver(hypervisor).ValidateCreateData(ctx, userCred, input)
 }
 
 func (manager *SGuestManager) checkCreateQuota(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, input *api.ServerCreateInput, hasBackup bool) error {
 	req := getGuestResourceRequirements(ctx, userCred, input, 1, hasBackup)
+	if req.Eip > 0 && len(input.PreferRegion) > 0 {
+		// the new eip is reserved globally with the server as its region
+		// may not be known until scheduled, check the quota of the
+		// preferred region beforehand
+		_, err := QuotaManager.CheckScopedQuota(ctx, userCred, ownerProjId, quotas.SQuotaScope{RegionId: input.PreferRegion}, &SQuota{Eip: req.Eip})
+		if err != nil {
+			return httperrors.NewOutOfQuotaError(err.Error())
+		}
+	}
 	err := QuotaManager.CheckSetPendingQuota(ctx, userCred, ownerProjId, &req)
 	if err != nil {
 		return httperrors.NewOutOfQuotaError(err.Error())
@@ -1236,7 +1295,7 @@ func (manager *SGuestManager) OnCreateComplete(ctx context.Context, items []db.I
 	input := new(api.ServerCreateInput)
 	data.Unmarshal(input)
 	pendingUsage := getGuestResourceRequirements(ctx, userCred, input, len(items), input.Backup)
-	RunBatchCreateTask(ctx, items, userCred, data, pendingUsage, "GuestBatchCreateTask")
+	RunBatchCreateTask(ctx, items, userCred, data, pendingUsage, "GuestBatchCreateTask", "")
 }
 
 func (guest *SGuest) GetGroups() []SGroupguest {
@@ -2864,6 +2923,10 @@ func (self *SGuest) Delete(ctx context.Context, userCred mcclient.TokenCredentia
 }
 
 func (self *SGuest) RealDelete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	err := SnapshotPolicyGuestManager.DetachGuest(ctx, userCred, self.Id)
+	if err != nil {
+		log.Errorf("guest %s detach snapshot policies fail %s", self.Id, err)
+	}
 	return self.SVirtualResourceBase.Delete(ctx, userCred)
 }
 
@@ -2986,7 +3049,7 @@ func (self *SGuest) GetDeployConfigOnHost(ctx context.Context, userCred mcclient
 		registerVpcId := vpc.ExternalId
 		externalVpcId := vpc.ExternalId
 		switch self.Hypervisor {
-		case HYPERVISOR_ALIYUN, HYPERVISOR_AWS, HYPERVISOR_HUAWEI:
+		case HYPERVISOR_ALIYUN, HYPERVISOR_AWS, HYPERVISOR_HUAWEI, HYPERVISOR_GOOGLE:
 			break
 		case HYPERVISOR_QCLOUD, HYPERVISOR_OPENSTACK:
 			registerVpcId = "normal"
@@ -3078,6 +3141,9 @@ func (self *SGuest) GetJsonDescAtHypervisor(ctx context.Context, host *SHost) *j
 	desc.Add(jsonutils.NewString(self.getMachine()), "machine")
 	desc.Add(jsonutils.NewString(self.getBios()), "bios")
 	desc.Add(jsonutils.NewString(self.BootOrder), "boot_order")
+	if len(self.NumaPolicy) > 0 && self.NumaPolicy != api.GUEST_NUMA_POLICY_NONE {
+		desc.Add(jsonutils.NewString(self.NumaPolicy), "numa_policy")
+	}
 
 	if len(self.BackupHostId) > 0 {
 		if self.HostId == host.Id {
@@ -3936,6 +4002,8 @@ func (self *SGuest) GetApptags() []string {
 func (self *SGuest) ToSchedDesc() *schedapi.ScheduleInput {
 	desc := new(schedapi.ScheduleInput)
 	config := &schedapi.ServerConfig{
+		ServerConfigs: new(api.ServerConfigs),
+
 		Name:   self.Name,
 		Memory: self.VmemSize,
 		Ncpu:   int(self.VcpuCount),
@@ -3953,6 +4021,7 @@ func (self *SGuest) ToSchedDesc() *schedapi.ScheduleInput {
 	}*/
 
 	config.Hypervisor = self.GetHypervisor()
+	config.NumaPolicy = self.NumaPolicy
 	desc.ServerConfig = *config
 	return desc
 }
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+const (
+	HOST_METADATA_DRAIN_STATUS = "__drain_status"
+	HOST_METADATA_DRAIN_GUESTS = "__drain_guests"
+)
+
+func (self *SHost) AllowPerformDrain(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return db.IsAdminAllowPerform(userCred, self, "drain")
+}
+
+// PerformDrain puts a kvm host in maintenance so that the scheduler skips it,
+// then migrates all its guests to other hosts
+func (self *SHost) PerformDrain(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if self.HostType != HOST_TYPE_HYPERVISOR {
+		return nil, httperrors.NewNotAcceptableError("Cannot drain host of type %s", self.HostType)
+	}
+	if self.GetMetadata(HOST_METADATA_DRAIN_STATUS, userCred) == api.HOST_DRAIN_STATUS_DRAINING {
+		return nil, httperrors.NewInvalidStatusError("Host is draining")
+	}
+	_, err := db.Update(self, func() error {
+		self.IsMaintenance = true
+		return nil
+	})
+	if err != nil {
+		return nil, httperrors.NewGeneralError(err)
+	}
+	self.ClearSchedDescCache()
+
+	guests := self.GetGuests()
+	drainGuests := make([]api.HostDrainGuest, len(guests))
+	for i := range guests {
+		drainGuests[i] = api.HostDrainGuest{
+			GuestId: guests[i].Id,
+			Name:    guests[i].Name,
+			Status:  api.HOST_DRAIN_GUEST_PENDING,
+		}
+	}
+	err = self.SetDrainProgress(ctx, userCred, api.HOST_DRAIN_STATUS_DRAINING, drainGuests)
+	if err != nil {
+		return nil, err
+	}
+	db.OpsLog.LogEvent(self, db.ACT_DRAINING, "", userCred)
+
+	task, err := taskman.TaskManager.NewTask(ctx, "HostDrainTask", self, userCred, nil, "", "", nil)
+	if err != nil {
+		log.Errorf("start HostDrainTask: %v", err)
+		return nil, err
+	}
+	task.ScheduleRun(nil)
+	return nil, nil
+}
+
+func (self *SHost) AllowPerformUndrain(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return db.IsAdminAllowPerform(userCred, self, "undrain")
+}
+
+// PerformUndrain releases a drained host from maintenance
+func (self *SHost) PerformUndrain(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	status := self.GetMetadata(HOST_METADATA_DRAIN_STATUS, userCred)
+	if len(status) == 0 {
+		return nil, httperrors.NewInvalidStatusError("Host is not drained")
+	}
+	if status == api.HOST_DRAIN_STATUS_DRAINING {
+		return nil, httperrors.NewInvalidStatusError("Host is draining")
+	}
+	_, err := db.Update(self, func() error {
+		self.IsMaintenance = false
+		return nil
+	})
+	if err != nil {
+		return nil, httperrors.NewGeneralError(err)
+	}
+	self.ClearSchedDescCache()
+	self.RemoveMetadata(ctx, HOST_METADATA_DRAIN_STATUS, userCred)
+	self.RemoveMetadata(ctx, HOST_METADATA_DRAIN_GUESTS, userCred)
+	db.OpsLog.LogEvent(self, db.ACT_UNDRAIN, "", userCred)
+	return nil, nil
+}
+
+func (self *SHost) AllowGetDetailsDrain(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
+	return db.IsAdminAllowGetSpec(userCred, self, "drain")
+}
+
+// GetDetailsDrain reports the progress of the guests of a draining host
+func (self *SHost) GetDetailsDrain(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	progress, err := self.GetDrainProgress(userCred)
+	if err != nil {
+		return nil, err
+	}
+	return jsonutils.Marshal(progress), nil
+}
+
+func (self *SHost) GetDrainProgress(userCred mcclient.TokenCredential) (*api.HostDrainProgress, error) {
+	progress := &api.HostDrainProgress{
+		Status: self.GetMetadata(HOST_METADATA_DRAIN_STATUS, userCred),
+		Guests: []api.HostDrainGuest{},
+	}
+	guests := self.GetMetadataJson(HOST_METADATA_DRAIN_GUESTS, userCred)
+	if guests != nil {
+		err := guests.Unmarshal(&progress.Guests)
+		if err != nil {
+			return nil, fmt.Errorf("invalid drain guests: %v", err)
+		}
+	}
+	return progress, nil
+}
+
+func (self *SHost) SetDrainProgress(ctx context.Context, userCred mcclient.TokenCredential, status string, guests []api.HostDrainGuest) error {
+	return self.SetAllMetadata(ctx, map[string]interface{}{
+		HOST_METADATA_DRAIN_STATUS: status,
+		HOST_METADATA_DRAIN_GUESTS: jsonutils.Marshal(guests),
+	}, userCred)
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/utils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/baremetal/utils/ipmitool"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+const (
+	HOST_METADATA_HA_STATUS = "__ha_status"
+	// ids of ha guests running when the host went offline
+	HOST_METADATA_HA_GUESTS = "__ha_guests"
+)
+
+// GetHaGuests returns the guests of the host in the given status which can be
+// restarted on other hosts, in the order of their ha_priority
+func (self *SHost) GetHaGuests(status ...string) []SGuest {
+	if len(status) == 0 {
+		status = []string{VM_RUNNING}
+	}
+	guests := make([]SGuest, 0)
+	for _, guest := range self.GetGuests() {
+		if guest.HaPolicy != api.GUEST_HA_POLICY_RESTART || !utils.IsInStringArray(guest.Status, status) {
+			continue
+		}
+		if err := guest.checkHaRestart(); err != nil {
+			log.Warningf("guest %s can not be restarted by ha: %s", guest.Name, err)
+			continue
+		}
+		guests = append(guests, guest)
+	}
+	sort.SliceStable(guests, func(i, j int) bool {
+		return guests[i].HaPriority > guests[j].HaPriority
+	})
+	return guests
+}
+
+// checkHaRestart makes sure the guest can be started on another host without
+// its current host, i.e. all its disks are on shared storages
+func (self *SGuest) checkHaRestart() error {
+	if self.GetHypervisor() != HYPERVISOR_KVM {
+		return fmt.Errorf("hypervisor %s not supported", self.GetHypervisor())
+	}
+	if len(self.BackupHostId) > 0 {
+		return fmt.Errorf("guest has a backup")
+	}
+	if len(self.GetIsolatedDevices()) > 0 {
+		return fmt.Errorf("guest has isolated devices")
+	}
+	for _, guestDisk := range self.GetDisks() {
+		disk := guestDisk.GetDisk()
+		if disk == nil {
+			return fmt.Errorf("disk %s not found", guestDisk.DiskId)
+		}
+		storage := disk.GetStorage()
+		if storage == nil || utils.IsInStringArray(storage.StorageType, STORAGE_LOCAL_TYPES) {
+			return fmt.Errorf("disk %s is not on shared storage", guestDisk.DiskId)
+		}
+	}
+	return nil
+}
+
+// SetOfflineHaGuests records the ha guests running before the host went
+// offline.  Guests are all marked unknown once the host is offline, the
+// record tells those to be restarted from those stopped on purpose
+func (self *SHost) SetOfflineHaGuests(ctx context.Context, userCred mcclient.TokenCredential, guests []SGuest) {
+	guestIds := make([]string, len(guests))
+	for i := range guests {
+		guestIds[i] = guests[i].Id
+	}
+	self.SetMetadata(ctx, HOST_METADATA_HA_GUESTS, strings.Join(guestIds, ","), userCred)
+}
+
+// GetOfflineHaGuests returns the recorded ha guests which are not yet
+// restarted or stopped since the host went offline
+func (self *SHost) GetOfflineHaGuests(userCred mcclient.TokenCredential) []SGuest {
+	guestIds := strings.Split(self.GetMetadata(HOST_METADATA_HA_GUESTS, userCred), ",")
+	guests := make([]SGuest, 0)
+	for _, guest := range self.GetHaGuests(VM_UNKNOWN, VM_RUNNING) {
+		if utils.IsInStringArray(guest.Id, guestIds) {
+			guests = append(guests, guest)
+		}
+	}
+	return guests
+}
+
+// FenceByIpmi powers off the host through its BMC so that the guests on the
+// shared storages can not be run twice
+func (self *SHost) FenceByIpmi() error {
+	info, ok := self.IpmiInfo.(*jsonutils.JSONDict)
+	if !ok {
+		return fmt.Errorf("no ipmi information of host %s", self.Name)
+	}
+	ipAddr, _ := info.GetString("ip_addr")
+	username, _ := info.GetString("username")
+	password, _ := info.GetString("password")
+	if len(ipAddr) == 0 || len(username) == 0 || len(password) == 0 {
+		return fmt.Errorf("incomplete ipmi information of host %s", self.Name)
+	}
+	password, err := utils.DescryptAESBase64(self.Id, password)
+	if err != nil {
+		return fmt.Errorf("decrypt ipmi password: %v", err)
+	}
+	ipmi := ipmitool.NewLanPlusIPMI(ipAddr, username, password)
+	status, err := ipmitool.GetChassisPowerStatus(ipmi)
+	if err != nil {
+		return fmt.Errorf("get chassis power status: %v", err)
+	}
+	if status != "off" {
+		err = ipmitool.DoHardShutdown(ipmi)
+		if err != nil {
+			return fmt.Errorf("hard shutdown: %v", err)
+		}
+		status, err = ipmitool.GetChassisPowerStatus(ipmi)
+		if err != nil {
+			return fmt.Errorf("get chassis power status: %v", err)
+		}
+	}
+	if status != "off" {
+		return fmt.Errorf("chassis power is %s after shutdown", status)
+	}
+	return nil
+}
+
+func (self *SHost) StartHaTask(ctx context.Context, userCred mcclient.TokenCredential, guests []SGuest, parentTaskId string) error {
+	guestIds := make([]string, len(guests))
+	for i := range guests {
+		guestIds[i] = guests[i].Id
+	}
+	params := jsonutils.NewDict()
+	params.Set("guest_ids", jsonutils.NewStringArray(guestIds))
+	task, err := taskman.TaskManager.NewTask(ctx, "HostHaTask", self, userCred, params, parentTaskId, "", nil)
+	if err != nil {
+		log.Errorf("start HostHaTask: %v", err)
+		return err
+	}
+	self.SetMetadata(ctx, HOST_METADATA_HA_STATUS, api.HOST_HA_STATUS_FENCING, userCred)
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (self *SHost) AllowPerformHaRestart(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return db.IsAdminAllowPerform(userCred, self, "ha-restart")
+}
+
+// PerformHaRestart fences an offline host and restarts its guests of ha_policy
+// restart which were running when the host went offline, e.g. to retry after
+// a failed fencing
+func (self *SHost) PerformHaRestart(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if self.HostType != HOST_TYPE_HYPERVISOR {
+		return nil, httperrors.NewNotAcceptableError("Cannot ha restart guests of host type %s", self.HostType)
+	}
+	if self.HostStatus != HOST_OFFLINE {
+		return nil, httperrors.NewInvalidStatusError("Cannot ha restart guests of host in status %s", self.HostStatus)
+	}
+	status := self.GetMetadata(HOST_METADATA_HA_STATUS, userCred)
+	if utils.IsInStringArray(status, []string{api.HOST_HA_STATUS_FENCING, api.HOST_HA_STATUS_RESTARTING}) {
+		return nil, httperrors.NewInvalidStatusError("Host ha is %s", status)
+	}
+	// guests stopped before the host went offline stay stopped
+	guests := self.GetOfflineHaGuests(userCred)
+	if len(guests) == 0 {
+		return nil, httperrors.NewNotFoundError("No guest of ha_policy %s on host %s", api.GUEST_HA_POLICY_RESTART, self.Name)
+	}
+	return nil, self.StartHaTask(ctx, userCred, guests, "")
+}
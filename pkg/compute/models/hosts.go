@@ -52,6 +52,7 @@ const (
 	HOST_TYPE_AZURE     = api.HOST_TYPE_AZURE
 	HOST_TYPE_HUAWEI    = api.HOST_TYPE_HUAWEI
 	HOST_TYPE_OPENSTACK = api.HOST_TYPE_OPENSTACK
+	HOST_TYPE_GOOGLE    = api.HOST_TYPE_GOOGLE
 
 	HOST_TYPE_DEFAULT = HOST_TYPE_HYPERVISOR
 
@@ -2780,6 +2781,7 @@ func (self *SHost) PerformPing(ctx context.Context, userCred mcclient.TokenCrede
 	} else {
 		self.SaveUpdates(func() error {
 			self.LastPingAt = time.Now()
+			self.updateSysInfoNumaNodes(data)
 			return nil
 		})
 	}
@@ -2802,6 +2804,21 @@ func (self *SHost) PerformPing(ctx context.Context, userCred mcclient.TokenCrede
 	return result, nil
 }
 
+// updateSysInfoNumaNodes saves the numa nodes with free memory reported by
+// host on ping, which are used by the scheduler to place numa guests
+func (self *SHost) updateSysInfoNumaNodes(data jsonutils.JSONObject) {
+	if data == nil || !data.Contains("numa_nodes") {
+		return
+	}
+	numaNodes, _ := data.Get("numa_nodes")
+	sysInfo := jsonutils.NewDict()
+	if info, ok := self.SysInfo.(*jsonutils.JSONDict); ok {
+		sysInfo = info.Copy()
+	}
+	sysInfo.Set("numa_nodes", numaNodes)
+	self.SysInfo = sysInfo
+}
+
 func (self *SHost) AllowPerformPrepare(ctx context.Context,
 	userCred mcclient.TokenCredential,
 	query jsonutils.JSONObject,
@@ -3746,8 +3763,14 @@ func (manager *SHostManager) PingDetectionTask(ctx context.Context, userCred mcc
 		var host = SHost{}
 		q.Row2Struct(rows, &host)
 		host.SetModelManager(manager)
+		// collect before the guests are marked unknown
+		haGuests := host.GetHaGuests()
+		host.SetOfflineHaGuests(ctx, userCred, haGuests)
 		host.PerformOffline(ctx, userCred, nil, nil)
 		host.MarkGuestUnknown(userCred)
+		if options.Options.EnableHostHa && len(haGuests) > 0 {
+			host.StartHaTask(ctx, userCred, haGuests, "")
+		}
 	}
 }
 
@@ -80,6 +80,9 @@ type SLoadbalancerAgentParamsHaproxy struct {
 	LogHttp        bool
 	LogTcp         bool
 	LogNormal      bool
+	// AccessLog enables collecting access logs of all listeners, which
+	// are written to influxdb of telegraf params
+	AccessLog bool
 }
 
 type SLoadbalancerAgentParamsTelegraf struct {
@@ -462,6 +465,8 @@ func (lbagent *SLoadbalancerAgent) PerformHb(ctx context.Context, userCred mccli
 		log.Infof("lbagent %s(%s) state changed: %s", lbagent.Name, lbagent.Id, diff)
 		db.OpsLog.LogEvent(lbagent, db.ACT_UPDATE, diff, userCred)
 	}
+	lbagent.updateBackendHealthStatuses(ctx, userCred, data)
+	lbagent.updateBackendDrainStates(ctx, userCred, data)
 	return nil, nil
 }
 
@@ -498,6 +503,8 @@ func (lbagent *SLoadbalancerAgent) PerformParamsPatch(ctx context.Context, userC
 		}
 		db.OpsLog.LogEvent(lbagent, db.ACT_UPDATE, diff, userCred)
 	}
+	lbagent.updateBackendHealthStatuses(ctx, userCred, data)
+	lbagent.updateBackendDrainStates(ctx, userCred, data)
 	return nil, nil
 }
 
@@ -200,10 +200,17 @@ func (lbbg *SLoadbalancerBackendGroup) RefCount() int {
 func (lbbg *SLoadbalancerBackendGroup) refCount(men db.IModelManager) int {
 	t := men.TableSpec().Instance()
 	pdF := t.Field("pending_deleted")
-	return t.Query().
-		Equals("backend_group_id", lbbg.Id).
-		Filter(sqlchemy.OR(sqlchemy.IsNull(pdF), sqlchemy.IsFalse(pdF))).
-		Count()
+	q := t.Query().Filter(sqlchemy.OR(sqlchemy.IsNull(pdF), sqlchemy.IsFalse(pdF)))
+	// 监听及转发规则还可能以灰度后端服务器组引用
+	if men == LoadbalancerListenerManager || men == LoadbalancerListenerRuleManager {
+		q = q.Filter(sqlchemy.OR(
+			sqlchemy.Equals(t.Field("backend_group_id"), lbbg.Id),
+			sqlchemy.Equals(t.Field("canary_backend_group_id"), lbbg.Id),
+		))
+	} else {
+		q = q.Equals("backend_group_id", lbbg.Id)
+	}
+	return q.Count()
 }
 
 func (lbbg *SLoadbalancerBackendGroup) getRefManagers() []db.IModelManager {
@@ -237,6 +244,48 @@ func (lbbg *SLoadbalancerBackendGroup) ValidateDeleteCondition(ctx context.Conte
 	return region.GetDriver().ValidateDeleteLoadbalancerBackendGroupCondition(ctx, lbbg)
 }
 
+// loadbalancerValidateCanary 校验监听或转发规则的灰度后端服务器组及分流百分比,
+// backendGroupId, canaryBackendGroupId 为更新前的值, 灰度后端服务器组传空值表示取消灰度
+func loadbalancerValidateCanary(data *jsonutils.JSONDict, ownerProjId, lbId, managerId, listenerType, backendGroupId, canaryBackendGroupId string) error {
+	if data.Contains("backend_group_id") {
+		backendGroupId, _ = data.GetString("backend_group_id")
+	}
+	if data.Contains("canary_backend_group") {
+		if canary, _ := data.GetString("canary_backend_group"); len(canary) == 0 {
+			data.Remove("canary_backend_group")
+			data.Set("canary_backend_group_id", jsonutils.NewString(""))
+			data.Set("canary_weight", jsonutils.NewInt(0))
+			return nil
+		}
+		canaryV := validators.NewModelIdOrNameValidator("canary_backend_group", "loadbalancerbackendgroup", ownerProjId)
+		if err := canaryV.Validate(data); err != nil {
+			return err
+		}
+		lbbg := canaryV.Model.(*SLoadbalancerBackendGroup)
+		if lbbg.LoadbalancerId != lbId {
+			return httperrors.NewInputParameterError("canary backend group %s(%s) belongs to loadbalancer %s instead of %s",
+				lbbg.Name, lbbg.Id, lbbg.LoadbalancerId, lbId)
+		}
+		canaryBackendGroupId = lbbg.Id
+	}
+	if err := validators.NewRangeValidator("canary_weight", 0, 100).Optional(true).Validate(data); err != nil {
+		return err
+	}
+	if len(canaryBackendGroupId) == 0 {
+		return nil
+	}
+	if len(managerId) > 0 {
+		return httperrors.NewUnsupportOperationError("canary backend group is not supported by managed loadbalancer")
+	}
+	if listenerType == api.LB_LISTENER_TYPE_UDP {
+		return httperrors.NewUnsupportOperationError("canary backend group is not supported by udp listener")
+	}
+	if canaryBackendGroupId == backendGroupId {
+		return httperrors.NewInputParameterError("canary backend group must differ from backend group %s", backendGroupId)
+	}
+	return nil
+}
+
 func (lbbg *SLoadbalancerBackendGroup) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
 	extra := lbbg.SVirtualResourceBase.GetCustomizeColumns(ctx, userCred, query)
 	{
@@ -49,6 +49,11 @@ type SLoadbalancerBackend struct {
 	Weight         int    `width:"36" charset:"ascii" nullable:"false" list:"user" create:"optional" update:"user"`
 	Address        string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"optional"`
 	Port           int    `nullable:"false" list:"user" create:"required" update:"user"`
+
+	// HealthStatus is reported by lbagent, see UpdateHealthStatuses
+	HealthStatus string `width:"16" charset:"ascii" nullable:"false" list:"user" default:"unknown"`
+	// DrainState is set by drain action, see loadbalancerbackends_drain.go
+	DrainState string `width:"16" charset:"ascii" nullable:"true" list:"user"`
 }
 
 func (man *SLoadbalancerBackendManager) PreDeleteSubs(ctx context.Context, userCred mcclient.TokenCredential, q *sqlchemy.SQuery) {
@@ -238,8 +243,21 @@ func (lbb *SLoadbalancerBackend) PerformPurge(ctx context.Context, userCred mccl
 }
 
 func (lbb *SLoadbalancerBackend) CustomizeDelete(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) error {
+	params := jsonutils.NewDict()
+	drain := false
+	for _, d := range []jsonutils.JSONObject{query, data} {
+		if d != nil && jsonutils.QueryBoolean(d, "drain", false) {
+			drain = true
+		}
+	}
+	if drain {
+		if len(lbb.ManagerId) > 0 {
+			return httperrors.NewUnsupportOperationError("drain is not supported by managed loadbalancer backend")
+		}
+		params.Set("drain", jsonutils.JSONTrue)
+	}
 	lbb.SetStatus(userCred, api.LB_STATUS_DELETING, "")
-	return lbb.StartLoadBalancerBackendDeleteTask(ctx, userCred, jsonutils.NewDict(), "")
+	return lbb.StartLoadBalancerBackendDeleteTask(ctx, userCred, params, "")
 }
 
 func (lbb *SLoadbalancerBackend) StartLoadBalancerBackendDeleteTask(ctx context.Context, userCred mcclient.TokenCredential, params *jsonutils.JSONDict, parentTaskId string) error {
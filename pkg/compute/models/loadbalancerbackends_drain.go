@@ -0,0 +1,138 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/sqlchemy"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+// Draining works as follows
+//
+//  - drain_state of the backend is set to draining, lbagent will pick it up
+//    and set the haproxy server to DRAIN through runtime api
+//  - MASTER lbagent reports in heartbeat backends without active sessions,
+//    these draining backends are then marked drained
+//  - deletion with drain waits for the backend to become drained before
+//    actually removing it
+
+func (lbb *SLoadbalancerBackend) AllowPerformDrain(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return lbb.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, lbb, "drain")
+}
+
+func (lbb *SLoadbalancerBackend) PerformDrain(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if len(lbb.ManagerId) > 0 {
+		return nil, httperrors.NewUnsupportOperationError("drain is not supported by managed loadbalancer backend")
+	}
+	return nil, lbb.StartDrain(ctx, userCred)
+}
+
+func (lbb *SLoadbalancerBackend) AllowPerformUndrain(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return lbb.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, lbb, "undrain")
+}
+
+func (lbb *SLoadbalancerBackend) PerformUndrain(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if lbb.Status == api.LB_STATUS_DELETING {
+		return nil, httperrors.NewInvalidStatusError("cannot undrain backend in status %s", lbb.Status)
+	}
+	return nil, lbb.setDrainState(ctx, userCred, api.LB_BACKEND_DRAIN_STATE_NONE)
+}
+
+// StartDrain stops new connections to the backend.  It's a no-op if the
+// backend is already draining or drained
+func (lbb *SLoadbalancerBackend) StartDrain(ctx context.Context, userCred mcclient.TokenCredential) error {
+	if lbb.DrainState != api.LB_BACKEND_DRAIN_STATE_NONE {
+		return nil
+	}
+	return lbb.setDrainState(ctx, userCred, api.LB_BACKEND_DRAIN_STATE_DRAINING)
+}
+
+// setDrainState bumps updated_at so that lbagents will sync the change
+func (lbb *SLoadbalancerBackend) setDrainState(ctx context.Context, userCred mcclient.TokenCredential, state string) error {
+	diff, err := db.Update(lbb, func() error {
+		lbb.DrainState = state
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	db.OpsLog.LogEvent(lbb, db.ACT_UPDATE, diff, userCred)
+	return nil
+}
+
+// WaitDrained waits until lbagent reports no active sessions on the
+// backend, or timeout
+func (lbb *SLoadbalancerBackend) WaitDrained(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		obj, err := LoadbalancerBackendManager.FetchById(lbb.Id)
+		if err != nil {
+			return err
+		}
+		switch obj.(*SLoadbalancerBackend).DrainState {
+		case api.LB_BACKEND_DRAIN_STATE_DRAINED:
+			return nil
+		case api.LB_BACKEND_DRAIN_STATE_NONE:
+			return fmt.Errorf("backend was undrained")
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for backend to be drained: %s", ctx.Err())
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// MarkDrained marks draining backends reported by lbagent as drained.  Like
+// health status, updated_at is not touched.  Only reports from MASTER
+// agents are accepted, BACKUP agents carry no traffic and always see no
+// sessions
+func (man *SLoadbalancerBackendManager) MarkDrained(ctx context.Context, userCred mcclient.TokenCredential, lbagent *SLoadbalancerAgent, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	if lbagent.HaState != api.LB_HA_STATE_MASTER {
+		log.Warningf("lbagent %s(%s) in state %s reported drained backends, ignored", lbagent.Name, lbagent.Id, lbagent.HaState)
+		return
+	}
+	lbbs := []SLoadbalancerBackend{}
+	q := man.Query().In("id", ids).Equals("drain_state", api.LB_BACKEND_DRAIN_STATE_DRAINING)
+	if err := db.FetchModelObjects(man, q, &lbbs); err != nil {
+		log.Errorf("fetch draining loadbalancer backends: %s", err)
+		return
+	}
+	sql := fmt.Sprintf("UPDATE `%s` SET `drain_state` = ? WHERE `id` = ? AND `drain_state` = ?",
+		man.TableSpec().Name())
+	for i := range lbbs {
+		lbb := &lbbs[i]
+		_, err := sqlchemy.GetDB().Exec(sql, api.LB_BACKEND_DRAIN_STATE_DRAINED, lbb.Id, api.LB_BACKEND_DRAIN_STATE_DRAINING)
+		if err != nil {
+			log.Errorf("loadbalancer backend %s(%s): mark drained: %s", lbb.Name, lbb.Id, err)
+			continue
+		}
+		notes := fmt.Sprintf("drained, reported by lbagent %s(%s)", lbagent.Name, lbagent.Id)
+		db.OpsLog.LogEvent(lbb, db.ACT_UPDATE_STATUS, notes, userCred)
+	}
+}
+
+func (lbagent *SLoadbalancerAgent) updateBackendDrainStates(ctx context.Context, userCred mcclient.TokenCredential, data jsonutils.JSONObject) {
+	drainedJson, err := data.Get("backend_drained")
+	if err != nil {
+		return
+	}
+	ids := []string{}
+	if err := drainedJson.Unmarshal(&ids); err != nil {
+		log.Warningf("lbagent %s(%s): unmarshal backend_drained: %s", lbagent.Name, lbagent.Id, err)
+		return
+	}
+	LoadbalancerBackendManager.MarkDrained(ctx, userCred, lbagent, ids)
+}
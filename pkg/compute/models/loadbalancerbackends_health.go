@@ -0,0 +1,120 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/sqlchemy"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/notifyclient"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+// UpdateHealthStatuses saves backend health states reported by lbagent in
+// heartbeat.  States is a map from backend id to health status.  Only
+// transitions between up and down are recorded and notified.
+//
+// Only reports from MASTER agents are accepted.  BACKUP agents run their own
+// health checks which may disagree with the MASTER, accepting them all would
+// make the status flip between heartbeats.  Backends missing from the report
+// are not checked any more, e.g. their listeners were disabled, their status
+// is reset to unknown
+func (man *SLoadbalancerBackendManager) UpdateHealthStatuses(ctx context.Context, userCred mcclient.TokenCredential, lbagent *SLoadbalancerAgent, states map[string]string) {
+	if lbagent.HaState != api.LB_HA_STATE_MASTER {
+		return
+	}
+	reported := []string{}
+	ids := []string{}
+	for id, state := range states {
+		reported = append(reported, id)
+		if !api.LB_BACKEND_HEALTH_STATUSES.Has(state) {
+			log.Warningf("lbagent %s(%s) reported invalid health status %q for backend %s",
+				lbagent.Name, lbagent.Id, state, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	update := func(lbb *SLoadbalancerBackend, state string) {
+		if lbb.HealthStatus == state {
+			return
+		}
+		if err := lbb.setHealthStatus(state); err != nil {
+			log.Errorf("loadbalancer backend %s(%s): set health status: %s", lbb.Name, lbb.Id, err)
+			return
+		}
+		lbb.onHealthStatusChanged(ctx, userCred, lbagent, lbb.HealthStatus, state)
+	}
+	if len(ids) > 0 {
+		lbbs := []SLoadbalancerBackend{}
+		q := man.Query().In("id", ids).IsNullOrEmpty("manager_id")
+		if err := db.FetchModelObjects(man, q, &lbbs); err != nil {
+			log.Errorf("fetch loadbalancer backends: %s", err)
+			return
+		}
+		for i := range lbbs {
+			update(&lbbs[i], states[lbbs[i].Id])
+		}
+	}
+	{
+		lbbs := []SLoadbalancerBackend{}
+		q := man.Query().IsNullOrEmpty("manager_id").NotEquals("health_status", api.LB_BACKEND_HEALTH_STATUS_UNKNOWN)
+		if len(reported) > 0 {
+			q = q.NotIn("id", reported)
+		}
+		if err := db.FetchModelObjects(man, q, &lbbs); err != nil {
+			log.Errorf("fetch unreported loadbalancer backends: %s", err)
+			return
+		}
+		for i := range lbbs {
+			update(&lbbs[i], api.LB_BACKEND_HEALTH_STATUS_UNKNOWN)
+		}
+	}
+}
+
+// setHealthStatus updates health_status without touching updated_at.
+// lbagent fetches backends incrementally by updated_at, bumping it would
+// make the agent regenerate and reload haproxy config on every health
+// status change
+func (lbb *SLoadbalancerBackend) setHealthStatus(state string) error {
+	sql := fmt.Sprintf("UPDATE `%s` SET `health_status` = ? WHERE `id` = ?",
+		LoadbalancerBackendManager.TableSpec().Name())
+	if _, err := sqlchemy.GetDB().Exec(sql, state, lbb.Id); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (lbb *SLoadbalancerBackend) onHealthStatusChanged(ctx context.Context, userCred mcclient.TokenCredential, lbagent *SLoadbalancerAgent, oldState, newState string) {
+	notes := fmt.Sprintf("health status %s -> %s, reported by lbagent %s(%s)",
+		oldState, newState, lbagent.Name, lbagent.Id)
+	log.Infof("loadbalancer backend %s(%s) %s", lbb.Name, lbb.Id, notes)
+	if newState == api.LB_BACKEND_HEALTH_STATUS_UNKNOWN {
+		// health check was disabled, or the backend was put into
+		// maintenance
+		return
+	}
+	if oldState == api.LB_BACKEND_HEALTH_STATUS_UNKNOWN && newState == api.LB_BACKEND_HEALTH_STATUS_UP {
+		// newly checked backend
+		return
+	}
+	db.OpsLog.LogEvent(lbb, db.ACT_UPDATE_STATUS, notes, userCred)
+	event := fmt.Sprintf("loadbalancer_backend_%s", newState)
+	notifyclient.NotifySystemWarning(lbb.Id, lbb.Name, event, notes)
+}
+
+func (lbagent *SLoadbalancerAgent) updateBackendHealthStatuses(ctx context.Context, userCred mcclient.TokenCredential, data jsonutils.JSONObject) {
+	statesJson, err := data.Get("backend_health_states")
+	if err != nil {
+		return
+	}
+	states := map[string]string{}
+	if err := statesJson.Unmarshal(&states); err != nil {
+		log.Warningf("lbagent %s(%s): unmarshal backend_health_states: %s", lbagent.Name, lbagent.Id, err)
+		return
+	}
+	LoadbalancerBackendManager.UpdateHealthStatuses(ctx, userCred, lbagent, states)
+}
@@ -7,11 +7,13 @@ import (
 	"encoding/hex"
 	"encoding/pem"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
 	"yunion.io/x/jsonutils"
 	"yunion.io/x/log"
+	"yunion.io/x/pkg/gotypes"
 	"yunion.io/x/pkg/util/compare"
 	"yunion.io/x/sqlchemy"
 
@@ -33,6 +35,9 @@ type SLoadbalancerCertificateManager struct {
 var LoadbalancerCertificateManager *SLoadbalancerCertificateManager
 
 func init() {
+	gotypes.RegisterSerializable(reflect.TypeOf(&SLoadbalancerAcmeChallenges{}), func() gotypes.ISerializable {
+		return &SLoadbalancerAcmeChallenges{}
+	})
 	LoadbalancerCertificateManager = &SLoadbalancerCertificateManager{
 		SVirtualResourceBaseManager: db.NewVirtualResourceBaseManager(
 			SLoadbalancerCertificate{},
@@ -51,8 +56,19 @@ type SLoadbalancerCertificate struct {
 	db.SVirtualResourceBase
 	SManagedResourceBase
 
-	Certificate string `create:"required" list:"user" update:"user"`
-	PrivateKey  string `create:"required" list:"admin" update:"user"`
+	Certificate string `create:"optional" list:"user" update:"user"`
+	PrivateKey  string `create:"optional" list:"admin" update:"user"`
+
+	// acme mode certificates are issued and renewed by region through
+	// ACME protocol
+	CertificateMode string                       `width:"16" charset:"ascii" nullable:"false" default:"upload" list:"user" create:"optional"`
+	AcmeDomains     string                       `list:"user" create:"optional"`
+	AcmeEmail       string                       `width:"128" charset:"ascii" nullable:"true" list:"user" create:"optional" update:"user"`
+	AcmeAccountKey  string                       `nullable:"true"`
+	AcmeChallenges  *SLoadbalancerAcmeChallenges `nullable:"true" list:"admin"`
+	// 最近一次签发的时间及连续失败次数, 自动续期失败后据此退避
+	AcmeLastAttemptAt time.Time `nullable:"true" list:"admin"`
+	AcmeFailures      int       `nullable:"false" default:"0" list:"admin"`
 
 	// derived attributes
 	PublicKeyAlgorithm      string    `create:"optional" list:"user" update:"user"`
@@ -125,7 +141,17 @@ func (man *SLoadbalancerCertificateManager) validateCertKey(ctx context.Context,
 }
 
 func (man *SLoadbalancerCertificateManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
-	data, err := man.validateCertKey(ctx, data)
+	modeV := validators.NewStringChoicesValidator("certificate_mode", api.LB_CERT_MODES)
+	modeV.Default(api.LB_CERT_MODE_UPLOAD)
+	if err := modeV.Validate(data); err != nil {
+		return nil, err
+	}
+	var err error
+	if modeV.Value == api.LB_CERT_MODE_ACME {
+		data, err = man.validateAcme(ctx, data)
+	} else {
+		data, err = man.validateCertKey(ctx, data)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -184,15 +210,25 @@ func (lbcert *SLoadbalancerCertificate) AllowPerformStatus(ctx context.Context,
 }
 
 func (lbcert *SLoadbalancerCertificate) ValidateUpdateData(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
-	if !data.Contains("certificate") {
-		data.Set("certificate", jsonutils.NewString(lbcert.Certificate))
-	}
-	if !data.Contains("private_key") {
-		data.Set("private_key", jsonutils.NewString(lbcert.PrivateKey))
-	}
-	data, err := LoadbalancerCertificateManager.validateCertKey(ctx, data)
-	if err != nil {
-		return nil, err
+	if lbcert.CertificateMode == api.LB_CERT_MODE_ACME {
+		if data.Contains("certificate") || data.Contains("private_key") {
+			return nil, httperrors.NewInputParameterError("certificate and private_key of acme certificate are not updatable")
+		}
+		if err := validateAcmeEmail(data); err != nil {
+			return nil, err
+		}
+	} else {
+		if !data.Contains("certificate") {
+			data.Set("certificate", jsonutils.NewString(lbcert.Certificate))
+		}
+		if !data.Contains("private_key") {
+			data.Set("private_key", jsonutils.NewString(lbcert.PrivateKey))
+		}
+		var err error
+		data, err = LoadbalancerCertificateManager.validateCertKey(ctx, data)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if _, err := lbcert.SVirtualResourceBase.ValidateUpdateData(ctx, userCred, query, data); err != nil {
 		return nil, err
@@ -208,6 +244,12 @@ func (lbcert *SLoadbalancerCertificate) PostCreate(ctx context.Context, userCred
 	lbcert.SVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
 
 	lbcert.SetStatus(userCred, api.LB_CREATING, "")
+	if lbcert.CertificateMode == api.LB_CERT_MODE_ACME {
+		if err := lbcert.StartLoadbalancerCertificateAcmeIssueTask(ctx, userCred, false, ""); err != nil {
+			log.Errorf("Failed to issue acme loadbalancercertificate error: %v", err)
+		}
+		return
+	}
 	if err := lbcert.StartLoadBalancerCertificateCreateTask(ctx, userCred, ""); err != nil {
 		log.Errorf("Failed to create loadbalancercertificate error: %v", err)
 	}
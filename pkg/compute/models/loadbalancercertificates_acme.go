@@ -0,0 +1,422 @@
+package models
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/regutils"
+	"yunion.io/x/sqlchemy"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/compute/options"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/util/acme"
+)
+
+// SLoadbalancerAcmeChallenge is a pending http-01 challenge.  lbagents
+// respond to requests for /.well-known/acme-challenge/<token> with the key
+// authorization on http listeners
+type SLoadbalancerAcmeChallenge struct {
+	Domain           string
+	Token            string
+	KeyAuthorization string
+}
+
+type SLoadbalancerAcmeChallenges []*SLoadbalancerAcmeChallenge
+
+func (challenges *SLoadbalancerAcmeChallenges) String() string {
+	return jsonutils.Marshal(challenges).String()
+}
+
+func (challenges *SLoadbalancerAcmeChallenges) IsZero() bool {
+	if len([]*SLoadbalancerAcmeChallenge(*challenges)) == 0 {
+		return true
+	}
+	return false
+}
+
+// ACME limits the number of names in one certificate to 100
+const (
+	lbcertAcmeMaxDomains = 100
+
+	acmeRenewBackoffMax = 24 * time.Hour
+)
+
+func (man *SLoadbalancerCertificateManager) validateAcme(ctx context.Context, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	if data.Contains("certificate") || data.Contains("private_key") {
+		return nil, httperrors.NewInputParameterError("certificate and private_key are not allowed for acme certificate")
+	}
+	jDomains, err := data.Get("acme_domains")
+	if err != nil {
+		return nil, httperrors.NewMissingParameterError("acme_domains")
+	}
+	// space or comma separated string, or an array of strings
+	domains0 := []string{}
+	switch jDomains := jDomains.(type) {
+	case *jsonutils.JSONArray:
+		for _, jDomain := range jDomains.Value() {
+			domain, err := jDomain.GetString()
+			if err != nil {
+				return nil, httperrors.NewInputParameterError("acme_domains: invalid domain %s", jDomain)
+			}
+			domains0 = append(domains0, domain)
+		}
+	default:
+		s, _ := jDomains.GetString()
+		domains0 = strings.FieldsFunc(s, func(r rune) bool {
+			return r == ' ' || r == ','
+		})
+	}
+	domains := []string{}
+	seen := map[string]bool{}
+	for _, domain := range domains0 {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" || seen[domain] {
+			continue
+		}
+		// wildcard names cannot be validated with http-01 challenges
+		if !regutils.MatchDomainName(domain) {
+			return nil, httperrors.NewInputParameterError("acme_domains: invalid domain name %q", domain)
+		}
+		seen[domain] = true
+		domains = append(domains, domain)
+	}
+	if len(domains) == 0 {
+		return nil, httperrors.NewMissingParameterError("acme_domains")
+	}
+	if len(domains) > lbcertAcmeMaxDomains {
+		return nil, httperrors.NewInputParameterError("acme_domains: at most %d domains are allowed, got %d",
+			lbcertAcmeMaxDomains, len(domains))
+	}
+	if err := validateAcmeEmail(data); err != nil {
+		return nil, err
+	}
+	data.Set("acme_domains", jsonutils.NewString(strings.Join(domains, " ")))
+	data.Set("common_name", jsonutils.NewString(domains[0]))
+	data.Set("subject_alternative_names", jsonutils.NewString(strings.Join(domains, " ")))
+	return data, nil
+}
+
+func validateAcmeEmail(data *jsonutils.JSONDict) error {
+	if email, _ := data.GetString("acme_email"); email != "" && !regutils.MatchEmail(email) {
+		return httperrors.NewInputParameterError("acme_email: invalid email %q", email)
+	}
+	return nil
+}
+
+func (lbcert *SLoadbalancerCertificate) GetAcmeDomains() []string {
+	return strings.Fields(lbcert.AcmeDomains)
+}
+
+func (lbcert *SLoadbalancerCertificate) StartLoadbalancerCertificateAcmeIssueTask(ctx context.Context, userCred mcclient.TokenCredential, renew bool, parentTaskId string) error {
+	params := jsonutils.NewDict()
+	if renew {
+		params.Set("renew", jsonutils.JSONTrue)
+	}
+	_, err := db.Update(lbcert, func() error {
+		lbcert.AcmeLastAttemptAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	task, err := taskman.TaskManager.NewTask(ctx, "LoadbalancerCertificateAcmeIssueTask", lbcert, userCred, params, parentTaskId, "", nil)
+	if err != nil {
+		return err
+	}
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (lbcert *SLoadbalancerCertificate) AllowPerformRenew(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return db.IsAdminAllowPerform(userCred, lbcert, "renew")
+}
+
+func (lbcert *SLoadbalancerCertificate) PerformRenew(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if lbcert.CertificateMode != api.LB_CERT_MODE_ACME {
+		return nil, httperrors.NewUnsupportOperationError("only acme certificate can be renewed")
+	}
+	switch lbcert.Status {
+	case api.LB_STATUS_ENABLED, api.LB_CERT_STATUS_RENEW_FAILED, api.LB_CREATE_FAILED:
+	default:
+		return nil, httperrors.NewInvalidStatusError("cannot renew certificate in status %s", lbcert.Status)
+	}
+	// a failed initial issuance is retried as creation
+	renew := lbcert.Status != api.LB_CREATE_FAILED
+	if renew {
+		lbcert.SetStatus(userCred, api.LB_CERT_STATUS_RENEWING, "")
+	} else {
+		lbcert.SetStatus(userCred, api.LB_CREATING, "")
+	}
+	return nil, lbcert.StartLoadbalancerCertificateAcmeIssueTask(ctx, userCred, renew, "")
+}
+
+func (lbcert *SLoadbalancerCertificate) getAcmeAccountKey() (crypto.Signer, error) {
+	if lbcert.AcmeAccountKey != "" {
+		return acme.ParsePrivateKey(lbcert.AcmeAccountKey)
+	}
+	key, err := acme.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	keyPem, err := acme.MarshalPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Update(lbcert, func() error {
+		lbcert.AcmeAccountKey = keyPem
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (lbcert *SLoadbalancerCertificate) setAcmeChallenges(challenges SLoadbalancerAcmeChallenges) error {
+	_, err := db.Update(lbcert, func() error {
+		if len(challenges) > 0 {
+			lbcert.AcmeChallenges = &challenges
+		} else {
+			lbcert.AcmeChallenges = nil
+		}
+		return nil
+	})
+	return err
+}
+
+// waitAcmeChallengesSynced waits for all active lbagents to have seen the
+// certificate with challenges, then gives haproxy some time to reload
+func (lbcert *SLoadbalancerCertificate) waitAcmeChallengesSynced(ctx context.Context) error {
+	// use updated_at as stored in db, which is what lbagents see
+	obj, err := LoadbalancerCertificateManager.FetchById(lbcert.Id)
+	if err != nil {
+		return err
+	}
+	updatedAt := obj.(*SLoadbalancerCertificate).UpdatedAt
+	for {
+		lbagents := []SLoadbalancerAgent{}
+		if err := db.FetchModelObjects(LoadbalancerAgentManager, LoadbalancerAgentManager.Query(), &lbagents); err != nil {
+			return err
+		}
+		synced := true
+		for i := range lbagents {
+			lbagent := &lbagents[i]
+			if lbagent.IsActive() && lbagent.LoadbalancerCertificates.Before(updatedAt) {
+				log.Infof("lbcert %s(%s): waiting for lbagent %s to sync acme challenges", lbcert.Name, lbcert.Id, lbagent.Name)
+				synced = false
+				break
+			}
+		}
+		if synced {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for lbagents to sync acme challenges: %s", ctx.Err())
+		case <-time.After(5 * time.Second):
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(10 * time.Second):
+	}
+	return nil
+}
+
+// AcmeIssue obtains certificate for AcmeDomains from the acme directory
+// and updates the certificate in place.  Listeners referring to it will
+// be updated by lbagents as usual
+func (lbcert *SLoadbalancerCertificate) AcmeIssue(ctx context.Context, userCred mcclient.TokenCredential) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(options.Options.LoadbalancerAcmeIssueTimeout)*time.Second)
+	defer cancel()
+
+	accountKey, err := lbcert.getAcmeAccountKey()
+	if err != nil {
+		return fmt.Errorf("acme account key: %s", err)
+	}
+	cli := acme.NewClient(options.Options.LoadbalancerAcmeDirectoryUrl, accountKey, nil)
+	if err := cli.Register(ctx, lbcert.AcmeEmail); err != nil {
+		return fmt.Errorf("acme register: %s", err)
+	}
+	domains := lbcert.GetAcmeDomains()
+	order, err := cli.NewOrder(ctx, domains)
+	if err != nil {
+		return fmt.Errorf("acme new order: %s", err)
+	}
+
+	challenges := SLoadbalancerAcmeChallenges{}
+	pendingChallenges := []*acme.SChallenge{}
+	pendingAuthzUrls := []string{}
+	for _, authzUrl := range order.Authorizations {
+		authz, err := cli.GetAuthorization(ctx, authzUrl)
+		if err != nil {
+			return fmt.Errorf("acme get authorization: %s", err)
+		}
+		if authz.Status == acme.STATUS_VALID {
+			continue
+		}
+		chal := authz.GetChallenge(acme.CHALLENGE_TYPE_HTTP01)
+		if chal == nil {
+			return fmt.Errorf("acme: no http-01 challenge for %s", authz.Identifier.Value)
+		}
+		keyAuth, err := cli.KeyAuthorization(chal.Token)
+		if err != nil {
+			return err
+		}
+		challenges = append(challenges, &SLoadbalancerAcmeChallenge{
+			Domain:           authz.Identifier.Value,
+			Token:            chal.Token,
+			KeyAuthorization: keyAuth,
+		})
+		pendingChallenges = append(pendingChallenges, chal)
+		pendingAuthzUrls = append(pendingAuthzUrls, authzUrl)
+	}
+	if len(challenges) > 0 {
+		if err := lbcert.setAcmeChallenges(challenges); err != nil {
+			return err
+		}
+		defer func() {
+			if err := lbcert.setAcmeChallenges(nil); err != nil {
+				log.Errorf("lbcert %s(%s): clear acme challenges: %s", lbcert.Name, lbcert.Id, err)
+			}
+		}()
+		if err := lbcert.waitAcmeChallengesSynced(ctx); err != nil {
+			return err
+		}
+		for _, chal := range pendingChallenges {
+			if err := cli.AcceptChallenge(ctx, chal); err != nil {
+				return fmt.Errorf("acme accept challenge: %s", err)
+			}
+		}
+		for _, authzUrl := range pendingAuthzUrls {
+			if _, err := cli.WaitAuthorization(ctx, authzUrl); err != nil {
+				return fmt.Errorf("acme authorization: %s", err)
+			}
+		}
+	}
+
+	certKey, err := acme.GenerateKey()
+	if err != nil {
+		return err
+	}
+	csr, err := acme.NewCertificateRequest(certKey, domains)
+	if err != nil {
+		return err
+	}
+	order, err = cli.FinalizeOrder(ctx, order, csr)
+	if err != nil {
+		return fmt.Errorf("acme finalize order: %s", err)
+	}
+	certPem, err := cli.FetchCertificate(ctx, order.Certificate)
+	if err != nil {
+		return fmt.Errorf("acme fetch certificate: %s", err)
+	}
+	keyPem, err := acme.MarshalPrivateKey(certKey)
+	if err != nil {
+		return err
+	}
+
+	data := jsonutils.NewDict()
+	data.Set("certificate", jsonutils.NewString(certPem))
+	data.Set("private_key", jsonutils.NewString(keyPem))
+	data, err = LoadbalancerCertificateManager.validateCertKey(ctx, data)
+	if err != nil {
+		return err
+	}
+	diff, err := db.Update(lbcert, func() error {
+		return data.Unmarshal(lbcert)
+	})
+	if err != nil {
+		return err
+	}
+	db.OpsLog.LogEvent(lbcert, db.ACT_UPDATE, diff, userCred)
+	return nil
+}
+
+// SetAcmeIssueResult 记录签发结果, 失败时累加连续失败次数
+func (lbcert *SLoadbalancerCertificate) SetAcmeIssueResult(succ bool) error {
+	_, err := db.Update(lbcert, func() error {
+		if succ {
+			lbcert.AcmeFailures = 0
+		} else {
+			lbcert.AcmeFailures += 1
+		}
+		return nil
+	})
+	return err
+}
+
+// acmeRenewBackoff 返回续期失败后到下次重试的间隔, 从检查间隔开始每次失败加倍,
+// 最长一天, 以免触发 ACME 服务的频率限制
+func acmeRenewBackoff(failures int) time.Duration {
+	interval := time.Duration(options.Options.LoadbalancerAcmeRenewCheckInterval) * time.Second
+	backoff := interval
+	for i := 1; i < failures && backoff < acmeRenewBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > acmeRenewBackoffMax {
+		backoff = acmeRenewBackoffMax
+	}
+	return backoff
+}
+
+// acmeIssueTimedOut 判断最近一次签发是否已超过签发超时时间仍未结束
+func (lbcert *SLoadbalancerCertificate) acmeIssueTimedOut(now time.Time) bool {
+	timeout := time.Duration(options.Options.LoadbalancerAcmeIssueTimeout) * time.Second
+	return now.After(lbcert.AcmeLastAttemptAt.Add(timeout))
+}
+
+func (man *SLoadbalancerCertificateManager) AutoRenewAcmeCertificates(ctx context.Context, userCred mcclient.TokenCredential, isStart bool) {
+	renewBefore := time.Now().Add(time.Duration(options.Options.LoadbalancerAcmeRenewDays) * 24 * time.Hour)
+	q := man.Query().Equals("certificate_mode", api.LB_CERT_MODE_ACME)
+	q = q.Filter(sqlchemy.OR(
+		sqlchemy.AND(
+			sqlchemy.In(q.Field("status"), []string{api.LB_STATUS_ENABLED, api.LB_CERT_STATUS_RENEW_FAILED}),
+			sqlchemy.LT(q.Field("not_after"), renewBefore),
+		),
+		// 续期任务可能因服务重启等原因中断, 一直停留在续期中
+		sqlchemy.Equals(q.Field("status"), api.LB_CERT_STATUS_RENEWING),
+	))
+	q = q.Filter(sqlchemy.OR(sqlchemy.IsNull(q.Field("pending_deleted")), sqlchemy.IsFalse(q.Field("pending_deleted"))))
+	lbcerts := []SLoadbalancerCertificate{}
+	if err := db.FetchModelObjects(man, q, &lbcerts); err != nil {
+		log.Errorf("fetch acme loadbalancer certificates to renew: %s", err)
+		return
+	}
+	for i := range lbcerts {
+		lbcert := &lbcerts[i]
+		if lbcert.Status == api.LB_CERT_STATUS_RENEWING {
+			if !lbcert.acmeIssueTimedOut(time.Now()) {
+				continue
+			}
+			reason := "acme issue timed out"
+			log.Warningf("renew acme loadbalancer certificate %s(%s): %s, retry it", lbcert.Name, lbcert.Id, reason)
+			if err := lbcert.SetAcmeIssueResult(false); err != nil {
+				log.Errorf("lbcert %s(%s): record acme issue failure: %s", lbcert.Name, lbcert.Id, err)
+				continue
+			}
+			lbcert.SetStatus(userCred, api.LB_CERT_STATUS_RENEW_FAILED, reason)
+		}
+		if lbcert.Status == api.LB_CERT_STATUS_RENEW_FAILED && lbcert.AcmeFailures > 0 {
+			if next := lbcert.AcmeLastAttemptAt.Add(acmeRenewBackoff(lbcert.AcmeFailures)); time.Now().Before(next) {
+				log.Debugf("renew acme loadbalancer certificate %s(%s) failed %d times, retry after %s", lbcert.Name, lbcert.Id, lbcert.AcmeFailures, next)
+				continue
+			}
+		}
+		log.Infof("renew acme loadbalancer certificate %s(%s), not after %s", lbcert.Name, lbcert.Id, lbcert.NotAfter)
+		lbcert.SetStatus(userCred, api.LB_CERT_STATUS_RENEWING, "auto renew")
+		if err := lbcert.StartLoadbalancerCertificateAcmeIssueTask(ctx, userCred, true, ""); err != nil {
+			log.Errorf("start renew task for lbcert %s(%s): %s", lbcert.Name, lbcert.Id, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"yunion.io/x/onecloud/pkg/compute/options"
+)
+
+func TestAcmeRenewBackoff(t *testing.T) {
+	interval := options.Options.LoadbalancerAcmeRenewCheckInterval
+	defer func() {
+		options.Options.LoadbalancerAcmeRenewCheckInterval = interval
+	}()
+	options.Options.LoadbalancerAcmeRenewCheckInterval = 3600
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Hour},
+		{2, 2 * time.Hour},
+		{3, 4 * time.Hour},
+		{5, 16 * time.Hour},
+		{6, 24 * time.Hour},
+		{100, 24 * time.Hour},
+	}
+	for _, c := range cases {
+		if got := acmeRenewBackoff(c.failures); got != c.want {
+			t.Errorf("%d failures: want %s, got %s", c.failures, c.want, got)
+		}
+	}
+}
+
+func TestAcmeIssueTimedOut(t *testing.T) {
+	timeout := options.Options.LoadbalancerAcmeIssueTimeout
+	defer func() {
+		options.Options.LoadbalancerAcmeIssueTimeout = timeout
+	}()
+	options.Options.LoadbalancerAcmeIssueTimeout = 600
+	now := time.Now()
+	cases := []struct {
+		lastAttempt time.Time
+		want        bool
+	}{
+		{now.Add(-time.Minute), false},
+		{now.Add(-11 * time.Minute), true},
+		{time.Time{}, true},
+	}
+	for _, c := range cases {
+		lbcert := &SLoadbalancerCertificate{AcmeLastAttemptAt: c.lastAttempt}
+		if got := lbcert.acmeIssueTimedOut(now); got != c.want {
+			t.Errorf("last attempt at %s: want %v, got %v", c.lastAttempt, c.want, got)
+		}
+	}
+}
@@ -3,10 +3,15 @@ package models
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"regexp"
+	"unicode"
 
 	"yunion.io/x/jsonutils"
 	"yunion.io/x/log"
+	"yunion.io/x/pkg/gotypes"
 	"yunion.io/x/pkg/util/compare"
+	"yunion.io/x/pkg/utils"
 	"yunion.io/x/sqlchemy"
 
 	api "yunion.io/x/onecloud/pkg/apis/compute"
@@ -26,7 +31,75 @@ type SLoadbalancerListenerRuleManager struct {
 
 var LoadbalancerListenerRuleManager *SLoadbalancerListenerRuleManager
 
+var regexpHTTPHeaderName = regexp.MustCompile(`^[a-zA-Z0-9!#$&'*+.^_|~-]+$`)
+
+type SLoadbalancerHTTPHeaderAction struct {
+	Action string
+	Name   string
+	Value  string
+}
+
+func (headerAction *SLoadbalancerHTTPHeaderAction) Validate(data *jsonutils.JSONDict) error {
+	if !api.LB_HTTP_HEADER_ACTIONS.Has(headerAction.Action) {
+		return httperrors.NewInputParameterError("invalid header action %q", headerAction.Action)
+	}
+	if !regexpHTTPHeaderName.MatchString(headerAction.Name) {
+		return httperrors.NewInputParameterError("invalid header name %q", headerAction.Name)
+	}
+	if headerAction.Action == api.LB_HTTP_HEADER_ACTION_DEL {
+		headerAction.Value = ""
+		return nil
+	}
+	if len(headerAction.Value) == 0 {
+		return httperrors.NewInputParameterError("header %s: empty value", headerAction.Name)
+	}
+	if valueLimit := 256; len(headerAction.Value) > valueLimit {
+		return httperrors.NewInputParameterError("header %s: value too long (%d>%d)",
+			headerAction.Name, len(headerAction.Value), valueLimit)
+	}
+	for _, r := range headerAction.Value {
+		// value will be double quoted in haproxy config
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) || r == '"' || r == '\\' {
+			return httperrors.NewInputParameterError("header %s: value contains invalid char: %q", headerAction.Name, r)
+		}
+	}
+	return nil
+}
+
+type SLoadbalancerHTTPHeaderActions []*SLoadbalancerHTTPHeaderAction
+
+func (headerActions *SLoadbalancerHTTPHeaderActions) String() string {
+	return jsonutils.Marshal(headerActions).String()
+}
+
+func (headerActions *SLoadbalancerHTTPHeaderActions) IsZero() bool {
+	if len([]*SLoadbalancerHTTPHeaderAction(*headerActions)) == 0 {
+		return true
+	}
+	return false
+}
+
+func (headerActions *SLoadbalancerHTTPHeaderActions) Validate(data *jsonutils.JSONDict) error {
+	for _, headerAction := range *headerActions {
+		if err := headerAction.Validate(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 重定向规则, 未指定的部分保持请求原值
+type SLoadbalancerHTTPRedirect struct {
+	RedirectCode   int    `nullable:"false" list:"user" create:"optional" update:"user"`
+	RedirectScheme string `width:"16" charset:"ascii" nullable:"false" list:"user" create:"optional" update:"user"`
+	RedirectHost   string `width:"128" charset:"ascii" nullable:"false" list:"user" create:"optional" update:"user"`
+	RedirectPath   string `width:"128" charset:"ascii" nullable:"false" list:"user" create:"optional" update:"user"`
+}
+
 func init() {
+	gotypes.RegisterSerializable(reflect.TypeOf(&SLoadbalancerHTTPHeaderActions{}), func() gotypes.ISerializable {
+		return &SLoadbalancerHTTPHeaderActions{}
+	})
 	LoadbalancerListenerRuleManager = &SLoadbalancerListenerRuleManager{
 		SVirtualResourceBaseManager: db.NewVirtualResourceBaseManager(
 			SLoadbalancerListenerRule{},
@@ -45,9 +118,25 @@ type SLoadbalancerListenerRule struct {
 	ListenerId     string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"optional"`
 	BackendGroupId string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"optional" update:"user"`
 
+	// 灰度后端服务器组及其分得的请求百分比
+	CanaryBackendGroupId string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"optional" update:"user"`
+	CanaryWeight         int    `nullable:"false" list:"user" default:"0" create:"optional" update:"user"`
+
 	Domain string `width:"128" charset:"ascii" nullable:"false" list:"user" create:"optional"`
 	Path   string `width:"128" charset:"ascii" nullable:"false" list:"user" create:"optional"`
 
+	// 数值大的规则优先匹配, 相同时域名及路径更长的规则优先
+	Priority int `nullable:"false" list:"user" create:"optional" update:"user"`
+
+	Action string `width:"16" charset:"ascii" nullable:"false" list:"user" default:"forward" create:"optional" update:"user"`
+	SLoadbalancerHTTPRedirect
+	FixedResponseCode int `nullable:"false" list:"user" create:"optional" update:"user"`
+
+	// 转发前将匹配的路径前缀替换为RewritePath
+	RewritePath           string                          `width:"128" charset:"ascii" nullable:"false" list:"user" create:"optional" update:"user"`
+	RequestHeaderActions  *SLoadbalancerHTTPHeaderActions `list:"user" create:"optional" update:"user"`
+	ResponseHeaderActions *SLoadbalancerHTTPHeaderActions `list:"user" create:"optional" update:"user"`
+
 	SLoadbalancerHealthCheck // 目前只有腾讯云HTTP、HTTPS类型的健康检查是和规则绑定的。
 	SLoadbalancerHTTPRateLimiter
 }
@@ -66,6 +155,89 @@ func loadbalancerListenerRuleCheckUniqueness(ctx context.Context, lbls *SLoadbal
 	return nil
 }
 
+// lbr为nil时校验创建参数, 否则校验更新参数, 未指定的字段取规则当前值
+func loadbalancerListenerRuleValidateAction(data *jsonutils.JSONDict, listener *SLoadbalancerListener, lbr *SLoadbalancerListenerRule) error {
+	update := lbr != nil
+	if !update {
+		lbr = &SLoadbalancerListenerRule{}
+		lbr.Action = api.LB_RULE_ACTION_FORWARD
+	}
+	requestHeaderActions := SLoadbalancerHTTPHeaderActions{}
+	responseHeaderActions := SLoadbalancerHTTPHeaderActions{}
+	keyV := map[string]validators.IValidator{
+		"priority":                validators.NewRangeValidator("priority", 0, 50000),
+		"action":                  validators.NewStringChoicesValidator("action", api.LB_RULE_ACTIONS),
+		"redirect_code":           validators.NewNonNegativeValidator("redirect_code"),
+		"redirect_host":           validators.NewDomainNameValidator("redirect_host").AllowEmpty(true),
+		"redirect_path":           validators.NewURLPathValidator("redirect_path"),
+		"fixed_response_code":     validators.NewNonNegativeValidator("fixed_response_code"),
+		"rewrite_path":            validators.NewURLPathValidator("rewrite_path"),
+		"request_header_actions":  validators.NewStructValidator("request_header_actions", &requestHeaderActions),
+		"response_header_actions": validators.NewStructValidator("response_header_actions", &responseHeaderActions),
+	}
+	for _, v := range keyV {
+		v.Optional(true)
+		if err := v.Validate(data); err != nil {
+			return err
+		}
+	}
+
+	getString := func(key, cur string) string {
+		if data.Contains(key) {
+			v, _ := data.GetString(key)
+			return v
+		}
+		return cur
+	}
+	getInt := func(key string, cur int) int {
+		if data.Contains(key) {
+			v, _ := data.Int(key)
+			return int(v)
+		}
+		return cur
+	}
+	action := getString("action", lbr.Action)
+	path := getString("path", lbr.Path)
+	rewritePath := getString("rewrite_path", lbr.RewritePath)
+	redirectCode := getInt("redirect_code", lbr.RedirectCode)
+	redirectScheme := getString("redirect_scheme", lbr.RedirectScheme)
+	redirectHost := getString("redirect_host", lbr.RedirectHost)
+	redirectPath := getString("redirect_path", lbr.RedirectPath)
+	fixedResponseCode := getInt("fixed_response_code", lbr.FixedResponseCode)
+
+	switch action {
+	case api.LB_RULE_ACTION_FORWARD:
+		if len(rewritePath) > 0 && len(path) == 0 {
+			return httperrors.NewInputParameterError("rewrite_path requires path of rule")
+		}
+	case api.LB_RULE_ACTION_REDIRECT:
+		if redirectCode == 0 {
+			redirectCode = api.LB_REDIRECT_CODE_DEFAULT
+			data.Set("redirect_code", jsonutils.NewInt(int64(redirectCode)))
+		}
+		if ok, _ := utils.InArray(redirectCode, api.LB_REDIRECT_CODES); !ok {
+			return httperrors.NewInputParameterError("invalid redirect_code %d, want one of %v", redirectCode, api.LB_REDIRECT_CODES)
+		}
+		if len(redirectScheme) > 0 && !api.LB_REDIRECT_SCHEMES.Has(redirectScheme) {
+			return httperrors.NewInputParameterError("invalid redirect_scheme %s", redirectScheme)
+		}
+		if len(redirectScheme) == 0 && len(redirectHost) == 0 && len(redirectPath) == 0 {
+			return httperrors.NewMissingParameterError("redirect_scheme, redirect_host or redirect_path")
+		}
+		if len(redirectHost) == 0 && len(redirectPath) == 0 && redirectScheme == listener.ListenerType {
+			return httperrors.NewInputParameterError("redirect to %s from %s listener loops", redirectScheme, listener.ListenerType)
+		}
+	case api.LB_RULE_ACTION_FIXED_RESPONSE:
+		if ok, _ := utils.InArray(fixedResponseCode, api.LB_FIXED_RESPONSE_CODES); !ok {
+			return httperrors.NewInputParameterError("invalid fixed_response_code %d, want one of %v", fixedResponseCode, api.LB_FIXED_RESPONSE_CODES)
+		}
+	}
+	if !update {
+		data.Set("action", jsonutils.NewString(action))
+	}
+	return nil
+}
+
 func (man *SLoadbalancerListenerRuleManager) PreDeleteSubs(ctx context.Context, userCred mcclient.TokenCredential, q *sqlchemy.SQuery) {
 	subs := []SLoadbalancerListenerRule{}
 	db.FetchModelObjects(man, q, &subs)
@@ -96,6 +268,15 @@ func (man *SLoadbalancerListenerRuleManager) ValidateCreateData(ctx context.Cont
 	backendGroupV := validators.NewModelIdOrNameValidator("backend_group", "loadbalancerbackendgroup", ownerProjId)
 	domainV := validators.NewDomainNameValidator("domain")
 	pathV := validators.NewURLPathValidator("path")
+	actionV := validators.NewStringChoicesValidator("action", api.LB_RULE_ACTIONS)
+	actionV.Default(api.LB_RULE_ACTION_FORWARD)
+	if err := actionV.Validate(data); err != nil {
+		return nil, err
+	}
+	// 只有转发规则需要后端服务器组
+	if actionV.Value != api.LB_RULE_ACTION_FORWARD {
+		backendGroupV.Optional(true)
+	}
 	keyV := map[string]validators.IValidator{
 		"status": validators.NewStringChoicesValidator("status", api.LB_STATUS_SPEC).Default(api.LB_STATUS_ENABLED),
 
@@ -113,6 +294,9 @@ func (man *SLoadbalancerListenerRuleManager) ValidateCreateData(ctx context.Cont
 		}
 	}
 	listener := listenerV.Model.(*SLoadbalancerListener)
+	if err := loadbalancerListenerRuleValidateAction(data, listener, nil); err != nil {
+		return nil, err
+	}
 	data.Set("cloudregion_id", jsonutils.NewString(listener.CloudregionId))
 	data.Set("manager_id", jsonutils.NewString(listener.ManagerId))
 	listenerType := listener.ListenerType
@@ -123,7 +307,7 @@ func (man *SLoadbalancerListenerRuleManager) ValidateCreateData(ctx context.Cont
 		if lbbg, ok := backendGroupV.Model.(*SLoadbalancerBackendGroup); ok && lbbg.LoadbalancerId != listener.LoadbalancerId {
 			return nil, httperrors.NewInputParameterError("backend group %s(%s) belongs to loadbalancer %s instead of %s",
 				lbbg.Name, lbbg.Id, lbbg.LoadbalancerId, listener.LoadbalancerId)
-		} else {
+		} else if ok {
 			// 腾讯云backend group只能1v1关联
 			if listener.GetProviderName() == CLOUD_PROVIDER_QCLOUD {
 				count := lbbg.RefCount()
@@ -133,6 +317,9 @@ func (man *SLoadbalancerListenerRuleManager) ValidateCreateData(ctx context.Cont
 			}
 		}
 	}
+	if err := loadbalancerValidateCanary(data, ownerProjId, listener.LoadbalancerId, listener.ManagerId, listenerType, "", ""); err != nil {
+		return nil, err
+	}
 	err := loadbalancerListenerRuleCheckUniqueness(ctx, listener, domainV.Value, pathV.Value)
 	if err != nil {
 		return nil, err
@@ -207,25 +394,43 @@ func (lbr *SLoadbalancerListenerRule) ValidateUpdateData(ctx context.Context, us
 			return nil, err
 		}
 	}
+	listenerM, err := LoadbalancerListenerManager.FetchById(lbr.ListenerId)
+	if err != nil {
+		return nil, httperrors.NewInputParameterError("loadbalancerlistenerrule %s(%s): fetching listener %s failed",
+			lbr.Name, lbr.Id, lbr.ListenerId)
+	}
+	listener := listenerM.(*SLoadbalancerListener)
+	if err := loadbalancerListenerRuleValidateAction(data, listener, lbr); err != nil {
+		return nil, err
+	}
+	if action, _ := data.GetString("action"); action == api.LB_RULE_ACTION_FORWARD && len(lbr.BackendGroupId) == 0 && backendGroupV.Model == nil {
+		return nil, httperrors.NewMissingParameterError("backend_group")
+	}
 	if backendGroup, ok := backendGroupV.Model.(*SLoadbalancerBackendGroup); ok && backendGroup.Id != lbr.BackendGroupId {
-		listenerM, err := LoadbalancerListenerManager.FetchById(lbr.ListenerId)
-		if err != nil {
-			return nil, httperrors.NewInputParameterError("loadbalancerlistenerrule %s(%s): fetching listener %s failed",
-				lbr.Name, lbr.Id, lbr.ListenerId)
-		}
-		listener := listenerM.(*SLoadbalancerListener)
 		if backendGroup.LoadbalancerId != listener.LoadbalancerId {
 			return nil, httperrors.NewInputParameterError("backend group %s(%s) belongs to loadbalancer %s instead of %s",
 				backendGroup.Name, backendGroup.Id, backendGroup.LoadbalancerId, listener.LoadbalancerId)
 		}
 	}
-	return lbr.SVirtualResourceBase.ValidateUpdateData(ctx, userCred, query, data)
+	if err := loadbalancerValidateCanary(data, lbr.GetOwnerProjectId(), listener.LoadbalancerId, listener.ManagerId, listener.ListenerType, lbr.BackendGroupId, lbr.CanaryBackendGroupId); err != nil {
+		return nil, err
+	}
+	if _, err := lbr.SVirtualResourceBase.ValidateUpdateData(ctx, userCred, query, data); err != nil {
+		return nil, err
+	}
+	region := listener.GetRegion()
+	if region == nil {
+		return nil, httperrors.NewResourceNotFoundError("failed to find region for loadbalancer listener %s", listener.Name)
+	}
+	return region.GetDriver().ValidateUpdateLoadbalancerListenerRuleData(ctx, userCred, data, lbr, backendGroupV.Model)
 }
 
 func (lbr *SLoadbalancerListenerRule) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
 	extra := lbr.SVirtualResourceBase.GetCustomizeColumns(ctx, userCred, query)
 	if lbr.BackendGroupId == "" {
-		log.Errorf("loadbalancer listener rule %s(%s): empty backend group field", lbr.Name, lbr.Id)
+		if lbr.Action == api.LB_RULE_ACTION_FORWARD {
+			log.Errorf("loadbalancer listener rule %s(%s): empty backend group field", lbr.Name, lbr.Id)
+		}
 		return extra
 	}
 	lbbg, err := LoadbalancerBackendGroupManager.FetchById(lbr.BackendGroupId)
@@ -14,6 +14,7 @@ import (
 	api "yunion.io/x/onecloud/pkg/apis/compute"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/quotas"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
 	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
 	"yunion.io/x/onecloud/pkg/cloudprovider"
@@ -98,6 +99,10 @@ type SLoadbalancerListener struct {
 	BackendGroupId    string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"optional" update:"user"`
 	BackendServerPort int    `nullable:"false" get:"user" list:"user" default:"0" create:"optional"`
 
+	// 灰度后端服务器组及其分得的请求百分比
+	CanaryBackendGroupId string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"optional" update:"user"`
+	CanaryWeight         int    `nullable:"false" list:"user" default:"0" create:"optional" update:"user"`
+
 	Scheduler string `width:"16" charset:"ascii" nullable:"false" list:"user" create:"required" update:"user"`
 
 	ClientRequestTimeout  int `nullable:"false" list:"user" create:"optional" update:"user"`
@@ -236,6 +241,9 @@ func (man *SLoadbalancerListenerManager) ValidateCreateData(ctx context.Context,
 			}
 		}
 	}
+	if err := loadbalancerValidateCanary(data, ownerProjId, lb.Id, lb.ManagerId, listenerType, "", ""); err != nil {
+		return nil, err
+	}
 	{
 		if listenerType == api.LB_LISTENER_TYPE_HTTPS {
 			certV := validators.NewModelIdOrNameValidator("certificate", "loadbalancercertificate", ownerProjId)
@@ -288,7 +296,15 @@ func (man *SLoadbalancerListenerManager) ValidateCreateData(ctx context.Context,
 	if region == nil {
 		return nil, httperrors.NewResourceNotFoundError("failed to find region for loadbalancer %s", lb.Name)
 	}
-	return region.GetDriver().ValidateCreateLoadbalancerListenerData(ctx, userCred, data, backendGroupV.Model)
+	data, err := region.GetDriver().ValidateCreateLoadbalancerListenerData(ctx, userCred, data, backendGroupV.Model)
+	if err != nil {
+		return nil, err
+	}
+	pendingUsage := SQuota{Listener: 1}
+	if err := QuotaManager.CheckSetPendingScopedQuota(ctx, userCred, ownerProjId, getQuotaScopes(lb.CloudregionId, lb.ZoneId), &pendingUsage); err != nil {
+		return nil, httperrors.NewOutOfQuotaError("%s", err)
+	}
+	return data, nil
 }
 
 func (man *SLoadbalancerListenerManager) checkTypeV(listenerType string) validators.IValidator {
@@ -318,6 +334,26 @@ func (man *SLoadbalancerListenerManager) validateAcl(aclStatusV *validators.Vali
 	return nil
 }
 
+func (lblis *SLoadbalancerListener) getQuotaScopes() []quotas.SQuotaScope {
+	lb := lblis.GetLoadbalancer()
+	if lb == nil {
+		return getQuotaScopes(lblis.CloudregionId, "")
+	}
+	return getQuotaScopes(lb.CloudregionId, lb.ZoneId)
+}
+
+func totalLoadbalancerListenerCount(projectId string, scope quotas.SQuotaScope) int {
+	q := LoadbalancerListenerManager.Query().Equals("tenant_id", projectId)
+	if len(scope.ZoneId) > 0 {
+		lbs := LoadbalancerManager.Query().SubQuery()
+		subq := lbs.Query(lbs.Field("id")).Equals("zone_id", scope.ZoneId).SubQuery()
+		q = q.Filter(sqlchemy.In(q.Field("loadbalancer_id"), subq))
+	} else if len(scope.RegionId) > 0 {
+		q = q.Equals("cloudregion_id", scope.RegionId)
+	}
+	return q.Count()
+}
+
 func (lblis *SLoadbalancerListener) AllowPerformStatus(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
 	return lblis.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, lblis, "status")
 }
@@ -440,6 +476,9 @@ func (lblis *SLoadbalancerListener) ValidateUpdateData(ctx context.Context, user
 				backendGroup.Name, backendGroup.Id, backendGroup.LoadbalancerId, lblis.LoadbalancerId)
 		}
 	}
+	if err := loadbalancerValidateCanary(data, ownerProjId, lblis.LoadbalancerId, lblis.ManagerId, lblis.ListenerType, lblis.BackendGroupId, lblis.CanaryBackendGroupId); err != nil {
+		return nil, err
+	}
 	if _, err := lblis.SVirtualResourceBase.ValidateUpdateData(ctx, userCred, query, data); err != nil {
 		return nil, err
 	}
@@ -504,6 +543,8 @@ func (lblis *SLoadbalancerListener) GetExtraDetails(ctx context.Context, userCre
 
 func (lblis *SLoadbalancerListener) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
 	lblis.SVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
+	pendingUsage := SQuota{Listener: 1}
+	QuotaManager.CancelPendingScopedUsage(ctx, userCred, ownerProjId, lblis.getQuotaScopes(), &pendingUsage, &pendingUsage)
 
 	lblis.SetStatus(userCred, api.LB_CREATING, "")
 	if err := lblis.StartLoadBalancerListenerCreateTask(ctx, userCred, ""); err != nil {
@@ -511,6 +552,13 @@ func (lblis *SLoadbalancerListener) PostCreate(ctx context.Context, userCred mcc
 	}
 }
 
+func (man *SLoadbalancerListenerManager) OnCreateFailed(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	lblis := &SLoadbalancerListener{}
+	data.Unmarshal(lblis)
+	pendingUsage := SQuota{Listener: 1}
+	QuotaManager.CancelPendingScopedUsage(ctx, userCred, ownerProjId, lblis.getQuotaScopes(), &pendingUsage, &pendingUsage)
+}
+
 func (lblis *SLoadbalancerListener) StartLoadBalancerListenerCreateTask(ctx context.Context, userCred mcclient.TokenCredential, parentTaskId string) error {
 	task, err := taskman.TaskManager.NewTask(ctx, "LoadbalancerListenerCreateTask", lblis, userCred, nil, parentTaskId, "", nil)
 	if err != nil {
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/util/influxdb"
+)
+
+const (
+	lblisAccessStatsIntervalDefault = 300
+	lblisAccessStatsIntervalMax     = 86400
+)
+
+// accessLogInfluxdbs returns where lbagents serving the loadbalancer write
+// access logs to.
+//
+// Agents form clusters by vrrp virtual router id and the master of each
+// cluster holds addresses of all enabled local loadbalancers.  Only clusters
+// with an active master are serving.  Backups of these clusters are included
+// as they may have been master in the queried interval.  Agents sharing the
+// same influxdb are queried once
+func (man *SLoadbalancerAgentManager) accessLogInfluxdbs(lb *SLoadbalancer) ([]*influxdb.SInfluxdb, error) {
+	if lb.Status != api.LB_STATUS_ENABLED || lb.Address == "" {
+		return nil, fmt.Errorf("loadbalancer %s(%s) is not served by lbagents", lb.Name, lb.Id)
+	}
+	agents := []SLoadbalancerAgent{}
+	q := man.Query()
+	if err := db.FetchModelObjects(man, q, &agents); err != nil {
+		return nil, err
+	}
+	serving := map[int]bool{}
+	for i := range agents {
+		agent := &agents[i]
+		if agent.Params != nil && agent.HaState == api.LB_HA_STATE_MASTER && agent.IsActive() {
+			serving[agent.Params.Vrrp.VirtualRouterId] = true
+		}
+	}
+	clients := []*influxdb.SInfluxdb{}
+	targets := map[string]bool{}
+	for i := range agents {
+		params := agents[i].Params
+		if params == nil || !serving[params.Vrrp.VirtualRouterId] {
+			continue
+		}
+		if !params.Haproxy.AccessLog || params.Telegraf.InfluxDbOutputUrl == "" {
+			continue
+		}
+		target := params.Telegraf.InfluxDbOutputUrl + "/" + params.Telegraf.InfluxDbOutputName
+		if targets[target] {
+			continue
+		}
+		targets[target] = true
+		client := influxdb.NewInfluxdb(params.Telegraf.InfluxDbOutputUrl)
+		if err := client.SetDatabase(params.Telegraf.InfluxDbOutputName); err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("no serving lbagent has access log enabled")
+	}
+	return clients, nil
+}
+
+func (lblis *SLoadbalancerListener) AllowGetDetailsAccessStats(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
+	return lblis.IsOwner(userCred) || db.IsAdminAllowGetSpec(userCred, lblis, "access-stats")
+}
+
+// GetDetailsAccessStats summarizes access logs of the listener in recent
+// interval seconds: requests per second, ratio of http 5xx responses and
+// p99 latency in milliseconds
+func (lblis *SLoadbalancerListener) GetDetailsAccessStats(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if len(lblis.ManagerId) > 0 {
+		return nil, httperrors.NewUnsupportOperationError("access stats is not supported by managed loadbalancer listener")
+	}
+	interval, _ := query.Int("interval")
+	if interval <= 0 {
+		interval = lblisAccessStatsIntervalDefault
+	}
+	if interval > lblisAccessStatsIntervalMax {
+		return nil, httperrors.NewInputParameterError("interval must not exceed %d seconds", lblisAccessStatsIntervalMax)
+	}
+	lb := lblis.GetLoadbalancer()
+	if lb == nil {
+		return nil, httperrors.NewResourceNotFoundError("failed to find loadbalancer %s", lblis.LoadbalancerId)
+	}
+	clients, err := LoadbalancerAgentManager.accessLogInfluxdbs(lb)
+	if err != nil {
+		return nil, httperrors.NewGeneralError(err)
+	}
+	where := fmt.Sprintf(`"listener_id" = '%s' AND time > now() - %ds`, lblis.Id, interval)
+	countSql := fmt.Sprintf(`SELECT count("status"), sum("http_5xx") FROM "%s" WHERE %s`,
+		api.LB_ACCESS_LOG_MEASUREMENT, where)
+	// latency is -1 for aborted sessions, they are counted as requests but
+	// left out of the latency
+	latencySql := fmt.Sprintf(`SELECT percentile("latency", 99) FROM "%s" WHERE %s AND "latency" >= 0`,
+		api.LB_ACCESS_LOG_MEASUREMENT, where)
+	// logs of the listener are only in influxdbs of agents that took its
+	// traffic.  p99 from different influxdbs can not be merged exactly, the
+	// largest one is taken
+	var count, http5xx, p99 float64
+	for _, client := range clients {
+		// columns are time, count, sum
+		values, err := accessStatsQueryRow(client, countSql, 3)
+		if err != nil {
+			return nil, httperrors.NewGeneralError(err)
+		}
+		if values != nil {
+			c, _ := values[1].Float()
+			h, _ := values[2].Float()
+			count += c
+			http5xx += h
+		}
+		// columns are time, percentile
+		values, err = accessStatsQueryRow(client, latencySql, 2)
+		if err != nil {
+			return nil, httperrors.NewGeneralError(err)
+		}
+		if values != nil {
+			p, _ := values[1].Float()
+			if p > p99 {
+				p99 = p
+			}
+		}
+	}
+	ret := jsonutils.NewDict()
+	ret.Set("interval", jsonutils.NewInt(interval))
+	ret.Set("requests", jsonutils.NewInt(int64(count)))
+	ret.Set("request_rate", jsonutils.NewFloat(count/float64(interval)))
+	if count > 0 {
+		ret.Set("http_5xx_ratio", jsonutils.NewFloat(http5xx/count))
+	} else {
+		ret.Set("http_5xx_ratio", jsonutils.NewFloat(0))
+	}
+	ret.Set("p99_latency_ms", jsonutils.NewFloat(p99))
+	return ret, nil
+}
+
+// accessStatsQueryRow returns the first row of the query result, nil if there
+// is no row with the expected number of columns
+func accessStatsQueryRow(client *influxdb.SInfluxdb, sql string, columns int) ([]jsonutils.JSONObject, error) {
+	results, err := client.Query(sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 || len(results[0]) == 0 || len(results[0][0].Values) == 0 {
+		return nil, nil
+	}
+	values := results[0][0].Values[0]
+	if len(values) < columns {
+		return nil, nil
+	}
+	return values, nil
+}
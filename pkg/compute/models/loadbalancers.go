@@ -13,6 +13,7 @@ import (
 	api "yunion.io/x/onecloud/pkg/apis/compute"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/quotas"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
 	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
 	"yunion.io/x/onecloud/pkg/cloudprovider"
@@ -121,6 +122,7 @@ func (man *SLoadbalancerManager) ValidateCreateData(ctx context.Context, userCre
 		}
 	}
 	var region *SCloudregion
+	var zone *SZone
 	if addressTypeV.Value == api.LB_ADDR_TYPE_INTRANET {
 		network := networkV.Model.(*SNetwork)
 		if ipAddr := addressV.IP; ipAddr != nil {
@@ -153,7 +155,7 @@ func (man *SLoadbalancerManager) ValidateCreateData(ctx context.Context, userCre
 		if len(vpc.ManagerId) > 0 {
 			data.Set("manager_id", jsonutils.NewString(vpc.ManagerId))
 		}
-		zone := wire.GetZone()
+		zone = wire.GetZone()
 		if zone == nil {
 			return nil, fmt.Errorf("getting zone failed")
 		}
@@ -167,7 +169,7 @@ func (man *SLoadbalancerManager) ValidateCreateData(ctx context.Context, userCre
 		data.Set("network_type", jsonutils.NewString(api.LB_NETWORK_TYPE_CLASSIC))
 		data.Set("address_type", jsonutils.NewString(api.LB_ADDR_TYPE_INTRANET))
 	} else {
-		zone := zoneV.Model.(*SZone)
+		zone = zoneV.Model.(*SZone)
 		region = zone.GetRegion()
 		if region == nil {
 			return nil, fmt.Errorf("getting region failed")
@@ -183,7 +185,25 @@ func (man *SLoadbalancerManager) ValidateCreateData(ctx context.Context, userCre
 	if _, err := man.SVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data); err != nil {
 		return nil, err
 	}
-	return region.GetDriver().ValidateCreateLoadbalancerData(ctx, userCred, data)
+	data, err := region.GetDriver().ValidateCreateLoadbalancerData(ctx, userCred, data)
+	if err != nil {
+		return nil, err
+	}
+	pendingUsage := SQuota{Loadbalancer: 1}
+	if err := QuotaManager.CheckSetPendingScopedQuota(ctx, userCred, ownerProjId, getQuotaScopes(region.Id, zone.Id), &pendingUsage); err != nil {
+		return nil, httperrors.NewOutOfQuotaError("%s", err)
+	}
+	return data, nil
+}
+
+func totalLoadbalancerCount(projectId string, scope quotas.SQuotaScope) int {
+	q := LoadbalancerManager.Query().Equals("tenant_id", projectId)
+	if len(scope.ZoneId) > 0 {
+		q = q.Equals("zone_id", scope.ZoneId)
+	} else if len(scope.RegionId) > 0 {
+		q = q.Equals("cloudregion_id", scope.RegionId)
+	}
+	return q.Count()
 }
 
 func (lb *SLoadbalancer) AllowPerformStatus(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
@@ -240,6 +260,8 @@ func (lb *SLoadbalancer) StartLoadBalancerSyncstatusTask(ctx context.Context, us
 
 func (lb *SLoadbalancer) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
 	lb.SVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
+	pendingUsage := SQuota{Loadbalancer: 1}
+	QuotaManager.CancelPendingScopedUsage(ctx, userCred, ownerProjId, getQuotaScopes(lb.CloudregionId, lb.ZoneId), &pendingUsage, &pendingUsage)
 	// NOTE lb.Id will only be available after BeforeInsert happens
 	// NOTE this means lb.UpdateVersion will be 0, then 1 after creation
 	// NOTE need ways to notify error
@@ -250,6 +272,13 @@ func (lb *SLoadbalancer) PostCreate(ctx context.Context, userCred mcclient.Token
 	}
 }
 
+func (man *SLoadbalancerManager) OnCreateFailed(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	lb := &SLoadbalancer{}
+	data.Unmarshal(lb)
+	pendingUsage := SQuota{Loadbalancer: 1}
+	QuotaManager.CancelPendingScopedUsage(ctx, userCred, ownerProjId, getQuotaScopes(lb.CloudregionId, lb.ZoneId), &pendingUsage, &pendingUsage)
+}
+
 func (lb *SLoadbalancer) GetCloudprovider() *SCloudprovider {
 	cloudprovider, err := CloudproviderManager.FetchById(lb.ManagerId)
 	if err != nil {
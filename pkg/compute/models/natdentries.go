@@ -0,0 +1,305 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/compare"
+	"yunion.io/x/sqlchemy"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+type SNatDEntryManager struct {
+	db.SVirtualResourceBaseManager
+}
+
+var NatDEntryManager *SNatDEntryManager
+
+func init() {
+	NatDEntryManager = &SNatDEntryManager{
+		SVirtualResourceBaseManager: db.NewVirtualResourceBaseManager(
+			SNatDEntry{},
+			"natdentries_tbl",
+			"natdentry",
+			"natdentries",
+		),
+	}
+}
+
+type SNatDEntry struct {
+	db.SVirtualResourceBase
+	SManagedResourceBase
+
+	NatgatewayId string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"required"`
+	IpProtocol   string `width:"8" charset:"ascii" list:"user" create:"required"`
+	ExternalIp   string `width:"17" charset:"ascii" list:"user" create:"required"` // DNAT公网IP
+	ExternalPort int    `list:"user" create:"required"`
+	InternalIp   string `width:"17" charset:"ascii" list:"user" create:"required"`
+	InternalPort int    `list:"user" create:"required"`
+}
+
+func (man *SNatDEntryManager) ListItemFilter(ctx context.Context, q *sqlchemy.SQuery, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*sqlchemy.SQuery, error) {
+	var err error
+	q, err = managedResourceFilterByAccount(q, query, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	q = managedResourceFilterByCloudType(q, query, "", nil)
+
+	q, err = man.SVirtualResourceBaseManager.ListItemFilter(ctx, q, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	userProjId := userCred.GetProjectId()
+	data := query.(*jsonutils.JSONDict)
+	q, err = validators.ApplyModelFilters(q, data, []*validators.ModelFilterOptions{
+		{Key: "natgateway", ModelKeyword: "natgateway", ProjectId: userProjId},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (man *SNatDEntryManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	natgatewayV := validators.NewModelIdOrNameValidator("natgateway", "natgateway", ownerProjId)
+	eipV := validators.NewModelIdOrNameValidator("eip", "eip", ownerProjId)
+	keyV := map[string]validators.IValidator{
+		"natgateway":    natgatewayV,
+		"eip":           eipV,
+		"ip_protocol":   validators.NewStringChoicesValidator("ip_protocol", api.NAT_DNAT_PROTOCOLS).Default(api.NAT_DNAT_PROTOCOL_TCP),
+		"external_port": validators.NewPortValidator("external_port"),
+		"internal_ip":   validators.NewIPv4AddrValidator("internal_ip"),
+		"internal_port": validators.NewPortValidator("internal_port"),
+	}
+	for _, v := range keyV {
+		if err := v.Validate(data); err != nil {
+			return nil, err
+		}
+	}
+	natgateway := natgatewayV.Model.(*SNatGateway)
+	eip := eipV.Model.(*SElasticip)
+	if eip.CloudregionId != natgateway.CloudregionId || eip.ManagerId != natgateway.ManagerId {
+		return nil, httperrors.NewInputParameterError("eip %s and natgateway %s are not in the same region", eip.Name, natgateway.Name)
+	}
+	if eip.IsAssociated() {
+		return nil, httperrors.NewConflictError("eip %s has been associated with instance", eip.Name)
+	}
+	data.Set("external_ip", jsonutils.NewString(eip.IpAddr))
+	data.Set("manager_id", jsonutils.NewString(natgateway.ManagerId))
+	return man.SVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+}
+
+func (self *SNatDEntry) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	self.SVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
+
+	self.SetStatus(userCred, api.NAT_STATUS_ALLOCATE, "")
+	params := jsonutils.NewDict()
+	if eipId, _ := data.GetString("eip_id"); len(eipId) > 0 {
+		params.Set("eip_id", jsonutils.NewString(eipId))
+	}
+	if err := self.StartNatDEntryCreateTask(ctx, userCred, params, ""); err != nil {
+		log.Errorf("Failed to create dnat entry error: %v", err)
+	}
+}
+
+func (self *SNatDEntry) StartNatDEntryCreateTask(ctx context.Context, userCred mcclient.TokenCredential, params *jsonutils.JSONDict, parentTaskId string) error {
+	task, err := taskman.TaskManager.NewTask(ctx, "NatDEntryCreateTask", self, userCred, params, parentTaskId, "", nil)
+	if err != nil {
+		return err
+	}
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (self *SNatDEntry) GetNatgateway() (*SNatGateway, error) {
+	natgateway, err := NatGatewayManager.FetchById(self.NatgatewayId)
+	if err != nil {
+		return nil, err
+	}
+	return natgateway.(*SNatGateway), nil
+}
+
+func (self *SNatDEntry) GetINatDEntry() (cloudprovider.ICloudNatDEntry, error) {
+	natgateway, err := self.GetNatgateway()
+	if err != nil {
+		return nil, err
+	}
+	inatgateway, err := natgateway.GetINatGateway()
+	if err != nil {
+		return nil, err
+	}
+	return inatgateway.GetINatDEntryById(self.ExternalId)
+}
+
+func (self *SNatDEntry) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSONDict {
+	if natgateway, err := self.GetNatgateway(); err == nil {
+		extra.Set("natgateway", jsonutils.NewString(natgateway.Name))
+	}
+	return extra
+}
+
+func (self *SNatDEntry) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
+	extra := self.SVirtualResourceBase.GetCustomizeColumns(ctx, userCred, query)
+	return self.getMoreDetails(extra)
+}
+
+func (self *SNatDEntry) GetExtraDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	extra, err := self.SVirtualResourceBase.GetExtraDetails(ctx, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	return self.getMoreDetails(extra), nil
+}
+
+func (self *SNatDEntry) AllowPerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return db.IsAdminAllowPerform(userCred, self, "purge")
+}
+
+func (self *SNatDEntry) PerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	provider := self.GetCloudprovider()
+	if provider != nil {
+		if provider.Enabled {
+			return nil, httperrors.NewInvalidStatusError("Cannot purge dnat entry on enabled cloud provider")
+		}
+	}
+	err := self.RealDelete(ctx, userCred)
+	return nil, err
+}
+
+func (self *SNatDEntry) Delete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return nil
+}
+
+func (self *SNatDEntry) RealDelete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return self.SVirtualResourceBase.Delete(ctx, userCred)
+}
+
+func (self *SNatDEntry) CustomizeDelete(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) error {
+	self.SetStatus(userCred, api.NAT_STATUS_DELETING, "")
+	return self.StartNatDEntryDeleteTask(ctx, userCred, "")
+}
+
+func (self *SNatDEntry) StartNatDEntryDeleteTask(ctx context.Context, userCred mcclient.TokenCredential, parentTaskId string) error {
+	task, err := taskman.TaskManager.NewTask(ctx, "NatDEntryDeleteTask", self, userCred, nil, parentTaskId, "", nil)
+	if err != nil {
+		return err
+	}
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (man *SNatDEntryManager) SyncNatDEntries(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, natgateway *SNatGateway, extEntries []cloudprovider.ICloudNatDEntry) compare.SyncResult {
+	lockman.LockClass(ctx, man, man.GetOwnerId(userCred))
+	defer lockman.ReleaseClass(ctx, man, man.GetOwnerId(userCred))
+
+	syncResult := compare.SyncResult{}
+
+	dbEntries, err := natgateway.GetNatDEntries()
+	if err != nil {
+		syncResult.Error(err)
+		return syncResult
+	}
+
+	removed := make([]SNatDEntry, 0)
+	commondb := make([]SNatDEntry, 0)
+	commonext := make([]cloudprovider.ICloudNatDEntry, 0)
+	added := make([]cloudprovider.ICloudNatDEntry, 0)
+	if err := compare.CompareSets(dbEntries, extEntries, &removed, &commondb, &commonext, &added); err != nil {
+		syncResult.Error(err)
+		return syncResult
+	}
+
+	for i := 0; i < len(removed); i += 1 {
+		err := removed[i].syncRemoveCloudNatDEntry(ctx, userCred)
+		if err != nil {
+			syncResult.DeleteError(err)
+		} else {
+			syncResult.Delete()
+		}
+	}
+
+	for i := 0; i < len(commondb); i += 1 {
+		err := commondb[i].SyncWithCloudNatDEntry(ctx, userCred, commonext[i])
+		if err != nil {
+			syncResult.UpdateError(err)
+			continue
+		}
+		syncResult.Update()
+	}
+
+	for i := 0; i < len(added); i += 1 {
+		_, err := man.newFromCloudNatDEntry(ctx, userCred, natgateway, added[i])
+		if err != nil {
+			syncResult.AddError(err)
+			continue
+		}
+		syncResult.Add()
+	}
+	return syncResult
+}
+
+func (self *SNatDEntry) syncRemoveCloudNatDEntry(ctx context.Context, userCred mcclient.TokenCredential) error {
+	lockman.LockObject(ctx, self)
+	defer lockman.ReleaseObject(ctx, self)
+
+	err := self.ValidateDeleteCondition(ctx)
+	if err != nil {
+		self.SetStatus(userCred, api.NAT_STATUS_UNKNOWN, "sync to delete")
+		return err
+	}
+	return self.RealDelete(ctx, userCred)
+}
+
+func (self *SNatDEntry) SyncWithCloudNatDEntry(ctx context.Context, userCred mcclient.TokenCredential, extEntry cloudprovider.ICloudNatDEntry) error {
+	diff, err := db.UpdateWithLock(ctx, self, func() error {
+		self.Status = extEntry.GetStatus()
+		self.IpProtocol = extEntry.GetIpProtocol()
+		self.ExternalIp = extEntry.GetExternalIp()
+		self.ExternalPort = extEntry.GetExternalPort()
+		self.InternalIp = extEntry.GetInternalIp()
+		self.InternalPort = extEntry.GetInternalPort()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	db.OpsLog.LogSyncUpdate(self, diff, userCred)
+	return nil
+}
+
+func (man *SNatDEntryManager) newFromCloudNatDEntry(ctx context.Context, userCred mcclient.TokenCredential, natgateway *SNatGateway, extEntry cloudprovider.ICloudNatDEntry) (*SNatDEntry, error) {
+	entry := SNatDEntry{}
+	entry.SetModelManager(man)
+
+	entry.Name = db.GenerateName(man, natgateway.ProjectId, extEntry.GetName())
+	entry.Status = extEntry.GetStatus()
+	entry.ExternalId = extEntry.GetGlobalId()
+	entry.IsEmulated = extEntry.IsEmulated()
+	entry.ManagerId = natgateway.ManagerId
+	entry.ProjectId = natgateway.ProjectId
+	entry.NatgatewayId = natgateway.Id
+	entry.IpProtocol = extEntry.GetIpProtocol()
+	entry.ExternalIp = extEntry.GetExternalIp()
+	entry.ExternalPort = extEntry.GetExternalPort()
+	entry.InternalIp = extEntry.GetInternalIp()
+	entry.InternalPort = extEntry.GetInternalPort()
+
+	err := man.TableSpec().Insert(&entry)
+	if err != nil {
+		return nil, fmt.Errorf("newFromCloudNatDEntry fail %s", err)
+	}
+
+	db.OpsLog.LogEvent(&entry, db.ACT_CREATE, entry.GetShortDesc(ctx), userCred)
+	return &entry, nil
+}
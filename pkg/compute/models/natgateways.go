@@ -0,0 +1,425 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/compare"
+	"yunion.io/x/sqlchemy"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+type SNatGatewayManager struct {
+	db.SVirtualResourceBaseManager
+}
+
+var NatGatewayManager *SNatGatewayManager
+
+func init() {
+	NatGatewayManager = &SNatGatewayManager{
+		SVirtualResourceBaseManager: db.NewVirtualResourceBaseManager(
+			SNatGateway{},
+			"natgateways_tbl",
+			"natgateway",
+			"natgateways",
+		),
+	}
+}
+
+type SNatGateway struct {
+	db.SVirtualResourceBase
+	SManagedResourceBase
+	SBillingResourceBase
+
+	VpcId         string `width:"36" charset:"ascii" nullable:"false" list:"user"`
+	CloudregionId string `width:"36" charset:"ascii" nullable:"false" list:"user"`
+	NatSpec       string `width:"32" charset:"ascii" nullable:"true" list:"user"` // NAT规格
+}
+
+func (man *SNatGatewayManager) ListItemFilter(ctx context.Context, q *sqlchemy.SQuery, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*sqlchemy.SQuery, error) {
+	var err error
+	q, err = managedResourceFilterByAccount(q, query, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	q = managedResourceFilterByCloudType(q, query, "", nil)
+
+	q, err = man.SVirtualResourceBaseManager.ListItemFilter(ctx, q, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	userProjId := userCred.GetProjectId()
+	data := query.(*jsonutils.JSONDict)
+	q, err = validators.ApplyModelFilters(q, data, []*validators.ModelFilterOptions{
+		{Key: "vpc", ModelKeyword: "vpc", ProjectId: userProjId},
+		{Key: "cloudregion", ModelKeyword: "cloudregion", ProjectId: userProjId},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (man *SNatGatewayManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	vpcV := validators.NewModelIdOrNameValidator("vpc", "vpc", ownerProjId)
+	networkV := validators.NewModelIdOrNameValidator("network", "network", ownerProjId)
+	eipV := validators.NewModelIdOrNameValidator("eip", "eip", ownerProjId)
+	keyV := map[string]validators.IValidator{
+		"vpc":      vpcV,
+		"network":  networkV.Optional(true),
+		"eip":      eipV.Optional(true),
+		"nat_spec": validators.NewStringChoicesValidator("nat_spec", api.NAT_SPECS).Default(api.NAT_SPEC_SMALL),
+	}
+	for _, v := range keyV {
+		if err := v.Validate(data); err != nil {
+			return nil, err
+		}
+	}
+	vpc := vpcV.Model.(*SVpc)
+	provider := vpc.GetCloudprovider()
+	if provider == nil {
+		return nil, httperrors.NewInputParameterError("vpc %s is not managed by any cloud provider", vpc.Name)
+	}
+	if networkV.Model != nil {
+		network := networkV.Model.(*SNetwork)
+		networkVpc := network.GetVpc()
+		if networkVpc == nil || networkVpc.Id != vpc.Id {
+			return nil, httperrors.NewInputParameterError("network %s not in vpc %s", network.Name, vpc.Name)
+		}
+	}
+	if eipV.Model != nil {
+		eip := eipV.Model.(*SElasticip)
+		if eip.CloudregionId != vpc.CloudregionId || eip.ManagerId != vpc.ManagerId {
+			return nil, httperrors.NewInputParameterError("eip %s and vpc %s are not in the same region", eip.Name, vpc.Name)
+		}
+		if eip.IsAssociated() {
+			return nil, httperrors.NewConflictError("eip %s has been associated with instance", eip.Name)
+		}
+	}
+	region, err := vpc.GetRegion()
+	if err != nil {
+		return nil, httperrors.NewGeneralError(err)
+	}
+	data, err = region.GetDriver().ValidateCreateNatGatewayData(ctx, userCred, data)
+	if err != nil {
+		return nil, err
+	}
+	data.Set("cloudregion_id", jsonutils.NewString(vpc.CloudregionId))
+	data.Set("manager_id", jsonutils.NewString(vpc.ManagerId))
+	return man.SVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+}
+
+func (self *SNatGateway) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	self.SVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
+
+	self.SetStatus(userCred, api.NAT_STATUS_ALLOCATE, "")
+	// 子网和EIP只在创建时使用, 不保存在NAT网关上
+	params := data.(*jsonutils.JSONDict).CopyIncludes("network_id", "eip_id")
+	if err := self.StartNatGatewayCreateTask(ctx, userCred, params, ""); err != nil {
+		self.SetStatus(userCred, api.NAT_STATUS_CREATE_FAILED, err.Error())
+	}
+}
+
+func (self *SNatGateway) StartNatGatewayCreateTask(ctx context.Context, userCred mcclient.TokenCredential, params *jsonutils.JSONDict, parentTaskId string) error {
+	task, err := taskman.TaskManager.NewTask(ctx, "NatGatewayCreateTask", self, userCred, params, parentTaskId, "", nil)
+	if err != nil {
+		log.Errorf("newTask NatGatewayCreateTask fail %s", err)
+		return err
+	}
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (self *SNatGateway) GetVpc() (*SVpc, error) {
+	vpc, err := VpcManager.FetchById(self.VpcId)
+	if err != nil {
+		return nil, err
+	}
+	return vpc.(*SVpc), nil
+}
+
+func (self *SNatGateway) GetRegion() *SCloudregion {
+	return CloudregionManager.FetchRegionById(self.CloudregionId)
+}
+
+func (self *SNatGateway) GetIRegion() (cloudprovider.ICloudRegion, error) {
+	provider, err := self.GetDriver()
+	if err != nil {
+		return nil, err
+	}
+	region := self.GetRegion()
+	if region == nil {
+		return nil, fmt.Errorf("fail to find region for natgateway %s", self.Name)
+	}
+	return provider.GetIRegionById(region.GetExternalId())
+}
+
+func (self *SNatGateway) GetINatGateway() (cloudprovider.ICloudNatGateway, error) {
+	iregion, err := self.GetIRegion()
+	if err != nil {
+		return nil, err
+	}
+	vpc, err := self.GetVpc()
+	if err != nil {
+		return nil, err
+	}
+	ivpc, err := iregion.GetIVpcById(vpc.ExternalId)
+	if err != nil {
+		return nil, err
+	}
+	natGateways, err := ivpc.GetINatGateways()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(natGateways); i++ {
+		if natGateways[i].GetGlobalId() == self.ExternalId {
+			return natGateways[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SNatGateway) GetNatSEntries() ([]SNatSEntry, error) {
+	entries := []SNatSEntry{}
+	q := NatSEntryManager.Query().Equals("natgateway_id", self.Id)
+	if err := db.FetchModelObjects(NatSEntryManager, q, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (self *SNatGateway) GetNatDEntries() ([]SNatDEntry, error) {
+	entries := []SNatDEntry{}
+	q := NatDEntryManager.Query().Equals("natgateway_id", self.Id)
+	if err := db.FetchModelObjects(NatDEntryManager, q, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (self *SNatGateway) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSONDict {
+	region := self.GetRegion()
+	provider := self.GetCloudprovider()
+	info := MakeCloudProviderInfo(region, nil, provider)
+	extra.Update(jsonutils.Marshal(&info))
+	if vpc, err := self.GetVpc(); err == nil {
+		extra.Set("vpc", jsonutils.NewString(vpc.Name))
+	}
+	return extra
+}
+
+func (self *SNatGateway) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
+	extra := self.SVirtualResourceBase.GetCustomizeColumns(ctx, userCred, query)
+	return self.getMoreDetails(extra)
+}
+
+func (self *SNatGateway) GetExtraDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	extra, err := self.SVirtualResourceBase.GetExtraDetails(ctx, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	return self.getMoreDetails(extra), nil
+}
+
+func (man *SNatGatewayManager) getNatGatewaysByVpc(vpc *SVpc) ([]SNatGateway, error) {
+	natGateways := []SNatGateway{}
+	q := man.Query().Equals("vpc_id", vpc.Id)
+	if err := db.FetchModelObjects(man, q, &natGateways); err != nil {
+		return nil, err
+	}
+	return natGateways, nil
+}
+
+func (man *SNatGatewayManager) SyncNatGateways(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, vpc *SVpc, cloudNatGateways []cloudprovider.ICloudNatGateway) ([]SNatGateway, []cloudprovider.ICloudNatGateway, compare.SyncResult) {
+	lockman.LockClass(ctx, man, man.GetOwnerId(userCred))
+	defer lockman.ReleaseClass(ctx, man, man.GetOwnerId(userCred))
+
+	localNatGateways := make([]SNatGateway, 0)
+	remoteNatGateways := make([]cloudprovider.ICloudNatGateway, 0)
+	syncResult := compare.SyncResult{}
+
+	dbNatGateways, err := man.getNatGatewaysByVpc(vpc)
+	if err != nil {
+		syncResult.Error(err)
+		return nil, nil, syncResult
+	}
+
+	removed := make([]SNatGateway, 0)
+	commondb := make([]SNatGateway, 0)
+	commonext := make([]cloudprovider.ICloudNatGateway, 0)
+	added := make([]cloudprovider.ICloudNatGateway, 0)
+	if err := compare.CompareSets(dbNatGateways, cloudNatGateways, &removed, &commondb, &commonext, &added); err != nil {
+		syncResult.Error(err)
+		return nil, nil, syncResult
+	}
+
+	for i := 0; i < len(removed); i += 1 {
+		err := removed[i].syncRemoveCloudNatGateway(ctx, userCred)
+		if err != nil {
+			syncResult.DeleteError(err)
+		} else {
+			syncResult.Delete()
+		}
+	}
+
+	for i := 0; i < len(commondb); i += 1 {
+		err := commondb[i].SyncWithCloudNatGateway(ctx, userCred, provider, commonext[i])
+		if err != nil {
+			syncResult.UpdateError(err)
+			continue
+		}
+		syncMetadata(ctx, userCred, &commondb[i], commonext[i])
+		localNatGateways = append(localNatGateways, commondb[i])
+		remoteNatGateways = append(remoteNatGateways, commonext[i])
+		syncResult.Update()
+	}
+
+	for i := 0; i < len(added); i += 1 {
+		natGateway, err := man.newFromCloudNatGateway(ctx, userCred, provider, vpc, added[i])
+		if err != nil {
+			syncResult.AddError(err)
+			continue
+		}
+		syncMetadata(ctx, userCred, natGateway, added[i])
+		localNatGateways = append(localNatGateways, *natGateway)
+		remoteNatGateways = append(remoteNatGateways, added[i])
+		syncResult.Add()
+	}
+	return localNatGateways, remoteNatGateways, syncResult
+}
+
+func (self *SNatGateway) syncRemoveCloudNatGateway(ctx context.Context, userCred mcclient.TokenCredential) error {
+	lockman.LockObject(ctx, self)
+	defer lockman.ReleaseObject(ctx, self)
+
+	err := self.ValidateDeleteCondition(ctx)
+	if err != nil {
+		self.SetStatus(userCred, api.NAT_STATUS_UNKNOWN, "sync to delete")
+		return err
+	}
+	return self.Purge(ctx, userCred)
+}
+
+func (self *SNatGateway) SyncWithCloudNatGateway(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, extNat cloudprovider.ICloudNatGateway) error {
+	diff, err := db.UpdateWithLock(ctx, self, func() error {
+		self.Status = extNat.GetStatus()
+		self.NatSpec = extNat.GetNatSpec()
+
+		factory, _ := provider.GetProviderFactory()
+		if factory != nil && factory.IsSupportPrepaidResources() {
+			self.BillingType = extNat.GetBillingType()
+			self.ExpiredAt = extNat.GetExpiredAt()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	db.OpsLog.LogSyncUpdate(self, diff, userCred)
+	return nil
+}
+
+func (man *SNatGatewayManager) newFromCloudNatGateway(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, vpc *SVpc, extNat cloudprovider.ICloudNatGateway) (*SNatGateway, error) {
+	natGateway := SNatGateway{}
+	natGateway.SetModelManager(man)
+
+	natGateway.Name = db.GenerateName(man, provider.ProjectId, extNat.GetName())
+	natGateway.VpcId = vpc.Id
+	natGateway.CloudregionId = vpc.CloudregionId
+	natGateway.Status = extNat.GetStatus()
+	natGateway.NatSpec = extNat.GetNatSpec()
+	natGateway.ExternalId = extNat.GetGlobalId()
+	natGateway.IsEmulated = extNat.IsEmulated()
+	natGateway.ManagerId = provider.Id
+	natGateway.ProjectId = provider.ProjectId
+	if len(natGateway.ProjectId) == 0 {
+		natGateway.ProjectId = userCred.GetProjectId()
+	}
+
+	factory, _ := provider.GetProviderFactory()
+	if factory != nil && factory.IsSupportPrepaidResources() {
+		natGateway.BillingType = extNat.GetBillingType()
+		natGateway.ExpiredAt = extNat.GetExpiredAt()
+	}
+
+	err := man.TableSpec().Insert(&natGateway)
+	if err != nil {
+		log.Errorf("newFromCloudNatGateway fail %s", err)
+		return nil, err
+	}
+
+	db.OpsLog.LogEvent(&natGateway, db.ACT_CREATE, natGateway.GetShortDesc(ctx), userCred)
+	return &natGateway, nil
+}
+
+func (self *SNatGateway) AllowPerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return db.IsAdminAllowPerform(userCred, self, "purge")
+}
+
+func (self *SNatGateway) PerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	provider := self.GetCloudprovider()
+	if provider != nil {
+		if provider.Enabled {
+			return nil, httperrors.NewInvalidStatusError("Cannot purge natgateway on enabled cloud provider")
+		}
+	}
+	err := self.Purge(ctx, userCred)
+	return nil, err
+}
+
+// 清理本地NAT网关及其SNAT/DNAT条目
+func (self *SNatGateway) Purge(ctx context.Context, userCred mcclient.TokenCredential) error {
+	sentries, err := self.GetNatSEntries()
+	if err != nil {
+		return err
+	}
+	for i := range sentries {
+		if err := sentries[i].RealDelete(ctx, userCred); err != nil {
+			return err
+		}
+	}
+	dentries, err := self.GetNatDEntries()
+	if err != nil {
+		return err
+	}
+	for i := range dentries {
+		if err := dentries[i].RealDelete(ctx, userCred); err != nil {
+			return err
+		}
+	}
+	return self.RealDelete(ctx, userCred)
+}
+
+func (self *SNatGateway) Delete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	log.Infof("NatGateway delete do nothing")
+	return nil
+}
+
+func (self *SNatGateway) RealDelete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return self.SVirtualResourceBase.Delete(ctx, userCred)
+}
+
+func (self *SNatGateway) CustomizeDelete(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) error {
+	return self.StartNatGatewayDeleteTask(ctx, userCred, "")
+}
+
+func (self *SNatGateway) StartNatGatewayDeleteTask(ctx context.Context, userCred mcclient.TokenCredential, parentTaskId string) error {
+	task, err := taskman.TaskManager.NewTask(ctx, "NatGatewayDeleteTask", self, userCred, nil, parentTaskId, "", nil)
+	if err != nil {
+		log.Errorf("newTask NatGatewayDeleteTask fail %s", err)
+		return err
+	}
+	self.SetStatus(userCred, api.NAT_STATUS_DELETING, "start to delete")
+	task.ScheduleRun(nil)
+	return nil
+}
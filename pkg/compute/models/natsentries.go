@@ -0,0 +1,334 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/compare"
+	"yunion.io/x/sqlchemy"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+type SNatSEntryManager struct {
+	db.SVirtualResourceBaseManager
+}
+
+var NatSEntryManager *SNatSEntryManager
+
+func init() {
+	NatSEntryManager = &SNatSEntryManager{
+		SVirtualResourceBaseManager: db.NewVirtualResourceBaseManager(
+			SNatSEntry{},
+			"natsentries_tbl",
+			"natsentry",
+			"natsentries",
+		),
+	}
+}
+
+type SNatSEntry struct {
+	db.SVirtualResourceBase
+	SManagedResourceBase
+
+	NatgatewayId string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"required"`
+	Ip           string `width:"17" charset:"ascii" list:"user" create:"required"` // SNAT公网IP
+	SourceCidr   string `width:"22" charset:"ascii" list:"user" create:"optional"`
+	NetworkId    string `width:"36" charset:"ascii" list:"user" create:"optional"`
+}
+
+func (man *SNatSEntryManager) ListItemFilter(ctx context.Context, q *sqlchemy.SQuery, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*sqlchemy.SQuery, error) {
+	var err error
+	q, err = managedResourceFilterByAccount(q, query, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	q = managedResourceFilterByCloudType(q, query, "", nil)
+
+	q, err = man.SVirtualResourceBaseManager.ListItemFilter(ctx, q, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	userProjId := userCred.GetProjectId()
+	data := query.(*jsonutils.JSONDict)
+	q, err = validators.ApplyModelFilters(q, data, []*validators.ModelFilterOptions{
+		{Key: "natgateway", ModelKeyword: "natgateway", ProjectId: userProjId},
+		{Key: "network", ModelKeyword: "network", ProjectId: userProjId},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (man *SNatSEntryManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	natgatewayV := validators.NewModelIdOrNameValidator("natgateway", "natgateway", ownerProjId)
+	eipV := validators.NewModelIdOrNameValidator("eip", "eip", ownerProjId)
+	networkV := validators.NewModelIdOrNameValidator("network", "network", ownerProjId)
+	keyV := map[string]validators.IValidator{
+		"natgateway": natgatewayV,
+		"eip":        eipV,
+		"network":    networkV.Optional(true),
+	}
+	for _, v := range keyV {
+		if err := v.Validate(data); err != nil {
+			return nil, err
+		}
+	}
+	natgateway := natgatewayV.Model.(*SNatGateway)
+	eip := eipV.Model.(*SElasticip)
+	if eip.CloudregionId != natgateway.CloudregionId || eip.ManagerId != natgateway.ManagerId {
+		return nil, httperrors.NewInputParameterError("eip %s and natgateway %s are not in the same region", eip.Name, natgateway.Name)
+	}
+	if eip.IsAssociated() {
+		return nil, httperrors.NewConflictError("eip %s has been associated with instance", eip.Name)
+	}
+	if networkV.Model != nil {
+		network := networkV.Model.(*SNetwork)
+		vpc := network.GetVpc()
+		if vpc == nil || vpc.Id != natgateway.VpcId {
+			return nil, httperrors.NewInputParameterError("network %s not in vpc of natgateway %s", network.Name, natgateway.Name)
+		}
+	} else {
+		sourceCidr, _ := data.GetString("source_cidr")
+		if len(sourceCidr) == 0 {
+			return nil, httperrors.NewMissingParameterError("network or source_cidr")
+		}
+		_, ipNet, err := net.ParseCIDR(sourceCidr)
+		if err != nil {
+			return nil, httperrors.NewInputParameterError("invalid source_cidr %s", sourceCidr)
+		}
+		data.Set("source_cidr", jsonutils.NewString(ipNet.String()))
+	}
+	data.Set("ip", jsonutils.NewString(eip.IpAddr))
+	data.Set("manager_id", jsonutils.NewString(natgateway.ManagerId))
+	return man.SVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+}
+
+func (self *SNatSEntry) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	self.SVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
+
+	self.SetStatus(userCred, api.NAT_STATUS_ALLOCATE, "")
+	params := jsonutils.NewDict()
+	if eipId, _ := data.GetString("eip_id"); len(eipId) > 0 {
+		params.Set("eip_id", jsonutils.NewString(eipId))
+	}
+	if err := self.StartNatSEntryCreateTask(ctx, userCred, params, ""); err != nil {
+		log.Errorf("Failed to create snat entry error: %v", err)
+	}
+}
+
+func (self *SNatSEntry) StartNatSEntryCreateTask(ctx context.Context, userCred mcclient.TokenCredential, params *jsonutils.JSONDict, parentTaskId string) error {
+	task, err := taskman.TaskManager.NewTask(ctx, "NatSEntryCreateTask", self, userCred, params, parentTaskId, "", nil)
+	if err != nil {
+		return err
+	}
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (self *SNatSEntry) GetNatgateway() (*SNatGateway, error) {
+	natgateway, err := NatGatewayManager.FetchById(self.NatgatewayId)
+	if err != nil {
+		return nil, err
+	}
+	return natgateway.(*SNatGateway), nil
+}
+
+func (self *SNatSEntry) GetINatSEntry() (cloudprovider.ICloudNatSEntry, error) {
+	natgateway, err := self.GetNatgateway()
+	if err != nil {
+		return nil, err
+	}
+	inatgateway, err := natgateway.GetINatGateway()
+	if err != nil {
+		return nil, err
+	}
+	return inatgateway.GetINatSEntryById(self.ExternalId)
+}
+
+func (self *SNatSEntry) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSONDict {
+	if natgateway, err := self.GetNatgateway(); err == nil {
+		extra.Set("natgateway", jsonutils.NewString(natgateway.Name))
+	}
+	if len(self.NetworkId) > 0 {
+		if network, err := NetworkManager.FetchById(self.NetworkId); err == nil {
+			extra.Set("network", jsonutils.NewString(network.GetName()))
+		}
+	}
+	return extra
+}
+
+func (self *SNatSEntry) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
+	extra := self.SVirtualResourceBase.GetCustomizeColumns(ctx, userCred, query)
+	return self.getMoreDetails(extra)
+}
+
+func (self *SNatSEntry) GetExtraDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	extra, err := self.SVirtualResourceBase.GetExtraDetails(ctx, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	return self.getMoreDetails(extra), nil
+}
+
+func (self *SNatSEntry) AllowPerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return db.IsAdminAllowPerform(userCred, self, "purge")
+}
+
+func (self *SNatSEntry) PerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	provider := self.GetCloudprovider()
+	if provider != nil {
+		if provider.Enabled {
+			return nil, httperrors.NewInvalidStatusError("Cannot purge snat entry on enabled cloud provider")
+		}
+	}
+	err := self.RealDelete(ctx, userCred)
+	return nil, err
+}
+
+func (self *SNatSEntry) Delete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return nil
+}
+
+func (self *SNatSEntry) RealDelete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return self.SVirtualResourceBase.Delete(ctx, userCred)
+}
+
+func (self *SNatSEntry) CustomizeDelete(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) error {
+	self.SetStatus(userCred, api.NAT_STATUS_DELETING, "")
+	return self.StartNatSEntryDeleteTask(ctx, userCred, "")
+}
+
+func (self *SNatSEntry) StartNatSEntryDeleteTask(ctx context.Context, userCred mcclient.TokenCredential, parentTaskId string) error {
+	task, err := taskman.TaskManager.NewTask(ctx, "NatSEntryDeleteTask", self, userCred, nil, parentTaskId, "", nil)
+	if err != nil {
+		return err
+	}
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (man *SNatSEntryManager) SyncNatSEntries(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, natgateway *SNatGateway, extEntries []cloudprovider.ICloudNatSEntry) compare.SyncResult {
+	lockman.LockClass(ctx, man, man.GetOwnerId(userCred))
+	defer lockman.ReleaseClass(ctx, man, man.GetOwnerId(userCred))
+
+	syncResult := compare.SyncResult{}
+
+	dbEntries, err := natgateway.GetNatSEntries()
+	if err != nil {
+		syncResult.Error(err)
+		return syncResult
+	}
+
+	removed := make([]SNatSEntry, 0)
+	commondb := make([]SNatSEntry, 0)
+	commonext := make([]cloudprovider.ICloudNatSEntry, 0)
+	added := make([]cloudprovider.ICloudNatSEntry, 0)
+	if err := compare.CompareSets(dbEntries, extEntries, &removed, &commondb, &commonext, &added); err != nil {
+		syncResult.Error(err)
+		return syncResult
+	}
+
+	for i := 0; i < len(removed); i += 1 {
+		err := removed[i].syncRemoveCloudNatSEntry(ctx, userCred)
+		if err != nil {
+			syncResult.DeleteError(err)
+		} else {
+			syncResult.Delete()
+		}
+	}
+
+	for i := 0; i < len(commondb); i += 1 {
+		err := commondb[i].SyncWithCloudNatSEntry(ctx, userCred, commonext[i])
+		if err != nil {
+			syncResult.UpdateError(err)
+			continue
+		}
+		syncResult.Update()
+	}
+
+	for i := 0; i < len(added); i += 1 {
+		_, err := man.newFromCloudNatSEntry(ctx, userCred, natgateway, added[i])
+		if err != nil {
+			syncResult.AddError(err)
+			continue
+		}
+		syncResult.Add()
+	}
+	return syncResult
+}
+
+func (self *SNatSEntry) syncRemoveCloudNatSEntry(ctx context.Context, userCred mcclient.TokenCredential) error {
+	lockman.LockObject(ctx, self)
+	defer lockman.ReleaseObject(ctx, self)
+
+	err := self.ValidateDeleteCondition(ctx)
+	if err != nil {
+		self.SetStatus(userCred, api.NAT_STATUS_UNKNOWN, "sync to delete")
+		return err
+	}
+	return self.RealDelete(ctx, userCred)
+}
+
+// 云上SNAT条目关联的是子网外部ID, 需要转换为本地网络ID
+func natSEntryNetworkId(extEntry cloudprovider.ICloudNatSEntry) string {
+	extNetworkId := extEntry.GetNetworkId()
+	if len(extNetworkId) == 0 {
+		return ""
+	}
+	network, err := NetworkManager.FetchByExternalId(extNetworkId)
+	if err != nil {
+		return ""
+	}
+	return network.GetId()
+}
+
+func (self *SNatSEntry) SyncWithCloudNatSEntry(ctx context.Context, userCred mcclient.TokenCredential, extEntry cloudprovider.ICloudNatSEntry) error {
+	diff, err := db.UpdateWithLock(ctx, self, func() error {
+		self.Status = extEntry.GetStatus()
+		self.Ip = extEntry.GetIP()
+		self.SourceCidr = extEntry.GetSourceCIDR()
+		self.NetworkId = natSEntryNetworkId(extEntry)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	db.OpsLog.LogSyncUpdate(self, diff, userCred)
+	return nil
+}
+
+func (man *SNatSEntryManager) newFromCloudNatSEntry(ctx context.Context, userCred mcclient.TokenCredential, natgateway *SNatGateway, extEntry cloudprovider.ICloudNatSEntry) (*SNatSEntry, error) {
+	entry := SNatSEntry{}
+	entry.SetModelManager(man)
+
+	entry.Name = db.GenerateName(man, natgateway.ProjectId, extEntry.GetName())
+	entry.Status = extEntry.GetStatus()
+	entry.ExternalId = extEntry.GetGlobalId()
+	entry.IsEmulated = extEntry.IsEmulated()
+	entry.ManagerId = natgateway.ManagerId
+	entry.ProjectId = natgateway.ProjectId
+	entry.NatgatewayId = natgateway.Id
+	entry.Ip = extEntry.GetIP()
+	entry.SourceCidr = extEntry.GetSourceCIDR()
+	entry.NetworkId = natSEntryNetworkId(extEntry)
+
+	err := man.TableSpec().Insert(&entry)
+	if err != nil {
+		return nil, fmt.Errorf("newFromCloudNatSEntry fail %s", err)
+	}
+
+	db.OpsLog.LogEvent(&entry, db.ACT_CREATE, entry.GetShortDesc(ctx), userCred)
+	return &entry, nil
+}
@@ -18,6 +18,7 @@ import (
 	api "yunion.io/x/onecloud/pkg/apis/compute"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/quotas"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
 	"yunion.io/x/onecloud/pkg/cloudprovider"
 	"yunion.io/x/onecloud/pkg/compute/options"
@@ -805,6 +806,35 @@ func (self *SNetwork) getVpc() *SVpc {
 	return nil
 }
 
+func (self *SNetwork) getQuotaScopes() []quotas.SQuotaScope {
+	wire := self.GetWire()
+	if wire == nil {
+		return nil
+	}
+	regionId := ""
+	if vpc := wire.getVpc(); vpc != nil {
+		regionId = vpc.CloudregionId
+	}
+	return getQuotaScopes(regionId, wire.ZoneId)
+}
+
+func totalNetworkCount(projectId string, scope quotas.SQuotaScope) int {
+	q := NetworkManager.Query().Equals("tenant_id", projectId)
+	if len(scope.ZoneId) > 0 {
+		wires := WireManager.Query().SubQuery()
+		subq := wires.Query(wires.Field("id")).Equals("zone_id", scope.ZoneId).SubQuery()
+		q = q.Filter(sqlchemy.In(q.Field("wire_id"), subq))
+	} else if len(scope.RegionId) > 0 {
+		wires := WireManager.Query().SubQuery()
+		vpcs := VpcManager.Query().SubQuery()
+		subq := wires.Query(wires.Field("id")).
+			Join(vpcs, sqlchemy.Equals(wires.Field("vpc_id"), vpcs.Field("id"))).
+			Filter(sqlchemy.Equals(vpcs.Field("cloudregion_id"), scope.RegionId)).SubQuery()
+		q = q.Filter(sqlchemy.In(q.Field("wire_id"), subq))
+	}
+	return q.Count()
+}
+
 func (self *SNetwork) getRegion() *SCloudregion {
 	wire := self.GetWire()
 	if wire != nil {
@@ -1060,9 +1090,9 @@ func (manager *SNetworkManager) ValidateCreateData(ctx context.Context, userCred
 					return nil, httperrors.NewInternalServerError("zone %s related region not found", zone.Id)
 				}
 
-				// 华为云wire zone_id 为空
+				// 华为云和谷歌云wire zone_id 为空
 				var wires []SWire
-				if region.Provider == CLOUD_PROVIDER_HUAWEI {
+				if utils.IsInStringArray(region.Provider, []string{CLOUD_PROVIDER_HUAWEI, CLOUD_PROVIDER_GOOGLE}) {
 					wires, err = WireManager.getWiresByVpcAndZone(vpc, nil)
 				} else {
 					wires, err = WireManager.getWiresByVpcAndZone(vpc, zone)
@@ -1123,7 +1153,15 @@ func (manager *SNetworkManager) ValidateCreateData(ctx context.Context, userCred
 	}
 	data.Add(jsonutils.NewString(serverTypeStr), "server_type")
 
-	return manager.SSharableVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+	data, err = manager.SSharableVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+	if err != nil {
+		return nil, err
+	}
+	pendingUsage := SQuota{Network: 1}
+	if err := QuotaManager.CheckSetPendingScopedQuota(ctx, userCred, ownerProjId, getQuotaScopes(vpc.CloudregionId, wire.ZoneId), &pendingUsage); err != nil {
+		return nil, httperrors.NewOutOfQuotaError("%s", err)
+	}
+	return data, nil
 }
 
 func (self *SNetwork) ValidateUpdateData(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
@@ -1254,6 +1292,8 @@ func (self *SNetwork) CustomizeCreate(ctx context.Context, userCred mcclient.Tok
 
 func (self *SNetwork) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
 	self.SSharableVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
+	pendingUsage := SQuota{Network: 1}
+	QuotaManager.CancelPendingScopedUsage(ctx, userCred, ownerProjId, self.getQuotaScopes(), &pendingUsage, &pendingUsage)
 	wire := self.GetWire()
 	if wire == nil {
 		log.Errorf("cannot find wire???")
@@ -1273,6 +1313,13 @@ func (self *SNetwork) PostCreate(ctx context.Context, userCred mcclient.TokenCre
 	}
 }
 
+func (manager *SNetworkManager) OnCreateFailed(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	network := &SNetwork{}
+	data.Unmarshal(network)
+	pendingUsage := SQuota{Network: 1}
+	QuotaManager.CancelPendingScopedUsage(ctx, userCred, ownerProjId, network.getQuotaScopes(), &pendingUsage, &pendingUsage)
+}
+
 func (self *SNetwork) GetPrefix() (netutils.IPV4Prefix, error) {
 	addr, err := netutils.NewIPV4Addr(self.GuestIpStart)
 	if err != nil {
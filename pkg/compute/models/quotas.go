@@ -8,6 +8,7 @@ import (
 	"yunion.io/x/jsonutils"
 	"yunion.io/x/pkg/tristate"
 
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/quotas"
 	"yunion.io/x/onecloud/pkg/compute/options"
 )
@@ -22,37 +23,48 @@ func init() {
 }
 
 var (
-	ErrOutOfCPU     = errors.New("out of CPU quota")
-	ErrOutOfMemory  = errors.New("out of memory quota")
-	ErrOutOfStorage = errors.New("out of storage quota")
-	ErrOutOfPort    = errors.New("out of internal port quota")
-	ErrOutOfEip     = errors.New("out of eip quota")
-	ErrOutOfEport   = errors.New("out of external port quota")
-	ErrOutOfBw      = errors.New("out of internal bandwidth quota")
-	ErrOutOfEbw     = errors.New("out of external bandwidth quota")
-	ErrOutOfKeypair = errors.New("out of keypair quota")
-	// ErrOutOfImage          = errors.New("out of image quota")
+	ErrOutOfCPU            = errors.New("out of CPU quota")
+	ErrOutOfMemory         = errors.New("out of memory quota")
+	ErrOutOfStorage        = errors.New("out of storage quota")
+	ErrOutOfPort           = errors.New("out of internal port quota")
+	ErrOutOfEip            = errors.New("out of eip quota")
+	ErrOutOfEport          = errors.New("out of external port quota")
+	ErrOutOfBw             = errors.New("out of internal bandwidth quota")
+	ErrOutOfEbw            = errors.New("out of external bandwidth quota")
+	ErrOutOfKeypair        = errors.New("out of keypair quota")
+	ErrOutOfImage          = errors.New("out of image quota")
 	ErrOutOfGroup          = errors.New("out of group quota")
 	ErrOutOfSecgroup       = errors.New("out of secgroup quota")
 	ErrOutOfIsolatedDevice = errors.New("out of isolated device quota")
 	ErrOutOfSnapshot       = errors.New("out of snapshot quota")
+	ErrOutOfLoadbalancer   = errors.New("out of loadbalancer quota")
+	ErrOutOfListener       = errors.New("out of loadbalancer listener quota")
+	ErrOutOfNetwork        = errors.New("out of network quota")
+	ErrOutOfVpc            = errors.New("out of vpc quota")
 )
 
+// SQuota is the compute quota of a project. The global image quota is kept by
+// the image service, Image here only limits the images cached in a
+// cloudregion or a zone
 type SQuota struct {
-	Cpu     int
-	Memory  int
-	Storage int
-	Port    int
-	Eip     int
-	Eport   int
-	Bw      int
-	Ebw     int
-	Keypair int
-	// Image          int
+	Cpu            int
+	Memory         int
+	Storage        int
+	Port           int
+	Eip            int
+	Eport          int
+	Bw             int
+	Ebw            int
+	Keypair        int
+	Image          int
 	Group          int
 	Secgroup       int
 	IsolatedDevice int
 	Snapshot       int
+	Loadbalancer   int
+	Listener       int
+	Network        int
+	Vpc            int
 }
 
 func (self *SQuota) FetchSystemQuota() {
@@ -69,6 +81,19 @@ func (self *SQuota) FetchSystemQuota() {
 	self.Secgroup = options.Options.DefaultSecgroupQuota
 	self.IsolatedDevice = options.Options.DefaultIsolatedDeviceQuota
 	self.Snapshot = options.Options.DefaultSnapshotQuota
+	self.Loadbalancer = options.Options.DefaultLoadbalancerQuota
+	self.Listener = options.Options.DefaultLoadbalancerListenerQuota
+	self.Network = options.Options.DefaultNetworkQuota
+	self.Vpc = options.Options.DefaultVpcQuota
+}
+
+// FetchUnsetDefaults fills the dimensions added after the quotas of some
+// projects were set, the stored quota is loaded over them afterwards
+func (self *SQuota) FetchUnsetDefaults() {
+	self.Loadbalancer = options.Options.DefaultLoadbalancerQuota
+	self.Listener = options.Options.DefaultLoadbalancerListenerQuota
+	self.Network = options.Options.DefaultNetworkQuota
+	self.Vpc = options.Options.DefaultVpcQuota
 }
 
 func (self *SQuota) FetchUsage(ctx context.Context, projectId string) error {
@@ -94,9 +119,55 @@ func (self *SQuota) FetchUsage(ctx context.Context, projectId string) error {
 	self.Secgroup = totalSecurityGroupCount(projectId)
 	self.IsolatedDevice = guest.TotalIsolatedCount
 	self.Snapshot = snapshotCount
+	self.Loadbalancer = totalLoadbalancerCount(projectId, quotas.SQuotaScope{})
+	self.Listener = totalLoadbalancerListenerCount(projectId, quotas.SQuotaScope{})
+	self.Network = totalNetworkCount(projectId, quotas.SQuotaScope{})
+	self.Vpc = totalVpcCount(projectId, quotas.SQuotaScope{})
 	return nil
 }
 
+// FetchScopedUsage fills the usage of the project in a cloudregion or a zone.
+// Only eip, image, loadbalancer, listener, network and vpc are limited per
+// scope, the other dimensions are left zero and bound by the global quota only
+func (self *SQuota) FetchScopedUsage(ctx context.Context, projectId string, scope quotas.SQuotaScope) error {
+	var rangeObj db.IStandaloneModel
+	if len(scope.ZoneId) > 0 {
+		zone := ZoneManager.FetchZoneById(scope.ZoneId)
+		if zone == nil {
+			return fmt.Errorf("zone %s not found", scope.ZoneId)
+		}
+		rangeObj = zone
+	} else if len(scope.RegionId) > 0 {
+		region := CloudregionManager.FetchRegionById(scope.RegionId)
+		if region == nil {
+			return fmt.Errorf("cloudregion %s not found", scope.RegionId)
+		}
+		rangeObj = region
+	}
+	eipUsage := ElasticipManager.TotalCount(projectId, rangeObj, nil)
+
+	self.Eip = eipUsage.Total()
+	self.Loadbalancer = totalLoadbalancerCount(projectId, scope)
+	self.Listener = totalLoadbalancerListenerCount(projectId, scope)
+	self.Network = totalNetworkCount(projectId, scope)
+	self.Vpc = totalVpcCount(projectId, scope)
+	self.Image = totalCachedImageCount(projectId, scope)
+	return nil
+}
+
+// getQuotaScopes returns the quota scopes a resource in the zone and region
+// counts against
+func getQuotaScopes(regionId, zoneId string) []quotas.SQuotaScope {
+	scopes := make([]quotas.SQuotaScope, 0, 2)
+	if len(regionId) > 0 {
+		scopes = append(scopes, quotas.SQuotaScope{RegionId: regionId})
+	}
+	if len(zoneId) > 0 {
+		scopes = append(scopes, quotas.SQuotaScope{ZoneId: zoneId})
+	}
+	return scopes
+}
+
 func (self *SQuota) IsEmpty() bool {
 	if self.Cpu > 0 {
 		return false
@@ -125,6 +196,9 @@ func (self *SQuota) IsEmpty() bool {
 	if self.Keypair > 0 {
 		return false
 	}
+	if self.Image > 0 {
+		return false
+	}
 	if self.Group > 0 {
 		return false
 	}
@@ -137,6 +211,18 @@ func (self *SQuota) IsEmpty() bool {
 	if self.Snapshot > 0 {
 		return false
 	}
+	if self.Loadbalancer > 0 {
+		return false
+	}
+	if self.Listener > 0 {
+		return false
+	}
+	if self.Network > 0 {
+		return false
+	}
+	if self.Vpc > 0 {
+		return false
+	}
 	return true
 }
 
@@ -151,10 +237,15 @@ func (self *SQuota) Add(quota quotas.IQuota) {
 	self.Bw = self.Bw + squota.Bw
 	self.Ebw = self.Ebw + squota.Ebw
 	self.Keypair = self.Keypair + squota.Keypair
+	self.Image = self.Image + squota.Image
 	self.Group = self.Group + squota.Group
 	self.Secgroup = self.Secgroup + squota.Secgroup
 	self.IsolatedDevice = self.IsolatedDevice + squota.IsolatedDevice
 	self.Snapshot = self.Snapshot + squota.Snapshot
+	self.Loadbalancer = self.Loadbalancer + squota.Loadbalancer
+	self.Listener = self.Listener + squota.Listener
+	self.Network = self.Network + squota.Network
+	self.Vpc = self.Vpc + squota.Vpc
 }
 
 func nonNegative(val int) int {
@@ -172,10 +263,15 @@ func (self *SQuota) Sub(quota quotas.IQuota) {
 	self.Bw = nonNegative(self.Bw - squota.Bw)
 	self.Ebw = nonNegative(self.Ebw - squota.Ebw)
 	self.Keypair = nonNegative(self.Keypair - squota.Keypair)
+	self.Image = nonNegative(self.Image - squota.Image)
 	self.Group = nonNegative(self.Group - squota.Group)
 	self.Secgroup = nonNegative(self.Secgroup - squota.Secgroup)
 	self.IsolatedDevice = nonNegative(self.IsolatedDevice - squota.IsolatedDevice)
 	self.Snapshot = nonNegative(self.Snapshot - squota.Snapshot)
+	self.Loadbalancer = nonNegative(self.Loadbalancer - squota.Loadbalancer)
+	self.Listener = nonNegative(self.Listener - squota.Listener)
+	self.Network = nonNegative(self.Network - squota.Network)
+	self.Vpc = nonNegative(self.Vpc - squota.Vpc)
 }
 
 func (self *SQuota) Update(quota quotas.IQuota) {
@@ -207,6 +303,9 @@ func (self *SQuota) Update(quota quotas.IQuota) {
 	if squota.Keypair > 0 {
 		self.Keypair = squota.Keypair
 	}
+	if squota.Image > 0 {
+		self.Image = squota.Image
+	}
 	if squota.Group > 0 {
 		self.Group = squota.Group
 	}
@@ -219,6 +318,18 @@ func (self *SQuota) Update(quota quotas.IQuota) {
 	if squota.Snapshot > 0 {
 		self.Snapshot = squota.Snapshot
 	}
+	if squota.Loadbalancer > 0 {
+		self.Loadbalancer = squota.Loadbalancer
+	}
+	if squota.Listener > 0 {
+		self.Listener = squota.Listener
+	}
+	if squota.Network > 0 {
+		self.Network = squota.Network
+	}
+	if squota.Vpc > 0 {
+		self.Vpc = squota.Vpc
+	}
 }
 
 func (self *SQuota) Exceed(request quotas.IQuota, quota quotas.IQuota) error {
@@ -251,6 +362,10 @@ func (self *SQuota) Exceed(request quotas.IQuota, quota quotas.IQuota) error {
 	if sreq.Keypair > 0 && self.Keypair > squota.Keypair {
 		return ErrOutOfKeypair
 	}
+	// the global image quota is limited by the image service, 0 is unlimited here
+	if sreq.Image > 0 && squota.Image > 0 && self.Image > squota.Image {
+		return ErrOutOfImage
+	}
 	if sreq.Group > 0 && self.Group > squota.Group {
 		return ErrOutOfGroup
 	}
@@ -263,6 +378,18 @@ func (self *SQuota) Exceed(request quotas.IQuota, quota quotas.IQuota) error {
 	if sreq.Snapshot > 0 && self.Snapshot > squota.Snapshot {
 		return ErrOutOfSnapshot
 	}
+	if sreq.Loadbalancer > 0 && self.Loadbalancer > squota.Loadbalancer {
+		return ErrOutOfLoadbalancer
+	}
+	if sreq.Listener > 0 && self.Listener > squota.Listener {
+		return ErrOutOfListener
+	}
+	if sreq.Network > 0 && self.Network > squota.Network {
+		return ErrOutOfNetwork
+	}
+	if sreq.Vpc > 0 && self.Vpc > squota.Vpc {
+		return ErrOutOfVpc
+	}
 	return nil
 }
 
@@ -303,6 +430,9 @@ func (self *SQuota) ToJSON(prefix string) jsonutils.JSONObject {
 	if self.Keypair > 0 {
 		ret.Add(jsonutils.NewInt(int64(self.Keypair)), keyName(prefix, "keypair"))
 	}
+	if self.Image > 0 {
+		ret.Add(jsonutils.NewInt(int64(self.Image)), keyName(prefix, "image"))
+	}
 	if self.Group > 0 {
 		ret.Add(jsonutils.NewInt(int64(self.Group)), keyName(prefix, "group"))
 	}
@@ -315,5 +445,17 @@ func (self *SQuota) ToJSON(prefix string) jsonutils.JSONObject {
 	if self.Snapshot > 0 {
 		ret.Add(jsonutils.NewInt(int64(self.Snapshot)), keyName(prefix, "snapshot"))
 	}
+	if self.Loadbalancer > 0 {
+		ret.Add(jsonutils.NewInt(int64(self.Loadbalancer)), keyName(prefix, "loadbalancer"))
+	}
+	if self.Listener > 0 {
+		ret.Add(jsonutils.NewInt(int64(self.Listener)), keyName(prefix, "listener"))
+	}
+	if self.Network > 0 {
+		ret.Add(jsonutils.NewInt(int64(self.Network)), keyName(prefix, "network"))
+	}
+	if self.Vpc > 0 {
+		ret.Add(jsonutils.NewInt(int64(self.Vpc)), keyName(prefix, "vpc"))
+	}
 	return ret
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"database/sql"
 	"net"
 	"reflect"
 	"strings"
@@ -12,8 +13,10 @@ import (
 	"yunion.io/x/pkg/util/compare"
 	"yunion.io/x/sqlchemy"
 
+	api "yunion.io/x/onecloud/pkg/apis/compute"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
 	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
 	"yunion.io/x/onecloud/pkg/cloudprovider"
 	"yunion.io/x/onecloud/pkg/httperrors"
@@ -244,16 +247,17 @@ func (rt *SRouteTable) AllowPerformDelRoutes(ctx context.Context, userCred mccli
 // PerformAddRoutes patches acl entries by adding then deleting the specified acls.
 // This is intended mainly for command line operations.
 func (rt *SRouteTable) PerformAddRoutes(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
-	routes := gotypes.DeepCopy(rt.Routes).(SRoutes)
+	routes := rt.copyRoutes()
+	adds := SRoutes{}
 	{
-		adds := SRoutes{}
-		addsV := validators.NewStructValidator("routes", &adds)
+		inputs := SRoutes{}
+		addsV := validators.NewStructValidator("routes", &inputs)
 		addsV.Optional(true)
 		err := addsV.Validate(data)
 		if err != nil {
 			return nil, err
 		}
-		for _, add := range adds {
+		for _, add := range inputs {
 			found := false
 			for _, route := range routes {
 				if route.Cidr == add.Cidr {
@@ -263,8 +267,22 @@ func (rt *SRouteTable) PerformAddRoutes(ctx context.Context, userCred mcclient.T
 			}
 			if !found {
 				routes = append(routes, add)
+				adds = append(adds, add)
+			}
+		}
+	}
+	if rt.IsManaged() {
+		if len(adds) == 0 {
+			return nil, nil
+		}
+		for _, add := range adds {
+			if err := rt.resolveRouteNextHop(userCred, add); err != nil {
+				return nil, err
 			}
 		}
+		params := jsonutils.NewDict()
+		params.Set("routes", jsonutils.Marshal(adds))
+		return nil, rt.StartRouteTableUpdateTask(ctx, userCred, "RouteTableAddRoutesTask", params, "")
 	}
 	_, err := db.Update(rt, func() error {
 		rt.Routes = &routes
@@ -277,7 +295,8 @@ func (rt *SRouteTable) PerformAddRoutes(ctx context.Context, userCred mcclient.T
 }
 
 func (rt *SRouteTable) PerformDelRoutes(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
-	routes := gotypes.DeepCopy(rt.Routes).(SRoutes)
+	routes := rt.copyRoutes()
+	dels := SRoutes{}
 	{
 		cidrs := []string{}
 		err := data.Unmarshal(&cidrs, "cidrs")
@@ -292,11 +311,20 @@ func (rt *SRouteTable) PerformDelRoutes(ctx context.Context, userCred mcclient.T
 				}
 				if route.Cidr == cidr {
 					routes = append(routes[:i], routes[i+1:]...)
+					dels = append(dels, route)
 					break
 				}
 			}
 		}
 	}
+	if rt.IsManaged() {
+		if len(dels) == 0 {
+			return nil, nil
+		}
+		params := jsonutils.NewDict()
+		params.Set("routes", jsonutils.Marshal(dels))
+		return nil, rt.StartRouteTableUpdateTask(ctx, userCred, "RouteTableDelRoutesTask", params, "")
+	}
 	_, err := db.Update(rt, func() error {
 		rt.Routes = &routes
 		return nil
@@ -307,6 +335,84 @@ func (rt *SRouteTable) PerformDelRoutes(ctx context.Context, userCred mcclient.T
 	return nil, nil
 }
 
+func (rt *SRouteTable) copyRoutes() SRoutes {
+	if rt.Routes == nil {
+		return SRoutes{}
+	}
+	return *gotypes.DeepCopy(rt.Routes).(*SRoutes)
+}
+
+// 云上路由的下一跳需使用外部ID, 若指定的是本地资源则进行转换
+func (rt *SRouteTable) resolveRouteNextHop(userCred mcclient.TokenCredential, route *SRoute) error {
+	if len(route.NextHopType) == 0 || len(route.NextHopId) == 0 {
+		return httperrors.NewMissingParameterError("next_hop_type and next_hop_id")
+	}
+	if len(route.Type) == 0 {
+		route.Type = "custom"
+	}
+	switch route.NextHopType {
+	case api.ROUTE_NEXT_HOP_TYPE_VPC_PEERING:
+		peeringObj, err := VpcPeeringManager.FetchByIdOrName(userCred, route.NextHopId)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return httperrors.NewGeneralError(err)
+		}
+		peering := peeringObj.(*SVpcPeering)
+		if peering.VpcId != rt.VpcId && peering.PeerVpcId != rt.VpcId {
+			return httperrors.NewInputParameterError("vpc peering %s is not connected to vpc of route table %s", peering.Name, rt.Name)
+		}
+		route.NextHopId = peering.ExternalId
+	case api.ROUTE_NEXT_HOP_TYPE_INSTANCE:
+		guestObj, err := GuestManager.FetchByIdOrName(userCred, route.NextHopId)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return httperrors.NewGeneralError(err)
+		}
+		guest := guestObj.(*SGuest)
+		host := guest.GetHost()
+		if host == nil || host.ManagerId != rt.ManagerId {
+			return httperrors.NewInputParameterError("server %s and route table %s are not in the same cloud provider", guest.Name, rt.Name)
+		}
+		route.NextHopId = guest.ExternalId
+	}
+	return nil
+}
+
+func (rt *SRouteTable) StartRouteTableUpdateTask(ctx context.Context, userCred mcclient.TokenCredential, taskName string, params *jsonutils.JSONDict, parentTaskId string) error {
+	rt.SetStatus(userCred, api.ROUTE_TABLE_STATUS_UPDATING, "")
+	task, err := taskman.TaskManager.NewTask(ctx, taskName, rt, userCred, params, parentTaskId, "", nil)
+	if err != nil {
+		return err
+	}
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (rt *SRouteTable) GetIRouteTable() (cloudprovider.ICloudRouteTable, error) {
+	vpc, err := rt.getVpc()
+	if err != nil {
+		return nil, err
+	}
+	ivpc, err := vpc.GetIVpc()
+	if err != nil {
+		return nil, err
+	}
+	routeTables, err := ivpc.GetIRouteTables()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(routeTables); i++ {
+		if routeTables[i].GetGlobalId() == rt.ExternalId {
+			return routeTables[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
 func (rt *SRouteTable) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSONDict {
 	info := rt.getCloudProviderInfo()
 	extra.Update(jsonutils.Marshal(&info))
@@ -349,7 +455,7 @@ func (man *SRouteTableManager) SyncRouteTables(ctx context.Context, userCred mcc
 	syncResult := compare.SyncResult{}
 
 	dbRouteTables := []SRouteTable{}
-	if err := db.FetchModelObjects(man, man.Query(), &dbRouteTables); err != nil {
+	if err := db.FetchModelObjects(man, man.Query().Equals("vpc_id", vpc.Id), &dbRouteTables); err != nil {
 		syncResult.Error(err)
 		return nil, nil, syncResult
 	}
@@ -421,6 +527,7 @@ func (man *SRouteTableManager) newRouteTableFromCloud(userCred mcclient.TokenCre
 		Routes:        (*SRoutes)(&routes),
 	}
 	routeTable.Name = db.GenerateName(man, userCred.GetProjectId(), cloudRouteTable.GetName())
+	routeTable.Status = api.ROUTE_TABLE_STATUS_AVAILABLE
 	routeTable.ManagerId = vpc.ManagerId
 	routeTable.ExternalId = cloudRouteTable.GetGlobalId()
 	routeTable.Description = cloudRouteTable.GetDescription()
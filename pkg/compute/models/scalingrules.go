@@ -0,0 +1,204 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/sqlchemy"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+// names of influxdb measurements and fields, they are put into queries as is
+var scalingMetricNameReg = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+type SScalingRuleManager struct {
+	db.SVirtualResourceBaseManager
+}
+
+var ScalingRuleManager *SScalingRuleManager
+
+func init() {
+	ScalingRuleManager = &SScalingRuleManager{
+		SVirtualResourceBaseManager: db.NewVirtualResourceBaseManager(
+			SScalingRule{},
+			"scalingrules_tbl",
+			"scalingrule",
+			"scalingrules",
+		),
+	}
+}
+
+// SScalingRule moves the desired size of a scaling group by Step when the metric
+// Measurement.Field of the servers of the group, aggregated over the last Period
+// seconds, compares to Threshold by Operator
+type SScalingRule struct {
+	db.SVirtualResourceBase
+
+	GroupId string `width:"36" charset:"ascii" nullable:"false" index:"true" list:"user" create:"required"`
+
+	// influxdb measurement and field of the server metrics, e.g. vm_cpu and usage_active
+	Measurement string `width:"64" charset:"ascii" nullable:"false" list:"user" create:"required" update:"user"`
+	Field       string `width:"64" charset:"ascii" nullable:"false" list:"user" create:"required" update:"user"`
+	Aggregate   string `width:"16" charset:"ascii" nullable:"false" default:"mean" list:"user" create:"optional" update:"user"`
+	Period      int    `nullable:"false" default:"300" list:"user" create:"optional" update:"user"`
+
+	Operator  string  `width:"8" charset:"ascii" nullable:"false" list:"user" create:"required" update:"user"`
+	Threshold float64 `nullable:"false" list:"user" create:"required" update:"user"`
+
+	Action string `width:"16" charset:"ascii" nullable:"false" list:"user" create:"required" update:"user"`
+	Step   int    `nullable:"false" default:"1" list:"user" create:"optional" update:"user"`
+
+	Enabled bool `nullable:"false" default:"true" list:"user" create:"optional" update:"user"`
+}
+
+func validateScalingRuleData(data *jsonutils.JSONDict, create bool) error {
+	keyV := map[string]validators.IValidator{
+		"measurement": validators.NewRegexpValidator("measurement", scalingMetricNameReg),
+		"field":       validators.NewRegexpValidator("field", scalingMetricNameReg),
+		"aggregate":   validators.NewStringChoicesValidator("aggregate", api.SCALING_AGGREGATES),
+		"period":      validators.NewRangeValidator("period", 60, 86400),
+		"operator":    validators.NewStringChoicesValidator("operator", api.SCALING_OPERATORS),
+		"action":      validators.NewStringChoicesValidator("action", api.SCALING_ACTIONS),
+		"step":        validators.NewRangeValidator("step", 1, 100),
+	}
+	for key, v := range keyV {
+		if !create || key == "aggregate" || key == "period" || key == "step" {
+			v.Optional(true)
+		}
+		if err := v.Validate(data); err != nil {
+			return err
+		}
+	}
+	if data.Contains("threshold") || create {
+		threshold, err := data.Float("threshold")
+		if err != nil {
+			return httperrors.NewInputParameterError("invalid threshold")
+		}
+		data.Set("threshold", jsonutils.NewFloat(threshold))
+	}
+	return nil
+}
+
+func (manager *SScalingRuleManager) AllowListItems(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
+	return true
+}
+
+func (manager *SScalingRuleManager) AllowCreateItem(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return true
+}
+
+func (self *SScalingRule) AllowGetDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowGet(userCred, self)
+}
+
+func (self *SScalingRule) AllowUpdateItem(ctx context.Context, userCred mcclient.TokenCredential) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowUpdate(userCred, self)
+}
+
+func (self *SScalingRule) AllowDeleteItem(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowDelete(userCred, self)
+}
+
+func (manager *SScalingRuleManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	groupV := validators.NewModelIdOrNameValidator("group", "group", ownerProjId)
+	err := groupV.Validate(data)
+	if err != nil {
+		return nil, err
+	}
+	err = validateScalingRuleData(data, true)
+	if err != nil {
+		return nil, err
+	}
+	return manager.SVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+}
+
+func (self *SScalingRule) ValidateUpdateData(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	err := validateScalingRuleData(data, false)
+	if err != nil {
+		return nil, err
+	}
+	return self.SVirtualResourceBase.ValidateUpdateData(ctx, userCred, query, data)
+}
+
+func (self *SScalingRule) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	self.SVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
+	self.SetStatus(userCred, api.SCALING_RULE_READY, "")
+}
+
+func (manager *SScalingRuleManager) ListItemFilter(ctx context.Context, q *sqlchemy.SQuery, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*sqlchemy.SQuery, error) {
+	q, err := manager.SVirtualResourceBaseManager.ListItemFilter(ctx, q, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	if groupStr := jsonutils.GetAnyString(query, []string{"group", "group_id"}); len(groupStr) > 0 {
+		group, err := GroupManager.FetchByIdOrName(userCred, groupStr)
+		if err != nil {
+			return nil, httperrors.NewResourceNotFoundError2(GroupManager.Keyword(), groupStr)
+		}
+		q = q.Equals("group_id", group.GetId())
+	}
+	return q, nil
+}
+
+func (self *SScalingRule) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
+	extra := self.SVirtualResourceBase.GetCustomizeColumns(ctx, userCred, query)
+	return self.getMoreDetails(extra)
+}
+
+func (self *SScalingRule) GetExtraDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	extra, err := self.SVirtualResourceBase.GetExtraDetails(ctx, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	return self.getMoreDetails(extra), nil
+}
+
+func (self *SScalingRule) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSONDict {
+	if group, _ := GroupManager.FetchById(self.GroupId); group != nil {
+		extra.Add(jsonutils.NewString(group.GetName()), "group")
+	}
+	return extra
+}
+
+// matches reports whether the aggregated metric value triggers the rule
+func (self *SScalingRule) matches(value float64) bool {
+	switch self.Operator {
+	case api.SCALING_OPERATOR_GT:
+		return value > self.Threshold
+	case api.SCALING_OPERATOR_GE:
+		return value >= self.Threshold
+	case api.SCALING_OPERATOR_LT:
+		return value < self.Threshold
+	case api.SCALING_OPERATOR_LE:
+		return value <= self.Threshold
+	}
+	return false
+}
+
+// metricQuery returns the influxdb query aggregating the metric of the rule over the given servers
+func (self *SScalingRule) metricQuery(guestIds []string) string {
+	conds := make([]string, len(guestIds))
+	for i := range guestIds {
+		conds[i] = fmt.Sprintf(`"vm_id" = '%s'`, guestIds[i])
+	}
+	return fmt.Sprintf(`SELECT %s("%s") FROM "%s" WHERE time > now() - %ds AND (%s)`,
+		self.Aggregate, self.Field, self.Measurement, self.Period, strings.Join(conds, " OR "))
+}
+
+func (group *SGroup) GetScalingRules() ([]SScalingRule, error) {
+	rules := make([]SScalingRule, 0)
+	q := ScalingRuleManager.Query().Equals("group_id", group.Id)
+	err := db.FetchModelObjects(ScalingRuleManager, q, &rules)
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
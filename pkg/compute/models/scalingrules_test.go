@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+)
+
+func TestScalingRuleMatches(t *testing.T) {
+	cases := []struct {
+		operator string
+		value    float64
+		want     bool
+	}{
+		{api.SCALING_OPERATOR_GT, 80, false},
+		{api.SCALING_OPERATOR_GT, 80.1, true},
+		{api.SCALING_OPERATOR_GE, 80, true},
+		{api.SCALING_OPERATOR_LT, 80, false},
+		{api.SCALING_OPERATOR_LT, 10, true},
+		{api.SCALING_OPERATOR_LE, 80, true},
+		{"unknown", 80, false},
+	}
+	for _, c := range cases {
+		rule := SScalingRule{Operator: c.operator, Threshold: 80}
+		if got := rule.matches(c.value); got != c.want {
+			t.Errorf("%s %f 80: want %v, got %v", c.operator, c.value, c.want, got)
+		}
+	}
+}
+
+func TestScalingRuleMetricQuery(t *testing.T) {
+	rule := SScalingRule{
+		Measurement: "vm_cpu",
+		Field:       "usage_active",
+		Aggregate:   "mean",
+		Period:      300,
+	}
+	want := `SELECT mean("usage_active") FROM "vm_cpu" WHERE time > now() - 300s AND ("vm_id" = 'a' OR "vm_id" = 'b')`
+	if got := rule.metricQuery([]string{"a", "b"}); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,266 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	"yunion.io/x/onecloud/pkg/cloudcommon/cmdline"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+const (
+	SERVER_TEMPLATE_READY = "ready"
+)
+
+var (
+	// keys of a server create body that only make sense for a single launch
+	servertemplateExcludeKeys = []string{
+		"name", "generate_name", "count", "password",
+		"project", "project_id", "tenant", "tenant_id",
+		"template", "template_id", "template_version",
+	}
+
+	// keyword pairs of the array parameters of a server create body, each array
+	// can be given either as a whole, e.g. disks, or by index, e.g. disk.0
+	servertemplateArrayKeywords = [][2]string{
+		{"disk", "disks"},
+		{"net", "nets"},
+		{"schedtag", "schedtags"},
+		{"isolated_device", "isolated_devices"},
+		{"baremetal_disk_config", "baremetal_disk_configs"},
+	}
+)
+
+type SServertemplateManager struct {
+	db.SSharableVirtualResourceBaseManager
+}
+
+var ServertemplateManager *SServertemplateManager
+
+func init() {
+	ServertemplateManager = &SServertemplateManager{
+		SSharableVirtualResourceBaseManager: db.NewSharableVirtualResourceBaseManager(
+			SServertemplate{},
+			"servertemplates_tbl",
+			"servertemplate",
+			"servertemplates",
+		),
+	}
+}
+
+// SServertemplate keeps a validated server create body, servers can be created
+// from it by giving template_id along with the parameters to override
+type SServertemplate struct {
+	db.SSharableVirtualResourceBase
+
+	Content jsonutils.JSONObject `nullable:"false" list:"user" create:"required" update:"user"`
+	// bumped on every change of Content, the previous contents are kept as servertemplateversions
+	Version int `nullable:"false" default:"1" list:"user"`
+}
+
+func (manager *SServertemplateManager) AllowListItems(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
+	return true
+}
+
+func (manager *SServertemplateManager) AllowCreateItem(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return true
+}
+
+func (self *SServertemplate) AllowUpdateItem(ctx context.Context, userCred mcclient.TokenCredential) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowUpdate(userCred, self)
+}
+
+func (self *SServertemplate) AllowDeleteItem(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowDelete(userCred, self)
+}
+
+// validateServertemplateContent normalizes a server create body and checks it
+// the same way as a server create request does, without touching quota
+func validateServertemplateContent(ctx context.Context, userCred mcclient.TokenCredential, content jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	contentDict, ok := content.(*jsonutils.JSONDict)
+	if !ok {
+		return nil, httperrors.NewInputParameterError("content should be a dict")
+	}
+	body := contentDict.CopyExcludes(servertemplateExcludeKeys...)
+	input, err := cmdline.FetchServerCreateInputByJSON(body)
+	if err != nil {
+		return nil, httperrors.NewInputParameterError("invalid content: %s", err)
+	}
+	normalized := input.JSON(input)
+	_, err = GuestManager.validateCreateInput(ctx, userCred, input, body)
+	if err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+func (manager *SServertemplateManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	content, err := data.Get("content")
+	if err != nil {
+		return nil, httperrors.NewMissingParameterError("content")
+	}
+	normalized, err := validateServertemplateContent(ctx, userCred, content)
+	if err != nil {
+		return nil, err
+	}
+	data.Set("content", normalized)
+	return manager.SSharableVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+}
+
+func (self *SServertemplate) ValidateUpdateData(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	if content, _ := data.Get("content"); content != nil {
+		normalized, err := validateServertemplateContent(ctx, userCred, content)
+		if err != nil {
+			return nil, err
+		}
+		data.Set("content", normalized)
+	}
+	return self.SSharableVirtualResourceBase.ValidateUpdateData(ctx, userCred, query, data)
+}
+
+func (self *SServertemplate) CustomizeCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) error {
+	self.Version = 1
+	return self.SSharableVirtualResourceBase.CustomizeCreate(ctx, userCred, ownerProjId, query, data)
+}
+
+func (self *SServertemplate) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	self.SSharableVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
+	err := ServertemplateVersionManager.recordVersion(self)
+	if err != nil {
+		log.Errorf("record version of servertemplate %s fail: %s", self.Name, err)
+	}
+	self.SetStatus(userCred, SERVER_TEMPLATE_READY, "")
+}
+
+func (self *SServertemplate) PostUpdate(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	self.SSharableVirtualResourceBase.PostUpdate(ctx, userCred, query, data)
+	if !data.Contains("content") {
+		return
+	}
+	_, err := db.Update(self, func() error {
+		self.Version += 1
+		return nil
+	})
+	if err != nil {
+		log.Errorf("update version of servertemplate %s fail: %s", self.Name, err)
+		return
+	}
+	err = ServertemplateVersionManager.recordVersion(self)
+	if err != nil {
+		log.Errorf("record version of servertemplate %s fail: %s", self.Name, err)
+	}
+}
+
+func (self *SServertemplate) CustomizeDelete(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) error {
+	versions, err := ServertemplateVersionManager.fetchVersions(self.Id)
+	if err != nil {
+		return err
+	}
+	for i := range versions {
+		_, err = db.Update(&versions[i], versions[i].MarkDelete)
+		if err != nil {
+			return err
+		}
+	}
+	return self.SSharableVirtualResourceBase.CustomizeDelete(ctx, userCred, query, data)
+}
+
+func (self *SServertemplate) AllowGetDetailsVersions(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
+	return self.AllowGetDetails(ctx, userCred, query)
+}
+
+func (self *SServertemplate) GetDetailsVersions(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	versions, err := ServertemplateVersionManager.fetchVersions(self.Id)
+	if err != nil {
+		return nil, httperrors.NewGeneralError(err)
+	}
+	ret := jsonutils.NewDict()
+	ret.Add(jsonutils.Marshal(versions), "data")
+	ret.Add(jsonutils.NewInt(int64(len(versions))), "total")
+	return ret, nil
+}
+
+// getContent returns the content of the given version, 0 means the current one
+func (self *SServertemplate) getContent(version int) (*jsonutils.JSONDict, error) {
+	content := self.Content
+	if version > 0 && version != self.Version {
+		v, err := ServertemplateVersionManager.fetchVersion(self.Id, version)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, httperrors.NewResourceNotFoundError("version %d of servertemplate %s not found", version, self.Name)
+			}
+			return nil, httperrors.NewGeneralError(err)
+		}
+		content = v.Content
+	}
+	contentDict, ok := content.(*jsonutils.JSONDict)
+	if !ok {
+		return nil, httperrors.NewInternalServerError("invalid content of servertemplate %s", self.Name)
+	}
+	return contentDict, nil
+}
+
+// MergeCreateData expands a server create body in place by the servertemplate it refers to,
+// parameters given in data take precedence over those of the template. The body is
+// changed in place as the create body is passed on to the create tasks as is
+func (manager *SServertemplateManager) MergeCreateData(ctx context.Context, userCred mcclient.TokenCredential, templateId string, data *jsonutils.JSONDict) error {
+	obj, err := manager.FetchByIdOrName(userCred, templateId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return httperrors.NewResourceNotFoundError2(manager.Keyword(), templateId)
+		}
+		return httperrors.NewGeneralError(err)
+	}
+	template := obj.(*SServertemplate)
+	if !template.AllowGetDetails(ctx, userCred, nil) {
+		return httperrors.NewForbiddenError("not allow to use servertemplate %s", template.Name)
+	}
+	version := 0
+	if data.Contains("template_version") {
+		v, err := data.Int("template_version")
+		if err != nil || v <= 0 {
+			return httperrors.NewInputParameterError("invalid template_version")
+		}
+		version = int(v)
+	}
+	content, err := template.getContent(version)
+	if err != nil {
+		return err
+	}
+
+	merged := mergeServertemplateContent(content, data)
+	for _, k := range data.SortedKeys() {
+		data.Remove(k)
+	}
+	for k, v := range merged.Value() {
+		data.Set(k, v)
+	}
+	return nil
+}
+
+// mergeServertemplateContent overlays data on the template content, an array
+// parameter given in data, in either form, replaces the whole array of the template
+func mergeServertemplateContent(content *jsonutils.JSONDict, data *jsonutils.JSONDict) *jsonutils.JSONDict {
+	merged := content.Copy()
+	for _, kw := range servertemplateArrayKeywords {
+		if data.Contains(kw[1]) || data.Contains(fmt.Sprintf("%s.0", kw[0])) {
+			merged.Remove(kw[1])
+		}
+	}
+	if data.Contains("deploy_configs") || data.Contains("deploy.0.path") {
+		merged.Remove("deploy_configs")
+	}
+	for k, v := range data.Value() {
+		merged.Set(k, v)
+	}
+	for _, k := range []string{"template", "template_id", "template_version"} {
+		merged.Remove(k)
+	}
+	return merged
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"testing"
+
+	"yunion.io/x/jsonutils"
+)
+
+func TestMergeServertemplateContent(t *testing.T) {
+	mustJ := func(s string) *jsonutils.JSONDict {
+		data, err := jsonutils.ParseString(s)
+		if err != nil {
+			t.Fatalf("invalid json string: %s\n%s", err, s)
+		}
+		return data.(*jsonutils.JSONDict)
+	}
+	content := mustJ(`{
+		"vmem_size": 1024,
+		"vcpu_count": 2,
+		"disks": [{"image_id": "centos"}],
+		"nets": [{"network": "vnet"}],
+	}`)
+	cases := []struct {
+		name string
+		data *jsonutils.JSONDict
+		want *jsonutils.JSONDict
+	}{
+		{
+			name: "no override",
+			data: mustJ(`{"name": "vm", "template_id": "tmpl"}`),
+			want: mustJ(`{
+				"name": "vm",
+				"vmem_size": 1024,
+				"vcpu_count": 2,
+				"disks": [{"image_id": "centos"}],
+				"nets": [{"network": "vnet"}],
+			}`),
+		},
+		{
+			name: "override scalar",
+			data: mustJ(`{"name": "vm", "template": "tmpl", "template_version": 2, "vcpu_count": 4}`),
+			want: mustJ(`{
+				"name": "vm",
+				"vmem_size": 1024,
+				"vcpu_count": 4,
+				"disks": [{"image_id": "centos"}],
+				"nets": [{"network": "vnet"}],
+			}`),
+		},
+		{
+			name: "override array by index",
+			data: mustJ(`{"name": "vm", "template_id": "tmpl", "disk.0": "ubuntu", "disk.1": "10g"}`),
+			want: mustJ(`{
+				"name": "vm",
+				"vmem_size": 1024,
+				"vcpu_count": 2,
+				"disk.0": "ubuntu",
+				"disk.1": "10g",
+				"nets": [{"network": "vnet"}],
+			}`),
+		},
+		{
+			name: "override array as a whole",
+			data: mustJ(`{"name": "vm", "template_id": "tmpl", "nets": [{"network": "vnet2"}]}`),
+			want: mustJ(`{
+				"name": "vm",
+				"vmem_size": 1024,
+				"vcpu_count": 2,
+				"disks": [{"image_id": "centos"}],
+				"nets": [{"network": "vnet2"}],
+			}`),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := mergeServertemplateContent(content, c.data)
+			if got.String() != c.want.String() {
+				t.Errorf("want %s, got %s", c.want, got)
+			}
+		})
+	}
+}
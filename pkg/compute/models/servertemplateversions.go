@@ -0,0 +1,72 @@
+package models
+
+import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+type SServertemplateVersionManager struct {
+	db.SResourceBaseManager
+}
+
+var ServertemplateVersionManager *SServertemplateVersionManager
+
+func init() {
+	ServertemplateVersionManager = &SServertemplateVersionManager{
+		SResourceBaseManager: db.NewResourceBaseManager(
+			SServertemplateVersion{},
+			"servertemplateversions_tbl",
+			"servertemplateversion",
+			"servertemplateversions",
+		),
+	}
+}
+
+// SServertemplateVersion is a snapshot of the content of a servertemplate
+type SServertemplateVersion struct {
+	db.SResourceBase
+
+	Id               int64                `primary:"true" auto_increment:"true" list:"user"`
+	ServertemplateId string               `width:"36" charset:"ascii" nullable:"false" index:"true" list:"user"`
+	Version          int                  `nullable:"false" list:"user"`
+	Content          jsonutils.JSONObject `nullable:"false" list:"user"`
+}
+
+func (manager *SServertemplateVersionManager) AllowListItems(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
+	return false
+}
+
+func (manager *SServertemplateVersionManager) recordVersion(template *SServertemplate) error {
+	version := SServertemplateVersion{
+		ServertemplateId: template.Id,
+		Version:          template.Version,
+		Content:          template.Content,
+	}
+	version.SetModelManager(manager)
+	return manager.TableSpec().Insert(&version)
+}
+
+func (manager *SServertemplateVersionManager) fetchVersions(templateId string) ([]SServertemplateVersion, error) {
+	q := manager.Query().Equals("servertemplate_id", templateId).Desc("version")
+	versions := make([]SServertemplateVersion, 0)
+	err := db.FetchModelObjects(manager, q, &versions)
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (manager *SServertemplateVersionManager) fetchVersion(templateId string, version int) (*SServertemplateVersion, error) {
+	q := manager.Query().Equals("servertemplate_id", templateId).Equals("version", version)
+	v := SServertemplateVersion{}
+	v.SetModelManager(manager)
+	err := q.First(&v)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
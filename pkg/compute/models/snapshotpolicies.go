@@ -0,0 +1,434 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/utils"
+	"yunion.io/x/sqlchemy"
+
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/util/cronexpr"
+)
+
+const (
+	SNAPSHOT_POLICY_READY = "ready"
+)
+
+type SSnapshotPolicyManager struct {
+	db.SVirtualResourceBaseManager
+}
+
+var SnapshotPolicyManager *SSnapshotPolicyManager
+
+func init() {
+	SnapshotPolicyManager = &SSnapshotPolicyManager{
+		SVirtualResourceBaseManager: db.NewVirtualResourceBaseManager(
+			SSnapshotPolicy{},
+			"snapshotpolicies_tbl",
+			"snapshotpolicy",
+			"snapshotpolicies",
+		),
+	}
+}
+
+// SSnapshotPolicy takes snapshots of the bound disks, and of all disks of the bound guests,
+// at the time points described by Schedule, and prunes the snapshots it took before
+type SSnapshotPolicy struct {
+	db.SVirtualResourceBase
+
+	// cron expression, minute hour day-of-month month day-of-week
+	Schedule string `width:"64" charset:"ascii" nullable:"false" list:"user" create:"required" update:"user"`
+
+	// keep at most RetentionCount snapshots per disk, 0 means no limit
+	RetentionCount int `nullable:"false" default:"0" list:"user" create:"optional" update:"user"`
+	// drop snapshots older than RetentionDays days, 0 means no limit
+	RetentionDays int `nullable:"false" default:"0" list:"user" create:"optional" update:"user"`
+
+	Enabled bool `nullable:"false" default:"true" list:"user" create:"optional" update:"user"`
+}
+
+func validateSnapshotPolicyInputData(data *jsonutils.JSONDict, create bool) error {
+	if data.Contains("schedule") || create {
+		schedule, _ := data.GetString("schedule")
+		if len(schedule) == 0 {
+			return httperrors.NewMissingParameterError("schedule")
+		}
+		expr, err := cronexpr.Parse(schedule)
+		if err != nil {
+			return httperrors.NewInputParameterError("invalid schedule: %s", err)
+		}
+		if expr.Next(time.Now()).IsZero() {
+			return httperrors.NewInputParameterError("schedule %s never fires", schedule)
+		}
+		data.Set("schedule", jsonutils.NewString(expr.String()))
+	}
+	for _, key := range []string{"retention_count", "retention_days"} {
+		if data.Contains(key) {
+			val, err := data.Int(key)
+			if err != nil || val < 0 {
+				return httperrors.NewInputParameterError("invalid %s", key)
+			}
+		}
+	}
+	return nil
+}
+
+func (manager *SSnapshotPolicyManager) AllowListItems(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
+	return true
+}
+
+func (manager *SSnapshotPolicyManager) AllowCreateItem(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return true
+}
+
+func (self *SSnapshotPolicy) AllowGetDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowGet(userCred, self)
+}
+
+func (self *SSnapshotPolicy) AllowUpdateItem(ctx context.Context, userCred mcclient.TokenCredential) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowUpdate(userCred, self)
+}
+
+func (self *SSnapshotPolicy) AllowDeleteItem(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowDelete(userCred, self)
+}
+
+func (manager *SSnapshotPolicyManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	err := validateSnapshotPolicyInputData(data, true)
+	if err != nil {
+		return nil, err
+	}
+	return manager.SVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+}
+
+func (self *SSnapshotPolicy) ValidateUpdateData(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	err := validateSnapshotPolicyInputData(data, false)
+	if err != nil {
+		return nil, err
+	}
+	return self.SVirtualResourceBase.ValidateUpdateData(ctx, userCred, query, data)
+}
+
+func (self *SSnapshotPolicy) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	self.SVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
+	self.SetStatus(userCred, SNAPSHOT_POLICY_READY, "")
+}
+
+func (self *SSnapshotPolicy) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSONDict {
+	extra.Add(jsonutils.NewInt(int64(self.getDiskBindingCount())), "disk_count")
+	extra.Add(jsonutils.NewInt(int64(self.getGuestBindingCount())), "guest_count")
+	if self.Enabled {
+		if expr, err := cronexpr.Parse(self.Schedule); err == nil {
+			if next := expr.Next(time.Now()); !next.IsZero() {
+				extra.Add(jsonutils.NewTimeString(next.UTC()), "next_run_at")
+			}
+		}
+	}
+	return extra
+}
+
+func (self *SSnapshotPolicy) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
+	extra := self.SVirtualResourceBase.GetCustomizeColumns(ctx, userCred, query)
+	return self.getMoreDetails(extra)
+}
+
+func (self *SSnapshotPolicy) GetExtraDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	extra, err := self.SVirtualResourceBase.GetExtraDetails(ctx, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	return self.getMoreDetails(extra), nil
+}
+
+func (self *SSnapshotPolicy) getDiskBindingCount() int {
+	return SnapshotPolicyDiskManager.Query().Equals("snapshotpolicy_id", self.Id).Count()
+}
+
+func (self *SSnapshotPolicy) getGuestBindingCount() int {
+	return SnapshotPolicyGuestManager.Query().Equals("snapshotpolicy_id", self.Id).Count()
+}
+
+func (self *SSnapshotPolicy) GetDiskBindings() ([]SSnapshotPolicyDisk, error) {
+	bindings := make([]SSnapshotPolicyDisk, 0)
+	q := SnapshotPolicyDiskManager.Query().Equals("snapshotpolicy_id", self.Id)
+	err := db.FetchModelObjects(SnapshotPolicyDiskManager, q, &bindings)
+	if err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
+
+func (self *SSnapshotPolicy) GetGuestBindings() ([]SSnapshotPolicyGuest, error) {
+	bindings := make([]SSnapshotPolicyGuest, 0)
+	q := SnapshotPolicyGuestManager.Query().Equals("snapshotpolicy_id", self.Id)
+	err := db.FetchModelObjects(SnapshotPolicyGuestManager, q, &bindings)
+	if err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
+
+func (self *SSnapshotPolicy) AllowPerformEnable(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, self, "enable")
+}
+
+func (self *SSnapshotPolicy) PerformEnable(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	return nil, self.setEnabled(ctx, userCred, true)
+}
+
+func (self *SSnapshotPolicy) AllowPerformDisable(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, self, "disable")
+}
+
+func (self *SSnapshotPolicy) PerformDisable(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	return nil, self.setEnabled(ctx, userCred, false)
+}
+
+func (self *SSnapshotPolicy) setEnabled(ctx context.Context, userCred mcclient.TokenCredential, enabled bool) error {
+	if self.Enabled == enabled {
+		return nil
+	}
+	diff, err := db.Update(self, func() error {
+		self.Enabled = enabled
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	db.OpsLog.LogEvent(self, db.ACT_UPDATE, diff, userCred)
+	return nil
+}
+
+func (self *SSnapshotPolicy) CustomizeDelete(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) error {
+	diskBindings, err := self.GetDiskBindings()
+	if err != nil {
+		return err
+	}
+	for i := range diskBindings {
+		if err := diskBindings[i].Detach(ctx, userCred); err != nil {
+			return err
+		}
+	}
+	guestBindings, err := self.GetGuestBindings()
+	if err != nil {
+		return err
+	}
+	for i := range guestBindings {
+		if err := guestBindings[i].Detach(ctx, userCred); err != nil {
+			return err
+		}
+	}
+	return self.SVirtualResourceBase.CustomizeDelete(ctx, userCred, query, data)
+}
+
+type sSnapshotPolicyTarget struct {
+	disk     *SDisk
+	boundAt  time.Time
+	guestIds []string
+}
+
+// getTargetDisks collects the disks to be protected by the policy, keyed by disk id.
+// A disk bound both directly and through its guest uses the earlier binding time.
+func (self *SSnapshotPolicy) getTargetDisks() (map[string]*sSnapshotPolicyTarget, error) {
+	targets := make(map[string]*sSnapshotPolicyTarget)
+	addTarget := func(disk *SDisk, boundAt time.Time) {
+		if target, ok := targets[disk.Id]; ok {
+			if boundAt.Before(target.boundAt) {
+				target.boundAt = boundAt
+			}
+			return
+		}
+		targets[disk.Id] = &sSnapshotPolicyTarget{disk: disk, boundAt: boundAt}
+	}
+
+	diskBindings, err := self.GetDiskBindings()
+	if err != nil {
+		return nil, err
+	}
+	for i := range diskBindings {
+		disk := diskBindings[i].GetDisk()
+		if disk == nil {
+			continue
+		}
+		addTarget(disk, diskBindings[i].CreatedAt)
+	}
+
+	guestBindings, err := self.GetGuestBindings()
+	if err != nil {
+		return nil, err
+	}
+	for i := range guestBindings {
+		guest := guestBindings[i].GetGuest()
+		if guest == nil {
+			continue
+		}
+		for _, guestdisk := range guest.GetDisks() {
+			disk := guestdisk.GetDisk()
+			if disk == nil {
+				continue
+			}
+			addTarget(disk, guestBindings[i].CreatedAt)
+		}
+	}
+	return targets, nil
+}
+
+func (self *SSnapshotPolicy) getLatestSnapshot(diskId string) *SSnapshot {
+	snapshot := SSnapshot{}
+	q := SnapshotManager.Query().Equals("disk_id", diskId).Equals("snapshotpolicy_id", self.Id).
+		Equals("fake_deleted", false).Desc("created_at")
+	err := q.First(&snapshot)
+	if err != nil {
+		return nil
+	}
+	snapshot.SetModelManager(SnapshotManager)
+	return &snapshot
+}
+
+// getExpiredSnapshots returns ready snapshots taken by the policy for the disk which exceed
+// the retention limits, oldest first.  The latest snapshot is always kept.
+func (self *SSnapshotPolicy) getExpiredSnapshots(diskId string, now time.Time) ([]SSnapshot, error) {
+	snapshots := make([]SSnapshot, 0)
+	q := SnapshotManager.Query().Equals("disk_id", diskId).Equals("snapshotpolicy_id", self.Id).
+		Equals("fake_deleted", false).Desc("created_at")
+	err := db.FetchModelObjects(SnapshotManager, q, &snapshots)
+	if err != nil {
+		return nil, err
+	}
+	expired := make([]SSnapshot, 0)
+	for i := len(snapshots) - 1; i > 0; i-- {
+		if snapshots[i].Status != SNAPSHOT_READY {
+			continue
+		}
+		if self.RetentionCount > 0 && i >= self.RetentionCount {
+			expired = append(expired, snapshots[i])
+		} else if self.RetentionDays > 0 && snapshots[i].CreatedAt.Before(now.AddDate(0, 0, -self.RetentionDays)) {
+			expired = append(expired, snapshots[i])
+		}
+	}
+	return expired, nil
+}
+
+func (self *SSnapshotPolicy) createSnapshot(ctx context.Context, userCred mcclient.TokenCredential, disk *SDisk, guest *SGuest, now time.Time) error {
+	pendingUsage := &SQuota{Snapshot: 1}
+	err := QuotaManager.CheckSetPendingQuota(ctx, userCred, disk.ProjectId, pendingUsage)
+	if err != nil {
+		return fmt.Errorf("check set pending quota error %s", err)
+	}
+	name := fmt.Sprintf("%s-%s-%s", self.Name, disk.Name, now.Format("20060102150405"))
+	snapshot, err := SnapshotManager.createSnapshot(ctx, disk.ProjectId, AUTO, self.Id, disk.Id, "", name)
+	QuotaManager.CancelPendingUsage(ctx, userCred, disk.ProjectId, nil, pendingUsage)
+	if err != nil {
+		return err
+	}
+	return guest.StartDiskSnapshot(ctx, userCred, disk.Id, snapshot.Id)
+}
+
+// execute takes at most one action on each target disk per round: either create a snapshot
+// if a scheduled time point has passed since the last one, or delete the oldest expired one.
+// Both operations occupy the guest, so anything skipped here is retried in the next round.
+func (self *SSnapshotPolicy) execute(ctx context.Context, userCred mcclient.TokenCredential, now time.Time) {
+	expr, err := cronexpr.Parse(self.Schedule)
+	if err != nil {
+		log.Errorf("snapshot policy %s(%s) invalid schedule %s: %s", self.Name, self.Id, self.Schedule, err)
+		return
+	}
+	lastPoint := expr.Prev(now)
+
+	targets, err := self.getTargetDisks()
+	if err != nil {
+		log.Errorf("snapshot policy %s(%s) get target disks fail: %s", self.Name, self.Id, err)
+		return
+	}
+	busyGuests := make(map[string]bool)
+	for _, target := range targets {
+		disk := target.disk
+		guests := disk.GetGuests()
+		if len(guests) != 1 {
+			log.Debugf("Disk %s(%s) is attached to %d guest(s), skip snapshot policy %s", disk.Name, disk.Id, len(guests), self.Name)
+			continue
+		}
+		guest := &guests[0]
+		if busyGuests[guest.Id] || !utils.IsInStringArray(guest.Status, []string{VM_RUNNING, VM_READY}) {
+			continue
+		}
+
+		if !lastPoint.IsZero() && lastPoint.After(target.boundAt) {
+			latest := self.getLatestSnapshot(disk.Id)
+			if latest == nil || latest.CreatedAt.Before(lastPoint) {
+				busyGuests[guest.Id] = true
+				err := self.createSnapshot(ctx, userCred, disk, guest, now)
+				if err != nil {
+					log.Errorf("snapshot policy %s create snapshot for disk %s fail: %s", self.Name, disk.Id, err)
+					db.OpsLog.LogEvent(disk, db.ACT_SNAPSHOT_FAIL, err.Error(), userCred)
+				}
+				continue
+			}
+		}
+
+		expired, err := self.getExpiredSnapshots(disk.Id, now)
+		if err != nil {
+			log.Errorf("snapshot policy %s get expired snapshots for disk %s fail: %s", self.Name, disk.Id, err)
+			continue
+		}
+		if len(expired) > 0 {
+			busyGuests[guest.Id] = true
+			err := expired[0].StartSnapshotDeleteTask(ctx, userCred, false, "")
+			if err != nil {
+				log.Errorf("snapshot policy %s delete snapshot %s fail: %s", self.Name, expired[0].Id, err)
+			}
+		}
+	}
+}
+
+func (manager *SSnapshotPolicyManager) getEnabledPolicies() ([]SSnapshotPolicy, error) {
+	policies := make([]SSnapshotPolicy, 0)
+	q := manager.Query().IsTrue("enabled")
+	err := db.FetchModelObjects(manager, q, &policies)
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (manager *SSnapshotPolicyManager) ExecuteSnapshotPolicies(ctx context.Context, userCred mcclient.TokenCredential, isStart bool) {
+	policies, err := manager.getEnabledPolicies()
+	if err != nil {
+		log.Errorf("ExecuteSnapshotPolicies fetch policies fail %s", err)
+		return
+	}
+	now := time.Now()
+	for i := range policies {
+		policies[i].execute(ctx, userCred, now)
+	}
+}
+
+func (manager *SSnapshotPolicyManager) ListItemFilter(ctx context.Context, q *sqlchemy.SQuery, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*sqlchemy.SQuery, error) {
+	q, err := manager.SVirtualResourceBaseManager.ListItemFilter(ctx, q, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	if diskStr := jsonutils.GetAnyString(query, []string{"disk", "disk_id"}); len(diskStr) > 0 {
+		disk, err := DiskManager.FetchByIdOrName(userCred, diskStr)
+		if err != nil {
+			return nil, httperrors.NewResourceNotFoundError2(DiskManager.Keyword(), diskStr)
+		}
+		sq := SnapshotPolicyDiskManager.Query("snapshotpolicy_id").Equals("disk_id", disk.GetId()).SubQuery()
+		q = q.In("id", sq)
+	}
+	if guestStr := jsonutils.GetAnyString(query, []string{"guest", "guest_id", "server", "server_id"}); len(guestStr) > 0 {
+		guest, err := GuestManager.FetchByIdOrName(userCred, guestStr)
+		if err != nil {
+			return nil, httperrors.NewResourceNotFoundError2(GuestManager.Keyword(), guestStr)
+		}
+		sq := SnapshotPolicyGuestManager.Query("snapshotpolicy_id").Equals("guest_id", guest.GetId()).SubQuery()
+		q = q.In("id", sq)
+	}
+	return q, nil
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+type SSnapshotPolicyDiskManager struct {
+	SSnapshotPolicyJointsManager
+}
+
+var SnapshotPolicyDiskManager *SSnapshotPolicyDiskManager
+
+func init() {
+	db.InitManager(func() {
+		SnapshotPolicyDiskManager = &SSnapshotPolicyDiskManager{
+			SSnapshotPolicyJointsManager: NewSnapshotPolicyJointsManager(
+				SSnapshotPolicyDisk{},
+				"snapshotpolicydisks_tbl",
+				"snapshotpolicydisk",
+				"snapshotpolicydisks",
+				DiskManager,
+			),
+		}
+	})
+}
+
+type SSnapshotPolicyDisk struct {
+	SSnapshotPolicyJointsBase
+
+	DiskId string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"required"`
+}
+
+func (manager *SSnapshotPolicyDiskManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	diskId, _ := data.GetString("disk_id")
+	disk, err := DiskManager.FetchById(diskId)
+	if err != nil {
+		return nil, httperrors.NewResourceNotFoundError2(DiskManager.Keyword(), diskId)
+	}
+	if disk.(*SDisk).DiskType == DISK_TYPE_SWAP {
+		return nil, httperrors.NewUnsupportOperationError("Cannot take snapshots of swap disk")
+	}
+	return manager.SSnapshotPolicyJointsManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+}
+
+func (joint *SSnapshotPolicyDisk) Master() db.IStandaloneModel {
+	return db.JointMaster(joint)
+}
+
+func (joint *SSnapshotPolicyDisk) Slave() db.IStandaloneModel {
+	return db.JointSlave(joint)
+}
+
+func (self *SSnapshotPolicyDisk) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
+	extra := self.SSnapshotPolicyJointsBase.GetCustomizeColumns(ctx, userCred, query)
+	return db.JointModelExtra(self, extra)
+}
+
+func (self *SSnapshotPolicyDisk) GetExtraDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	extra, err := self.SSnapshotPolicyJointsBase.GetExtraDetails(ctx, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	return db.JointModelExtra(self, extra), nil
+}
+
+func (self *SSnapshotPolicyDisk) GetDisk() *SDisk {
+	disk, _ := DiskManager.FetchById(self.DiskId)
+	if disk != nil {
+		return disk.(*SDisk)
+	}
+	return nil
+}
+
+func (self *SSnapshotPolicyDisk) Delete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return db.DeleteModel(ctx, userCred, self)
+}
+
+func (self *SSnapshotPolicyDisk) Detach(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return db.DetachJoint(ctx, userCred, self)
+}
+
+func (manager *SSnapshotPolicyDiskManager) DetachDisk(ctx context.Context, userCred mcclient.TokenCredential, diskId string) error {
+	bindings := make([]SSnapshotPolicyDisk, 0)
+	q := manager.Query().Equals("disk_id", diskId)
+	err := db.FetchModelObjects(manager, q, &bindings)
+	if err != nil {
+		return err
+	}
+	for i := range bindings {
+		err = bindings[i].Detach(ctx, userCred)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
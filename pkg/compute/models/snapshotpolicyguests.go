@@ -0,0 +1,91 @@
+package models
+
+import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+type SSnapshotPolicyGuestManager struct {
+	SSnapshotPolicyJointsManager
+}
+
+var SnapshotPolicyGuestManager *SSnapshotPolicyGuestManager
+
+func init() {
+	db.InitManager(func() {
+		SnapshotPolicyGuestManager = &SSnapshotPolicyGuestManager{
+			SSnapshotPolicyJointsManager: NewSnapshotPolicyJointsManager(
+				SSnapshotPolicyGuest{},
+				"snapshotpolicyguests_tbl",
+				"snapshotpolicyguest",
+				"snapshotpolicyguests",
+				GuestManager,
+			),
+		}
+	})
+}
+
+// SSnapshotPolicyGuest binds a guest to a snapshot policy, which then covers every disk
+// attached to the guest at the time the policy runs
+type SSnapshotPolicyGuest struct {
+	SSnapshotPolicyJointsBase
+
+	GuestId string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"required"`
+}
+
+func (joint *SSnapshotPolicyGuest) Master() db.IStandaloneModel {
+	return db.JointMaster(joint)
+}
+
+func (joint *SSnapshotPolicyGuest) Slave() db.IStandaloneModel {
+	return db.JointSlave(joint)
+}
+
+func (self *SSnapshotPolicyGuest) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
+	extra := self.SSnapshotPolicyJointsBase.GetCustomizeColumns(ctx, userCred, query)
+	return db.JointModelExtra(self, extra)
+}
+
+func (self *SSnapshotPolicyGuest) GetExtraDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	extra, err := self.SSnapshotPolicyJointsBase.GetExtraDetails(ctx, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	return db.JointModelExtra(self, extra), nil
+}
+
+func (self *SSnapshotPolicyGuest) GetGuest() *SGuest {
+	guest, _ := GuestManager.FetchById(self.GuestId)
+	if guest != nil {
+		return guest.(*SGuest)
+	}
+	return nil
+}
+
+func (self *SSnapshotPolicyGuest) Delete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return db.DeleteModel(ctx, userCred, self)
+}
+
+func (self *SSnapshotPolicyGuest) Detach(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return db.DetachJoint(ctx, userCred, self)
+}
+
+func (manager *SSnapshotPolicyGuestManager) DetachGuest(ctx context.Context, userCred mcclient.TokenCredential, guestId string) error {
+	bindings := make([]SSnapshotPolicyGuest, 0)
+	q := manager.Query().Equals("guest_id", guestId)
+	err := db.FetchModelObjects(manager, q, &bindings)
+	if err != nil {
+		return err
+	}
+	for i := range bindings {
+		err = bindings[i].Detach(ctx, userCred)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
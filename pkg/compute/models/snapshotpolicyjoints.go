@@ -0,0 +1,34 @@
+package models
+
+import "yunion.io/x/onecloud/pkg/cloudcommon/db"
+
+type SSnapshotPolicyJointsManager struct {
+	db.SVirtualJointResourceBaseManager
+}
+
+func NewSnapshotPolicyJointsManager(dt interface{}, tableName string, keyword string, keywordPlural string, slave db.IVirtualModelManager) SSnapshotPolicyJointsManager {
+	return SSnapshotPolicyJointsManager{
+		SVirtualJointResourceBaseManager: db.NewVirtualJointResourceBaseManager(
+			dt,
+			tableName,
+			keyword,
+			keywordPlural,
+			SnapshotPolicyManager,
+			slave,
+		),
+	}
+}
+
+type SSnapshotPolicyJointsBase struct {
+	db.SVirtualJointResourceBase
+
+	SnapshotpolicyId string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"required"`
+}
+
+func (self *SSnapshotPolicyJointsBase) GetSnapshotPolicy() *SSnapshotPolicy {
+	policy, _ := SnapshotPolicyManager.FetchById(self.SnapshotpolicyId)
+	if policy != nil {
+		return policy.(*SSnapshotPolicy)
+	}
+	return nil
+}
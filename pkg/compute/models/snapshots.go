@@ -54,6 +54,9 @@ type SSnapshot struct {
 	RefCount int `nullable:"false" default:"0" list:"user"`
 
 	CloudregionId string `width:"36" charset:"ascii" nullable:"true" list:"user"`
+
+	// snapshot policy which took the snapshot
+	SnapshotpolicyId string `width:"36" charset:"ascii" nullable:"true" list:"user"`
 }
 
 var SnapshotManager *SSnapshotManager
@@ -127,6 +130,17 @@ func (manager *SSnapshotManager) ListItemFilter(ctx context.Context, q *sqlchemy
 			sqlchemy.In(q.Field("storage_id"), sq)))
 	}
 
+	if policyStr := jsonutils.GetAnyString(query, []string{"snapshotpolicy", "snapshotpolicy_id"}); len(policyStr) > 0 {
+		policy, err := SnapshotPolicyManager.FetchByIdOrName(userCred, policyStr)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, httperrors.NewResourceNotFoundError2(SnapshotPolicyManager.Keyword(), policyStr)
+			}
+			return nil, httperrors.NewGeneralError(err)
+		}
+		q = q.Equals("snapshotpolicy_id", policy.GetId())
+	}
+
 	if diskType, err := query.GetString("disk_type"); err == nil {
 		diskTbl := DiskManager.Query().SubQuery()
 		sq := diskTbl.Query(diskTbl.Field("id")).Equals("disk_type", diskType).SubQuery()
@@ -342,6 +356,10 @@ func (self *SSnapshotManager) GetDiskSnapshotCount(diskId string) int {
 }
 
 func (self *SSnapshotManager) CreateSnapshot(ctx context.Context, userCred mcclient.TokenCredential, createdBy, diskId, guestId, location, name string) (*SSnapshot, error) {
+	return self.createSnapshot(ctx, userCred.GetProjectId(), createdBy, "", diskId, location, name)
+}
+
+func (self *SSnapshotManager) createSnapshot(ctx context.Context, ownerProjId, createdBy, policyId, diskId, location, name string) (*SSnapshot, error) {
 	iDisk, err := DiskManager.FetchById(diskId)
 	if err != nil {
 		return nil, err
@@ -350,7 +368,7 @@ func (self *SSnapshotManager) CreateSnapshot(ctx context.Context, userCred mccli
 	storage := disk.GetStorage()
 	snapshot := &SSnapshot{}
 	snapshot.SetModelManager(self)
-	snapshot.ProjectId = userCred.GetProjectId()
+	snapshot.ProjectId = ownerProjId
 	snapshot.DiskId = disk.Id
 	if len(disk.ExternalId) == 0 {
 		snapshot.StorageId = disk.StorageId
@@ -359,6 +377,7 @@ func (self *SSnapshotManager) CreateSnapshot(ctx context.Context, userCred mccli
 	snapshot.DiskType = disk.DiskType
 	snapshot.Location = location
 	snapshot.CreatedBy = createdBy
+	snapshot.SnapshotpolicyId = policyId
 	snapshot.ManagerId = storage.ManagerId
 	snapshot.CloudregionId = storage.getZone().GetRegion().GetId()
 	snapshot.Name = name
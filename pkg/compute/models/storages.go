@@ -63,6 +63,10 @@ const (
 
 	// openstack
 	STORAGE_OPENSTACK_ISCSI = api.STORAGE_OPENSTACK_ISCSI
+
+	// google storage type
+	STORAGE_GOOGLE_PD_STANDARD = api.STORAGE_GOOGLE_PD_STANDARD // 标准永久性磁盘
+	STORAGE_GOOGLE_PD_SSD      = api.STORAGE_GOOGLE_PD_SSD      // SSD永久性磁盘
 )
 
 const (
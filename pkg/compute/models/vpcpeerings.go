@@ -0,0 +1,396 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/compare"
+	"yunion.io/x/sqlchemy"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+type SVpcPeeringManager struct {
+	db.SVirtualResourceBaseManager
+}
+
+var VpcPeeringManager *SVpcPeeringManager
+
+func init() {
+	VpcPeeringManager = &SVpcPeeringManager{
+		SVirtualResourceBaseManager: db.NewVirtualResourceBaseManager(
+			SVpcPeering{},
+			"vpcpeerings_tbl",
+			"vpcpeering",
+			"vpcpeerings",
+		),
+	}
+}
+
+// 对等连接以发起端VPC为准, 对端VPC未纳管时仅记录其外部ID
+type SVpcPeering struct {
+	db.SVirtualResourceBase
+	SManagedResourceBase
+
+	VpcId         string `width:"36" charset:"ascii" nullable:"false" list:"user" create:"required"`
+	CloudregionId string `width:"36" charset:"ascii" nullable:"false" list:"user"`
+
+	PeerVpcId            string `width:"36" charset:"ascii" nullable:"true" list:"user" create:"optional"`
+	ExternalPeerVpcId    string `width:"256" charset:"utf8" nullable:"true" list:"user" create:"optional"`
+	ExternalPeerRegionId string `width:"64" charset:"ascii" nullable:"true" list:"user" create:"optional"`
+	PeerAccountId        string `width:"128" charset:"ascii" nullable:"true" list:"user" create:"optional"` // 跨账号时对端账号ID
+}
+
+func (manager *SVpcPeeringManager) ListItemFilter(ctx context.Context, q *sqlchemy.SQuery, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*sqlchemy.SQuery, error) {
+	var err error
+	q, err = managedResourceFilterByAccount(q, query, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	q = managedResourceFilterByCloudType(q, query, "", nil)
+
+	q, err = manager.SVirtualResourceBaseManager.ListItemFilter(ctx, q, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	userProjId := userCred.GetProjectId()
+	data := query.(*jsonutils.JSONDict)
+	q, err = validators.ApplyModelFilters(q, data, []*validators.ModelFilterOptions{
+		{Key: "vpc", ModelKeyword: "vpc", ProjectId: userProjId},
+		{Key: "peer_vpc", ModelKeyword: "vpc", ProjectId: userProjId},
+		{Key: "cloudregion", ModelKeyword: "cloudregion", ProjectId: userProjId},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (manager *SVpcPeeringManager) ValidateCreateData(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	vpcV := validators.NewModelIdOrNameValidator("vpc", "vpc", ownerProjId)
+	peerVpcV := validators.NewModelIdOrNameValidator("peer_vpc", "vpc", ownerProjId)
+	keyV := map[string]validators.IValidator{
+		"vpc":      vpcV,
+		"peer_vpc": peerVpcV.Optional(true),
+	}
+	for _, v := range keyV {
+		if err := v.Validate(data); err != nil {
+			return nil, err
+		}
+	}
+	vpc := vpcV.Model.(*SVpc)
+	provider := vpc.GetCloudprovider()
+	if provider == nil {
+		return nil, httperrors.NewInputParameterError("vpc %s is not managed by any cloud provider", vpc.Name)
+	}
+	if peerVpcV.Model != nil {
+		peerVpc := peerVpcV.Model.(*SVpc)
+		if peerVpc.Id == vpc.Id {
+			return nil, httperrors.NewInputParameterError("cannot peer vpc %s with itself", vpc.Name)
+		}
+		peerProvider := peerVpc.GetCloudprovider()
+		if peerProvider == nil || peerProvider.Provider != provider.Provider {
+			return nil, httperrors.NewInputParameterError("vpc %s and peer vpc %s are not from the same cloud", vpc.Name, peerVpc.Name)
+		}
+		// 跨账号时无法从本地数据推断对端账号ID, 需由用户指定
+		peerAccountId, _ := data.GetString("peer_account_id")
+		if peerProvider.CloudaccountId != provider.CloudaccountId && len(peerAccountId) == 0 {
+			return nil, httperrors.NewMissingParameterError("peer_account_id")
+		}
+		data.Set("external_peer_vpc_id", jsonutils.NewString(peerVpc.ExternalId))
+	} else {
+		externalPeerVpcId, _ := data.GetString("external_peer_vpc_id")
+		if len(externalPeerVpcId) == 0 {
+			return nil, httperrors.NewMissingParameterError("peer_vpc or external_peer_vpc_id")
+		}
+	}
+	data.Set("cloudregion_id", jsonutils.NewString(vpc.CloudregionId))
+	data.Set("manager_id", jsonutils.NewString(vpc.ManagerId))
+	return manager.SVirtualResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+}
+
+func (self *SVpcPeering) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	self.SVirtualResourceBase.PostCreate(ctx, userCred, ownerProjId, query, data)
+
+	err := self.StartVpcPeeringTask(ctx, userCred, "VpcPeeringCreateTask", api.VPC_PEERING_STATUS_CREATING, "")
+	if err != nil {
+		self.SetStatus(userCred, api.VPC_PEERING_STATUS_CREATE_FAILED, err.Error())
+	}
+}
+
+func (self *SVpcPeering) StartVpcPeeringTask(ctx context.Context, userCred mcclient.TokenCredential, taskName string, status string, parentTaskId string) error {
+	task, err := taskman.TaskManager.NewTask(ctx, taskName, self, userCred, nil, parentTaskId, "", nil)
+	if err != nil {
+		log.Errorf("newTask %s fail %s", taskName, err)
+		return err
+	}
+	self.SetStatus(userCred, status, "")
+	task.ScheduleRun(nil)
+	return nil
+}
+
+func (self *SVpcPeering) GetRegion() *SCloudregion {
+	return CloudregionManager.FetchRegionById(self.CloudregionId)
+}
+
+func (self *SVpcPeering) GetVpc() (*SVpc, error) {
+	vpc, err := VpcManager.FetchById(self.VpcId)
+	if err != nil {
+		return nil, err
+	}
+	return vpc.(*SVpc), nil
+}
+
+func (self *SVpcPeering) GetPeerVpc() (*SVpc, error) {
+	if len(self.PeerVpcId) == 0 {
+		return nil, nil
+	}
+	vpc, err := VpcManager.FetchById(self.PeerVpcId)
+	if err != nil {
+		return nil, err
+	}
+	return vpc.(*SVpc), nil
+}
+
+func (self *SVpcPeering) GetIVpcPeering() (cloudprovider.ICloudVpcPeering, error) {
+	vpc, err := self.GetVpc()
+	if err != nil {
+		return nil, err
+	}
+	ivpc, err := vpc.GetIVpc()
+	if err != nil {
+		return nil, err
+	}
+	return ivpc.GetIVpcPeeringById(self.ExternalId)
+}
+
+// 对端VPC已纳管时, 通过对端账号获取对等连接, 用于接受跨账号的连接请求
+func (self *SVpcPeering) GetPeerIVpcPeering() (cloudprovider.ICloudVpcPeering, error) {
+	peerVpc, err := self.GetPeerVpc()
+	if err != nil {
+		return nil, err
+	}
+	if peerVpc == nil {
+		return nil, cloudprovider.ErrNotFound
+	}
+	ivpc, err := peerVpc.GetIVpc()
+	if err != nil {
+		return nil, err
+	}
+	return ivpc.GetIVpcPeeringById(self.ExternalId)
+}
+
+func (self *SVpcPeering) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSONDict {
+	info := MakeCloudProviderInfo(self.GetRegion(), nil, self.GetCloudprovider())
+	extra.Update(jsonutils.Marshal(&info))
+	if vpc, err := self.GetVpc(); err == nil {
+		extra.Add(jsonutils.NewString(vpc.Name), "vpc")
+	}
+	if peerVpc, err := self.GetPeerVpc(); err == nil && peerVpc != nil {
+		extra.Add(jsonutils.NewString(peerVpc.Name), "peer_vpc")
+	}
+	return extra
+}
+
+func (self *SVpcPeering) GetCustomizeColumns(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) *jsonutils.JSONDict {
+	extra := self.SVirtualResourceBase.GetCustomizeColumns(ctx, userCred, query)
+	return self.getMoreDetails(extra)
+}
+
+func (self *SVpcPeering) GetExtraDetails(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject) (*jsonutils.JSONDict, error) {
+	extra, err := self.SVirtualResourceBase.GetExtraDetails(ctx, userCred, query)
+	if err != nil {
+		return nil, err
+	}
+	return self.getMoreDetails(extra), nil
+}
+
+func (self *SVpcPeering) AllowPerformAccept(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, self, "accept")
+}
+
+func (self *SVpcPeering) PerformAccept(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	if self.Status != api.VPC_PEERING_STATUS_PENDING_ACCEPT && self.Status != api.VPC_PEERING_STATUS_ACCEPT_FAILED {
+		return nil, httperrors.NewInvalidStatusError("cannot accept vpc peering in status %s", self.Status)
+	}
+	if len(self.PeerVpcId) == 0 {
+		return nil, httperrors.NewUnsupportOperationError("peer vpc is not managed, vpc peering must be accepted by the peer account")
+	}
+	return nil, self.StartVpcPeeringTask(ctx, userCred, "VpcPeeringAcceptTask", api.VPC_PEERING_STATUS_ACCEPTING, "")
+}
+
+func (self *SVpcPeering) AllowPerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return db.IsAdminAllowPerform(userCred, self, "purge")
+}
+
+func (self *SVpcPeering) PerformPurge(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	provider := self.GetCloudprovider()
+	if provider != nil {
+		if provider.Enabled {
+			return nil, httperrors.NewInvalidStatusError("Cannot purge vpc peering on enabled cloud provider")
+		}
+	}
+	err := self.RealDelete(ctx, userCred)
+	return nil, err
+}
+
+func (self *SVpcPeering) Delete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	log.Infof("vpc peering delete do nothing")
+	return nil
+}
+
+func (self *SVpcPeering) RealDelete(ctx context.Context, userCred mcclient.TokenCredential) error {
+	return self.SVirtualResourceBase.Delete(ctx, userCred)
+}
+
+func (self *SVpcPeering) CustomizeDelete(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) error {
+	return self.StartVpcPeeringTask(ctx, userCred, "VpcPeeringDeleteTask", api.VPC_PEERING_STATUS_DELETING, "")
+}
+
+func (manager *SVpcPeeringManager) getVpcPeeringsByVpc(vpc *SVpc) ([]SVpcPeering, error) {
+	peerings := make([]SVpcPeering, 0)
+	q := manager.Query().Equals("vpc_id", vpc.Id)
+	err := db.FetchModelObjects(manager, q, &peerings)
+	if err != nil {
+		return nil, err
+	}
+	return peerings, nil
+}
+
+func (manager *SVpcPeeringManager) SyncVpcPeerings(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, vpc *SVpc, extPeerings []cloudprovider.ICloudVpcPeering) compare.SyncResult {
+	lockman.LockClass(ctx, manager, provider.ProjectId)
+	defer lockman.ReleaseClass(ctx, manager, provider.ProjectId)
+
+	syncResult := compare.SyncResult{}
+
+	dbPeerings, err := manager.getVpcPeeringsByVpc(vpc)
+	if err != nil {
+		syncResult.Error(err)
+		return syncResult
+	}
+
+	for i := range dbPeerings {
+		if taskman.TaskManager.IsInTask(&dbPeerings[i]) {
+			syncResult.Error(fmt.Errorf("object in task"))
+			return syncResult
+		}
+	}
+
+	removed := make([]SVpcPeering, 0)
+	commondb := make([]SVpcPeering, 0)
+	commonext := make([]cloudprovider.ICloudVpcPeering, 0)
+	added := make([]cloudprovider.ICloudVpcPeering, 0)
+
+	err = compare.CompareSets(dbPeerings, extPeerings, &removed, &commondb, &commonext, &added)
+	if err != nil {
+		syncResult.Error(err)
+		return syncResult
+	}
+
+	for i := 0; i < len(removed); i += 1 {
+		err = removed[i].syncRemoveCloudVpcPeering(ctx, userCred)
+		if err != nil {
+			syncResult.DeleteError(err)
+		} else {
+			syncResult.Delete()
+		}
+	}
+
+	for i := 0; i < len(commondb); i += 1 {
+		err = commondb[i].SyncWithCloudVpcPeering(ctx, userCred, commonext[i])
+		if err != nil {
+			syncResult.UpdateError(err)
+			continue
+		}
+		syncMetadata(ctx, userCred, &commondb[i], commonext[i])
+		syncResult.Update()
+	}
+
+	for i := 0; i < len(added); i += 1 {
+		peering, err := manager.newFromCloudVpcPeering(ctx, userCred, provider, vpc, added[i])
+		if err != nil {
+			syncResult.AddError(err)
+			continue
+		}
+		syncMetadata(ctx, userCred, peering, added[i])
+		syncResult.Add()
+	}
+
+	return syncResult
+}
+
+func (self *SVpcPeering) syncRemoveCloudVpcPeering(ctx context.Context, userCred mcclient.TokenCredential) error {
+	lockman.LockObject(ctx, self)
+	defer lockman.ReleaseObject(ctx, self)
+
+	err := self.ValidateDeleteCondition(ctx)
+	if err != nil {
+		self.SetStatus(userCred, api.VPC_PEERING_STATUS_UNKNOWN, "sync to delete")
+		return err
+	}
+	return self.RealDelete(ctx, userCred)
+}
+
+// 对端VPC若已同步到本地则记录本地ID
+func (self *SVpcPeering) syncWithCloud(extPeering cloudprovider.ICloudVpcPeering) {
+	self.Status = extPeering.GetStatus()
+	self.ExternalId = extPeering.GetGlobalId()
+	self.IsEmulated = extPeering.IsEmulated()
+	self.Description = extPeering.GetDescription()
+	self.ExternalPeerVpcId = extPeering.GetPeerVpcId()
+	self.ExternalPeerRegionId = extPeering.GetPeerRegionId()
+	self.PeerAccountId = extPeering.GetPeerAccountId()
+
+	self.PeerVpcId = ""
+	if len(self.ExternalPeerVpcId) > 0 {
+		if peerVpc, err := VpcManager.FetchByExternalId(self.ExternalPeerVpcId); err == nil && peerVpc != nil {
+			self.PeerVpcId = peerVpc.GetId()
+		}
+	}
+}
+
+func (self *SVpcPeering) SyncWithCloudVpcPeering(ctx context.Context, userCred mcclient.TokenCredential, extPeering cloudprovider.ICloudVpcPeering) error {
+	diff, err := db.UpdateWithLock(ctx, self, func() error {
+		self.syncWithCloud(extPeering)
+		return nil
+	})
+	if err != nil {
+		log.Errorf("SyncWithCloudVpcPeering fail %s", err)
+		return err
+	}
+	db.OpsLog.LogSyncUpdate(self, diff, userCred)
+	return nil
+}
+
+func (manager *SVpcPeeringManager) newFromCloudVpcPeering(ctx context.Context, userCred mcclient.TokenCredential, provider *SCloudprovider, vpc *SVpc, extPeering cloudprovider.ICloudVpcPeering) (*SVpcPeering, error) {
+	peering := SVpcPeering{}
+	peering.SetModelManager(manager)
+
+	peering.Name = db.GenerateName(manager, provider.ProjectId, extPeering.GetName())
+	peering.ManagerId = provider.Id
+	peering.VpcId = vpc.Id
+	peering.CloudregionId = vpc.CloudregionId
+	peering.syncWithCloud(extPeering)
+	peering.ProjectId = provider.ProjectId
+	if len(peering.ProjectId) == 0 {
+		peering.ProjectId = userCred.GetProjectId()
+	}
+
+	err := manager.TableSpec().Insert(&peering)
+	if err != nil {
+		log.Errorf("newFromCloudVpcPeering fail %s", err)
+		return nil, err
+	}
+
+	db.OpsLog.LogEvent(&peering, db.ACT_CREATE, peering.GetShortDesc(ctx), userCred)
+	return &peering, nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/lockman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/quotas"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
 	"yunion.io/x/onecloud/pkg/cloudprovider"
 	"yunion.io/x/onecloud/pkg/httperrors"
@@ -166,10 +167,33 @@ func (self *SVpc) GetRouteTableCount() int {
 	return self.GetRouteTableQuery().Count()
 }
 
+func (self *SVpc) GetNatgatewayQuery() *sqlchemy.SQuery {
+	return NatGatewayManager.Query().Equals("vpc_id", self.Id)
+}
+
+func (self *SVpc) GetNatgateways() []SNatGateway {
+	q := self.GetNatgatewayQuery()
+	natgateways := []SNatGateway{}
+	db.FetchModelObjects(NatGatewayManager, q, &natgateways)
+	return natgateways
+}
+
+func (self *SVpc) GetNatgatewayCount() int {
+	return self.GetNatgatewayQuery().Count()
+}
+
+func (self *SVpc) GetVpcPeeringCount() int {
+	q := VpcPeeringManager.Query()
+	q = q.Filter(sqlchemy.OR(sqlchemy.Equals(q.Field("vpc_id"), self.Id), sqlchemy.Equals(q.Field("peer_vpc_id"), self.Id)))
+	return q.Count()
+}
+
 func (self *SVpc) getMoreDetails(extra *jsonutils.JSONDict) *jsonutils.JSONDict {
 	extra.Add(jsonutils.NewInt(int64(self.GetWireCount())), "wire_count")
 	extra.Add(jsonutils.NewInt(int64(self.GetNetworkCount())), "network_count")
 	extra.Add(jsonutils.NewInt(int64(self.GetRouteTableCount())), "routetable_count")
+	extra.Add(jsonutils.NewInt(int64(self.GetNatgatewayCount())), "natgateway_count")
+	extra.Add(jsonutils.NewInt(int64(self.GetVpcPeeringCount())), "vpcpeering_count")
 	/* region, err := self.GetRegion()
 	if err != nil {
 		log.Errorf("failed getting region for vpc %s(%s)", self.Name, self.Id)
@@ -426,7 +450,7 @@ func (manager *SVpcManager) ValidateCreateData(ctx context.Context, userCred mcc
 			return nil, httperrors.NewMissingParameterError("manager_id")
 		}
 		managerObj := CloudproviderManager.FetchCloudproviderByIdOrName(managerStr)
-		if err != nil {
+		if managerObj == nil {
 			return nil, httperrors.NewResourceNotFoundError("Cloud provider/manager %s not found", managerStr)
 		}
 		data.Add(jsonutils.NewString(managerObj.GetId()), "manager_id")
@@ -441,13 +465,61 @@ func (manager *SVpcManager) ValidateCreateData(ctx context.Context, userCred mcc
 			return nil, httperrors.NewInputParameterError("invalid cidr_block %s", cidrBlock)
 		}
 	}
-	return manager.SEnabledStatusStandaloneResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+	data, err = manager.SEnabledStatusStandaloneResourceBaseManager.ValidateCreateData(ctx, userCred, ownerProjId, query, data)
+	if err != nil {
+		return nil, err
+	}
+	vpc := &SVpc{}
+	data.Unmarshal(vpc)
+	pendingUsage := SQuota{Vpc: 1}
+	if err := QuotaManager.CheckSetPendingScopedQuota(ctx, userCred, vpc.getQuotaProjectId(), vpc.getQuotaScopes(), &pendingUsage); err != nil {
+		return nil, httperrors.NewOutOfQuotaError("%s", err)
+	}
+	return data, nil
+}
+
+// getQuotaProjectId returns the project whose vpc quota the vpc counts
+// against, which is the project of its cloud provider
+func (self *SVpc) getQuotaProjectId() string {
+	provider := CloudproviderManager.FetchCloudproviderById(self.ManagerId)
+	if provider == nil {
+		return ""
+	}
+	return provider.GetOwnerProjectId()
+}
+
+func (self *SVpc) getQuotaScopes() []quotas.SQuotaScope {
+	return getQuotaScopes(self.CloudregionId, "")
+}
+
+func totalVpcCount(projectId string, scope quotas.SQuotaScope) int {
+	if len(scope.ZoneId) > 0 {
+		// vpcs are not bound to a zone
+		return 0
+	}
+	q := VpcManager.Query()
+	providers := CloudproviderManager.Query().SubQuery()
+	q = q.Join(providers, sqlchemy.Equals(q.Field("manager_id"), providers.Field("id")))
+	q = q.Filter(sqlchemy.Equals(providers.Field("tenant_id"), projectId))
+	if len(scope.RegionId) > 0 {
+		q = q.Equals("cloudregion_id", scope.RegionId)
+	}
+	return q.Count()
+}
+
+func (manager *SVpcManager) OnCreateFailed(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
+	vpc := &SVpc{}
+	data.Unmarshal(vpc)
+	pendingUsage := SQuota{Vpc: 1}
+	QuotaManager.CancelPendingScopedUsage(ctx, userCred, vpc.getQuotaProjectId(), vpc.getQuotaScopes(), &pendingUsage, &pendingUsage)
 }
 
 func (self *SVpc) PostCreate(ctx context.Context, userCred mcclient.TokenCredential, ownerProjId string, query jsonutils.JSONObject, data jsonutils.JSONObject) {
 	if len(self.ManagerId) == 0 {
 		return
 	}
+	pendingUsage := SQuota{Vpc: 1}
+	QuotaManager.CancelPendingScopedUsage(ctx, userCred, self.getQuotaProjectId(), self.getQuotaScopes(), &pendingUsage, &pendingUsage)
 	task, err := taskman.TaskManager.NewTask(ctx, "VpcCreateTask", self, userCred, nil, "", "", nil)
 	if err != nil {
 		log.Errorf("VpcCreateTask newTask error %s", err)
@@ -517,6 +589,10 @@ func (self *SVpc) RealDelete(ctx context.Context, userCred mcclient.TokenCredent
 	for i := 0; i < len(routes); i++ {
 		routes[i].RealDelete(ctx, userCred)
 	}
+	natgateways := self.GetNatgateways()
+	for i := 0; i < len(natgateways); i++ {
+		natgateways[i].Purge(ctx, userCred)
+	}
 	return self.SEnabledStatusStandaloneResourceBase.Delete(ctx, userCred)
 }
 
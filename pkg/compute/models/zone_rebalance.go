@@ -0,0 +1,67 @@
+package models
+
+import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	schedapi "yunion.io/x/onecloud/pkg/apis/scheduler"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/compute/options"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/mcclient/auth"
+	"yunion.io/x/onecloud/pkg/mcclient/modules"
+)
+
+func (self *SZone) AllowPerformRebalance(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) bool {
+	return db.IsAdminAllowPerform(userCred, self, "rebalance")
+}
+
+// PerformRebalance asks the scheduler for a live migration plan evening out
+// the load of the hosts of the zone, the plan is carried out when execute is set
+func (self *SZone) PerformRebalance(ctx context.Context, userCred mcclient.TokenCredential, query jsonutils.JSONObject, data jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	input := &schedapi.RebalanceInput{ZoneId: self.Id}
+	if data.Contains("max_migrations") {
+		maxMigrations, err := data.Int("max_migrations")
+		if err != nil || maxMigrations <= 0 {
+			return nil, httperrors.NewInputParameterError("invalid max_migrations")
+		}
+		input.MaxMigrations = int(maxMigrations)
+	}
+	if data.Contains("threshold") {
+		threshold, err := data.Float("threshold")
+		if err != nil || threshold <= 0 || threshold >= 1 {
+			return nil, httperrors.NewInputParameterError("threshold should be between 0 and 1")
+		}
+		input.Threshold = threshold
+	}
+
+	s := auth.GetAdminSession(ctx, options.Options.Region, "")
+	plan, err := modules.SchedManager.Rebalance(s, input)
+	if err != nil {
+		return nil, httperrors.NewGeneralError(err)
+	}
+	if jsonutils.QueryBoolean(data, "execute", false) && len(plan.Migrations) > 0 {
+		err = self.StartRebalanceTask(ctx, userCred, plan.Migrations, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return jsonutils.Marshal(plan), nil
+}
+
+func (self *SZone) StartRebalanceTask(ctx context.Context, userCred mcclient.TokenCredential, migrations []*schedapi.RebalanceMigration, parentTaskId string) error {
+	params := jsonutils.NewDict()
+	params.Set("migrations", jsonutils.Marshal(migrations))
+	task, err := taskman.TaskManager.NewTask(ctx, "ZoneRebalanceTask", self, userCred, params, parentTaskId, "", nil)
+	if err != nil {
+		log.Errorf("start ZoneRebalanceTask: %v", err)
+		return err
+	}
+	db.OpsLog.LogEvent(self, db.ACT_REBALANCING, params, userCred)
+	task.ScheduleRun(nil)
+	return nil
+}
@@ -29,6 +29,12 @@ type ComputeOptions struct {
 
 	LoadbalancerPendingDeleteCheckInterval int `default:"3600" help:"Interval between checks of pending deleted loadbalancer objects, defaults to 1h"`
 
+	LoadbalancerAcmeDirectoryUrl       string `default:"https://acme-v02.api.letsencrypt.org/directory" help:"ACME directory url for issuing loadbalancer certificates"`
+	LoadbalancerAcmeIssueTimeout       int    `default:"600" help:"Timeout in seconds of issuing a loadbalancer certificate through ACME, defaults to 10m"`
+	LoadbalancerAcmeRenewDays          int    `default:"30" help:"Renew ACME loadbalancer certificates this many days before expiration, defaults to 30"`
+	LoadbalancerAcmeRenewCheckInterval int    `default:"3600" help:"Interval between checks of ACME loadbalancer certificates to renew, defaults to 1h"`
+	LoadbalancerBackendDrainTimeout    int    `default:"300" help:"Max seconds to wait for connections to finish when deleting loadbalancer backends with drain, defaults to 5m"`
+
 	ImageCacheStoragePolicy string `default:"least_used" choices:"best_fit|least_used" help:"Policy to choose storage for image cache, best_fit or least_used"`
 	MetricsRetentionDays    int32  `default:"30" help:"Retention days for monitoring metrics in influxdb"`
 
@@ -48,6 +54,11 @@ type ComputeOptions struct {
 	DefaultIsolatedDeviceQuota int `default:"200" help:"Common isolated device quota per tenant, default 200"`
 	DefaultSnapshotQuota       int `default:"10" help:"Common snapshot quota per tenant, default 10"`
 
+	DefaultLoadbalancerQuota         int `default:"50" help:"Common loadbalancer quota per tenant, default 50"`
+	DefaultLoadbalancerListenerQuota int `default:"200" help:"Common loadbalancer listener quota per tenant, default 200"`
+	DefaultNetworkQuota              int `default:"500" help:"Common network quota per tenant, default 500"`
+	DefaultVpcQuota                  int `default:"20" help:"Common vpc quota per tenant, default 20"`
+
 	SystemAdminQuotaCheck bool `help:"Enable quota check for system admin, default False" default:"false"`
 
 	BaremetalPreparePackageUrl string `help:"Baremetal online register package"`
@@ -57,6 +68,9 @@ type ComputeOptions struct {
 	AutoSnapshotHour              int `default:"2" help:"What hour take sanpshot, default 02:00"`
 	DefaultMaxSnapshotCount       int `default:"9" help:"Per Disk max snapshot count, default 9"`
 	DefaultMaxManualSnapshotCount int `default:"2" help:"Per Disk max manual snapshot count, default 2"`
+	SnapshotPolicyCheckInterval   int `default:"60" help:"Interval to run snapshot policies, default 1 minute"`
+
+	ScalingGroupCheckInterval int `default:"60" help:"Interval to evaluate the rules of scaling groups, default 1 minute"`
 
 	// sku sync
 	SyncSkusDay  int `default:"1" help:"Days auto sync skus data, default 1 day"`
@@ -73,8 +87,9 @@ type ComputeOptions struct {
 
 	SnapshotCreateDiskProtocol string `help:"Snapshot create disk protocol" choices:"url|fuse" default:"fuse"`
 
-	HostOfflineMaxSeconds        int `help:"Maximal seconds interval that a host considered offline during which it did not ping region, default is 3 minues" default:"180"`
-	HostOfflineDetectionInterval int `help:"Interval to check offline hosts, defualt is half a minute" default:"30"`
+	HostOfflineMaxSeconds        int  `help:"Maximal seconds interval that a host considered offline during which it did not ping region, default is 3 minues" default:"180"`
+	HostOfflineDetectionInterval int  `help:"Interval to check offline hosts, defualt is half a minute" default:"30"`
+	EnableHostHa                 bool `help:"Fence offline kvm hosts by IPMI and restart their guests of ha_policy restart on other hosts" default:"false"`
 
 	MinimalIpAddrReusedIntervalSeconds int `help:"Minimal seconds when a release IP address can be reallocate" default:"30"`
 
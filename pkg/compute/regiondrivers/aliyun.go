@@ -116,6 +116,9 @@ func (self *SAliyunRegionDriver) ValidateCreateLoadbalancerBackendData(ctx conte
 }
 
 func (self *SAliyunRegionDriver) ValidateCreateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	if err := validateLoadbalancerListenerRuleActions(data, []string{api.LB_RULE_ACTION_FORWARD}); err != nil {
+		return nil, err
+	}
 	backendgroup, ok := backendGroup.(*models.SLoadbalancerBackendGroup)
 	if !ok {
 		return nil, httperrors.NewMissingParameterError("backend_group")
@@ -126,6 +129,14 @@ func (self *SAliyunRegionDriver) ValidateCreateLoadbalancerListenerRuleData(ctx
 	return data, nil
 }
 
+func (self *SAliyunRegionDriver) ValidateUpdateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, lbr *models.SLoadbalancerListenerRule, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	ruleData, backendGroup := loadbalancerListenerRuleUpdateData(data, lbr, backendGroup)
+	if _, err := self.ValidateCreateLoadbalancerListenerRuleData(ctx, userCred, ruleData, backendGroup); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
 func (self *SAliyunRegionDriver) ValidateCreateLoadbalancerListenerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
 	backendgroup, ok := backendGroup.(*models.SLoadbalancerBackendGroup)
 	if !ok {
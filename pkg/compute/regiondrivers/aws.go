@@ -1,7 +1,25 @@
 package regiondrivers
 
 import (
+	"context"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/utils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
 	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+const (
+	AWS_LB_SPEC_APPLICATION = "application"
+	AWS_LB_SPEC_NETWORK     = "network"
+	AWS_LB_SPEC_CLASSIC     = "classic"
 )
 
 type SAwsRegionDriver struct {
@@ -16,3 +34,132 @@ func init() {
 func (self *SAwsRegionDriver) GetProvider() string {
 	return models.CLOUD_PROVIDER_AWS
 }
+
+func (self *SAwsRegionDriver) ValidateCreateLoadbalancerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	loadbalancerSpec, _ := data.GetString("loadbalancer_spec")
+	if len(loadbalancerSpec) == 0 {
+		data.Set("loadbalancer_spec", jsonutils.NewString(AWS_LB_SPEC_APPLICATION))
+	} else if !utils.IsInStringArray(loadbalancerSpec, []string{AWS_LB_SPEC_APPLICATION, AWS_LB_SPEC_NETWORK}) {
+		return nil, httperrors.NewInputParameterError("Unsupport loadbalancer_spec %s, support %s、%s", loadbalancerSpec, AWS_LB_SPEC_APPLICATION, AWS_LB_SPEC_NETWORK)
+	}
+	return data, nil
+}
+
+func (self *SAwsRegionDriver) ValidateCreateLoadbalancerAclData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Aws loadbalancer not support acl, use security group instead")
+}
+
+func (self *SAwsRegionDriver) ValidateCreateLoadbalancerBackendGroupData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, lb *models.SLoadbalancer, backends []cloudprovider.SLoadbalancerBackend) (*jsonutils.JSONDict, error) {
+	if lb.LoadbalancerSpec == AWS_LB_SPEC_CLASSIC {
+		return nil, httperrors.NewUnsupportOperationError("Aws classic loadbalancer only has the default backend group")
+	}
+	return self.SManagedVirtualizationRegionDriver.ValidateCreateLoadbalancerBackendGroupData(ctx, userCred, data, lb, backends)
+}
+
+func (self *SAwsRegionDriver) ValidateDeleteLoadbalancerBackendGroupCondition(ctx context.Context, lbbg *models.SLoadbalancerBackendGroup) error {
+	if lbbg.Type == api.LB_BACKENDGROUP_TYPE_DEFAULT {
+		return httperrors.NewUnsupportOperationError("not allow to delete default backend group")
+	}
+	return nil
+}
+
+func (self *SAwsRegionDriver) ValidateCreateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	actions := []string{api.LB_RULE_ACTION_FORWARD, api.LB_RULE_ACTION_REDIRECT, api.LB_RULE_ACTION_FIXED_RESPONSE}
+	if err := validateLoadbalancerListenerRuleActions(data, actions); err != nil {
+		return nil, err
+	}
+	switch action, _ := data.GetString("action"); action {
+	case api.LB_RULE_ACTION_REDIRECT:
+		// aws只支持301及302重定向
+		if code, _ := data.Int("redirect_code"); code != 301 && code != 302 {
+			return nil, httperrors.NewUnsupportOperationError("Aws loadbalancer only support redirect code 301 or 302")
+		}
+		return data, nil
+	case api.LB_RULE_ACTION_FIXED_RESPONSE:
+		return data, nil
+	}
+	backendgroup, ok := backendGroup.(*models.SLoadbalancerBackendGroup)
+	if !ok {
+		return nil, httperrors.NewMissingParameterError("backend_group")
+	}
+	if backendgroup.Type != api.LB_BACKENDGROUP_TYPE_NORMAL {
+		return nil, httperrors.NewInputParameterError("backend group type must be normal")
+	}
+	return data, nil
+}
+
+func (self *SAwsRegionDriver) ValidateUpdateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, lbr *models.SLoadbalancerListenerRule, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	ruleData, backendGroup := loadbalancerListenerRuleUpdateData(data, lbr, backendGroup)
+	if _, err := self.ValidateCreateLoadbalancerListenerRuleData(ctx, userCred, ruleData, backendGroup); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (self *SAwsRegionDriver) validateLoadbalancerListenerData(data *jsonutils.JSONDict, lb *models.SLoadbalancer) error {
+	if aclStatus, _ := data.GetString("acl_status"); aclStatus == api.LB_BOOL_ON {
+		return httperrors.NewUnsupportOperationError("Aws loadbalancer not support acl, use security group instead")
+	}
+	if lb != nil {
+		listenerType, _ := data.GetString("listener_type")
+		var listenerTypes []string
+		switch lb.LoadbalancerSpec {
+		case AWS_LB_SPEC_APPLICATION:
+			listenerTypes = []string{api.LB_LISTENER_TYPE_HTTP, api.LB_LISTENER_TYPE_HTTPS}
+		case AWS_LB_SPEC_NETWORK:
+			listenerTypes = []string{api.LB_LISTENER_TYPE_TCP, api.LB_LISTENER_TYPE_UDP}
+		case AWS_LB_SPEC_CLASSIC:
+			listenerTypes = []string{api.LB_LISTENER_TYPE_TCP, api.LB_LISTENER_TYPE_HTTP, api.LB_LISTENER_TYPE_HTTPS}
+		}
+		if len(listenerType) > 0 && len(listenerTypes) > 0 && !utils.IsInStringArray(listenerType, listenerTypes) {
+			return httperrors.NewInputParameterError("%s loadbalancer only support %s listener", lb.LoadbalancerSpec, strings.Join(listenerTypes, ","))
+		}
+	}
+	keyV := map[string]validators.IValidator{
+		"sticky_session_cookie_timeout": validators.NewRangeValidator("sticky_session_cookie_timeout", 1, 604800),
+
+		"health_check_rise":     validators.NewRangeValidator("health_check_rise", 2, 10),
+		"health_check_fall":     validators.NewRangeValidator("health_check_fall", 2, 10),
+		"health_check_timeout":  validators.NewRangeValidator("health_check_timeout", 2, 120),
+		"health_check_interval": validators.NewRangeValidator("health_check_interval", 5, 300),
+	}
+	for _, v := range keyV {
+		if err := v.Validate(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *SAwsRegionDriver) ValidateCreateLoadbalancerListenerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	backendgroup, ok := backendGroup.(*models.SLoadbalancerBackendGroup)
+	if !ok {
+		return nil, httperrors.NewMissingParameterError("backend_group")
+	}
+	if err := self.validateLoadbalancerListenerData(data, backendgroup.GetLoadbalancer()); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (self *SAwsRegionDriver) ValidateUpdateLoadbalancerListenerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	var lb *models.SLoadbalancer
+	if backendgroup, ok := backendGroup.(*models.SLoadbalancerBackendGroup); ok {
+		lb = backendgroup.GetLoadbalancer()
+	}
+	if err := self.validateLoadbalancerListenerData(data, lb); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// AWS NAT网关需指定子网并绑定EIP
+func (self *SAwsRegionDriver) ValidateCreateNatGatewayData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	if networkId, _ := data.GetString("network_id"); len(networkId) == 0 {
+		return nil, httperrors.NewMissingParameterError("network")
+	}
+	if eipId, _ := data.GetString("eip_id"); len(eipId) == 0 {
+		return nil, httperrors.NewMissingParameterError("eip")
+	}
+	return data, nil
+}
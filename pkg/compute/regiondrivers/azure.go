@@ -1,7 +1,15 @@
 package regiondrivers
 
 import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
 )
 
 type SAzureRegionDriver struct {
@@ -16,3 +24,54 @@ func init() {
 func (self *SAzureRegionDriver) GetProvider() string {
 	return models.CLOUD_PROVIDER_AZURE
 }
+
+func (self *SAzureRegionDriver) ValidateCreateLoadbalancerAclData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Azure loadbalancer not support acl")
+}
+
+// 应用网关证书需要PFX格式及密码, 只支持同步
+func (self *SAzureRegionDriver) ValidateCreateLoadbalancerCertificateData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Azure application gateway certificate can only be synchronized")
+}
+
+func (self *SAzureRegionDriver) ValidateCreateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Azure loadbalancer not support listener rule")
+}
+func (self *SAzureRegionDriver) ValidateUpdateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, lbr *models.SLoadbalancerListenerRule, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Azure loadbalancer not support listener rule")
+}
+
+// 只支持创建四层负载均衡规则, 七层的应用网关只能同步
+func (self *SAzureRegionDriver) validateLoadbalancerListenerData(data *jsonutils.JSONDict) error {
+	if listenerType, _ := data.GetString("listener_type"); len(listenerType) > 0 && listenerType != api.LB_LISTENER_TYPE_TCP && listenerType != api.LB_LISTENER_TYPE_UDP {
+		return httperrors.NewUnsupportOperationError("Azure loadbalancer only support tcp or udp listener")
+	}
+	if scheduler, _ := data.GetString("scheduler"); len(scheduler) > 0 && scheduler != api.LB_SCHEDULER_SCH && scheduler != api.LB_SCHEDULER_TCH {
+		return httperrors.NewUnsupportOperationError("Azure loadbalancer only support sch or tch scheduler")
+	}
+	if aclStatus, _ := data.GetString("acl_status"); aclStatus == api.LB_BOOL_ON {
+		return httperrors.NewUnsupportOperationError("Azure loadbalancer not support acl")
+	}
+	return nil
+}
+
+func (self *SAzureRegionDriver) ValidateCreateLoadbalancerListenerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	if _, ok := backendGroup.(*models.SLoadbalancerBackendGroup); !ok {
+		return nil, httperrors.NewMissingParameterError("backend_group")
+	}
+	if err := self.validateLoadbalancerListenerData(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (self *SAzureRegionDriver) ValidateUpdateLoadbalancerListenerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	if err := self.validateLoadbalancerListenerData(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (self *SAzureRegionDriver) ValidateCreateNatGatewayData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Not support create nat gateway for %s", self.GetProvider())
+}
@@ -1,7 +1,13 @@
 package regiondrivers
 
 import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+
 	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
 )
 
 type SEsxiRegionDriver struct {
@@ -16,3 +22,7 @@ func init() {
 func (self *SEsxiRegionDriver) GetProvider() string {
 	return models.CLOUD_PROVIDER_VMWARE
 }
+
+func (self *SEsxiRegionDriver) ValidateCreateNatGatewayData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Not support create nat gateway for %s", self.GetProvider())
+}
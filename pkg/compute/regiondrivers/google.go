@@ -0,0 +1,40 @@
+package regiondrivers
+
+import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+type SGoogleRegionDriver struct {
+	SManagedVirtualizationRegionDriver
+}
+
+func init() {
+	driver := SGoogleRegionDriver{}
+	models.RegisterRegionDriver(&driver)
+}
+
+func (self *SGoogleRegionDriver) GetProvider() string {
+	return models.CLOUD_PROVIDER_GOOGLE
+}
+
+func (self *SGoogleRegionDriver) ValidateCreateLoadbalancerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Not support create loadbalancer for %s", self.GetProvider())
+}
+
+func (self *SGoogleRegionDriver) ValidateCreateLoadbalancerAclData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Not support create loadbalancer acl for %s", self.GetProvider())
+}
+
+func (self *SGoogleRegionDriver) ValidateCreateLoadbalancerCertificateData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Not support create loadbalancer certificate for %s", self.GetProvider())
+}
+
+func (self *SGoogleRegionDriver) ValidateCreateNatGatewayData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Not support create nat gateway for %s", self.GetProvider())
+}
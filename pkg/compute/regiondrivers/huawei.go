@@ -1,7 +1,16 @@
 package regiondrivers
 
 import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
 	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
 )
 
 type SHuaWeiRegionDriver struct {
@@ -16,3 +25,81 @@ func init() {
 func (self *SHuaWeiRegionDriver) GetProvider() string {
 	return models.CLOUD_PROVIDER_HUAWEI
 }
+
+func (self *SHuaWeiRegionDriver) ValidateCreateLoadbalancerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	if addressType, _ := data.GetString("address_type"); addressType == api.LB_ADDR_TYPE_INTERNET {
+		return nil, httperrors.NewUnsupportOperationError("Huawei loadbalancer only support intranet address, bind an eip to vip port for internet access")
+	}
+	return data, nil
+}
+
+// 白名单属于监听器, 只能通过监听器的访问控制创建
+func (self *SHuaWeiRegionDriver) ValidateCreateLoadbalancerAclData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Huawei loadbalancer acl is the whitelist of listener, create it with listener")
+}
+
+func (self *SHuaWeiRegionDriver) ValidateCreateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	if err := validateLoadbalancerListenerRuleActions(data, []string{api.LB_RULE_ACTION_FORWARD}); err != nil {
+		return nil, err
+	}
+	if _, ok := backendGroup.(*models.SLoadbalancerBackendGroup); !ok {
+		return nil, httperrors.NewMissingParameterError("backend_group")
+	}
+	return data, nil
+}
+
+func (self *SHuaWeiRegionDriver) ValidateUpdateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, lbr *models.SLoadbalancerListenerRule, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	ruleData, backendGroup := loadbalancerListenerRuleUpdateData(data, lbr, backendGroup)
+	if _, err := self.ValidateCreateLoadbalancerListenerRuleData(ctx, userCred, ruleData, backendGroup); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (self *SHuaWeiRegionDriver) validateLoadbalancerListenerData(data *jsonutils.JSONDict) error {
+	// 后端服务器组统一为HTTP协议, 只能被HTTP及HTTPS监听使用
+	if listenerType, _ := data.GetString("listener_type"); listenerType == api.LB_LISTENER_TYPE_TCP || listenerType == api.LB_LISTENER_TYPE_UDP {
+		return httperrors.NewUnsupportOperationError("Huawei loadbalancer backend group only support http or https listener")
+	}
+	if aclStatus, _ := data.GetString("acl_status"); aclStatus == api.LB_BOOL_ON {
+		if aclType, _ := data.GetString("acl_type"); aclType != api.LB_ACL_TYPE_WHITE {
+			return httperrors.NewUnsupportOperationError("Huawei loadbalancer only support whitelist acl")
+		}
+	}
+	keyV := map[string]validators.IValidator{
+		"health_check_rise":     validators.NewRangeValidator("health_check_rise", 1, 10),
+		"health_check_timeout":  validators.NewRangeValidator("health_check_timeout", 1, 50),
+		"health_check_interval": validators.NewRangeValidator("health_check_interval", 1, 50),
+	}
+	for _, v := range keyV {
+		if err := v.Validate(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *SHuaWeiRegionDriver) ValidateCreateLoadbalancerListenerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	if _, ok := backendGroup.(*models.SLoadbalancerBackendGroup); !ok {
+		return nil, httperrors.NewMissingParameterError("backend_group")
+	}
+	if err := self.validateLoadbalancerListenerData(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (self *SHuaWeiRegionDriver) ValidateUpdateLoadbalancerListenerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	if err := self.validateLoadbalancerListenerData(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// 华为云NAT网关需指定子网
+func (self *SHuaWeiRegionDriver) ValidateCreateNatGatewayData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	if networkId, _ := data.GetString("network_id"); len(networkId) == 0 {
+		return nil, httperrors.NewMissingParameterError("network")
+	}
+	return data, nil
+}
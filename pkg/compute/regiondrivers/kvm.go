@@ -3,19 +3,26 @@ package regiondrivers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"yunion.io/x/jsonutils"
 	"yunion.io/x/log"
 	"yunion.io/x/pkg/utils"
 
 	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/appsrv"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
 	"yunion.io/x/onecloud/pkg/cloudprovider"
 	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/compute/options"
+	"yunion.io/x/onecloud/pkg/httperrors"
 	"yunion.io/x/onecloud/pkg/mcclient"
 )
 
+// 等待后端连接结束可能需要数分钟
+var lbbDrainWorkerMan = appsrv.NewWorkerManager("LoadbalancerBackendDrainWorkerManager", 4, 1024, false)
+
 type SKVMRegionDriver struct {
 	SBaseRegionDriver
 }
@@ -94,6 +101,9 @@ func (self *SKVMRegionDriver) ValidateCreateLoadbalancerBackendData(ctx context.
 func (self *SKVMRegionDriver) ValidateCreateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
 	return data, nil
 }
+func (self *SKVMRegionDriver) ValidateUpdateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, lbr *models.SLoadbalancerListenerRule, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	return data, nil
+}
 
 func (self *SKVMRegionDriver) ValidateCreateLoadbalancerListenerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
 	return data, nil
@@ -224,7 +234,21 @@ func (self *SKVMRegionDriver) ValidateDeleteLoadbalancerBackendGroupCondition(ct
 }
 
 func (self *SKVMRegionDriver) RequestDeleteLoadbalancerBackend(ctx context.Context, userCred mcclient.TokenCredential, lbb *models.SLoadbalancerBackend, task taskman.ITask) error {
-	task.ScheduleRun(nil)
+	if !jsonutils.QueryBoolean(task.GetParams(), "drain", false) {
+		task.ScheduleRun(nil)
+		return nil
+	}
+	if err := lbb.StartDrain(ctx, userCred); err != nil {
+		return err
+	}
+	taskman.LocalTaskRunWithWorkers(task, func() (jsonutils.JSONObject, error) {
+		timeout := time.Duration(options.Options.LoadbalancerBackendDrainTimeout) * time.Second
+		if err := lbb.WaitDrained(ctx, timeout); err != nil {
+			// 超时后仍然删除
+			log.Warningf("loadbalancer backend %s(%s) drain: %s, deleting anyway", lbb.Name, lbb.Id, err)
+		}
+		return nil, nil
+	}, lbbDrainWorkerMan)
 	return nil
 }
 
@@ -273,3 +297,7 @@ func (self *SKVMRegionDriver) RequestDeleteLoadbalancerListenerRule(ctx context.
 	task.ScheduleRun(nil)
 	return nil
 }
+
+func (self *SKVMRegionDriver) ValidateCreateNatGatewayData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Not support create nat gateway for %s", self.GetProvider())
+}
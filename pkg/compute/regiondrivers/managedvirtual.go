@@ -47,6 +47,10 @@ func (self *SManagedVirtualizationRegionDriver) ValidateCreateLoadbalancerAclDat
 }
 
 func (self *SManagedVirtualizationRegionDriver) ValidateCreateLoadbalancerCertificateData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	// 公有云证书需上传, 不支持ACME自动签发
+	if mode, _ := data.GetString("certificate_mode"); mode == api.LB_CERT_MODE_ACME {
+		return nil, httperrors.NewUnsupportOperationError("acme certificate is not supported by managed region")
+	}
 	return data, nil
 }
 
@@ -89,7 +93,46 @@ func (self *SManagedVirtualizationRegionDriver) ValidateCreateLoadbalancerBacken
 	return data, nil
 }
 
+// 规则动作及请求改写仅部分平台支持, 其余平台只能转发到后端服务器组
+func validateLoadbalancerListenerRuleActions(data *jsonutils.JSONDict, actions []string) error {
+	if action, _ := data.GetString("action"); !utils.IsInStringArray(action, actions) {
+		return httperrors.NewUnsupportOperationError("loadbalancer listener rule action %s is not supported", action)
+	}
+	if rewritePath, _ := data.GetString("rewrite_path"); len(rewritePath) > 0 {
+		return httperrors.NewUnsupportOperationError("loadbalancer listener rule rewrite_path is not supported")
+	}
+	for _, key := range []string{"request_header_actions", "response_header_actions"} {
+		if headerActions, _ := data.GetArray(key); len(headerActions) > 0 {
+			return httperrors.NewUnsupportOperationError("loadbalancer listener rule %s is not supported", key)
+		}
+	}
+	return nil
+}
+
 func (self *SManagedVirtualizationRegionDriver) ValidateCreateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	if err := validateLoadbalancerListenerRuleActions(data, []string{api.LB_RULE_ACTION_FORWARD}); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// 更新规则时未指定的字段取规则当前值, 以便沿用创建时的校验
+func loadbalancerListenerRuleUpdateData(data *jsonutils.JSONDict, lbr *models.SLoadbalancerListenerRule, backendGroup db.IModel) (*jsonutils.JSONDict, db.IModel) {
+	ruleData := jsonutils.Marshal(lbr).(*jsonutils.JSONDict)
+	ruleData.Update(data)
+	if backendGroup == nil && len(lbr.BackendGroupId) > 0 {
+		if lbbg := lbr.GetLoadbalancerBackendGroup(); lbbg != nil {
+			backendGroup = lbbg
+		}
+	}
+	return ruleData, backendGroup
+}
+
+func (self *SManagedVirtualizationRegionDriver) ValidateUpdateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, lbr *models.SLoadbalancerListenerRule, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	ruleData, backendGroup := loadbalancerListenerRuleUpdateData(data, lbr, backendGroup)
+	if _, err := self.ValidateCreateLoadbalancerListenerRuleData(ctx, userCred, ruleData, backendGroup); err != nil {
+		return nil, err
+	}
 	return data, nil
 }
 
@@ -662,6 +705,14 @@ func (self *SManagedVirtualizationRegionDriver) RequestCreateLoadbalancerListene
 			Name:   lbr.Name,
 			Domain: lbr.Domain,
 			Path:   lbr.Path,
+
+			Priority:          lbr.Priority,
+			Action:            lbr.Action,
+			RedirectCode:      lbr.RedirectCode,
+			RedirectScheme:    lbr.RedirectScheme,
+			RedirectHost:      lbr.RedirectHost,
+			RedirectPath:      lbr.RedirectPath,
+			FixedResponseCode: lbr.FixedResponseCode,
 		}
 		if len(lbr.BackendGroupId) > 0 {
 			group := lbr.GetLoadbalancerBackendGroup()
@@ -719,3 +770,7 @@ func (self *SManagedVirtualizationRegionDriver) RequestDeleteLoadbalancerListene
 	})
 	return nil
 }
+
+func (self *SManagedVirtualizationRegionDriver) ValidateCreateNatGatewayData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return data, nil
+}
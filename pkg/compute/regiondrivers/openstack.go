@@ -1,7 +1,16 @@
 package regiondrivers
 
 import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/validators"
 	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
 )
 
 type SOpenStackRegionDriver struct {
@@ -16,3 +25,97 @@ func init() {
 func (self *SOpenStackRegionDriver) GetProvider() string {
 	return models.CLOUD_PROVIDER_OPENSTACK
 }
+
+func (self *SOpenStackRegionDriver) ValidateCreateLoadbalancerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	if addressType, _ := data.GetString("address_type"); addressType == api.LB_ADDR_TYPE_INTERNET {
+		return nil, httperrors.NewUnsupportOperationError("OpenStack loadbalancer only support intranet address, bind a floating ip to vip port for internet access")
+	}
+	return data, nil
+}
+
+func (self *SOpenStackRegionDriver) ValidateCreateLoadbalancerAclData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("OpenStack loadbalancer not support acl")
+}
+
+func (self *SOpenStackRegionDriver) ValidateUpdateLoadbalancerCertificateData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	if data.Contains("certificate") || data.Contains("private_key") {
+		return nil, httperrors.NewUnsupportOperationError("OpenStack not allow to change certificate")
+	}
+	return data, nil
+}
+
+func (self *SOpenStackRegionDriver) ValidateCreateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	actions := []string{api.LB_RULE_ACTION_FORWARD, api.LB_RULE_ACTION_REDIRECT, api.LB_RULE_ACTION_FIXED_RESPONSE}
+	if err := validateLoadbalancerListenerRuleActions(data, actions); err != nil {
+		return nil, err
+	}
+	switch action, _ := data.GetString("action"); action {
+	case api.LB_RULE_ACTION_REDIRECT:
+		// 重定向需要完整的URL
+		if redirectHost, _ := data.GetString("redirect_host"); len(redirectHost) == 0 {
+			return nil, httperrors.NewMissingParameterError("redirect_host")
+		}
+		return data, nil
+	case api.LB_RULE_ACTION_FIXED_RESPONSE:
+		// REJECT策略固定返回403
+		if code, _ := data.Int("fixed_response_code"); code != 403 {
+			return nil, httperrors.NewUnsupportOperationError("OpenStack loadbalancer only support fixed response code 403")
+		}
+		return data, nil
+	}
+	if _, ok := backendGroup.(*models.SLoadbalancerBackendGroup); !ok {
+		return nil, httperrors.NewMissingParameterError("backend_group")
+	}
+	return data, nil
+}
+
+func (self *SOpenStackRegionDriver) ValidateUpdateLoadbalancerListenerRuleData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, lbr *models.SLoadbalancerListenerRule, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	ruleData, backendGroup := loadbalancerListenerRuleUpdateData(data, lbr, backendGroup)
+	if _, err := self.ValidateCreateLoadbalancerListenerRuleData(ctx, userCred, ruleData, backendGroup); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (self *SOpenStackRegionDriver) validateLoadbalancerListenerData(data *jsonutils.JSONDict) error {
+	if aclStatus, _ := data.GetString("acl_status"); aclStatus == api.LB_BOOL_ON {
+		return httperrors.NewUnsupportOperationError("OpenStack loadbalancer not support acl")
+	}
+	// 后端服务器组统一为HTTP协议, 不能被UDP监听使用
+	if listenerType, _ := data.GetString("listener_type"); listenerType == api.LB_LISTENER_TYPE_UDP {
+		return httperrors.NewUnsupportOperationError("OpenStack loadbalancer not support udp listener")
+	}
+	keyV := map[string]validators.IValidator{
+		"health_check_rise":     validators.NewRangeValidator("health_check_rise", 1, 10),
+		"health_check_fall":     validators.NewRangeValidator("health_check_fall", 1, 10),
+		"health_check_timeout":  validators.NewRangeValidator("health_check_timeout", 1, 300),
+		"health_check_interval": validators.NewRangeValidator("health_check_interval", 1, 300),
+	}
+	for _, v := range keyV {
+		if err := v.Validate(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *SOpenStackRegionDriver) ValidateCreateLoadbalancerListenerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	if _, ok := backendGroup.(*models.SLoadbalancerBackendGroup); !ok {
+		return nil, httperrors.NewMissingParameterError("backend_group")
+	}
+	if err := self.validateLoadbalancerListenerData(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (self *SOpenStackRegionDriver) ValidateUpdateLoadbalancerListenerData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict, backendGroup db.IModel) (*jsonutils.JSONDict, error) {
+	if err := self.validateLoadbalancerListenerData(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (self *SOpenStackRegionDriver) ValidateCreateNatGatewayData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error) {
+	return nil, httperrors.NewUnsupportOperationError("Not support create nat gateway for %s", self.GetProvider())
+}
@@ -35,6 +35,7 @@ func InitHandlers(app *appsrv.Application) {
 		models.GuestcdromManager,
 		models.NetInterfaceManager,
 		models.VCenterManager,
+		models.ServertemplateVersionManager,
 	} {
 		db.RegisterModelManager(manager)
 	}
@@ -69,6 +70,7 @@ func InitHandlers(app *appsrv.Application) {
 		models.DnsRecordManager,
 		models.ElasticipManager,
 		models.SnapshotManager,
+		models.SnapshotPolicyManager,
 		models.BaremetalagentManager,
 		models.LoadbalancerManager,
 		models.LoadbalancerListenerManager,
@@ -79,11 +81,19 @@ func InitHandlers(app *appsrv.Application) {
 		models.LoadbalancerAclManager,
 		models.LoadbalancerAgentManager,
 		models.RouteTableManager,
+		models.NatGatewayManager,
+		models.NatSEntryManager,
+		models.NatDEntryManager,
+		models.BucketManager,
+		models.DBInstanceManager,
+		models.VpcPeeringManager,
 
 		models.SchedpolicyManager,
 		models.DynamicschedtagManager,
 
 		models.ServerSkuManager,
+		models.ServertemplateManager,
+		models.ScalingRuleManager,
 		models.ExternalProjectManager,
 	} {
 		db.RegisterModelManager(manager)
@@ -105,6 +115,8 @@ func InitHandlers(app *appsrv.Application) {
 		models.GroupguestManager,
 		models.StoragecachedimageManager,
 		models.CloudproviderRegionManager,
+		models.SnapshotPolicyDiskManager,
+		models.SnapshotPolicyGuestManager,
 	} {
 		db.RegisterModelManager(manager)
 		// log.Infof("Register handler %s", manager.KeywordPlural())
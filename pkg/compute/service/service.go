@@ -24,6 +24,7 @@ import (
 	_ "yunion.io/x/onecloud/pkg/util/aws/provider"
 	_ "yunion.io/x/onecloud/pkg/util/azure/provider"
 	_ "yunion.io/x/onecloud/pkg/util/esxi/provider"
+	_ "yunion.io/x/onecloud/pkg/util/google/provider"
 	_ "yunion.io/x/onecloud/pkg/util/huawei/provider"
 	_ "yunion.io/x/onecloud/pkg/util/openstack/provider"
 	_ "yunion.io/x/onecloud/pkg/util/qcloud/provider"
@@ -72,7 +73,10 @@ func StartService() {
 	cron.AddJob1("CleanPendingDeleteServers", time.Duration(opts.PendingDeleteCheckSeconds)*time.Second, models.GuestManager.CleanPendingDeleteServers)
 	cron.AddJob1("CleanPendingDeleteDisks", time.Duration(opts.PendingDeleteCheckSeconds)*time.Second, models.DiskManager.CleanPendingDeleteDisks)
 	cron.AddJob1("CleanPendingDeleteLoadbalancers", time.Duration(opts.LoadbalancerPendingDeleteCheckInterval)*time.Second, models.LoadbalancerAgentManager.CleanPendingDeleteLoadbalancers)
+	cron.AddJob1("AutoRenewLoadbalancerAcmeCertificates", time.Duration(opts.LoadbalancerAcmeRenewCheckInterval)*time.Second, models.LoadbalancerCertificateManager.AutoRenewAcmeCertificates)
 	cron.AddJob1("CleanExpiredPrepaidServers", time.Duration(opts.PrepaidExpireCheckSeconds)*time.Second, models.GuestManager.DeleteExpiredPrepaidServers)
+	cron.AddJob1("ExecuteSnapshotPolicies", time.Duration(opts.SnapshotPolicyCheckInterval)*time.Second, models.SnapshotPolicyManager.ExecuteSnapshotPolicies)
+	cron.AddJob1("ExecuteScalingGroups", time.Duration(opts.ScalingGroupCheckInterval)*time.Second, models.GroupManager.ExecuteScalingGroups)
 	cron.AddJob1("StartHostPingDetectionTask", time.Duration(opts.HostOfflineDetectionInterval)*time.Second, models.HostManager.PingDetectionTask)
 
 	cron.AddJob1WithStartRun("AutoSyncCloudaccountTask", time.Duration(opts.CloudAutoSyncIntervalSeconds)*time.Second, models.CloudaccountManager.AutoSyncCloudaccountTask, true)
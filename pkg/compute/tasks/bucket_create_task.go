@@ -0,0 +1,58 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type BucketCreateTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(BucketCreateTask{})
+}
+
+func (self *BucketCreateTask) taskFail(ctx context.Context, bucket *models.SBucket, reason string) {
+	bucket.SetStatus(self.UserCred, api.BUCKET_STATUS_CREATE_FAILED, reason)
+	db.OpsLog.LogEvent(bucket, db.ACT_ALLOCATE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, bucket, logclient.ACT_CREATE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *BucketCreateTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	bucket := obj.(*models.SBucket)
+
+	iregion, err := bucket.GetIRegion()
+	if err != nil {
+		self.taskFail(ctx, bucket, fmt.Sprintf("fail to find region for bucket %s", err))
+		return
+	}
+	err = iregion.CreateIBucket(bucket.Name, bucket.StorageClass, bucket.Acl)
+	if err != nil {
+		self.taskFail(ctx, bucket, fmt.Sprintf("fail to create bucket %s", err))
+		return
+	}
+	ibucket, err := iregion.GetIBucketById(bucket.Name)
+	if err != nil {
+		self.taskFail(ctx, bucket, fmt.Sprintf("fail to find created bucket %s", err))
+		return
+	}
+	err = bucket.SyncWithCloudBucket(ctx, self.UserCred, ibucket)
+	if err != nil {
+		self.taskFail(ctx, bucket, fmt.Sprintf("fail to sync bucket %s", err))
+		return
+	}
+
+	db.OpsLog.LogEvent(bucket, db.ACT_ALLOCATE, bucket.GetShortDesc(ctx), self.UserCred)
+	logclient.AddActionLogWithStartable(self, bucket, logclient.ACT_CREATE, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
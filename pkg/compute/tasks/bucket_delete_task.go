@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type BucketDeleteTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(BucketDeleteTask{})
+}
+
+func (self *BucketDeleteTask) taskFail(ctx context.Context, bucket *models.SBucket, reason string) {
+	bucket.SetStatus(self.UserCred, api.BUCKET_STATUS_DELETE_FAILED, reason)
+	db.OpsLog.LogEvent(bucket, db.ACT_DELOCATE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, bucket, logclient.ACT_DELETE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *BucketDeleteTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	bucket := obj.(*models.SBucket)
+
+	if len(bucket.ExternalId) > 0 {
+		iregion, err := bucket.GetIRegion()
+		if err != nil {
+			if err != cloudprovider.ErrInvalidProvider {
+				self.taskFail(ctx, bucket, fmt.Sprintf("fail to find region for bucket %s", err))
+				return
+			}
+		} else {
+			err = iregion.DeleteIBucket(bucket.ExternalId)
+			if err != nil {
+				self.taskFail(ctx, bucket, fmt.Sprintf("fail to delete bucket %s", err))
+				return
+			}
+		}
+	}
+
+	err := bucket.RealDelete(ctx, self.UserCred)
+	if err != nil {
+		self.taskFail(ctx, bucket, fmt.Sprintf("fail to delete bucket %s", err))
+		return
+	}
+
+	logclient.AddActionLogWithStartable(self, bucket, logclient.ACT_DELETE, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
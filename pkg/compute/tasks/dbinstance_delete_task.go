@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type DBInstanceDeleteTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(DBInstanceDeleteTask{})
+}
+
+func (self *DBInstanceDeleteTask) taskFail(ctx context.Context, dbinstance *models.SDBInstance, reason string) {
+	dbinstance.SetStatus(self.UserCred, api.DBINSTANCE_STATUS_DELETE_FAILED, reason)
+	db.OpsLog.LogEvent(dbinstance, db.ACT_DELOCATE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, dbinstance, logclient.ACT_DELETE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *DBInstanceDeleteTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	dbinstance := obj.(*models.SDBInstance)
+
+	if len(dbinstance.ExternalId) > 0 {
+		iinstance, err := dbinstance.GetIDBInstance()
+		if err != nil {
+			if err != cloudprovider.ErrNotFound && err != cloudprovider.ErrInvalidProvider {
+				self.taskFail(ctx, dbinstance, fmt.Sprintf("fail to find dbinstance %s", err))
+				return
+			}
+		} else {
+			err = iinstance.Delete()
+			if err != nil {
+				self.taskFail(ctx, dbinstance, fmt.Sprintf("fail to delete dbinstance %s", err))
+				return
+			}
+		}
+	}
+
+	err := dbinstance.RealDelete(ctx, self.UserCred)
+	if err != nil {
+		self.taskFail(ctx, dbinstance, fmt.Sprintf("fail to delete dbinstance %s", err))
+		return
+	}
+
+	logclient.AddActionLogWithStartable(self, dbinstance, logclient.ACT_DELETE, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type DBInstanceStartTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(DBInstanceStartTask{})
+}
+
+func (self *DBInstanceStartTask) taskFail(ctx context.Context, dbinstance *models.SDBInstance, reason string) {
+	dbinstance.SetStatus(self.UserCred, api.DBINSTANCE_STATUS_START_FAILED, reason)
+	db.OpsLog.LogEvent(dbinstance, db.ACT_START_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, dbinstance, logclient.ACT_VM_START, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *DBInstanceStartTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	dbinstance := obj.(*models.SDBInstance)
+
+	iinstance, err := dbinstance.GetIDBInstance()
+	if err != nil {
+		self.taskFail(ctx, dbinstance, fmt.Sprintf("fail to find dbinstance %s", err))
+		return
+	}
+	err = iinstance.Start()
+	if err != nil {
+		if err == cloudprovider.ErrNotSupported {
+			err = fmt.Errorf("start dbinstance is not supported by %s", dbinstance.GetProviderName())
+		}
+		self.taskFail(ctx, dbinstance, fmt.Sprintf("fail to start dbinstance %s", err))
+		return
+	}
+	err = cloudprovider.WaitStatus(iinstance, api.DBINSTANCE_STATUS_RUNNING, 10*time.Second, 600*time.Second)
+	if err != nil {
+		self.taskFail(ctx, dbinstance, fmt.Sprintf("wait dbinstance start %s", err))
+		return
+	}
+
+	dbinstance.SetStatus(self.UserCred, api.DBINSTANCE_STATUS_RUNNING, "")
+	db.OpsLog.LogEvent(dbinstance, db.ACT_START, dbinstance.GetShortDesc(ctx), self.UserCred)
+	logclient.AddActionLogWithStartable(self, dbinstance, logclient.ACT_VM_START, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type DBInstanceStopTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(DBInstanceStopTask{})
+}
+
+func (self *DBInstanceStopTask) taskFail(ctx context.Context, dbinstance *models.SDBInstance, reason string) {
+	dbinstance.SetStatus(self.UserCred, api.DBINSTANCE_STATUS_STOP_FAILED, reason)
+	db.OpsLog.LogEvent(dbinstance, db.ACT_STOP_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, dbinstance, logclient.ACT_VM_STOP, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *DBInstanceStopTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	dbinstance := obj.(*models.SDBInstance)
+
+	iinstance, err := dbinstance.GetIDBInstance()
+	if err != nil {
+		self.taskFail(ctx, dbinstance, fmt.Sprintf("fail to find dbinstance %s", err))
+		return
+	}
+	err = iinstance.Stop()
+	if err != nil {
+		if err == cloudprovider.ErrNotSupported {
+			err = fmt.Errorf("stop dbinstance is not supported by %s", dbinstance.GetProviderName())
+		}
+		self.taskFail(ctx, dbinstance, fmt.Sprintf("fail to stop dbinstance %s", err))
+		return
+	}
+	err = cloudprovider.WaitStatus(iinstance, api.DBINSTANCE_STATUS_READY, 10*time.Second, 600*time.Second)
+	if err != nil {
+		self.taskFail(ctx, dbinstance, fmt.Sprintf("wait dbinstance stop %s", err))
+		return
+	}
+
+	dbinstance.SetStatus(self.UserCred, api.DBINSTANCE_STATUS_READY, "")
+	db.OpsLog.LogEvent(dbinstance, db.ACT_STOP, dbinstance.GetShortDesc(ctx), self.UserCred)
+	logclient.AddActionLogWithStartable(self, dbinstance, logclient.ACT_VM_STOP, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
@@ -8,6 +8,7 @@ import (
 	"yunion.io/x/log"
 
 	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/quotas"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
 	"yunion.io/x/onecloud/pkg/compute/models"
 )
@@ -42,7 +43,13 @@ func (self *EipAllocateTask) setGuestAllocateEipFailed(resion string) {
 func (self *EipAllocateTask) finalReleasePendingUsage(ctx context.Context) {
 	pendingUsage := models.SQuota{}
 	if err := self.GetPendingUsage(&pendingUsage); err == nil && !pendingUsage.IsEmpty() {
-		if err := models.QuotaManager.CancelPendingUsage(ctx, self.UserCred, self.UserCred.GetProjectId(), nil, &pendingUsage); err != nil {
+		// release exactly where the eip was reserved, eips of new servers are
+		// only reserved globally
+		scopes := []quotas.SQuotaScope{}
+		if self.Params.Contains("pending_quota_scopes") {
+			self.Params.Unmarshal(&scopes, "pending_quota_scopes")
+		}
+		if err := models.QuotaManager.CancelPendingScopedUsage(ctx, self.UserCred, self.UserCred.GetProjectId(), scopes, nil, &pendingUsage); err != nil {
 			log.Errorf("CancelPendingUsage error: %v", err)
 		}
 	}
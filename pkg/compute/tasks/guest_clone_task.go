@@ -0,0 +1,157 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+// GuestCloneTask snapshots the disks of a guest one by one, then creates
+// a new guest from the snapshots. On failure the clone guest and the
+// snapshots are removed.
+type GuestCloneTask struct {
+	SGuestBaseTask
+}
+
+func init() {
+	taskman.RegisterTask(GuestCloneTask{})
+}
+
+func (self *GuestCloneTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	guest := obj.(*models.SGuest)
+	self.StartDiskSnapshot(ctx, guest)
+}
+
+func (self *GuestCloneTask) StartDiskSnapshot(ctx context.Context, guest *models.SGuest) {
+	snapshotIds := jsonutils.GetQueryStringArray(self.Params, "snapshot_ids")
+	idx, _ := self.Params.Int("snapshot_index")
+	if int(idx) >= len(snapshotIds) {
+		self.StartCreateClone(ctx, guest)
+		return
+	}
+	iSnapshot, err := models.SnapshotManager.FetchById(snapshotIds[idx])
+	if err != nil {
+		self.TaskFailed(ctx, guest, fmt.Sprintf("fetch snapshot %s: %s", snapshotIds[idx], err))
+		return
+	}
+	snapshot := iSnapshot.(*models.SSnapshot)
+
+	params := jsonutils.NewDict()
+	params.Add(jsonutils.NewString(snapshot.DiskId), "disk_id")
+	params.Add(jsonutils.NewString(snapshot.Id), "snapshot_id")
+	self.SetStage("OnDiskSnapshotComplete", jsonutils.Marshal(map[string]int64{"snapshot_index": idx + 1}).(*jsonutils.JSONDict))
+	guest.SetStatus(self.UserCred, models.VM_START_SNAPSHOT, "GuestCloneTask")
+	task, err := taskman.TaskManager.NewTask(ctx, "GuestDiskSnapshotTask", guest, self.UserCred, params, self.GetTaskId(), "", nil)
+	if err != nil {
+		self.TaskFailed(ctx, guest, err.Error())
+		return
+	}
+	task.ScheduleRun(nil)
+}
+
+func (self *GuestCloneTask) OnDiskSnapshotComplete(ctx context.Context, guest *models.SGuest, data jsonutils.JSONObject) {
+	self.StartDiskSnapshot(ctx, guest)
+}
+
+func (self *GuestCloneTask) OnDiskSnapshotCompleteFailed(ctx context.Context, guest *models.SGuest, data jsonutils.JSONObject) {
+	self.TaskFailed(ctx, guest, data.String())
+}
+
+func (self *GuestCloneTask) StartCreateClone(ctx context.Context, guest *models.SGuest) {
+	name, _ := self.Params.GetString("name")
+	snapshotIds := jsonutils.GetQueryStringArray(self.Params, "snapshot_ids")
+	autoStart := jsonutils.QueryBoolean(self.Params, "auto_start", false)
+	input, err := guest.GetCloneCreateInput(name, snapshotIds, autoStart)
+	if err != nil {
+		self.TaskFailed(ctx, guest, err.Error())
+		return
+	}
+	self.SetStage("OnCloneCreateComplete", nil)
+	clone, err := guest.CreateClone(ctx, self.UserCred, input, self.GetTaskId())
+	if err != nil {
+		self.TaskFailed(ctx, guest, err.Error())
+		return
+	}
+	params := jsonutils.NewDict()
+	params.Add(jsonutils.NewString(clone.Id), "clone_id")
+	self.SaveParams(params)
+}
+
+func (self *GuestCloneTask) OnCloneCreateComplete(ctx context.Context, guest *models.SGuest, data jsonutils.JSONObject) {
+	cloneId, _ := self.Params.GetString("clone_id")
+	db.OpsLog.LogEvent(guest, db.ACT_CLONE, cloneId, self.UserCred)
+	logclient.AddActionLogWithStartable(self, guest, logclient.ACT_VM_CLONE, cloneId, self.UserCred, true)
+	ret := jsonutils.NewDict()
+	ret.Add(jsonutils.NewString(cloneId), "clone_id")
+	self.SetStageComplete(ctx, ret)
+}
+
+func (self *GuestCloneTask) OnCloneCreateCompleteFailed(ctx context.Context, guest *models.SGuest, data jsonutils.JSONObject) {
+	reason := data.String()
+	cloneId, _ := self.Params.GetString("clone_id")
+	if len(cloneId) == 0 {
+		self.TaskFailed(ctx, guest, reason)
+		return
+	}
+	iClone, err := models.GuestManager.FetchById(cloneId)
+	if err != nil {
+		self.TaskFailed(ctx, guest, reason)
+		return
+	}
+	clone := iClone.(*models.SGuest)
+	params := jsonutils.NewDict()
+	params.Add(jsonutils.NewString(reason), "reason")
+	self.SetStage("OnCloneDeleteComplete", params)
+	err = clone.StartDeleteGuestTask(ctx, self.UserCred, self.GetTaskId(), false, true)
+	if err != nil {
+		self.TaskFailed(ctx, guest, fmt.Sprintf("%s, delete clone %s fail: %s", reason, clone.Name, err))
+	}
+}
+
+func (self *GuestCloneTask) OnCloneDeleteComplete(ctx context.Context, guest *models.SGuest, data jsonutils.JSONObject) {
+	reason, _ := self.Params.GetString("reason")
+	self.TaskFailed(ctx, guest, reason)
+}
+
+func (self *GuestCloneTask) OnCloneDeleteCompleteFailed(ctx context.Context, guest *models.SGuest, data jsonutils.JSONObject) {
+	reason, _ := self.Params.GetString("reason")
+	self.TaskFailed(ctx, guest, fmt.Sprintf("%s, delete clone fail: %s", reason, data))
+}
+
+// cleanSnapshots drops the snapshots taken for the clone, those still used by disks are kept
+func (self *GuestCloneTask) cleanSnapshots(ctx context.Context) {
+	for _, snapshotId := range jsonutils.GetQueryStringArray(self.Params, "snapshot_ids") {
+		iSnapshot, err := models.SnapshotManager.FetchById(snapshotId)
+		if err != nil {
+			continue
+		}
+		snapshot := iSnapshot.(*models.SSnapshot)
+		if snapshot.RefCount > 0 {
+			log.Warningf("snapshot %s is still in use, skip cleaning", snapshot.Name)
+			continue
+		}
+		if snapshot.Status == models.SNAPSHOT_READY {
+			// the latest snapshot is the backing file of the disk, leave it to be purged later
+			err = snapshot.FakeDelete()
+		} else {
+			err = snapshot.RealDelete(ctx, self.UserCred)
+		}
+		if err != nil {
+			log.Errorf("clean snapshot %s fail: %s", snapshot.Name, err)
+		}
+	}
+}
+
+func (self *GuestCloneTask) TaskFailed(ctx context.Context, guest *models.SGuest, reason string) {
+	self.cleanSnapshots(ctx)
+	db.OpsLog.LogEvent(guest, db.ACT_CLONE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, guest, logclient.ACT_VM_CLONE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
@@ -146,7 +146,8 @@ func (self *GuestCreateTask) StartEipSubTask(ctx context.Context, guest *models.
 			log.Errorf("GetPendingUsage fail %s", err)
 		}
 		eipChargeType, _ := self.Params.GetString("eip_charge_type")
-		models.ElasticipManager.AllocateEipAndAssociateVM(ctx, self.UserCred, guest, int(eipBw), eipChargeType, &pendingUsage)
+		// the eip was reserved globally along with the server
+		models.ElasticipManager.AllocateEipAndAssociateVM(ctx, self.UserCred, guest, int(eipBw), eipChargeType, &pendingUsage, nil)
 		self.SetPendingUsage(&pendingUsage)
 	}
 }
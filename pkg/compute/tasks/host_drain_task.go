@@ -0,0 +1,132 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// HostDrainTask migrates the guests of a host in maintenance one by one, running
+// guests are live migrated and stopped ones cold migrated. The progress of every
+// guest is kept in the host metadata.
+type HostDrainTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(HostDrainTask{})
+}
+
+func (self *HostDrainTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	host := obj.(*models.SHost)
+	self.StartMigrateGuest(ctx, host)
+}
+
+func (self *HostDrainTask) StartMigrateGuest(ctx context.Context, host *models.SHost) {
+	progress, err := host.GetDrainProgress(self.UserCred)
+	if err != nil {
+		self.TaskFailed(ctx, host, err.Error())
+		return
+	}
+	guests := progress.Guests
+	for i := range guests {
+		item := &guests[i]
+		if item.Status != api.HOST_DRAIN_GUEST_PENDING {
+			continue
+		}
+		guest := models.GuestManager.FetchGuestById(item.GuestId)
+		if guest == nil || guest.HostId != host.Id {
+			item.Status = api.HOST_DRAIN_GUEST_SKIPPED
+			item.Reason = "guest is not on the host any more"
+			continue
+		}
+		err := self.startMigrate(ctx, guest, item)
+		if err != nil {
+			item.Status = api.HOST_DRAIN_GUEST_FAILED
+			item.Reason = err.Error()
+			continue
+		}
+		item.Status = api.HOST_DRAIN_GUEST_MIGRATING
+		host.SetDrainProgress(ctx, self.UserCred, api.HOST_DRAIN_STATUS_DRAINING, guests)
+		return
+	}
+	self.TaskComplete(ctx, host, guests)
+}
+
+func (self *HostDrainTask) startMigrate(ctx context.Context, guest *models.SGuest, item *api.HostDrainGuest) error {
+	self.SetStage("OnGuestMigrateComplete", jsonutils.Marshal(map[string]string{"guest_id": guest.Id}).(*jsonutils.JSONDict))
+	switch guest.Status {
+	case models.VM_RUNNING, models.VM_SUSPEND:
+		item.Action = api.HOST_DRAIN_ACTION_LIVE_MIGRATE
+		if err := guest.CheckLiveMigrate(ctx, self.UserCred); err != nil {
+			return err
+		}
+		return guest.StartGuestLiveMigrateTask(ctx, self.UserCred, guest.Status, "", self.GetTaskId())
+	case models.VM_READY:
+		item.Action = api.HOST_DRAIN_ACTION_MIGRATE
+		if guest.GetHypervisor() != models.HYPERVISOR_KVM {
+			return fmt.Errorf("Cannot migrate guest of hypervisor %s", guest.GetHypervisor())
+		}
+		if len(guest.GetIsolatedDevices()) > 0 {
+			return fmt.Errorf("Cannot migrate with isolated devices")
+		}
+		return guest.StartMigrateTask(ctx, self.UserCred, false, guest.Status, "", self.GetTaskId())
+	}
+	return fmt.Errorf("Cannot migrate guest in status %s", guest.Status)
+}
+
+func (self *HostDrainTask) OnGuestMigrateComplete(ctx context.Context, host *models.SHost, data jsonutils.JSONObject) {
+	self.setGuestStatus(ctx, host, api.HOST_DRAIN_GUEST_MIGRATED, "")
+}
+
+func (self *HostDrainTask) OnGuestMigrateCompleteFailed(ctx context.Context, host *models.SHost, data jsonutils.JSONObject) {
+	self.setGuestStatus(ctx, host, api.HOST_DRAIN_GUEST_FAILED, data.String())
+}
+
+func (self *HostDrainTask) setGuestStatus(ctx context.Context, host *models.SHost, status, reason string) {
+	progress, err := host.GetDrainProgress(self.UserCred)
+	if err != nil {
+		self.TaskFailed(ctx, host, err.Error())
+		return
+	}
+	guestId, _ := self.Params.GetString("guest_id")
+	for i := range progress.Guests {
+		if progress.Guests[i].GuestId == guestId {
+			progress.Guests[i].Status = status
+			progress.Guests[i].Reason = reason
+		}
+	}
+	host.SetDrainProgress(ctx, self.UserCred, api.HOST_DRAIN_STATUS_DRAINING, progress.Guests)
+	self.StartMigrateGuest(ctx, host)
+}
+
+func (self *HostDrainTask) TaskComplete(ctx context.Context, host *models.SHost, guests []api.HostDrainGuest) {
+	failed := 0
+	for i := range guests {
+		if guests[i].Status == api.HOST_DRAIN_GUEST_FAILED {
+			failed++
+		}
+	}
+	if failed > 0 {
+		host.SetDrainProgress(ctx, self.UserCred, api.HOST_DRAIN_STATUS_DRAIN_FAIL, guests)
+		reason := fmt.Sprintf("%d of %d guests failed to migrate", failed, len(guests))
+		db.OpsLog.LogEvent(host, db.ACT_DRAIN_FAIL, reason, self.UserCred)
+		self.SetStageFailed(ctx, reason)
+		return
+	}
+	host.SetDrainProgress(ctx, self.UserCred, api.HOST_DRAIN_STATUS_DRAINED, guests)
+	db.OpsLog.LogEvent(host, db.ACT_DRAIN, "", self.UserCred)
+	self.SetStageComplete(ctx, nil)
+}
+
+func (self *HostDrainTask) TaskFailed(ctx context.Context, host *models.SHost, reason string) {
+	host.SetMetadata(ctx, models.HOST_METADATA_DRAIN_STATUS, api.HOST_DRAIN_STATUS_DRAIN_FAIL, self.UserCred)
+	db.OpsLog.LogEvent(host, db.ACT_DRAIN_FAIL, reason, self.UserCred)
+	self.SetStageFailed(ctx, reason)
+}
@@ -0,0 +1,136 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/utils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// HostHaTask fences an offline host by IPMI and then restarts its ha guests
+// on other hosts one by one, in the order of their priority. No guest is
+// restarted unless the host is surely powered off.
+type HostHaTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(HostHaTask{})
+}
+
+func (self *HostHaTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	host := obj.(*models.SHost)
+	self.SetStage("OnFenceComplete", nil)
+	taskman.LocalTaskRun(self, func() (jsonutils.JSONObject, error) {
+		return nil, host.FenceByIpmi()
+	})
+}
+
+func (self *HostHaTask) OnFenceComplete(ctx context.Context, host *models.SHost, data jsonutils.JSONObject) {
+	db.OpsLog.LogEvent(host, db.ACT_FENCE, "", self.UserCred)
+	host.SetMetadata(ctx, models.HOST_METADATA_HA_STATUS, api.HOST_HA_STATUS_RESTARTING, self.UserCred)
+	self.StartRestartGuest(ctx, host)
+}
+
+func (self *HostHaTask) OnFenceCompleteFailed(ctx context.Context, host *models.SHost, data jsonutils.JSONObject) {
+	host.SetMetadata(ctx, models.HOST_METADATA_HA_STATUS, api.HOST_HA_STATUS_FENCE_FAIL, self.UserCred)
+	db.OpsLog.LogEvent(host, db.ACT_FENCE_FAIL, data, self.UserCred)
+	self.SetStageFailed(ctx, data.String())
+}
+
+func (self *HostHaTask) StartRestartGuest(ctx context.Context, host *models.SHost) {
+	guestIds := jsonutils.GetQueryStringArray(self.Params, "guest_ids")
+	idx, _ := self.Params.Int("guest_index")
+	for ; int(idx) < len(guestIds); idx++ {
+		guest := models.GuestManager.FetchGuestById(guestIds[idx])
+		if guest == nil || guest.HostId != host.Id {
+			log.Warningf("HostHaTask skip guest %s: not on host %s any more", guestIds[idx], host.Name)
+			continue
+		}
+		if !utils.IsInStringArray(guest.Status, []string{models.VM_UNKNOWN, models.VM_RUNNING}) {
+			// stopped or being operated on since the host went offline
+			log.Warningf("HostHaTask skip guest %s: in status %s", guest.Name, guest.Status)
+			continue
+		}
+		self.SetStage("OnGuestRestartComplete", jsonutils.Marshal(map[string]int64{"guest_index": idx + 1}).(*jsonutils.JSONDict))
+		db.OpsLog.LogEvent(guest, db.ACT_HA_RESTARTING, fmt.Sprintf("host %s offline", host.Name), self.UserCred)
+		err := guest.StartMigrateTask(ctx, self.UserCred, true, models.VM_READY, "", self.GetTaskId())
+		if err != nil {
+			self.guestFailed(guest, err.Error())
+			continue
+		}
+		return
+	}
+	self.TaskComplete(ctx, host)
+}
+
+func (self *HostHaTask) OnGuestRestartComplete(ctx context.Context, host *models.SHost, data jsonutils.JSONObject) {
+	guest := self.currentGuest()
+	if guest != nil {
+		db.OpsLog.LogEvent(guest, db.ACT_HA_RESTART, "", self.UserCred)
+		self.addGuest("restarted_guests", guest.Id)
+	}
+	self.StartRestartGuest(ctx, host)
+}
+
+func (self *HostHaTask) OnGuestRestartCompleteFailed(ctx context.Context, host *models.SHost, data jsonutils.JSONObject) {
+	guest := self.currentGuest()
+	if guest != nil {
+		// undeploying from the dead host always fails once the guest moved
+		if guest.HostId != host.Id {
+			db.OpsLog.LogEvent(guest, db.ACT_HA_RESTART, "", self.UserCred)
+			self.addGuest("restarted_guests", guest.Id)
+		} else {
+			self.guestFailed(guest, data.String())
+		}
+	}
+	self.StartRestartGuest(ctx, host)
+}
+
+// currentGuest returns the guest whose restart the task waits for
+func (self *HostHaTask) currentGuest() *models.SGuest {
+	guestIds := jsonutils.GetQueryStringArray(self.Params, "guest_ids")
+	idx, _ := self.Params.Int("guest_index")
+	if idx <= 0 || int(idx) > len(guestIds) {
+		return nil
+	}
+	return models.GuestManager.FetchGuestById(guestIds[idx-1])
+}
+
+func (self *HostHaTask) guestFailed(guest *models.SGuest, reason string) {
+	log.Errorf("HostHaTask restart guest %s failed: %s", guest.Name, reason)
+	db.OpsLog.LogEvent(guest, db.ACT_HA_RESTART_FAIL, reason, self.UserCred)
+	self.addGuest("failed_guests", guest.Id)
+}
+
+func (self *HostHaTask) addGuest(key, guestId string) {
+	guestIds := jsonutils.GetQueryStringArray(self.Params, key)
+	guestIds = append(guestIds, guestId)
+	params := jsonutils.NewDict()
+	params.Set(key, jsonutils.NewStringArray(guestIds))
+	self.SaveParams(params)
+}
+
+func (self *HostHaTask) TaskComplete(ctx context.Context, host *models.SHost) {
+	restarted := jsonutils.GetQueryStringArray(self.Params, "restarted_guests")
+	failed := jsonutils.GetQueryStringArray(self.Params, "failed_guests")
+	result := jsonutils.NewDict()
+	result.Set("restarted_guests", jsonutils.NewStringArray(restarted))
+	result.Set("failed_guests", jsonutils.NewStringArray(failed))
+	if len(failed) > 0 {
+		host.SetMetadata(ctx, models.HOST_METADATA_HA_STATUS, api.HOST_HA_STATUS_HA_FAIL, self.UserCred)
+		db.OpsLog.LogEvent(host, db.ACT_HA_RESTART_FAIL, result, self.UserCred)
+		self.SetStageFailed(ctx, result.String())
+		return
+	}
+	host.SetMetadata(ctx, models.HOST_METADATA_HA_STATUS, api.HOST_HA_STATUS_RESTARTED, self.UserCred)
+	db.OpsLog.LogEvent(host, db.ACT_HA_RESTART, result, self.UserCred)
+	self.SetStageComplete(ctx, result)
+}
@@ -0,0 +1,77 @@
+package tasks
+
+import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/appsrv"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudcommon/notifyclient"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+// acme issuance waits for lbagents and acme servers, which may take
+// minutes, so it runs with its own workers
+var lbcertAcmeWorkerMan = appsrv.NewWorkerManager("LoadbalancerCertificateAcmeWorkerManager", 2, 512, false)
+
+type LoadbalancerCertificateAcmeIssueTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(LoadbalancerCertificateAcmeIssueTask{})
+}
+
+func (self *LoadbalancerCertificateAcmeIssueTask) isRenew() bool {
+	return jsonutils.QueryBoolean(self.Params, "renew", false)
+}
+
+func (self *LoadbalancerCertificateAcmeIssueTask) taskFail(ctx context.Context, lbcert *models.SLoadbalancerCertificate, reason string) {
+	if err := lbcert.SetAcmeIssueResult(false); err != nil {
+		log.Errorf("lbcert %s(%s): record acme issue failure: %s", lbcert.Name, lbcert.Id, err)
+	}
+	if self.isRenew() {
+		// the old certificate is still in use
+		lbcert.SetStatus(self.GetUserCred(), api.LB_CERT_STATUS_RENEW_FAILED, reason)
+		db.OpsLog.LogEvent(lbcert, db.ACT_UPDATE_FAIL, reason, self.UserCred)
+		logclient.AddActionLogWithStartable(self, lbcert, logclient.ACT_UPDATE, reason, self.UserCred, false)
+		notifyclient.NotifySystemError(lbcert.Id, lbcert.Name, api.LB_CERT_STATUS_RENEW_FAILED, reason)
+	} else {
+		lbcert.SetStatus(self.GetUserCred(), api.LB_CREATE_FAILED, reason)
+		db.OpsLog.LogEvent(lbcert, db.ACT_ALLOCATE_FAIL, reason, self.UserCred)
+		logclient.AddActionLogWithStartable(self, lbcert, logclient.ACT_CREATE, reason, self.UserCred, false)
+		notifyclient.NotifySystemError(lbcert.Id, lbcert.Name, api.LB_CREATE_FAILED, reason)
+	}
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *LoadbalancerCertificateAcmeIssueTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	lbcert := obj.(*models.SLoadbalancerCertificate)
+	self.SetStage("OnAcmeIssueComplete", nil)
+	taskman.LocalTaskRunWithWorkers(self, func() (jsonutils.JSONObject, error) {
+		return nil, lbcert.AcmeIssue(ctx, self.GetUserCred())
+	}, lbcertAcmeWorkerMan)
+}
+
+func (self *LoadbalancerCertificateAcmeIssueTask) OnAcmeIssueComplete(ctx context.Context, lbcert *models.SLoadbalancerCertificate, data jsonutils.JSONObject) {
+	if err := lbcert.SetAcmeIssueResult(true); err != nil {
+		log.Errorf("lbcert %s(%s): record acme issue success: %s", lbcert.Name, lbcert.Id, err)
+	}
+	lbcert.SetStatus(self.GetUserCred(), api.LB_STATUS_ENABLED, "")
+	if self.isRenew() {
+		logclient.AddActionLogWithStartable(self, lbcert, logclient.ACT_UPDATE, nil, self.UserCred, true)
+	} else {
+		db.OpsLog.LogEvent(lbcert, db.ACT_ALLOCATE, lbcert.GetShortDesc(ctx), self.UserCred)
+		logclient.AddActionLogWithStartable(self, lbcert, logclient.ACT_CREATE, nil, self.UserCred, true)
+	}
+	self.SetStageComplete(ctx, nil)
+}
+
+func (self *LoadbalancerCertificateAcmeIssueTask) OnAcmeIssueCompleteFailed(ctx context.Context, lbcert *models.SLoadbalancerCertificate, reason jsonutils.JSONObject) {
+	self.taskFail(ctx, lbcert, reason.String())
+}
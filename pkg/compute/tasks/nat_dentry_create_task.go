@@ -0,0 +1,82 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type NatDEntryCreateTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(NatDEntryCreateTask{})
+}
+
+func (self *NatDEntryCreateTask) taskFail(ctx context.Context, entry *models.SNatDEntry, reason string) {
+	entry.SetStatus(self.UserCred, api.NAT_STATUS_CREATE_FAILED, reason)
+	db.OpsLog.LogEvent(entry, db.ACT_ALLOCATE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, entry, logclient.ACT_CREATE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *NatDEntryCreateTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	entry := obj.(*models.SNatDEntry)
+
+	natgateway, err := entry.GetNatgateway()
+	if err != nil {
+		self.taskFail(ctx, entry, fmt.Sprintf("fail to find natgateway %s", err))
+		return
+	}
+	inatgateway, err := natgateway.GetINatGateway()
+	if err != nil {
+		self.taskFail(ctx, entry, fmt.Sprintf("fail to find remote natgateway %s", err))
+		return
+	}
+
+	rule := cloudprovider.SNatDRule{
+		Name:         entry.Name,
+		Protocol:     entry.IpProtocol,
+		InternalIP:   entry.InternalIp,
+		InternalPort: entry.InternalPort,
+		ExternalIP:   entry.ExternalIp,
+		ExternalPort: entry.ExternalPort,
+	}
+	if eipId, _ := self.Params.GetString("eip_id"); len(eipId) > 0 {
+		eip, err := models.ElasticipManager.FetchById(eipId)
+		if err != nil {
+			self.taskFail(ctx, entry, fmt.Sprintf("fail to find eip %s: %s", eipId, err))
+			return
+		}
+		rule.ExternalIPID = eip.(*models.SElasticip).ExternalId
+	}
+
+	ientry, err := inatgateway.CreateINatDEntry(rule)
+	if err != nil {
+		self.taskFail(ctx, entry, fmt.Sprintf("fail to create dnat entry %s", err))
+		return
+	}
+
+	_, err = db.Update(entry, func() error {
+		entry.ExternalId = ientry.GetGlobalId()
+		entry.Status = api.NAT_STATUS_AVAILABLE
+		return nil
+	})
+	if err != nil {
+		self.taskFail(ctx, entry, fmt.Sprintf("fail to update dnat entry %s", err))
+		return
+	}
+
+	db.OpsLog.LogEvent(entry, db.ACT_ALLOCATE, entry.GetShortDesc(ctx), self.UserCred)
+	logclient.AddActionLogWithStartable(self, entry, logclient.ACT_CREATE, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type NatDEntryDeleteTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(NatDEntryDeleteTask{})
+}
+
+func (self *NatDEntryDeleteTask) taskFail(ctx context.Context, entry *models.SNatDEntry, reason string) {
+	entry.SetStatus(self.UserCred, api.NAT_STATUS_DELETE_FAILED, reason)
+	db.OpsLog.LogEvent(entry, db.ACT_DELOCATE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, entry, logclient.ACT_DELETE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *NatDEntryDeleteTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	entry := obj.(*models.SNatDEntry)
+
+	if len(entry.ExternalId) > 0 {
+		ientry, err := entry.GetINatDEntry()
+		if err != nil {
+			if err != cloudprovider.ErrNotFound && err != cloudprovider.ErrInvalidProvider {
+				self.taskFail(ctx, entry, fmt.Sprintf("fail to find dnat entry %s", err))
+				return
+			}
+		} else {
+			err = ientry.Delete()
+			if err != nil {
+				self.taskFail(ctx, entry, fmt.Sprintf("fail to delete dnat entry %s", err))
+				return
+			}
+		}
+	}
+
+	err := entry.RealDelete(ctx, self.UserCred)
+	if err != nil {
+		self.taskFail(ctx, entry, fmt.Sprintf("fail to delete dnat entry %s", err))
+		return
+	}
+
+	logclient.AddActionLogWithStartable(self, entry, logclient.ACT_DELETE, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
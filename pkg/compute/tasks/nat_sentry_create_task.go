@@ -0,0 +1,87 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type NatSEntryCreateTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(NatSEntryCreateTask{})
+}
+
+func (self *NatSEntryCreateTask) taskFail(ctx context.Context, entry *models.SNatSEntry, reason string) {
+	entry.SetStatus(self.UserCred, api.NAT_STATUS_CREATE_FAILED, reason)
+	db.OpsLog.LogEvent(entry, db.ACT_ALLOCATE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, entry, logclient.ACT_CREATE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *NatSEntryCreateTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	entry := obj.(*models.SNatSEntry)
+
+	natgateway, err := entry.GetNatgateway()
+	if err != nil {
+		self.taskFail(ctx, entry, fmt.Sprintf("fail to find natgateway %s", err))
+		return
+	}
+	inatgateway, err := natgateway.GetINatGateway()
+	if err != nil {
+		self.taskFail(ctx, entry, fmt.Sprintf("fail to find remote natgateway %s", err))
+		return
+	}
+
+	rule := cloudprovider.SNatSRule{
+		Name:       entry.Name,
+		SourceCIDR: entry.SourceCidr,
+		ExternalIP: entry.Ip,
+	}
+	if eipId, _ := self.Params.GetString("eip_id"); len(eipId) > 0 {
+		eip, err := models.ElasticipManager.FetchById(eipId)
+		if err != nil {
+			self.taskFail(ctx, entry, fmt.Sprintf("fail to find eip %s: %s", eipId, err))
+			return
+		}
+		rule.ExternalIPID = eip.(*models.SElasticip).ExternalId
+	}
+	if len(entry.NetworkId) > 0 {
+		network, err := models.NetworkManager.FetchById(entry.NetworkId)
+		if err != nil {
+			self.taskFail(ctx, entry, fmt.Sprintf("fail to find network %s: %s", entry.NetworkId, err))
+			return
+		}
+		rule.NetworkID = network.(*models.SNetwork).ExternalId
+	}
+
+	ientry, err := inatgateway.CreateINatSEntry(rule)
+	if err != nil {
+		self.taskFail(ctx, entry, fmt.Sprintf("fail to create snat entry %s", err))
+		return
+	}
+
+	_, err = db.Update(entry, func() error {
+		entry.ExternalId = ientry.GetGlobalId()
+		entry.Status = api.NAT_STATUS_AVAILABLE
+		return nil
+	})
+	if err != nil {
+		self.taskFail(ctx, entry, fmt.Sprintf("fail to update snat entry %s", err))
+		return
+	}
+
+	db.OpsLog.LogEvent(entry, db.ACT_ALLOCATE, entry.GetShortDesc(ctx), self.UserCred)
+	logclient.AddActionLogWithStartable(self, entry, logclient.ACT_CREATE, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
@@ -0,0 +1,101 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type NatGatewayCreateTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(NatGatewayCreateTask{})
+}
+
+func (self *NatGatewayCreateTask) taskFail(ctx context.Context, natgateway *models.SNatGateway, reason string) {
+	natgateway.SetStatus(self.UserCred, api.NAT_STATUS_CREATE_FAILED, reason)
+	db.OpsLog.LogEvent(natgateway, db.ACT_ALLOCATE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, natgateway, logclient.ACT_CREATE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *NatGatewayCreateTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	natgateway := obj.(*models.SNatGateway)
+
+	vpc, err := natgateway.GetVpc()
+	if err != nil {
+		self.taskFail(ctx, natgateway, fmt.Sprintf("fail to find vpc %s", err))
+		return
+	}
+	ivpc, err := vpc.GetIVpc()
+	if err != nil {
+		self.taskFail(ctx, natgateway, fmt.Sprintf("fail to find remote vpc %s", err))
+		return
+	}
+
+	opts := &cloudprovider.SNatGatewayCreateOptions{
+		Name:    natgateway.Name,
+		Desc:    natgateway.Description,
+		NatSpec: natgateway.NatSpec,
+	}
+	if networkId, _ := self.Params.GetString("network_id"); len(networkId) > 0 {
+		network, err := models.NetworkManager.FetchById(networkId)
+		if err != nil {
+			self.taskFail(ctx, natgateway, fmt.Sprintf("fail to find network %s: %s", networkId, err))
+			return
+		}
+		opts.NetworkId = network.(*models.SNetwork).ExternalId
+	}
+	if eipId, _ := self.Params.GetString("eip_id"); len(eipId) > 0 {
+		eip, err := models.ElasticipManager.FetchById(eipId)
+		if err != nil {
+			self.taskFail(ctx, natgateway, fmt.Sprintf("fail to find eip %s: %s", eipId, err))
+			return
+		}
+		opts.EipId = eip.(*models.SElasticip).ExternalId
+		opts.EipAddr = eip.(*models.SElasticip).IpAddr
+	}
+
+	inatgateway, err := ivpc.CreateINatGateway(opts)
+	if err != nil {
+		self.taskFail(ctx, natgateway, fmt.Sprintf("fail to create natgateway %s", err))
+		return
+	}
+	_, err = db.Update(natgateway, func() error {
+		natgateway.ExternalId = inatgateway.GetGlobalId()
+		return nil
+	})
+	if err != nil {
+		self.taskFail(ctx, natgateway, fmt.Sprintf("fail to update natgateway %s", err))
+		return
+	}
+
+	if inatgateway.GetStatus() != api.NAT_STATUS_AVAILABLE {
+		err = cloudprovider.WaitStatus(inatgateway, api.NAT_STATUS_AVAILABLE, 10*time.Second, 600*time.Second)
+		if err != nil {
+			self.taskFail(ctx, natgateway, fmt.Sprintf("fail to wait natgateway available %s", err))
+			return
+		}
+	}
+
+	err = natgateway.SyncWithCloudNatGateway(ctx, self.UserCred, natgateway.GetCloudprovider(), inatgateway)
+	if err != nil {
+		self.taskFail(ctx, natgateway, fmt.Sprintf("fail to sync natgateway %s", err))
+		return
+	}
+
+	db.OpsLog.LogEvent(natgateway, db.ACT_ALLOCATE, natgateway.GetShortDesc(ctx), self.UserCred)
+	logclient.AddActionLogWithStartable(self, natgateway, logclient.ACT_CREATE, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
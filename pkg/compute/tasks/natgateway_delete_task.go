@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type NatGatewayDeleteTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(NatGatewayDeleteTask{})
+}
+
+func (self *NatGatewayDeleteTask) taskFail(ctx context.Context, natgateway *models.SNatGateway, reason string) {
+	natgateway.SetStatus(self.UserCred, api.NAT_STATUS_DELETE_FAILED, reason)
+	db.OpsLog.LogEvent(natgateway, db.ACT_DELOCATE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, natgateway, logclient.ACT_DELETE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *NatGatewayDeleteTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	natgateway := obj.(*models.SNatGateway)
+
+	if len(natgateway.ExternalId) > 0 {
+		inatgateway, err := natgateway.GetINatGateway()
+		if err != nil {
+			if err != cloudprovider.ErrNotFound && err != cloudprovider.ErrInvalidProvider {
+				self.taskFail(ctx, natgateway, fmt.Sprintf("fail to find natgateway %s", err))
+				return
+			}
+		} else {
+			err = inatgateway.Delete()
+			if err != nil {
+				self.taskFail(ctx, natgateway, fmt.Sprintf("fail to delete natgateway %s", err))
+				return
+			}
+		}
+	}
+
+	err := natgateway.Purge(ctx, self.UserCred)
+	if err != nil {
+		self.taskFail(ctx, natgateway, fmt.Sprintf("fail to delete natgateway %s", err))
+		return
+	}
+
+	logclient.AddActionLogWithStartable(self, natgateway, logclient.ACT_DELETE, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
@@ -0,0 +1,68 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type RouteTableDelRoutesTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(RouteTableDelRoutesTask{})
+}
+
+func (self *RouteTableDelRoutesTask) taskFail(ctx context.Context, routeTable *models.SRouteTable, reason string) {
+	routeTable.SetStatus(self.UserCred, api.ROUTE_TABLE_STATUS_UPDATE_FAILED, reason)
+	db.OpsLog.LogEvent(routeTable, db.ACT_UPDATE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, routeTable, logclient.ACT_UPDATE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *RouteTableDelRoutesTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	routeTable := obj.(*models.SRouteTable)
+
+	routes := models.SRoutes{}
+	err := self.Params.Unmarshal(&routes, "routes")
+	if err != nil {
+		self.taskFail(ctx, routeTable, fmt.Sprintf("invalid routes %s", err))
+		return
+	}
+	iRouteTable, err := routeTable.GetIRouteTable()
+	if err != nil {
+		self.taskFail(ctx, routeTable, fmt.Sprintf("fail to find route table %s", err))
+		return
+	}
+	for _, route := range routes {
+		routeSet := cloudprovider.SRouteSet{
+			Destination: route.Cidr,
+			NextHopType: route.NextHopType,
+			NextHop:     route.NextHopId,
+		}
+		err = iRouteTable.RemoveRoute(routeSet)
+		if err != nil && err != cloudprovider.ErrNotFound {
+			self.taskFail(ctx, routeTable, fmt.Sprintf("fail to remove route %s: %s", route.Cidr, err))
+			return
+		}
+	}
+
+	err = syncRouteTableRoutes(ctx, self.UserCred, routeTable, iRouteTable)
+	if err != nil {
+		self.taskFail(ctx, routeTable, fmt.Sprintf("fail to sync routes %s", err))
+		return
+	}
+	routeTable.SetStatus(self.UserCred, api.ROUTE_TABLE_STATUS_AVAILABLE, "")
+	db.OpsLog.LogEvent(routeTable, db.ACT_UPDATE, routes, self.UserCred)
+	logclient.AddActionLogWithStartable(self, routeTable, logclient.ACT_UPDATE, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
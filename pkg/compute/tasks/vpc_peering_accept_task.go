@@ -0,0 +1,57 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type VpcPeeringAcceptTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(VpcPeeringAcceptTask{})
+}
+
+func (self *VpcPeeringAcceptTask) taskFail(ctx context.Context, peering *models.SVpcPeering, reason string) {
+	peering.SetStatus(self.UserCred, api.VPC_PEERING_STATUS_ACCEPT_FAILED, reason)
+	db.OpsLog.LogEvent(peering, db.ACT_ACCEPT_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, peering, logclient.ACT_ACCEPT, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *VpcPeeringAcceptTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	peering := obj.(*models.SVpcPeering)
+
+	// 需使用对端VPC所在账号接受连接请求
+	ipeering, err := peering.GetPeerIVpcPeering()
+	if err != nil {
+		self.taskFail(ctx, peering, fmt.Sprintf("fail to find vpc peering from peer vpc %s", err))
+		return
+	}
+	err = ipeering.Accept()
+	if err != nil {
+		self.taskFail(ctx, peering, fmt.Sprintf("fail to accept vpc peering %s", err))
+		return
+	}
+	err = cloudprovider.WaitStatus(ipeering, api.VPC_PEERING_STATUS_ACTIVE, 10*time.Second, 600*time.Second)
+	if err != nil {
+		self.taskFail(ctx, peering, fmt.Sprintf("fail to wait vpc peering active %s", err))
+		return
+	}
+
+	peering.SetStatus(self.UserCred, api.VPC_PEERING_STATUS_ACTIVE, "")
+	db.OpsLog.LogEvent(peering, db.ACT_ACCEPT, nil, self.UserCred)
+	logclient.AddActionLogWithStartable(self, peering, logclient.ACT_ACCEPT, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
@@ -0,0 +1,108 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type VpcPeeringCreateTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(VpcPeeringCreateTask{})
+}
+
+func (self *VpcPeeringCreateTask) taskFail(ctx context.Context, peering *models.SVpcPeering, reason string) {
+	peering.SetStatus(self.UserCred, api.VPC_PEERING_STATUS_CREATE_FAILED, reason)
+	db.OpsLog.LogEvent(peering, db.ACT_ALLOCATE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, peering, logclient.ACT_CREATE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *VpcPeeringCreateTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	peering := obj.(*models.SVpcPeering)
+
+	vpc, err := peering.GetVpc()
+	if err != nil {
+		self.taskFail(ctx, peering, fmt.Sprintf("fail to find vpc %s", err))
+		return
+	}
+	ivpc, err := vpc.GetIVpc()
+	if err != nil {
+		self.taskFail(ctx, peering, fmt.Sprintf("fail to find remote vpc %s", err))
+		return
+	}
+
+	opts := &cloudprovider.SVpcPeeringCreateOptions{
+		Name:          peering.Name,
+		Desc:          peering.Description,
+		PeerVpcId:     peering.ExternalPeerVpcId,
+		PeerAccountId: peering.PeerAccountId,
+		PeerRegionId:  peering.ExternalPeerRegionId,
+	}
+	peerVpc, err := peering.GetPeerVpc()
+	if err != nil {
+		self.taskFail(ctx, peering, fmt.Sprintf("fail to find peer vpc %s", err))
+		return
+	}
+	if peerVpc != nil {
+		iregion, err := peerVpc.GetIRegion()
+		if err != nil {
+			self.taskFail(ctx, peering, fmt.Sprintf("fail to find peer vpc region %s", err))
+			return
+		}
+		opts.PeerRegionId = iregion.GetId()
+	}
+
+	ipeering, err := ivpc.CreateIVpcPeering(opts)
+	if err != nil {
+		self.taskFail(ctx, peering, fmt.Sprintf("fail to create vpc peering %s", err))
+		return
+	}
+	// 连接请求发起后需等待其进入待接受状态
+	if ipeering.GetStatus() == api.VPC_PEERING_STATUS_CREATING {
+		err = cloudprovider.WaitStatus(ipeering, api.VPC_PEERING_STATUS_PENDING_ACCEPT, 10*time.Second, 600*time.Second)
+		if err != nil {
+			self.taskFail(ctx, peering, fmt.Sprintf("fail to wait vpc peering pending accept %s", err))
+			return
+		}
+	}
+
+	err = peering.SyncWithCloudVpcPeering(ctx, self.UserCred, ipeering)
+	if err != nil {
+		self.taskFail(ctx, peering, fmt.Sprintf("fail to sync vpc peering %s", err))
+		return
+	}
+	db.OpsLog.LogEvent(peering, db.ACT_ALLOCATE, peering.GetShortDesc(ctx), self.UserCred)
+	logclient.AddActionLogWithStartable(self, peering, logclient.ACT_CREATE, nil, self.UserCred, true)
+
+	// 对端VPC已纳管时自动接受连接请求
+	if peering.Status == api.VPC_PEERING_STATUS_PENDING_ACCEPT && len(peering.PeerVpcId) > 0 {
+		self.SetStage("OnAcceptComplete", nil)
+		err = peering.StartVpcPeeringTask(ctx, self.UserCred, "VpcPeeringAcceptTask", api.VPC_PEERING_STATUS_ACCEPTING, self.GetTaskId())
+		if err != nil {
+			self.taskFail(ctx, peering, fmt.Sprintf("fail to start accept task %s", err))
+		}
+		return
+	}
+	self.SetStageComplete(ctx, nil)
+}
+
+func (self *VpcPeeringCreateTask) OnAcceptComplete(ctx context.Context, peering *models.SVpcPeering, data jsonutils.JSONObject) {
+	self.SetStageComplete(ctx, nil)
+}
+
+func (self *VpcPeeringCreateTask) OnAcceptCompleteFailed(ctx context.Context, peering *models.SVpcPeering, data jsonutils.JSONObject) {
+	self.SetStageFailed(ctx, data.String())
+}
@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/logclient"
+)
+
+type VpcPeeringDeleteTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(VpcPeeringDeleteTask{})
+}
+
+func (self *VpcPeeringDeleteTask) taskFail(ctx context.Context, peering *models.SVpcPeering, reason string) {
+	peering.SetStatus(self.UserCred, api.VPC_PEERING_STATUS_DELETE_FAILED, reason)
+	db.OpsLog.LogEvent(peering, db.ACT_DELOCATE_FAIL, reason, self.UserCred)
+	logclient.AddActionLogWithStartable(self, peering, logclient.ACT_DELETE, reason, self.UserCred, false)
+	self.SetStageFailed(ctx, reason)
+}
+
+func (self *VpcPeeringDeleteTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	peering := obj.(*models.SVpcPeering)
+
+	if len(peering.ExternalId) > 0 {
+		ipeering, err := peering.GetIVpcPeering()
+		if err != nil {
+			if err != cloudprovider.ErrNotFound && err != cloudprovider.ErrInvalidProvider {
+				self.taskFail(ctx, peering, fmt.Sprintf("fail to find vpc peering %s", err))
+				return
+			}
+		} else {
+			err = ipeering.Delete()
+			if err != nil {
+				self.taskFail(ctx, peering, fmt.Sprintf("fail to delete vpc peering %s", err))
+				return
+			}
+		}
+	}
+
+	err := peering.RealDelete(ctx, self.UserCred)
+	if err != nil {
+		self.taskFail(ctx, peering, fmt.Sprintf("fail to delete vpc peering %s", err))
+		return
+	}
+
+	logclient.AddActionLogWithStartable(self, peering, logclient.ACT_DELETE, nil, self.UserCred, true)
+	self.SetStageComplete(ctx, nil)
+}
@@ -0,0 +1,119 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	schedapi "yunion.io/x/onecloud/pkg/apis/scheduler"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db"
+	"yunion.io/x/onecloud/pkg/cloudcommon/db/taskman"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// ZoneRebalanceTask live migrates the guests of a rebalance plan one by one.
+// A guest which moved or can not be live migrated any more is skipped, a
+// failed migration does not stop the rest of the plan.
+type ZoneRebalanceTask struct {
+	taskman.STask
+}
+
+func init() {
+	taskman.RegisterTask(ZoneRebalanceTask{})
+}
+
+func (self *ZoneRebalanceTask) OnInit(ctx context.Context, obj db.IStandaloneModel, data jsonutils.JSONObject) {
+	zone := obj.(*models.SZone)
+	self.StartMigrateGuest(ctx, zone)
+}
+
+func (self *ZoneRebalanceTask) StartMigrateGuest(ctx context.Context, zone *models.SZone) {
+	migrations := make([]schedapi.RebalanceMigration, 0)
+	err := self.Params.Unmarshal(&migrations, "migrations")
+	if err != nil {
+		self.TaskFailed(ctx, zone, fmt.Sprintf("invalid migrations: %s", err))
+		return
+	}
+	idx, _ := self.Params.Int("migration_index")
+	for ; int(idx) < len(migrations); idx++ {
+		m := migrations[idx]
+		guest := models.GuestManager.FetchGuestById(m.GuestId)
+		if guest == nil {
+			self.skipGuest(m.GuestId, "guest not found")
+			continue
+		}
+		if guest.HostId != m.SourceHostId {
+			self.skipGuest(m.GuestId, fmt.Sprintf("guest is not on host %s any more", m.SourceHostName))
+			continue
+		}
+		if err := guest.CheckLiveMigrate(ctx, self.UserCred); err != nil {
+			self.skipGuest(m.GuestId, err.Error())
+			continue
+		}
+		self.SetStage("OnGuestMigrateComplete", jsonutils.Marshal(map[string]int64{"migration_index": idx + 1}).(*jsonutils.JSONDict))
+		err := guest.StartGuestLiveMigrateTask(ctx, self.UserCred, guest.Status, m.TargetHostId, self.GetTaskId())
+		if err != nil {
+			self.skipGuest(m.GuestId, err.Error())
+			continue
+		}
+		return
+	}
+	self.TaskComplete(ctx, zone)
+}
+
+func (self *ZoneRebalanceTask) skipGuest(guestId, reason string) {
+	log.Warningf("ZoneRebalanceTask skip guest %s: %s", guestId, reason)
+	self.addGuest("failed_guests", guestId)
+}
+
+func (self *ZoneRebalanceTask) addGuest(key, guestId string) {
+	guestIds := jsonutils.GetQueryStringArray(self.Params, key)
+	guestIds = append(guestIds, guestId)
+	params := jsonutils.NewDict()
+	params.Set(key, jsonutils.NewStringArray(guestIds))
+	self.SaveParams(params)
+}
+
+func (self *ZoneRebalanceTask) OnGuestMigrateComplete(ctx context.Context, zone *models.SZone, data jsonutils.JSONObject) {
+	guestId := self.currentGuestId()
+	self.addGuest("migrated_guests", guestId)
+	self.StartMigrateGuest(ctx, zone)
+}
+
+func (self *ZoneRebalanceTask) OnGuestMigrateCompleteFailed(ctx context.Context, zone *models.SZone, data jsonutils.JSONObject) {
+	guestId := self.currentGuestId()
+	self.skipGuest(guestId, data.String())
+	self.StartMigrateGuest(ctx, zone)
+}
+
+// currentGuestId returns the guest whose migration the task waits for
+func (self *ZoneRebalanceTask) currentGuestId() string {
+	migrations := make([]schedapi.RebalanceMigration, 0)
+	self.Params.Unmarshal(&migrations, "migrations")
+	idx, _ := self.Params.Int("migration_index")
+	if idx <= 0 || int(idx) > len(migrations) {
+		return ""
+	}
+	return migrations[idx-1].GuestId
+}
+
+func (self *ZoneRebalanceTask) TaskComplete(ctx context.Context, zone *models.SZone) {
+	migrated := jsonutils.GetQueryStringArray(self.Params, "migrated_guests")
+	failed := jsonutils.GetQueryStringArray(self.Params, "failed_guests")
+	result := jsonutils.NewDict()
+	result.Set("migrated_guests", jsonutils.NewStringArray(migrated))
+	result.Set("failed_guests", jsonutils.NewStringArray(failed))
+	if len(migrated) == 0 && len(failed) > 0 {
+		self.TaskFailed(ctx, zone, result.String())
+		return
+	}
+	db.OpsLog.LogEvent(zone, db.ACT_REBALANCE, result, self.UserCred)
+	self.SetStageComplete(ctx, result)
+}
+
+func (self *ZoneRebalanceTask) TaskFailed(ctx context.Context, zone *models.SZone, reason string) {
+	db.OpsLog.LogEvent(zone, db.ACT_REBALANCE_FAIL, reason, self.UserCred)
+	self.SetStageFailed(ctx, reason)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -174,7 +175,33 @@ func (m *SGuestManager) StartCpusetBalancer() {
 }
 
 func (m *SGuestManager) cpusetBalance() {
-	cgrouputils.RebalanceProcesses(nil)
+	// guests bound to a numa node keep their cpuset, all other processes
+	// are rebalanced as before
+	pinned := make(map[string]bool)
+	for _, guest := range m.Servers {
+		if guest.IsRunning() && guest.getNumaNode() != nil {
+			pinned[strconv.Itoa(guest.GetPid())] = true
+		}
+	}
+	if len(pinned) == 0 {
+		cgrouputils.RebalanceProcesses(nil)
+		return
+	}
+	allPids, err := cgrouputils.GetAllPids()
+	if err != nil {
+		log.Errorf("cpuset balance get all pids: %s", err)
+		return
+	}
+	pids := make([]string, 0, len(allPids))
+	for _, pid := range allPids {
+		if !pinned[pid] {
+			pids = append(pids, pid)
+		}
+	}
+	if len(pids) == 0 {
+		return
+	}
+	cgrouputils.RebalanceProcesses(pids)
 }
 
 func (m *SGuestManager) IsGuestDir(f os.FileInfo) bool {
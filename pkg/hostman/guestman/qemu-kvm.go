@@ -19,6 +19,7 @@ import (
 
 	api "yunion.io/x/onecloud/pkg/apis/compute"
 	"yunion.io/x/onecloud/pkg/appctx"
+	"yunion.io/x/onecloud/pkg/cloudcommon/types"
 	"yunion.io/x/onecloud/pkg/hostman/guestfs"
 	"yunion.io/x/onecloud/pkg/hostman/hostinfo/hostbridge"
 	"yunion.io/x/onecloud/pkg/hostman/hostutils"
@@ -30,6 +31,7 @@ import (
 	"yunion.io/x/onecloud/pkg/util/fileutils2"
 	"yunion.io/x/onecloud/pkg/util/netutils2"
 	"yunion.io/x/onecloud/pkg/util/procutils"
+	"yunion.io/x/onecloud/pkg/util/sysutils"
 	"yunion.io/x/onecloud/pkg/util/timeutils2"
 	"yunion.io/x/onecloud/pkg/util/version"
 )
@@ -974,6 +976,7 @@ func (s *SKVMGuestInstance) SetCgroup() {
 	s.cgroupPid = s.GetPid()
 	s.setCgroupIo()
 	s.setCgroupCpu()
+	s.setCgroupCpuset()
 }
 
 func (s *SKVMGuestInstance) setCgroupIo() {
@@ -1003,6 +1006,51 @@ func (s *SKVMGuestInstance) setCgroupCpu() {
 	cgrouputils.CgroupSet(strconv.Itoa(s.cgroupPid), int(cpu)*cpuWeight)
 }
 
+func (s *SKVMGuestInstance) getNumaNode() *types.SNumaNode {
+	if !s.Desc.Contains("numa_node") {
+		return nil
+	}
+	nodeId, _ := s.Desc.Int("numa_node")
+	for _, node := range guestManger.GetHost().GetNumaNodes() {
+		if node.NodeId == int(nodeId) {
+			return node
+		}
+	}
+	return nil
+}
+
+// setCgroupCpuset binds the guest of numa_policy single_node to the cpus and
+// memory of its numa node, and pins each vcpu thread to one of the cpus
+func (s *SKVMGuestInstance) setCgroupCpuset() {
+	node := s.getNumaNode()
+	if node == nil {
+		return
+	}
+	task := cgrouputils.NewCGroupCPUSetTaskWithMems(strconv.Itoa(s.cgroupPid), 0,
+		sysutils.FormatCpuList(node.Cpus), strconv.Itoa(node.NodeId))
+	if !task.SetTask() {
+		log.Errorf("Guest %s bind to numa node %d failed", s.Id, node.NodeId)
+		return
+	}
+	if s.Monitor == nil {
+		return
+	}
+	s.Monitor.HumanMonitorCommand("info cpus", func(res string) {
+		s.pinVcpus(node, res)
+	})
+}
+
+func (s *SKVMGuestInstance) pinVcpus(node *types.SNumaNode, cpusInfo string) {
+	threads := monitor.ParseVcpuThreadIds(cpusInfo)
+	for vcpu, threadId := range threads {
+		cpu := node.Cpus[vcpu%len(node.Cpus)]
+		_, err := procutils.NewCommand("taskset", "-pc", strconv.Itoa(cpu), strconv.Itoa(threadId)).Run()
+		if err != nil {
+			log.Errorf("Guest %s pin vcpu %d to cpu %d failed: %s", s.Id, vcpu, cpu, err)
+		}
+	}
+}
+
 func (s *SKVMGuestInstance) CreateFromDesc(desc jsonutils.JSONObject) error {
 	if err := s.PrepareDir(); err != nil {
 		uuid, _ := desc.GetString("uuid")
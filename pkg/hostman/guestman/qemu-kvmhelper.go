@@ -10,12 +10,15 @@ import (
 	"yunion.io/x/log"
 	"yunion.io/x/pkg/utils"
 
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/types"
 	"yunion.io/x/onecloud/pkg/hostman/options"
 	"yunion.io/x/onecloud/pkg/hostman/storageman"
 	"yunion.io/x/onecloud/pkg/util/ethernet"
 	"yunion.io/x/onecloud/pkg/util/ethernet/arp"
 	"yunion.io/x/onecloud/pkg/util/fileutils2"
 	"yunion.io/x/onecloud/pkg/util/qemutils"
+	"yunion.io/x/onecloud/pkg/util/sysutils"
 )
 
 const (
@@ -304,7 +307,11 @@ func (s *SKVMGuestInstance) generateStartScript(data *jsonutils.JSONDict) (strin
 		cmd += fmt.Sprintf("%s %s\n", downscript, ifname)
 	}
 
-	if options.HostOptions.HugepagesOption == "native" {
+	numaNode, err := s.prepareNumaNode(int(mem), int(cpu))
+	if err != nil {
+		return "", err
+	}
+	if s.useHugepages() {
 		cmd += fmt.Sprintf("mkdir -p /dev/hugepages/%s\n", uuid)
 		cmd += fmt.Sprintf("mount -t hugetlbfs -o size=%dM hugetlbfs-%s /dev/hugepages/%s\n",
 			mem, uuid, uuid)
@@ -407,7 +414,9 @@ func (s *SKVMGuestInstance) generateStartScript(data *jsonutils.JSONDict) (strin
 	// #cmd += fmt.Sprintf(" -uuid %s", self.desc["uuid"])
 	cmd += fmt.Sprintf(" -m %dM,slots=4,maxmem=262144M", mem)
 
-	if options.HostOptions.HugepagesOption == "native" {
+	if numaNode != nil {
+		cmd += s.getNumaDesc(numaNode, int(mem))
+	} else if options.HostOptions.HugepagesOption == "native" {
 		cmd += fmt.Sprintf(" -mem-prealloc -mem-path %s", fmt.Sprintf("/dev/hugepages/%s", uuid))
 	}
 
@@ -612,7 +621,7 @@ func (s *SKVMGuestInstance) generateStopScript(data *jsonutils.JSONDict) string
 	cmd += "  rm -f $VNC_FILE\n"
 	cmd += "fi\n"
 
-	if options.HostOptions.HugepagesOption == "native" {
+	if s.useHugepages() {
 		cmd += fmt.Sprintf("if [ -f /dev/hugepages/%s ]; then\n", uuid)
 		cmd += fmt.Sprintf("  umount /dev/hugepages/%s\n", uuid)
 		cmd += fmt.Sprintf("  rm -rf /dev/hugepages/%s\n", uuid)
@@ -626,6 +635,89 @@ func (s *SKVMGuestInstance) generateStopScript(data *jsonutils.JSONDict) string
 	return cmd
 }
 
+func (s *SKVMGuestInstance) isNumaSingleNode() bool {
+	numaPolicy, _ := s.Desc.GetString("numa_policy")
+	return numaPolicy == api.GUEST_NUMA_POLICY_SINGLE_NODE
+}
+
+// useHugepages tells whether the memory of the guest is backed by the
+// hugetlbfs mounted at /dev/hugepages/<uuid>
+func (s *SKVMGuestInstance) useHugepages() bool {
+	if options.HostOptions.HugepagesOption == "native" {
+		return true
+	}
+	return jsonutils.QueryBoolean(s.Desc, "numa_hugepages", false)
+}
+
+// prepareNumaNode picks the numa node of the host to hold all the vcpus and
+// memory of a single_node guest, the node with free hugepages for the whole
+// memory is preferred and then the one with the most free memory.
+func (s *SKVMGuestInstance) prepareNumaNode(mem, cpu int) (*types.SNumaNode, error) {
+	s.Desc.Remove("numa_node")
+	s.Desc.Remove("numa_hugepages")
+	if !s.isNumaSingleNode() {
+		return nil, nil
+	}
+	var (
+		native      = options.HostOptions.HugepagesOption == "native"
+		pageSizeMb  = guestManger.GetHost().GetHugepagesizeMb()
+		selected    *types.SNumaNode
+		selectedHp  bool
+		selectedMem int
+	)
+	for _, node := range guestManger.GetHost().GetNumaNodes() {
+		if len(node.Cpus) < cpu {
+			continue
+		}
+		freeMem, hugepages := 0, false
+		if pageSizeMb > 0 {
+			pages, err := sysutils.GetNumaNodeFreeHugepages(node.NodeId, pageSizeMb*1024)
+			if err == nil && pages*pageSizeMb >= mem {
+				freeMem, hugepages = pages*pageSizeMb, true
+			}
+		}
+		if !hugepages {
+			if native {
+				continue
+			}
+			free, err := sysutils.GetNumaNodeFreeMemMb(node.NodeId)
+			if err != nil || free < mem {
+				continue
+			}
+			freeMem = free
+		}
+		if selected == nil || (hugepages && !selectedHp) ||
+			(hugepages == selectedHp && freeMem > selectedMem) {
+			selected, selectedHp, selectedMem = node, hugepages, freeMem
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("no numa node fits %d cpus and %dM memory", cpu, mem)
+	}
+	s.Desc.Set("numa_node", jsonutils.NewInt(int64(selected.NodeId)))
+	if selectedHp {
+		s.Desc.Set("numa_hugepages", jsonutils.JSONTrue)
+	}
+	if err := s.SaveDesc(s.Desc); err != nil {
+		return nil, err
+	}
+	return selected, nil
+}
+
+func (s *SKVMGuestInstance) getNumaDesc(node *types.SNumaNode, mem int) string {
+	uuid, _ := s.Desc.GetString("uuid")
+	var cmd string
+	if s.useHugepages() {
+		cmd += fmt.Sprintf(" -object memory-backend-file,id=mem0,size=%dM,mem-path=/dev/hugepages/%s,share=on,prealloc=on", mem, uuid)
+	} else {
+		cmd += fmt.Sprintf(" -object memory-backend-ram,id=mem0,size=%dM", mem)
+	}
+	cmd += fmt.Sprintf(",host-nodes=%d,policy=bind", node.NodeId)
+	// hotplugged vcpus up to maxcpus stay in the same node
+	cmd += " -numa node,nodeid=0,cpus=0-127,memdev=mem0"
+	return cmd
+}
+
 func (s *SKVMGuestInstance) presendArpForNic(nic jsonutils.JSONObject) {
 	ifname, _ := nic.GetString("ifname")
 	ifi, err := net.InterfaceByName(ifname)
@@ -17,6 +17,7 @@ import (
 
 	api "yunion.io/x/onecloud/pkg/apis/compute"
 	"yunion.io/x/onecloud/pkg/cloudcommon/sshkeys"
+	"yunion.io/x/onecloud/pkg/cloudcommon/types"
 	"yunion.io/x/onecloud/pkg/hostman/guestfs/fsdriver"
 	"yunion.io/x/onecloud/pkg/hostman/hostinfo/hostbridge"
 	"yunion.io/x/onecloud/pkg/hostman/hostutils"
@@ -113,6 +114,17 @@ func (h *SHostInfo) IsKvmSupport() bool {
 	return true
 }
 
+func (h *SHostInfo) GetNumaNodes() []*types.SNumaNode {
+	if h.sysinfo != nil {
+		return h.sysinfo.NumaNodes
+	}
+	return nil
+}
+
+func (h *SHostInfo) GetHugepagesizeMb() int {
+	return h.Mem.GetHugepagesizeMb()
+}
+
 func (h *SHostInfo) IsNestedVirtualization() bool {
 	return utils.IsInStringArray("hypervisor", h.Cpu.cpuFeatures)
 }
@@ -285,6 +297,7 @@ func (h *SHostInfo) detectHostInfo() error {
 	}
 
 	h.detectiveStorageSystem()
+	h.detectiveNumaNodes()
 
 	if options.HostOptions.CheckSystemServices {
 		if err := h.checkSystemServices(); err != nil {
@@ -325,6 +338,58 @@ func (h *SHostInfo) detectiveStorageSystem() {
 	h.sysinfo.StorageType = stype
 }
 
+func (h *SHostInfo) detectiveNumaNodes() {
+	nodes, err := sysutils.DetectNumaNodes()
+	if err != nil {
+		log.Warningf("detect numa nodes: %s", err)
+		return
+	}
+	h.sysinfo.NumaNodes = nodes
+}
+
+// refreshNumaNodes updates the free memory and hugepages of the numa nodes.
+// With native hugepages, memory out of the hugepages is not usable by guests
+func (h *SHostInfo) refreshNumaNodes() []*types.SNumaNode {
+	if h.sysinfo == nil || len(h.sysinfo.NumaNodes) == 0 {
+		return nil
+	}
+	pageSizeMb := h.GetHugepagesizeMb()
+	nodes := make([]*types.SNumaNode, len(h.sysinfo.NumaNodes))
+	for i, node := range h.sysinfo.NumaNodes {
+		n := *node
+		n.FreeMemSizeMb, n.FreeHugepageSizeMb = 0, 0
+		if options.HostOptions.HugepagesOption != "native" {
+			free, err := sysutils.GetNumaNodeFreeMemMb(n.NodeId)
+			if err != nil {
+				log.Warningf("get numa node %d free memory: %s", n.NodeId, err)
+			} else {
+				n.FreeMemSizeMb = free
+			}
+		}
+		if pageSizeMb > 0 {
+			pages, err := sysutils.GetNumaNodeFreeHugepages(n.NodeId, pageSizeMb*1024)
+			if err == nil {
+				n.FreeHugepageSizeMb = pages * pageSizeMb
+			}
+		}
+		nodes[i] = &n
+	}
+	h.sysinfo.NumaNodes = nodes
+	return nodes
+}
+
+// getPingParams reports the resources changing over time, i.e. the free
+// memory of numa nodes
+func (h *SHostInfo) getPingParams() jsonutils.JSONObject {
+	nodes := h.refreshNumaNodes()
+	if len(nodes) == 0 {
+		return nil
+	}
+	params := jsonutils.NewDict()
+	params.Set("numa_nodes", jsonutils.Marshal(nodes))
+	return params
+}
+
 func (h *SHostInfo) fixPathEnv() error {
 	var paths = []string{
 		"/usr/local/sbin",
@@ -861,6 +926,7 @@ func (h *SHostInfo) updateHostRecord(hostId string) {
 	content.Set("storage_size", jsonutils.NewInt(int64(storageman.GetManager().GetTotalCapacity())))
 
 	// TODO optimize content data struct
+	h.refreshNumaNodes()
 	content.Set("sys_info", jsonutils.Marshal(h.sysinfo))
 	content.Set("sn", jsonutils.NewString(h.sysinfo.SN))
 	content.Set("host_type", jsonutils.NewString(options.HostOptions.HostType))
@@ -291,6 +291,8 @@ type SSysInfo struct {
 	OvsVersion     string `json:"ovs_version"`
 
 	StorageType string `json:"storage_type"`
+
+	NumaNodes []*types.SNumaNode `json:"numa_nodes,omitempty"`
 }
 
 func StartDetachStorages(hs []jsonutils.JSONObject) {
@@ -56,7 +56,7 @@ func (p *SHostPingTask) Start() {
 			return
 		}
 		res, err := modules.Hosts.PerformAction(hostutils.GetComputeSession(context.Background()),
-			hostId, "ping", nil)
+			hostId, "ping", Instance().getPingParams())
 		if err != nil {
 			div = 3
 		} else {
@@ -10,6 +10,7 @@ import (
 
 	"yunion.io/x/onecloud/pkg/appctx"
 	"yunion.io/x/onecloud/pkg/appsrv"
+	"yunion.io/x/onecloud/pkg/cloudcommon/types"
 	"yunion.io/x/onecloud/pkg/cloudcommon/workmanager"
 	"yunion.io/x/onecloud/pkg/hostman/hostinfo/hostbridge"
 	"yunion.io/x/onecloud/pkg/hostman/isolated_device"
@@ -30,6 +31,9 @@ type IHost interface {
 	IsKvmSupport() bool
 	IsNestedVirtualization() bool
 
+	GetNumaNodes() []*types.SNumaNode
+	GetHugepagesizeMb() int
+
 	PutHostOnline() error
 
 	GetBridgeDev(bridge string) hostbridge.IBridgeDriver
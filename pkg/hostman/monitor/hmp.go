@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -405,6 +406,24 @@ func (m *HmpMonitor) GetCpuCount(callback func(count int)) {
 	m.Query("info cpus", cb)
 }
 
+var vcpuThreadRe = regexp.MustCompile(`CPU #(\d+):.*thread_id=(\d+)`)
+
+// ParseVcpuThreadIds parses the output of info cpus into thread ids by vcpu
+// index, e.g. "* CPU #0: pc=0xffffffff8104f596 (halted) thread_id=11282"
+func ParseVcpuThreadIds(cpusInfo string) map[int]int {
+	threads := make(map[int]int)
+	for _, line := range strings.Split(cpusInfo, "\n") {
+		m := vcpuThreadRe.FindStringSubmatch(line)
+		if len(m) != 3 {
+			continue
+		}
+		vcpu, _ := strconv.Atoi(m[1])
+		threadId, _ := strconv.Atoi(m[2])
+		threads[vcpu] = threadId
+	}
+	return threads
+}
+
 func (m *HmpMonitor) AddCpu(cpuIndex int, callback StringCallback) {
 	m.Query(fmt.Sprintf("cpu-add %d", cpuIndex), callback)
 }
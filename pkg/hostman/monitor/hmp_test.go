@@ -25,3 +25,12 @@ func TestHmpMonitor_Connect(t *testing.T) {
 	time.Sleep(3 * time.Second)
 	m.Disconnect()
 }
+
+func TestParseVcpuThreadIds(t *testing.T) {
+	cpusInfo := "* CPU #0: pc=0xffffffff8104f596 (halted) thread_id=11282\r\n" +
+		"  CPU #1: pc=0xffffffff8104f596 (halted) thread_id=11283\r\n"
+	threads := ParseVcpuThreadIds(cpusInfo)
+	if len(threads) != 2 || threads[0] != 11282 || threads[1] != 11283 {
+		t.Errorf("ParseVcpuThreadIds() = %v", threads)
+	}
+}
@@ -0,0 +1,205 @@
+package lbagent
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	agentmodels "yunion.io/x/onecloud/pkg/lbagent/models"
+	agentutils "yunion.io/x/onecloud/pkg/lbagent/utils"
+	"yunion.io/x/onecloud/pkg/util/influxdb"
+)
+
+const (
+	accessLogChanSize   = 4096
+	accessLogBatchLimit = 8192
+
+	// how long the sequences of a millisecond are kept for records that
+	// come out of order
+	accessLogSeqKeep = time.Minute
+)
+
+// AccessLogHelper receives access logs from haproxy through unix datagram
+// socket and writes them to influxdb in batch
+type AccessLogHelper struct {
+	opts *Options
+
+	mu        sync.Mutex
+	enabled   bool
+	influxUrl string
+	influxDb  string
+
+	// influxdb overwrites points with the same timestamp and tag set.
+	// Records in the same millisecond are told apart by the nanoseconds,
+	// keyed by the millisecond in unix nanoseconds
+	seqs   map[int64]int64
+	latest int64
+}
+
+func NewAccessLogHelper(opts *Options) (*AccessLogHelper, error) {
+	if opts.AccessLogFlushInterval <= 0 {
+		return nil, fmt.Errorf("invalid access log flush interval: %d", opts.AccessLogFlushInterval)
+	}
+	helper := &AccessLogHelper{
+		opts: opts,
+		seqs: map[int64]int64{},
+	}
+	return helper, nil
+}
+
+// UseAgentParams updates where access logs go
+func (h *AccessLogHelper) UseAgentParams(agentParams *agentmodels.AgentParams) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	params := agentParams.AgentModel.Params
+	h.enabled = params.Haproxy.AccessLog
+	h.influxUrl = params.Telegraf.InfluxDbOutputUrl
+	h.influxDb = params.Telegraf.InfluxDbOutputName
+}
+
+func (h *AccessLogHelper) target() (url, dbName string, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.influxUrl, h.influxDb, h.enabled && h.influxUrl != ""
+}
+
+func (h *AccessLogHelper) Run(ctx context.Context) {
+	defer func() {
+		log.Infof("access log helper bye")
+		wg := ctx.Value("wg").(*sync.WaitGroup)
+		wg.Done()
+	}()
+
+	socketFile := h.opts.haproxyAccessLogSocketFile()
+	// stale socket file from last run
+	os.Remove(socketFile)
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketFile, Net: "unixgram"})
+	if err != nil {
+		log.Errorf("listen access log socket %s: %s", socketFile, err)
+		return
+	}
+	defer os.Remove(socketFile)
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	recordChan := make(chan *agentutils.HaproxyAccessLogRecord, accessLogChanSize)
+	go h.receive(conn, recordChan)
+
+	ticker := time.NewTicker(time.Duration(h.opts.AccessLogFlushInterval) * time.Second)
+	defer ticker.Stop()
+	records := []*agentutils.HaproxyAccessLogRecord{}
+	for {
+		select {
+		case r, ok := <-recordChan:
+			if !ok {
+				return
+			}
+			if len(records) >= accessLogBatchLimit {
+				// influxdb is not keeping up
+				continue
+			}
+			records = append(records, r)
+		case <-ticker.C:
+			if len(records) > 0 {
+				h.flush(records)
+				records = records[:0]
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *AccessLogHelper) receive(conn *net.UnixConn, recordChan chan<- *agentutils.HaproxyAccessLogRecord) {
+	defer close(recordChan)
+	buf := make([]byte, 8192)
+	for {
+		n, _, err := conn.ReadFromUnix(buf)
+		if err != nil {
+			log.Infof("access log socket: %s", err)
+			return
+		}
+		if _, _, ok := h.target(); !ok {
+			continue
+		}
+		r, err := agentutils.ParseHaproxySyslog(string(buf[:n]))
+		if err != nil {
+			log.Debugf("access log: %s: %s", err, buf[:n])
+			continue
+		}
+		select {
+		case recordChan <- r:
+		default:
+			log.Warningf("access log channel full, dropped")
+		}
+	}
+}
+
+func (h *AccessLogHelper) flush(records []*agentutils.HaproxyAccessLogRecord) {
+	url, dbName, ok := h.target()
+	if !ok {
+		return
+	}
+	points := make([]influxdb.SPoint, 0, len(records))
+	for _, r := range records {
+		points = append(points, h.point(r))
+	}
+	h.pruneSeqs()
+	db := influxdb.NewInfluxdb(url)
+	if err := db.SetDatabase(dbName); err != nil {
+		log.Errorf("access log: influxdb set database %s: %s", dbName, err)
+		return
+	}
+	if err := db.Write(points); err != nil {
+		log.Errorf("access log: write %d points: %s", len(points), err)
+	}
+}
+
+func (h *AccessLogHelper) pruneSeqs() {
+	for ms := range h.seqs {
+		if ms < h.latest-int64(accessLogSeqKeep) {
+			delete(h.seqs, ms)
+		}
+	}
+}
+
+func (h *AccessLogHelper) point(r *agentutils.HaproxyAccessLogRecord) influxdb.SPoint {
+	// haproxy log time has only millisecond precision, the record stays in
+	// its own millisecond however late it comes
+	t := r.Time.Truncate(time.Millisecond)
+	ms := t.UnixNano()
+	seq := h.seqs[ms]
+	h.seqs[ms] = seq + 1
+	t = t.Add(time.Duration(seq))
+	if ms > h.latest {
+		h.latest = ms
+	}
+	http5xx := 0
+	if r.Status >= 500 {
+		http5xx = 1
+	}
+	return influxdb.SPoint{
+		Measurement: api.LB_ACCESS_LOG_MEASUREMENT,
+		Tags: map[string]string{
+			"listener_id": r.Frontend,
+			"backend":     r.Backend,
+			"server":      r.Server,
+		},
+		Fields: map[string]interface{}{
+			"status":   r.Status,
+			"http_5xx": http5xx,
+			"latency":  r.Latency,
+			"bytes":    r.Bytes,
+			"client":   r.ClientAddr,
+		},
+		Time: t,
+	}
+}
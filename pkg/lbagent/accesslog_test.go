@@ -0,0 +1,46 @@
+package lbagent
+
+import (
+	"testing"
+	"time"
+
+	agentutils "yunion.io/x/onecloud/pkg/lbagent/utils"
+)
+
+func TestAccessLogPointTime(t *testing.T) {
+	h, err := NewAccessLogHelper(&Options{AccessLogFlushInterval: 1})
+	if err != nil {
+		t.Fatalf("new helper: %s", err)
+	}
+	base := time.Unix(1500000000, 0)
+	ms := func(n int) time.Time {
+		return base.Add(time.Duration(n) * time.Millisecond)
+	}
+	cases := []struct {
+		recordTime time.Time
+		want       time.Time
+	}{
+		{recordTime: ms(1), want: ms(1)},
+		{recordTime: ms(1), want: ms(1).Add(1)},
+		{recordTime: ms(3), want: ms(3)},
+		// out of order records stay in their own millisecond
+		{recordTime: ms(1), want: ms(1).Add(2)},
+		{recordTime: ms(2), want: ms(2)},
+		{recordTime: ms(3), want: ms(3).Add(1)},
+	}
+	for i, c := range cases {
+		p := h.point(&agentutils.HaproxyAccessLogRecord{Time: c.recordTime})
+		if !p.Time.Equal(c.want) {
+			t.Errorf("record %d: want %s, got %s", i, c.want.Format(time.RFC3339Nano), p.Time.Format(time.RFC3339Nano))
+		}
+	}
+
+	h.latest = ms(1).Add(accessLogSeqKeep).Add(time.Millisecond).UnixNano()
+	h.pruneSeqs()
+	if _, ok := h.seqs[ms(1).UnixNano()]; ok {
+		t.Errorf("sequence of expired millisecond is kept")
+	}
+	if _, ok := h.seqs[ms(3).UnixNano()]; !ok {
+		t.Errorf("sequence of recent millisecond is pruned")
+	}
+}
@@ -3,6 +3,8 @@ package lbagent
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +33,8 @@ type ApiHelper struct {
 
 	haState         string
 	haStateProvider HaStateProvider
+
+	accessLogHelper *AccessLogHelper
 }
 
 func NewApiHelper(opts *Options) (*ApiHelper, error) {
@@ -88,6 +92,10 @@ func (h *ApiHelper) SetHaStateProvider(hsp HaStateProvider) {
 	h.haStateProvider = hsp
 }
 
+func (h *ApiHelper) SetAccessLogHelper(alh *AccessLogHelper) {
+	h.accessLogHelper = alh
+}
+
 func (h *ApiHelper) adminClientSession(ctx context.Context) *mcclient.ClientSession {
 	region := h.opts.CommonOptions.Region
 	apiVersion := "v2"
@@ -246,9 +254,109 @@ func (h *ApiHelper) newAgentHbParams(ctx context.Context) (*jsonutils.JSONDict,
 	if err != nil {
 		return nil, err
 	}
+	if stats := h.haproxyServerStats(ctx); stats != nil {
+		params.Set("backend_health_states", jsonutils.Marshal(backendHealthStates(stats)))
+		params.Set("backend_drained", jsonutils.Marshal(h.backendDrained(stats)))
+	}
 	return params, nil
 }
 
+// haproxyServerStats reads server states from haproxy stats socket.  Nil
+// will be returned when haproxy is not running, e.g. when the agent is in
+// BACKUP state
+func (h *ApiHelper) haproxyServerStats(ctx context.Context) []*agentutils.HaproxyServerStat {
+	statsSocket := h.opts.haproxyStatsSocketFile()
+	if fi, err := os.Stat(statsSocket); err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+	stats, err := agentutils.HaproxyShowStat(statsSocket)
+	if err != nil {
+		log.Warningf("read haproxy stats: %s", err)
+		return nil
+	}
+	return stats
+}
+
+// backendHealthStates returns health status keyed by loadbalancer backend
+// ids, which are also haproxy server names.  The same backend may be
+// checked in multiple haproxy backends by different listeners, it is
+// considered down if any of them reports so
+func backendHealthStates(stats []*agentutils.HaproxyServerStat) map[string]string {
+	states := map[string]string{}
+	for _, stat := range stats {
+		state := haproxyServerHealthStatus(stat.Status)
+		switch states[stat.Server] {
+		case api.LB_BACKEND_HEALTH_STATUS_DOWN:
+		case api.LB_BACKEND_HEALTH_STATUS_UP:
+			if state == api.LB_BACKEND_HEALTH_STATUS_DOWN {
+				states[stat.Server] = state
+			}
+		default:
+			states[stat.Server] = state
+		}
+	}
+	return states
+}
+
+// backendDrained returns ids of draining backends that accept no new
+// connections and have no active sessions in all haproxy backends.
+//
+// Only the MASTER agent reports drained backends.  Haproxy also runs on
+// BACKUP agents, but without traffic its sessions count means nothing.
+// Old haproxy processes left by soft reload (-sf) still hold sessions that
+// are not visible through the stats socket of the new process, reporting
+// is suspended until they exit
+func (h *ApiHelper) backendDrained(stats []*agentutils.HaproxyServerStat) []string {
+	if h.haState != api.LB_HA_STATE_MASTER {
+		return nil
+	}
+	if pids := agentutils.FindProcessesByArg(h.opts.haproxyPidFile()); len(pids) > 1 {
+		log.Infof("old haproxy processes still running: %v", pids)
+		return nil
+	}
+	drained := map[string]bool{}
+	if h.corpus != nil {
+		for id, backend := range h.corpus.LoadbalancerBackends {
+			if backend.DrainState == api.LB_BACKEND_DRAIN_STATE_DRAINING {
+				drained[id] = true
+			}
+		}
+	}
+	for _, stat := range stats {
+		if !drained[stat.Server] {
+			continue
+		}
+		if stat.Weight != 0 && !strings.HasPrefix(stat.Status, "DRAIN") {
+			// not yet applied
+			drained[stat.Server] = false
+		} else if stat.CurrentSessions > 0 {
+			drained[stat.Server] = false
+		}
+	}
+	ids := []string{}
+	for id, ok := range drained {
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// haproxyServerHealthStatus maps haproxy server status to backend health
+// status.  Transitional states like "UP 1/3" and "DOWN 1/2" are counted as
+// their current state
+func haproxyServerHealthStatus(status string) string {
+	switch strings.SplitN(status, " ", 2)[0] {
+	case "UP", "NOLB", "DRAIN":
+		return api.LB_BACKEND_HEALTH_STATUS_UP
+	case "DOWN":
+		return api.LB_BACKEND_HEALTH_STATUS_DOWN
+	}
+	// "no check", "MAINT", etc.
+	return api.LB_BACKEND_HEALTH_STATUS_UNKNOWN
+}
+
 func (h *ApiHelper) doHb(ctx context.Context) (*models.LoadbalancerAgent, error) {
 	// TODO check if things changed recently
 	s := h.adminClientSession(ctx)
@@ -374,6 +482,9 @@ func (h *ApiHelper) doUseCorpus(ctx context.Context) {
 		return
 	}
 	log.Infof("make effect new corpus and params")
+	if h.accessLogHelper != nil {
+		h.accessLogHelper.UseAgentParams(h.agentParams)
+	}
 	cmdData := &LbagentCmdUseCorpusData{
 		Corpus:      h.corpus,
 		AgentParams: h.agentParams,
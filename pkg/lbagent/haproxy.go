@@ -117,6 +117,7 @@ func (h *HaproxyHelper) handleUseCorpusCmd(ctx context.Context, cmd *LbagentCmd)
 		{
 			opt := fmt.Sprintf("stats socket %s expose-fd listeners", h.haproxyStatsSocketFile())
 			agentParams.SetHaproxyParams("global_stats_socket", opt)
+			agentParams.SetHaproxyParams("access_log_socket", h.opts.haproxyAccessLogSocketFile())
 		}
 		var genHaproxyConfigsResult *agentmodels.GenHaproxyConfigsResult
 		var err error
@@ -207,6 +208,8 @@ func (h *HaproxyHelper) useConfigs(ctx context.Context, d string) error {
 		return err
 	}
 	haproxyConfD := h.haproxyConfD()
+	// config dir currently in use, ignore error as it may not exist yet
+	oldConfD, _ := os.Readlink(haproxyConfD)
 	gobetweenJson := filepath.Join(h.opts.haproxyConfigDir, "gobetween.json")
 	keepalivedConf := filepath.Join(h.opts.haproxyConfigDir, "keepalived.conf")
 	telegrafConf := filepath.Join(h.opts.haproxyConfigDir, "telegraf.conf")
@@ -227,7 +230,7 @@ func (h *HaproxyHelper) useConfigs(ctx context.Context, d string) error {
 		var err error
 		{
 			// reload haproxy
-			err = h.reloadHaproxy(ctx)
+			err = h.reloadOrUpdateHaproxy(ctx, oldConfD, d)
 			if err != nil {
 				errs = append(errs, err)
 			}
@@ -258,11 +261,11 @@ func (h *HaproxyHelper) haproxyConfD() string {
 }
 
 func (h *HaproxyHelper) haproxyPidFile() string {
-	return filepath.Join(h.opts.haproxyRunDir, "haproxy.pid")
+	return h.opts.haproxyPidFile()
 }
 
 func (h *HaproxyHelper) haproxyStatsSocketFile() string {
-	return filepath.Join(h.opts.haproxyRunDir, "haproxy.sock")
+	return h.opts.haproxyStatsSocketFile()
 }
 
 func (h *HaproxyHelper) reloadHaproxy(ctx context.Context) error {
@@ -0,0 +1,172 @@
+package lbagent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"yunion.io/x/log"
+
+	agentutils "yunion.io/x/onecloud/pkg/lbagent/utils"
+)
+
+// files in config dir that are not used by haproxy
+var haproxyRuntimeIgnoredFiles = map[string]bool{
+	"gobetween.json":  true,
+	"keepalived.conf": true,
+	"telegraf.conf":   true,
+}
+
+var haproxyServerWeightPat = regexp.MustCompile(`^(\s*server\s+(\S+)\s+\S+\s+weight\s+)(\d+)(.*)$`)
+
+// haproxyRuntimeCommands compares haproxy configs in oldDir and newDir.  If
+// they differ only in weight of servers, the changes are returned as
+// runtime api commands so that they can be applied without reloading.
+// Weight 0 means the server is being drained
+func haproxyRuntimeCommands(oldDir, newDir string) ([]string, error) {
+	oldFiles, err := haproxyConfigFiles(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := haproxyConfigFiles(newDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(oldFiles) != len(newFiles) {
+		return nil, fmt.Errorf("config files added or removed")
+	}
+	cmds := []string{}
+	for _, name := range newFiles {
+		oldData, err := ioutil.ReadFile(filepath.Join(oldDir, name))
+		if err != nil {
+			return nil, err
+		}
+		newData, err := ioutil.ReadFile(filepath.Join(newDir, name))
+		if err != nil {
+			return nil, err
+		}
+		// configs refer to files by absolute path of the config dir
+		oldData = bytes.Replace(oldData, []byte(oldDir), []byte(newDir), -1)
+		if bytes.Equal(oldData, newData) {
+			continue
+		}
+		if filepath.Ext(name) != "."+agentutils.HaproxyCfgExt {
+			return nil, fmt.Errorf("%s changed", name)
+		}
+		fileCmds, err := haproxyCfgWeightCommands(string(oldData), string(newData))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		cmds = append(cmds, fileCmds...)
+	}
+	return cmds, nil
+}
+
+func haproxyConfigFiles(dir string) ([]string, error) {
+	names := []string{}
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if !haproxyRuntimeIgnoredFiles[name] {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func haproxyCfgWeightCommands(oldCfg, newCfg string) ([]string, error) {
+	oldLines := strings.Split(oldCfg, "\n")
+	newLines := strings.Split(newCfg, "\n")
+	if len(oldLines) != len(newLines) {
+		return nil, fmt.Errorf("lines added or removed")
+	}
+	cmds := []string{}
+	proxy := ""
+	for i, newLine := range newLines {
+		oldLine := oldLines[i]
+		if fields := strings.Fields(newLine); len(fields) >= 2 {
+			switch fields[0] {
+			case "backend", "listen":
+				proxy = fields[1]
+			case "frontend", "global", "defaults":
+				proxy = ""
+			}
+		}
+		if oldLine == newLine {
+			continue
+		}
+		oldM := haproxyServerWeightPat.FindStringSubmatch(oldLine)
+		newM := haproxyServerWeightPat.FindStringSubmatch(newLine)
+		if oldM == nil || newM == nil || oldM[1] != newM[1] || oldM[4] != newM[4] || proxy == "" {
+			return nil, fmt.Errorf("line %d changed", i+1)
+		}
+		server := fmt.Sprintf("%s/%s", proxy, newM[2])
+		oldWeight, _ := strconv.Atoi(oldM[3])
+		newWeight, _ := strconv.Atoi(newM[3])
+		switch {
+		case newWeight == 0:
+			cmds = append(cmds, fmt.Sprintf("set server %s state drain", server))
+		case oldWeight == 0:
+			cmds = append(cmds,
+				fmt.Sprintf("set server %s state ready", server),
+				fmt.Sprintf("set weight %s %d", server, newWeight),
+			)
+		default:
+			cmds = append(cmds, fmt.Sprintf("set weight %s %d", server, newWeight))
+		}
+	}
+	return cmds, nil
+}
+
+// applyHaproxyRuntimeCommands runs commands one by one.  Commands mentioned
+// above respond nothing on success
+func (h *HaproxyHelper) applyHaproxyRuntimeCommands(cmds []string) error {
+	statsSocket := h.haproxyStatsSocketFile()
+	for _, cmd := range cmds {
+		resp, err := agentutils.HaproxyRuntimeCommand(statsSocket, cmd)
+		if err != nil {
+			return err
+		}
+		if resp = strings.TrimSpace(resp); resp != "" {
+			return fmt.Errorf("haproxy runtime command %q: %s", cmd, resp)
+		}
+		log.Infof("haproxy runtime command: %s", cmd)
+	}
+	return nil
+}
+
+// reloadOrUpdateHaproxy applies changes from oldDir to newDir through
+// runtime api if possible, otherwise haproxy is reloaded
+func (h *HaproxyHelper) reloadOrUpdateHaproxy(ctx context.Context, oldDir, newDir string) error {
+	if oldDir == "" || agentutils.ReadPidFile(h.haproxyPidFile()) == nil {
+		return h.reloadHaproxy(ctx)
+	}
+	cmds, err := haproxyRuntimeCommands(oldDir, newDir)
+	if err != nil {
+		log.Infof("haproxy config: %s, reload required", err)
+		return h.reloadHaproxy(ctx)
+	}
+	if err := h.applyHaproxyRuntimeCommands(cmds); err != nil {
+		log.Errorf("apply haproxy runtime changes: %s, reloading", err)
+		return h.reloadHaproxy(ctx)
+	}
+	return nil
+}
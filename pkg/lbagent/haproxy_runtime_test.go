@@ -0,0 +1,91 @@
+package lbagent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHaproxyCfgWeightCommands(t *testing.T) {
+	cfg := func(w0, w1 string) string {
+		return `
+frontend listener-lbl0
+	bind 10.0.0.1:80
+	default_backend backends_listener_default-lbl0
+
+backend backends_listener_default-lbl0
+	mode http
+	balance roundrobin
+	server lbb0 192.168.0.10:80 weight ` + w0 + ` check rise 2 fall 3 inter 5s
+	server lbb1 192.168.0.11:80 weight ` + w1 + ` check rise 2 fall 3 inter 5s
+`
+	}
+	cases := []struct {
+		name    string
+		oldCfg  string
+		newCfg  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "unchanged",
+			oldCfg: cfg("1", "1"),
+			newCfg: cfg("1", "1"),
+			want:   []string{},
+		},
+		{
+			name:   "weight",
+			oldCfg: cfg("90", "10"),
+			newCfg: cfg("50", "50"),
+			want: []string{
+				"set weight backends_listener_default-lbl0/lbb0 50",
+				"set weight backends_listener_default-lbl0/lbb1 50",
+			},
+		},
+		{
+			name:   "drain",
+			oldCfg: cfg("1", "1"),
+			newCfg: cfg("0", "1"),
+			want: []string{
+				"set server backends_listener_default-lbl0/lbb0 state drain",
+			},
+		},
+		{
+			name:   "undrain",
+			oldCfg: cfg("0", "1"),
+			newCfg: cfg("3", "1"),
+			want: []string{
+				"set server backends_listener_default-lbl0/lbb0 state ready",
+				"set weight backends_listener_default-lbl0/lbb0 3",
+			},
+		},
+		{
+			name:    "other changes",
+			oldCfg:  cfg("1", "1"),
+			newCfg:  cfg("1", "1 backup"),
+			wantErr: true,
+		},
+		{
+			name:    "server added",
+			oldCfg:  cfg("1", "1"),
+			newCfg:  cfg("1", "1") + "	server lbb2 192.168.0.12:80 weight 1\n",
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := haproxyCfgWeightCommands(c.oldCfg, c.newCfg)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("want %v, got %v", c.want, got)
+			}
+		})
+	}
+}
@@ -7,12 +7,16 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 
 	"yunion.io/x/log"
 
+	api "yunion.io/x/onecloud/pkg/apis/compute"
 	agentutils "yunion.io/x/onecloud/pkg/lbagent/utils"
+	"yunion.io/x/onecloud/pkg/mcclient/models"
 )
 
 var haproxyConfigErrNop = errors.New("nop haproxy config snippet")
@@ -59,6 +63,10 @@ func (b *LoadbalancerCorpus) GenHaproxyConfigs(dir string, opts *AgentParams) (*
 			}
 		}
 		for _, lbcert := range b.LoadbalancerCertificates {
+			if lbcert.Certificate == "" {
+				// acme certificate not issued yet
+				continue
+			}
 			d := []byte(lbcert.Certificate)
 			if d[len(d)-1] != '\n' {
 				d = append(d, '\n')
@@ -72,6 +80,9 @@ func (b *LoadbalancerCorpus) GenHaproxyConfigs(dir string, opts *AgentParams) (*
 			}
 		}
 	}
+	if err := b.genHaproxyConfigAcmeChallenges(dir); err != nil {
+		return nil, err
+	}
 	for _, lbacl := range b.LoadbalancerAcls {
 		cidrs := []string{}
 		if lbacl.AclEntries != nil {
@@ -149,6 +160,80 @@ func (b *LoadbalancerCorpus) GenHaproxyConfigs(dir string, opts *AgentParams) (*
 	return r, nil
 }
 
+const haproxyAcmeChallengesDir = "acme-challenges"
+
+// acme tokens are base64url encoded.  Check it anyway as they are used in
+// file names and haproxy config
+var haproxyAcmeTokenRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func haproxyAcmeChallengeBackendId(token string) string {
+	return fmt.Sprintf("acme_challenge-%s", token)
+}
+
+// acmeChallenges returns pending acme http-01 challenges of all
+// certificates, sorted by token for stable config output
+func (b *LoadbalancerCorpus) acmeChallenges() []*models.LoadbalancerAcmeChallenge {
+	challenges := []*models.LoadbalancerAcmeChallenge{}
+	for _, lbcert := range b.LoadbalancerCertificates {
+		if lbcert.AcmeChallenges == nil {
+			continue
+		}
+		for _, challenge := range *lbcert.AcmeChallenges {
+			if !haproxyAcmeTokenRegexp.MatchString(challenge.Token) {
+				continue
+			}
+			challenges = append(challenges, challenge)
+		}
+	}
+	sort.Slice(challenges, func(i, j int) bool {
+		return challenges[i].Token < challenges[j].Token
+	})
+	return challenges
+}
+
+// genHaproxyConfigAcmeChallenges generates one backend for each acme
+// challenge.  The backend responds with key authorization by denying
+// requests with status 200, whose errorfile is the full http response
+func (b *LoadbalancerCorpus) genHaproxyConfigAcmeChallenges(dir string) error {
+	challenges := b.acmeChallenges()
+	if len(challenges) == 0 {
+		return nil
+	}
+	base := filepath.Join(dir, haproxyAcmeChallengesDir)
+	baseFinal := filepath.Join(agentutils.DirStagingToFinal(dir), haproxyAcmeChallengesDir)
+	if err := os.MkdirAll(base, agentutils.FileModeDir); err != nil {
+		return fmt.Errorf("mkdir %s: %s", base, err)
+	}
+	lines := []string{"## acme http-01 challenges", ""}
+	for _, challenge := range challenges {
+		fn := fmt.Sprintf("%s.http", challenge.Token)
+		resp := fmt.Sprintf("HTTP/1.0 200 OK\r\n"+
+			"Cache-Control: no-cache\r\n"+
+			"Connection: close\r\n"+
+			"Content-Type: text/plain\r\n"+
+			"Content-Length: %d\r\n"+
+			"\r\n"+
+			"%s", len(challenge.KeyAuthorization), challenge.KeyAuthorization)
+		p := filepath.Join(base, fn)
+		if err := ioutil.WriteFile(p, []byte(resp), agentutils.FileModeFile); err != nil {
+			return fmt.Errorf("write acme challenge %s: %s", challenge.Token, err)
+		}
+		lines = append(lines,
+			fmt.Sprintf("# domain %s", challenge.Domain),
+			fmt.Sprintf("backend %s", haproxyAcmeChallengeBackendId(challenge.Token)),
+			"	mode http",
+			fmt.Sprintf("	errorfile 200 %s", filepath.Join(baseFinal, fn)),
+			"	http-request deny deny_status 200",
+			"",
+		)
+	}
+	p := filepath.Join(dir, "02-haproxy.cfg")
+	if err := ioutil.WriteFile(p, []byte(strings.Join(lines, "\n")), agentutils.FileModeFile); err != nil {
+		return fmt.Errorf("write 02-haproxy.cfg: %s", err)
+	}
+	return nil
+}
+
 func (b *LoadbalancerCorpus) genHaproxyConfigCommon(lb *Loadbalancer, listener *LoadbalancerListener, opts *AgentParams) map[string]interface{} {
 	data := map[string]interface{}{
 		"comment":       fmt.Sprintf("%s(%s)", listener.Name, listener.Id),
@@ -175,11 +260,34 @@ func (b *LoadbalancerCorpus) genHaproxyConfigCommon(lb *Loadbalancer, listener *
 		agentHaproxyParams := opts.AgentModel.Params.Haproxy
 		if agentHaproxyParams.GlobalLog != "" {
 			if listener.ListenerType == "http" && agentHaproxyParams.LogHttp {
-				data["log"] = true
+				data["log"] = "option httplog clf"
 			} else if listener.ListenerType == "tcp" && agentHaproxyParams.LogTcp {
-				data["log"] = true
+				data["log"] = "option tcplog"
 			}
 		}
+		accessLogSocket, _ := opts.getXxParams("haproxy", "access_log_socket").(string)
+		if agentHaproxyParams.AccessLog && accessLogSocket != "" {
+			// access logs are parsed by lbagent, which expects the
+			// default httplog and tcplog format.  Log targets from
+			// defaults section are no longer inherited once the proxy
+			// has its own, so "log global" has to be repeated here.
+			// Normal sessions are needed for request stats
+			switch listener.ListenerType {
+			case "http", "https":
+				data["log"] = "option httplog"
+			case "tcp":
+				data["log"] = "option tcplog"
+			}
+			accessLog := []string{}
+			if agentHaproxyParams.GlobalLog != "" {
+				accessLog = append(accessLog, "log global")
+			}
+			accessLog = append(accessLog,
+				fmt.Sprintf("log %s len 4096 local0 info", accessLogSocket),
+				"no option dontlog-normal",
+			)
+			data["access_log"] = strings.Join(accessLog, "\n\t")
+		}
 	}
 	if listener.AclStatus == "on" {
 		lbacl, ok := b.LoadbalancerAcls[listener.AclId]
@@ -219,7 +327,82 @@ func (b *LoadbalancerCorpus) genHaproxyConfigCommon(lb *Loadbalancer, listener *
 	return data
 }
 
-func (b *LoadbalancerCorpus) genHaproxyConfigBackend(data map[string]interface{}, lb *Loadbalancer, listener *LoadbalancerListener, backendGroup *LoadbalancerBackendGroup) error {
+// haproxyBackendsSorted returns backends of the group ordered by id so that
+// the rendered config is stable between runs
+func haproxyBackendsSorted(backendGroup *LoadbalancerBackendGroup) []*LoadbalancerBackend {
+	backends := make([]*LoadbalancerBackend, 0, len(backendGroup.backends))
+	for _, backend := range backendGroup.backends {
+		backends = append(backends, backend)
+	}
+	sort.Slice(backends, func(i, j int) bool {
+		return backends[i].Id < backends[j].Id
+	})
+	return backends
+}
+
+// haproxyServerWeights returns haproxy weights of servers keyed by backend
+// id.  Draining servers get weight 0.  Configured weights are ignored by
+// "rr" scheduler, all servers get the same weight 1.
+//
+// Without a canary group, configured weights are used as is.  With a canary
+// group, canaryWeight percent of requests go to the canary group and the
+// rest to the main group.  Within each group requests are distributed by
+// configured weights, scaled to fit in haproxy weight range [0, 256]
+func haproxyServerWeights(scheduler string, backendGroup, canaryGroup *LoadbalancerBackendGroup, canaryWeight int) map[string]int {
+	backendWeight := func(backend *LoadbalancerBackend) int {
+		if backend.DrainState != api.LB_BACKEND_DRAIN_STATE_NONE {
+			return 0
+		}
+		if scheduler == "rr" {
+			return 1
+		}
+		return backend.Weight
+	}
+	weights := map[string]int{}
+	if canaryGroup == nil {
+		for _, backend := range backendGroup.backends {
+			weights[backend.Id] = backendWeight(backend)
+		}
+		return weights
+	}
+	if canaryWeight < 0 {
+		canaryWeight = 0
+	} else if canaryWeight > 100 {
+		canaryWeight = 100
+	}
+	groupShares := []struct {
+		group *LoadbalancerBackendGroup
+		share int
+	}{
+		{backendGroup, 100 - canaryWeight},
+		{canaryGroup, canaryWeight},
+	}
+	for _, gs := range groupShares {
+		total := 0
+		for _, backend := range gs.group.backends {
+			if bw := backendWeight(backend); bw > 0 {
+				total += bw
+			}
+		}
+		for _, backend := range gs.group.backends {
+			bw := backendWeight(backend)
+			if bw <= 0 || gs.share == 0 {
+				weights[backend.Id] = 0
+				continue
+			}
+			// round to nearest, but keep at least 1 so that servers
+			// with a share of requests are not put into drain mode
+			w := (2*gs.share*bw*256 + 100*total) / (2 * 100 * total)
+			if w < 1 {
+				w = 1
+			}
+			weights[backend.Id] = w
+		}
+	}
+	return weights
+}
+
+func (b *LoadbalancerCorpus) genHaproxyConfigBackend(data map[string]interface{}, lb *Loadbalancer, listener *LoadbalancerListener, backendGroup, canaryGroup *LoadbalancerBackendGroup, canaryWeight int) error {
 	var mode string
 	var balanceAlgorithm string
 	var httpCheck, httpCheckExpect string
@@ -275,14 +458,19 @@ func (b *LoadbalancerCorpus) genHaproxyConfigBackend(data map[string]interface{}
 		}
 	}
 	{
+		// servers of the canary group are always rendered when it's set.
+		// Weight 0 keeps draining servers in drain mode after reload.
+		// Weight is the only field that differs, so that drain and canary
+		// shift can be applied through runtime api
+		backends := haproxyBackendsSorted(backendGroup)
+		if canaryGroup != nil {
+			backends = append(backends, haproxyBackendsSorted(canaryGroup)...)
+		}
+		weights := haproxyServerWeights(listener.Scheduler, backendGroup, canaryGroup, canaryWeight)
 		serverLines := []string{}
-		for _, backend := range backendGroup.backends {
+		for _, backend := range backends {
 			serverLine := fmt.Sprintf("server %s %s:%d", backend.Id, backend.Address, backend.Port)
-			if listener.Scheduler == "rr" {
-				serverLine += " weight 1"
-			} else {
-				serverLine += fmt.Sprintf(" weight %d", backend.Weight)
-			}
+			serverLine += fmt.Sprintf(" weight %d", weights[backend.Id])
 			if checkEnable {
 				serverLine += fmt.Sprintf(" check rise %d fall %d inter %ds",
 					listener.HealthCheckRise, listener.HealthCheckFall, listener.HealthCheckInterval)
@@ -355,8 +543,76 @@ func (b *LoadbalancerCorpus) genHaproxyConfigHttpRate(data map[string]interface{
 	return nil
 }
 
+func haproxyConfigHeaderActions(directive string, headerActions *models.LoadbalancerHTTPHeaderActions) []string {
+	lines := []string{}
+	if headerActions == nil {
+		return lines
+	}
+	for _, headerAction := range *headerActions {
+		switch headerAction.Action {
+		case "set", "add":
+			// value is a log-format string, literal '%' must be escaped
+			value := strings.Replace(headerAction.Value, "%", "%%", -1)
+			lines = append(lines, fmt.Sprintf("%s %s-header %s \"%s\"",
+				directive, headerAction.Action, headerAction.Name, value))
+		case "del":
+			lines = append(lines, fmt.Sprintf("%s del-header %s",
+				directive, headerAction.Name))
+		}
+	}
+	return lines
+}
+
+func (b *LoadbalancerCorpus) genHaproxyConfigHttpRuleActions(data map[string]interface{}, listener *LoadbalancerListener, rule *LoadbalancerListenerRule) error {
+	requestRules := []string{}
+	switch rule.Action {
+	case "redirect":
+		code := rule.RedirectCode
+		if code == 0 {
+			code = 302
+		}
+		if rule.RedirectHost == "" && rule.RedirectPath == "" {
+			requestRules = append(requestRules, fmt.Sprintf("http-request redirect scheme %s code %d",
+				rule.RedirectScheme, code))
+			break
+		}
+		// parts not specified are taken from the request
+		scheme := rule.RedirectScheme
+		if scheme == "" {
+			scheme = listener.ListenerType
+		}
+		host := rule.RedirectHost
+		if host == "" {
+			host = "%[req.hdr(host)]"
+		}
+		path := strings.Replace(rule.RedirectPath, "%", "%%", -1)
+		if path == "" {
+			path = "%[capture.req.uri]"
+		}
+		requestRules = append(requestRules, fmt.Sprintf("http-request redirect location %s://%s%s code %d",
+			scheme, host, path, code))
+	case "fixed_response":
+		requestRules = append(requestRules, fmt.Sprintf("http-request deny deny_status %d",
+			rule.FixedResponseCode))
+	default:
+		if rule.RewritePath != "" && rule.Path != "" {
+			// replace the matched prefix
+			requestRules = append(requestRules, fmt.Sprintf("http-request set-path %s%%[path,regsub(^.{%d},)]",
+				strings.Replace(rule.RewritePath, "%", "%%", -1), len(rule.Path)))
+		}
+		requestRules = append(requestRules, haproxyConfigHeaderActions("http-request", rule.RequestHeaderActions)...)
+		data["http_response_rules"] = haproxyConfigHeaderActions("http-response", rule.ResponseHeaderActions)
+	}
+	data["http_request_rules"] = requestRules
+	return nil
+}
+
 func (b *LoadbalancerCorpus) genHaproxyConfigHttp(buf *bytes.Buffer, listener *LoadbalancerListener, opts *AgentParams) error {
 	lb := listener.loadbalancer
+	if listener.ListenerType == "https" && listener.certificate != nil && listener.certificate.Certificate == "" {
+		// acme certificate not issued yet
+		return haproxyConfigErrNop
+	}
 	rules := listener.rules.OrderedEnabledList()
 	data := b.genHaproxyConfigCommon(lb, listener, opts)
 	ruleBackendIdGen := func(id string) string {
@@ -370,9 +626,54 @@ func (b *LoadbalancerCorpus) genHaproxyConfigHttp(buf *bytes.Buffer, listener *L
 		data["xforwardedfor"] = listener.XForwardedFor
 		data["gzip"] = listener.Gzip
 	}
-	{ // use_backend rule.Id if xx
+	{
+		// use_backend rule.Id if xx
+		//
+		// Each rule has its own backend section, in which redirect, fixed
+		// response and rewrite actions take place, so that rule order
+		// decided by use_backend lines is honoured
 		ruleLines := []string{}
+		backends := []interface{}{}
+		if listener.ListenerType == "http" {
+			// acme http-01 challenges take precedence over rules
+			for _, challenge := range b.acmeChallenges() {
+				ruleLines = append(ruleLines, fmt.Sprintf("use_backend %s if { path %s%s }",
+					haproxyAcmeChallengeBackendId(challenge.Token),
+					"/.well-known/acme-challenge/", challenge.Token))
+			}
+		}
 		for _, rule := range rules {
+			backendData := map[string]interface{}{
+				"id": ruleBackendIdGen(rule.Id),
+			}
+			switch rule.Action {
+			case "redirect", "fixed_response":
+				backendData["comment"] = fmt.Sprintf("rule %s(%s) action %s",
+					rule.Name, rule.Id, rule.Action)
+				backendData["action_backend"] = true
+			default:
+				// NOTE dup is ok
+				if rule.BackendGroupId == "" {
+					// just in case
+					continue
+				}
+				backendGroup := lb.backendGroups[rule.BackendGroupId]
+				canaryGroup := lb.backendGroups[rule.CanaryBackendGroupId]
+				backendData["comment"] = fmt.Sprintf("rule %s(%s) backendGroup %s(%s)",
+					rule.Name, rule.Id,
+					backendGroup.Name, backendGroup.Id)
+				if err := b.genHaproxyConfigBackend(backendData, lb, listener, backendGroup, canaryGroup, rule.CanaryWeight); err != nil {
+					return err
+				}
+				if err := b.genHaproxyConfigHttpRate(backendData, rule.HTTPRequestRate, rule.HTTPRequestRatePerSrc); err != nil {
+					return err
+				}
+			}
+			if err := b.genHaproxyConfigHttpRuleActions(backendData, listener, rule); err != nil {
+				return err
+			}
+			backends = append(backends, backendData)
+
 			ruleLine := fmt.Sprintf("use_backend %s", ruleBackendIdGen(rule.Id))
 			if rule.Domain != "" || rule.Path != "" {
 				ruleLine += " if"
@@ -386,31 +687,6 @@ func (b *LoadbalancerCorpus) genHaproxyConfigHttp(buf *bytes.Buffer, listener *L
 			ruleLines = append(ruleLines, ruleLine)
 		}
 		data["rules"] = ruleLines
-	}
-	{
-		backends := []interface{}{}
-		// rules backend group
-		for _, rule := range rules {
-			// NOTE dup is ok
-			if rule.BackendGroupId == "" {
-				// just in case
-				continue
-			}
-			backendGroup := lb.backendGroups[rule.BackendGroupId]
-			backendData := map[string]interface{}{
-				"comment": fmt.Sprintf("rule %s(%s) backendGroup %s(%s)",
-					rule.Name, rule.Id,
-					backendGroup.Name, backendGroup.Id),
-				"id": ruleBackendIdGen(rule.Id),
-			}
-			if err := b.genHaproxyConfigBackend(backendData, lb, listener, backendGroup); err != nil {
-				return err
-			}
-			if err := b.genHaproxyConfigHttpRate(backendData, rule.HTTPRequestRate, rule.HTTPRequestRatePerSrc); err != nil {
-				return err
-			}
-			backends = append(backends, backendData)
-		}
 		// default backend group
 		if listener.BackendGroupId != "" {
 			backendGroup := lb.backendGroups[listener.BackendGroupId]
@@ -420,7 +696,8 @@ func (b *LoadbalancerCorpus) genHaproxyConfigHttp(buf *bytes.Buffer, listener *L
 					backendGroup.Name, backendGroup.Id),
 				"id": fmt.Sprintf("backends_listener_default-%s", listener.Id),
 			}
-			if err := b.genHaproxyConfigBackend(backendData, lb, listener, backendGroup); err != nil {
+			canaryGroup := lb.backendGroups[listener.CanaryBackendGroupId]
+			if err := b.genHaproxyConfigBackend(backendData, lb, listener, backendGroup, canaryGroup, listener.CanaryWeight); err != nil {
 				return err
 			}
 			if err := b.genHaproxyConfigHttpRate(backendData, listener.HTTPRequestRate, listener.HTTPRequestRatePerSrc); err != nil {
@@ -429,7 +706,7 @@ func (b *LoadbalancerCorpus) genHaproxyConfigHttp(buf *bytes.Buffer, listener *L
 			backends = append(backends, backendData)
 			data["default_backend"] = backendData
 		}
-		if len(backends) == 0 {
+		if len(backends) == 0 && len(ruleLines) == 0 {
 			// no backendgroup specified, nothing to serve
 			return haproxyConfigErrNop
 		}
@@ -450,7 +727,8 @@ func (b *LoadbalancerCorpus) genHaproxyConfigTcp(buf *bytes.Buffer, listener *Lo
 				backendGroup.Name, backendGroup.Id),
 			"id": fmt.Sprintf("backends_listener-%s", listener.Id),
 		}
-		err := b.genHaproxyConfigBackend(backendData, lb, listener, backendGroup)
+		canaryGroup := lb.backendGroups[listener.CanaryBackendGroupId]
+		err := b.genHaproxyConfigBackend(backendData, lb, listener, backendGroup, canaryGroup, listener.CanaryWeight)
 		if err != nil {
 			return err
 		}
@@ -468,7 +746,8 @@ listen {{ .id }}
 	bind {{ .bind }}
 	mode tcp
 	{{- println }}
-	{{- if .log }}	{{ println "option tcplog" }} {{- end }}
+	{{- if .access_log }}	{{ println .access_log }} {{- end }}
+	{{- if .log }}	{{ println .log }} {{- end }}
 	{{- if .acl }}	{{ println .acl }} {{- end}}
 	{{- if .client_idle_timeout }}	timeout client {{ println .client_idle_timeout }} {{- end}}
 	default_backend {{ .backend.id }}
@@ -485,7 +764,8 @@ frontend {{ .id }}
 	bind {{ .bind }}
 	mode http
 	{{- println }}
-	{{- if .log }}	{{ println "option httplog clf" }} {{- end }}
+	{{- if .access_log }}	{{ println .access_log }} {{- end }}
+	{{- if .log }}	{{ println .log }} {{- end }}
 	{{- if .acl }}	{{ println .acl }} {{- end}}
 	{{- range .rate_rules }}	{{ println . }} {{- end }}
 	{{- if .client_request_timeout }}	timeout http-request {{ println .client_request_timeout }} {{- end}}
@@ -495,9 +775,21 @@ frontend {{ .id }}
 	{{- range .rules }}	{{ println . }} {{- end }}
 	{{- if .default_backend.id }}	default_backend {{ println .default_backend.id }} {{- end }}
 {{- range .backends }}
+{{- if .action_backend }}
+{{- template "actionBackend" . }}
+{{- else }}
 {{- template "backend" . }}
 {{- end }}
 {{- end }}
+{{- end }}
+
+{{ define "actionBackend" -}}
+# {{ .comment }}
+backend {{ .id }}
+	mode http
+	{{- println }}
+	{{- range .http_request_rules }}	{{ println . }} {{- end }}
+{{- end }}
 
 {{ define "backend" -}}
 # {{ .comment }}
@@ -510,6 +802,8 @@ backend {{ .id }}
 	balance {{ .balanceAlgorithm }}
 	{{- println }}
 	{{- range .rate_rules }}	{{ println . }} {{- end }}
+	{{- range .http_request_rules }}	{{ println . }} {{- end }}
+	{{- range .http_response_rules }}	{{ println . }} {{- end }}
 	{{- if .backend_connect_timeout }}	timeout connect {{ println .backend_connect_timeout }} {{- end}}
 	{{- if .backend_idle_timeout }}	timeout server {{ println .backend_idle_timeout }} {{- end}}
 	{{- if .timeout_check }}	{{ println .timeout_check }} {{- end }}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/mcclient/models"
+)
+
+func newTestBackendGroup(weights map[string]int, draining ...string) *LoadbalancerBackendGroup {
+	backendGroup := &LoadbalancerBackendGroup{
+		backends: LoadbalancerBackends{},
+	}
+	for id, weight := range weights {
+		backend := &models.LoadbalancerBackend{
+			Weight:     weight,
+			DrainState: api.LB_BACKEND_DRAIN_STATE_NONE,
+		}
+		backend.Id = id
+		backendGroup.backends[id] = &LoadbalancerBackend{LoadbalancerBackend: backend}
+	}
+	for _, id := range draining {
+		backendGroup.backends[id].DrainState = api.LB_BACKEND_DRAIN_STATE_DRAINING
+	}
+	return backendGroup
+}
+
+func TestHaproxyServerWeights(t *testing.T) {
+	cases := []struct {
+		name         string
+		scheduler    string
+		main         *LoadbalancerBackendGroup
+		canary       *LoadbalancerBackendGroup
+		canaryWeight int
+		want         map[string]int
+	}{
+		{
+			name:      "no canary",
+			scheduler: "wrr",
+			main:      newTestBackendGroup(map[string]int{"a": 1, "b": 3, "c": 5}, "c"),
+			want:      map[string]int{"a": 1, "b": 3, "c": 0},
+		},
+		{
+			name:      "no canary rr",
+			scheduler: "rr",
+			main:      newTestBackendGroup(map[string]int{"a": 1, "b": 3, "c": 5}, "c"),
+			want:      map[string]int{"a": 1, "b": 1, "c": 0},
+		},
+		{
+			name:         "canary 10 percent",
+			main:         newTestBackendGroup(map[string]int{"a": 1, "b": 1}),
+			canary:       newTestBackendGroup(map[string]int{"c": 1}),
+			canaryWeight: 10,
+			want:         map[string]int{"a": 115, "b": 115, "c": 26},
+		},
+		{
+			name:         "canary weighted within group",
+			main:         newTestBackendGroup(map[string]int{"a": 10}),
+			canary:       newTestBackendGroup(map[string]int{"c": 1, "d": 3}),
+			canaryWeight: 50,
+			want:         map[string]int{"a": 128, "c": 32, "d": 96},
+		},
+		{
+			name:         "canary rr",
+			scheduler:    "rr",
+			main:         newTestBackendGroup(map[string]int{"a": 10}),
+			canary:       newTestBackendGroup(map[string]int{"c": 1, "d": 3}),
+			canaryWeight: 50,
+			want:         map[string]int{"a": 128, "c": 64, "d": 64},
+		},
+		{
+			name:         "canary draining server",
+			main:         newTestBackendGroup(map[string]int{"a": 1}),
+			canary:       newTestBackendGroup(map[string]int{"c": 1, "d": 1}, "d"),
+			canaryWeight: 50,
+			want:         map[string]int{"a": 128, "c": 128, "d": 0},
+		},
+		{
+			name:         "small share kept",
+			main:         newTestBackendGroup(map[string]int{"a": 1}),
+			canary:       newTestBackendGroup(map[string]int{"c": 1, "d": 256}),
+			canaryWeight: 1,
+			want:         map[string]int{"a": 253, "c": 1, "d": 3},
+		},
+		{
+			name:         "canary off",
+			main:         newTestBackendGroup(map[string]int{"a": 1}),
+			canary:       newTestBackendGroup(map[string]int{"c": 1}),
+			canaryWeight: 0,
+			want:         map[string]int{"a": 256, "c": 0},
+		},
+		{
+			name:         "all to canary",
+			main:         newTestBackendGroup(map[string]int{"a": 1}),
+			canary:       newTestBackendGroup(map[string]int{"c": 1}),
+			canaryWeight: 100,
+			want:         map[string]int{"a": 0, "c": 256},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := haproxyServerWeights(c.scheduler, c.main, c.canary, c.canaryWeight)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("want %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestHaproxyConfigHeaderActions(t *testing.T) {
+	headerActions := &models.LoadbalancerHTTPHeaderActions{
+		{Action: "set", Name: "X-Ratio", Value: "50%"},
+		{Action: "add", Name: "X-Src", Value: "lb"},
+		{Action: "del", Name: "Server"},
+	}
+	want := []string{
+		`http-request set-header X-Ratio "50%%"`,
+		`http-request add-header X-Src "lb"`,
+		`http-request del-header Server`,
+	}
+	got := haproxyConfigHeaderActions("http-request", headerActions)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
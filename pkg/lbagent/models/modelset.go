@@ -170,6 +170,9 @@ func (lst OrderedLoadbalancerListenerRuleList) Len() int {
 }
 
 func (lst OrderedLoadbalancerListenerRuleList) Less(i, j int) bool {
+	if lst[i].Priority != lst[j].Priority {
+		return lst[i].Priority < lst[j].Priority
+	}
 	ldi := len(lst[i].Domain)
 	ldj := len(lst[j].Domain)
 	if ldi < ldj {
@@ -195,7 +198,7 @@ func (set LoadbalancerListenerRules) OrderedEnabledList() OrderedLoadbalancerLis
 			rules = append(rules, rule)
 		}
 	}
-	// more specific rules come first
+	// rules with higher priority, then more specific ones come first
 	sort.Sort(sort.Reverse(rules))
 	return rules
 }
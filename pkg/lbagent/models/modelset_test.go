@@ -86,3 +86,39 @@ func TestLoadbalancerListenerRules_OrderedEnabledList(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadbalancerListenerRules_OrderedEnabledListPriority(t *testing.T) {
+	set := LoadbalancerListenerRules(map[string]*LoadbalancerListenerRule{
+		"a.com/img": {
+			LoadbalancerListenerRule: &models.LoadbalancerListenerRule{
+				Domain: "a.com",
+				Path:   "/img",
+			},
+		},
+		"/": {
+			LoadbalancerListenerRule: &models.LoadbalancerListenerRule{
+				Domain:   "",
+				Path:     "/",
+				Priority: 10,
+			},
+		},
+		"a.com": {
+			LoadbalancerListenerRule: &models.LoadbalancerListenerRule{
+				Domain:   "a.com",
+				Path:     "",
+				Priority: 5,
+			},
+		},
+	})
+	for _, rule := range set {
+		rule.Status = "enabled"
+	}
+	rules := set.OrderedEnabledList()
+	want := []string{"/", "a.com", "a.com/img"}
+	for i, rule := range rules {
+		got := rule.Domain + rule.Path
+		if got != want[i] {
+			t.Errorf("rule %d: want %s, got %s", i, want[i], got)
+		}
+	}
+}
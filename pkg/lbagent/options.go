@@ -19,6 +19,8 @@ type LbagentOptions struct {
 
 	DataPreserveN int `default:"8" help:"number of recent data to preserve on disk"`
 
+	AccessLogFlushInterval int `default:"10" help:"interval in seconds for writing collected access logs to influxdb"`
+
 	BaseDataDir      string // `required:"true"`
 	apiDataStoreDir  string
 	haproxyConfigDir string
@@ -71,3 +73,21 @@ func (opts *Options) initDirs() error {
 
 	return nil
 }
+
+// haproxyStatsSocketFile is shared by haproxy helper for reloading and api
+// helper for querying runtime states
+func (opts *Options) haproxyStatsSocketFile() string {
+	return filepath.Join(opts.haproxyRunDir, "haproxy.sock")
+}
+
+// haproxyPidFile is shared by haproxy helper for reloading and api helper
+// for finding old haproxy processes still serving sessions after reload
+func (opts *Options) haproxyPidFile() string {
+	return filepath.Join(opts.haproxyRunDir, "haproxy.pid")
+}
+
+// haproxyAccessLogSocketFile is where haproxy sends access logs to when it's
+// enabled
+func (opts *Options) haproxyAccessLogSocketFile() string {
+	return filepath.Join(opts.haproxyRunDir, "access_log.sock")
+}
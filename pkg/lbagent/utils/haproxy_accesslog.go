@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const haproxyLogTimeFmt = "02/Jan/2006:15:04:05.000"
+
+// HaproxyAccessLogRecord is parsed from haproxy logs in the default httplog
+// or tcplog format
+//
+// httplog: %ci:%cp [%tr] %ft %b/%s %TR/%Tw/%Tc/%Tr/%Ta %ST %B %CC %CS %tsc %ac/%fc/%bc/%sc/%rc %sq/%bq %hr %hs %{+Q}r
+// tcplog:  %ci:%cp [%t] %ft %b/%s %Tw/%Tc/%Tt %B %ts %ac/%fc/%bc/%sc/%rc %sq/%bq
+type HaproxyAccessLogRecord struct {
+	Time       time.Time
+	ClientAddr string
+	Frontend   string
+	Backend    string
+	Server     string
+	// Status is http status code, 0 for tcp logs, -1 when no response
+	// was sent
+	Status int
+	// Latency is the total active time in milliseconds, -1 when the
+	// session was aborted
+	Latency          int
+	Bytes            int64
+	TerminationState string
+	Request          string
+}
+
+// ParseHaproxySyslog parses rfc3164 syslog message sent by haproxy, e.g.
+//
+//	<134>Oct 18 12:00:00 haproxy[1234]: 10.0.0.1:51234 [18/Oct/2026:12:00:00.123] ...
+func ParseHaproxySyslog(msg string) (*HaproxyAccessLogRecord, error) {
+	i := strings.Index(msg, "]: ")
+	if !strings.HasPrefix(msg, "<") || i < 0 {
+		return nil, fmt.Errorf("not a syslog message")
+	}
+	return ParseHaproxyAccessLog(strings.TrimSpace(msg[i+3:]))
+}
+
+func ParseHaproxyAccessLog(s string) (*HaproxyAccessLogRecord, error) {
+	fields := strings.SplitN(s, " ", 12)
+	if len(fields) < 9 {
+		return nil, fmt.Errorf("not an access log: too few fields")
+	}
+	if !strings.HasPrefix(fields[1], "[") || !strings.HasSuffix(fields[1], "]") {
+		return nil, fmt.Errorf("not an access log: bad date field %q", fields[1])
+	}
+	t, err := time.ParseInLocation(haproxyLogTimeFmt, fields[1][1:len(fields[1])-1], time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("not an access log: %s", err)
+	}
+	r := &HaproxyAccessLogRecord{
+		Time:       t,
+		ClientAddr: fields[0],
+		// ssl frontends are suffixed with "~"
+		Frontend: strings.TrimSuffix(fields[2], "~"),
+	}
+	backendServer := strings.SplitN(fields[3], "/", 2)
+	if len(backendServer) != 2 {
+		return nil, fmt.Errorf("bad backend/server field %q", fields[3])
+	}
+	r.Backend, r.Server = backendServer[0], backendServer[1]
+	timers := strings.Split(fields[4], "/")
+	var bytesField string
+	switch len(timers) {
+	case 5:
+		if len(fields) < 11 {
+			return nil, fmt.Errorf("not an http access log: too few fields")
+		}
+		r.Status, err = strconv.Atoi(fields[5])
+		if err != nil {
+			return nil, fmt.Errorf("bad status %q", fields[5])
+		}
+		bytesField = fields[6]
+		r.TerminationState = fields[9]
+		if len(fields) == 12 {
+			// captured headers come before the request line
+			if i := strings.Index(fields[11], `"`); i >= 0 {
+				r.Request = strings.TrimSuffix(fields[11][i+1:], `"`)
+			}
+		}
+	case 3:
+		bytesField = fields[5]
+		r.TerminationState = fields[6]
+	default:
+		return nil, fmt.Errorf("bad timers field %q", fields[4])
+	}
+	r.Latency, err = strconv.Atoi(strings.TrimPrefix(timers[len(timers)-1], "+"))
+	if err != nil {
+		return nil, fmt.Errorf("bad timers field %q", fields[4])
+	}
+	r.Bytes, err = strconv.ParseInt(strings.TrimPrefix(bytesField, "+"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("bad bytes field %q", bytesField)
+	}
+	return r, nil
+}
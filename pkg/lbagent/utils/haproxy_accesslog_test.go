@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHaproxySyslog(t *testing.T) {
+	cases := []struct {
+		name    string
+		msg     string
+		want    HaproxyAccessLogRecord
+		wantErr bool
+	}{
+		{
+			name: "http",
+			msg:  `<134>Oct 18 12:00:00 haproxy[1234]: 10.0.0.1:51234 [18/Oct/2026:12:00:00.123] lbl0~ backends_listener_default-lbl0/lbb0 0/0/1/12/13 200 512 - - ---- 1/1/0/0/0 0/0 "GET /index.html HTTP/1.1"`,
+			want: HaproxyAccessLogRecord{
+				Time:             time.Date(2026, 10, 18, 12, 0, 0, 123000000, time.Local),
+				ClientAddr:       "10.0.0.1:51234",
+				Frontend:         "lbl0",
+				Backend:          "backends_listener_default-lbl0",
+				Server:           "lbb0",
+				Status:           200,
+				Latency:          13,
+				Bytes:            512,
+				TerminationState: "----",
+				Request:          "GET /index.html HTTP/1.1",
+			},
+		},
+		{
+			name: "http no server",
+			msg:  `<134>Oct 18 12:00:00 haproxy[1234]: 10.0.0.1:51234 [18/Oct/2026:12:00:00.123] lbl0 backends_listener_default-lbl0/<NOSRV> 0/-1/-1/-1/+2 503 212 - - SC-- 1/1/0/0/0 0/0 "GET / HTTP/1.1"`,
+			want: HaproxyAccessLogRecord{
+				Time:             time.Date(2026, 10, 18, 12, 0, 0, 123000000, time.Local),
+				ClientAddr:       "10.0.0.1:51234",
+				Frontend:         "lbl0",
+				Backend:          "backends_listener_default-lbl0",
+				Server:           "<NOSRV>",
+				Status:           503,
+				Latency:          2,
+				Bytes:            212,
+				TerminationState: "SC--",
+				Request:          "GET / HTTP/1.1",
+			},
+		},
+		{
+			name: "tcp",
+			msg:  `<134>Oct 18 12:00:00 haproxy[1234]: 10.0.0.1:51234 [18/Oct/2026:12:00:00.123] lbl1 backends_listener-lbl1/lbb1 0/1/3000 1024 -- 1/1/0/0/0 0/0`,
+			want: HaproxyAccessLogRecord{
+				Time:             time.Date(2026, 10, 18, 12, 0, 0, 123000000, time.Local),
+				ClientAddr:       "10.0.0.1:51234",
+				Frontend:         "lbl1",
+				Backend:          "backends_listener-lbl1",
+				Server:           "lbb1",
+				Latency:          3000,
+				Bytes:            1024,
+				TerminationState: "--",
+			},
+		},
+		{
+			name:    "not access log",
+			msg:     `<133>Oct 18 12:00:00 haproxy[1234]: Proxy lbl0 started.`,
+			wantErr: true,
+		},
+		{
+			name:    "not syslog",
+			msg:     `hello`,
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseHaproxySyslog(c.msg)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !got.Time.Equal(c.want.Time) {
+				t.Errorf("want time %s, got %s", c.want.Time, got.Time)
+			}
+			got.Time = c.want.Time
+			if *got != c.want {
+				t.Errorf("want %#v, got %#v", c.want, *got)
+			}
+		})
+	}
+}
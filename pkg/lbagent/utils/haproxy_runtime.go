@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	haproxyRuntimeDialTimeout = 3 * time.Second
+	haproxyRuntimeTimeout     = 10 * time.Second
+)
+
+// HaproxyRuntimeCommand sends one command to haproxy runtime api through the
+// stats socket and returns the whole response
+func HaproxyRuntimeCommand(socketFile string, cmd string) (string, error) {
+	conn, err := net.DialTimeout("unix", socketFile, haproxyRuntimeDialTimeout)
+	if err != nil {
+		return "", fmt.Errorf("dial haproxy stats socket %s: %s", socketFile, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(haproxyRuntimeTimeout))
+	if _, err := io.WriteString(conn, cmd+"\n"); err != nil {
+		return "", fmt.Errorf("haproxy runtime command %q: %s", cmd, err)
+	}
+	// haproxy closes the connection after responding in non-interactive
+	// mode
+	d, err := ioutil.ReadAll(conn)
+	if err != nil {
+		return "", fmt.Errorf("haproxy runtime command %q: read response: %s", cmd, err)
+	}
+	return string(d), nil
+}
+
+type HaproxyServerStat struct {
+	Proxy           string
+	Server          string
+	Status          string
+	CheckStatus     string
+	CurrentSessions int
+	Weight          int
+}
+
+// HaproxyShowStat returns stats of servers, excluding FRONTEND and BACKEND
+// summary lines
+func HaproxyShowStat(socketFile string) ([]*HaproxyServerStat, error) {
+	s, err := HaproxyRuntimeCommand(socketFile, "show stat")
+	if err != nil {
+		return nil, err
+	}
+	return ParseHaproxyStat(s)
+}
+
+// ParseHaproxyStat parses csv output of "show stat".  Columns are located by
+// the header line, which starts with "# "
+func ParseHaproxyStat(s string) ([]*HaproxyServerStat, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "# ")
+	if s == "" {
+		return nil, fmt.Errorf("empty haproxy stat")
+	}
+	r := csv.NewReader(bytes.NewBufferString(s))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse haproxy stat: %s", err)
+	}
+	cols := map[string]int{}
+	for i, name := range records[0] {
+		cols[name] = i
+	}
+	for _, name := range []string{"pxname", "svname", "status"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("parse haproxy stat: no %s column", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+	stats := []*HaproxyServerStat{}
+	for _, record := range records[1:] {
+		svname := field(record, "svname")
+		switch svname {
+		case "", "FRONTEND", "BACKEND":
+			continue
+		}
+		stat := &HaproxyServerStat{
+			Proxy:       field(record, "pxname"),
+			Server:      svname,
+			Status:      field(record, "status"),
+			CheckStatus: field(record, "check_status"),
+		}
+		stat.CurrentSessions, _ = strconv.Atoi(field(record, "scur"))
+		stat.Weight, _ = strconv.Atoi(field(record, "weight"))
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
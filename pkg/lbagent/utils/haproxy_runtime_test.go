@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseHaproxyStat(t *testing.T) {
+	s := `# pxname,svname,qcur,qmax,scur,smax,slim,stot,bin,bout,dreq,dresp,ereq,econ,eresp,wretr,wredis,status,weight,act,bck,chkfail,chkdown,lastchg,downtime,qlimit,pid,iid,sid,throttle,lbtot,tracked,type,rate,rate_lim,rate_max,check_status,
+listener-lbl0,FRONTEND,,,3,5,2000,10,1024,2048,0,0,0,,,,,OPEN,,,,,,,,,1,2,0,,,,0,0,0,3,,
+backends_listener_default-lbl0,lbb0,0,0,2,3,,8,512,1024,,0,,0,0,0,0,UP,1,1,0,0,0,100,0,,1,3,1,,8,,2,0,,3,L4OK,
+backends_listener_default-lbl0,lbb1,0,0,0,0,,2,512,1024,,0,,0,0,0,0,DOWN 1/2,10,1,0,1,1,5,5,,1,3,2,,2,,2,0,,1,L4CON,
+backends_listener_default-lbl0,BACKEND,0,0,2,3,200,10,1024,2048,0,0,,0,0,0,0,UP,11,2,0,,1,100,0,,1,3,0,,10,,1,0,,3,,
+`
+	stats, err := ParseHaproxyStat(s)
+	if err != nil {
+		t.Fatalf("parse: %s", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("want 2 server stats, got %d", len(stats))
+	}
+	want := []HaproxyServerStat{
+		{
+			Proxy:           "backends_listener_default-lbl0",
+			Server:          "lbb0",
+			Status:          "UP",
+			CheckStatus:     "L4OK",
+			CurrentSessions: 2,
+			Weight:          1,
+		},
+		{
+			Proxy:           "backends_listener_default-lbl0",
+			Server:          "lbb1",
+			Status:          "DOWN 1/2",
+			CheckStatus:     "L4CON",
+			CurrentSessions: 0,
+			Weight:          10,
+		},
+	}
+	for i := range want {
+		if *stats[i] != want[i] {
+			t.Errorf("stat %d: want %#v, got %#v", i, want[i], *stats[i])
+		}
+	}
+}
+
+func TestParseHaproxyStatNoHeader(t *testing.T) {
+	if _, err := ParseHaproxyStat("\n"); err == nil {
+		t.Errorf("want error for empty input")
+	}
+	if _, err := ParseHaproxyStat("a,b,c\n1,2,3\n"); err == nil {
+		t.Errorf("want error for missing columns")
+	}
+}
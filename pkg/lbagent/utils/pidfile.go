@@ -1,9 +1,11 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
@@ -34,3 +36,30 @@ func WritePidFile(pid int, pidFile string) error {
 	err := ioutil.WriteFile(pidFile, []byte(data), FileModeFile)
 	return err
 }
+
+// FindProcessesByArg returns pids of processes with arg in their command
+// line arguments
+func FindProcessesByArg(arg string) []int {
+	cmdlineFiles, err := filepath.Glob("/proc/[0-9]*/cmdline")
+	if err != nil {
+		return nil
+	}
+	pids := []int{}
+	for _, cmdlineFile := range cmdlineFiles {
+		data, err := ioutil.ReadFile(cmdlineFile)
+		if err != nil {
+			// process exited
+			continue
+		}
+		for _, a := range bytes.Split(data, []byte{0}) {
+			if string(a) == arg {
+				pid, err := strconv.Atoi(filepath.Base(filepath.Dir(cmdlineFile)))
+				if err == nil {
+					pids = append(pids, pid)
+				}
+				break
+			}
+		}
+	}
+	return pids
+}
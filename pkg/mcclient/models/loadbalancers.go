@@ -65,6 +65,9 @@ type LoadbalancerListener struct {
 	BackendGroupId    string
 	BackendServerPort int
 
+	CanaryBackendGroupId string
+	CanaryWeight         int
+
 	AclStatus string
 	AclType   string
 	AclId     string
@@ -100,12 +103,39 @@ type LoadbalancerListenerRule struct {
 	ListenerId     string
 	BackendGroupId string
 
+	CanaryBackendGroupId string
+	CanaryWeight         int
+
 	Domain string
 	Path   string
 
+	Priority int
+
+	Action string
+	LoadbalancerHTTPRedirect
+	FixedResponseCode int
+
+	RewritePath           string
+	RequestHeaderActions  *LoadbalancerHTTPHeaderActions
+	ResponseHeaderActions *LoadbalancerHTTPHeaderActions
+
 	LoadbalancerHTTPRateLimiter
 }
 
+type LoadbalancerHTTPRedirect struct {
+	RedirectCode   int
+	RedirectScheme string
+	RedirectHost   string
+	RedirectPath   string
+}
+
+type LoadbalancerHTTPHeaderAction struct {
+	Action string
+	Name   string
+	Value  string
+}
+type LoadbalancerHTTPHeaderActions []*LoadbalancerHTTPHeaderAction
+
 type LoadbalancerBackendGroup struct {
 	VirtualResource
 	ManagedResource
@@ -127,6 +157,7 @@ type LoadbalancerBackend struct {
 	Weight         int
 	Address        string
 	Port           int
+	DrainState     string
 }
 
 type LoadbalancerAclEntry struct {
@@ -149,6 +180,10 @@ type LoadbalancerCertificate struct {
 	Certificate string
 	PrivateKey  string
 
+	CertificateMode string
+	AcmeDomains     string
+	AcmeChallenges  *LoadbalancerAcmeChallenges
+
 	CloudregionId           string
 	PublicKeyAlgorithm      string
 	PublicKeyBitLen         int
@@ -160,6 +195,13 @@ type LoadbalancerCertificate struct {
 	SubjectAlternativeNames string
 }
 
+type LoadbalancerAcmeChallenge struct {
+	Domain           string
+	Token            string
+	KeyAuthorization string
+}
+type LoadbalancerAcmeChallenges []*LoadbalancerAcmeChallenge
+
 type LoadbalancerAgent struct {
 	StandaloneResource
 
@@ -195,6 +237,7 @@ type LoadbalancerAgentParamsHaproxy struct {
 	LogHttp        bool
 	LogTcp         bool
 	LogNormal      bool
+	AccessLog      bool
 }
 
 type LoadbalancerAgentParamsTelegraf struct {
@@ -0,0 +1,24 @@
+package modules
+
+var (
+	Buckets ResourceManager
+)
+
+func init() {
+	Buckets = NewComputeManager(
+		"bucket",
+		"buckets",
+		[]string{
+			"id",
+			"name",
+			"status",
+			"storage_class",
+			"location",
+			"acl",
+			"cloudregion_id",
+			"created_at",
+		},
+		[]string{"tenant"},
+	)
+	registerCompute(&Buckets)
+}
@@ -0,0 +1,35 @@
+package modules
+
+var (
+	DBInstances ResourceManager
+)
+
+func init() {
+	DBInstances = NewComputeManager(
+		"dbinstance",
+		"dbinstances",
+		[]string{
+			"id",
+			"name",
+			"status",
+			"engine",
+			"engine_version",
+			"instance_type",
+			"vcpu_count",
+			"vmem_size_mb",
+			"disk_size_gb",
+			"category",
+			"port",
+			"connection_str",
+			"internal_connection_str",
+			"billing_type",
+			"expired_at",
+			"cloudregion_id",
+			"zone_id",
+			"vpc_id",
+			"network_id",
+		},
+		[]string{"tenant"},
+	)
+	registerCompute(&DBInstances)
+}
@@ -22,6 +22,8 @@ func init() {
 				"address",
 				"port",
 				"weight",
+				"health_status",
+				"drain_state",
 			},
 			[]string{"tenant"},
 		),
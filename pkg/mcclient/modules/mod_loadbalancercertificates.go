@@ -16,6 +16,8 @@ func init() {
 			[]string{
 				"id",
 				"name",
+				"status",
+				"certificate_mode",
 				"algorithm",
 				"fingerprint",
 				"not_before",
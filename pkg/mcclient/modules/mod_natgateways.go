@@ -0,0 +1,69 @@
+package modules
+
+type NatGatewayManager struct {
+	ResourceManager
+}
+
+var (
+	NatGateways NatGatewayManager
+	NatSEntries NatGatewayManager
+	NatDEntries NatGatewayManager
+)
+
+func init() {
+	NatGateways = NatGatewayManager{
+		NewComputeManager(
+			"natgateway",
+			"natgateways",
+			[]string{
+				"id",
+				"name",
+				"status",
+				"nat_spec",
+				"vpc",
+				"vpc_id",
+				"cloudregion_id",
+				"billing_type",
+				"expired_at",
+			},
+			[]string{"tenant"},
+		),
+	}
+	NatSEntries = NatGatewayManager{
+		NewComputeManager(
+			"natsentry",
+			"natsentries",
+			[]string{
+				"id",
+				"name",
+				"status",
+				"natgateway_id",
+				"ip",
+				"source_cidr",
+				"network_id",
+			},
+			[]string{"tenant"},
+		),
+	}
+	NatDEntries = NatGatewayManager{
+		NewComputeManager(
+			"natdentry",
+			"natdentries",
+			[]string{
+				"id",
+				"name",
+				"status",
+				"natgateway_id",
+				"ip_protocol",
+				"external_ip",
+				"external_port",
+				"internal_ip",
+				"internal_port",
+			},
+			[]string{"tenant"},
+		),
+	}
+	registerCompute(&NatGateways)
+	registerCompute(&NatSEntries)
+	registerCompute(&NatDEntries)
+}
@@ -27,11 +27,28 @@ func (this *QuotaManager) getURL(params jsonutils.JSONObject) string {
 	return url
 }
 
+// getScopeQuery returns the query string selecting the region or zone scope
+// of the quota
+func (this *QuotaManager) getScopeQuery(params jsonutils.JSONObject) string {
+	if params == nil {
+		return ""
+	}
+	query := params.(*jsonutils.JSONDict).CopyIncludes("region_id", "zone_id", "scopes")
+	if query.Size() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("?%s", query.QueryString())
+}
+
 func (this *QuotaManager) GetQuota(s *mcclient.ClientSession, params jsonutils.JSONObject) (jsonutils.JSONObject, error) {
-	computeQuota, err := this._get(s, this.getURL(params), this.KeywordPlural)
+	computeQuota, err := this._get(s, this.getURL(params)+this.getScopeQuery(params), this.KeywordPlural)
 	if err != nil {
 		return nil, err
 	}
+	if params != nil && (params.Contains("region_id") || params.Contains("zone_id")) {
+		// the scoped image quotas are kept by the compute service
+		return computeQuota, nil
+	}
 	imageQuota, err := ImageQuotas._get(s, ImageQuotas.getURL(params), ImageQuotas.KeywordPlural)
 	if err != nil {
 		return nil, err
@@ -46,7 +63,14 @@ func (this *QuotaManager) doPost(s *mcclient.ClientSession, params jsonutils.JSO
 	if !ok {
 		return nil, fmt.Errorf("Invalid input")
 	}
-	data := quotas.CopyExcludes("tenant", "user", "image")
+	excludes := []string{"tenant", "user", "scopes"}
+	scoped := quotas.Contains("region_id") || quotas.Contains("zone_id")
+	if !scoped {
+		// the global image quota is kept by the image service, the
+		// compute service limits the images cached in a region or zone
+		excludes = append(excludes, "image")
+	}
+	data := quotas.CopyExcludes(excludes...)
 	var err error
 	if data.Size() > 0 {
 		body := jsonutils.NewDict()
@@ -56,6 +80,9 @@ func (this *QuotaManager) doPost(s *mcclient.ClientSession, params jsonutils.JSO
 			return nil, err
 		}
 	}
+	if scoped {
+		return jsonutils.NewDict(), nil
+	}
 	data = quotas.CopyIncludes("image")
 	if data.Size() > 0 {
 		body := jsonutils.NewDict()
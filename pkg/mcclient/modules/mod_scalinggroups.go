@@ -0,0 +1,20 @@
+package modules
+
+var (
+	ServerGroups ResourceManager
+	ScalingRules ResourceManager
+)
+
+func init() {
+	ServerGroups = NewComputeManager("group", "groups",
+		[]string{"ID", "Name", "Status", "Zone_id", "Scaling_enabled", "Min_size", "Max_size",
+			"Desired_size", "Guest_count", "Servertemplate_id", "Loadbalancer_backend_group_id", "Last_scale_at"},
+		[]string{"Tenant"})
+	registerComputeV2(&ServerGroups)
+
+	ScalingRules = NewComputeManager("scalingrule", "scalingrules",
+		[]string{"ID", "Name", "Status", "Group", "Enabled", "Measurement", "Field",
+			"Aggregate", "Period", "Operator", "Threshold", "Action", "Step"},
+		[]string{"Tenant"})
+	registerComputeV2(&ScalingRules)
+}
@@ -176,3 +176,17 @@ func (this *SchedulerManager) CleanCache(s *mcclient.ClientSession, hostId strin
 	resp.Body.Close()
 	return nil
 }
+
+func (this *SchedulerManager) Rebalance(s *mcclient.ClientSession, input *api.RebalanceInput) (*api.RebalanceOutput, error) {
+	url := newSchedURL("rebalance")
+	_, obj, err := this.jsonRequest(s, "POST", url, nil, input.JSON(input))
+	if err != nil {
+		return nil, err
+	}
+	output := new(api.RebalanceOutput)
+	err = obj.Unmarshal(output)
+	if err != nil {
+		return nil, fmt.Errorf("Not a valid response: %v", err)
+	}
+	return output, nil
+}
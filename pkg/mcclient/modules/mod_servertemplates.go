@@ -0,0 +1,12 @@
+package modules
+
+var (
+	Servertemplates ResourceManager
+)
+
+func init() {
+	Servertemplates = NewComputeManager("servertemplate", "servertemplates",
+		[]string{"ID", "Name", "Status", "Version", "Is_public", "Description"},
+		[]string{"Tenant"})
+	registerComputeV2(&Servertemplates)
+}
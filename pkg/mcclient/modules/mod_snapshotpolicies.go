@@ -0,0 +1,29 @@
+package modules
+
+var (
+	SnapshotPolicies      ResourceManager
+	SnapshotPolicyDisks   JointResourceManager
+	SnapshotPolicyServers JointResourceManager
+)
+
+func init() {
+	SnapshotPolicies = NewComputeManager("snapshotpolicy", "snapshotpolicies",
+		[]string{"ID", "Name", "Status", "Enabled", "Schedule",
+			"Retention_count", "Retention_days", "Disk_count", "Guest_count", "Next_run_at"},
+		[]string{"Tenant"})
+	registerComputeV2(&SnapshotPolicies)
+
+	SnapshotPolicyDisks = NewJointComputeManager("snapshotpolicydisk", "snapshotpolicydisks",
+		[]string{"Snapshotpolicy_ID", "Snapshotpolicy", "Disk_ID", "Disk", "Created_at"},
+		[]string{},
+		&SnapshotPolicies,
+		&Disks)
+	registerComputeV2(&SnapshotPolicyDisks)
+
+	SnapshotPolicyServers = NewJointComputeManager("snapshotpolicyguest", "snapshotpolicyguests",
+		[]string{"Snapshotpolicy_ID", "Snapshotpolicy", "Guest_ID", "Guest", "Created_at"},
+		[]string{},
+		&SnapshotPolicies,
+		&Servers)
+	registerComputeV2(&SnapshotPolicyServers)
+}
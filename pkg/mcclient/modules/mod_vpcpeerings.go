@@ -0,0 +1,25 @@
+package modules
+
+var (
+	VpcPeerings ResourceManager
+)
+
+func init() {
+	VpcPeerings = NewComputeManager(
+		"vpcpeering",
+		"vpcpeerings",
+		[]string{
+			"id",
+			"name",
+			"status",
+			"vpc_id",
+			"peer_vpc_id",
+			"external_peer_vpc_id",
+			"peer_account_id",
+			"external_peer_region_id",
+			"cloudregion_id",
+		},
+		[]string{"tenant"},
+	)
+	registerCompute(&VpcPeerings)
+}
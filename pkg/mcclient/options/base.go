@@ -192,7 +192,7 @@ type BaseListOptions struct {
 
 	Manager      string `help:"List objects belonging to the cloud provider" json:"manager,omitempty"`
 	Account      string `help:"List objects belonging to the cloud account" json:"account,omitempty"`
-	Provider     string `help:"List objects from the provider" choices:"VMware|Aliyun|Qcloud|Azure|Aws|Huawei|Openstack" json:"provider,omitempty"`
+	Provider     string `help:"List objects from the provider" choices:"VMware|Aliyun|Qcloud|Azure|Aws|Huawei|Openstack|Google" json:"provider,omitempty"`
 	PublicCloud  *bool  `help:"List objects belonging to public cloud" json:"public_cloud"`
 	PrivateCloud *bool  `help:"List objects belonging to private cloud" json:"private_cloud"`
 	IsOnPremise  *bool  `help:"List objects belonging to on premise infrastructures" token:"on-premise" json:"is_on_premise"`
@@ -0,0 +1,32 @@
+package options
+
+type BucketListOptions struct {
+	BaseListOptions
+
+	Cloudregion string `help:"Cloudregion id or name"`
+}
+
+type BucketIdOptions struct {
+	ID string `help:"Id or name of bucket" json:"-"`
+}
+
+type BucketCreateOptions struct {
+	NAME         string `help:"Name of bucket"`
+	Cloudregion  string `help:"Cloudregion id or name" required:"true"`
+	Manager      string `help:"Cloudprovider id or name" required:"true"`
+	StorageClass string `help:"Storage class of bucket"`
+	Acl          string `help:"Canned acl of bucket" choices:"private|authenticated-read|public-read|public-read-write"`
+}
+
+type BucketAclOptions struct {
+	ID  string `help:"Id or name of bucket" json:"-"`
+	ACL string `help:"Canned acl of bucket" choices:"private|authenticated-read|public-read|public-read-write"`
+}
+
+type BucketListObjectsOptions struct {
+	ID        string `help:"Id or name of bucket" json:"-"`
+	Prefix    string `help:"List objects with the prefix"`
+	Marker    string `help:"List objects after the marker"`
+	Delimiter string `help:"Delimiter to group object keys"`
+	Limit     int    `help:"Max count of objects to return"`
+}
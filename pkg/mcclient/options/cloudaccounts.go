@@ -1,5 +1,12 @@
 package options
 
+import (
+	"fmt"
+	"io/ioutil"
+
+	"yunion.io/x/jsonutils"
+)
+
 type SUserPasswordCredential struct {
 	Username string `help:"Username" positional:"true"`
 	Password string `help:"Password" positional:"true"`
@@ -55,6 +62,30 @@ type SAccessKeyCredentialWithEnvironment struct {
 	Environment string `help:"Cloud environment" choices:"InternationalCloud|ChinaCloud" default:"ChinaCloud"`
 }
 
+type SGoogleCredential struct {
+	KeyFile string `help:"Google service account key file in json format" positional:"true" json:"-"`
+}
+
+func (opts *SGoogleCredential) Params() (*jsonutils.JSONDict, error) {
+	data, err := ioutil.ReadFile(opts.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read key file %s error: %v", opts.KeyFile, err)
+	}
+	key, err := jsonutils.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse key file %s error: %v", opts.KeyFile, err)
+	}
+	params := jsonutils.NewDict()
+	for _, k := range []string{"project_id", "client_email", "private_key_id", "private_key"} {
+		v, _ := key.GetString(k)
+		if len(v) == 0 {
+			return nil, fmt.Errorf("missing %s in key file %s", k, opts.KeyFile)
+		}
+		params.Add(jsonutils.NewString(v), "gcp_"+k)
+	}
+	return params, nil
+}
+
 /// create options
 
 type SCloudAccountCreateBaseOptions struct {
@@ -105,6 +136,11 @@ type SHuaweiCloudAccountCreateOptions struct {
 	SAccessKeyCredentialWithEnvironment
 }
 
+type SGoogleCloudAccountCreateOptions struct {
+	SCloudAccountCreateBaseOptions
+	SGoogleCredential
+}
+
 // update credential options
 
 type SCloudAccountUpdateCredentialBaseOptions struct {
@@ -146,6 +182,11 @@ type SHuaweiCloudAccountUpdateCredentialOptions struct {
 	SAccessKeyCredential
 }
 
+type SGoogleCloudAccountUpdateCredentialOptions struct {
+	SCloudAccountUpdateCredentialBaseOptions
+	SGoogleCredential
+}
+
 // update
 
 type SCloudAccountUpdateBaseOptions struct {
@@ -191,3 +232,7 @@ type SOpenStackCloudAccountUpdateOptions struct {
 type SHuaweiCloudAccountUpdateOptions struct {
 	SCloudAccountUpdateBaseOptions
 }
+
+type SGoogleCloudAccountUpdateOptions struct {
+	SCloudAccountUpdateBaseOptions
+}
@@ -0,0 +1,15 @@
+package options
+
+type DBInstanceListOptions struct {
+	BaseListOptions
+
+	Cloudregion string `help:"Cloudregion id or name"`
+	Zone        string `help:"Zone id or name"`
+	Vpc         string `help:"Vpc id or name"`
+	Network     string `help:"Network id or name"`
+	Engine      string `help:"Database engine, e.g. MySQL, PostgreSQL"`
+}
+
+type DBInstanceIdOptions struct {
+	ID string `help:"Id or name of dbinstance" json:"-"`
+}
@@ -25,6 +25,7 @@ type LoadbalancerAgentParamsOptions struct {
 	HaproxyLogHttp        string `choices:"true|false"`
 	HaproxyLogTcp         string `choices:"true|false"`
 	HaproxyLogNormal      string `choices:"true|false"`
+	HaproxyAccessLog      string `choices:"true|false" help:"collect access logs of listeners into influxdb of telegraf params"`
 
 	TelegrafInfluxDbOutputUrl       string
 	TelegrafInfluxDbOutputName      string
@@ -32,4 +32,14 @@ type LoadbalancerBackendGetOptions struct {
 
 type LoadbalancerBackendDeleteOptions struct {
 	ID string `json:-`
+
+	Drain bool `help:"Stop new connections and wait for existing ones to finish before deletion"`
+}
+
+type LoadbalancerBackendPurgeOptions struct {
+	ID string `json:-`
+}
+
+type LoadbalancerBackendDrainOptions struct {
+	ID string `json:-`
 }
@@ -36,10 +36,14 @@ func loadbalancerCertificateLoadFiles(cert, pkey string, allowEmpty bool) (*json
 type LoadbalancerCertificateCreateOptions struct {
 	NAME string
 
-	Cert      string `required:"true" json:"-" help:"path to certificate file"`
-	Pkey      string `required:"true" json:"-" help:"path to private key file"`
+	Cert      string `json:"-" help:"path to certificate file, required for upload mode"`
+	Pkey      string `json:"-" help:"path to private key file, required for upload mode"`
 	Region    string `json:"cloudregion"`
 	ManagerId string
+
+	Mode       string   `json:"certificate_mode" choices:"upload|acme" help:"upload certificate or issue it through acme"`
+	AcmeDomain []string `json:"acme_domains" help:"domain name of acme certificate, can be specified multiple times"`
+	AcmeEmail  string   `help:"contact email of acme account"`
 }
 
 func (opts *LoadbalancerCertificateCreateOptions) Params() (*jsonutils.JSONDict, error) {
@@ -47,6 +51,9 @@ func (opts *LoadbalancerCertificateCreateOptions) Params() (*jsonutils.JSONDict,
 	if err != nil {
 		return nil, err
 	}
+	if opts.Mode == "acme" {
+		return params, nil
+	}
 	paramsCertKey, err := loadbalancerCertificateLoadFiles(opts.Cert, opts.Pkey, false)
 	if err != nil {
 		return nil, err
@@ -77,6 +84,8 @@ type LoadbalancerCertificateUpdateOptions struct {
 
 	Cert string `json:"-" help:"path to certificate file"`
 	Pkey string `json:"-" help:"path to private key file"`
+
+	AcmeEmail string `help:"contact email of acme account"`
 }
 
 func (opts *LoadbalancerCertificateUpdateOptions) Params() (*jsonutils.JSONDict, error) {
@@ -84,5 +93,12 @@ func (opts *LoadbalancerCertificateUpdateOptions) Params() (*jsonutils.JSONDict,
 	if err != nil {
 		return nil, err
 	}
+	if opts.AcmeEmail != "" {
+		paramsCertKey.Set("acme_email", jsonutils.NewString(opts.AcmeEmail))
+	}
 	return paramsCertKey, nil
 }
+
+type LoadbalancerCertificateRenewOptions struct {
+	ID string `json:"-"`
+}
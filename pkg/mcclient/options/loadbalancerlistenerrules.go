@@ -1,11 +1,61 @@
 package options
 
+import (
+	"fmt"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+)
+
+type HTTPHeaderAction struct {
+	Action string
+	Name   string
+	Value  string
+}
+
+type HTTPHeaderActions []*HTTPHeaderAction
+
+// NewHTTPHeaderActions parses header actions in the form of set:Name:Value,
+// add:Name:Value or del:Name
+func NewHTTPHeaderActions(ss []string) (HTTPHeaderActions, error) {
+	headerActions := HTTPHeaderActions{}
+	for _, s := range ss {
+		tu := strings.SplitN(s, ":", 3)
+		if len(tu) < 2 {
+			return nil, fmt.Errorf("invalid header action %q", s)
+		}
+		headerAction := &HTTPHeaderAction{
+			Action: tu[0],
+			Name:   tu[1],
+		}
+		if len(tu) > 2 {
+			headerAction.Value = tu[2]
+		}
+		headerActions = append(headerActions, headerAction)
+	}
+	return headerActions, nil
+}
+
 type LoadbalancerListenerRuleCreateOptions struct {
 	NAME         string
 	Listener     string `required:"true"`
 	BackendGroup string
 	Domain       string
 	Path         string
+
+	CanaryBackendGroup string
+	CanaryWeight       *int `help:"percent of requests sent to canary backend group"`
+
+	Priority          *int   `help:"rules with higher priority are matched first"`
+	Action            string `choices:"forward|redirect|fixed_response"`
+	RedirectCode      *int   `help:"one of 301, 302, 303, 307 and 308, defaults to 302"`
+	RedirectScheme    string `choices:"http|https"`
+	RedirectHost      string
+	RedirectPath      string
+	FixedResponseCode *int
+	RewritePath       string   `help:"replace the matched path prefix with it before forwarding"`
+	RequestHeader     []string `help:"request header action, e.g. set:X-Real-IP:%[src], add:Name:Value or del:Name" json:"-"`
+	ResponseHeader    []string `help:"response header action, e.g. set:Name:Value, add:Name:Value or del:Name" json:"-"`
 }
 
 type LoadbalancerListenerRuleListOptions struct {
@@ -22,6 +72,62 @@ type LoadbalancerListenerRuleUpdateOptions struct {
 	Name string
 
 	BackendGroup string
+
+	CanaryBackendGroup string
+	CanaryWeight       *int `help:"percent of requests sent to canary backend group"`
+
+	Priority          *int
+	Action            string `choices:"forward|redirect|fixed_response"`
+	RedirectCode      *int   `help:"one of 301, 302, 303, 307 and 308, defaults to 302"`
+	RedirectScheme    string `choices:"http|https"`
+	RedirectHost      string
+	RedirectPath      string
+	FixedResponseCode *int
+	RewritePath       string
+	RequestHeader     []string `help:"request header action, e.g. set:X-Real-IP:%[src], add:Name:Value or del:Name" json:"-"`
+	ResponseHeader    []string `help:"response header action, e.g. set:Name:Value, add:Name:Value or del:Name" json:"-"`
+}
+
+func loadbalancerListenerRuleHeaderParams(params *jsonutils.JSONDict, requestHeader, responseHeader []string) error {
+	m := map[string][]string{
+		"request_header_actions":  requestHeader,
+		"response_header_actions": responseHeader,
+	}
+	for k, ss := range m {
+		// - when it's nil, we leave it alone without updating
+		// - when it's non-nil, we update it as a whole
+		if ss == nil {
+			continue
+		}
+		headerActions, err := NewHTTPHeaderActions(ss)
+		if err != nil {
+			return err
+		}
+		params.Set(k, jsonutils.Marshal(headerActions))
+	}
+	return nil
+}
+
+func (opts *LoadbalancerListenerRuleCreateOptions) Params() (*jsonutils.JSONDict, error) {
+	params, err := optionsStructToParams(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadbalancerListenerRuleHeaderParams(params, opts.RequestHeader, opts.ResponseHeader); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func (opts *LoadbalancerListenerRuleUpdateOptions) Params() (*jsonutils.JSONDict, error) {
+	params, err := optionsStructToParams(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadbalancerListenerRuleHeaderParams(params, opts.RequestHeader, opts.ResponseHeader); err != nil {
+		return nil, err
+	}
+	return params, nil
 }
 
 type LoadbalancerListenerRuleGetOptions struct {
@@ -9,6 +9,9 @@ type LoadbalancerListenerCreateOptions struct {
 	BackendServerPort *int
 	BackendGroup      string
 
+	CanaryBackendGroup string
+	CanaryWeight       *int `help:"percent of requests sent to canary backend group"`
+
 	Scheduler string `required:"true" choices:"rr|wrr|wlc|sch|tch"`
 
 	ClientRequestTimeout  *int
@@ -107,6 +110,9 @@ type LoadbalancerListenerUpdateOptions struct {
 
 	BackendGroup string
 
+	CanaryBackendGroup string
+	CanaryWeight       *int `help:"percent of requests sent to canary backend group"`
+
 	Scheduler string `choices:"rr|wrr|wlc|sch|tch"`
 
 	ClientRequestTimeout  *int
@@ -165,3 +171,8 @@ type LoadbalancerListenerActionStatusOptions struct {
 type LoadbalancerListenerActionSyncStatusOptions struct {
 	ID string `json:-`
 }
+
+type LoadbalancerListenerAccessStatsOptions struct {
+	ID       string `json:-`
+	Interval int    `help:"stats of recent seconds, default 300"`
+}
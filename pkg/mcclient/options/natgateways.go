@@ -0,0 +1,56 @@
+package options
+
+type NatGatewayListOptions struct {
+	BaseListOptions
+
+	Vpc         string `help:"Vpc id or name"`
+	Cloudregion string `help:"Cloudregion id or name"`
+}
+
+type NatGatewayCreateOptions struct {
+	NAME    string `help:"Name of nat gateway"`
+	Vpc     string `help:"Vpc id or name" required:"true"`
+	Network string `help:"Network id or name the nat gateway is placed in, required by huawei and aws"`
+	Eip     string `help:"Eip id or name bound to the nat gateway, required by aws"`
+	NatSpec string `help:"Spec of nat gateway" choices:"small|middle|large|xlarge"`
+	Desc    string `help:"Description" json:"description"`
+}
+
+type NatGatewayIdOptions struct {
+	ID string `help:"Id or name of nat gateway" json:"-"`
+}
+
+type NatSEntryCreateOptions struct {
+	NAME       string `help:"Name of snat entry"`
+	Natgateway string `help:"Nat gateway id or name" required:"true"`
+	Eip        string `help:"Eip id or name used as the snat ip" required:"true"`
+	Network    string `help:"Network id or name the snat entry applies to"`
+	SourceCidr string `help:"Source cidr the snat entry applies to, used when network is not set"`
+}
+
+type NatSEntryListOptions struct {
+	BaseListOptions
+
+	Natgateway string `help:"Nat gateway id or name"`
+	Network    string `help:"Network id or name"`
+}
+
+type NatDEntryCreateOptions struct {
+	NAME         string `help:"Name of dnat entry"`
+	Natgateway   string `help:"Nat gateway id or name" required:"true"`
+	Eip          string `help:"Eip id or name used as the external ip" required:"true"`
+	ExternalPort int    `help:"External port" required:"true"`
+	InternalIp   string `help:"Internal ip" required:"true"`
+	InternalPort int    `help:"Internal port" required:"true"`
+	IpProtocol   string `help:"Ip protocol" choices:"tcp|udp|any" default:"tcp"`
+}
+
+type NatDEntryListOptions struct {
+	BaseListOptions
+
+	Natgateway string `help:"Nat gateway id or name"`
+}
+
+type NatEntryIdOptions struct {
+	ID string `help:"Id or name of nat entry" json:"-"`
+}
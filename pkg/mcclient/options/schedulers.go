@@ -62,6 +62,7 @@ func (o *SchedulerTestOptions) Params(s *mcclient.ClientSession) (*scheduler.Sch
 
 type SchedulerForecastOptions struct {
 	SchedulerTestBaseOptions
+	Verbose bool `help:"Explain every candidate by its failed predicates and scores"`
 }
 
 func (o SchedulerForecastOptions) Params(s *mcclient.ClientSession) (*scheduler.ScheduleInput, error) {
@@ -73,5 +74,6 @@ func (o SchedulerForecastOptions) Params(s *mcclient.ClientSession) (*scheduler.
 	input := new(scheduler.ScheduleInput)
 	input.ServerConfig = *data
 	input.ScheduleBaseConfig = *opts
+	input.Verbose = o.Verbose
 	return input, nil
 }
@@ -25,7 +25,7 @@ type ServerListOptions struct {
 	Gpu           *bool  `help:"Show gpu servers"`
 	Secgroup      string `help:"Secgroup ID or Name"`
 	AdminSecgroup string `help:"AdminSecgroup ID or Name"`
-	Hypervisor    string `help:"Show server of hypervisor" choices:"kvm|esxi|container|baremetal|aliyun|azure|aws|huawei"`
+	Hypervisor    string `help:"Show server of hypervisor" choices:"kvm|esxi|container|baremetal|aliyun|azure|aws|huawei|google"`
 	Region        string `help:"Show servers in cloudregion"`
 	WithEip       *bool  `help:"Show Servers with EIP"`
 	WithoutEip    *bool  `help:"Show Servers without EIP"`
@@ -111,9 +111,10 @@ type ServerConfigs struct {
 	Host       string `help:"Preferred host where virtual server should be created" json:"prefer_host"`
 	BackupHost string `help:"Perfered host where virtual backup server should be created"`
 
-	Hypervisor   string `help:"Hypervisor type" choices:"kvm|esxi|baremetal|container|aliyun|azure|qcloud|aws|huawei"`
+	Hypervisor   string `help:"Hypervisor type" choices:"kvm|esxi|baremetal|container|aliyun|azure|qcloud|aws|huawei|google"`
 	ResourceType string `help:"Resource type" choices:"shared|prepaid|dedicated"`
 	Backup       bool   `help:"Create server with backup server"`
+	NumaPolicy   string `help:"Place vcpus and memory of server on a single numa node of host" choices:"none|single_node"`
 
 	Schedtag       []string `help:"Schedule policy, key = aggregate name, value = require|exclude|prefer|avoid" metavar:"<KEY:VALUE>"`
 	Disk           []string `help:"Disk descriptions" nargs:"+"`
@@ -138,6 +139,7 @@ func (o ServerConfigs) Data() (*computeapi.ServerConfigs, error) {
 		Project:          o.Project,
 		Backup:           o.Backup,
 		Count:            o.Count,
+		NumaPolicy:       o.NumaPolicy,
 	}
 	for i, d := range o.Disk {
 		disk, err := cmdline.ParseDiskConfig(d, i)
@@ -228,6 +230,9 @@ type ServerCreateOptions struct {
 	EipBw         int    `help:"allocate EIP with bandwidth in MB when server is created" json:"eip_bw,omitzero"`
 	EipChargeType string `help:"newly allocated EIP charge type, either traffic or bandwidth" choices:"traffic|bandwidth" json:"eip_charge_type,omitempty"`
 	Eip           string `help:"associate with an existing EIP when server is created" json:"eip,omitempty"`
+
+	HaPolicy   string `help:"Restart the server on another host when its host goes offline" choices:"none|restart"`
+	HaPriority int    `help:"Servers of higher priority are restarted first by ha"`
 }
 
 func (o *ServerCreateOptions) ToScheduleInput() (*schedapi.ScheduleInput, error) {
@@ -300,6 +305,8 @@ func (opts *ServerCreateOptions) Params() (*computeapi.ServerCreateInput, error)
 		EipBw:              opts.EipBw,
 		EipChargeType:      opts.EipChargeType,
 		Eip:                opts.Eip,
+		HaPolicy:           opts.HaPolicy,
+		HaPriority:         opts.HaPriority,
 	}
 
 	if opts.GenerateName {
@@ -375,6 +382,8 @@ type ServerUpdateOptions struct {
 	Boot             string   `help:"Boot device" choices:"disk|cdrom"`
 	Delete           string   `help:"Lock server to prevent from deleting" choices:"enable|disable" json:"-"`
 	ShutdownBehavior string   `help:"Behavior after VM server shutdown, stop or terminate server" choices:"stop|terminate"`
+	HaPolicy         string   `help:"Restart the server on another host when its host goes offline" choices:"none|restart"`
+	HaPriority       *int     `help:"Servers of higher priority are restarted first by ha"`
 }
 
 func (opts *ServerUpdateOptions) Params() (*jsonutils.JSONDict, error) {
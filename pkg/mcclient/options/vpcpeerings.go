@@ -0,0 +1,24 @@
+package options
+
+type VpcPeeringListOptions struct {
+	BaseListOptions
+
+	Vpc         string `help:"Vpc id or name"`
+	PeerVpc     string `help:"Peer vpc id or name"`
+	Cloudregion string `help:"Cloudregion id or name"`
+}
+
+type VpcPeeringCreateOptions struct {
+	NAME string `help:"Name of vpc peering"`
+	Desc string `help:"Description" json:"description"`
+
+	Vpc                  string `help:"Requester vpc id or name" required:"true"`
+	PeerVpc              string `help:"Peer vpc id or name, if the peer vpc is managed"`
+	ExternalPeerVpcId    string `help:"External id of peer vpc, if the peer vpc is not managed"`
+	PeerAccountId        string `help:"Account id of peer vpc, required for cross account peering"`
+	ExternalPeerRegionId string `help:"External region id of peer vpc, for cross region peering with unmanaged peer vpc"`
+}
+
+type VpcPeeringIdOptions struct {
+	ID string `help:"Id or name of vpc peering" json:"-"`
+}
@@ -16,6 +16,8 @@ const (
 	ErrNoAvailableNetwork    = `no available network on this host`
 	ErrNoEnoughAvailableGPUs = `no enough available GPUs`
 	ErrNotSupportNest        = `nested function not supported`
+	ErrNoNumaTopology        = `numa topology not reported`
+	ErrNoEnoughNumaNode      = `no numa node fits the cpu and memory`
 
 	ErrRequireMvs                      = `require mvs`
 	ErrRequireNoMvs                    = `require not mvs`
@@ -0,0 +1,77 @@
+package guest
+
+import (
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudcommon/types"
+	"yunion.io/x/onecloud/pkg/scheduler/algorithm/predicates"
+	"yunion.io/x/onecloud/pkg/scheduler/core"
+)
+
+// NumaPredicate filters out the hosts without a single numa node holding all
+// the vcpus and memory of a guest of numa_policy single_node.
+type NumaPredicate struct {
+	predicates.BasePredicate
+}
+
+func (p *NumaPredicate) Name() string {
+	return "host_numa"
+}
+
+func (p *NumaPredicate) Clone() core.FitPredicate {
+	return &NumaPredicate{}
+}
+
+func (p *NumaPredicate) PreExecute(u *core.Unit, cs []core.Candidater) (bool, error) {
+	data := u.SchedData()
+	if data.NumaPolicy != api.GUEST_NUMA_POLICY_SINGLE_NODE {
+		return false, nil
+	}
+	if data.Ncpu <= 0 || data.Memory <= 0 {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (p *NumaPredicate) Execute(u *core.Unit, c core.Candidater) (bool, []core.PredicateFailureReason, error) {
+	h := predicates.NewPredicateHelper(p, u, c)
+	d := u.SchedData()
+	hc, err := h.HostCandidate()
+	if err != nil {
+		return false, nil, err
+	}
+
+	nodes := make([]*types.SNumaNode, 0)
+	if hc.SysInfo != nil && hc.SysInfo.Contains("numa_nodes") {
+		if err := hc.SysInfo.Unmarshal(&nodes, "numa_nodes"); err != nil {
+			return false, nil, err
+		}
+	}
+	if len(nodes) == 0 {
+		h.Exclude(predicates.ErrNoNumaTopology)
+		return h.GetResult()
+	}
+
+	// the memory already taken by running guests and the host is counted by
+	// the free memory and hugepages reported by host, a guest is backed by
+	// either of them
+	var capacity int64
+	for _, node := range nodes {
+		byCpu := int64(len(node.Cpus) / d.Ncpu)
+		byMem := int64(node.FreeMemSizeMb / d.Memory)
+		if byHugepage := int64(node.FreeHugepageSizeMb / d.Memory); byHugepage > byMem {
+			byMem = byHugepage
+		}
+		if byCpu < byMem {
+			capacity += byCpu
+		} else {
+			capacity += byMem
+		}
+	}
+	if capacity == 0 {
+		h.Exclude(predicates.ErrNoEnoughNumaNode)
+		return h.GetResult()
+	}
+
+	h.SetCapacity(capacity)
+	return h.GetResult()
+}
@@ -193,8 +193,46 @@ type ForecastResult struct {
 	Capacity  int64  `json:"capacity"`
 }
 
+const (
+	ForecastReasonInsufficientResource = "insufficient_resource"
+	ForecastReasonUnexpectedResource   = "unexpected_resource"
+	ForecastReasonUnknown              = "unknown"
+)
+
+// ForecastReason is why a predicate rejects a candidate, Resource is set
+// when the candidate is short of the resource
+type ForecastReason struct {
+	Type     string `json:"type"`
+	Resource string `json:"resource,omitempty"`
+	Message  string `json:"message"`
+}
+
+type ForecastFailedPredicate struct {
+	Predicate string           `json:"predicate"`
+	Reasons   []ForecastReason `json:"reasons"`
+}
+
+type ForecastScore struct {
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+// ForecastCandidate explains the result of a verbose forecast on a candidate
+type ForecastCandidate struct {
+	Id               string                    `json:"id"`
+	Name             string                    `json:"name"`
+	HostType         string                    `json:"host_type"`
+	Fit              bool                      `json:"fit"`
+	Count            int64                     `json:"count"`
+	Capacity         int64                     `json:"capacity"`
+	FailedPredicates []ForecastFailedPredicate `json:"failed_predicates"`
+	Score            string                    `json:"score"`
+	Scores           []ForecastScore           `json:"scores"`
+}
+
 type SchedForecastResult struct {
-	CanCreate bool              `json:"can_create"`
-	Filters   []*ForecastFilter `json:"filters"`
-	Results   []ForecastResult  `json:"results"`
+	CanCreate  bool                 `json:"can_create"`
+	Filters    []*ForecastFilter    `json:"filters"`
+	Results    []ForecastResult     `json:"results"`
+	Candidates []*ForecastCandidate `json:"candidates,omitempty"`
 }
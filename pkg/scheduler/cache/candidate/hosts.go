@@ -969,6 +969,7 @@ func (b *HostBuilder) buildOne(host *computemodels.SHost) (interface{}, error) {
 
 	desc.CPUCmtbound = host.GetCPUOvercommitBound()
 	desc.MemCmtbound = host.GetMemoryOvercommitBound()
+	desc.IsMaintenance = host.IsMaintenance
 
 	desc.GuestReservedResource = NewGuestReservedResourceByBuilder(b, host)
 	guestRsvdUsed, err := NewGuestReservedResourceUsedByBuilder(b, host)
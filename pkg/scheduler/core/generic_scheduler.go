@@ -541,7 +541,8 @@ func unitFitsOnCandidate(
 			// the configured predicates even after one or more of them fails.
 			// When the flag is set to false, scheduler skips checking the rest
 			// of the predicates after it finds one predicate that failed.
-			if !o.GetOptions().AlwaysCheckAllPredicates {
+			// A verbose forecast always checks all of them to explain the candidate.
+			if !o.GetOptions().AlwaysCheckAllPredicates && !unit.SchedInfo.Verbose {
 				break
 			}
 		}
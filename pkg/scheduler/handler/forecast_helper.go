@@ -2,7 +2,9 @@ package handler
 
 import (
 	"fmt"
+	"sort"
 
+	"yunion.io/x/onecloud/pkg/scheduler/algorithm/predicates"
 	"yunion.io/x/onecloud/pkg/scheduler/api"
 	"yunion.io/x/onecloud/pkg/scheduler/core"
 )
@@ -77,9 +79,81 @@ func transToSchedForecastResult(result *core.SchedResultItemList) interface{} {
 	if readyCount < reqCount {
 		canCreate = false
 	}
-	return &api.SchedForecastResult{
+	ret := &api.SchedForecastResult{
 		CanCreate: canCreate,
 		Filters:   filters,
 		Results:   results,
 	}
+	if unit.SchedData().Verbose {
+		ret.Candidates = transToForecastCandidates(unit, items)
+	}
+	return ret
+}
+
+// transToForecastCandidates explains every candidate by the predicates it
+// failed and the score breakdown of the priorities it passed
+func transToForecastCandidates(unit *core.Unit, items []*core.SchedResultItem) []*api.ForecastCandidate {
+	failedMap := make(map[string][]api.ForecastFailedPredicate)
+	for stage, fcs := range unit.FailedCandidateMap {
+		for _, fc := range fcs.Candidates {
+			id := fc.Candidate.IndexKey()
+			failedMap[id] = append(failedMap[id], api.ForecastFailedPredicate{
+				Predicate: stage,
+				Reasons:   transToForecastReasons(fc.Reasons),
+			})
+		}
+	}
+
+	candidates := make([]*api.ForecastCandidate, 0, len(items))
+	for _, item := range items {
+		failed := failedMap[item.ID]
+		sort.Slice(failed, func(i, j int) bool {
+			return failed[i].Predicate < failed[j].Predicate
+		})
+		candidate := &api.ForecastCandidate{
+			Id:               item.ID,
+			Name:             item.Name,
+			HostType:         fmt.Sprintf("%v", item.Candidater.Get("HostType")),
+			Fit:              len(failed) == 0,
+			Count:            item.Count,
+			Capacity:         item.Capacity,
+			FailedPredicates: failed,
+			Scores:           make([]api.ForecastScore, 0),
+		}
+		if candidate.Fit {
+			candidate.Score = item.Score
+			for _, s := range unit.GetScore(item.ID).GetScores() {
+				candidate.Scores = append(candidate.Scores, api.ForecastScore{
+					Name:  s.Name,
+					Score: int(s.Score),
+				})
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Fit != candidates[j].Fit {
+			return candidates[i].Fit
+		}
+		return candidates[i].Capacity > candidates[j].Capacity
+	})
+	return candidates
+}
+
+func transToForecastReasons(reasons []core.PredicateFailureReason) []api.ForecastReason {
+	ret := make([]api.ForecastReason, 0, len(reasons))
+	for _, reason := range reasons {
+		r := api.ForecastReason{Message: reason.GetReason()}
+		switch e := reason.(type) {
+		case *predicates.InsufficientResourceError:
+			r.Type = api.ForecastReasonInsufficientResource
+			r.Resource = e.ResourceName
+		case *predicates.UnexceptedResourceError:
+			r.Type = api.ForecastReasonUnexpectedResource
+		default:
+			r.Type = api.ForecastReasonUnknown
+		}
+		ret = append(ret, r)
+	}
+	return ret
 }
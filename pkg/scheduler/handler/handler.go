@@ -63,6 +63,8 @@ func schedulerActionHandler(c *gin.Context) {
 		doHistoryList(c)
 	case "clean-cache":
 		doCleanAllHostCache(c)
+	case "rebalance":
+		doRebalance(c)
 	//case "reserved-resources":
 	//doReservedResources(c)
 	default:
@@ -0,0 +1,393 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	gin "gopkg.in/gin-gonic/gin.v1"
+
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/utils"
+
+	schedapi "yunion.io/x/onecloud/pkg/apis/scheduler"
+	"yunion.io/x/onecloud/pkg/appsrv"
+	computemodels "yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/scheduler/api"
+	"yunion.io/x/onecloud/pkg/scheduler/cache/candidate"
+	schedman "yunion.io/x/onecloud/pkg/scheduler/manager"
+)
+
+const (
+	defaultRebalanceMaxMigrations = 10
+	defaultRebalanceThreshold     = 0.1
+)
+
+// rebalanceHost tracks the resources of a candidate host while the plan moves guests around
+type rebalanceHost struct {
+	id   string
+	name string
+
+	totalCPU     int64
+	freeCPU      int64
+	totalMem     int64
+	freeMem      int64
+	totalStorage int64
+	freeStorage  int64
+}
+
+type rebalanceGuest struct {
+	guest *computemodels.SGuest
+
+	id      string
+	name    string
+	cpu     int64
+	mem     int64
+	storage int64
+}
+
+func newRebalanceHost(h *candidate.HostDesc) *rebalanceHost {
+	return &rebalanceHost{
+		id:           h.IndexKey(),
+		name:         h.Name,
+		totalCPU:     h.GetTotalCPUCount(false),
+		freeCPU:      h.GetFreeCPUCount(false),
+		totalMem:     h.GetTotalMemSize(false),
+		freeMem:      h.GetFreeMemSize(false),
+		totalStorage: h.GetTotalLocalStorageSize(false),
+		freeStorage:  h.GetFreeLocalStorageSize(false),
+	}
+}
+
+func usageRatio(total, free int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	ratio := float64(total-free) / float64(total)
+	if ratio < 0 {
+		return 0
+	}
+	return ratio
+}
+
+// loadWith returns the load of the host once cpu, mem and storage are allocated,
+// the load is the average usage of the cpu, memory and local storage of the host
+func (h *rebalanceHost) loadWith(cpu, mem, storage int64) float64 {
+	usages := []float64{}
+	if h.totalCPU > 0 {
+		usages = append(usages, usageRatio(h.totalCPU, h.freeCPU-cpu))
+	}
+	if h.totalMem > 0 {
+		usages = append(usages, usageRatio(h.totalMem, h.freeMem-mem))
+	}
+	if h.totalStorage > 0 {
+		usages = append(usages, usageRatio(h.totalStorage, h.freeStorage-storage))
+	}
+	if len(usages) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, u := range usages {
+		sum += u
+	}
+	return sum / float64(len(usages))
+}
+
+func (h *rebalanceHost) load() float64 {
+	return h.loadWith(0, 0, 0)
+}
+
+func (h *rebalanceHost) fits(g *rebalanceGuest) bool {
+	if h.freeCPU < g.cpu || h.freeMem < g.mem {
+		return false
+	}
+	return g.storage == 0 || h.freeStorage >= g.storage
+}
+
+func (h *rebalanceHost) toHostLoad() *schedapi.RebalanceHostLoad {
+	return &schedapi.RebalanceHostLoad{
+		HostId:       h.id,
+		Name:         h.name,
+		CpuUsage:     usageRatio(h.totalCPU, h.freeCPU),
+		MemUsage:     usageRatio(h.totalMem, h.freeMem),
+		StorageUsage: usageRatio(h.totalStorage, h.freeStorage),
+		Load:         h.load(),
+	}
+}
+
+// rebalancePlanner greedily moves guests off the most loaded hosts of a zone
+// as long as a move lowers the load of the busier of the source and target host
+type rebalancePlanner struct {
+	maxMigrations int
+	threshold     float64
+
+	hosts    []*rebalanceHost
+	hostsMap map[string]*rebalanceHost
+
+	// guests returns the guests of a host which may be live migrated
+	guests func(h *rebalanceHost) ([]*rebalanceGuest, error)
+	// targets returns the ids of the hosts a guest can migrate to, best first
+	targets func(g *rebalanceGuest) ([]string, error)
+
+	guestsCache  map[string][]*rebalanceGuest
+	targetsCache map[string][]string
+	moved        map[string]bool
+	migrations   []*schedapi.RebalanceMigration
+}
+
+func newRebalancePlanner(input *schedapi.RebalanceInput, hosts []*rebalanceHost) *rebalancePlanner {
+	p := &rebalancePlanner{
+		maxMigrations: input.MaxMigrations,
+		threshold:     input.Threshold,
+		hosts:         hosts,
+		hostsMap:      make(map[string]*rebalanceHost),
+		guestsCache:   make(map[string][]*rebalanceGuest),
+		targetsCache:  make(map[string][]string),
+		moved:         make(map[string]bool),
+		migrations:    make([]*schedapi.RebalanceMigration, 0),
+	}
+	if p.maxMigrations <= 0 {
+		p.maxMigrations = defaultRebalanceMaxMigrations
+	}
+	if p.threshold <= 0 {
+		p.threshold = defaultRebalanceThreshold
+	}
+	for _, h := range hosts {
+		p.hostsMap[h.id] = h
+	}
+	return p
+}
+
+func (p *rebalancePlanner) averageLoad() float64 {
+	if len(p.hosts) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, h := range p.hosts {
+		sum += h.load()
+	}
+	return sum / float64(len(p.hosts))
+}
+
+func (p *rebalancePlanner) hostLoads() []*schedapi.RebalanceHostLoad {
+	ret := make([]*schedapi.RebalanceHostLoad, len(p.hosts))
+	for i, h := range p.hosts {
+		ret[i] = h.toHostLoad()
+	}
+	return ret
+}
+
+func (p *rebalancePlanner) getGuests(h *rebalanceHost) ([]*rebalanceGuest, error) {
+	if guests, ok := p.guestsCache[h.id]; ok {
+		return guests, nil
+	}
+	guests, err := p.guests(h)
+	if err != nil {
+		return nil, err
+	}
+	p.guestsCache[h.id] = guests
+	return guests, nil
+}
+
+func (p *rebalancePlanner) getTargets(g *rebalanceGuest) []string {
+	if targets, ok := p.targetsCache[g.id]; ok {
+		return targets
+	}
+	targets, err := p.targets(g)
+	if err != nil {
+		log.Warningf("rebalance: schedule guest %s(%s) error: %v", g.name, g.id, err)
+		targets = []string{}
+	}
+	p.targetsCache[g.id] = targets
+	return targets
+}
+
+func (p *rebalancePlanner) plan() error {
+	for len(p.migrations) < p.maxMigrations {
+		moved, err := p.moveOne()
+		if err != nil {
+			return err
+		}
+		if !moved {
+			break
+		}
+	}
+	return nil
+}
+
+// moveOne plans the migration of one guest, it returns false when no move improves the balance
+func (p *rebalancePlanner) moveOne() (bool, error) {
+	avg := p.averageLoad()
+	sources := make([]*rebalanceHost, len(p.hosts))
+	copy(sources, p.hosts)
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].load() > sources[j].load()
+	})
+	for _, src := range sources {
+		srcLoad := src.load()
+		if srcLoad-avg <= p.threshold {
+			break
+		}
+		guests, err := p.getGuests(src)
+		if err != nil {
+			return false, err
+		}
+		for _, g := range guests {
+			if p.moved[g.id] {
+				continue
+			}
+			for _, targetId := range p.getTargets(g) {
+				dst, ok := p.hostsMap[targetId]
+				if !ok || dst == src || !dst.fits(g) {
+					continue
+				}
+				newSrcLoad := src.loadWith(-g.cpu, -g.mem, -g.storage)
+				newDstLoad := dst.loadWith(g.cpu, g.mem, g.storage)
+				if newSrcLoad >= srcLoad || newDstLoad >= srcLoad {
+					continue
+				}
+				p.move(g, src, dst)
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (p *rebalancePlanner) move(g *rebalanceGuest, src, dst *rebalanceHost) {
+	src.freeCPU += g.cpu
+	src.freeMem += g.mem
+	src.freeStorage += g.storage
+	dst.freeCPU -= g.cpu
+	dst.freeMem -= g.mem
+	dst.freeStorage -= g.storage
+	p.moved[g.id] = true
+	p.migrations = append(p.migrations, &schedapi.RebalanceMigration{
+		GuestId:        g.id,
+		GuestName:      g.name,
+		SourceHostId:   src.id,
+		SourceHostName: src.name,
+		TargetHostId:   dst.id,
+		TargetHostName: dst.name,
+		VcpuCount:      int(g.cpu),
+		VmemSize:       int(g.mem),
+		LocalDiskSize:  int(g.storage),
+	})
+}
+
+// getRebalanceGuests returns the running kvm guests of a host without
+// isolated devices, biggest memory first
+func getRebalanceGuests(h *rebalanceHost) ([]*rebalanceGuest, error) {
+	host := computemodels.HostManager.FetchHostById(h.id)
+	if host == nil {
+		return nil, fmt.Errorf("Host %s not found", h.id)
+	}
+	ret := make([]*rebalanceGuest, 0)
+	guests := host.GetGuests()
+	for i := range guests {
+		guest := &guests[i]
+		if guest.Status != computemodels.VM_RUNNING || guest.GetHypervisor() != computemodels.HYPERVISOR_KVM {
+			continue
+		}
+		if len(guest.GetIsolatedDevices()) > 0 {
+			continue
+		}
+		storage := 0
+		for _, gd := range guest.GetDisks() {
+			disk := gd.GetDisk()
+			if disk == nil {
+				continue
+			}
+			if s := disk.GetStorage(); s != nil && utils.IsInStringArray(s.StorageType, computemodels.STORAGE_LOCAL_TYPES) {
+				storage += disk.DiskSize
+			}
+		}
+		ret = append(ret, &rebalanceGuest{
+			guest:   guest,
+			id:      guest.Id,
+			name:    guest.Name,
+			cpu:     int64(guest.VcpuCount),
+			mem:     int64(guest.VmemSize),
+			storage: int64(storage),
+		})
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].mem > ret[j].mem
+	})
+	return ret, nil
+}
+
+// scheduleRebalanceTargets runs the predicates and priorities of a guest
+// migration in suggestion mode, the hosts are not marked dirty
+func scheduleRebalanceTargets(zoneId string, limit int) func(g *rebalanceGuest) ([]string, error) {
+	return func(g *rebalanceGuest) ([]string, error) {
+		desc := g.guest.ToSchedDesc()
+		desc.PreferZone = zoneId
+		desc.SuggestionLimit = int64(limit)
+		schedInfo := api.NewSchedInfo(desc)
+		schedInfo.IsSuggestion = true
+		result, err := schedman.Schedule(schedInfo)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0)
+		for _, item := range result.Data {
+			if item.Capacity > 0 {
+				ids = append(ids, item.ID)
+			}
+		}
+		return ids, nil
+	}
+}
+
+func doRebalance(c *gin.Context) {
+	if !schedman.IsReady() {
+		c.AbortWithError(http.StatusBadRequest, fmt.Errorf("Global scheduler not init"))
+		return
+	}
+	body, err := appsrv.FetchJSON(c.Request)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	input := new(schedapi.RebalanceInput)
+	if err := body.Unmarshal(input); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	if len(input.ZoneId) == 0 {
+		c.AbortWithError(http.StatusBadRequest, fmt.Errorf("Missing zone_id"))
+		return
+	}
+
+	candidates, err := schedman.GetCandidateHostsDesc()
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	hosts := make([]*rebalanceHost, 0)
+	for _, h := range candidates {
+		if h.ZoneId != input.ZoneId || h.HostType != computemodels.HOST_TYPE_HYPERVISOR {
+			continue
+		}
+		if !h.Enabled || h.HostStatus != computemodels.HOST_ONLINE || h.IsMaintenance {
+			continue
+		}
+		hosts = append(hosts, newRebalanceHost(h))
+	}
+
+	planner := newRebalancePlanner(input, hosts)
+	planner.guests = getRebalanceGuests
+	planner.targets = scheduleRebalanceTargets(input.ZoneId, len(hosts))
+	before := planner.hostLoads()
+	if err := planner.plan(); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	output := &schedapi.RebalanceOutput{
+		Migrations:  planner.migrations,
+		HostsBefore: before,
+		HostsAfter:  planner.hostLoads(),
+	}
+	c.JSON(http.StatusOK, output)
+}
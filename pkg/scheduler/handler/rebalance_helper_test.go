@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"testing"
+
+	schedapi "yunion.io/x/onecloud/pkg/apis/scheduler"
+)
+
+func newTestRebalanceHost(id string, freeCPU, freeMem int64) *rebalanceHost {
+	return &rebalanceHost{
+		id:       id,
+		name:     id,
+		totalCPU: 16,
+		freeCPU:  freeCPU,
+		totalMem: 16384,
+		freeMem:  freeMem,
+	}
+}
+
+func TestRebalancePlanner(t *testing.T) {
+	busy := newTestRebalanceHost("busy", 2, 2048)
+	idle := newTestRebalanceHost("idle", 16, 16384)
+	guests := map[string][]*rebalanceGuest{
+		"busy": {
+			{id: "g1", name: "g1", cpu: 4, mem: 4096},
+			{id: "g2", name: "g2", cpu: 4, mem: 4096},
+			{id: "g3", name: "g3", cpu: 4, mem: 4096},
+			{id: "g4", name: "g4", cpu: 2, mem: 2048},
+		},
+	}
+
+	p := newRebalancePlanner(&schedapi.RebalanceInput{}, []*rebalanceHost{busy, idle})
+	p.guests = func(h *rebalanceHost) ([]*rebalanceGuest, error) {
+		return guests[h.id], nil
+	}
+	p.targets = func(g *rebalanceGuest) ([]string, error) {
+		return []string{"busy", "idle"}, nil
+	}
+	if err := p.plan(); err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+
+	if len(p.migrations) != 2 {
+		t.Fatalf("want 2 migrations, got %d", len(p.migrations))
+	}
+	for _, m := range p.migrations {
+		if m.SourceHostId != "busy" || m.TargetHostId != "idle" {
+			t.Errorf("unexpected migration %s: %s -> %s", m.GuestId, m.SourceHostId, m.TargetHostId)
+		}
+	}
+	if busy.load() != 0.375 || idle.load() != 0.5 {
+		t.Errorf("unexpected loads after rebalance: busy %f, idle %f", busy.load(), idle.load())
+	}
+}
+
+func TestRebalancePlannerMaxMigrations(t *testing.T) {
+	busy := newTestRebalanceHost("busy", 0, 0)
+	idle := newTestRebalanceHost("idle", 16, 16384)
+	p := newRebalancePlanner(&schedapi.RebalanceInput{MaxMigrations: 1}, []*rebalanceHost{busy, idle})
+	p.guests = func(h *rebalanceHost) ([]*rebalanceGuest, error) {
+		return []*rebalanceGuest{
+			{id: "g1", cpu: 4, mem: 4096},
+			{id: "g2", cpu: 4, mem: 4096},
+		}, nil
+	}
+	p.targets = func(g *rebalanceGuest) ([]string, error) {
+		return []string{"idle"}, nil
+	}
+	if err := p.plan(); err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if len(p.migrations) != 1 {
+		t.Errorf("want 1 migration, got %d", len(p.migrations))
+	}
+}
@@ -0,0 +1,213 @@
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJWKThumbprint(t *testing.T) {
+	// RFC 7638, section 3.1
+	n := "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		t.Fatalf("decode n: %s", err)
+	}
+	pub := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: 65537,
+	}
+	got, err := JWKThumbprint(pub)
+	if err != nil {
+		t.Fatalf("thumbprint: %s", err)
+	}
+	want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"
+	if got != want {
+		t.Errorf("thumbprint want %s, got %s", want, got)
+	}
+}
+
+func TestJwsEncode(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key: %s", err)
+	}
+	d, err := jwsEncode(key, "", "nonce0", "https://example.com/acme/new-account", map[string]string{"k": "v"})
+	if err != nil {
+		t.Fatalf("encode: %s", err)
+	}
+	jws := map[string]string{}
+	if err := json.Unmarshal(d, &jws); err != nil {
+		t.Fatalf("unmarshal: %s", err)
+	}
+	protectedJson, _ := base64.RawURLEncoding.DecodeString(jws["protected"])
+	protected := map[string]interface{}{}
+	if err := json.Unmarshal(protectedJson, &protected); err != nil {
+		t.Fatalf("unmarshal protected: %s", err)
+	}
+	if protected["alg"] != "ES256" || protected["nonce"] != "nonce0" {
+		t.Errorf("unexpected protected header: %s", protectedJson)
+	}
+	if _, ok := protected["jwk"]; !ok {
+		t.Errorf("jwk expected in protected header: %s", protectedJson)
+	}
+	sig, _ := base64.RawURLEncoding.DecodeString(jws["signature"])
+	if len(sig) != 64 {
+		t.Fatalf("signature length want 64, got %d", len(sig))
+	}
+	h := sha256.Sum256([]byte(jws["protected"] + "." + jws["payload"]))
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(&key.PublicKey, h[:], r, s) {
+		t.Errorf("signature verification failed")
+	}
+}
+
+func TestMarshalPrivateKey(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key: %s", err)
+	}
+	s, err := MarshalPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal: %s", err)
+	}
+	key1, err := ParsePrivateKey(s)
+	if err != nil {
+		t.Fatalf("parse: %s", err)
+	}
+	if key1.(*ecdsa.PrivateKey).D.Cmp(key.D) != 0 {
+		t.Errorf("parsed key differs")
+	}
+}
+
+// TestPebble runs against a local pebble instance, e.g.
+//
+//	PEBBLE_VA_ALWAYS_VALID=1 pebble -config test/config/pebble-config.json
+//	ACME_TEST_DIRECTORY_URL=https://localhost:14000/dir go test
+//
+// Without PEBBLE_VA_ALWAYS_VALID, pebble validates http-01 challenges
+// against ACME_TEST_HTTP_ADDR (defaults to :5002)
+func TestPebble(t *testing.T) {
+	directoryUrl := os.Getenv("ACME_TEST_DIRECTORY_URL")
+	if directoryUrl == "" {
+		t.Skip("ACME_TEST_DIRECTORY_URL not set")
+	}
+	httpAddr := os.Getenv("ACME_TEST_HTTP_ADDR")
+	if httpAddr == "" {
+		httpAddr = ":5002"
+	}
+	domains := []string{"acme-test.example.com", "www.acme-test.example.com"}
+
+	responses := map[string]string{}
+	responsesLock := sync.Mutex{}
+	listener, err := net.Listen("tcp", httpAddr)
+	if err != nil {
+		t.Fatalf("listen %s: %s", httpAddr, err)
+	}
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := strings.TrimPrefix(r.URL.Path, HTTP01_CHALLENGE_PATH)
+			responsesLock.Lock()
+			keyAuth, ok := responses[token]
+			responsesLock.Unlock()
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, keyAuth)
+		}),
+	}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	accountKey, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("generate account key: %s", err)
+	}
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			// pebble uses a self-signed certificate
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	cli := NewClient(directoryUrl, accountKey, httpClient)
+	if err := cli.Register(ctx, "admin@example.com"); err != nil {
+		t.Fatalf("register: %s", err)
+	}
+	order, err := cli.NewOrder(ctx, domains)
+	if err != nil {
+		t.Fatalf("new order: %s", err)
+	}
+	for _, authzUrl := range order.Authorizations {
+		authz, err := cli.GetAuthorization(ctx, authzUrl)
+		if err != nil {
+			t.Fatalf("get authorization: %s", err)
+		}
+		if authz.Status == STATUS_VALID {
+			continue
+		}
+		chal := authz.GetChallenge(CHALLENGE_TYPE_HTTP01)
+		if chal == nil {
+			t.Fatalf("no http-01 challenge for %s", authz.Identifier.Value)
+		}
+		keyAuth, err := cli.KeyAuthorization(chal.Token)
+		if err != nil {
+			t.Fatalf("key authorization: %s", err)
+		}
+		responsesLock.Lock()
+		responses[chal.Token] = keyAuth
+		responsesLock.Unlock()
+		if err := cli.AcceptChallenge(ctx, chal); err != nil {
+			t.Fatalf("accept challenge: %s", err)
+		}
+		if _, err := cli.WaitAuthorization(ctx, authzUrl); err != nil {
+			t.Fatalf("wait authorization: %s", err)
+		}
+	}
+	certKey, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("generate cert key: %s", err)
+	}
+	csr, err := NewCertificateRequest(certKey, domains)
+	if err != nil {
+		t.Fatalf("csr: %s", err)
+	}
+	order, err = cli.FinalizeOrder(ctx, order, csr)
+	if err != nil {
+		t.Fatalf("finalize: %s", err)
+	}
+	certPem, err := cli.FetchCertificate(ctx, order.Certificate)
+	if err != nil {
+		t.Fatalf("fetch certificate: %s", err)
+	}
+	block, _ := pem.Decode([]byte(certPem))
+	if block == nil {
+		t.Fatalf("no pem block in certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse certificate: %s", err)
+	}
+	if err := cert.VerifyHostname(domains[1]); err != nil {
+		t.Errorf("verify hostname: %s", err)
+	}
+}
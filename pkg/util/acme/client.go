@@ -0,0 +1,439 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"yunion.io/x/onecloud/pkg/util/httputils"
+)
+
+// Subset of ACME protocol (RFC 8555) for obtaining certificates with
+// http-01 challenges
+const (
+	LETSENCRYPT_DIRECTORY_URL = "https://acme-v02.api.letsencrypt.org/directory"
+
+	CHALLENGE_TYPE_HTTP01 = "http-01"
+
+	// http-01 challenge responses are served at this path prefix
+	HTTP01_CHALLENGE_PATH = "/.well-known/acme-challenge/"
+
+	STATUS_PENDING     = "pending"
+	STATUS_READY       = "ready"
+	STATUS_PROCESSING  = "processing"
+	STATUS_VALID       = "valid"
+	STATUS_INVALID     = "invalid"
+	STATUS_DEACTIVATED = "deactivated"
+	STATUS_EXPIRED     = "expired"
+	STATUS_REVOKED     = "revoked"
+
+	contentTypeJose = "application/jose+json"
+
+	defaultPollInterval = 2 * time.Second
+)
+
+type SDirectory struct {
+	NewNonce   string `json:"newNonce"`
+	NewAccount string `json:"newAccount"`
+	NewOrder   string `json:"newOrder"`
+	RevokeCert string `json:"revokeCert"`
+	KeyChange  string `json:"keyChange"`
+}
+
+type SProblem struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
+}
+
+func (p *SProblem) Error() string {
+	return fmt.Sprintf("acme: %d %s: %s", p.Status, p.Type, p.Detail)
+}
+
+func (p *SProblem) isBadNonce() bool {
+	return p.Type == "urn:ietf:params:acme:error:badNonce"
+}
+
+type SIdentifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type SOrder struct {
+	Url string `json:"-"`
+
+	Status         string        `json:"status"`
+	Expires        string        `json:"expires"`
+	Identifiers    []SIdentifier `json:"identifiers"`
+	Authorizations []string      `json:"authorizations"`
+	Finalize       string        `json:"finalize"`
+	Certificate    string        `json:"certificate"`
+	Error          *SProblem     `json:"error"`
+}
+
+type SChallenge struct {
+	Type   string    `json:"type"`
+	Url    string    `json:"url"`
+	Status string    `json:"status"`
+	Token  string    `json:"token"`
+	Error  *SProblem `json:"error"`
+}
+
+type SAuthorization struct {
+	Url string `json:"-"`
+
+	Status     string       `json:"status"`
+	Identifier SIdentifier  `json:"identifier"`
+	Challenges []SChallenge `json:"challenges"`
+	Wildcard   bool         `json:"wildcard"`
+}
+
+func (authz *SAuthorization) GetChallenge(typ string) *SChallenge {
+	for i := range authz.Challenges {
+		if authz.Challenges[i].Type == typ {
+			return &authz.Challenges[i]
+		}
+	}
+	return nil
+}
+
+func (authz *SAuthorization) problem() error {
+	for i := range authz.Challenges {
+		if chal := &authz.Challenges[i]; chal.Error != nil {
+			return chal.Error
+		}
+	}
+	return fmt.Errorf("authorization %s for %s is %s", authz.Url, authz.Identifier.Value, authz.Status)
+}
+
+type SClient struct {
+	DirectoryUrl string
+	Key          crypto.Signer
+	HttpClient   *http.Client
+
+	directory *SDirectory
+	kid       string
+
+	nonceLock sync.Mutex
+	nonces    []string
+}
+
+func NewClient(directoryUrl string, key crypto.Signer, client *http.Client) *SClient {
+	if client == nil {
+		client = httputils.GetClient(false)
+	}
+	return &SClient{
+		DirectoryUrl: directoryUrl,
+		Key:          key,
+		HttpClient:   client,
+	}
+}
+
+func (cli *SClient) getDirectory(ctx context.Context) (*SDirectory, error) {
+	if cli.directory != nil {
+		return cli.directory, nil
+	}
+	resp, err := httputils.Request(cli.HttpClient, ctx, httputils.GET, cli.DirectoryUrl, nil, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := responseError(resp); err != nil {
+		return nil, err
+	}
+	dir := &SDirectory{}
+	if err := json.NewDecoder(resp.Body).Decode(dir); err != nil {
+		return nil, fmt.Errorf("decode directory: %s", err)
+	}
+	cli.directory = dir
+	return dir, nil
+}
+
+func (cli *SClient) saveNonce(resp *http.Response) {
+	nonce := resp.Header.Get("Replay-Nonce")
+	if nonce == "" {
+		return
+	}
+	cli.nonceLock.Lock()
+	defer cli.nonceLock.Unlock()
+	cli.nonces = append(cli.nonces, nonce)
+}
+
+func (cli *SClient) popNonce(ctx context.Context) (string, error) {
+	cli.nonceLock.Lock()
+	if n := len(cli.nonces); n > 0 {
+		nonce := cli.nonces[n-1]
+		cli.nonces = cli.nonces[:n-1]
+		cli.nonceLock.Unlock()
+		return nonce, nil
+	}
+	cli.nonceLock.Unlock()
+
+	dir, err := cli.getDirectory(ctx)
+	if err != nil {
+		return "", err
+	}
+	resp, err := httputils.Request(cli.HttpClient, ctx, httputils.HEAD, dir.NewNonce, nil, nil, false)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	nonce := resp.Header.Get("Replay-Nonce")
+	if nonce == "" {
+		return "", fmt.Errorf("acme: no nonce in %s response", dir.NewNonce)
+	}
+	return nonce, nil
+}
+
+func responseError(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+	d, _ := ioutil.ReadAll(resp.Body)
+	problem := &SProblem{}
+	if err := json.Unmarshal(d, problem); err != nil || problem.Type == "" {
+		return fmt.Errorf("acme: %s: %s", resp.Status, string(d))
+	}
+	if problem.Status == 0 {
+		problem.Status = resp.StatusCode
+	}
+	return problem
+}
+
+// post sends signed request.  Payload nil means POST-as-GET.  The caller
+// is responsible for closing the response body
+func (cli *SClient) post(ctx context.Context, url string, payload interface{}) (*http.Response, error) {
+	var lastErr error
+	// retry once on badNonce as recommended by the rfc
+	for i := 0; i < 2; i++ {
+		nonce, err := cli.popNonce(ctx)
+		if err != nil {
+			return nil, err
+		}
+		body, err := jwsEncode(cli.Key, cli.kid, nonce, url, payload)
+		if err != nil {
+			return nil, err
+		}
+		header := http.Header{}
+		header.Set("Content-Type", contentTypeJose)
+		resp, err := httputils.Request(cli.HttpClient, ctx, httputils.POST, url, header, bytes.NewReader(body), false)
+		if err != nil {
+			return nil, err
+		}
+		cli.saveNonce(resp)
+		err = responseError(resp)
+		if err == nil {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if problem, ok := err.(*SProblem); ok && problem.isBadNonce() {
+			lastErr = err
+			continue
+		}
+		return nil, err
+	}
+	return nil, lastErr
+}
+
+func (cli *SClient) postJSON(ctx context.Context, url string, payload interface{}, v interface{}) (*http.Response, error) {
+	resp, err := cli.post(ctx, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("decode %s response: %s", url, err)
+	}
+	return resp, nil
+}
+
+// Register creates the account, or looks up the existing one bound to the
+// key.  Terms of service are agreed implicitly
+func (cli *SClient) Register(ctx context.Context, email string) error {
+	dir, err := cli.getDirectory(ctx)
+	if err != nil {
+		return err
+	}
+	payload := map[string]interface{}{
+		"termsOfServiceAgreed": true,
+	}
+	if email != "" {
+		payload["contact"] = []string{"mailto:" + email}
+	}
+	account := map[string]interface{}{}
+	resp, err := cli.postJSON(ctx, dir.NewAccount, payload, &account)
+	if err != nil {
+		return err
+	}
+	kid := resp.Header.Get("Location")
+	if kid == "" {
+		return fmt.Errorf("acme: no account url in response")
+	}
+	cli.kid = kid
+	return nil
+}
+
+func (cli *SClient) NewOrder(ctx context.Context, domains []string) (*SOrder, error) {
+	dir, err := cli.getDirectory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	identifiers := []SIdentifier{}
+	for _, domain := range domains {
+		identifiers = append(identifiers, SIdentifier{Type: "dns", Value: domain})
+	}
+	payload := map[string]interface{}{
+		"identifiers": identifiers,
+	}
+	order := &SOrder{}
+	resp, err := cli.postJSON(ctx, dir.NewOrder, payload, order)
+	if err != nil {
+		return nil, err
+	}
+	order.Url = resp.Header.Get("Location")
+	return order, nil
+}
+
+func (cli *SClient) GetOrder(ctx context.Context, url string) (*SOrder, error) {
+	order := &SOrder{}
+	if _, err := cli.postJSON(ctx, url, nil, order); err != nil {
+		return nil, err
+	}
+	order.Url = url
+	return order, nil
+}
+
+func (cli *SClient) GetAuthorization(ctx context.Context, url string) (*SAuthorization, error) {
+	authz := &SAuthorization{}
+	if _, err := cli.postJSON(ctx, url, nil, authz); err != nil {
+		return nil, err
+	}
+	authz.Url = url
+	return authz, nil
+}
+
+// KeyAuthorization returns the content to be served for the challenge token
+func (cli *SClient) KeyAuthorization(token string) (string, error) {
+	thumbprint, err := JWKThumbprint(cli.Key.Public())
+	if err != nil {
+		return "", err
+	}
+	return token + "." + thumbprint, nil
+}
+
+// AcceptChallenge tells the server that the challenge response is ready
+func (cli *SClient) AcceptChallenge(ctx context.Context, chal *SChallenge) error {
+	return cli.postJSONDiscard(ctx, chal.Url, map[string]interface{}{})
+}
+
+func (cli *SClient) postJSONDiscard(ctx context.Context, url string, payload interface{}) error {
+	resp, err := cli.post(ctx, url, payload)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WaitAuthorization polls until the authorization is no longer pending.
+// Error is returned if it ends up not being valid
+func (cli *SClient) WaitAuthorization(ctx context.Context, url string) (*SAuthorization, error) {
+	for {
+		authz, err := cli.GetAuthorization(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		switch authz.Status {
+		case STATUS_VALID:
+			return authz, nil
+		case STATUS_PENDING:
+		default:
+			return authz, authz.problem()
+		}
+		if err := sleepCtx(ctx, defaultPollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// WaitOrder polls until the order is no longer pending or processing
+func (cli *SClient) WaitOrder(ctx context.Context, url string) (*SOrder, error) {
+	for {
+		order, err := cli.GetOrder(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		switch order.Status {
+		case STATUS_READY, STATUS_VALID:
+			return order, nil
+		case STATUS_PENDING, STATUS_PROCESSING:
+		default:
+			if order.Error != nil {
+				return order, order.Error
+			}
+			return order, fmt.Errorf("order %s is %s", url, order.Status)
+		}
+		if err := sleepCtx(ctx, defaultPollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// FinalizeOrder submits DER encoded csr and waits for the certificate to
+// be issued
+func (cli *SClient) FinalizeOrder(ctx context.Context, order *SOrder, csr []byte) (*SOrder, error) {
+	payload := map[string]string{
+		"csr": b64(csr),
+	}
+	if err := cli.postJSONDiscard(ctx, order.Finalize, payload); err != nil {
+		return nil, err
+	}
+	for {
+		o, err := cli.WaitOrder(ctx, order.Url)
+		if err != nil {
+			return nil, err
+		}
+		if o.Status == STATUS_VALID {
+			return o, nil
+		}
+		// ready but not yet processed
+		if err := sleepCtx(ctx, defaultPollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// FetchCertificate downloads the PEM encoded certificate chain
+func (cli *SClient) FetchCertificate(ctx context.Context, url string) (string, error) {
+	resp, err := cli.post(ctx, url, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	d, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(string(d), "-----BEGIN CERTIFICATE-----") {
+		return "", fmt.Errorf("acme: unexpected certificate content type %s", resp.Header.Get("Content-Type"))
+	}
+	return string(d), nil
+}
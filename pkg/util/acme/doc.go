@@ -0,0 +1 @@
+package acme // import "yunion.io/x/onecloud/pkg/util/acme"
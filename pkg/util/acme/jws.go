@@ -0,0 +1,211 @@
+package acme
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+)
+
+func b64(d []byte) string {
+	return base64.RawURLEncoding.EncodeToString(d)
+}
+
+// JWK returns the json web key (RFC 7517) of the public key, with members
+// in lexicographic order as required by thumbprint calculation (RFC 7638)
+func JWK(pub crypto.PublicKey) (string, error) {
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`,
+			pub.Curve.Params().Name,
+			b64(padBytes(pub.X.Bytes(), size)),
+			b64(padBytes(pub.Y.Bytes(), size)),
+		), nil
+	case *rsa.PublicKey:
+		return fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`,
+			b64(big.NewInt(int64(pub.E)).Bytes()),
+			b64(pub.N.Bytes()),
+		), nil
+	}
+	return "", fmt.Errorf("unsupported key type %T", pub)
+}
+
+// JWKThumbprint returns base64url encoded SHA-256 thumbprint of the key
+func JWKThumbprint(pub crypto.PublicKey) (string, error) {
+	jwk, err := JWK(pub)
+	if err != nil {
+		return "", err
+	}
+	d := sha256.Sum256([]byte(jwk))
+	return b64(d[:]), nil
+}
+
+func padBytes(d []byte, size int) []byte {
+	if len(d) >= size {
+		return d
+	}
+	r := make([]byte, size)
+	copy(r[size-len(d):], d)
+	return r
+}
+
+func jwsAlgorithm(key crypto.Signer) (string, error) {
+	switch pub := key.Public().(type) {
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return "ES256", nil
+		case elliptic.P384():
+			return "ES384", nil
+		}
+		return "", fmt.Errorf("unsupported curve %s", pub.Curve.Params().Name)
+	case *rsa.PublicKey:
+		return "RS256", nil
+	}
+	return "", fmt.Errorf("unsupported key type %T", key.Public())
+}
+
+func jwsSign(key crypto.Signer, alg string, data []byte) ([]byte, error) {
+	switch alg {
+	case "ES256", "ES384":
+		hash := crypto.SHA256
+		if alg == "ES384" {
+			hash = crypto.SHA384
+		}
+		h := hash.New()
+		h.Write(data)
+		ecKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s requires ecdsa key, got %T", alg, key)
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, h.Sum(nil))
+		if err != nil {
+			return nil, err
+		}
+		// r || s, each padded to the curve size
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		sig := padBytes(r.Bytes(), size)
+		sig = append(sig, padBytes(s.Bytes(), size)...)
+		return sig, nil
+	case "RS256":
+		h := sha256.Sum256(data)
+		return key.Sign(rand.Reader, h[:], crypto.SHA256)
+	}
+	return nil, fmt.Errorf("unsupported jws algorithm %s", alg)
+}
+
+// jwsEncode signs payload in flattened json serialization.  When kid is
+// empty, the jwk of the key is embedded in the protected header.  A nil
+// payload results in an empty payload string, which is used for
+// POST-as-GET requests
+func jwsEncode(key crypto.Signer, kid, nonce, url string, payload interface{}) ([]byte, error) {
+	alg, err := jwsAlgorithm(key)
+	if err != nil {
+		return nil, err
+	}
+	protected := map[string]interface{}{
+		"alg":   alg,
+		"nonce": nonce,
+		"url":   url,
+	}
+	if kid != "" {
+		protected["kid"] = kid
+	} else {
+		jwk, err := JWK(key.Public())
+		if err != nil {
+			return nil, err
+		}
+		protected["jwk"] = json.RawMessage(jwk)
+	}
+	protectedJson, err := json.Marshal(protected)
+	if err != nil {
+		return nil, err
+	}
+	payloadB64 := ""
+	if payload != nil {
+		payloadJson, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		payloadB64 = b64(payloadJson)
+	}
+	protectedB64 := b64(protectedJson)
+	sig, err := jwsSign(key, alg, []byte(protectedB64+"."+payloadB64))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]string{
+		"protected": protectedB64,
+		"payload":   payloadB64,
+		"signature": b64(sig),
+	})
+}
+
+// GenerateKey generates an ECDSA P-256 key suitable for both accounts and
+// certificates
+func GenerateKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+func MarshalPrivateKey(key crypto.Signer) (string, error) {
+	var block *pem.Block
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		d, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return "", err
+		}
+		block = &pem.Block{Type: "EC PRIVATE KEY", Bytes: d}
+	case *rsa.PrivateKey:
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	default:
+		return "", fmt.Errorf("unsupported key type %T", key)
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+func ParsePrivateKey(s string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, fmt.Errorf("no pem block found")
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("unsupported pem type %s", block.Type)
+}
+
+// NewCertificateRequest creates DER encoded csr with the first domain as
+// common name and all domains as subject alternative names
+func NewCertificateRequest(key crypto.Signer, domains []string) ([]byte, error) {
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("no domain specified")
+	}
+	tmpl := &x509.CertificateRequest{
+		DNSNames: domains,
+	}
+	tmpl.Subject.CommonName = domains[0]
+	return x509.CreateCertificateRequest(rand.Reader, tmpl, key)
+}
@@ -25,6 +25,7 @@ const (
 	ALIYUN_API_VERSION     = "2014-05-26"
 	ALIYUN_API_VERSION_VPC = "2016-04-28"
 	ALIYUN_API_VERSION_LB  = "2014-05-15"
+	ALIYUN_API_VERSION_RDS = "2014-08-15"
 
 	ALIYUN_BSS_API_VERSION = "2017-12-14"
 
@@ -0,0 +1,264 @@
+package aliyun
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SBucket struct {
+	region *SRegion
+
+	Name         string
+	Location     string
+	CreationDate time.Time
+	StorageClass string
+
+	acl string
+}
+
+func (b *SBucket) GetId() string {
+	return b.Name
+}
+
+func (b *SBucket) GetName() string {
+	return b.Name
+}
+
+func (b *SBucket) GetGlobalId() string {
+	return b.Name
+}
+
+func (b *SBucket) GetStatus() string {
+	return api.BUCKET_STATUS_READY
+}
+
+func (b *SBucket) Refresh() error {
+	b.acl = ""
+	return nil
+}
+
+func (b *SBucket) IsEmulated() bool {
+	return false
+}
+
+func (b *SBucket) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (b *SBucket) GetLocation() string {
+	return b.Location
+}
+
+func (b *SBucket) GetStorageClass() string {
+	return b.StorageClass
+}
+
+func (b *SBucket) GetCreateAt() time.Time {
+	return b.CreationDate
+}
+
+// ACL需要单独查询, 按需获取
+func (b *SBucket) GetAcl() cloudprovider.TBucketACLType {
+	if len(b.acl) == 0 {
+		osscli, err := b.region.GetOssClient()
+		if err != nil {
+			log.Errorf("b.region.GetOssClient fail %s", err)
+			return cloudprovider.ACLDefault
+		}
+		result, err := osscli.GetBucketACL(b.Name)
+		if err != nil {
+			log.Errorf("GetBucketACL %s fail %s", b.Name, err)
+			return cloudprovider.ACLDefault
+		}
+		b.acl = result.ACL
+	}
+	return cloudprovider.TBucketACLType(b.acl)
+}
+
+func (b *SBucket) SetAcl(aclStr cloudprovider.TBucketACLType) error {
+	osscli, err := b.region.GetOssClient()
+	if err != nil {
+		return err
+	}
+	acl, err := str2Acl(string(aclStr))
+	if err != nil {
+		return err
+	}
+	err = osscli.SetBucketACL(b.Name, acl)
+	if err != nil {
+		return err
+	}
+	b.acl = string(acl)
+	return nil
+}
+
+func (b *SBucket) ListObjects(prefix string, marker string, delimiter string, maxCount int) (cloudprovider.SListObjectResult, error) {
+	result := cloudprovider.SListObjectResult{}
+	osscli, err := b.region.GetOssClient()
+	if err != nil {
+		return result, err
+	}
+	bucket, err := osscli.Bucket(b.Name)
+	if err != nil {
+		return result, err
+	}
+	opts := make([]oss.Option, 0)
+	if len(prefix) > 0 {
+		opts = append(opts, oss.Prefix(prefix))
+	}
+	if len(marker) > 0 {
+		opts = append(opts, oss.Marker(marker))
+	}
+	if len(delimiter) > 0 {
+		opts = append(opts, oss.Delimiter(delimiter))
+	}
+	if maxCount > 0 {
+		opts = append(opts, oss.MaxKeys(maxCount))
+	}
+	oResult, err := bucket.ListObjects(opts...)
+	if err != nil {
+		return result, err
+	}
+	result.Objects = make([]cloudprovider.SCloudObject, 0)
+	for _, object := range oResult.Objects {
+		result.Objects = append(result.Objects, cloudprovider.SCloudObject{
+			Key:          object.Key,
+			SizeBytes:    object.Size,
+			StorageClass: object.StorageClass,
+			ETag:         object.ETag,
+			LastModified: object.LastModified,
+		})
+	}
+	result.CommonPrefixes = oResult.CommonPrefixes
+	result.IsTruncated = oResult.IsTruncated
+	result.NextMarker = oResult.NextMarker
+	return result, nil
+}
+
+func str2Acl(aclStr string) (oss.ACLType, error) {
+	switch aclStr {
+	case string(cloudprovider.ACLPrivate):
+		return oss.ACLPrivate, nil
+	case string(cloudprovider.ACLPublicRead):
+		return oss.ACLPublicRead, nil
+	case string(cloudprovider.ACLPublicReadWrite):
+		return oss.ACLPublicReadWrite, nil
+	default:
+		return "", fmt.Errorf("unsupported acl %s", aclStr)
+	}
+}
+
+func str2StorageClass(storageClassStr string) (oss.StorageClassType, error) {
+	switch storageClassStr {
+	case string(oss.StorageStandard), "":
+		return oss.StorageStandard, nil
+	case string(oss.StorageIA):
+		return oss.StorageIA, nil
+	case string(oss.StorageArchive):
+		return oss.StorageArchive, nil
+	default:
+		return "", fmt.Errorf("unsupported storage class %s", storageClassStr)
+	}
+}
+
+// OSS的ListBuckets返回账号下所有区域的bucket, 需按Location过滤
+func (self *SRegion) GetBuckets() ([]SBucket, error) {
+	osscli, err := self.GetOssClient()
+	if err != nil {
+		return nil, err
+	}
+	location := fmt.Sprintf("oss-%s", self.RegionId)
+	buckets := make([]SBucket, 0)
+	marker := ""
+	for {
+		result, err := osscli.ListBuckets(oss.Marker(marker))
+		if err != nil {
+			return nil, err
+		}
+		for _, bucket := range result.Buckets {
+			if bucket.Location != location {
+				continue
+			}
+			buckets = append(buckets, SBucket{
+				region:       self,
+				Name:         bucket.Name,
+				Location:     bucket.Location,
+				CreationDate: bucket.CreationDate,
+				StorageClass: bucket.StorageClass,
+			})
+		}
+		if !result.IsTruncated || len(result.NextMarker) == 0 {
+			break
+		}
+		marker = result.NextMarker
+	}
+	return buckets, nil
+}
+
+func (self *SRegion) GetIBuckets() ([]cloudprovider.ICloudBucket, error) {
+	buckets, err := self.GetBuckets()
+	if err != nil {
+		return nil, err
+	}
+	ibuckets := make([]cloudprovider.ICloudBucket, len(buckets))
+	for i := 0; i < len(buckets); i++ {
+		ibuckets[i] = &buckets[i]
+	}
+	return ibuckets, nil
+}
+
+func (self *SRegion) GetIBucketById(name string) (cloudprovider.ICloudBucket, error) {
+	buckets, err := self.GetBuckets()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(buckets); i++ {
+		if buckets[i].Name == name {
+			return &buckets[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) CreateIBucket(name string, storageClassStr string, aclStr string) error {
+	osscli, err := self.GetOssClient()
+	if err != nil {
+		return err
+	}
+	storageClass, err := str2StorageClass(storageClassStr)
+	if err != nil {
+		return err
+	}
+	opts := []oss.Option{oss.StorageClass(storageClass)}
+	if len(aclStr) > 0 {
+		acl, err := str2Acl(aclStr)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, oss.ACL(acl))
+	}
+	return osscli.CreateBucket(name, opts...)
+}
+
+func (self *SRegion) DeleteIBucket(name string) error {
+	osscli, err := self.GetOssClient()
+	if err != nil {
+		return err
+	}
+	err = osscli.DeleteBucket(name)
+	if err != nil {
+		if serr, ok := err.(oss.ServiceError); ok && serr.StatusCode == 404 {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
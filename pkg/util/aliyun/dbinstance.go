@@ -0,0 +1,494 @@
+package aliyun
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SDBInstance struct {
+	region *SRegion
+
+	attr     *SDBInstanceAttribute
+	netInfos []SDBInstanceNetInfo
+
+	DBInstanceId          string
+	DBInstanceDescription string
+	DBInstanceType        string
+	DBInstanceClass       string
+	DBInstanceStatus      string
+	DBInstanceStorageType string
+	DBInstanceNetType     string
+	Category              string
+	Engine                string
+	EngineVersion         string
+	PayType               string
+	ExpireTime            time.Time
+	CreateTime            time.Time
+	InstanceNetworkType   string
+	RegionId              string
+	ZoneId                string
+	VpcId                 string
+	VSwitchId             string
+	LockMode              string
+}
+
+type SDBInstanceAttribute struct {
+	DBInstanceCPU     string
+	DBInstanceMemory  int
+	DBInstanceStorage int
+	ConnectionString  string
+	Port              string
+	MaxIOPS           int
+	MaxConnections    int
+}
+
+type SDBInstanceNetInfo struct {
+	ConnectionString string
+	IPAddress        string
+	// Inner: 经典网络内网, Private: VPC内网, Public: 外网
+	IPType    string
+	Port      string
+	VPCId     string
+	VSwitchId string
+}
+
+func (rds *SDBInstance) GetId() string {
+	return rds.DBInstanceId
+}
+
+func (rds *SDBInstance) GetName() string {
+	if len(rds.DBInstanceDescription) > 0 {
+		return rds.DBInstanceDescription
+	}
+	return rds.DBInstanceId
+}
+
+func (rds *SDBInstance) GetGlobalId() string {
+	return rds.DBInstanceId
+}
+
+func (rds *SDBInstance) GetStatus() string {
+	switch rds.DBInstanceStatus {
+	case "Creating", "GuardDBInstanceCreating", "DBInstanceClassChanging", "INS_CLONING", "Restoring", "Importing", "ImportingFromOthers":
+		return api.DBINSTANCE_STATUS_DEPLOYING
+	case "Running":
+		return api.DBINSTANCE_STATUS_RUNNING
+	case "Deleting":
+		return api.DBINSTANCE_STATUS_DELETING
+	case "Rebooting":
+		return api.DBINSTANCE_STATUS_REBOOTING
+	case "TRANSING", "TransingToOthers", "EngineVersionUpgrading", "DBInstanceNetTypeChanging", "GuardSwitching":
+		return api.DBINSTANCE_STATUS_MAINTENANCE
+	default:
+		return api.DBINSTANCE_STATUS_UNKNOWN
+	}
+}
+
+func (rds *SDBInstance) Refresh() error {
+	instance, err := rds.region.GetDBInstanceDetail(rds.DBInstanceId)
+	if err != nil {
+		return err
+	}
+	rds.attr = nil
+	rds.netInfos = nil
+	return jsonutils.Update(rds, instance)
+}
+
+func (rds *SDBInstance) IsEmulated() bool {
+	return false
+}
+
+func (rds *SDBInstance) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (rds *SDBInstance) GetBillingType() string {
+	// 阿里云RDS付费类型为 Postpaid 或 Prepaid
+	if strings.ToLower(rds.PayType) == "prepaid" {
+		return convertChargeType(PrePaidInstanceChargeType)
+	}
+	return convertChargeType(PostPaidInstanceChargeType)
+}
+
+func (rds *SDBInstance) GetExpiredAt() time.Time {
+	return convertExpiredAt(rds.ExpireTime)
+}
+
+func (rds *SDBInstance) GetEngine() string {
+	switch rds.Engine {
+	case "MySQL":
+		return api.DBINSTANCE_ENGINE_MYSQL
+	case "SQLServer":
+		return api.DBINSTANCE_ENGINE_SQLSERVER
+	case "PostgreSQL":
+		return api.DBINSTANCE_ENGINE_POSTGRESQL
+	case "MariaDB":
+		return api.DBINSTANCE_ENGINE_MARIADB
+	case "PPAS":
+		return api.DBINSTANCE_ENGINE_PPAS
+	}
+	return rds.Engine
+}
+
+func (rds *SDBInstance) GetEngineVersion() string {
+	return rds.EngineVersion
+}
+
+func (rds *SDBInstance) GetInstanceType() string {
+	return rds.DBInstanceClass
+}
+
+func (rds *SDBInstance) fetchAttribute() *SDBInstanceAttribute {
+	if rds.attr != nil {
+		return rds.attr
+	}
+	attr, err := rds.region.GetDBInstanceAttribute(rds.DBInstanceId)
+	if err != nil {
+		log.Errorf("failed to fetch attribute for dbinstance %s: %v", rds.DBInstanceId, err)
+		return &SDBInstanceAttribute{}
+	}
+	rds.attr = attr
+	return rds.attr
+}
+
+func (rds *SDBInstance) fetchNetInfos() []SDBInstanceNetInfo {
+	if rds.netInfos != nil {
+		return rds.netInfos
+	}
+	netInfos, err := rds.region.GetDBInstanceNetInfo(rds.DBInstanceId)
+	if err != nil {
+		log.Errorf("failed to fetch net info for dbinstance %s: %v", rds.DBInstanceId, err)
+		return []SDBInstanceNetInfo{}
+	}
+	rds.netInfos = netInfos
+	return rds.netInfos
+}
+
+func (rds *SDBInstance) GetVcpuCount() int {
+	cpu, _ := strconv.Atoi(rds.fetchAttribute().DBInstanceCPU)
+	return cpu
+}
+
+func (rds *SDBInstance) GetVmemSizeMB() int {
+	return rds.fetchAttribute().DBInstanceMemory
+}
+
+func (rds *SDBInstance) GetDiskSizeGB() int {
+	return rds.fetchAttribute().DBInstanceStorage
+}
+
+func (rds *SDBInstance) GetCategory() string {
+	return strings.ToLower(rds.Category)
+}
+
+func (rds *SDBInstance) GetStorageType() string {
+	return rds.DBInstanceStorageType
+}
+
+func (rds *SDBInstance) GetCreateTime() time.Time {
+	return rds.CreateTime
+}
+
+func (rds *SDBInstance) GetPort() int {
+	port, _ := strconv.Atoi(rds.fetchAttribute().Port)
+	return port
+}
+
+func (rds *SDBInstance) GetConnectionStr() string {
+	for _, net := range rds.fetchNetInfos() {
+		if net.IPType == "Public" {
+			return net.ConnectionString
+		}
+	}
+	return ""
+}
+
+func (rds *SDBInstance) GetInternalConnectionStr() string {
+	for _, net := range rds.fetchNetInfos() {
+		if net.IPType != "Public" {
+			return net.ConnectionString
+		}
+	}
+	return rds.fetchAttribute().ConnectionString
+}
+
+func (rds *SDBInstance) GetVpcId() string {
+	return rds.VpcId
+}
+
+func (rds *SDBInstance) GetNetworkId() string {
+	return rds.VSwitchId
+}
+
+func (rds *SDBInstance) GetZoneId() string {
+	// 多可用区实例的ZoneId形如 cn-hangzhou-MAZ6(b,c), 无法对应到单个可用区
+	zone, err := rds.region.getZoneById(rds.ZoneId)
+	if err != nil {
+		return ""
+	}
+	return zone.GetGlobalId()
+}
+
+func (rds *SDBInstance) GetIDBInstanceBackups() ([]cloudprovider.ICloudDBInstanceBackup, error) {
+	backups, err := rds.region.GetDBInstanceBackups(rds.DBInstanceId, rds.CreateTime)
+	if err != nil {
+		return nil, err
+	}
+	ibackups := make([]cloudprovider.ICloudDBInstanceBackup, len(backups))
+	for i := 0; i < len(backups); i++ {
+		ibackups[i] = &backups[i]
+	}
+	return ibackups, nil
+}
+
+func (rds *SDBInstance) Start() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (rds *SDBInstance) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (rds *SDBInstance) Reboot() error {
+	return rds.region.RestartDBInstance(rds.DBInstanceId)
+}
+
+func (rds *SDBInstance) Delete() error {
+	return rds.region.DeleteDBInstance(rds.DBInstanceId)
+}
+
+func (region *SRegion) GetDBInstances(instanceId string, offset int, limit int) ([]SDBInstance, int, error) {
+	if limit > 100 || limit < 30 {
+		limit = 30
+	}
+	params := make(map[string]string)
+	params["RegionId"] = region.RegionId
+	params["PageSize"] = fmt.Sprintf("%d", limit)
+	params["PageNumber"] = fmt.Sprintf("%d", (offset/limit)+1)
+	if len(instanceId) > 0 {
+		params["DBInstanceId"] = instanceId
+	}
+
+	body, err := region.rdsRequest("DescribeDBInstances", params)
+	if err != nil {
+		log.Errorf("DescribeDBInstances fail %s", err)
+		return nil, 0, err
+	}
+
+	instances := make([]SDBInstance, 0)
+	err = body.Unmarshal(&instances, "Items", "DBInstance")
+	if err != nil {
+		log.Errorf("Unmarshal dbinstances fail %s", err)
+		return nil, 0, err
+	}
+	total, _ := body.Int("TotalRecordCount")
+	return instances, int(total), nil
+}
+
+func (region *SRegion) GetDBInstanceDetail(instanceId string) (*SDBInstance, error) {
+	instances, total, err := region.GetDBInstances(instanceId, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if total != 1 || len(instances) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	instances[0].region = region
+	return &instances[0], nil
+}
+
+func (region *SRegion) GetDBInstanceAttribute(instanceId string) (*SDBInstanceAttribute, error) {
+	params := map[string]string{
+		"RegionId":     region.RegionId,
+		"DBInstanceId": instanceId,
+	}
+	body, err := region.rdsRequest("DescribeDBInstanceAttribute", params)
+	if err != nil {
+		return nil, err
+	}
+	attrs := make([]SDBInstanceAttribute, 0)
+	err = body.Unmarshal(&attrs, "Items", "DBInstanceAttribute")
+	if err != nil {
+		return nil, err
+	}
+	if len(attrs) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &attrs[0], nil
+}
+
+func (region *SRegion) GetDBInstanceNetInfo(instanceId string) ([]SDBInstanceNetInfo, error) {
+	params := map[string]string{
+		"RegionId":     region.RegionId,
+		"DBInstanceId": instanceId,
+	}
+	body, err := region.rdsRequest("DescribeDBInstanceNetInfo", params)
+	if err != nil {
+		return nil, err
+	}
+	netInfos := make([]SDBInstanceNetInfo, 0)
+	err = body.Unmarshal(&netInfos, "DBInstanceNetInfos", "DBInstanceNetInfo")
+	if err != nil {
+		return nil, err
+	}
+	return netInfos, nil
+}
+
+func (region *SRegion) RestartDBInstance(instanceId string) error {
+	params := map[string]string{
+		"RegionId":     region.RegionId,
+		"DBInstanceId": instanceId,
+	}
+	_, err := region.rdsRequest("RestartDBInstance", params)
+	return err
+}
+
+func (region *SRegion) DeleteDBInstance(instanceId string) error {
+	params := map[string]string{
+		"RegionId":     region.RegionId,
+		"DBInstanceId": instanceId,
+	}
+	_, err := region.rdsRequest("DeleteDBInstance", params)
+	return err
+}
+
+func (region *SRegion) GetIDBInstances() ([]cloudprovider.ICloudDBInstance, error) {
+	instances := make([]SDBInstance, 0)
+	for {
+		parts, total, err := region.GetDBInstances("", len(instances), 50)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, parts...)
+		if len(instances) >= total || len(parts) == 0 {
+			break
+		}
+	}
+	iinstances := make([]cloudprovider.ICloudDBInstance, len(instances))
+	for i := 0; i < len(instances); i++ {
+		instances[i].region = region
+		iinstances[i] = &instances[i]
+	}
+	return iinstances, nil
+}
+
+func (region *SRegion) GetIDBInstanceById(instanceId string) (cloudprovider.ICloudDBInstance, error) {
+	instance, err := region.GetDBInstanceDetail(instanceId)
+	if err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+type SDBInstanceBackup struct {
+	BackupId        string
+	DBInstanceId    string
+	BackupStatus    string
+	BackupStartTime time.Time
+	BackupEndTime   time.Time
+	BackupType      string
+	BackupMode      string
+	BackupMethod    string
+	BackupSize      int64
+	BackupLocation  string
+}
+
+func (backup *SDBInstanceBackup) GetId() string {
+	return backup.BackupId
+}
+
+func (backup *SDBInstanceBackup) GetName() string {
+	return backup.BackupId
+}
+
+func (backup *SDBInstanceBackup) GetGlobalId() string {
+	return backup.BackupId
+}
+
+func (backup *SDBInstanceBackup) GetStatus() string {
+	switch backup.BackupStatus {
+	case "Success":
+		return api.DBINSTANCE_BACKUP_STATUS_READY
+	case "Failed":
+		return api.DBINSTANCE_BACKUP_STATUS_FAILED
+	default:
+		return api.DBINSTANCE_BACKUP_STATUS_UNKNOWN
+	}
+}
+
+func (backup *SDBInstanceBackup) Refresh() error {
+	return nil
+}
+
+func (backup *SDBInstanceBackup) IsEmulated() bool {
+	return false
+}
+
+func (backup *SDBInstanceBackup) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (backup *SDBInstanceBackup) GetDBInstanceId() string {
+	return backup.DBInstanceId
+}
+
+func (backup *SDBInstanceBackup) GetStartTime() time.Time {
+	return backup.BackupStartTime
+}
+
+func (backup *SDBInstanceBackup) GetEndTime() time.Time {
+	return backup.BackupEndTime
+}
+
+func (backup *SDBInstanceBackup) GetBackupSizeMb() int {
+	return int(backup.BackupSize / 1024 / 1024)
+}
+
+func (backup *SDBInstanceBackup) GetBackupMode() string {
+	if backup.BackupMode == "Manual" {
+		return api.DBINSTANCE_BACKUP_MODE_MANUAL
+	}
+	return api.DBINSTANCE_BACKUP_MODE_AUTOMATED
+}
+
+func (region *SRegion) GetDBInstanceBackups(instanceId string, since time.Time) ([]SDBInstanceBackup, error) {
+	// 查询备份必须指定时间范围, 默认查询最近7天的备份
+	now := time.Now().UTC()
+	if since.IsZero() || now.Sub(since) > time.Hour*24*7 {
+		since = now.Add(-time.Hour * 24 * 7)
+	}
+	params := make(map[string]string)
+	params["RegionId"] = region.RegionId
+	params["DBInstanceId"] = instanceId
+	params["StartTime"] = since.UTC().Format("2006-01-02T15:04Z")
+	params["EndTime"] = now.Format("2006-01-02T15:04Z")
+	params["PageSize"] = "100"
+
+	backups := make([]SDBInstanceBackup, 0)
+	for pageNumber := 1; ; pageNumber++ {
+		params["PageNumber"] = fmt.Sprintf("%d", pageNumber)
+		body, err := region.rdsRequest("DescribeBackups", params)
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]SDBInstanceBackup, 0)
+		err = body.Unmarshal(&parts, "Items", "Backup")
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, parts...)
+		total, _ := body.Int("TotalRecordCount")
+		if len(backups) >= int(total) || len(parts) == 0 {
+			break
+		}
+	}
+	return backups, nil
+}
@@ -0,0 +1,195 @@
+package aliyun
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SForwardTableEntry struct {
+	gateway *SNatGetway
+
+	ForwardEntryId   string
+	ForwardEntryName string
+	ForwardTableId   string
+	ExternalIp       string
+	ExternalPort     string
+	InternalIp       string
+	InternalPort     string
+	IpProtocol       string
+	Status           string
+}
+
+func (entry *SForwardTableEntry) GetId() string {
+	return entry.ForwardEntryId
+}
+
+func (entry *SForwardTableEntry) GetName() string {
+	if len(entry.ForwardEntryName) > 0 {
+		return entry.ForwardEntryName
+	}
+	return entry.ForwardEntryId
+}
+
+func (entry *SForwardTableEntry) GetGlobalId() string {
+	return entry.ForwardEntryId
+}
+
+func (entry *SForwardTableEntry) GetStatus() string {
+	return convertNatEntryStatus(entry.Status)
+}
+
+func (entry *SForwardTableEntry) Refresh() error {
+	new, err := entry.gateway.vpc.region.getForwardEntry(entry.ForwardTableId, entry.ForwardEntryId)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(entry, new)
+}
+
+func (entry *SForwardTableEntry) IsEmulated() bool {
+	return false
+}
+
+func (entry *SForwardTableEntry) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (entry *SForwardTableEntry) GetIpProtocol() string {
+	return strings.ToLower(entry.IpProtocol)
+}
+
+func (entry *SForwardTableEntry) GetExternalIp() string {
+	return entry.ExternalIp
+}
+
+func (entry *SForwardTableEntry) GetExternalPort() int {
+	port, _ := strconv.Atoi(entry.ExternalPort)
+	return port
+}
+
+func (entry *SForwardTableEntry) GetInternalIp() string {
+	return entry.InternalIp
+}
+
+func (entry *SForwardTableEntry) GetInternalPort() int {
+	port, _ := strconv.Atoi(entry.InternalPort)
+	return port
+}
+
+func (entry *SForwardTableEntry) Delete() error {
+	return entry.gateway.vpc.region.DeleteForwardEntry(entry.ForwardTableId, entry.ForwardEntryId)
+}
+
+func (self *SRegion) GetForwardTableEntries(tableId string, entryId string, offset, limit int) ([]SForwardTableEntry, int, error) {
+	if limit > 50 || limit <= 0 {
+		limit = 50
+	}
+	params := make(map[string]string)
+	params["RegionId"] = self.RegionId
+	params["PageSize"] = fmt.Sprintf("%d", limit)
+	params["PageNumber"] = fmt.Sprintf("%d", (offset/limit)+1)
+	params["ForwardTableId"] = tableId
+	if len(entryId) > 0 {
+		params["ForwardEntryId"] = entryId
+	}
+
+	body, err := self.vpcRequest("DescribeForwardTableEntries", params)
+	if err != nil {
+		log.Errorf("DescribeForwardTableEntries fail %s", err)
+		return nil, 0, err
+	}
+
+	if self.client.Debug {
+		log.Debugf("%s", body.PrettyString())
+	}
+
+	entries := make([]SForwardTableEntry, 0)
+	err = body.Unmarshal(&entries, "ForwardTableEntries", "ForwardTableEntry")
+	if err != nil {
+		log.Errorf("Unmarshal entries fail %s", err)
+		return nil, 0, err
+	}
+	total, _ := body.Int("TotalCount")
+	return entries, int(total), nil
+}
+
+func (self *SRegion) getForwardEntry(tableId string, entryId string) (*SForwardTableEntry, error) {
+	entries, total, err := self.GetForwardTableEntries(tableId, entryId, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if total != 1 || len(entries) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &entries[0], nil
+}
+
+func (self *SRegion) createForwardEntry(tableId string, rule cloudprovider.SNatDRule) (string, error) {
+	params := make(map[string]string)
+	params["RegionId"] = self.RegionId
+	params["ForwardTableId"] = tableId
+	params["ExternalIp"] = rule.ExternalIP
+	params["InternalIp"] = rule.InternalIP
+	switch rule.Protocol {
+	case api.NAT_DNAT_PROTOCOL_TCP, api.NAT_DNAT_PROTOCOL_UDP:
+		params["IpProtocol"] = rule.Protocol
+		params["ExternalPort"] = fmt.Sprintf("%d", rule.ExternalPort)
+		params["InternalPort"] = fmt.Sprintf("%d", rule.InternalPort)
+	default:
+		params["IpProtocol"] = "Any"
+		params["ExternalPort"] = "Any"
+		params["InternalPort"] = "Any"
+	}
+	if len(rule.Name) > 0 {
+		params["ForwardEntryName"] = rule.Name
+	}
+	body, err := self.vpcRequest("CreateForwardEntry", params)
+	if err != nil {
+		return "", err
+	}
+	return body.GetString("ForwardEntryId")
+}
+
+func (self *SRegion) DeleteForwardEntry(tableId string, entryId string) error {
+	params := make(map[string]string)
+	params["RegionId"] = self.RegionId
+	params["ForwardTableId"] = tableId
+	params["ForwardEntryId"] = entryId
+	_, err := self.vpcRequest("DeleteForwardEntry", params)
+	return err
+}
+
+func (nat *SNatGetway) getForwardEntriesForTable(tableId string) ([]SForwardTableEntry, error) {
+	entries := make([]SForwardTableEntry, 0)
+	entryTotal := -1
+	for entryTotal < 0 || len(entries) < entryTotal {
+		parts, total, err := nat.vpc.region.GetForwardTableEntries(tableId, "", len(entries), 50)
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) > 0 {
+			entries = append(entries, parts...)
+		}
+		entryTotal = total
+	}
+	return entries, nil
+}
+
+func (nat *SNatGetway) getForwardEntries() ([]SForwardTableEntry, error) {
+	entries := make([]SForwardTableEntry, 0)
+	for i := range nat.ForwardTableIds.ForwardTableId {
+		dentries, err := nat.getForwardEntriesForTable(nat.ForwardTableIds.ForwardTableId[i])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, dentries...)
+	}
+	return entries, nil
+}
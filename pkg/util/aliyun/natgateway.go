@@ -2,9 +2,15 @@ package aliyun
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"yunion.io/x/jsonutils"
 	"yunion.io/x/log"
+	"yunion.io/x/pkg/utils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
 )
 
 type SBandwidthPackageIds struct {
@@ -28,7 +34,8 @@ type SNatGetway struct {
 	Description         string
 	ForwardTableIds     SForwardTableIds
 	SnatTableIds        SSnatTableIds
-	InstanceChargeType  string
+	InstanceChargeType  TChargeType
+	ExpiredTime         time.Time
 	Name                string
 	NatGatewayId        string
 	RegionId            string
@@ -37,6 +44,250 @@ type SNatGetway struct {
 	VpcId               string
 }
 
+func (gateway *SNatGetway) GetId() string {
+	return gateway.NatGatewayId
+}
+
+func (gateway *SNatGetway) GetName() string {
+	if len(gateway.Name) > 0 {
+		return gateway.Name
+	}
+	return gateway.NatGatewayId
+}
+
+func (gateway *SNatGetway) GetGlobalId() string {
+	return gateway.NatGatewayId
+}
+
+func (gateway *SNatGetway) GetStatus() string {
+	switch gateway.Status {
+	case "Initiating", "Pending":
+		return api.NAT_STATUS_ALLOCATE
+	case "Available":
+		return api.NAT_STATUS_AVAILABLE
+	case "Converting", "Modifying":
+		return api.NAT_STATUS_DEPLOYING
+	case "Deleting":
+		return api.NAT_STATUS_DELETING
+	default:
+		return api.NAT_STATUS_UNKNOWN
+	}
+}
+
+func (gateway *SNatGetway) Refresh() error {
+	gateways, total, err := gateway.vpc.region.GetNatGateways("", gateway.NatGatewayId, 0, 1)
+	if err != nil {
+		return err
+	}
+	if total != 1 {
+		return cloudprovider.ErrNotFound
+	}
+	return jsonutils.Update(gateway, gateways[0])
+}
+
+func (gateway *SNatGetway) IsEmulated() bool {
+	return false
+}
+
+func (gateway *SNatGetway) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (gateway *SNatGetway) GetBillingType() string {
+	return convertChargeType(gateway.InstanceChargeType)
+}
+
+func (gateway *SNatGetway) GetExpiredAt() time.Time {
+	return convertExpiredAt(gateway.ExpiredTime)
+}
+
+func (gateway *SNatGetway) GetNatSpec() string {
+	// 阿里云规格: Small, Middle, Large, XLarge.1
+	switch strings.ToLower(gateway.Spec) {
+	case "small":
+		return api.NAT_SPEC_SMALL
+	case "middle":
+		return api.NAT_SPEC_MIDDLE
+	case "large":
+		return api.NAT_SPEC_LARGE
+	case "xlarge.1":
+		return api.NAT_SPEC_XLARGE
+	}
+	return gateway.Spec
+}
+
+func (gateway *SNatGetway) GetINatSEntries() ([]cloudprovider.ICloudNatSEntry, error) {
+	entries, err := gateway.getSnatEntries()
+	if err != nil {
+		return nil, err
+	}
+	ientries := make([]cloudprovider.ICloudNatSEntry, len(entries))
+	for i := 0; i < len(entries); i++ {
+		entries[i].gateway = gateway
+		ientries[i] = &entries[i]
+	}
+	return ientries, nil
+}
+
+func (gateway *SNatGetway) GetINatDEntries() ([]cloudprovider.ICloudNatDEntry, error) {
+	entries, err := gateway.getForwardEntries()
+	if err != nil {
+		return nil, err
+	}
+	ientries := make([]cloudprovider.ICloudNatDEntry, len(entries))
+	for i := 0; i < len(entries); i++ {
+		entries[i].gateway = gateway
+		ientries[i] = &entries[i]
+	}
+	return ientries, nil
+}
+
+func (gateway *SNatGetway) GetINatSEntryById(id string) (cloudprovider.ICloudNatSEntry, error) {
+	for _, tableId := range gateway.SnatTableIds.SnatTableId {
+		entry, err := gateway.vpc.region.getSnatEntry(tableId, id)
+		if err == cloudprovider.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry.gateway = gateway
+		return entry, nil
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (gateway *SNatGetway) GetINatDEntryById(id string) (cloudprovider.ICloudNatDEntry, error) {
+	for _, tableId := range gateway.ForwardTableIds.ForwardTableId {
+		entry, err := gateway.vpc.region.getForwardEntry(tableId, id)
+		if err == cloudprovider.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry.gateway = gateway
+		return entry, nil
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (gateway *SNatGetway) CreateINatSEntry(rule cloudprovider.SNatSRule) (cloudprovider.ICloudNatSEntry, error) {
+	if len(gateway.SnatTableIds.SnatTableId) == 0 {
+		return nil, fmt.Errorf("no snat table found for nat gateway %s", gateway.NatGatewayId)
+	}
+	tableId := gateway.SnatTableIds.SnatTableId[0]
+	entryId, err := gateway.vpc.region.createSnatEntry(tableId, rule)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := gateway.vpc.region.getSnatEntry(tableId, entryId)
+	if err != nil {
+		return nil, err
+	}
+	entry.gateway = gateway
+	return entry, nil
+}
+
+func (gateway *SNatGetway) CreateINatDEntry(rule cloudprovider.SNatDRule) (cloudprovider.ICloudNatDEntry, error) {
+	if len(gateway.ForwardTableIds.ForwardTableId) == 0 {
+		return nil, fmt.Errorf("no forward table found for nat gateway %s", gateway.NatGatewayId)
+	}
+	tableId := gateway.ForwardTableIds.ForwardTableId[0]
+	entryId, err := gateway.vpc.region.createForwardEntry(tableId, rule)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := gateway.vpc.region.getForwardEntry(tableId, entryId)
+	if err != nil {
+		return nil, err
+	}
+	entry.gateway = gateway
+	return entry, nil
+}
+
+func (self *SVpc) CreateINatGateway(opts *cloudprovider.SNatGatewayCreateOptions) (cloudprovider.ICloudNatGateway, error) {
+	natGwId, err := self.region.CreateNatGateway(self.VpcId, opts)
+	if err != nil {
+		return nil, err
+	}
+	gateways, total, err := self.region.GetNatGateways("", natGwId, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if total != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	gateway := &gateways[0]
+	gateway.vpc = self
+	if len(opts.EipId) > 0 {
+		// 网关可用后才能绑定EIP
+		err = cloudprovider.WaitStatus(gateway, api.NAT_STATUS_AVAILABLE, 5*time.Second, 300*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		err = self.region.AssociateEipWithNatGateway(opts.EipId, natGwId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return gateway, nil
+}
+
+func (self *SRegion) CreateNatGateway(vpcId string, opts *cloudprovider.SNatGatewayCreateOptions) (string, error) {
+	params := make(map[string]string)
+	params["RegionId"] = self.RegionId
+	params["VpcId"] = vpcId
+	params["Name"] = opts.Name
+	if len(opts.Desc) > 0 {
+		params["Description"] = opts.Desc
+	}
+	if len(opts.NetworkId) > 0 {
+		params["VSwitchId"] = opts.NetworkId
+	}
+	switch opts.NatSpec {
+	case api.NAT_SPEC_MIDDLE:
+		params["Spec"] = "Middle"
+	case api.NAT_SPEC_LARGE:
+		params["Spec"] = "Large"
+	case api.NAT_SPEC_XLARGE:
+		params["Spec"] = "XLarge.1"
+	default:
+		params["Spec"] = "Small"
+	}
+	params["ClientToken"] = utils.GenRequestId(20)
+	body, err := self.vpcRequest("CreateNatGateway", params)
+	if err != nil {
+		return "", err
+	}
+	return body.GetString("NatGatewayId")
+}
+
+func (self *SRegion) AssociateEipWithNatGateway(eipId string, natGwId string) error {
+	params := make(map[string]string)
+	params["RegionId"] = self.RegionId
+	params["AllocationId"] = eipId
+	params["InstanceId"] = natGwId
+	params["InstanceType"] = "Nat"
+	_, err := self.vpcRequest("AssociateEipAddress", params)
+	return err
+}
+
+func (gateway *SNatGetway) Delete() error {
+	return gateway.vpc.region.DeleteNatGateway(gateway.NatGatewayId, false)
+}
+
+func (self *SRegion) DeleteNatGateway(natGwId string, force bool) error {
+	params := make(map[string]string)
+	params["RegionId"] = self.RegionId
+	params["NatGatewayId"] = natGwId
+	if force {
+		params["Force"] = "true"
+	}
+	_, err := self.vpcRequest("DeleteNatGateway", params)
+	return err
+}
+
 func (self *SRegion) GetNatGateways(vpcId string, natGwId string, offset, limit int) ([]SNatGetway, int, error) {
 	if limit > 50 || limit <= 0 {
 		limit = 50
@@ -73,7 +324,10 @@ func (self *SRegion) GetNatGateways(vpcId string, natGwId string, offset, limit
 }
 
 type SSNATTableEntry struct {
+	gateway *SNatGetway
+
 	SnatEntryId     string
+	SnatEntryName   string
 	SnatIp          string
 	SnatTableId     string `json:"snat_table_id"`
 	SourceCIDR      string `json:"source_cidr"`
@@ -82,6 +336,10 @@ type SSNATTableEntry struct {
 }
 
 func (self *SRegion) GetSNATEntries(tableId string, offset, limit int) ([]SSNATTableEntry, int, error) {
+	return self.getSNATEntries(tableId, "", offset, limit)
+}
+
+func (self *SRegion) getSNATEntries(tableId string, entryId string, offset, limit int) ([]SSNATTableEntry, int, error) {
 	if limit > 50 || limit <= 0 {
 		limit = 50
 	}
@@ -90,6 +348,9 @@ func (self *SRegion) GetSNATEntries(tableId string, offset, limit int) ([]SSNATT
 	params["PageSize"] = fmt.Sprintf("%d", limit)
 	params["PageNumber"] = fmt.Sprintf("%d", (offset/limit)+1)
 	params["SnatTableId"] = tableId
+	if len(entryId) > 0 {
+		params["SnatEntryId"] = entryId
+	}
 
 	body, err := self.vpcRequest("DescribeSnatTableEntries", params)
 	if err != nil {
@@ -154,7 +415,7 @@ func (nat *SNatGetway) dissociateWithVswitch(vswitchId string) error {
 		return err
 	}
 	for i := range entries {
-		log.Debugf("%s", entries[i])
+		log.Debugf("snat entry %s source vswitch %s", entries[i].SnatEntryId, entries[i].SourceVSwitchId)
 		if entries[i].SourceVSwitchId == vswitchId {
 			err := nat.vpc.region.DeleteSnatEntry(entries[i].SnatTableId, entries[i].SnatEntryId)
 			if err != nil {
@@ -0,0 +1,104 @@
+package aliyun
+
+import (
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+func (entry *SSNATTableEntry) GetId() string {
+	return entry.SnatEntryId
+}
+
+func (entry *SSNATTableEntry) GetName() string {
+	if len(entry.SnatEntryName) > 0 {
+		return entry.SnatEntryName
+	}
+	return entry.SnatEntryId
+}
+
+func (entry *SSNATTableEntry) GetGlobalId() string {
+	return entry.SnatEntryId
+}
+
+func (entry *SSNATTableEntry) GetStatus() string {
+	return convertNatEntryStatus(entry.Status)
+}
+
+func (entry *SSNATTableEntry) Refresh() error {
+	new, err := entry.gateway.vpc.region.getSnatEntry(entry.SnatTableId, entry.SnatEntryId)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(entry, new)
+}
+
+func (entry *SSNATTableEntry) IsEmulated() bool {
+	return false
+}
+
+func (entry *SSNATTableEntry) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (entry *SSNATTableEntry) GetIP() string {
+	return entry.SnatIp
+}
+
+func (entry *SSNATTableEntry) GetSourceCIDR() string {
+	return entry.SourceCIDR
+}
+
+func (entry *SSNATTableEntry) GetNetworkId() string {
+	return entry.SourceVSwitchId
+}
+
+func (entry *SSNATTableEntry) Delete() error {
+	return entry.gateway.vpc.region.DeleteSnatEntry(entry.SnatTableId, entry.SnatEntryId)
+}
+
+func (self *SRegion) getSnatEntry(tableId string, entryId string) (*SSNATTableEntry, error) {
+	entries, total, err := self.getSNATEntries(tableId, entryId, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if total != 1 || len(entries) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &entries[0], nil
+}
+
+func (self *SRegion) createSnatEntry(tableId string, rule cloudprovider.SNatSRule) (string, error) {
+	params := make(map[string]string)
+	params["RegionId"] = self.RegionId
+	params["SnatTableId"] = tableId
+	params["SnatIp"] = rule.ExternalIP
+	if len(rule.NetworkID) > 0 {
+		params["SourceVSwitchId"] = rule.NetworkID
+	} else {
+		params["SourceCIDR"] = rule.SourceCIDR
+	}
+	if len(rule.Name) > 0 {
+		params["SnatEntryName"] = rule.Name
+	}
+	body, err := self.vpcRequest("CreateSnatEntry", params)
+	if err != nil {
+		return "", err
+	}
+	return body.GetString("SnatEntryId")
+}
+
+// 阿里云SNAT和DNAT条目状态: Pending, Available, Deleting
+func convertNatEntryStatus(status string) string {
+	switch status {
+	case "Pending":
+		return api.NAT_STATUS_ALLOCATE
+	case "Available":
+		return api.NAT_STATUS_AVAILABLE
+	case "Deleting":
+		return api.NAT_STATUS_DELETING
+	default:
+		return api.NAT_STATUS_UNKNOWN
+	}
+}
@@ -102,6 +102,14 @@ func (self *SRegion) vpcRequest(action string, params map[string]string) (jsonut
 	return jsonRequest(client, "vpc.aliyuncs.com", ALIYUN_API_VERSION_VPC, action, params, self.Debug)
 }
 
+func (self *SRegion) rdsRequest(action string, params map[string]string) (jsonutils.JSONObject, error) {
+	client, err := self.getSdkClient()
+	if err != nil {
+		return nil, err
+	}
+	return jsonRequest(client, "rds.aliyuncs.com", ALIYUN_API_VERSION_RDS, action, params, self.client.Debug)
+}
+
 type LBRegion struct {
 	RegionEndpoint string
 	RegionId       string
@@ -8,6 +8,7 @@ import (
 	"yunion.io/x/jsonutils"
 	"yunion.io/x/log"
 
+	api "yunion.io/x/onecloud/pkg/apis/compute"
 	"yunion.io/x/onecloud/pkg/cloudprovider"
 )
 
@@ -138,6 +139,39 @@ func (self *SRouteTable) Refresh() error {
 	return nil
 }
 
+func (self *SRouteTable) CreateRoute(route cloudprovider.SRouteSet) error {
+	nextHopType, err := aliyunNextHopType(route.NextHopType)
+	if err != nil {
+		return err
+	}
+	err = self.region.CreateRouteEntry(self.RouteTableId, route.Destination, nextHopType, route.NextHop)
+	if err != nil {
+		return err
+	}
+	self.routes = nil
+	return nil
+}
+
+func (self *SRouteTable) RemoveRoute(route cloudprovider.SRouteSet) error {
+	err := self.region.DeleteRouteEntry(self.RouteTableId, route.Destination, route.NextHop)
+	if err != nil {
+		return err
+	}
+	self.routes = nil
+	return nil
+}
+
+// 阿里云自定义路由条目支持的下一跳类型
+func aliyunNextHopType(nextHopType string) (string, error) {
+	switch nextHopType {
+	case api.ROUTE_NEXT_HOP_TYPE_INSTANCE, api.ROUTE_NEXT_HOP_TYPE_NETWORK_INTERFACE,
+		api.ROUTE_NEXT_HOP_TYPE_VPN_GATEWAY, api.ROUTE_NEXT_HOP_TYPE_NAT_GATEWAY,
+		"HaVip", "RouterInterface":
+		return nextHopType, nil
+	}
+	return "", fmt.Errorf("unsupported next hop type %s", nextHopType)
+}
+
 func (self *SRouteTable) fetchRoutes() error {
 	routes := make([]*SRouteEntry, 0)
 	for {
@@ -224,6 +258,29 @@ func (region *SRegion) AssociateRouteTable(rtableId string, vswitchId string) er
 	return err
 }
 
+func (region *SRegion) CreateRouteEntry(rtableId string, cidr string, nextHopType string, nextHopId string) error {
+	params := make(map[string]string)
+	params["RegionId"] = region.RegionId
+	params["RouteTableId"] = rtableId
+	params["DestinationCidrBlock"] = cidr
+	params["NextHopType"] = nextHopType
+	params["NextHopId"] = nextHopId
+	_, err := region.vpcRequest("CreateRouteEntry", params)
+	return err
+}
+
+func (region *SRegion) DeleteRouteEntry(rtableId string, cidr string, nextHopId string) error {
+	params := make(map[string]string)
+	params["RegionId"] = region.RegionId
+	params["RouteTableId"] = rtableId
+	params["DestinationCidrBlock"] = cidr
+	if len(nextHopId) > 0 {
+		params["NextHopId"] = nextHopId
+	}
+	_, err := region.vpcRequest("DeleteRouteEntry", params)
+	return err
+}
+
 func (region *SRegion) UnassociateRouteTable(rtableId string, vswitchId string) error {
 	params := make(map[string]string)
 	params["RegionId"] = region.RegionId
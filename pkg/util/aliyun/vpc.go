@@ -214,6 +214,30 @@ func (self *SVpc) GetIRouteTables() ([]cloudprovider.ICloudRouteTable, error) {
 	return self.routeTables, nil
 }
 
+func (self *SVpc) GetINatGateways() ([]cloudprovider.ICloudNatGateway, error) {
+	natgatways, err := self.getNatGateways()
+	if err != nil {
+		return nil, err
+	}
+	inatgateways := make([]cloudprovider.ICloudNatGateway, len(natgatways))
+	for i := 0; i < len(natgatways); i++ {
+		inatgateways[i] = &natgatways[i]
+	}
+	return inatgateways, nil
+}
+
+func (self *SVpc) GetIVpcPeerings() ([]cloudprovider.ICloudVpcPeering, error) {
+	return []cloudprovider.ICloudVpcPeering{}, nil
+}
+
+func (self *SVpc) GetIVpcPeeringById(id string) (cloudprovider.ICloudVpcPeering, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SVpc) CreateIVpcPeering(opts *cloudprovider.SVpcPeeringCreateOptions) (cloudprovider.ICloudVpcPeering, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
 func (self *SVpc) GetManagerId() string {
 	return self.region.client.providerId
 }
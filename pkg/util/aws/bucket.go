@@ -0,0 +1,286 @@
+package aws
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+const (
+	S3_GROUP_ALL_USERS  = "http://acs.amazonaws.com/groups/global/AllUsers"
+	S3_GROUP_AUTH_USERS = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+type SBucket struct {
+	region *SRegion
+
+	Name         string
+	CreationDate time.Time
+	Location     string
+
+	acl string
+}
+
+func (b *SBucket) GetId() string {
+	return b.Name
+}
+
+func (b *SBucket) GetName() string {
+	return b.Name
+}
+
+func (b *SBucket) GetGlobalId() string {
+	return b.Name
+}
+
+func (b *SBucket) GetStatus() string {
+	return api.BUCKET_STATUS_READY
+}
+
+func (b *SBucket) Refresh() error {
+	b.acl = ""
+	return nil
+}
+
+func (b *SBucket) IsEmulated() bool {
+	return false
+}
+
+func (b *SBucket) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (b *SBucket) GetLocation() string {
+	return b.Location
+}
+
+// S3没有bucket级别的存储类型
+func (b *SBucket) GetStorageClass() string {
+	return ""
+}
+
+func (b *SBucket) GetCreateAt() time.Time {
+	return b.CreationDate
+}
+
+// 根据授权列表推算canned ACL
+func (b *SBucket) GetAcl() cloudprovider.TBucketACLType {
+	if len(b.acl) == 0 {
+		s3cli, err := b.region.getS3Client()
+		if err != nil {
+			log.Errorf("b.region.getS3Client fail %s", err)
+			return cloudprovider.ACLDefault
+		}
+		output, err := s3cli.GetBucketAcl(&s3.GetBucketAclInput{Bucket: aws.String(b.Name)})
+		if err != nil {
+			log.Errorf("GetBucketAcl %s fail %s", b.Name, err)
+			return cloudprovider.ACLDefault
+		}
+		acl := cloudprovider.ACLPrivate
+		allRead, allWrite, authRead := false, false, false
+		for _, grant := range output.Grants {
+			if grant.Grantee == nil || grant.Grantee.URI == nil || grant.Permission == nil {
+				continue
+			}
+			switch *grant.Grantee.URI {
+			case S3_GROUP_ALL_USERS:
+				switch *grant.Permission {
+				case s3.PermissionRead:
+					allRead = true
+				case s3.PermissionWrite:
+					allWrite = true
+				}
+			case S3_GROUP_AUTH_USERS:
+				if *grant.Permission == s3.PermissionRead {
+					authRead = true
+				}
+			}
+		}
+		if allRead && allWrite {
+			acl = cloudprovider.ACLPublicReadWrite
+		} else if allRead {
+			acl = cloudprovider.ACLPublicRead
+		} else if authRead {
+			acl = cloudprovider.ACLAuthRead
+		}
+		b.acl = string(acl)
+	}
+	return cloudprovider.TBucketACLType(b.acl)
+}
+
+func (b *SBucket) SetAcl(acl cloudprovider.TBucketACLType) error {
+	s3cli, err := b.region.getS3Client()
+	if err != nil {
+		return err
+	}
+	input := &s3.PutBucketAclInput{}
+	input.SetBucket(b.Name)
+	input.SetACL(string(acl))
+	_, err = s3cli.PutBucketAcl(input)
+	if err != nil {
+		return err
+	}
+	b.acl = string(acl)
+	return nil
+}
+
+func (b *SBucket) ListObjects(prefix string, marker string, delimiter string, maxCount int) (cloudprovider.SListObjectResult, error) {
+	result := cloudprovider.SListObjectResult{}
+	s3cli, err := b.region.getS3Client()
+	if err != nil {
+		return result, err
+	}
+	input := &s3.ListObjectsInput{}
+	input.SetBucket(b.Name)
+	if len(prefix) > 0 {
+		input.SetPrefix(prefix)
+	}
+	if len(marker) > 0 {
+		input.SetMarker(marker)
+	}
+	if len(delimiter) > 0 {
+		input.SetDelimiter(delimiter)
+	}
+	if maxCount > 0 {
+		input.SetMaxKeys(int64(maxCount))
+	}
+	output, err := s3cli.ListObjects(input)
+	if err != nil {
+		return result, err
+	}
+	result.Objects = make([]cloudprovider.SCloudObject, 0)
+	for _, object := range output.Contents {
+		obj := cloudprovider.SCloudObject{
+			Key:          aws.StringValue(object.Key),
+			SizeBytes:    aws.Int64Value(object.Size),
+			StorageClass: aws.StringValue(object.StorageClass),
+			ETag:         aws.StringValue(object.ETag),
+			LastModified: aws.TimeValue(object.LastModified),
+		}
+		result.Objects = append(result.Objects, obj)
+	}
+	result.CommonPrefixes = make([]string, 0)
+	for _, commonPrefix := range output.CommonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, aws.StringValue(commonPrefix.Prefix))
+	}
+	result.IsTruncated = aws.BoolValue(output.IsTruncated)
+	result.NextMarker = aws.StringValue(output.NextMarker)
+	if result.IsTruncated && len(result.NextMarker) == 0 && len(result.Objects) > 0 {
+		// 未指定delimiter时不返回NextMarker, 使用最后一个key
+		result.NextMarker = result.Objects[len(result.Objects)-1].Key
+	}
+	return result, nil
+}
+
+// LocationConstraint为空表示us-east-1, EU表示eu-west-1
+func s3LocationToRegionId(location string) string {
+	switch location {
+	case "":
+		return "us-east-1"
+	case s3.BucketLocationConstraintEu:
+		return "eu-west-1"
+	default:
+		return location
+	}
+}
+
+// S3的ListBuckets返回账号下所有区域的bucket, 需逐个查询所在区域
+func (self *SRegion) GetBuckets() ([]SBucket, error) {
+	s3cli, err := self.getS3Client()
+	if err != nil {
+		return nil, err
+	}
+	output, err := s3cli.ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		return nil, err
+	}
+	buckets := make([]SBucket, 0)
+	for _, bucket := range output.Buckets {
+		name := aws.StringValue(bucket.Name)
+		location, err := s3cli.GetBucketLocation(&s3.GetBucketLocationInput{Bucket: aws.String(name)})
+		if err != nil {
+			log.Errorf("GetBucketLocation %s fail %s", name, err)
+			continue
+		}
+		regionId := s3LocationToRegionId(aws.StringValue(location.LocationConstraint))
+		if regionId != self.RegionId {
+			continue
+		}
+		buckets = append(buckets, SBucket{
+			region:       self,
+			Name:         name,
+			CreationDate: aws.TimeValue(bucket.CreationDate),
+			Location:     regionId,
+		})
+	}
+	return buckets, nil
+}
+
+func (self *SRegion) GetIBuckets() ([]cloudprovider.ICloudBucket, error) {
+	buckets, err := self.GetBuckets()
+	if err != nil {
+		return nil, err
+	}
+	ibuckets := make([]cloudprovider.ICloudBucket, len(buckets))
+	for i := 0; i < len(buckets); i++ {
+		ibuckets[i] = &buckets[i]
+	}
+	return ibuckets, nil
+}
+
+func (self *SRegion) GetIBucketById(name string) (cloudprovider.ICloudBucket, error) {
+	buckets, err := self.GetBuckets()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(buckets); i++ {
+		if buckets[i].Name == name {
+			return &buckets[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) CreateIBucket(name string, storageClassStr string, acl string) error {
+	s3cli, err := self.getS3Client()
+	if err != nil {
+		return err
+	}
+	input := &s3.CreateBucketInput{}
+	input.SetBucket(name)
+	// us-east-1不能指定LocationConstraint
+	if self.RegionId != "us-east-1" {
+		location := &s3.CreateBucketConfiguration{}
+		location.SetLocationConstraint(self.RegionId)
+		input.SetCreateBucketConfiguration(location)
+	}
+	if len(acl) > 0 {
+		input.SetACL(acl)
+	}
+	_, err = s3cli.CreateBucket(input)
+	return err
+}
+
+func (self *SRegion) DeleteIBucket(name string) error {
+	s3cli, err := self.getS3Client()
+	if err != nil {
+		return err
+	}
+	_, err = s3cli.DeleteBucket(&s3.DeleteBucketInput{Bucket: aws.String(name)})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && strings.Contains(aerr.Code(), "NoSuchBucket") {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,459 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/aws/aws-sdk-go/aws"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+type SRdsEndpoint struct {
+	Address      *string
+	Port         *int64
+	HostedZoneId *string
+}
+
+type SRdsAvailabilityZone struct {
+	Name *string
+}
+
+type SRdsSubnet struct {
+	SubnetIdentifier       *string
+	SubnetAvailabilityZone *SRdsAvailabilityZone
+	SubnetStatus           *string
+}
+
+type SRdsSubnetGroup struct {
+	DBSubnetGroupName *string
+	VpcId             *string
+	Subnets           []*SRdsSubnet `locationNameList:"Subnet"`
+}
+
+// https://docs.aws.amazon.com/AmazonRDS/latest/APIReference/API_DBInstance.html
+type SDBInstance struct {
+	region *SRegion
+
+	DBInstanceIdentifier  *string
+	DBInstanceArn         *string
+	DBInstanceClass       *string
+	DBInstanceStatus      *string
+	Engine                *string
+	EngineVersion         *string
+	Endpoint              *SRdsEndpoint
+	AllocatedStorage      *int64
+	StorageType           *string
+	InstanceCreateTime    *time.Time
+	AvailabilityZone      *string
+	MultiAZ               *bool
+	PubliclyAccessible    *bool
+	DBSubnetGroup         *SRdsSubnetGroup
+	BackupRetentionPeriod *int64
+}
+
+func (self *SDBInstance) GetId() string {
+	return sdk.StringValue(self.DBInstanceIdentifier)
+}
+
+func (self *SDBInstance) GetName() string {
+	return sdk.StringValue(self.DBInstanceIdentifier)
+}
+
+func (self *SDBInstance) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SDBInstance) GetStatus() string {
+	switch sdk.StringValue(self.DBInstanceStatus) {
+	case "creating":
+		return api.DBINSTANCE_STATUS_DEPLOYING
+	case "available", "backing-up", "storage-full", "configuring-enhanced-monitoring":
+		return api.DBINSTANCE_STATUS_RUNNING
+	case "stopped":
+		return api.DBINSTANCE_STATUS_READY
+	case "starting":
+		return api.DBINSTANCE_STATUS_STARTING
+	case "stopping":
+		return api.DBINSTANCE_STATUS_STOPPING
+	case "rebooting":
+		return api.DBINSTANCE_STATUS_REBOOTING
+	case "deleting":
+		return api.DBINSTANCE_STATUS_DELETING
+	case "modifying", "maintenance", "upgrading", "renaming", "resetting-master-credentials", "storage-optimization":
+		return api.DBINSTANCE_STATUS_MAINTENANCE
+	default:
+		return api.DBINSTANCE_STATUS_UNKNOWN
+	}
+}
+
+func (self *SDBInstance) Refresh() error {
+	instance, err := self.region.GetDBInstance(self.GetId())
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, instance)
+}
+
+func (self *SDBInstance) IsEmulated() bool {
+	return false
+}
+
+func (self *SDBInstance) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SDBInstance) GetBillingType() string {
+	return models.BILLING_TYPE_POSTPAID
+}
+
+func (self *SDBInstance) GetExpiredAt() time.Time {
+	return time.Time{}
+}
+
+func (self *SDBInstance) GetEngine() string {
+	engine := sdk.StringValue(self.Engine)
+	switch {
+	case engine == "mysql" || engine == "aurora" || engine == "aurora-mysql":
+		return api.DBINSTANCE_ENGINE_MYSQL
+	case engine == "mariadb":
+		return api.DBINSTANCE_ENGINE_MARIADB
+	case engine == "postgres" || engine == "aurora-postgresql":
+		return api.DBINSTANCE_ENGINE_POSTGRESQL
+	case strings.HasPrefix(engine, "sqlserver"):
+		return api.DBINSTANCE_ENGINE_SQLSERVER
+	case strings.HasPrefix(engine, "oracle"):
+		return api.DBINSTANCE_ENGINE_ORACLE
+	}
+	return engine
+}
+
+func (self *SDBInstance) GetEngineVersion() string {
+	return sdk.StringValue(self.EngineVersion)
+}
+
+func (self *SDBInstance) GetInstanceType() string {
+	return sdk.StringValue(self.DBInstanceClass)
+}
+
+// RDS实例规格与EC2实例规格一一对应, 如 db.t2.micro 对应 t2.micro
+func (self *SDBInstance) getInstanceType() *SInstanceType {
+	instanceType, err := self.region.GetInstanceType(strings.TrimPrefix(self.GetInstanceType(), "db."))
+	if err != nil {
+		log.Debugf("failed to find instance type for dbinstance %s: %v", self.GetId(), err)
+		return nil
+	}
+	return instanceType
+}
+
+func (self *SDBInstance) GetVcpuCount() int {
+	if instanceType := self.getInstanceType(); instanceType != nil {
+		return instanceType.Cpu.Cores
+	}
+	return 0
+}
+
+func (self *SDBInstance) GetVmemSizeMB() int {
+	if instanceType := self.getInstanceType(); instanceType != nil {
+		return instanceType.memoryMB()
+	}
+	return 0
+}
+
+func (self *SDBInstance) GetDiskSizeGB() int {
+	return int(sdk.Int64Value(self.AllocatedStorage))
+}
+
+func (self *SDBInstance) GetCategory() string {
+	if sdk.BoolValue(self.MultiAZ) {
+		return "multi-az"
+	}
+	return "single-az"
+}
+
+func (self *SDBInstance) GetStorageType() string {
+	return sdk.StringValue(self.StorageType)
+}
+
+func (self *SDBInstance) GetCreateTime() time.Time {
+	return sdk.TimeValue(self.InstanceCreateTime)
+}
+
+func (self *SDBInstance) GetPort() int {
+	if self.Endpoint != nil {
+		return int(sdk.Int64Value(self.Endpoint.Port))
+	}
+	return 0
+}
+
+func (self *SDBInstance) getEndpoint() string {
+	if self.Endpoint == nil || len(sdk.StringValue(self.Endpoint.Address)) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", sdk.StringValue(self.Endpoint.Address), sdk.Int64Value(self.Endpoint.Port))
+}
+
+// AWS RDS只有一个访问地址, 公网可访问时该地址在VPC内解析为内网IP
+func (self *SDBInstance) GetConnectionStr() string {
+	if sdk.BoolValue(self.PubliclyAccessible) {
+		return self.getEndpoint()
+	}
+	return ""
+}
+
+func (self *SDBInstance) GetInternalConnectionStr() string {
+	return self.getEndpoint()
+}
+
+func (self *SDBInstance) GetVpcId() string {
+	if self.DBSubnetGroup != nil {
+		return sdk.StringValue(self.DBSubnetGroup.VpcId)
+	}
+	return ""
+}
+
+// 子网组包含多个子网, 这里返回实例所在可用区的子网
+func (self *SDBInstance) GetNetworkId() string {
+	if self.DBSubnetGroup == nil {
+		return ""
+	}
+	zoneName := sdk.StringValue(self.AvailabilityZone)
+	for _, subnet := range self.DBSubnetGroup.Subnets {
+		if subnet.SubnetAvailabilityZone != nil && sdk.StringValue(subnet.SubnetAvailabilityZone.Name) == zoneName {
+			return sdk.StringValue(subnet.SubnetIdentifier)
+		}
+	}
+	return ""
+}
+
+func (self *SDBInstance) GetZoneId() string {
+	if zoneName := sdk.StringValue(self.AvailabilityZone); len(zoneName) > 0 {
+		return fmt.Sprintf("%s/%s", self.region.GetGlobalId(), zoneName)
+	}
+	return ""
+}
+
+func (self *SDBInstance) GetIDBInstanceBackups() ([]cloudprovider.ICloudDBInstanceBackup, error) {
+	snapshots, err := self.region.GetDBSnapshots(self.GetId())
+	if err != nil {
+		return nil, err
+	}
+	ibackups := make([]cloudprovider.ICloudDBInstanceBackup, len(snapshots))
+	for i := 0; i < len(snapshots); i++ {
+		ibackups[i] = &snapshots[i]
+	}
+	return ibackups, nil
+}
+
+func (self *SDBInstance) Start() error {
+	return self.region.rdsRequest("StartDBInstance", &rdsDBInstanceActionInput{DBInstanceIdentifier: self.DBInstanceIdentifier}, nil)
+}
+
+func (self *SDBInstance) Stop() error {
+	return self.region.rdsRequest("StopDBInstance", &rdsDBInstanceActionInput{DBInstanceIdentifier: self.DBInstanceIdentifier}, nil)
+}
+
+func (self *SDBInstance) Reboot() error {
+	return self.region.rdsRequest("RebootDBInstance", &rdsDBInstanceActionInput{DBInstanceIdentifier: self.DBInstanceIdentifier}, nil)
+}
+
+func (self *SDBInstance) Delete() error {
+	params := &rdsDeleteDBInstanceInput{
+		DBInstanceIdentifier: self.DBInstanceIdentifier,
+		SkipFinalSnapshot:    sdk.Bool(true),
+	}
+	return self.region.rdsRequest("DeleteDBInstance", params, nil)
+}
+
+type rdsDescribeDBInstancesInput struct {
+	DBInstanceIdentifier *string
+	Marker               *string
+	MaxRecords           *int64
+}
+
+type rdsDescribeDBInstancesOutput struct {
+	DBInstances []*SDBInstance `locationNameList:"DBInstance"`
+	Marker      *string
+}
+
+type rdsDBInstanceActionInput struct {
+	DBInstanceIdentifier *string
+}
+
+type rdsDeleteDBInstanceInput struct {
+	DBInstanceIdentifier *string
+	SkipFinalSnapshot    *bool
+}
+
+func (self *SRegion) GetDBInstances(instanceId string) ([]SDBInstance, error) {
+	params := &rdsDescribeDBInstancesInput{MaxRecords: sdk.Int64(100)}
+	if len(instanceId) > 0 {
+		params.DBInstanceIdentifier = sdk.String(instanceId)
+	}
+	instances := []SDBInstance{}
+	for {
+		ret := &rdsDescribeDBInstancesOutput{}
+		err := self.rdsRequest("DescribeDBInstances", params, ret)
+		if err != nil {
+			if isAwsErrorCode(err, "DBInstanceNotFound") {
+				return nil, cloudprovider.ErrNotFound
+			}
+			return nil, err
+		}
+		for _, instance := range ret.DBInstances {
+			instance.region = self
+			instances = append(instances, *instance)
+		}
+		if len(sdk.StringValue(ret.Marker)) == 0 {
+			break
+		}
+		params.Marker = ret.Marker
+	}
+	return instances, nil
+}
+
+func (self *SRegion) GetDBInstance(instanceId string) (*SDBInstance, error) {
+	instances, err := self.GetDBInstances(instanceId)
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &instances[0], nil
+}
+
+func (self *SRegion) GetIDBInstances() ([]cloudprovider.ICloudDBInstance, error) {
+	instances, err := self.GetDBInstances("")
+	if err != nil {
+		return nil, err
+	}
+	iinstances := make([]cloudprovider.ICloudDBInstance, len(instances))
+	for i := 0; i < len(instances); i++ {
+		iinstances[i] = &instances[i]
+	}
+	return iinstances, nil
+}
+
+func (self *SRegion) GetIDBInstanceById(instanceId string) (cloudprovider.ICloudDBInstance, error) {
+	instance, err := self.GetDBInstance(instanceId)
+	if err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// https://docs.aws.amazon.com/AmazonRDS/latest/APIReference/API_DBSnapshot.html
+type SDBSnapshot struct {
+	DBSnapshotIdentifier *string
+	DBSnapshotArn        *string
+	DBInstanceIdentifier *string
+	SnapshotCreateTime   *time.Time
+	AllocatedStorage     *int64
+	// automated, manual
+	SnapshotType *string
+	// creating, available, failed ...
+	Status *string
+}
+
+func (self *SDBSnapshot) GetId() string {
+	return sdk.StringValue(self.DBSnapshotIdentifier)
+}
+
+func (self *SDBSnapshot) GetName() string {
+	return sdk.StringValue(self.DBSnapshotIdentifier)
+}
+
+func (self *SDBSnapshot) GetGlobalId() string {
+	return sdk.StringValue(self.DBSnapshotArn)
+}
+
+func (self *SDBSnapshot) GetStatus() string {
+	switch sdk.StringValue(self.Status) {
+	case "available":
+		return api.DBINSTANCE_BACKUP_STATUS_READY
+	case "creating":
+		return api.DBINSTANCE_BACKUP_STATUS_CREATING
+	case "failed":
+		return api.DBINSTANCE_BACKUP_STATUS_FAILED
+	default:
+		return api.DBINSTANCE_BACKUP_STATUS_UNKNOWN
+	}
+}
+
+func (self *SDBSnapshot) Refresh() error {
+	return nil
+}
+
+func (self *SDBSnapshot) IsEmulated() bool {
+	return false
+}
+
+func (self *SDBSnapshot) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SDBSnapshot) GetDBInstanceId() string {
+	return sdk.StringValue(self.DBInstanceIdentifier)
+}
+
+func (self *SDBSnapshot) GetStartTime() time.Time {
+	return sdk.TimeValue(self.SnapshotCreateTime)
+}
+
+// 快照只记录了创建时间
+func (self *SDBSnapshot) GetEndTime() time.Time {
+	return sdk.TimeValue(self.SnapshotCreateTime)
+}
+
+// 快照大小未知, 以实例分配的存储大小代替
+func (self *SDBSnapshot) GetBackupSizeMb() int {
+	return int(sdk.Int64Value(self.AllocatedStorage) * 1024)
+}
+
+func (self *SDBSnapshot) GetBackupMode() string {
+	if sdk.StringValue(self.SnapshotType) == "manual" {
+		return api.DBINSTANCE_BACKUP_MODE_MANUAL
+	}
+	return api.DBINSTANCE_BACKUP_MODE_AUTOMATED
+}
+
+type rdsDescribeDBSnapshotsInput struct {
+	DBInstanceIdentifier *string
+	Marker               *string
+	MaxRecords           *int64
+}
+
+type rdsDescribeDBSnapshotsOutput struct {
+	DBSnapshots []*SDBSnapshot `locationNameList:"DBSnapshot"`
+	Marker      *string
+}
+
+func (self *SRegion) GetDBSnapshots(instanceId string) ([]SDBSnapshot, error) {
+	params := &rdsDescribeDBSnapshotsInput{
+		DBInstanceIdentifier: sdk.String(instanceId),
+		MaxRecords:           sdk.Int64(100),
+	}
+	snapshots := []SDBSnapshot{}
+	for {
+		ret := &rdsDescribeDBSnapshotsOutput{}
+		err := self.rdsRequest("DescribeDBSnapshots", params, ret)
+		if err != nil {
+			return nil, err
+		}
+		for _, snapshot := range ret.DBSnapshots {
+			snapshots = append(snapshots, *snapshot)
+		}
+		if len(sdk.StringValue(ret.Marker)) == 0 {
+			break
+		}
+		params.Marker = ret.Marker
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,175 @@
+package aws
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/private/protocol/query"
+)
+
+// elasticloadbalancing 与 acm 的 sdk 未被 vendor, 这里基于 sdk 的 query / json 协议直接调用接口
+const (
+	ELB_SERVICE_NAME = "elasticloadbalancing"
+	ACM_SERVICE_NAME = "acm"
+
+	ELBV2_API_VERSION = "2015-12-01"
+	ELB_API_VERSION   = "2012-06-01"
+	ACM_API_VERSION   = "2015-12-08"
+
+	ACM_TARGET_PREFIX = "CertificateManager"
+)
+
+func (self *SRegion) newClient(serviceName, apiVersion string) (*client.Client, error) {
+	s, err := self.getAwsSession()
+	if err != nil {
+		return nil, err
+	}
+	c := s.ClientConfig(serviceName)
+	return client.New(
+		*c.Config,
+		metadata.ClientInfo{
+			ServiceName:   serviceName,
+			SigningName:   c.SigningName,
+			SigningRegion: c.SigningRegion,
+			Endpoint:      c.Endpoint,
+			APIVersion:    apiVersion,
+		},
+		c.Handlers,
+	), nil
+}
+
+func (self *SRegion) newQueryClient(serviceName, apiVersion string) (*client.Client, error) {
+	cli, err := self.newClient(serviceName, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	cli.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+	cli.Handlers.Build.PushBackNamed(query.BuildHandler)
+	cli.Handlers.Unmarshal.PushBackNamed(query.UnmarshalHandler)
+	cli.Handlers.UnmarshalMeta.PushBackNamed(query.UnmarshalMetaHandler)
+	cli.Handlers.UnmarshalError.PushBackNamed(query.UnmarshalErrorHandler)
+	return cli, nil
+}
+
+func (self *SRegion) newJsonClient(serviceName, apiVersion, targetPrefix string) (*client.Client, error) {
+	cli, err := self.newClient(serviceName, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	cli.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+	cli.Handlers.Build.PushBackNamed(request.NamedHandler{Name: "onecloud.jsonrpc.Build", Fn: func(r *request.Request) {
+		body := []byte("{}")
+		if r.Params != nil {
+			data, err := json.Marshal(r.Params)
+			if err != nil {
+				r.Error = awserr.New("SerializationError", "failed encoding JSON RPC request", err)
+				return
+			}
+			body = data
+		}
+		r.SetBufferBody(body)
+		r.HTTPRequest.Header.Set("X-Amz-Target", targetPrefix+"."+r.Operation.Name)
+		r.HTTPRequest.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	}})
+	cli.Handlers.Unmarshal.PushBackNamed(request.NamedHandler{Name: "onecloud.jsonrpc.Unmarshal", Fn: func(r *request.Request) {
+		defer r.HTTPResponse.Body.Close()
+		if r.DataFilled() {
+			err := json.NewDecoder(r.HTTPResponse.Body).Decode(r.Data)
+			if err != nil {
+				r.Error = awserr.New("SerializationError", "failed decoding JSON RPC response", err)
+			}
+		}
+	}})
+	cli.Handlers.UnmarshalError.PushBackNamed(request.NamedHandler{Name: "onecloud.jsonrpc.UnmarshalError", Fn: func(r *request.Request) {
+		defer r.HTTPResponse.Body.Close()
+		e := struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}{}
+		json.NewDecoder(r.HTTPResponse.Body).Decode(&e)
+		code := e.Type
+		if i := strings.LastIndex(code, "#"); i >= 0 {
+			code = code[i+1:]
+		}
+		r.Error = awserr.NewRequestFailure(awserr.New(code, e.Message, nil), r.HTTPResponse.StatusCode, r.RequestID)
+	}})
+	return cli, nil
+}
+
+func (self *SRegion) getElbv2Client() (*client.Client, error) {
+	if self.elbv2Client == nil {
+		cli, err := self.newQueryClient(ELB_SERVICE_NAME, ELBV2_API_VERSION)
+		if err != nil {
+			return nil, err
+		}
+		self.elbv2Client = cli
+	}
+	return self.elbv2Client, nil
+}
+
+func (self *SRegion) getElbClient() (*client.Client, error) {
+	if self.elbClient == nil {
+		cli, err := self.newQueryClient(ELB_SERVICE_NAME, ELB_API_VERSION)
+		if err != nil {
+			return nil, err
+		}
+		self.elbClient = cli
+	}
+	return self.elbClient, nil
+}
+
+func (self *SRegion) getAcmClient() (*client.Client, error) {
+	if self.acmClient == nil {
+		cli, err := self.newJsonClient(ACM_SERVICE_NAME, ACM_API_VERSION, ACM_TARGET_PREFIX)
+		if err != nil {
+			return nil, err
+		}
+		self.acmClient = cli
+	}
+	return self.acmClient, nil
+}
+
+func sendRequest(cli *client.Client, action string, params interface{}, result interface{}) error {
+	req := cli.NewRequest(&request.Operation{Name: action, HTTPMethod: "POST", HTTPPath: "/"}, params, result)
+	return req.Send()
+}
+
+func (self *SRegion) elbv2Request(action string, params interface{}, result interface{}) error {
+	cli, err := self.getElbv2Client()
+	if err != nil {
+		return err
+	}
+	return sendRequest(cli, action, params, result)
+}
+
+func (self *SRegion) elbRequest(action string, params interface{}, result interface{}) error {
+	cli, err := self.getElbClient()
+	if err != nil {
+		return err
+	}
+	return sendRequest(cli, action, params, result)
+}
+
+func (self *SRegion) acmRequest(action string, params interface{}, result interface{}) error {
+	cli, err := self.getAcmClient()
+	if err != nil {
+		return err
+	}
+	return sendRequest(cli, action, params, result)
+}
+
+func isAwsErrorCode(err error, codes ...string) bool {
+	if e, ok := err.(awserr.Error); ok {
+		for _, code := range codes {
+			if e.Code() == code {
+				return true
+			}
+		}
+	}
+	return false
+}
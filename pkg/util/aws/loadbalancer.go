@@ -0,0 +1,544 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+const (
+	ELB_TYPE_APPLICATION = "application"
+	ELB_TYPE_NETWORK     = "network"
+	ELB_TYPE_CLASSIC     = "classic"
+
+	ELB_SCHEME_INTERNAL = "internal"
+	ELB_SCHEME_INTERNET = "internet-facing"
+
+	// 目标组只有被监听器引用后才会关联到负载均衡, 创建时用该标签记录所属的负载均衡
+	ELB_TAG_LOADBALANCER = "onecloud-loadbalancer"
+)
+
+type SElbState struct {
+	Code   *string
+	Reason *string
+}
+
+type SElbAddress struct {
+	IpAddress    *string
+	AllocationId *string
+}
+
+type SElbAvailabilityZone struct {
+	ZoneName              *string
+	SubnetId              *string
+	LoadBalancerAddresses []*SElbAddress
+}
+
+type SElbTag struct {
+	Key   *string
+	Value *string
+}
+
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_LoadBalancer.html
+type SElb struct {
+	region *SRegion
+
+	LoadBalancerArn       *string
+	LoadBalancerName      *string
+	DNSName               *string
+	CanonicalHostedZoneId *string
+	Scheme                *string
+	Type                  *string
+	VpcId                 *string
+	IpAddressType         *string
+	CreatedTime           *time.Time
+	State                 *SElbState
+	AvailabilityZones     []*SElbAvailabilityZone
+	SecurityGroups        []*string
+}
+
+func (self *SElb) GetId() string {
+	return sdk.StringValue(self.LoadBalancerArn)
+}
+
+func (self *SElb) GetName() string {
+	return sdk.StringValue(self.LoadBalancerName)
+}
+
+func (self *SElb) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SElb) GetStatus() string {
+	if self.State == nil {
+		return api.LB_STATUS_UNKNOWN
+	}
+	switch sdk.StringValue(self.State.Code) {
+	case "active", "active_impaired":
+		return api.LB_STATUS_ENABLED
+	case "provisioning":
+		return api.LB_STATUS_INIT
+	default:
+		return api.LB_STATUS_UNKNOWN
+	}
+}
+
+func (self *SElb) Refresh() error {
+	lb, err := self.region.GetElb(self.GetId())
+	if err != nil {
+		return err
+	}
+	*self = *lb
+	return nil
+}
+
+func (self *SElb) IsEmulated() bool {
+	return false
+}
+
+func (self *SElb) GetMetadata() *jsonutils.JSONDict {
+	data := jsonutils.NewDict()
+	data.Add(jsonutils.NewString(sdk.StringValue(self.Type)), "type")
+	data.Add(jsonutils.NewString(sdk.StringValue(self.DNSName)), "dns_name")
+	data.Add(jsonutils.NewString(sdk.StringValue(self.IpAddressType)), "ip_address_type")
+	return data
+}
+
+func (self *SElb) GetProjectId() string {
+	return ""
+}
+
+// 应用型负载均衡只能通过DNS名称访问, 仅网络型负载均衡存在固定的地址
+func (self *SElb) GetAddress() string {
+	for _, zone := range self.AvailabilityZones {
+		for _, addr := range zone.LoadBalancerAddresses {
+			if ip := sdk.StringValue(addr.IpAddress); len(ip) > 0 {
+				return ip
+			}
+		}
+	}
+	return ""
+}
+
+func (self *SElb) GetAddressType() string {
+	if sdk.StringValue(self.Scheme) == ELB_SCHEME_INTERNAL {
+		return api.LB_ADDR_TYPE_INTRANET
+	}
+	return api.LB_ADDR_TYPE_INTERNET
+}
+
+func (self *SElb) GetNetworkType() string {
+	return api.LB_NETWORK_TYPE_VPC
+}
+
+func (self *SElb) GetNetworkId() string {
+	for _, zone := range self.AvailabilityZones {
+		if subnetId := sdk.StringValue(zone.SubnetId); len(subnetId) > 0 {
+			return subnetId
+		}
+	}
+	return ""
+}
+
+func (self *SElb) GetVpcId() string {
+	return sdk.StringValue(self.VpcId)
+}
+
+func (self *SElb) GetZoneId() string {
+	for _, zone := range self.AvailabilityZones {
+		if zoneName := sdk.StringValue(zone.ZoneName); len(zoneName) > 0 {
+			return fmt.Sprintf("%s/%s", self.region.GetGlobalId(), zoneName)
+		}
+	}
+	return ""
+}
+
+func (self *SElb) GetLoadbalancerSpec() string {
+	return sdk.StringValue(self.Type)
+}
+
+func (self *SElb) GetChargeType() string {
+	return api.LB_CHARGE_TYPE_BY_HOUR
+}
+
+func (self *SElb) Delete() error {
+	err := self.region.DeleteElb(self.GetId())
+	if err != nil {
+		return err
+	}
+	return cloudprovider.WaitDeleted(self, 5*time.Second, 300*time.Second)
+}
+
+func (self *SElb) Start() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElb) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElb) GetILoadBalancerListeners() ([]cloudprovider.ICloudLoadbalancerListener, error) {
+	listeners, err := self.region.GetElbListeners(self.GetId(), nil)
+	if err != nil {
+		return nil, err
+	}
+	ilisteners := make([]cloudprovider.ICloudLoadbalancerListener, len(listeners))
+	for i := range listeners {
+		listeners[i].lb = self
+		ilisteners[i] = &listeners[i]
+	}
+	return ilisteners, nil
+}
+
+func (self *SElb) GetILoadBalancerListenerById(listenerId string) (cloudprovider.ICloudLoadbalancerListener, error) {
+	listener, err := self.region.GetElbListener(listenerId)
+	if err != nil {
+		return nil, err
+	}
+	listener.lb = self
+	return listener, nil
+}
+
+func (self *SElb) CreateILoadBalancerListener(listener *cloudprovider.SLoadbalancerListener) (cloudprovider.ICloudLoadbalancerListener, error) {
+	if len(listener.BackendGroupID) == 0 {
+		return nil, fmt.Errorf("aws loadbalancer listener %s requires a backend group", listener.Name)
+	}
+	params := &elbListenerInput{
+		LoadBalancerArn: self.LoadBalancerArn,
+	}
+	params.setListener(listener)
+	ret := &elbListenersOutput{}
+	err := self.region.elbv2Request("CreateListener", params, ret)
+	if err != nil {
+		return nil, err
+	}
+	if len(ret.Listeners) == 0 {
+		return nil, fmt.Errorf("CreateListener returns no listener")
+	}
+	lis := ret.Listeners[0]
+	lis.lb = self
+	err = lis.syncTargetGroup(listener)
+	if err != nil {
+		return nil, err
+	}
+	return lis, nil
+}
+
+// 包括已关联的目标组, 以及创建后尚未被监听器引用的目标组
+func (self *SElb) GetElbTargetGroups() ([]SElbTargetGroup, error) {
+	groups, err := self.region.GetElbTargetGroups("", nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := []SElbTargetGroup{}
+	orphans := []string{}
+	for i := range groups {
+		if len(groups[i].LoadBalancerArns) == 0 {
+			if sdk.StringValue(groups[i].VpcId) == self.GetVpcId() {
+				orphans = append(orphans, groups[i].GetId())
+			}
+			continue
+		}
+		for _, arn := range groups[i].LoadBalancerArns {
+			if sdk.StringValue(arn) == self.GetId() {
+				ret = append(ret, groups[i])
+				break
+			}
+		}
+	}
+	if len(orphans) == 0 {
+		return ret, nil
+	}
+	tags, err := self.region.GetElbTags(orphans)
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if owner, ok := tags[groups[i].GetId()][ELB_TAG_LOADBALANCER]; ok && owner == self.GetId() {
+			ret = append(ret, groups[i])
+		}
+	}
+	return ret, nil
+}
+
+func (self *SElb) GetILoadBalancerBackendGroups() ([]cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	groups, err := self.GetElbTargetGroups()
+	if err != nil {
+		return nil, err
+	}
+	igroups := make([]cloudprovider.ICloudLoadbalancerBackendGroup, len(groups))
+	for i := range groups {
+		groups[i].lb = self
+		igroups[i] = &groups[i]
+	}
+	return igroups, nil
+}
+
+func (self *SElb) GetILoadBalancerBackendGroupById(groupId string) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	group, err := self.region.GetElbTargetGroup(groupId)
+	if err != nil {
+		return nil, err
+	}
+	group.lb = self
+	return group, nil
+}
+
+func (self *SElb) CreateILoadBalancerBackendGroup(group *cloudprovider.SLoadbalancerBackendGroup) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	protocol, port := "HTTP", int64(80)
+	if sdk.StringValue(self.Type) == ELB_TYPE_NETWORK {
+		protocol = "TCP"
+	}
+	if len(group.Backends) > 0 && group.Backends[0].Port > 0 {
+		port = int64(group.Backends[0].Port)
+	}
+	params := &elbCreateTargetGroupInput{
+		Name:       sdk.String(elbName(group.Name)),
+		Protocol:   sdk.String(protocol),
+		Port:       sdk.Int64(port),
+		VpcId:      self.VpcId,
+		TargetType: sdk.String("instance"),
+	}
+	ret := &elbTargetGroupsOutput{}
+	err := self.region.elbv2Request("CreateTargetGroup", params, ret)
+	if err != nil {
+		return nil, err
+	}
+	if len(ret.TargetGroups) == 0 {
+		return nil, fmt.Errorf("CreateTargetGroup returns no target group")
+	}
+	tg := ret.TargetGroups[0]
+	tg.lb = self
+	err = self.region.AddElbTags(tg.GetId(), map[string]string{ELB_TAG_LOADBALANCER: self.GetId()})
+	if err != nil {
+		return nil, err
+	}
+	for _, backend := range group.Backends {
+		_, err = tg.AddBackendServer(backend.ExternalID, backend.Weight, backend.Port)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tg, nil
+}
+
+type elbDescribeLoadBalancersInput struct {
+	LoadBalancerArns []*string
+	Names            []*string
+	Marker           *string
+	PageSize         *int64
+}
+
+type elbDescribeLoadBalancersOutput struct {
+	LoadBalancers []*SElb
+	NextMarker    *string
+}
+
+type elbCreateLoadBalancerInput struct {
+	Name          *string
+	Scheme        *string
+	Type          *string
+	IpAddressType *string
+	Subnets       []*string
+	Tags          []*SElbTag
+}
+
+type elbDeleteLoadBalancerInput struct {
+	LoadBalancerArn *string
+}
+
+type elbDescribeTagsInput struct {
+	ResourceArns []*string
+}
+
+type elbTagDescription struct {
+	ResourceArn *string
+	Tags        []*SElbTag
+}
+
+type elbDescribeTagsOutput struct {
+	TagDescriptions []*elbTagDescription
+}
+
+type elbAddTagsInput struct {
+	ResourceArns []*string
+	Tags         []*SElbTag
+}
+
+// 负载均衡名称最长32个字符, 只能包含字母、数字和连字符, 并且不能以连字符开头或结尾
+func elbName(name string) string {
+	ret := []byte{}
+	for _, c := range []byte(name) {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			ret = append(ret, c)
+		} else if len(ret) > 0 && ret[len(ret)-1] != '-' {
+			ret = append(ret, '-')
+		}
+	}
+	if len(ret) > 32 {
+		ret = ret[:32]
+	}
+	return strings.Trim(string(ret), "-")
+}
+
+func (self *SRegion) GetElbs(arns []string) ([]SElb, error) {
+	params := &elbDescribeLoadBalancersInput{PageSize: sdk.Int64(400)}
+	if len(arns) > 0 {
+		params.LoadBalancerArns = sdk.StringSlice(arns)
+	}
+	lbs := []SElb{}
+	for {
+		ret := &elbDescribeLoadBalancersOutput{}
+		err := self.elbv2Request("DescribeLoadBalancers", params, ret)
+		if err != nil {
+			if isAwsErrorCode(err, "LoadBalancerNotFound") {
+				return nil, cloudprovider.ErrNotFound
+			}
+			return nil, err
+		}
+		for _, lb := range ret.LoadBalancers {
+			lb.region = self
+			lbs = append(lbs, *lb)
+		}
+		if len(sdk.StringValue(ret.NextMarker)) == 0 {
+			break
+		}
+		params.Marker = ret.NextMarker
+	}
+	return lbs, nil
+}
+
+func (self *SRegion) GetElb(arn string) (*SElb, error) {
+	lbs, err := self.GetElbs([]string{arn})
+	if err != nil {
+		return nil, err
+	}
+	if len(lbs) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &lbs[0], nil
+}
+
+func (self *SRegion) DeleteElb(arn string) error {
+	return self.elbv2Request("DeleteLoadBalancer", &elbDeleteLoadBalancerInput{LoadBalancerArn: sdk.String(arn)}, nil)
+}
+
+// 返回资源arn到标签的映射, 每次最多查询20个资源
+func (self *SRegion) GetElbTags(arns []string) (map[string]map[string]string, error) {
+	tags := map[string]map[string]string{}
+	for len(arns) > 0 {
+		batch := arns
+		if len(batch) > 20 {
+			batch = batch[:20]
+		}
+		arns = arns[len(batch):]
+		ret := &elbDescribeTagsOutput{}
+		err := self.elbv2Request("DescribeTags", &elbDescribeTagsInput{ResourceArns: sdk.StringSlice(batch)}, ret)
+		if err != nil {
+			return nil, err
+		}
+		for _, desc := range ret.TagDescriptions {
+			arn := sdk.StringValue(desc.ResourceArn)
+			tags[arn] = map[string]string{}
+			for _, tag := range desc.Tags {
+				tags[arn][sdk.StringValue(tag.Key)] = sdk.StringValue(tag.Value)
+			}
+		}
+	}
+	return tags, nil
+}
+
+func (self *SRegion) AddElbTags(arn string, tags map[string]string) error {
+	params := &elbAddTagsInput{ResourceArns: []*string{sdk.String(arn)}}
+	for k, v := range tags {
+		params.Tags = append(params.Tags, &SElbTag{Key: sdk.String(k), Value: sdk.String(v)})
+	}
+	return self.elbv2Request("AddTags", params, nil)
+}
+
+// 应用型负载均衡要求至少两个可用区, 每个可用区选取一个子网; 指定的子网优先
+func (self *SRegion) getElbSubnets(vpcId, networkId, zoneId string, allZones bool) ([]string, error) {
+	params := &ec2.DescribeSubnetsInput{}
+	if len(vpcId) > 0 {
+		params.Filters = AppendFilter(params.Filters, "vpc-id", []string{vpcId})
+	} else {
+		params.Filters = AppendFilter(params.Filters, "default-for-az", []string{"true"})
+	}
+	ret, err := self.ec2Client.DescribeSubnets(params)
+	if err != nil {
+		return nil, err
+	}
+	zones := map[string]string{}
+	for _, subnet := range ret.Subnets {
+		subnetId, zone := sdk.StringValue(subnet.SubnetId), sdk.StringValue(subnet.AvailabilityZone)
+		if subnetId == networkId {
+			zoneId = zone
+			zones[zone] = subnetId
+			continue
+		}
+		if _, ok := zones[zone]; !ok || (sdk.BoolValue(subnet.DefaultForAz) && zones[zone] != networkId) {
+			zones[zone] = subnetId
+		}
+	}
+	if len(networkId) > 0 && zones[zoneId] != networkId {
+		return nil, fmt.Errorf("subnet %s not found in vpc %s", networkId, vpcId)
+	}
+	subnets := []string{}
+	if subnetId, ok := zones[zoneId]; ok {
+		subnets = append(subnets, subnetId)
+	}
+	if allZones || len(subnets) == 0 {
+		for zone, subnetId := range zones {
+			if zone != zoneId {
+				subnets = append(subnets, subnetId)
+			}
+		}
+	}
+	if len(subnets) == 0 {
+		return nil, fmt.Errorf("no available subnet for loadbalancer")
+	}
+	return subnets, nil
+}
+
+func (self *SRegion) CreateElb(loadbalancer *cloudprovider.SLoadbalancer) (*SElb, error) {
+	lbType := loadbalancer.LoadbalancerSpec
+	if len(lbType) == 0 {
+		lbType = ELB_TYPE_APPLICATION
+	}
+	if lbType != ELB_TYPE_APPLICATION && lbType != ELB_TYPE_NETWORK {
+		return nil, fmt.Errorf("unsupported aws loadbalancer type %s", lbType)
+	}
+	scheme := ELB_SCHEME_INTERNET
+	if loadbalancer.AddressType == api.LB_ADDR_TYPE_INTRANET {
+		scheme = ELB_SCHEME_INTERNAL
+	}
+	subnets, err := self.getElbSubnets(loadbalancer.VpcID, loadbalancer.NetworkID, loadbalancer.ZoneID, lbType == ELB_TYPE_APPLICATION)
+	if err != nil {
+		return nil, err
+	}
+	params := &elbCreateLoadBalancerInput{
+		Name:          sdk.String(elbName(loadbalancer.Name)),
+		Scheme:        sdk.String(scheme),
+		Type:          sdk.String(lbType),
+		IpAddressType: sdk.String("ipv4"),
+		Subnets:       sdk.StringSlice(subnets),
+	}
+	ret := &elbDescribeLoadBalancersOutput{}
+	err = self.elbv2Request("CreateLoadBalancer", params, ret)
+	if err != nil {
+		return nil, err
+	}
+	if len(ret.LoadBalancers) == 0 {
+		return nil, fmt.Errorf("CreateLoadBalancer returns no loadbalancer")
+	}
+	lb := ret.LoadBalancers[0]
+	lb.region = self
+	return lb, cloudprovider.WaitStatus(lb, api.LB_STATUS_ENABLED, 10*time.Second, 600*time.Second)
+}
@@ -0,0 +1,135 @@
+package aws
+
+import (
+	"fmt"
+
+	sdk "github.com/aws/aws-sdk-go/aws"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SElbTargetDescription struct {
+	Id               *string
+	Port             *int64
+	AvailabilityZone *string
+}
+
+type SElbTargetHealth struct {
+	State       *string
+	Reason      *string
+	Description *string
+}
+
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_TargetHealthDescription.html
+type SElbTarget struct {
+	group *SElbTargetGroup
+
+	Target          *SElbTargetDescription
+	HealthCheckPort *string
+	TargetHealth    *SElbTargetHealth
+}
+
+func (self *SElbTarget) GetId() string {
+	return fmt.Sprintf("%s/%s-%d", self.group.GetId(), self.GetBackendId(), self.GetPort())
+}
+
+func (self *SElbTarget) GetName() string {
+	return self.GetId()
+}
+
+func (self *SElbTarget) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SElbTarget) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbTarget) Refresh() error {
+	targets, err := self.group.lb.region.GetElbTargets(self.group.GetId())
+	if err != nil {
+		return err
+	}
+	for i := range targets {
+		targets[i].group = self.group
+		if targets[i].GetId() == self.GetId() {
+			*self = targets[i]
+			return nil
+		}
+	}
+	return cloudprovider.ErrNotFound
+}
+
+func (self *SElbTarget) IsEmulated() bool {
+	return false
+}
+
+func (self *SElbTarget) GetMetadata() *jsonutils.JSONDict {
+	data := jsonutils.NewDict()
+	if self.TargetHealth != nil {
+		data.Add(jsonutils.NewString(sdk.StringValue(self.TargetHealth.State)), "health_state")
+	}
+	return data
+}
+
+func (self *SElbTarget) GetProjectId() string {
+	return ""
+}
+
+func (self *SElbTarget) GetWeight() int {
+	return 1
+}
+
+func (self *SElbTarget) GetPort() int {
+	if self.Target == nil {
+		return 0
+	}
+	return int(sdk.Int64Value(self.Target.Port))
+}
+
+func (self *SElbTarget) GetBackendType() string {
+	return api.LB_BACKEND_GUEST
+}
+
+func (self *SElbTarget) GetBackendRole() string {
+	return api.LB_BACKEND_ROLE_DEFAULT
+}
+
+func (self *SElbTarget) GetBackendId() string {
+	if self.Target == nil {
+		return ""
+	}
+	return sdk.StringValue(self.Target.Id)
+}
+
+type elbTargetsInput struct {
+	TargetGroupArn *string
+	Targets        []*SElbTargetDescription
+}
+
+type elbTargetHealthOutput struct {
+	TargetHealthDescriptions []*SElbTarget
+}
+
+func (self *SRegion) GetElbTargets(groupArn string) ([]SElbTarget, error) {
+	ret := &elbTargetHealthOutput{}
+	err := self.elbv2Request("DescribeTargetHealth", &elbTargetsInput{TargetGroupArn: sdk.String(groupArn)}, ret)
+	if err != nil {
+		if isAwsErrorCode(err, "TargetGroupNotFound") {
+			return nil, cloudprovider.ErrNotFound
+		}
+		return nil, err
+	}
+	targets := []SElbTarget{}
+	for _, target := range ret.TargetHealthDescriptions {
+		// 正在注销的目标不再接收新连接
+		if target.TargetHealth != nil && sdk.StringValue(target.TargetHealth.State) == "draining" {
+			continue
+		}
+		targets = append(targets, *target)
+	}
+	return targets, nil
+}
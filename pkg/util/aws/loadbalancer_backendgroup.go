@@ -0,0 +1,253 @@
+package aws
+
+import (
+	"fmt"
+
+	sdk "github.com/aws/aws-sdk-go/aws"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SElbMatcher struct {
+	HttpCode *string
+}
+
+type SElbAttribute struct {
+	Key   *string
+	Value *string
+}
+
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_TargetGroup.html
+type SElbTargetGroup struct {
+	lb         *SElb
+	attributes map[string]string
+
+	TargetGroupArn             *string
+	TargetGroupName            *string
+	Protocol                   *string
+	Port                       *int64
+	VpcId                      *string
+	TargetType                 *string
+	HealthCheckEnabled         *bool
+	HealthCheckProtocol        *string
+	HealthCheckPort            *string
+	HealthCheckPath            *string
+	HealthCheckIntervalSeconds *int64
+	HealthCheckTimeoutSeconds  *int64
+	HealthyThresholdCount      *int64
+	UnhealthyThresholdCount    *int64
+	Matcher                    *SElbMatcher
+	LoadBalancerArns           []*string
+}
+
+func (self *SElbTargetGroup) GetId() string {
+	return sdk.StringValue(self.TargetGroupArn)
+}
+
+func (self *SElbTargetGroup) GetName() string {
+	return sdk.StringValue(self.TargetGroupName)
+}
+
+func (self *SElbTargetGroup) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SElbTargetGroup) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbTargetGroup) Refresh() error {
+	group, err := self.lb.region.GetElbTargetGroup(self.GetId())
+	if err != nil {
+		return err
+	}
+	group.lb = self.lb
+	*self = *group
+	return nil
+}
+
+func (self *SElbTargetGroup) IsEmulated() bool {
+	return false
+}
+
+func (self *SElbTargetGroup) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SElbTargetGroup) GetProjectId() string {
+	return ""
+}
+
+func (self *SElbTargetGroup) IsDefault() bool {
+	return false
+}
+
+func (self *SElbTargetGroup) GetType() string {
+	return api.LB_BACKENDGROUP_TYPE_NORMAL
+}
+
+func (self *SElbTargetGroup) getAttribute(key string) string {
+	if self.attributes == nil {
+		attrs, err := self.lb.region.GetElbTargetGroupAttributes(self.GetId())
+		if err != nil {
+			return ""
+		}
+		self.attributes = attrs
+	}
+	return self.attributes[key]
+}
+
+func (self *SElbTargetGroup) GetILoadbalancerBackends() ([]cloudprovider.ICloudLoadbalancerBackend, error) {
+	targets, err := self.lb.region.GetElbTargets(self.GetId())
+	if err != nil {
+		return nil, err
+	}
+	ibackends := make([]cloudprovider.ICloudLoadbalancerBackend, len(targets))
+	for i := range targets {
+		targets[i].group = self
+		ibackends[i] = &targets[i]
+	}
+	return ibackends, nil
+}
+
+// 目标组不支持权重
+func (self *SElbTargetGroup) AddBackendServer(serverId string, weight int, port int) (cloudprovider.ICloudLoadbalancerBackend, error) {
+	if port <= 0 {
+		port = int(sdk.Int64Value(self.Port))
+	}
+	err := self.lb.region.elbv2Request("RegisterTargets", self.targetsInput(serverId, port), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SElbTarget{group: self, Target: &SElbTargetDescription{Id: sdk.String(serverId), Port: sdk.Int64(int64(port))}}, nil
+}
+
+func (self *SElbTargetGroup) RemoveBackendServer(serverId string, weight int, port int) error {
+	if port <= 0 {
+		port = int(sdk.Int64Value(self.Port))
+	}
+	return self.lb.region.elbv2Request("DeregisterTargets", self.targetsInput(serverId, port), nil)
+}
+
+func (self *SElbTargetGroup) targetsInput(serverId string, port int) *elbTargetsInput {
+	return &elbTargetsInput{
+		TargetGroupArn: self.TargetGroupArn,
+		Targets:        []*SElbTargetDescription{{Id: sdk.String(serverId), Port: sdk.Int64(int64(port))}},
+	}
+}
+
+func (self *SElbTargetGroup) Delete() error {
+	return self.lb.region.elbv2Request("DeleteTargetGroup", &elbTargetGroupInput{TargetGroupArn: self.TargetGroupArn}, nil)
+}
+
+// 目标组创建后不能改名
+func (self *SElbTargetGroup) Sync(name string) error {
+	return nil
+}
+
+type elbTargetGroupInput struct {
+	TargetGroupArn             *string
+	HealthCheckEnabled         *bool
+	HealthCheckPath            *string
+	HealthCheckIntervalSeconds *int64
+	HealthCheckTimeoutSeconds  *int64
+	HealthyThresholdCount      *int64
+	UnhealthyThresholdCount    *int64
+	Matcher                    *SElbMatcher
+}
+
+type elbCreateTargetGroupInput struct {
+	Name       *string
+	Protocol   *string
+	Port       *int64
+	VpcId      *string
+	TargetType *string
+}
+
+type elbDescribeTargetGroupsInput struct {
+	LoadBalancerArn *string
+	TargetGroupArns []*string
+	Marker          *string
+	PageSize        *int64
+}
+
+type elbTargetGroupsOutput struct {
+	TargetGroups []*SElbTargetGroup
+	NextMarker   *string
+}
+
+type elbTargetGroupAttributesInput struct {
+	TargetGroupArn *string
+	Attributes     []*SElbAttribute
+}
+
+type elbTargetGroupAttributesOutput struct {
+	Attributes []*SElbAttribute
+}
+
+func (self *SRegion) GetElbTargetGroups(lbArn string, arns []string) ([]SElbTargetGroup, error) {
+	params := &elbDescribeTargetGroupsInput{PageSize: sdk.Int64(400)}
+	if len(lbArn) > 0 {
+		params.LoadBalancerArn = sdk.String(lbArn)
+	}
+	if len(arns) > 0 {
+		params.TargetGroupArns = sdk.StringSlice(arns)
+	}
+	groups := []SElbTargetGroup{}
+	for {
+		ret := &elbTargetGroupsOutput{}
+		err := self.elbv2Request("DescribeTargetGroups", params, ret)
+		if err != nil {
+			if isAwsErrorCode(err, "TargetGroupNotFound", "LoadBalancerNotFound") {
+				return nil, cloudprovider.ErrNotFound
+			}
+			return nil, err
+		}
+		for _, group := range ret.TargetGroups {
+			groups = append(groups, *group)
+		}
+		if len(sdk.StringValue(ret.NextMarker)) == 0 {
+			break
+		}
+		params.Marker = ret.NextMarker
+	}
+	return groups, nil
+}
+
+func (self *SRegion) GetElbTargetGroup(arn string) (*SElbTargetGroup, error) {
+	groups, err := self.GetElbTargetGroups("", []string{arn})
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &groups[0], nil
+}
+
+func (self *SRegion) GetElbTargetGroupAttributes(arn string) (map[string]string, error) {
+	ret := &elbTargetGroupAttributesOutput{}
+	err := self.elbv2Request("DescribeTargetGroupAttributes", &elbTargetGroupAttributesInput{TargetGroupArn: sdk.String(arn)}, ret)
+	if err != nil {
+		return nil, err
+	}
+	attrs := map[string]string{}
+	for _, attr := range ret.Attributes {
+		attrs[sdk.StringValue(attr.Key)] = sdk.StringValue(attr.Value)
+	}
+	return attrs, nil
+}
+
+func (self *SRegion) ModifyElbTargetGroupAttributes(arn string, attrs map[string]string) error {
+	params := &elbTargetGroupAttributesInput{TargetGroupArn: sdk.String(arn)}
+	for k, v := range attrs {
+		params.Attributes = append(params.Attributes, &SElbAttribute{Key: sdk.String(k), Value: sdk.String(v)})
+	}
+	if len(params.Attributes) == 0 {
+		return fmt.Errorf("no target group attributes to modify")
+	}
+	return self.elbv2Request("ModifyTargetGroupAttributes", params, nil)
+}
@@ -0,0 +1,260 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SAcmTag struct {
+	Key   string
+	Value string `json:",omitempty"`
+}
+
+// https://docs.aws.amazon.com/acm/latest/APIReference/API_CertificateDetail.html
+type SAcmCertificate struct {
+	region *SRegion
+
+	name        string
+	fingerprint string
+
+	CertificateArn          string
+	DomainName              string
+	SubjectAlternativeNames []string
+	Status                  string
+	Type                    string
+	KeyAlgorithm            string
+	NotBefore               float64
+	NotAfter                float64
+	InUseBy                 []string
+}
+
+func (self *SAcmCertificate) GetId() string {
+	return self.CertificateArn
+}
+
+func (self *SAcmCertificate) GetName() string {
+	if len(self.name) > 0 {
+		return self.name
+	}
+	return self.DomainName
+}
+
+func (self *SAcmCertificate) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SAcmCertificate) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SAcmCertificate) Refresh() error {
+	cert, err := self.region.GetAcmCertificate(self.GetId())
+	if err != nil {
+		return err
+	}
+	*self = *cert
+	return nil
+}
+
+func (self *SAcmCertificate) IsEmulated() bool {
+	return false
+}
+
+func (self *SAcmCertificate) GetMetadata() *jsonutils.JSONDict {
+	data := jsonutils.NewDict()
+	data.Add(jsonutils.NewString(self.Type), "type")
+	return data
+}
+
+func (self *SAcmCertificate) GetProjectId() string {
+	return ""
+}
+
+func (self *SAcmCertificate) GetCommonName() string {
+	return self.DomainName
+}
+
+func (self *SAcmCertificate) GetSubjectAlternativeNames() string {
+	return strings.Join(self.SubjectAlternativeNames, ",")
+}
+
+func (self *SAcmCertificate) GetFingerprint() string {
+	return self.fingerprint
+}
+
+func (self *SAcmCertificate) GetExpireTime() time.Time {
+	return time.Unix(int64(self.NotAfter), 0)
+}
+
+// 只有导入的证书可以重新导入以更新内容
+func (self *SAcmCertificate) Sync(name, privateKey, publickKey string) error {
+	if len(privateKey) > 0 && len(publickKey) > 0 {
+		_, err := self.region.ImportAcmCertificate(self.CertificateArn, publickKey, privateKey)
+		if err != nil {
+			return err
+		}
+	}
+	if len(name) > 0 && name != self.name {
+		err := self.region.acmRequest("AddTagsToCertificate", &acmTagsInput{CertificateArn: self.CertificateArn, Tags: []SAcmTag{{Key: "Name", Value: name}}}, nil)
+		if err != nil {
+			return err
+		}
+		self.name = name
+	}
+	return nil
+}
+
+func (self *SAcmCertificate) Delete() error {
+	return self.region.acmRequest("DeleteCertificate", &acmCertificateInput{CertificateArn: self.CertificateArn}, nil)
+}
+
+type acmCertificateInput struct {
+	CertificateArn string
+}
+
+type acmListCertificatesInput struct {
+	CertificateStatuses []string
+	NextToken           string `json:",omitempty"`
+	MaxItems            int
+}
+
+type acmCertificateSummary struct {
+	CertificateArn string
+	DomainName     string
+}
+
+type acmListCertificatesOutput struct {
+	CertificateSummaryList []acmCertificateSummary
+	NextToken              string
+}
+
+type acmDescribeCertificateOutput struct {
+	Certificate SAcmCertificate
+}
+
+type acmGetCertificateOutput struct {
+	Certificate      string
+	CertificateChain string
+}
+
+type acmImportCertificateInput struct {
+	CertificateArn   string `json:",omitempty"`
+	Certificate      []byte
+	PrivateKey       []byte
+	CertificateChain []byte `json:",omitempty"`
+}
+
+type acmImportCertificateOutput struct {
+	CertificateArn string
+}
+
+type acmTagsInput struct {
+	CertificateArn string
+	Tags           []SAcmTag
+}
+
+type acmTagsOutput struct {
+	Tags []SAcmTag
+}
+
+func (self *SRegion) GetAcmCertificates() ([]SAcmCertificate, error) {
+	params := &acmListCertificatesInput{CertificateStatuses: []string{"ISSUED"}, MaxItems: 100}
+	certs := []SAcmCertificate{}
+	for {
+		ret := &acmListCertificatesOutput{}
+		err := self.acmRequest("ListCertificates", params, ret)
+		if err != nil {
+			return nil, err
+		}
+		for _, summary := range ret.CertificateSummaryList {
+			cert, err := self.GetAcmCertificate(summary.CertificateArn)
+			if err != nil {
+				return nil, err
+			}
+			certs = append(certs, *cert)
+		}
+		if len(ret.NextToken) == 0 {
+			break
+		}
+		params.NextToken = ret.NextToken
+	}
+	return certs, nil
+}
+
+func (self *SRegion) GetAcmCertificate(arn string) (*SAcmCertificate, error) {
+	desc := &acmDescribeCertificateOutput{}
+	err := self.acmRequest("DescribeCertificate", &acmCertificateInput{CertificateArn: arn}, desc)
+	if err != nil {
+		if isAwsErrorCode(err, "ResourceNotFoundException") {
+			return nil, cloudprovider.ErrNotFound
+		}
+		return nil, err
+	}
+	cert := &desc.Certificate
+	cert.region = self
+
+	tags := &acmTagsOutput{}
+	err = self.acmRequest("ListTagsForCertificate", &acmCertificateInput{CertificateArn: arn}, tags)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags.Tags {
+		if tag.Key == "Name" {
+			cert.name = tag.Value
+		}
+	}
+
+	content := &acmGetCertificateOutput{}
+	err = self.acmRequest("GetCertificate", &acmCertificateInput{CertificateArn: arn}, content)
+	if err != nil {
+		return nil, err
+	}
+	if block, _ := pem.Decode([]byte(content.Certificate)); block != nil {
+		d := sha256.Sum256(block.Bytes)
+		cert.fingerprint = api.LB_TLS_CERT_FINGERPRINT_ALGO_SHA256 + ":" + hex.EncodeToString(d[:])
+	}
+	return cert, nil
+}
+
+// 证书链需要与证书分开导入
+func (self *SRegion) ImportAcmCertificate(arn, certificate, privateKey string) (string, error) {
+	block, rest := pem.Decode([]byte(certificate))
+	if block == nil {
+		return "", fmt.Errorf("invalid certificate")
+	}
+	params := &acmImportCertificateInput{
+		CertificateArn: arn,
+		Certificate:    pem.EncodeToMemory(block),
+		PrivateKey:     []byte(privateKey),
+	}
+	if chain := strings.TrimSpace(string(rest)); len(chain) > 0 {
+		params.CertificateChain = []byte(chain)
+	}
+	ret := &acmImportCertificateOutput{}
+	err := self.acmRequest("ImportCertificate", params, ret)
+	if err != nil {
+		return "", err
+	}
+	return ret.CertificateArn, nil
+}
+
+func (self *SRegion) CreateAcmCertificate(cert *cloudprovider.SLoadbalancerCertificate) (*SAcmCertificate, error) {
+	arn, err := self.ImportAcmCertificate("", cert.Certificate, cert.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	err = self.acmRequest("AddTagsToCertificate", &acmTagsInput{CertificateArn: arn, Tags: []SAcmTag{{Key: "Name", Value: cert.Name}}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return self.GetAcmCertificate(arn)
+}
@@ -0,0 +1,722 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/aws/aws-sdk-go/aws"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+/*
+传统型负载均衡(CLB)以名称作为标识, 没有目标组与转发规则:
+1. 注册的实例作为唯一的默认后端服务器组
+2. 健康检查属于负载均衡本身, 对所有监听器生效
+3. 监听器不支持修改, 同步时删除后重建
+*/
+
+type SClassicElbListenerDetail struct {
+	Protocol         *string
+	LoadBalancerPort *int64
+	InstanceProtocol *string
+	InstancePort     *int64
+	SSLCertificateId *string
+}
+
+type SClassicElbListenerDescription struct {
+	Listener    *SClassicElbListenerDetail
+	PolicyNames []*string
+}
+
+type SClassicElbHealthCheck struct {
+	Target             *string
+	Interval           *int64
+	Timeout            *int64
+	UnhealthyThreshold *int64
+	HealthyThreshold   *int64
+}
+
+type SClassicElbInstance struct {
+	InstanceId *string
+}
+
+// https://docs.aws.amazon.com/elasticloadbalancing/2012-06-01/APIReference/API_LoadBalancerDescription.html
+type SClassicElb struct {
+	region *SRegion
+
+	LoadBalancerName     *string
+	DNSName              *string
+	Scheme               *string
+	VPCId                *string
+	CreatedTime          *time.Time
+	AvailabilityZones    []*string
+	Subnets              []*string
+	SecurityGroups       []*string
+	Instances            []*SClassicElbInstance
+	ListenerDescriptions []*SClassicElbListenerDescription
+	HealthCheck          *SClassicElbHealthCheck
+}
+
+func (self *SClassicElb) GetId() string {
+	return sdk.StringValue(self.LoadBalancerName)
+}
+
+func (self *SClassicElb) GetName() string {
+	return self.GetId()
+}
+
+func (self *SClassicElb) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SClassicElb) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SClassicElb) Refresh() error {
+	lb, err := self.region.GetClassicElb(self.GetId())
+	if err != nil {
+		return err
+	}
+	*self = *lb
+	return nil
+}
+
+func (self *SClassicElb) IsEmulated() bool {
+	return false
+}
+
+func (self *SClassicElb) GetMetadata() *jsonutils.JSONDict {
+	data := jsonutils.NewDict()
+	data.Add(jsonutils.NewString(ELB_TYPE_CLASSIC), "type")
+	data.Add(jsonutils.NewString(sdk.StringValue(self.DNSName)), "dns_name")
+	return data
+}
+
+func (self *SClassicElb) GetProjectId() string {
+	return ""
+}
+
+func (self *SClassicElb) GetAddress() string {
+	return ""
+}
+
+func (self *SClassicElb) GetAddressType() string {
+	if sdk.StringValue(self.Scheme) == ELB_SCHEME_INTERNAL {
+		return api.LB_ADDR_TYPE_INTRANET
+	}
+	return api.LB_ADDR_TYPE_INTERNET
+}
+
+func (self *SClassicElb) GetNetworkType() string {
+	if len(sdk.StringValue(self.VPCId)) == 0 {
+		return api.LB_NETWORK_TYPE_CLASSIC
+	}
+	return api.LB_NETWORK_TYPE_VPC
+}
+
+func (self *SClassicElb) GetNetworkId() string {
+	if len(self.Subnets) > 0 {
+		return sdk.StringValue(self.Subnets[0])
+	}
+	return ""
+}
+
+func (self *SClassicElb) GetVpcId() string {
+	return sdk.StringValue(self.VPCId)
+}
+
+func (self *SClassicElb) GetZoneId() string {
+	if len(self.AvailabilityZones) > 0 {
+		return fmt.Sprintf("%s/%s", self.region.GetGlobalId(), sdk.StringValue(self.AvailabilityZones[0]))
+	}
+	return ""
+}
+
+func (self *SClassicElb) GetLoadbalancerSpec() string {
+	return ELB_TYPE_CLASSIC
+}
+
+func (self *SClassicElb) GetChargeType() string {
+	return api.LB_CHARGE_TYPE_BY_HOUR
+}
+
+func (self *SClassicElb) Delete() error {
+	return self.region.elbRequest("DeleteLoadBalancer", &classicElbInput{LoadBalancerName: self.LoadBalancerName}, nil)
+}
+
+func (self *SClassicElb) Start() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SClassicElb) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SClassicElb) GetILoadBalancerListeners() ([]cloudprovider.ICloudLoadbalancerListener, error) {
+	ilisteners := []cloudprovider.ICloudLoadbalancerListener{}
+	for _, desc := range self.ListenerDescriptions {
+		if desc.Listener != nil {
+			ilisteners = append(ilisteners, &SClassicElbListener{lb: self, SClassicElbListenerDetail: *desc.Listener})
+		}
+	}
+	return ilisteners, nil
+}
+
+func (self *SClassicElb) GetILoadBalancerListenerById(listenerId string) (cloudprovider.ICloudLoadbalancerListener, error) {
+	listeners, err := self.GetILoadBalancerListeners()
+	if err != nil {
+		return nil, err
+	}
+	for i := range listeners {
+		if listeners[i].GetGlobalId() == listenerId {
+			return listeners[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SClassicElb) CreateILoadBalancerListener(listener *cloudprovider.SLoadbalancerListener) (cloudprovider.ICloudLoadbalancerListener, error) {
+	detail := newClassicElbListenerDetail(listener)
+	params := &classicElbInput{
+		LoadBalancerName: self.LoadBalancerName,
+		Listeners:        []*SClassicElbListenerDetail{detail},
+	}
+	err := self.region.elbRequest("CreateLoadBalancerListeners", params, nil)
+	if err != nil {
+		return nil, err
+	}
+	lis := &SClassicElbListener{lb: self, SClassicElbListenerDetail: *detail}
+	return lis, lis.syncHealthCheck(listener)
+}
+
+func (self *SClassicElb) getBackendGroup() *SClassicElbBackendGroup {
+	return &SClassicElbBackendGroup{lb: self}
+}
+
+func (self *SClassicElb) GetILoadBalancerBackendGroups() ([]cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	return []cloudprovider.ICloudLoadbalancerBackendGroup{self.getBackendGroup()}, nil
+}
+
+func (self *SClassicElb) GetILoadBalancerBackendGroupById(groupId string) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	group := self.getBackendGroup()
+	if group.GetGlobalId() != groupId {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return group, nil
+}
+
+func (self *SClassicElb) CreateILoadBalancerBackendGroup(group *cloudprovider.SLoadbalancerBackendGroup) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+type SClassicElbListener struct {
+	lb *SClassicElb
+
+	SClassicElbListenerDetail
+}
+
+func newClassicElbListenerDetail(listener *cloudprovider.SLoadbalancerListener) *SClassicElbListenerDetail {
+	protocol := strings.ToUpper(listener.ListenerType)
+	instanceProtocol := protocol
+	if listener.ListenerType == api.LB_LISTENER_TYPE_HTTPS {
+		instanceProtocol = "HTTP"
+	}
+	detail := &SClassicElbListenerDetail{
+		Protocol:         sdk.String(protocol),
+		LoadBalancerPort: sdk.Int64(int64(listener.ListenerPort)),
+		InstanceProtocol: sdk.String(instanceProtocol),
+		InstancePort:     sdk.Int64(int64(listener.BackendServerPort)),
+	}
+	if listener.BackendServerPort <= 0 {
+		detail.InstancePort = detail.LoadBalancerPort
+	}
+	if listener.ListenerType == api.LB_LISTENER_TYPE_HTTPS && len(listener.CertificateID) > 0 {
+		detail.SSLCertificateId = sdk.String(listener.CertificateID)
+	}
+	return detail
+}
+
+func (self *SClassicElbListener) GetId() string {
+	return fmt.Sprintf("%s/%d", self.lb.GetId(), self.GetListenerPort())
+}
+
+func (self *SClassicElbListener) GetName() string {
+	return fmt.Sprintf("%s:%d", strings.ToLower(sdk.StringValue(self.Protocol)), self.GetListenerPort())
+}
+
+func (self *SClassicElbListener) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SClassicElbListener) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SClassicElbListener) Refresh() error {
+	err := self.lb.Refresh()
+	if err != nil {
+		return err
+	}
+	for _, desc := range self.lb.ListenerDescriptions {
+		if desc.Listener != nil && sdk.Int64Value(desc.Listener.LoadBalancerPort) == sdk.Int64Value(self.LoadBalancerPort) {
+			self.SClassicElbListenerDetail = *desc.Listener
+			return nil
+		}
+	}
+	return cloudprovider.ErrNotFound
+}
+
+func (self *SClassicElbListener) IsEmulated() bool {
+	return false
+}
+
+func (self *SClassicElbListener) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SClassicElbListener) GetProjectId() string {
+	return ""
+}
+
+func (self *SClassicElbListener) GetListenerType() string {
+	switch sdk.StringValue(self.Protocol) {
+	case "HTTP":
+		return api.LB_LISTENER_TYPE_HTTP
+	case "HTTPS":
+		return api.LB_LISTENER_TYPE_HTTPS
+	default:
+		return api.LB_LISTENER_TYPE_TCP
+	}
+}
+
+func (self *SClassicElbListener) GetListenerPort() int {
+	return int(sdk.Int64Value(self.LoadBalancerPort))
+}
+
+func (self *SClassicElbListener) GetScheduler() string {
+	return api.LB_SCHEDULER_RR
+}
+
+func (self *SClassicElbListener) GetAclStatus() string {
+	return api.LB_BOOL_OFF
+}
+
+func (self *SClassicElbListener) GetAclType() string {
+	return ""
+}
+
+func (self *SClassicElbListener) GetAclId() string {
+	return ""
+}
+
+// 健康检查目标格式为 PROTOCOL:PORT[/PATH]
+func (self *SClassicElbListener) parseHealthCheckTarget() (string, string) {
+	if self.lb.HealthCheck == nil {
+		return "", ""
+	}
+	target := sdk.StringValue(self.lb.HealthCheck.Target)
+	protocol, path := target, ""
+	if i := strings.Index(target, ":"); i >= 0 {
+		protocol = target[:i]
+		if j := strings.Index(target[i:], "/"); j >= 0 {
+			path = target[i+j:]
+		}
+	}
+	return strings.ToUpper(protocol), path
+}
+
+func (self *SClassicElbListener) GetHealthCheck() string {
+	if self.lb.HealthCheck == nil {
+		return api.LB_BOOL_OFF
+	}
+	return api.LB_BOOL_ON
+}
+
+func (self *SClassicElbListener) GetHealthCheckType() string {
+	protocol, _ := self.parseHealthCheckTarget()
+	if protocol == "HTTP" || protocol == "HTTPS" {
+		return api.LB_HEALTH_CHECK_HTTP
+	}
+	return api.LB_HEALTH_CHECK_TCP
+}
+
+func (self *SClassicElbListener) GetHealthCheckTimeout() int {
+	if self.lb.HealthCheck == nil {
+		return 0
+	}
+	return int(sdk.Int64Value(self.lb.HealthCheck.Timeout))
+}
+
+func (self *SClassicElbListener) GetHealthCheckInterval() int {
+	if self.lb.HealthCheck == nil {
+		return 0
+	}
+	return int(sdk.Int64Value(self.lb.HealthCheck.Interval))
+}
+
+func (self *SClassicElbListener) GetHealthCheckRise() int {
+	if self.lb.HealthCheck == nil {
+		return 0
+	}
+	return int(sdk.Int64Value(self.lb.HealthCheck.HealthyThreshold))
+}
+
+func (self *SClassicElbListener) GetHealthCheckFail() int {
+	if self.lb.HealthCheck == nil {
+		return 0
+	}
+	return int(sdk.Int64Value(self.lb.HealthCheck.UnhealthyThreshold))
+}
+
+func (self *SClassicElbListener) GetHealthCheckReq() string {
+	return ""
+}
+
+func (self *SClassicElbListener) GetHealthCheckExp() string {
+	return ""
+}
+
+func (self *SClassicElbListener) GetBackendGroupId() string {
+	return self.lb.getBackendGroup().GetGlobalId()
+}
+
+func (self *SClassicElbListener) GetBackendServerPort() int {
+	return int(sdk.Int64Value(self.InstancePort))
+}
+
+func (self *SClassicElbListener) GetHealthCheckDomain() string {
+	return ""
+}
+
+func (self *SClassicElbListener) GetHealthCheckURI() string {
+	_, path := self.parseHealthCheckTarget()
+	return path
+}
+
+func (self *SClassicElbListener) GetHealthCheckCode() string {
+	if self.GetHealthCheckType() == api.LB_HEALTH_CHECK_HTTP {
+		return api.LB_HEALTH_CHECK_HTTP_CODE_2xx
+	}
+	return ""
+}
+
+func (self *SClassicElbListener) CreateILoadBalancerListenerRule(rule *cloudprovider.SLoadbalancerListenerRule) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SClassicElbListener) GetILoadBalancerListenerRuleById(ruleId string) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SClassicElbListener) GetILoadbalancerListenerRules() ([]cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	return []cloudprovider.ICloudLoadbalancerListenerRule{}, nil
+}
+
+func (self *SClassicElbListener) GetStickySession() string {
+	return api.LB_BOOL_OFF
+}
+
+func (self *SClassicElbListener) GetStickySessionType() string {
+	return ""
+}
+
+func (self *SClassicElbListener) GetStickySessionCookie() string {
+	return ""
+}
+
+func (self *SClassicElbListener) GetStickySessionCookieTimeout() int {
+	return 0
+}
+
+func (self *SClassicElbListener) XForwardedForEnabled() bool {
+	protocol := sdk.StringValue(self.Protocol)
+	return protocol == "HTTP" || protocol == "HTTPS"
+}
+
+func (self *SClassicElbListener) GzipEnabled() bool {
+	return false
+}
+
+func (self *SClassicElbListener) GetCertificateId() string {
+	return sdk.StringValue(self.SSLCertificateId)
+}
+
+func (self *SClassicElbListener) GetTLSCipherPolicy() string {
+	return ""
+}
+
+func (self *SClassicElbListener) HTTP2Enabled() bool {
+	return false
+}
+
+func (self *SClassicElbListener) Start() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SClassicElbListener) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SClassicElbListener) Sync(listener *cloudprovider.SLoadbalancerListener) error {
+	err := self.Delete()
+	if err != nil {
+		return err
+	}
+	detail := newClassicElbListenerDetail(listener)
+	params := &classicElbInput{
+		LoadBalancerName: self.lb.LoadBalancerName,
+		Listeners:        []*SClassicElbListenerDetail{detail},
+	}
+	err = self.lb.region.elbRequest("CreateLoadBalancerListeners", params, nil)
+	if err != nil {
+		return err
+	}
+	self.SClassicElbListenerDetail = *detail
+	return self.syncHealthCheck(listener)
+}
+
+func (self *SClassicElbListener) Delete() error {
+	params := &classicElbInput{
+		LoadBalancerName:  self.lb.LoadBalancerName,
+		LoadBalancerPorts: []*int64{self.LoadBalancerPort},
+	}
+	return self.lb.region.elbRequest("DeleteLoadBalancerListeners", params, nil)
+}
+
+func (self *SClassicElbListener) syncHealthCheck(listener *cloudprovider.SLoadbalancerListener) error {
+	if listener.HealthCheck == api.LB_BOOL_OFF {
+		return nil
+	}
+	target := fmt.Sprintf("TCP:%d", sdk.Int64Value(self.InstancePort))
+	if len(listener.HealthCheckURI) > 0 && (listener.ListenerType == api.LB_LISTENER_TYPE_HTTP || listener.ListenerType == api.LB_LISTENER_TYPE_HTTPS) {
+		target = fmt.Sprintf("HTTP:%d%s", sdk.Int64Value(self.InstancePort), listener.HealthCheckURI)
+	}
+	interval := elbClamp(listener.HealthCheckInterval, 5, 300)
+	timeout := elbClamp(listener.HealthCheckTimeout, 2, 60)
+	if timeout >= interval {
+		timeout = interval - 1
+	}
+	params := &classicElbInput{
+		LoadBalancerName: self.lb.LoadBalancerName,
+		HealthCheck: &SClassicElbHealthCheck{
+			Target:             sdk.String(target),
+			Interval:           sdk.Int64(interval),
+			Timeout:            sdk.Int64(timeout),
+			HealthyThreshold:   sdk.Int64(elbClamp(listener.HealthCheckRise, 2, 10)),
+			UnhealthyThreshold: sdk.Int64(elbClamp(listener.HealthCheckFail, 2, 10)),
+		},
+	}
+	return self.lb.region.elbRequest("ConfigureHealthCheck", params, nil)
+}
+
+type SClassicElbBackendGroup struct {
+	lb *SClassicElb
+}
+
+func (self *SClassicElbBackendGroup) GetId() string {
+	return fmt.Sprintf("%s/default", self.lb.GetId())
+}
+
+func (self *SClassicElbBackendGroup) GetName() string {
+	return "default"
+}
+
+func (self *SClassicElbBackendGroup) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SClassicElbBackendGroup) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SClassicElbBackendGroup) Refresh() error {
+	return self.lb.Refresh()
+}
+
+func (self *SClassicElbBackendGroup) IsEmulated() bool {
+	return true
+}
+
+func (self *SClassicElbBackendGroup) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SClassicElbBackendGroup) GetProjectId() string {
+	return ""
+}
+
+func (self *SClassicElbBackendGroup) IsDefault() bool {
+	return true
+}
+
+func (self *SClassicElbBackendGroup) GetType() string {
+	return api.LB_BACKENDGROUP_TYPE_DEFAULT
+}
+
+// 实例端口由监听器决定, 这里取第一个监听器的实例端口
+func (self *SClassicElbBackendGroup) getPort() int {
+	for _, desc := range self.lb.ListenerDescriptions {
+		if desc.Listener != nil {
+			return int(sdk.Int64Value(desc.Listener.InstancePort))
+		}
+	}
+	return 0
+}
+
+func (self *SClassicElbBackendGroup) GetILoadbalancerBackends() ([]cloudprovider.ICloudLoadbalancerBackend, error) {
+	ibackends := []cloudprovider.ICloudLoadbalancerBackend{}
+	for _, instance := range self.lb.Instances {
+		ibackends = append(ibackends, &SClassicElbBackend{group: self, InstanceId: sdk.StringValue(instance.InstanceId), Port: self.getPort()})
+	}
+	return ibackends, nil
+}
+
+func (self *SClassicElbBackendGroup) AddBackendServer(serverId string, weight int, port int) (cloudprovider.ICloudLoadbalancerBackend, error) {
+	params := &classicElbInput{
+		LoadBalancerName: self.lb.LoadBalancerName,
+		Instances:        []*SClassicElbInstance{{InstanceId: sdk.String(serverId)}},
+	}
+	err := self.lb.region.elbRequest("RegisterInstancesWithLoadBalancer", params, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SClassicElbBackend{group: self, InstanceId: serverId, Port: self.getPort()}, nil
+}
+
+func (self *SClassicElbBackendGroup) RemoveBackendServer(serverId string, weight int, port int) error {
+	params := &classicElbInput{
+		LoadBalancerName: self.lb.LoadBalancerName,
+		Instances:        []*SClassicElbInstance{{InstanceId: sdk.String(serverId)}},
+	}
+	return self.lb.region.elbRequest("DeregisterInstancesFromLoadBalancer", params, nil)
+}
+
+func (self *SClassicElbBackendGroup) Delete() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SClassicElbBackendGroup) Sync(name string) error {
+	return nil
+}
+
+type SClassicElbBackend struct {
+	group *SClassicElbBackendGroup
+
+	InstanceId string
+	Port       int
+}
+
+func (self *SClassicElbBackend) GetId() string {
+	return fmt.Sprintf("%s/%s-%d", self.group.GetId(), self.InstanceId, self.Port)
+}
+
+func (self *SClassicElbBackend) GetName() string {
+	return self.GetId()
+}
+
+func (self *SClassicElbBackend) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SClassicElbBackend) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SClassicElbBackend) Refresh() error {
+	return nil
+}
+
+func (self *SClassicElbBackend) IsEmulated() bool {
+	return false
+}
+
+func (self *SClassicElbBackend) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SClassicElbBackend) GetProjectId() string {
+	return ""
+}
+
+func (self *SClassicElbBackend) GetWeight() int {
+	return 1
+}
+
+func (self *SClassicElbBackend) GetPort() int {
+	return self.Port
+}
+
+func (self *SClassicElbBackend) GetBackendType() string {
+	return api.LB_BACKEND_GUEST
+}
+
+func (self *SClassicElbBackend) GetBackendRole() string {
+	return api.LB_BACKEND_ROLE_DEFAULT
+}
+
+func (self *SClassicElbBackend) GetBackendId() string {
+	return self.InstanceId
+}
+
+type classicElbInput struct {
+	LoadBalancerName  *string
+	Listeners         []*SClassicElbListenerDetail
+	LoadBalancerPorts []*int64
+	Instances         []*SClassicElbInstance
+	HealthCheck       *SClassicElbHealthCheck
+}
+
+type classicElbDescribeInput struct {
+	LoadBalancerNames []*string
+	Marker            *string
+	PageSize          *int64
+}
+
+type classicElbDescribeOutput struct {
+	LoadBalancerDescriptions []*SClassicElb
+	NextMarker               *string
+}
+
+func (self *SRegion) GetClassicElbs(names []string) ([]SClassicElb, error) {
+	params := &classicElbDescribeInput{PageSize: sdk.Int64(400)}
+	if len(names) > 0 {
+		params.LoadBalancerNames = sdk.StringSlice(names)
+	}
+	lbs := []SClassicElb{}
+	for {
+		ret := &classicElbDescribeOutput{}
+		err := self.elbRequest("DescribeLoadBalancers", params, ret)
+		if err != nil {
+			if isAwsErrorCode(err, "LoadBalancerNotFound") {
+				return nil, cloudprovider.ErrNotFound
+			}
+			return nil, err
+		}
+		for _, lb := range ret.LoadBalancerDescriptions {
+			lb.region = self
+			lbs = append(lbs, *lb)
+		}
+		if len(sdk.StringValue(ret.NextMarker)) == 0 {
+			break
+		}
+		params.Marker = ret.NextMarker
+	}
+	return lbs, nil
+}
+
+func (self *SRegion) GetClassicElb(name string) (*SClassicElb, error) {
+	lbs, err := self.GetClassicElbs([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(lbs) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &lbs[0], nil
+}
@@ -0,0 +1,580 @@
+package aws
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sdk "github.com/aws/aws-sdk-go/aws"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+var elbTLSCipherPolicies = map[string]string{
+	api.LB_TLS_CIPHER_POLICY_1_0:        "ELBSecurityPolicy-2016-08",
+	api.LB_TLS_CIPHER_POLICY_1_1:        "ELBSecurityPolicy-TLS-1-1-2017-01",
+	api.LB_TLS_CIPHER_POLICY_1_2:        "ELBSecurityPolicy-TLS-1-2-2017-01",
+	api.LB_TLS_CIPHER_POLICY_1_2_strict: "ELBSecurityPolicy-TLS-1-2-Ext-2018-06",
+}
+
+type SElbRedirectConfig struct {
+	Protocol   *string
+	Host       *string
+	Path       *string
+	Query      *string
+	Port       *string
+	StatusCode *string
+}
+
+type SElbFixedResponseConfig struct {
+	StatusCode  *string
+	ContentType *string
+}
+
+type SElbAction struct {
+	Type                *string
+	TargetGroupArn      *string
+	Order               *int64
+	RedirectConfig      *SElbRedirectConfig
+	FixedResponseConfig *SElbFixedResponseConfig
+}
+
+type SElbListenerCertificate struct {
+	CertificateArn *string
+	IsDefault      *bool
+}
+
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_Listener.html
+type SElbListener struct {
+	lb    *SElb
+	group *SElbTargetGroup
+
+	ListenerArn     *string
+	LoadBalancerArn *string
+	Port            *int64
+	Protocol        *string
+	SslPolicy       *string
+	Certificates    []*SElbListenerCertificate
+	DefaultActions  []*SElbAction
+}
+
+func (self *SElbListener) GetId() string {
+	return sdk.StringValue(self.ListenerArn)
+}
+
+// 监听器没有名称, 以协议和端口命名
+func (self *SElbListener) GetName() string {
+	return fmt.Sprintf("%s:%d", strings.ToLower(sdk.StringValue(self.Protocol)), sdk.Int64Value(self.Port))
+}
+
+func (self *SElbListener) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SElbListener) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbListener) Refresh() error {
+	listener, err := self.lb.region.GetElbListener(self.GetId())
+	if err != nil {
+		return err
+	}
+	listener.lb = self.lb
+	*self = *listener
+	return nil
+}
+
+func (self *SElbListener) IsEmulated() bool {
+	return false
+}
+
+func (self *SElbListener) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SElbListener) GetProjectId() string {
+	return ""
+}
+
+func (self *SElbListener) getTargetGroup() *SElbTargetGroup {
+	if self.group == nil {
+		groupId := self.GetBackendGroupId()
+		if len(groupId) == 0 {
+			return nil
+		}
+		group, err := self.lb.region.GetElbTargetGroup(groupId)
+		if err != nil {
+			log.Errorf("failed to get target group %s of listener %s: %v", groupId, self.GetId(), err)
+			return nil
+		}
+		group.lb = self.lb
+		self.group = group
+	}
+	return self.group
+}
+
+func (self *SElbListener) GetListenerType() string {
+	switch sdk.StringValue(self.Protocol) {
+	case "HTTP":
+		return api.LB_LISTENER_TYPE_HTTP
+	case "HTTPS":
+		return api.LB_LISTENER_TYPE_HTTPS
+	case "UDP":
+		return api.LB_LISTENER_TYPE_UDP
+	default:
+		return api.LB_LISTENER_TYPE_TCP
+	}
+}
+
+func (self *SElbListener) GetListenerPort() int {
+	return int(sdk.Int64Value(self.Port))
+}
+
+func (self *SElbListener) GetScheduler() string {
+	if sdk.StringValue(self.lb.Type) == ELB_TYPE_NETWORK {
+		return api.LB_SCHEDULER_TCH
+	}
+	return api.LB_SCHEDULER_RR
+}
+
+func (self *SElbListener) GetAclStatus() string {
+	return api.LB_BOOL_OFF
+}
+
+func (self *SElbListener) GetAclType() string {
+	return ""
+}
+
+func (self *SElbListener) GetAclId() string {
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheck() string {
+	if group := self.getTargetGroup(); group != nil && sdk.BoolValue(group.HealthCheckEnabled) {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (self *SElbListener) GetHealthCheckType() string {
+	if group := self.getTargetGroup(); group != nil {
+		switch sdk.StringValue(group.HealthCheckProtocol) {
+		case "HTTP", "HTTPS":
+			return api.LB_HEALTH_CHECK_HTTP
+		}
+	}
+	return api.LB_HEALTH_CHECK_TCP
+}
+
+func (self *SElbListener) GetHealthCheckTimeout() int {
+	if group := self.getTargetGroup(); group != nil {
+		return int(sdk.Int64Value(group.HealthCheckTimeoutSeconds))
+	}
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckInterval() int {
+	if group := self.getTargetGroup(); group != nil {
+		return int(sdk.Int64Value(group.HealthCheckIntervalSeconds))
+	}
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckRise() int {
+	if group := self.getTargetGroup(); group != nil {
+		return int(sdk.Int64Value(group.HealthyThresholdCount))
+	}
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckFail() int {
+	if group := self.getTargetGroup(); group != nil {
+		return int(sdk.Int64Value(group.UnhealthyThresholdCount))
+	}
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckReq() string {
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheckExp() string {
+	return ""
+}
+
+func (self *SElbListener) GetBackendGroupId() string {
+	for _, action := range self.DefaultActions {
+		if sdk.StringValue(action.Type) == "forward" {
+			return sdk.StringValue(action.TargetGroupArn)
+		}
+	}
+	return ""
+}
+
+func (self *SElbListener) GetBackendServerPort() int {
+	if group := self.getTargetGroup(); group != nil {
+		return int(sdk.Int64Value(group.Port))
+	}
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckDomain() string {
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheckURI() string {
+	if group := self.getTargetGroup(); group != nil {
+		return sdk.StringValue(group.HealthCheckPath)
+	}
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheckCode() string {
+	if group := self.getTargetGroup(); group != nil && group.Matcher != nil {
+		return elbMatcherToHealthCheckCode(sdk.StringValue(group.Matcher.HttpCode))
+	}
+	return ""
+}
+
+func (self *SElbListener) GetStickySession() string {
+	if group := self.getTargetGroup(); group != nil && group.getAttribute("stickiness.enabled") == "true" {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (self *SElbListener) GetStickySessionType() string {
+	return api.LB_STICKY_SESSION_TYPE_INSERT
+}
+
+func (self *SElbListener) GetStickySessionCookie() string {
+	return ""
+}
+
+func (self *SElbListener) GetStickySessionCookieTimeout() int {
+	if group := self.getTargetGroup(); group != nil {
+		timeout, _ := strconv.Atoi(group.getAttribute("stickiness.lb_cookie.duration_seconds"))
+		return timeout
+	}
+	return 0
+}
+
+// 应用型负载均衡总会添加X-Forwarded-For头
+func (self *SElbListener) XForwardedForEnabled() bool {
+	return sdk.StringValue(self.lb.Type) == ELB_TYPE_APPLICATION
+}
+
+func (self *SElbListener) GzipEnabled() bool {
+	return false
+}
+
+func (self *SElbListener) GetCertificateId() string {
+	for _, cert := range self.Certificates {
+		if sdk.BoolValue(cert.IsDefault) || len(self.Certificates) == 1 {
+			return sdk.StringValue(cert.CertificateArn)
+		}
+	}
+	return ""
+}
+
+func (self *SElbListener) GetTLSCipherPolicy() string {
+	policy := sdk.StringValue(self.SslPolicy)
+	for k, v := range elbTLSCipherPolicies {
+		if v == policy {
+			return k
+		}
+	}
+	return ""
+}
+
+func (self *SElbListener) HTTP2Enabled() bool {
+	return sdk.StringValue(self.Protocol) == "HTTPS"
+}
+
+func (self *SElbListener) Start() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElbListener) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElbListener) Sync(listener *cloudprovider.SLoadbalancerListener) error {
+	params := &elbListenerInput{
+		ListenerArn: self.ListenerArn,
+	}
+	params.setListener(listener)
+	if len(listener.BackendGroupID) == 0 {
+		params.DefaultActions = nil
+	}
+	ret := &elbListenersOutput{}
+	err := self.lb.region.elbv2Request("ModifyListener", params, ret)
+	if err != nil {
+		return err
+	}
+	if len(ret.Listeners) > 0 {
+		lis := ret.Listeners[0]
+		lis.lb = self.lb
+		*self = *lis
+	}
+	return self.syncTargetGroup(listener)
+}
+
+func (self *SElbListener) Delete() error {
+	return self.lb.region.elbv2Request("DeleteListener", &elbListenerInput{ListenerArn: self.ListenerArn}, nil)
+}
+
+func (self *SElbListener) GetILoadbalancerListenerRules() ([]cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	rules, err := self.lb.region.GetElbRules(self.GetId(), nil)
+	if err != nil {
+		return nil, err
+	}
+	irules := []cloudprovider.ICloudLoadbalancerListenerRule{}
+	for i := range rules {
+		if sdk.BoolValue(rules[i].IsDefault) {
+			continue
+		}
+		rules[i].listener = self
+		irules = append(irules, &rules[i])
+	}
+	return irules, nil
+}
+
+func (self *SElbListener) GetILoadBalancerListenerRuleById(ruleId string) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	rule, err := self.lb.region.GetElbRule(ruleId)
+	if err != nil {
+		return nil, err
+	}
+	rule.listener = self
+	return rule, nil
+}
+
+func (self *SElbListener) CreateILoadBalancerListenerRule(rule *cloudprovider.SLoadbalancerListenerRule) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	return self.lb.region.CreateElbRule(self, rule)
+}
+
+// 健康检查与会话保持是目标组的属性, 监听器变更时同步到默认目标组
+func (self *SElbListener) syncTargetGroup(listener *cloudprovider.SLoadbalancerListener) error {
+	self.group = nil
+	group := self.getTargetGroup()
+	if group == nil {
+		return nil
+	}
+	params := &elbTargetGroupInput{
+		TargetGroupArn:     group.TargetGroupArn,
+		HealthCheckEnabled: sdk.Bool(listener.HealthCheck != api.LB_BOOL_OFF),
+	}
+	if sdk.StringValue(self.lb.Type) == ELB_TYPE_NETWORK {
+		// 网络型负载均衡的检查间隔只能为10或30秒, 且健康与不健康阈值必须相同
+		interval := int64(30)
+		if listener.HealthCheckInterval > 0 && listener.HealthCheckInterval <= 10 {
+			interval = 10
+		}
+		params.HealthCheckIntervalSeconds = sdk.Int64(interval)
+		if listener.HealthCheckRise > 0 {
+			threshold := elbClamp(listener.HealthCheckRise, 2, 10)
+			params.HealthyThresholdCount = sdk.Int64(threshold)
+			params.UnhealthyThresholdCount = sdk.Int64(threshold)
+		}
+	} else {
+		if listener.HealthCheckInterval > 0 {
+			params.HealthCheckIntervalSeconds = sdk.Int64(elbClamp(listener.HealthCheckInterval, 5, 300))
+		}
+		if listener.HealthCheckTimeout > 0 {
+			// 超时时间必须小于检查间隔
+			timeout := elbClamp(listener.HealthCheckTimeout, 2, 120)
+			if params.HealthCheckIntervalSeconds != nil && timeout >= *params.HealthCheckIntervalSeconds {
+				timeout = *params.HealthCheckIntervalSeconds - 1
+			}
+			params.HealthCheckTimeoutSeconds = sdk.Int64(timeout)
+		}
+		if listener.HealthCheckRise > 0 {
+			params.HealthyThresholdCount = sdk.Int64(elbClamp(listener.HealthCheckRise, 2, 10))
+		}
+		if listener.HealthCheckFail > 0 {
+			params.UnhealthyThresholdCount = sdk.Int64(elbClamp(listener.HealthCheckFail, 2, 10))
+		}
+		if len(listener.HealthCheckURI) > 0 {
+			params.HealthCheckPath = sdk.String(listener.HealthCheckURI)
+		}
+		if len(listener.HealthCheckHttpCode) > 0 {
+			params.Matcher = &SElbMatcher{HttpCode: sdk.String(elbHealthCheckCodeToMatcher(listener.HealthCheckHttpCode))}
+		}
+	}
+	err := self.lb.region.elbv2Request("ModifyTargetGroup", params, nil)
+	if err != nil {
+		return err
+	}
+	if sdk.StringValue(self.lb.Type) != ELB_TYPE_APPLICATION {
+		return nil
+	}
+	attrs := map[string]string{
+		"stickiness.enabled": "false",
+	}
+	if listener.StickySession == api.LB_BOOL_ON {
+		attrs["stickiness.enabled"] = "true"
+		attrs["stickiness.type"] = "lb_cookie"
+		if listener.StickySessionCookieTimeout > 0 {
+			attrs["stickiness.lb_cookie.duration_seconds"] = strconv.Itoa(int(elbClamp(listener.StickySessionCookieTimeout, 1, 604800)))
+		}
+	}
+	return self.lb.region.ModifyElbTargetGroupAttributes(group.GetId(), attrs)
+}
+
+type elbListenerInput struct {
+	LoadBalancerArn *string
+	ListenerArn     *string
+	Protocol        *string
+	Port            *int64
+	SslPolicy       *string
+	Certificates    []*SElbListenerCertificate
+	DefaultActions  []*SElbAction
+}
+
+type elbDescribeListenersInput struct {
+	LoadBalancerArn *string
+	ListenerArns    []*string
+	Marker          *string
+	PageSize        *int64
+}
+
+type elbListenersOutput struct {
+	Listeners  []*SElbListener
+	NextMarker *string
+}
+
+func (self *elbListenerInput) setListener(listener *cloudprovider.SLoadbalancerListener) {
+	self.Port = sdk.Int64(int64(listener.ListenerPort))
+	switch listener.ListenerType {
+	case api.LB_LISTENER_TYPE_HTTP:
+		self.Protocol = sdk.String("HTTP")
+	case api.LB_LISTENER_TYPE_HTTPS:
+		self.Protocol = sdk.String("HTTPS")
+	case api.LB_LISTENER_TYPE_UDP:
+		self.Protocol = sdk.String("UDP")
+	default:
+		self.Protocol = sdk.String("TCP")
+	}
+	if listener.ListenerType == api.LB_LISTENER_TYPE_HTTPS {
+		if len(listener.CertificateID) > 0 {
+			self.Certificates = []*SElbListenerCertificate{{CertificateArn: sdk.String(listener.CertificateID)}}
+		}
+		if policy, ok := elbTLSCipherPolicies[listener.TLSCipherPolicy]; ok {
+			self.SslPolicy = sdk.String(policy)
+		}
+	}
+	self.DefaultActions = []*SElbAction{
+		{
+			Type:           sdk.String("forward"),
+			TargetGroupArn: sdk.String(listener.BackendGroupID),
+		},
+	}
+}
+
+func elbClamp(v, min, max int) int64 {
+	if v < min {
+		v = min
+	} else if v > max {
+		v = max
+	}
+	return int64(v)
+}
+
+// http_2xx,http_3xx => 200-399, 应用型负载均衡只支持200-499
+func elbHealthCheckCodeToMatcher(codes string) string {
+	low, high := 0, 0
+	for _, code := range strings.Split(codes, ",") {
+		code = strings.TrimSpace(code)
+		if len(code) != len("http_2xx") {
+			continue
+		}
+		c := int(code[5]-'0') * 100
+		if c < 200 || c > 400 {
+			continue
+		}
+		if low == 0 || c < low {
+			low = c
+		}
+		if c+99 > high {
+			high = c + 99
+		}
+	}
+	if low == 0 {
+		return "200-399"
+	}
+	return fmt.Sprintf("%d-%d", low, high)
+}
+
+// 200,302 或 200-399 => http_2xx,http_3xx
+func elbMatcherToHealthCheckCode(matcher string) string {
+	classes := map[int]bool{}
+	for _, seg := range strings.Split(matcher, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(seg), "-", 2)
+		low, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		high := low
+		if len(bounds) == 2 {
+			high, err = strconv.Atoi(bounds[1])
+			if err != nil {
+				continue
+			}
+		}
+		for c := low / 100; c <= high/100; c++ {
+			classes[c] = true
+		}
+	}
+	codes := []string{}
+	for c := 1; c <= 5; c++ {
+		if classes[c] {
+			codes = append(codes, fmt.Sprintf("http_%dxx", c))
+		}
+	}
+	return strings.Join(codes, ",")
+}
+
+func (self *SRegion) GetElbListeners(lbArn string, arns []string) ([]SElbListener, error) {
+	params := &elbDescribeListenersInput{PageSize: sdk.Int64(400)}
+	if len(lbArn) > 0 {
+		params.LoadBalancerArn = sdk.String(lbArn)
+	}
+	if len(arns) > 0 {
+		params.ListenerArns = sdk.StringSlice(arns)
+	}
+	listeners := []SElbListener{}
+	for {
+		ret := &elbListenersOutput{}
+		err := self.elbv2Request("DescribeListeners", params, ret)
+		if err != nil {
+			if isAwsErrorCode(err, "ListenerNotFound", "LoadBalancerNotFound") {
+				return nil, cloudprovider.ErrNotFound
+			}
+			return nil, err
+		}
+		for _, listener := range ret.Listeners {
+			listeners = append(listeners, *listener)
+		}
+		if len(sdk.StringValue(ret.NextMarker)) == 0 {
+			break
+		}
+		params.Marker = ret.NextMarker
+	}
+	return listeners, nil
+}
+
+func (self *SRegion) GetElbListener(arn string) (*SElbListener, error) {
+	listeners, err := self.GetElbListeners("", []string{arn})
+	if err != nil {
+		return nil, err
+	}
+	if len(listeners) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &listeners[0], nil
+}
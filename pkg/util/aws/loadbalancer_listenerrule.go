@@ -0,0 +1,242 @@
+package aws
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sdk "github.com/aws/aws-sdk-go/aws"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SElbRuleCondition struct {
+	Field  *string
+	Values []*string
+}
+
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_Rule.html
+type SElbRule struct {
+	listener *SElbListener
+
+	RuleArn    *string
+	Priority   *string
+	IsDefault  *bool
+	Conditions []*SElbRuleCondition
+	Actions    []*SElbAction
+}
+
+func (self *SElbRule) GetId() string {
+	return sdk.StringValue(self.RuleArn)
+}
+
+func (self *SElbRule) GetName() string {
+	return fmt.Sprintf("rule-%s", sdk.StringValue(self.Priority))
+}
+
+func (self *SElbRule) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SElbRule) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbRule) Refresh() error {
+	rule, err := self.listener.lb.region.GetElbRule(self.GetId())
+	if err != nil {
+		return err
+	}
+	rule.listener = self.listener
+	*self = *rule
+	return nil
+}
+
+func (self *SElbRule) IsEmulated() bool {
+	return false
+}
+
+func (self *SElbRule) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SElbRule) GetProjectId() string {
+	return ""
+}
+
+func (self *SElbRule) getCondition(field string) string {
+	for _, cond := range self.Conditions {
+		if sdk.StringValue(cond.Field) == field && len(cond.Values) > 0 {
+			return sdk.StringValue(cond.Values[0])
+		}
+	}
+	return ""
+}
+
+func (self *SElbRule) GetDomain() string {
+	return self.getCondition("host-header")
+}
+
+// 规则的路径为前缀匹配, 对应aws路径模式末尾的通配符
+func (self *SElbRule) GetPath() string {
+	return strings.TrimSuffix(self.getCondition("path-pattern"), "*")
+}
+
+func (self *SElbRule) GetBackendGroupId() string {
+	for _, action := range self.Actions {
+		if sdk.StringValue(action.Type) == "forward" {
+			return sdk.StringValue(action.TargetGroupArn)
+		}
+	}
+	return ""
+}
+
+func (self *SElbRule) Delete() error {
+	return self.listener.lb.region.elbv2Request("DeleteRule", &elbRuleInput{RuleArn: self.RuleArn}, nil)
+}
+
+type elbRuleInput struct {
+	RuleArn     *string
+	ListenerArn *string
+	Priority    *int64
+	Conditions  []*SElbRuleCondition
+	Actions     []*SElbAction
+}
+
+type elbDescribeRulesInput struct {
+	ListenerArn *string
+	RuleArns    []*string
+	Marker      *string
+	PageSize    *int64
+}
+
+type elbRulesOutput struct {
+	Rules      []*SElbRule
+	NextMarker *string
+}
+
+func (self *SRegion) GetElbRules(listenerArn string, arns []string) ([]SElbRule, error) {
+	params := &elbDescribeRulesInput{PageSize: sdk.Int64(400)}
+	if len(listenerArn) > 0 {
+		params.ListenerArn = sdk.String(listenerArn)
+	}
+	if len(arns) > 0 {
+		params.RuleArns = sdk.StringSlice(arns)
+	}
+	rules := []SElbRule{}
+	for {
+		ret := &elbRulesOutput{}
+		err := self.elbv2Request("DescribeRules", params, ret)
+		if err != nil {
+			if isAwsErrorCode(err, "RuleNotFound", "ListenerNotFound") {
+				return nil, cloudprovider.ErrNotFound
+			}
+			return nil, err
+		}
+		for _, rule := range ret.Rules {
+			rules = append(rules, *rule)
+		}
+		if len(sdk.StringValue(ret.NextMarker)) == 0 {
+			break
+		}
+		params.Marker = ret.NextMarker
+	}
+	return rules, nil
+}
+
+func (self *SRegion) GetElbRule(arn string) (*SElbRule, error) {
+	rules, err := self.GetElbRules("", []string{arn})
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &rules[0], nil
+}
+
+// 未指定的重定向部分使用aws占位符保持请求原值
+func newElbRuleAction(rule *cloudprovider.SLoadbalancerListenerRule) (*SElbAction, error) {
+	switch rule.Action {
+	case api.LB_RULE_ACTION_REDIRECT:
+		config := &SElbRedirectConfig{
+			Protocol:   sdk.String("#{protocol}"),
+			Host:       sdk.String("#{host}"),
+			Path:       sdk.String("/#{path}"),
+			Query:      sdk.String("#{query}"),
+			Port:       sdk.String("#{port}"),
+			StatusCode: sdk.String(fmt.Sprintf("HTTP_%d", rule.RedirectCode)),
+		}
+		if len(rule.RedirectScheme) > 0 {
+			config.Protocol = sdk.String(strings.ToUpper(rule.RedirectScheme))
+			// 协议变化时使用协议默认端口
+			if rule.RedirectScheme == api.LB_REDIRECT_SCHEME_HTTPS {
+				config.Port = sdk.String("443")
+			} else {
+				config.Port = sdk.String("80")
+			}
+		}
+		if len(rule.RedirectHost) > 0 {
+			config.Host = sdk.String(rule.RedirectHost)
+		}
+		if len(rule.RedirectPath) > 0 {
+			config.Path = sdk.String(rule.RedirectPath)
+		}
+		return &SElbAction{Type: sdk.String("redirect"), RedirectConfig: config}, nil
+	case api.LB_RULE_ACTION_FIXED_RESPONSE:
+		config := &SElbFixedResponseConfig{
+			StatusCode:  sdk.String(strconv.Itoa(rule.FixedResponseCode)),
+			ContentType: sdk.String("text/plain"),
+		}
+		return &SElbAction{Type: sdk.String("fixed-response"), FixedResponseConfig: config}, nil
+	default:
+		if len(rule.BackendGroupID) == 0 {
+			return nil, fmt.Errorf("aws loadbalancer listener rule %s requires a backend group", rule.Name)
+		}
+		return &SElbAction{Type: sdk.String("forward"), TargetGroupArn: sdk.String(rule.BackendGroupID)}, nil
+	}
+}
+
+// 规则优先级不能重复, 新规则排在已有规则之后
+func (self *SRegion) CreateElbRule(listener *SElbListener, rule *cloudprovider.SLoadbalancerListenerRule) (*SElbRule, error) {
+	action, err := newElbRuleAction(rule)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := self.GetElbRules(listener.GetId(), nil)
+	if err != nil {
+		return nil, err
+	}
+	priority := int64(0)
+	for _, r := range rules {
+		p, err := strconv.ParseInt(sdk.StringValue(r.Priority), 10, 64)
+		if err == nil && p > priority {
+			priority = p
+		}
+	}
+	params := &elbRuleInput{
+		ListenerArn: listener.ListenerArn,
+		Priority:    sdk.Int64(priority + 1),
+		Actions:     []*SElbAction{action},
+	}
+	if len(rule.Domain) > 0 {
+		params.Conditions = append(params.Conditions, &SElbRuleCondition{Field: sdk.String("host-header"), Values: []*string{sdk.String(rule.Domain)}})
+	}
+	if len(rule.Path) > 0 {
+		params.Conditions = append(params.Conditions, &SElbRuleCondition{Field: sdk.String("path-pattern"), Values: []*string{sdk.String(rule.Path + "*")}})
+	}
+	ret := &elbRulesOutput{}
+	err = self.elbv2Request("CreateRule", params, ret)
+	if err != nil {
+		return nil, err
+	}
+	if len(ret.Rules) == 0 {
+		return nil, fmt.Errorf("CreateRule returns no rule")
+	}
+	r := ret.Rules[0]
+	r.listener = listener
+	return r, nil
+}
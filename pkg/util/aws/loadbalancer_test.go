@@ -0,0 +1,543 @@
+package aws
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	sdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/private/protocol/xml/xmlutil"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+func unmarshalElbResponse(t *testing.T, body, action string, v interface{}) {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	if err := xmlutil.UnmarshalXML(v, decoder, action+"Result"); err != nil {
+		t.Fatalf("unmarshal %s response: %s", action, err)
+	}
+}
+
+const testElbDescribeLoadBalancersResponse = `<DescribeLoadBalancersResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <DescribeLoadBalancersResult>
+    <LoadBalancers>
+      <member>
+        <LoadBalancerArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/app/my-alb/50dc6c495c0c9188</LoadBalancerArn>
+        <LoadBalancerName>my-alb</LoadBalancerName>
+        <DNSName>my-alb-1234567890.us-west-2.elb.amazonaws.com</DNSName>
+        <Scheme>internet-facing</Scheme>
+        <Type>application</Type>
+        <VpcId>vpc-3ac0fb5f</VpcId>
+        <IpAddressType>ipv4</IpAddressType>
+        <CreatedTime>2016-03-25T21:26:12.920Z</CreatedTime>
+        <State>
+          <Code>active</Code>
+        </State>
+        <AvailabilityZones>
+          <member>
+            <SubnetId>subnet-8360a9e7</SubnetId>
+            <ZoneName>us-west-2a</ZoneName>
+          </member>
+        </AvailabilityZones>
+        <SecurityGroups>
+          <member>sg-5943793c</member>
+        </SecurityGroups>
+      </member>
+      <member>
+        <LoadBalancerArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/net/my-nlb/73e2d6bc24d8a067</LoadBalancerArn>
+        <LoadBalancerName>my-nlb</LoadBalancerName>
+        <Scheme>internal</Scheme>
+        <Type>network</Type>
+        <VpcId>vpc-3ac0fb5f</VpcId>
+        <State>
+          <Code>provisioning</Code>
+        </State>
+        <AvailabilityZones>
+          <member>
+            <SubnetId>subnet-6dfb8e05</SubnetId>
+            <ZoneName>us-west-2b</ZoneName>
+            <LoadBalancerAddresses>
+              <member>
+                <IpAddress>10.0.1.10</IpAddress>
+              </member>
+            </LoadBalancerAddresses>
+          </member>
+        </AvailabilityZones>
+      </member>
+    </LoadBalancers>
+  </DescribeLoadBalancersResult>
+</DescribeLoadBalancersResponse>`
+
+func TestElbParseLoadBalancers(t *testing.T) {
+	ret := &elbDescribeLoadBalancersOutput{}
+	unmarshalElbResponse(t, testElbDescribeLoadBalancersResponse, "DescribeLoadBalancers", ret)
+	if len(ret.LoadBalancers) != 2 {
+		t.Fatalf("want 2 loadbalancers, got %d", len(ret.LoadBalancers))
+	}
+	cases := []struct {
+		name        string
+		status      string
+		address     string
+		addressType string
+		networkId   string
+		spec        string
+	}{
+		{"my-alb", api.LB_STATUS_ENABLED, "", api.LB_ADDR_TYPE_INTERNET, "subnet-8360a9e7", ELB_TYPE_APPLICATION},
+		{"my-nlb", api.LB_STATUS_INIT, "10.0.1.10", api.LB_ADDR_TYPE_INTRANET, "subnet-6dfb8e05", ELB_TYPE_NETWORK},
+	}
+	for i, c := range cases {
+		lb := ret.LoadBalancers[i]
+		if lb.GetName() != c.name {
+			t.Errorf("%d: want name %s, got %s", i, c.name, lb.GetName())
+		}
+		if lb.GetStatus() != c.status {
+			t.Errorf("%s: want status %s, got %s", c.name, c.status, lb.GetStatus())
+		}
+		if lb.GetAddress() != c.address {
+			t.Errorf("%s: want address %q, got %q", c.name, c.address, lb.GetAddress())
+		}
+		if lb.GetAddressType() != c.addressType {
+			t.Errorf("%s: want address type %s, got %s", c.name, c.addressType, lb.GetAddressType())
+		}
+		if lb.GetNetworkId() != c.networkId {
+			t.Errorf("%s: want network %s, got %s", c.name, c.networkId, lb.GetNetworkId())
+		}
+		if lb.GetLoadbalancerSpec() != c.spec {
+			t.Errorf("%s: want spec %s, got %s", c.name, c.spec, lb.GetLoadbalancerSpec())
+		}
+	}
+}
+
+func TestElbStatus(t *testing.T) {
+	cases := []struct {
+		state *SElbState
+		want  string
+	}{
+		{nil, api.LB_STATUS_UNKNOWN},
+		{&SElbState{Code: sdk.String("active")}, api.LB_STATUS_ENABLED},
+		{&SElbState{Code: sdk.String("active_impaired")}, api.LB_STATUS_ENABLED},
+		{&SElbState{Code: sdk.String("provisioning")}, api.LB_STATUS_INIT},
+		{&SElbState{Code: sdk.String("failed")}, api.LB_STATUS_UNKNOWN},
+	}
+	for _, c := range cases {
+		lb := &SElb{State: c.state}
+		if got := lb.GetStatus(); got != c.want {
+			t.Errorf("state %v: want %s, got %s", c.state, c.want, got)
+		}
+	}
+}
+
+const testElbDescribeListenersResponse = `<DescribeListenersResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <DescribeListenersResult>
+    <Listeners>
+      <member>
+        <ListenerArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:listener/app/my-alb/50dc6c495c0c9188/f2f7dc8efc522ab2</ListenerArn>
+        <LoadBalancerArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/app/my-alb/50dc6c495c0c9188</LoadBalancerArn>
+        <Port>443</Port>
+        <Protocol>HTTPS</Protocol>
+        <SslPolicy>ELBSecurityPolicy-TLS-1-2-2017-01</SslPolicy>
+        <Certificates>
+          <member>
+            <CertificateArn>arn:aws:acm:us-west-2:123456789012:certificate/68c0d1ad</CertificateArn>
+          </member>
+        </Certificates>
+        <DefaultActions>
+          <member>
+            <Type>forward</Type>
+            <TargetGroupArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067</TargetGroupArn>
+          </member>
+        </DefaultActions>
+      </member>
+      <member>
+        <ListenerArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:listener/app/my-alb/50dc6c495c0c9188/0467ef3c8400ae65</ListenerArn>
+        <Port>80</Port>
+        <Protocol>HTTP</Protocol>
+        <DefaultActions>
+          <member>
+            <Type>redirect</Type>
+            <RedirectConfig>
+              <Protocol>HTTPS</Protocol>
+              <Port>443</Port>
+              <StatusCode>HTTP_301</StatusCode>
+            </RedirectConfig>
+          </member>
+        </DefaultActions>
+      </member>
+    </Listeners>
+  </DescribeListenersResult>
+</DescribeListenersResponse>`
+
+func TestElbParseListeners(t *testing.T) {
+	ret := &elbListenersOutput{}
+	unmarshalElbResponse(t, testElbDescribeListenersResponse, "DescribeListeners", ret)
+	if len(ret.Listeners) != 2 {
+		t.Fatalf("want 2 listeners, got %d", len(ret.Listeners))
+	}
+	lb := &SElb{Type: sdk.String(ELB_TYPE_APPLICATION)}
+	cases := []struct {
+		name           string
+		listenerType   string
+		port           int
+		backendGroupId string
+		certificateId  string
+		tlsPolicy      string
+	}{
+		{
+			name:           "https:443",
+			listenerType:   api.LB_LISTENER_TYPE_HTTPS,
+			port:           443,
+			backendGroupId: "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067",
+			certificateId:  "arn:aws:acm:us-west-2:123456789012:certificate/68c0d1ad",
+			tlsPolicy:      api.LB_TLS_CIPHER_POLICY_1_2,
+		},
+		{
+			name:         "http:80",
+			listenerType: api.LB_LISTENER_TYPE_HTTP,
+			port:         80,
+		},
+	}
+	for i, c := range cases {
+		listener := ret.Listeners[i]
+		listener.lb = lb
+		if listener.GetName() != c.name {
+			t.Errorf("%d: want name %s, got %s", i, c.name, listener.GetName())
+		}
+		if listener.GetListenerType() != c.listenerType {
+			t.Errorf("%s: want type %s, got %s", c.name, c.listenerType, listener.GetListenerType())
+		}
+		if listener.GetListenerPort() != c.port {
+			t.Errorf("%s: want port %d, got %d", c.name, c.port, listener.GetListenerPort())
+		}
+		if listener.GetBackendGroupId() != c.backendGroupId {
+			t.Errorf("%s: want backend group %q, got %q", c.name, c.backendGroupId, listener.GetBackendGroupId())
+		}
+		if listener.GetCertificateId() != c.certificateId {
+			t.Errorf("%s: want certificate %q, got %q", c.name, c.certificateId, listener.GetCertificateId())
+		}
+		if listener.GetTLSCipherPolicy() != c.tlsPolicy {
+			t.Errorf("%s: want tls cipher policy %q, got %q", c.name, c.tlsPolicy, listener.GetTLSCipherPolicy())
+		}
+	}
+}
+
+func TestElbListenerMapping(t *testing.T) {
+	cases := []struct {
+		lbType       string
+		protocol     string
+		listenerType string
+		scheduler    string
+		xff          bool
+	}{
+		{ELB_TYPE_APPLICATION, "HTTP", api.LB_LISTENER_TYPE_HTTP, api.LB_SCHEDULER_RR, true},
+		{ELB_TYPE_APPLICATION, "HTTPS", api.LB_LISTENER_TYPE_HTTPS, api.LB_SCHEDULER_RR, true},
+		{ELB_TYPE_NETWORK, "TCP", api.LB_LISTENER_TYPE_TCP, api.LB_SCHEDULER_TCH, false},
+		{ELB_TYPE_NETWORK, "TLS", api.LB_LISTENER_TYPE_TCP, api.LB_SCHEDULER_TCH, false},
+		{ELB_TYPE_NETWORK, "UDP", api.LB_LISTENER_TYPE_UDP, api.LB_SCHEDULER_TCH, false},
+	}
+	for _, c := range cases {
+		listener := &SElbListener{
+			lb:       &SElb{Type: sdk.String(c.lbType)},
+			Protocol: sdk.String(c.protocol),
+		}
+		if got := listener.GetListenerType(); got != c.listenerType {
+			t.Errorf("%s %s: want listener type %s, got %s", c.lbType, c.protocol, c.listenerType, got)
+		}
+		if got := listener.GetScheduler(); got != c.scheduler {
+			t.Errorf("%s %s: want scheduler %s, got %s", c.lbType, c.protocol, c.scheduler, got)
+		}
+		if got := listener.XForwardedForEnabled(); got != c.xff {
+			t.Errorf("%s %s: want x-forwarded-for %v, got %v", c.lbType, c.protocol, c.xff, got)
+		}
+	}
+}
+
+func TestElbListenerInput(t *testing.T) {
+	cases := []struct {
+		listener cloudprovider.SLoadbalancerListener
+		protocol string
+		cert     string
+		policy   string
+	}{
+		{
+			listener: cloudprovider.SLoadbalancerListener{ListenerType: api.LB_LISTENER_TYPE_TCP, ListenerPort: 22, BackendGroupID: "tg"},
+			protocol: "TCP",
+		},
+		{
+			listener: cloudprovider.SLoadbalancerListener{ListenerType: api.LB_LISTENER_TYPE_HTTPS, ListenerPort: 443, BackendGroupID: "tg",
+				CertificateID: "cert", TLSCipherPolicy: api.LB_TLS_CIPHER_POLICY_1_1},
+			protocol: "HTTPS",
+			cert:     "cert",
+			policy:   "ELBSecurityPolicy-TLS-1-1-2017-01",
+		},
+	}
+	for _, c := range cases {
+		input := &elbListenerInput{}
+		input.setListener(&c.listener)
+		if got := sdk.StringValue(input.Protocol); got != c.protocol {
+			t.Errorf("%s: want protocol %s, got %s", c.listener.ListenerType, c.protocol, got)
+		}
+		if got := int(sdk.Int64Value(input.Port)); got != c.listener.ListenerPort {
+			t.Errorf("%s: want port %d, got %d", c.listener.ListenerType, c.listener.ListenerPort, got)
+		}
+		cert := ""
+		if len(input.Certificates) > 0 {
+			cert = sdk.StringValue(input.Certificates[0].CertificateArn)
+		}
+		if cert != c.cert {
+			t.Errorf("%s: want certificate %q, got %q", c.listener.ListenerType, c.cert, cert)
+		}
+		if got := sdk.StringValue(input.SslPolicy); got != c.policy {
+			t.Errorf("%s: want ssl policy %q, got %q", c.listener.ListenerType, c.policy, got)
+		}
+		if len(input.DefaultActions) != 1 || sdk.StringValue(input.DefaultActions[0].TargetGroupArn) != c.listener.BackendGroupID {
+			t.Errorf("%s: want forward to %s, got %#v", c.listener.ListenerType, c.listener.BackendGroupID, input.DefaultActions)
+		}
+	}
+}
+
+func TestElbHealthCheckCode(t *testing.T) {
+	toMatcher := []struct {
+		codes string
+		want  string
+	}{
+		{"", "200-399"},
+		{"http_2xx", "200-299"},
+		{"http_2xx,http_3xx", "200-399"},
+		{"http_3xx,http_4xx", "300-499"},
+		{"http_1xx,http_5xx", "200-399"},
+	}
+	for _, c := range toMatcher {
+		if got := elbHealthCheckCodeToMatcher(c.codes); got != c.want {
+			t.Errorf("codes %q: want matcher %s, got %s", c.codes, c.want, got)
+		}
+	}
+	toCodes := []struct {
+		matcher string
+		want    string
+	}{
+		{"200", "http_2xx"},
+		{"200,302", "http_2xx,http_3xx"},
+		{"200-399", "http_2xx,http_3xx"},
+		{"200-499", "http_2xx,http_3xx,http_4xx"},
+		{"bad", ""},
+	}
+	for _, c := range toCodes {
+		if got := elbMatcherToHealthCheckCode(c.matcher); got != c.want {
+			t.Errorf("matcher %q: want codes %s, got %s", c.matcher, c.want, got)
+		}
+	}
+}
+
+const testElbDescribeRulesResponse = `<DescribeRulesResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <DescribeRulesResult>
+    <Rules>
+      <member>
+        <RuleArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:listener-rule/app/my-alb/50dc6c495c0c9188/f2f7dc8efc522ab2/9683b2d02a6cabee</RuleArn>
+        <Priority>10</Priority>
+        <IsDefault>false</IsDefault>
+        <Conditions>
+          <member>
+            <Field>host-header</Field>
+            <Values>
+              <member>www.example.com</member>
+            </Values>
+          </member>
+          <member>
+            <Field>path-pattern</Field>
+            <Values>
+              <member>/img/*</member>
+            </Values>
+          </member>
+        </Conditions>
+        <Actions>
+          <member>
+            <Type>forward</Type>
+            <TargetGroupArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/img/73e2d6bc24d8a067</TargetGroupArn>
+          </member>
+        </Actions>
+      </member>
+      <member>
+        <RuleArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:listener-rule/app/my-alb/50dc6c495c0c9188/f2f7dc8efc522ab2/5b7a3e2d1c6f8a90</RuleArn>
+        <Priority>20</Priority>
+        <IsDefault>false</IsDefault>
+        <Conditions>
+          <member>
+            <Field>path-pattern</Field>
+            <Values>
+              <member>/old*</member>
+            </Values>
+          </member>
+        </Conditions>
+        <Actions>
+          <member>
+            <Type>fixed-response</Type>
+            <FixedResponseConfig>
+              <StatusCode>404</StatusCode>
+            </FixedResponseConfig>
+          </member>
+        </Actions>
+      </member>
+    </Rules>
+  </DescribeRulesResult>
+</DescribeRulesResponse>`
+
+func TestElbParseRules(t *testing.T) {
+	ret := &elbRulesOutput{}
+	unmarshalElbResponse(t, testElbDescribeRulesResponse, "DescribeRules", ret)
+	if len(ret.Rules) != 2 {
+		t.Fatalf("want 2 rules, got %d", len(ret.Rules))
+	}
+	cases := []struct {
+		name           string
+		domain         string
+		path           string
+		backendGroupId string
+	}{
+		{"rule-10", "www.example.com", "/img/", "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/img/73e2d6bc24d8a067"},
+		{"rule-20", "", "/old", ""},
+	}
+	for i, c := range cases {
+		rule := ret.Rules[i]
+		if rule.GetName() != c.name {
+			t.Errorf("%d: want name %s, got %s", i, c.name, rule.GetName())
+		}
+		if rule.GetDomain() != c.domain {
+			t.Errorf("%s: want domain %q, got %q", c.name, c.domain, rule.GetDomain())
+		}
+		if rule.GetPath() != c.path {
+			t.Errorf("%s: want path %q, got %q", c.name, c.path, rule.GetPath())
+		}
+		if rule.GetBackendGroupId() != c.backendGroupId {
+			t.Errorf("%s: want backend group %q, got %q", c.name, c.backendGroupId, rule.GetBackendGroupId())
+		}
+	}
+}
+
+func TestElbRuleAction(t *testing.T) {
+	cases := []struct {
+		name     string
+		rule     cloudprovider.SLoadbalancerListenerRule
+		wantType string
+		wantErr  bool
+		check    func(action *SElbAction) bool
+	}{
+		{
+			name:     "forward",
+			rule:     cloudprovider.SLoadbalancerListenerRule{BackendGroupID: "tg"},
+			wantType: "forward",
+			check: func(action *SElbAction) bool {
+				return sdk.StringValue(action.TargetGroupArn) == "tg"
+			},
+		},
+		{
+			name:    "forward without backend group",
+			rule:    cloudprovider.SLoadbalancerListenerRule{Action: api.LB_RULE_ACTION_FORWARD},
+			wantErr: true,
+		},
+		{
+			name:     "redirect to https",
+			rule:     cloudprovider.SLoadbalancerListenerRule{Action: api.LB_RULE_ACTION_REDIRECT, RedirectCode: 301, RedirectScheme: api.LB_REDIRECT_SCHEME_HTTPS},
+			wantType: "redirect",
+			check: func(action *SElbAction) bool {
+				config := action.RedirectConfig
+				return sdk.StringValue(config.Protocol) == "HTTPS" && sdk.StringValue(config.Port) == "443" &&
+					sdk.StringValue(config.Host) == "#{host}" && sdk.StringValue(config.StatusCode) == "HTTP_301"
+			},
+		},
+		{
+			name:     "fixed response",
+			rule:     cloudprovider.SLoadbalancerListenerRule{Action: api.LB_RULE_ACTION_FIXED_RESPONSE, FixedResponseCode: 503},
+			wantType: "fixed-response",
+			check: func(action *SElbAction) bool {
+				return sdk.StringValue(action.FixedResponseConfig.StatusCode) == "503"
+			},
+		},
+	}
+	for _, c := range cases {
+		action, err := newElbRuleAction(&c.rule)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: want error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err)
+			continue
+		}
+		if got := sdk.StringValue(action.Type); got != c.wantType {
+			t.Errorf("%s: want action type %s, got %s", c.name, c.wantType, got)
+			continue
+		}
+		if !c.check(action) {
+			t.Errorf("%s: unexpected action %#v", c.name, action)
+		}
+	}
+}
+
+func TestClassicElbListenerMapping(t *testing.T) {
+	cases := []struct {
+		protocol        string
+		target          string
+		listenerType    string
+		healthCheckType string
+		healthCheckURI  string
+	}{
+		{"HTTP", "HTTP:80/index.html", api.LB_LISTENER_TYPE_HTTP, api.LB_HEALTH_CHECK_HTTP, "/index.html"},
+		{"HTTPS", "HTTPS:443/", api.LB_LISTENER_TYPE_HTTPS, api.LB_HEALTH_CHECK_HTTP, "/"},
+		{"TCP", "TCP:22", api.LB_LISTENER_TYPE_TCP, api.LB_HEALTH_CHECK_TCP, ""},
+		{"SSL", "SSL:443", api.LB_LISTENER_TYPE_TCP, api.LB_HEALTH_CHECK_TCP, ""},
+	}
+	for _, c := range cases {
+		listener := &SClassicElbListener{
+			lb: &SClassicElb{HealthCheck: &SClassicElbHealthCheck{Target: sdk.String(c.target)}},
+			SClassicElbListenerDetail: SClassicElbListenerDetail{
+				Protocol: sdk.String(c.protocol),
+			},
+		}
+		if got := listener.GetListenerType(); got != c.listenerType {
+			t.Errorf("%s: want listener type %s, got %s", c.protocol, c.listenerType, got)
+		}
+		if got := listener.GetHealthCheckType(); got != c.healthCheckType {
+			t.Errorf("%s: want health check type %s, got %s", c.target, c.healthCheckType, got)
+		}
+		if got := listener.GetHealthCheckURI(); got != c.healthCheckURI {
+			t.Errorf("%s: want health check uri %q, got %q", c.target, c.healthCheckURI, got)
+		}
+	}
+}
+
+func TestClassicElbListenerDetail(t *testing.T) {
+	cases := []struct {
+		listener         cloudprovider.SLoadbalancerListener
+		protocol         string
+		instanceProtocol string
+		instancePort     int64
+	}{
+		{
+			listener:         cloudprovider.SLoadbalancerListener{ListenerType: api.LB_LISTENER_TYPE_TCP, ListenerPort: 22},
+			protocol:         "TCP",
+			instanceProtocol: "TCP",
+			instancePort:     22,
+		},
+		{
+			listener:         cloudprovider.SLoadbalancerListener{ListenerType: api.LB_LISTENER_TYPE_HTTPS, ListenerPort: 443, BackendServerPort: 8080},
+			protocol:         "HTTPS",
+			instanceProtocol: "HTTP",
+			instancePort:     8080,
+		},
+	}
+	for _, c := range cases {
+		detail := newClassicElbListenerDetail(&c.listener)
+		if got := sdk.StringValue(detail.Protocol); got != c.protocol {
+			t.Errorf("%s: want protocol %s, got %s", c.listener.ListenerType, c.protocol, got)
+		}
+		if got := sdk.StringValue(detail.InstanceProtocol); got != c.instanceProtocol {
+			t.Errorf("%s: want instance protocol %s, got %s", c.listener.ListenerType, c.instanceProtocol, got)
+		}
+		if got := sdk.Int64Value(detail.InstancePort); got != c.instancePort {
+			t.Errorf("%s: want instance port %d, got %d", c.listener.ListenerType, c.instancePort, got)
+		}
+	}
+}
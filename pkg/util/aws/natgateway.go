@@ -0,0 +1,221 @@
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/utils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+type SNatGatewayAddress struct {
+	AllocationId       string
+	NetworkInterfaceId string
+	PrivateIp          string
+	PublicIp           string
+}
+
+type SNatGateway struct {
+	vpc *SVpc
+
+	NatGatewayId        string
+	Name                string
+	State               string
+	VpcId               string
+	SubnetId            string
+	CreateTime          time.Time
+	NatGatewayAddresses []SNatGatewayAddress
+}
+
+func (self *SNatGateway) GetId() string {
+	return self.NatGatewayId
+}
+
+func (self *SNatGateway) GetName() string {
+	if len(self.Name) > 0 {
+		return self.Name
+	}
+	return self.NatGatewayId
+}
+
+func (self *SNatGateway) GetGlobalId() string {
+	return self.NatGatewayId
+}
+
+func (self *SNatGateway) GetStatus() string {
+	switch self.State {
+	case ec2.NatGatewayStatePending:
+		return api.NAT_STATUS_ALLOCATE
+	case ec2.NatGatewayStateAvailable:
+		return api.NAT_STATUS_AVAILABLE
+	case ec2.NatGatewayStateDeleting:
+		return api.NAT_STATUS_DELETING
+	case ec2.NatGatewayStateDeleted:
+		return api.NAT_STATUS_DELETED
+	case ec2.NatGatewayStateFailed:
+		return api.NAT_STATUS_CREATE_FAILED
+	default:
+		return api.NAT_STATUS_UNKNOWN
+	}
+}
+
+func (self *SNatGateway) Refresh() error {
+	new, err := self.vpc.region.GetNatGateway(self.NatGatewayId)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, new)
+}
+
+func (self *SNatGateway) IsEmulated() bool {
+	return false
+}
+
+func (self *SNatGateway) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SNatGateway) GetBillingType() string {
+	return models.BILLING_TYPE_POSTPAID
+}
+
+func (self *SNatGateway) GetExpiredAt() time.Time {
+	return time.Time{}
+}
+
+// AWS NAT网关没有规格之分, 带宽自动扩展
+func (self *SNatGateway) GetNatSpec() string {
+	return ""
+}
+
+// AWS NAT网关通过路由表实现SNAT, 不支持DNAT
+func (self *SNatGateway) GetINatSEntries() ([]cloudprovider.ICloudNatSEntry, error) {
+	return []cloudprovider.ICloudNatSEntry{}, nil
+}
+
+func (self *SNatGateway) GetINatDEntries() ([]cloudprovider.ICloudNatDEntry, error) {
+	return []cloudprovider.ICloudNatDEntry{}, nil
+}
+
+func (self *SNatGateway) GetINatSEntryById(id string) (cloudprovider.ICloudNatSEntry, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SNatGateway) GetINatDEntryById(id string) (cloudprovider.ICloudNatDEntry, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SNatGateway) CreateINatSEntry(rule cloudprovider.SNatSRule) (cloudprovider.ICloudNatSEntry, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SNatGateway) CreateINatDEntry(rule cloudprovider.SNatDRule) (cloudprovider.ICloudNatDEntry, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SNatGateway) Delete() error {
+	return self.vpc.region.DeleteNatGateway(self.NatGatewayId)
+}
+
+func (self *SRegion) GetNatGateways(vpcId string, natGatewayIds []string) ([]SNatGateway, error) {
+	params := &ec2.DescribeNatGatewaysInput{}
+	if len(natGatewayIds) > 0 {
+		params.SetNatGatewayIds(ConvertedList(natGatewayIds))
+	}
+	if len(vpcId) > 0 {
+		params.SetFilter(AppendSingleValueFilter([]*ec2.Filter{}, "vpc-id", vpcId))
+	}
+
+	gateways := make([]SNatGateway, 0)
+	err := self.ec2Client.DescribeNatGatewaysPages(params, func(page *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+		for _, gateway := range page.NatGateways {
+			// 已删除的NAT网关会保留一段时间
+			if StrVal(gateway.State) == ec2.NatGatewayStateDeleted {
+				continue
+			}
+			tagspec := TagSpec{ResourceType: "natgateway"}
+			tagspec.LoadingEc2Tags(gateway.Tags)
+			nat := SNatGateway{
+				NatGatewayId: StrVal(gateway.NatGatewayId),
+				Name:         tagspec.GetNameTag(),
+				State:        StrVal(gateway.State),
+				VpcId:        StrVal(gateway.VpcId),
+				SubnetId:     StrVal(gateway.SubnetId),
+			}
+			if gateway.CreateTime != nil {
+				nat.CreateTime = *gateway.CreateTime
+			}
+			for _, addr := range gateway.NatGatewayAddresses {
+				nat.NatGatewayAddresses = append(nat.NatGatewayAddresses, SNatGatewayAddress{
+					AllocationId:       StrVal(addr.AllocationId),
+					NetworkInterfaceId: StrVal(addr.NetworkInterfaceId),
+					PrivateIp:          StrVal(addr.PrivateIp),
+					PublicIp:           StrVal(addr.PublicIp),
+				})
+			}
+			gateways = append(gateways, nat)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gateways, nil
+}
+
+func (self *SRegion) GetNatGateway(natGatewayId string) (*SNatGateway, error) {
+	gateways, err := self.GetNatGateways("", []string{natGatewayId})
+	if err != nil {
+		return nil, err
+	}
+	if len(gateways) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &gateways[0], nil
+}
+
+func (self *SVpc) CreateINatGateway(opts *cloudprovider.SNatGatewayCreateOptions) (cloudprovider.ICloudNatGateway, error) {
+	gateway, err := self.region.CreateNatGateway(opts)
+	if err != nil {
+		return nil, err
+	}
+	gateway.vpc = self
+	return gateway, nil
+}
+
+// AWS NAT网关创建在公有子网中, 必须绑定一个EIP
+func (self *SRegion) CreateNatGateway(opts *cloudprovider.SNatGatewayCreateOptions) (*SNatGateway, error) {
+	if len(opts.NetworkId) == 0 || len(opts.EipId) == 0 {
+		return nil, fmt.Errorf("network and eip are required to create nat gateway")
+	}
+	params := &ec2.CreateNatGatewayInput{}
+	params.SetSubnetId(opts.NetworkId)
+	params.SetAllocationId(opts.EipId)
+	params.SetClientToken(utils.GenRequestId(20))
+	ret, err := self.ec2Client.CreateNatGateway(params)
+	if err != nil {
+		return nil, err
+	}
+	natGatewayId := StrVal(ret.NatGateway.NatGatewayId)
+	if len(opts.Name) > 0 {
+		// 名称设置失败不影响NAT网关使用
+		if err := self.addTags(natGatewayId, "Name", opts.Name); err != nil {
+			log.Infof("CreateNatGateway create name tag failed: %s", err)
+		}
+	}
+	return self.GetNatGateway(natGatewayId)
+}
+
+func (self *SRegion) DeleteNatGateway(natGatewayId string) error {
+	params := &ec2.DeleteNatGatewayInput{}
+	params.SetNatGatewayId(natGatewayId)
+	_, err := self.ec2Client.DeleteNatGateway(params)
+	return err
+}
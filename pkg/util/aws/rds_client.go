@@ -0,0 +1,30 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws/client"
+)
+
+// rds 的 sdk 未被 vendor, 与 elb 一样基于 sdk 的 query 协议直接调用接口
+const (
+	RDS_SERVICE_NAME = "rds"
+	RDS_API_VERSION  = "2014-10-31"
+)
+
+func (self *SRegion) getRdsClient() (*client.Client, error) {
+	if self.rdsClient == nil {
+		cli, err := self.newQueryClient(RDS_SERVICE_NAME, RDS_API_VERSION)
+		if err != nil {
+			return nil, err
+		}
+		self.rdsClient = cli
+	}
+	return self.rdsClient, nil
+}
+
+func (self *SRegion) rdsRequest(action string, params interface{}, result interface{}) error {
+	cli, err := self.getRdsClient()
+	if err != nil {
+		return err
+	}
+	return sendRequest(cli, action, params, result)
+}
@@ -2,8 +2,10 @@ package aws
 
 import (
 	"fmt"
+	"strings"
 
 	sdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -45,6 +47,11 @@ type SRegion struct {
 	iamClient *iam.IAM
 	s3Client  *s3.S3
 
+	elbv2Client *client.Client
+	elbClient   *client.Client
+	acmClient   *client.Client
+	rdsClient   *client.Client
+
 	izones []cloudprovider.ICloudZone
 	ivpcs  []cloudprovider.ICloudVpc
 
@@ -465,39 +472,67 @@ func (self *SRegion) CreateInstanceSimple(name string, imgId string, cpu int, me
 }
 
 func (region *SRegion) GetILoadBalancers() ([]cloudprovider.ICloudLoadbalancer, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	lbs, err := region.GetElbs(nil)
+	if err != nil {
+		return nil, err
+	}
+	clbs, err := region.GetClassicElbs(nil)
+	if err != nil {
+		return nil, err
+	}
+	ilbs := []cloudprovider.ICloudLoadbalancer{}
+	for i := range lbs {
+		ilbs = append(ilbs, &lbs[i])
+	}
+	for i := range clbs {
+		ilbs = append(ilbs, &clbs[i])
+	}
+	return ilbs, nil
 }
 
+// 应用型与网络型负载均衡以arn标识, 传统型负载均衡以名称标识
 func (region *SRegion) GetILoadBalancerById(loadbalancerId string) (cloudprovider.ICloudLoadbalancer, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	if strings.HasPrefix(loadbalancerId, "arn:") {
+		return region.GetElb(loadbalancerId)
+	}
+	return region.GetClassicElb(loadbalancerId)
 }
 
 func (region *SRegion) GetILoadBalancerAclById(aclId string) (cloudprovider.ICloudLoadbalancerAcl, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return nil, cloudprovider.ErrNotSupported
 }
 
 func (region *SRegion) GetILoadBalancerCertificateById(certId string) (cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return region.GetAcmCertificate(certId)
 }
 
 func (region *SRegion) CreateILoadBalancerCertificate(cert *cloudprovider.SLoadbalancerCertificate) (cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return region.CreateAcmCertificate(cert)
 }
 
+// aws负载均衡没有访问控制列表, 由安全组实现访问控制
 func (region *SRegion) GetILoadBalancerAcls() ([]cloudprovider.ICloudLoadbalancerAcl, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return []cloudprovider.ICloudLoadbalancerAcl{}, nil
 }
 
 func (region *SRegion) GetILoadBalancerCertificates() ([]cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	certs, err := region.GetAcmCertificates()
+	if err != nil {
+		return nil, err
+	}
+	icerts := make([]cloudprovider.ICloudLoadbalancerCertificate, len(certs))
+	for i := range certs {
+		icerts[i] = &certs[i]
+	}
+	return icerts, nil
 }
 
 func (region *SRegion) CreateILoadBalancer(loadbalancer *cloudprovider.SLoadbalancer) (cloudprovider.ICloudLoadbalancer, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return region.CreateElb(loadbalancer)
 }
 
 func (region *SRegion) CreateILoadBalancerAcl(acl *cloudprovider.SLoadbalancerAccessControlList) (cloudprovider.ICloudLoadbalancerAcl, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return nil, cloudprovider.ErrNotSupported
 }
 
 func (region *SRegion) GetSkus(zoneId string) ([]cloudprovider.ICloudSku, error) {
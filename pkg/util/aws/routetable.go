@@ -0,0 +1,241 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SRoute struct {
+	DestinationCidrBlock   string
+	Origin                 string
+	State                  string
+	GatewayId              string
+	InstanceId             string
+	NatGatewayId           string
+	NetworkInterfaceId     string
+	VpcPeeringConnectionId string
+}
+
+func (self *SRoute) GetType() string {
+	// 创建路由表时自动生成的路由为系统路由
+	if self.Origin == ec2.RouteOriginCreateRouteTable {
+		return "system"
+	}
+	return "custom"
+}
+
+func (self *SRoute) GetCidr() string {
+	return self.DestinationCidrBlock
+}
+
+func (self *SRoute) GetNextHopType() string {
+	switch {
+	case len(self.VpcPeeringConnectionId) > 0:
+		return api.ROUTE_NEXT_HOP_TYPE_VPC_PEERING
+	case len(self.NatGatewayId) > 0:
+		return api.ROUTE_NEXT_HOP_TYPE_NAT_GATEWAY
+	case len(self.InstanceId) > 0:
+		return api.ROUTE_NEXT_HOP_TYPE_INSTANCE
+	case len(self.NetworkInterfaceId) > 0:
+		return api.ROUTE_NEXT_HOP_TYPE_NETWORK_INTERFACE
+	case self.GatewayId == "local":
+		return "local"
+	case len(self.GatewayId) > 0:
+		return api.ROUTE_NEXT_HOP_TYPE_INTERNET_GATEWAY
+	}
+	return ""
+}
+
+func (self *SRoute) GetNextHop() string {
+	switch {
+	case len(self.VpcPeeringConnectionId) > 0:
+		return self.VpcPeeringConnectionId
+	case len(self.NatGatewayId) > 0:
+		return self.NatGatewayId
+	case len(self.InstanceId) > 0:
+		return self.InstanceId
+	case len(self.NetworkInterfaceId) > 0:
+		return self.NetworkInterfaceId
+	}
+	return self.GatewayId
+}
+
+type SRouteTable struct {
+	vpc *SVpc
+
+	RouteTableId string
+	Name         string
+	VpcId        string
+	Main         bool
+	Routes       []SRoute
+}
+
+func (self *SRouteTable) GetId() string {
+	return self.RouteTableId
+}
+
+func (self *SRouteTable) GetName() string {
+	if len(self.Name) > 0 {
+		return self.Name
+	}
+	return self.RouteTableId
+}
+
+func (self *SRouteTable) GetGlobalId() string {
+	return self.RouteTableId
+}
+
+func (self *SRouteTable) GetStatus() string {
+	return ""
+}
+
+func (self *SRouteTable) Refresh() error {
+	routeTable, err := self.vpc.region.GetRouteTable(self.RouteTableId)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, routeTable)
+}
+
+func (self *SRouteTable) IsEmulated() bool {
+	return false
+}
+
+func (self *SRouteTable) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SRouteTable) GetManagerId() string {
+	return self.vpc.region.client.providerId
+}
+
+func (self *SRouteTable) GetDescription() string {
+	return ""
+}
+
+func (self *SRouteTable) GetRegionId() string {
+	return self.vpc.region.RegionId
+}
+
+func (self *SRouteTable) GetVpcId() string {
+	return self.VpcId
+}
+
+func (self *SRouteTable) GetType() string {
+	// 主路由表对应系统路由表
+	if self.Main {
+		return "system"
+	}
+	return "custom"
+}
+
+func (self *SRouteTable) GetIRoutes() ([]cloudprovider.ICloudRoute, error) {
+	iroutes := make([]cloudprovider.ICloudRoute, len(self.Routes))
+	for i := 0; i < len(self.Routes); i++ {
+		iroutes[i] = &self.Routes[i]
+	}
+	return iroutes, nil
+}
+
+func (self *SRouteTable) CreateRoute(route cloudprovider.SRouteSet) error {
+	params := &ec2.CreateRouteInput{}
+	params.SetRouteTableId(self.RouteTableId)
+	params.SetDestinationCidrBlock(route.Destination)
+	switch route.NextHopType {
+	case api.ROUTE_NEXT_HOP_TYPE_VPC_PEERING:
+		params.SetVpcPeeringConnectionId(route.NextHop)
+	case api.ROUTE_NEXT_HOP_TYPE_NAT_GATEWAY:
+		params.SetNatGatewayId(route.NextHop)
+	case api.ROUTE_NEXT_HOP_TYPE_INSTANCE:
+		params.SetInstanceId(route.NextHop)
+	case api.ROUTE_NEXT_HOP_TYPE_NETWORK_INTERFACE:
+		params.SetNetworkInterfaceId(route.NextHop)
+	case api.ROUTE_NEXT_HOP_TYPE_INTERNET_GATEWAY, api.ROUTE_NEXT_HOP_TYPE_VPN_GATEWAY:
+		params.SetGatewayId(route.NextHop)
+	default:
+		return fmt.Errorf("unsupported next hop type %s", route.NextHopType)
+	}
+	_, err := self.vpc.region.ec2Client.CreateRoute(params)
+	if err != nil {
+		return err
+	}
+	return self.Refresh()
+}
+
+func (self *SRouteTable) RemoveRoute(route cloudprovider.SRouteSet) error {
+	params := &ec2.DeleteRouteInput{}
+	params.SetRouteTableId(self.RouteTableId)
+	params.SetDestinationCidrBlock(route.Destination)
+	_, err := self.vpc.region.ec2Client.DeleteRoute(params)
+	if err != nil {
+		return err
+	}
+	return self.Refresh()
+}
+
+func (self *SRegion) GetRouteTables(vpcId string, routeTableIds []string) ([]SRouteTable, error) {
+	params := &ec2.DescribeRouteTablesInput{}
+	if len(routeTableIds) > 0 {
+		params.SetRouteTableIds(ConvertedList(routeTableIds))
+	}
+	if len(vpcId) > 0 {
+		params.SetFilters(AppendSingleValueFilter([]*ec2.Filter{}, "vpc-id", vpcId))
+	}
+
+	routeTables := make([]SRouteTable, 0)
+	err := self.ec2Client.DescribeRouteTablesPages(params, func(page *ec2.DescribeRouteTablesOutput, lastPage bool) bool {
+		for _, item := range page.RouteTables {
+			tagspec := TagSpec{ResourceType: "route-table"}
+			tagspec.LoadingEc2Tags(item.Tags)
+			routeTable := SRouteTable{
+				RouteTableId: StrVal(item.RouteTableId),
+				Name:         tagspec.GetNameTag(),
+				VpcId:        StrVal(item.VpcId),
+			}
+			for _, assoc := range item.Associations {
+				if assoc.Main != nil && *assoc.Main {
+					routeTable.Main = true
+				}
+			}
+			for _, route := range item.Routes {
+				// 暂不支持IPv6及前缀列表路由
+				if route.DestinationCidrBlock == nil {
+					continue
+				}
+				routeTable.Routes = append(routeTable.Routes, SRoute{
+					DestinationCidrBlock:   StrVal(route.DestinationCidrBlock),
+					Origin:                 StrVal(route.Origin),
+					State:                  StrVal(route.State),
+					GatewayId:              StrVal(route.GatewayId),
+					InstanceId:             StrVal(route.InstanceId),
+					NatGatewayId:           StrVal(route.NatGatewayId),
+					NetworkInterfaceId:     StrVal(route.NetworkInterfaceId),
+					VpcPeeringConnectionId: StrVal(route.VpcPeeringConnectionId),
+				})
+			}
+			routeTables = append(routeTables, routeTable)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return routeTables, nil
+}
+
+func (self *SRegion) GetRouteTable(routeTableId string) (*SRouteTable, error) {
+	routeTables, err := self.GetRouteTables("", []string{routeTableId})
+	if err != nil {
+		return nil, err
+	}
+	if len(routeTables) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &routeTables[0], nil
+}
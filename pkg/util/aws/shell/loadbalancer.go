@@ -0,0 +1,87 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/util/aws"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+func init() {
+	type LoadbalancerListOptions struct {
+		Classic bool `help:"List classic loadbalancers"`
+	}
+	shellutils.R(&LoadbalancerListOptions{}, "lb-list", "List loadbalancers", func(cli *aws.SRegion, args *LoadbalancerListOptions) error {
+		if args.Classic {
+			lbs, err := cli.GetClassicElbs(nil)
+			if err != nil {
+				return err
+			}
+			printList(lbs, len(lbs), 0, 0, []string{})
+			return nil
+		}
+		lbs, err := cli.GetElbs(nil)
+		if err != nil {
+			return err
+		}
+		printList(lbs, len(lbs), 0, 0, []string{})
+		return nil
+	})
+
+	type LoadbalancerListenerListOptions struct {
+		LB string `help:"Loadbalancer arn"`
+	}
+	shellutils.R(&LoadbalancerListenerListOptions{}, "lb-listener-list", "List loadbalancer listeners", func(cli *aws.SRegion, args *LoadbalancerListenerListOptions) error {
+		listeners, err := cli.GetElbListeners(args.LB, nil)
+		if err != nil {
+			return err
+		}
+		printList(listeners, len(listeners), 0, 0, []string{})
+		return nil
+	})
+
+	type LoadbalancerListenerRuleListOptions struct {
+		LISTENER string `help:"Listener arn"`
+	}
+	shellutils.R(&LoadbalancerListenerRuleListOptions{}, "lb-listener-rule-list", "List loadbalancer listener rules", func(cli *aws.SRegion, args *LoadbalancerListenerRuleListOptions) error {
+		rules, err := cli.GetElbRules(args.LISTENER, nil)
+		if err != nil {
+			return err
+		}
+		printList(rules, len(rules), 0, 0, []string{})
+		return nil
+	})
+
+	type LoadbalancerTargetGroupListOptions struct {
+		LB string `help:"Loadbalancer arn"`
+	}
+	shellutils.R(&LoadbalancerTargetGroupListOptions{}, "lb-target-group-list", "List loadbalancer target groups", func(cli *aws.SRegion, args *LoadbalancerTargetGroupListOptions) error {
+		groups, err := cli.GetElbTargetGroups(args.LB, nil)
+		if err != nil {
+			return err
+		}
+		printList(groups, len(groups), 0, 0, []string{})
+		return nil
+	})
+
+	type LoadbalancerTargetListOptions struct {
+		GROUP string `help:"Target group arn"`
+	}
+	shellutils.R(&LoadbalancerTargetListOptions{}, "lb-target-list", "List targets of target group", func(cli *aws.SRegion, args *LoadbalancerTargetListOptions) error {
+		targets, err := cli.GetElbTargets(args.GROUP)
+		if err != nil {
+			return err
+		}
+		printList(targets, len(targets), 0, 0, []string{})
+		return nil
+	})
+
+	type LoadbalancerCertificateListOptions struct {
+	}
+	shellutils.R(&LoadbalancerCertificateListOptions{}, "lb-cert-list", "List acm certificates", func(cli *aws.SRegion, args *LoadbalancerCertificateListOptions) error {
+		certs, err := cli.GetAcmCertificates()
+		if err != nil {
+			return err
+		}
+		printList(certs, len(certs), 0, 0, []string{})
+		return nil
+	})
+}
@@ -107,10 +107,31 @@ func (self *SVpc) GetISecurityGroups() ([]cloudprovider.ICloudSecurityGroup, err
 }
 
 func (self *SVpc) GetIRouteTables() ([]cloudprovider.ICloudRouteTable, error) {
-	rts := []cloudprovider.ICloudRouteTable{}
+	routeTables, err := self.region.GetRouteTables(self.VpcId, nil)
+	if err != nil {
+		return nil, err
+	}
+	rts := make([]cloudprovider.ICloudRouteTable, len(routeTables))
+	for i := 0; i < len(routeTables); i++ {
+		routeTables[i].vpc = self
+		rts[i] = &routeTables[i]
+	}
 	return rts, nil
 }
 
+func (self *SVpc) GetINatGateways() ([]cloudprovider.ICloudNatGateway, error) {
+	gateways, err := self.region.GetNatGateways(self.VpcId, nil)
+	if err != nil {
+		return nil, err
+	}
+	igateways := make([]cloudprovider.ICloudNatGateway, len(gateways))
+	for i := 0; i < len(gateways); i++ {
+		gateways[i].vpc = self
+		igateways[i] = &gateways[i]
+	}
+	return igateways, nil
+}
+
 func (self *SVpc) GetManagerId() string {
 	return self.region.client.providerId
 }
@@ -0,0 +1,232 @@
+package aws
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SVpcPeering struct {
+	region *SRegion
+
+	VpcPeeringConnectionId string
+	Name                   string
+	Description            string
+	StatusCode             string
+	StatusMessage          string
+
+	RequesterVpcId   string
+	RequesterOwnerId string
+	RequesterRegion  string
+	AccepterVpcId    string
+	AccepterOwnerId  string
+	AccepterRegion   string
+}
+
+func (self *SVpcPeering) GetId() string {
+	return self.VpcPeeringConnectionId
+}
+
+func (self *SVpcPeering) GetName() string {
+	if len(self.Name) > 0 {
+		return self.Name
+	}
+	return self.VpcPeeringConnectionId
+}
+
+func (self *SVpcPeering) GetGlobalId() string {
+	return self.VpcPeeringConnectionId
+}
+
+func (self *SVpcPeering) GetStatus() string {
+	switch self.StatusCode {
+	case ec2.VpcPeeringConnectionStateReasonCodeInitiatingRequest, ec2.VpcPeeringConnectionStateReasonCodeProvisioning:
+		return api.VPC_PEERING_STATUS_CREATING
+	case ec2.VpcPeeringConnectionStateReasonCodePendingAcceptance:
+		return api.VPC_PEERING_STATUS_PENDING_ACCEPT
+	case ec2.VpcPeeringConnectionStateReasonCodeActive:
+		return api.VPC_PEERING_STATUS_ACTIVE
+	case ec2.VpcPeeringConnectionStateReasonCodeRejected:
+		return api.VPC_PEERING_STATUS_REJECTED
+	case ec2.VpcPeeringConnectionStateReasonCodeExpired:
+		return api.VPC_PEERING_STATUS_EXPIRED
+	case ec2.VpcPeeringConnectionStateReasonCodeFailed:
+		return api.VPC_PEERING_STATUS_CREATE_FAILED
+	case ec2.VpcPeeringConnectionStateReasonCodeDeleting, ec2.VpcPeeringConnectionStateReasonCodeDeleted:
+		return api.VPC_PEERING_STATUS_DELETING
+	default:
+		return api.VPC_PEERING_STATUS_UNKNOWN
+	}
+}
+
+func (self *SVpcPeering) Refresh() error {
+	peering, err := self.region.GetVpcPeering(self.VpcPeeringConnectionId)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, peering)
+}
+
+func (self *SVpcPeering) IsEmulated() bool {
+	return false
+}
+
+func (self *SVpcPeering) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SVpcPeering) GetDescription() string {
+	return self.Description
+}
+
+func (self *SVpcPeering) GetVpcId() string {
+	return self.RequesterVpcId
+}
+
+func (self *SVpcPeering) GetPeerVpcId() string {
+	return self.AccepterVpcId
+}
+
+func (self *SVpcPeering) GetPeerAccountId() string {
+	if self.AccepterOwnerId == self.RequesterOwnerId {
+		return ""
+	}
+	return self.AccepterOwnerId
+}
+
+func (self *SVpcPeering) GetPeerRegionId() string {
+	return self.AccepterRegion
+}
+
+// 只能在对端VPC所在账号和区域接受
+func (self *SVpcPeering) Accept() error {
+	params := &ec2.AcceptVpcPeeringConnectionInput{}
+	params.SetVpcPeeringConnectionId(self.VpcPeeringConnectionId)
+	_, err := self.region.ec2Client.AcceptVpcPeeringConnection(params)
+	return err
+}
+
+func (self *SVpcPeering) Delete() error {
+	params := &ec2.DeleteVpcPeeringConnectionInput{}
+	params.SetVpcPeeringConnectionId(self.VpcPeeringConnectionId)
+	_, err := self.region.ec2Client.DeleteVpcPeeringConnection(params)
+	return err
+}
+
+func (self *SRegion) GetVpcPeerings(requesterVpcId string, peeringIds []string) ([]SVpcPeering, error) {
+	params := &ec2.DescribeVpcPeeringConnectionsInput{}
+	if len(peeringIds) > 0 {
+		params.SetVpcPeeringConnectionIds(ConvertedList(peeringIds))
+	}
+	if len(requesterVpcId) > 0 {
+		params.SetFilters(AppendSingleValueFilter([]*ec2.Filter{}, "requester-vpc-info.vpc-id", requesterVpcId))
+	}
+	ret, err := self.ec2Client.DescribeVpcPeeringConnections(params)
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidVpcPeeringConnectionID.NotFound") {
+			return nil, cloudprovider.ErrNotFound
+		}
+		return nil, err
+	}
+
+	peerings := make([]SVpcPeering, 0)
+	for _, item := range ret.VpcPeeringConnections {
+		peering := SVpcPeering{
+			region:                 self,
+			VpcPeeringConnectionId: StrVal(item.VpcPeeringConnectionId),
+		}
+		if item.Status != nil {
+			peering.StatusCode = StrVal(item.Status.Code)
+			peering.StatusMessage = StrVal(item.Status.Message)
+		}
+		// 已删除的对等连接会保留一段时间
+		if peering.StatusCode == ec2.VpcPeeringConnectionStateReasonCodeDeleted {
+			continue
+		}
+		if item.RequesterVpcInfo != nil {
+			peering.RequesterVpcId = StrVal(item.RequesterVpcInfo.VpcId)
+			peering.RequesterOwnerId = StrVal(item.RequesterVpcInfo.OwnerId)
+			peering.RequesterRegion = StrVal(item.RequesterVpcInfo.Region)
+		}
+		if item.AccepterVpcInfo != nil {
+			peering.AccepterVpcId = StrVal(item.AccepterVpcInfo.VpcId)
+			peering.AccepterOwnerId = StrVal(item.AccepterVpcInfo.OwnerId)
+			peering.AccepterRegion = StrVal(item.AccepterVpcInfo.Region)
+		}
+		tagspec := TagSpec{ResourceType: "vpc-peering-connection"}
+		tagspec.LoadingEc2Tags(item.Tags)
+		peering.Name = tagspec.GetNameTag()
+		peering.Description = tagspec.GetDescTag()
+		peerings = append(peerings, peering)
+	}
+	return peerings, nil
+}
+
+func (self *SRegion) GetVpcPeering(peeringId string) (*SVpcPeering, error) {
+	peerings, err := self.GetVpcPeerings("", []string{peeringId})
+	if err != nil {
+		return nil, err
+	}
+	if len(peerings) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &peerings[0], nil
+}
+
+func (self *SRegion) CreateVpcPeering(vpcId string, opts *cloudprovider.SVpcPeeringCreateOptions) (*SVpcPeering, error) {
+	params := &ec2.CreateVpcPeeringConnectionInput{}
+	params.SetVpcId(vpcId)
+	params.SetPeerVpcId(opts.PeerVpcId)
+	if len(opts.PeerAccountId) > 0 {
+		params.SetPeerOwnerId(opts.PeerAccountId)
+	}
+	if len(opts.PeerRegionId) > 0 && opts.PeerRegionId != self.RegionId {
+		params.SetPeerRegion(opts.PeerRegionId)
+	}
+	ret, err := self.ec2Client.CreateVpcPeeringConnection(params)
+	if err != nil {
+		return nil, err
+	}
+	peeringId := StrVal(ret.VpcPeeringConnection.VpcPeeringConnectionId)
+
+	tagspec := TagSpec{ResourceType: "vpc-peering-connection"}
+	tagspec.SetNameTag(opts.Name)
+	if len(opts.Desc) > 0 {
+		tagspec.SetDescTag(opts.Desc)
+	}
+	tags, _ := tagspec.GetTagSpecifications()
+	tagParams := &ec2.CreateTagsInput{}
+	tagParams.SetResources([]*string{&peeringId})
+	tagParams.SetTags(tags.Tags)
+	// 标签设置失败不影响对等连接的使用
+	if _, err := self.ec2Client.CreateTags(tagParams); err != nil {
+		log.Errorf("CreateVpcPeering create tags for %s failed: %s", peeringId, err)
+	}
+	return self.GetVpcPeering(peeringId)
+}
+
+func (self *SVpc) GetIVpcPeerings() ([]cloudprovider.ICloudVpcPeering, error) {
+	peerings, err := self.region.GetVpcPeerings(self.VpcId, nil)
+	if err != nil {
+		return nil, err
+	}
+	ipeerings := make([]cloudprovider.ICloudVpcPeering, len(peerings))
+	for i := 0; i < len(peerings); i++ {
+		ipeerings[i] = &peerings[i]
+	}
+	return ipeerings, nil
+}
+
+func (self *SVpc) GetIVpcPeeringById(id string) (cloudprovider.ICloudVpcPeering, error) {
+	return self.region.GetVpcPeering(id)
+}
+
+func (self *SVpc) CreateIVpcPeering(opts *cloudprovider.SVpcPeeringCreateOptions) (cloudprovider.ICloudVpcPeering, error) {
+	return self.region.CreateVpcPeering(self.VpcId, opts)
+}
@@ -0,0 +1,410 @@
+package azure
+
+import (
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SAppGatewaySku struct {
+	Name     string `json:"name,omitempty"`
+	Tier     string `json:"tier,omitempty"`
+	Capacity int    `json:"capacity,omitempty"`
+}
+
+type SAppGatewaySslPolicy struct {
+	PolicyType         string `json:"policyType,omitempty"`
+	PolicyName         string `json:"policyName,omitempty"`
+	MinProtocolVersion string `json:"minProtocolVersion,omitempty"`
+}
+
+type SAppGatewayIPConfiguration struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Properties struct {
+		Subnet *SubResource `json:"subnet,omitempty"`
+	} `json:"properties,omitempty"`
+}
+
+type SAppGatewayFrontendPort struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Properties struct {
+		Port int `json:"port,omitempty"`
+	} `json:"properties,omitempty"`
+}
+
+type SAppGatewayProbeMatch struct {
+	StatusCodes []string `json:"statusCodes,omitempty"`
+}
+
+type SAppGatewayProbe struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Properties struct {
+		Protocol           string                 `json:"protocol,omitempty"`
+		Host               string                 `json:"host,omitempty"`
+		Path               string                 `json:"path,omitempty"`
+		Interval           int                    `json:"interval,omitempty"`
+		Timeout            int                    `json:"timeout,omitempty"`
+		UnhealthyThreshold int                    `json:"unhealthyThreshold,omitempty"`
+		Match              *SAppGatewayProbeMatch `json:"match,omitempty"`
+	} `json:"properties,omitempty"`
+}
+
+type SAppGatewayBackendHttpSettings struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Properties struct {
+		Port                int          `json:"port,omitempty"`
+		Protocol            string       `json:"protocol,omitempty"`
+		CookieBasedAffinity string       `json:"cookieBasedAffinity,omitempty"`
+		AffinityCookieName  string       `json:"affinityCookieName,omitempty"`
+		RequestTimeout      int          `json:"requestTimeout,omitempty"`
+		Probe               *SubResource `json:"probe,omitempty"`
+	} `json:"properties,omitempty"`
+}
+
+type SAppGatewayHttpListenerProperties struct {
+	FrontendIPConfiguration *SubResource `json:"frontendIPConfiguration,omitempty"`
+	FrontendPort            *SubResource `json:"frontendPort,omitempty"`
+	Protocol                string       `json:"protocol,omitempty"`
+	HostName                string       `json:"hostName,omitempty"`
+	SslCertificate          *SubResource `json:"sslCertificate,omitempty"`
+}
+
+type SAppGatewayPathRuleProperties struct {
+	Paths               []string     `json:"paths,omitempty"`
+	BackendAddressPool  *SubResource `json:"backendAddressPool,omitempty"`
+	BackendHttpSettings *SubResource `json:"backendHttpSettings,omitempty"`
+}
+
+type SAppGatewayUrlPathMap struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Properties struct {
+		DefaultBackendAddressPool  *SubResource          `json:"defaultBackendAddressPool,omitempty"`
+		DefaultBackendHttpSettings *SubResource          `json:"defaultBackendHttpSettings,omitempty"`
+		PathRules                  []SAppGatewayPathRule `json:"pathRules,omitempty"`
+	} `json:"properties,omitempty"`
+}
+
+type SAppGatewayRequestRoutingRule struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Properties struct {
+		RuleType            string       `json:"ruleType,omitempty"`
+		HttpListener        *SubResource `json:"httpListener,omitempty"`
+		BackendAddressPool  *SubResource `json:"backendAddressPool,omitempty"`
+		BackendHttpSettings *SubResource `json:"backendHttpSettings,omitempty"`
+		UrlPathMap          *SubResource `json:"urlPathMap,omitempty"`
+	} `json:"properties,omitempty"`
+}
+
+type SApplicationGatewayProperties struct {
+	ProvisioningState             string                           `json:"provisioningState,omitempty"`
+	OperationalState              string                           `json:"operationalState,omitempty"`
+	Sku                           *SAppGatewaySku                  `json:"sku,omitempty"`
+	SslPolicy                     *SAppGatewaySslPolicy            `json:"sslPolicy,omitempty"`
+	EnableHttp2                   bool                             `json:"enableHttp2,omitempty"`
+	GatewayIPConfigurations       []SAppGatewayIPConfiguration     `json:"gatewayIPConfigurations,omitempty"`
+	SslCertificates               []SAppGatewayCertificate         `json:"sslCertificates,omitempty"`
+	FrontendIPConfigurations      []SFrontendIPConfiguration       `json:"frontendIPConfigurations,omitempty"`
+	FrontendPorts                 []SAppGatewayFrontendPort        `json:"frontendPorts,omitempty"`
+	Probes                        []SAppGatewayProbe               `json:"probes,omitempty"`
+	BackendAddressPools           []SAppGatewayBackendPool         `json:"backendAddressPools,omitempty"`
+	BackendHttpSettingsCollection []SAppGatewayBackendHttpSettings `json:"backendHttpSettingsCollection,omitempty"`
+	HttpListeners                 []SAppGatewayListener            `json:"httpListeners,omitempty"`
+	UrlPathMaps                   []SAppGatewayUrlPathMap          `json:"urlPathMaps,omitempty"`
+	RequestRoutingRules           []SAppGatewayRequestRoutingRule  `json:"requestRoutingRules,omitempty"`
+}
+
+// https://docs.microsoft.com/en-us/rest/api/application-gateway/applicationgateways
+// 七层负载均衡, 监听对应http监听器及其路由规则, 后端服务器组对应后端地址池
+// 应用网关的各项配置相互引用, 只支持同步及后端服务器的增删
+type SApplicationGateway struct {
+	region *SRegion
+
+	ID         string                        `json:"id,omitempty"`
+	Name       string                        `json:"name,omitempty"`
+	Location   string                        `json:"location,omitempty"`
+	Type       string                        `json:"type,omitempty"`
+	Properties SApplicationGatewayProperties `json:"properties,omitempty"`
+}
+
+func (self *SApplicationGateway) GetId() string {
+	return self.ID
+}
+
+func (self *SApplicationGateway) GetName() string {
+	return self.Name
+}
+
+func (self *SApplicationGateway) GetGlobalId() string {
+	return strings.ToLower(self.ID)
+}
+
+func (self *SApplicationGateway) GetStatus() string {
+	switch self.Properties.OperationalState {
+	case "Running":
+		return api.LB_STATUS_ENABLED
+	case "Stopped":
+		return api.LB_STATUS_DISABLED
+	case "Starting", "Stopping":
+		return api.LB_STATUS_INIT
+	default:
+		return api.LB_STATUS_UNKNOWN
+	}
+}
+
+func (self *SApplicationGateway) Refresh() error {
+	gw, err := self.region.GetApplicationGateway(self.ID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, gw)
+}
+
+func (self *SApplicationGateway) IsEmulated() bool {
+	return false
+}
+
+func (self *SApplicationGateway) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SApplicationGateway) GetProjectId() string {
+	return getResourceGroup(self.ID)
+}
+
+// 同时存在公网及私网前端时以公网地址为准
+func (self *SApplicationGateway) getFrontend() *SFrontendIPConfiguration {
+	frontends := self.Properties.FrontendIPConfigurations
+	for i := range frontends {
+		if frontends[i].Properties.PublicIPAddress != nil {
+			return &frontends[i]
+		}
+	}
+	if len(frontends) > 0 {
+		return &frontends[0]
+	}
+	return nil
+}
+
+func (self *SApplicationGateway) GetAddress() string {
+	frontend := self.getFrontend()
+	if frontend == nil {
+		return ""
+	}
+	if frontend.Properties.PublicIPAddress != nil {
+		eip, err := self.region.GetEip(frontend.Properties.PublicIPAddress.ID)
+		if err != nil {
+			log.Errorf("failed to get public ip of application gateway %s: %v", self.Name, err)
+			return ""
+		}
+		return eip.GetIpAddr()
+	}
+	return frontend.Properties.PrivateIPAddress
+}
+
+func (self *SApplicationGateway) GetAddressType() string {
+	if frontend := self.getFrontend(); frontend != nil && frontend.Properties.PublicIPAddress != nil {
+		return api.LB_ADDR_TYPE_INTERNET
+	}
+	return api.LB_ADDR_TYPE_INTRANET
+}
+
+func (self *SApplicationGateway) GetNetworkType() string {
+	return api.LB_NETWORK_TYPE_VPC
+}
+
+func (self *SApplicationGateway) GetNetworkId() string {
+	for _, conf := range self.Properties.GatewayIPConfigurations {
+		if conf.Properties.Subnet != nil {
+			return strings.ToLower(conf.Properties.Subnet.ID)
+		}
+	}
+	return ""
+}
+
+func (self *SApplicationGateway) GetVpcId() string {
+	return subnetVpcId(self.GetNetworkId())
+}
+
+func (self *SApplicationGateway) GetZoneId() string {
+	return self.region.getZoneGlobalId()
+}
+
+func (self *SApplicationGateway) GetLoadbalancerSpec() string {
+	if self.Properties.Sku != nil {
+		return self.Properties.Sku.Name
+	}
+	return ""
+}
+
+func (self *SApplicationGateway) GetChargeType() string {
+	return api.LB_CHARGE_TYPE_BY_TRAFFIC
+}
+
+func (self *SApplicationGateway) Delete() error {
+	return self.region.client.Delete(self.ID)
+}
+
+func (self *SApplicationGateway) Start() error {
+	_, err := self.region.client.PerformAction(self.ID, "start", "")
+	return err
+}
+
+func (self *SApplicationGateway) Stop() error {
+	_, err := self.region.client.PerformAction(self.ID, "stop", "")
+	return err
+}
+
+func (self *SApplicationGateway) GetILoadBalancerListeners() ([]cloudprovider.ICloudLoadbalancerListener, error) {
+	ilisteners := make([]cloudprovider.ICloudLoadbalancerListener, len(self.Properties.HttpListeners))
+	for i := range self.Properties.HttpListeners {
+		self.Properties.HttpListeners[i].gw = self
+		ilisteners[i] = &self.Properties.HttpListeners[i]
+	}
+	return ilisteners, nil
+}
+
+func (self *SApplicationGateway) GetILoadBalancerListenerById(listenerId string) (cloudprovider.ICloudLoadbalancerListener, error) {
+	for i := range self.Properties.HttpListeners {
+		if strings.ToLower(self.Properties.HttpListeners[i].ID) == strings.ToLower(listenerId) {
+			self.Properties.HttpListeners[i].gw = self
+			return &self.Properties.HttpListeners[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SApplicationGateway) CreateILoadBalancerListener(listener *cloudprovider.SLoadbalancerListener) (cloudprovider.ICloudLoadbalancerListener, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SApplicationGateway) GetILoadBalancerBackendGroups() ([]cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	igroups := make([]cloudprovider.ICloudLoadbalancerBackendGroup, len(self.Properties.BackendAddressPools))
+	for i := range self.Properties.BackendAddressPools {
+		self.Properties.BackendAddressPools[i].gw = self
+		igroups[i] = &self.Properties.BackendAddressPools[i]
+	}
+	return igroups, nil
+}
+
+func (self *SApplicationGateway) GetILoadBalancerBackendGroupById(groupId string) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	if pool := self.getBackendPool(groupId); pool != nil {
+		return pool, nil
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SApplicationGateway) CreateILoadBalancerBackendGroup(group *cloudprovider.SLoadbalancerBackendGroup) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SApplicationGateway) getBackendPool(poolId string) *SAppGatewayBackendPool {
+	for i := range self.Properties.BackendAddressPools {
+		if strings.ToLower(self.Properties.BackendAddressPools[i].ID) == strings.ToLower(poolId) {
+			self.Properties.BackendAddressPools[i].gw = self
+			return &self.Properties.BackendAddressPools[i]
+		}
+	}
+	return nil
+}
+
+func (self *SApplicationGateway) getFrontendPort(portId string) *SAppGatewayFrontendPort {
+	for i := range self.Properties.FrontendPorts {
+		if strings.ToLower(self.Properties.FrontendPorts[i].ID) == strings.ToLower(portId) {
+			return &self.Properties.FrontendPorts[i]
+		}
+	}
+	return nil
+}
+
+func (self *SApplicationGateway) getProbe(probeId string) *SAppGatewayProbe {
+	for i := range self.Properties.Probes {
+		if strings.ToLower(self.Properties.Probes[i].ID) == strings.ToLower(probeId) {
+			return &self.Properties.Probes[i]
+		}
+	}
+	return nil
+}
+
+func (self *SApplicationGateway) getBackendHttpSettings(settingsId string) *SAppGatewayBackendHttpSettings {
+	for i := range self.Properties.BackendHttpSettingsCollection {
+		if strings.ToLower(self.Properties.BackendHttpSettingsCollection[i].ID) == strings.ToLower(settingsId) {
+			return &self.Properties.BackendHttpSettingsCollection[i]
+		}
+	}
+	return nil
+}
+
+func (self *SApplicationGateway) getUrlPathMap(mapId string) *SAppGatewayUrlPathMap {
+	for i := range self.Properties.UrlPathMaps {
+		if strings.ToLower(self.Properties.UrlPathMaps[i].ID) == strings.ToLower(mapId) {
+			return &self.Properties.UrlPathMaps[i]
+		}
+	}
+	return nil
+}
+
+func (self *SApplicationGateway) getRoutingRule(listenerId string) *SAppGatewayRequestRoutingRule {
+	for i := range self.Properties.RequestRoutingRules {
+		rule := &self.Properties.RequestRoutingRules[i]
+		if rule.Properties.HttpListener != nil && strings.ToLower(rule.Properties.HttpListener.ID) == strings.ToLower(listenerId) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func (self *SApplicationGateway) getCertificate(certId string) *SAppGatewayCertificate {
+	for i := range self.Properties.SslCertificates {
+		if strings.ToLower(self.Properties.SslCertificates[i].ID) == strings.ToLower(certId) {
+			self.Properties.SslCertificates[i].gw = self
+			return &self.Properties.SslCertificates[i]
+		}
+	}
+	return nil
+}
+
+func (self *SRegion) GetApplicationGateways() ([]SApplicationGateway, error) {
+	gws := []SApplicationGateway{}
+	err := self.client.ListAll("Microsoft.Network/applicationGateways", &gws)
+	if err != nil {
+		return nil, err
+	}
+	result := []SApplicationGateway{}
+	for i := range gws {
+		if gws[i].Location == self.Name {
+			gws[i].region = self
+			result = append(result, gws[i])
+		}
+	}
+	return result, nil
+}
+
+func (self *SRegion) GetApplicationGateway(gwId string) (*SApplicationGateway, error) {
+	gw := SApplicationGateway{region: self}
+	return &gw, self.client.Get(gwId, []string{}, &gw)
+}
+
+func isApplicationGatewayId(id string) bool {
+	return strings.Contains(strings.ToLower(id), "/microsoft.network/applicationgateways/")
+}
+
+// .../applicationGateways/{name}/{kind}/{subname} => .../applicationGateways/{name}
+func applicationGatewayId(subResourceId string) string {
+	segs := strings.Split(subResourceId, "/")
+	for i := range segs {
+		if strings.ToLower(segs[i]) == "applicationgateways" && i+1 < len(segs) {
+			return strings.Join(segs[:i+2], "/")
+		}
+	}
+	return subResourceId
+}
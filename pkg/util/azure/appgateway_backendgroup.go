@@ -0,0 +1,325 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/utils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/util/azure/concurrent"
+)
+
+type SAppGatewayBackendAddress struct {
+	IpAddress string `json:"ipAddress,omitempty"`
+	Fqdn      string `json:"fqdn,omitempty"`
+}
+
+type SAppGatewayBackendPoolProperties struct {
+	BackendAddresses        []SAppGatewayBackendAddress `json:"backendAddresses,omitempty"`
+	BackendIPConfigurations []SubResource               `json:"backendIPConfigurations,omitempty"`
+}
+
+// https://docs.microsoft.com/en-us/rest/api/application-gateway/applicationgateways/get#applicationgatewaybackendaddresspool
+// 后端可以是网卡ip配置, 也可以直接填写ip地址
+type SAppGatewayBackendPool struct {
+	gw *SApplicationGateway
+
+	ID         string                           `json:"id,omitempty"`
+	Name       string                           `json:"name,omitempty"`
+	Properties SAppGatewayBackendPoolProperties `json:"properties,omitempty"`
+}
+
+type SAppGatewayBackend struct {
+	pool *SAppGatewayBackendPool
+
+	ID       string
+	Name     string
+	serverId string
+}
+
+func (self *SAppGatewayBackendPool) GetId() string {
+	return self.ID
+}
+
+func (self *SAppGatewayBackendPool) GetName() string {
+	return self.Name
+}
+
+func (self *SAppGatewayBackendPool) GetGlobalId() string {
+	return strings.ToLower(self.ID)
+}
+
+func (self *SAppGatewayBackendPool) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SAppGatewayBackendPool) Refresh() error {
+	gw, err := self.gw.region.GetApplicationGateway(self.gw.ID)
+	if err != nil {
+		return err
+	}
+	pool := gw.getBackendPool(self.ID)
+	if pool == nil {
+		return cloudprovider.ErrNotFound
+	}
+	return jsonutils.Update(self, pool)
+}
+
+func (self *SAppGatewayBackendPool) IsEmulated() bool {
+	return false
+}
+
+func (self *SAppGatewayBackendPool) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SAppGatewayBackendPool) GetProjectId() string {
+	return getResourceGroup(self.ID)
+}
+
+func (self *SAppGatewayBackendPool) IsDefault() bool {
+	return false
+}
+
+func (self *SAppGatewayBackendPool) GetType() string {
+	return api.LB_BACKENDGROUP_TYPE_NORMAL
+}
+
+// ip地址形式的后端通过网卡私网ip查找对应的虚拟机
+func (self *SAppGatewayBackendPool) GetILoadbalancerBackends() ([]cloudprovider.ICloudLoadbalancerBackend, error) {
+	backends := []SAppGatewayBackend{}
+	if len(self.Properties.BackendAddresses) > 0 {
+		nics, err := self.gw.region.GetNetworkInterfaces()
+		if err != nil {
+			return nil, err
+		}
+		ipServers := map[string]string{}
+		for _, nic := range nics {
+			if nic.Properties.VirtualMachine == nil {
+				continue
+			}
+			for _, ipConf := range nic.Properties.IPConfigurations {
+				ipServers[ipConf.Properties.PrivateIPAddress] = strings.ToLower(nic.Properties.VirtualMachine.ID)
+			}
+		}
+		for _, addr := range self.Properties.BackendAddresses {
+			serverId, ok := ipServers[addr.IpAddress]
+			if !ok {
+				log.Warningf("backend address %s%s of %s not belongs to any virtual machine", addr.IpAddress, addr.Fqdn, self.Name)
+				continue
+			}
+			backends = append(backends, SAppGatewayBackend{
+				pool:     self,
+				ID:       fmt.Sprintf("%s/%s", self.ID, addr.IpAddress),
+				Name:     addr.IpAddress,
+				serverId: serverId,
+			})
+		}
+	}
+	ipConfBackends := make([]SAppGatewayBackend, len(self.Properties.BackendIPConfigurations))
+	requests := []*concurrent.Request{}
+	for i := range self.Properties.BackendIPConfigurations {
+		backend := &ipConfBackends[i]
+		backend.pool = self
+		backend.ID = self.Properties.BackendIPConfigurations[i].ID
+		backend.Name = ipConfigurationNicName(backend.ID)
+		requests = append(requests, &concurrent.Request{
+			ID: backend.ID,
+			Work: func() error {
+				nic, err := self.gw.region.GetNetworkInterfaceDetail(ipConfigurationNicId(backend.ID))
+				if err != nil {
+					return err
+				}
+				if nic.Properties.VirtualMachine != nil {
+					backend.serverId = strings.ToLower(nic.Properties.VirtualMachine.ID)
+				}
+				return nil
+			},
+			ShouldRetry: func(err error) bool {
+				return err != cloudprovider.ErrNotFound
+			},
+		})
+	}
+	if err := runConcurrent(5, requests); err != nil {
+		return nil, err
+	}
+	for i := range ipConfBackends {
+		if len(ipConfBackends[i].serverId) == 0 {
+			log.Warningf("network interface of %s not attached to any virtual machine", ipConfBackends[i].ID)
+			continue
+		}
+		backends = append(backends, ipConfBackends[i])
+	}
+	ibackends := make([]cloudprovider.ICloudLoadbalancerBackend, len(backends))
+	for i := range backends {
+		ibackends[i] = &backends[i]
+	}
+	return ibackends, nil
+}
+
+// 通过网卡ip配置加入地址池, 避免整体更新应用网关
+func (self *SAppGatewayBackendPool) AddBackendServer(serverId string, weight int, port int) (cloudprovider.ICloudLoadbalancerBackend, error) {
+	nic, ipConf, err := self.gw.region.getInstancePrimaryIPConfiguration(serverId)
+	if err != nil {
+		return nil, err
+	}
+	backend := &SAppGatewayBackend{pool: self, ID: ipConf.ID, Name: ipConfigurationNicName(ipConf.ID), serverId: strings.ToLower(serverId)}
+	for _, pool := range ipConf.Properties.ApplicationGatewayBackendAddressPools {
+		if strings.ToLower(pool.ID) == strings.ToLower(self.ID) {
+			return backend, nil
+		}
+	}
+	ipConf.Properties.ApplicationGatewayBackendAddressPools = append(ipConf.Properties.ApplicationGatewayBackendAddressPools, SubResource{ID: self.ID})
+	if err := self.gw.region.client.Update(jsonutils.Marshal(nic), nil); err != nil {
+		return nil, err
+	}
+	return backend, nil
+}
+
+func (self *SAppGatewayBackendPool) RemoveBackendServer(serverId string, weight int, port int) error {
+	instance, err := self.gw.region.GetInstance(serverId)
+	if err != nil {
+		if err == cloudprovider.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	ips := []string{}
+	for _, nicRef := range instance.Properties.NetworkProfile.NetworkInterfaces {
+		nic, err := self.gw.region.GetNetworkInterfaceDetail(nicRef.ID)
+		if err != nil {
+			if err == cloudprovider.ErrNotFound {
+				continue
+			}
+			return err
+		}
+		changed := false
+		for i := range nic.Properties.IPConfigurations {
+			ipConf := &nic.Properties.IPConfigurations[i]
+			ips = append(ips, ipConf.Properties.PrivateIPAddress)
+			pools := []SubResource{}
+			for _, pool := range ipConf.Properties.ApplicationGatewayBackendAddressPools {
+				if strings.ToLower(pool.ID) == strings.ToLower(self.ID) {
+					changed = true
+					continue
+				}
+				pools = append(pools, pool)
+			}
+			ipConf.Properties.ApplicationGatewayBackendAddressPools = pools
+		}
+		if changed {
+			if err := self.gw.region.client.Update(jsonutils.Marshal(nic), nil); err != nil {
+				return err
+			}
+		}
+	}
+	return self.removeBackendAddresses(ips)
+}
+
+// ip地址形式的后端只能修改应用网关, 使用原始json避免丢失未解析的配置
+func (self *SAppGatewayBackendPool) removeBackendAddresses(ips []string) error {
+	found := false
+	for _, addr := range self.Properties.BackendAddresses {
+		if utils.IsInStringArray(addr.IpAddress, ips) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+	gw, err := self.gw.region.client.jsonRequest("GET", self.gw.ID, "")
+	if err != nil {
+		return err
+	}
+	pools, err := gw.GetArray("properties", "backendAddressPools")
+	if err != nil {
+		return err
+	}
+	for _, pool := range pools {
+		poolId, _ := pool.GetString("id")
+		if strings.ToLower(poolId) != strings.ToLower(self.ID) {
+			continue
+		}
+		addrs, _ := pool.GetArray("properties", "backendAddresses")
+		remains := jsonutils.NewArray()
+		for _, addr := range addrs {
+			ip, _ := addr.GetString("ipAddress")
+			if !utils.IsInStringArray(ip, ips) {
+				remains.Add(addr)
+			}
+		}
+		if properties, err := pool.Get("properties"); err == nil {
+			properties.(*jsonutils.JSONDict).Set("backendAddresses", remains)
+		}
+	}
+	return self.gw.region.client.Put(self.gw.ID, gw)
+}
+
+func (self *SAppGatewayBackendPool) Delete() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SAppGatewayBackendPool) Sync(name string) error {
+	if len(name) > 0 && name != self.Name {
+		return cloudprovider.ErrNotSupported
+	}
+	return nil
+}
+
+func (self *SAppGatewayBackend) GetId() string {
+	return self.ID
+}
+
+func (self *SAppGatewayBackend) GetName() string {
+	return self.Name
+}
+
+func (self *SAppGatewayBackend) GetGlobalId() string {
+	return strings.ToLower(self.ID)
+}
+
+func (self *SAppGatewayBackend) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SAppGatewayBackend) Refresh() error {
+	return nil
+}
+
+func (self *SAppGatewayBackend) IsEmulated() bool {
+	return false
+}
+
+func (self *SAppGatewayBackend) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SAppGatewayBackend) GetProjectId() string {
+	return getResourceGroup(self.pool.ID)
+}
+
+func (self *SAppGatewayBackend) GetWeight() int {
+	return 1
+}
+
+// 后端端口由后端http设置指定
+func (self *SAppGatewayBackend) GetPort() int {
+	return 0
+}
+
+func (self *SAppGatewayBackend) GetBackendType() string {
+	return api.LB_BACKEND_GUEST
+}
+
+func (self *SAppGatewayBackend) GetBackendRole() string {
+	return api.LB_BACKEND_ROLE_DEFAULT
+}
+
+func (self *SAppGatewayBackend) GetBackendId() string {
+	return self.serverId
+}
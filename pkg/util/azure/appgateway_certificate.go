@@ -0,0 +1,166 @@
+package azure
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SAppGatewayCertificateProperties struct {
+	PublicCertData string `json:"publicCertData,omitempty"`
+}
+
+// https://docs.microsoft.com/en-us/rest/api/application-gateway/applicationgateways/get#applicationgatewaysslcertificate
+// 证书属于应用网关, 查询时只返回PKCS#7格式的公钥证书
+type SAppGatewayCertificate struct {
+	gw *SApplicationGateway
+
+	ID         string                           `json:"id,omitempty"`
+	Name       string                           `json:"name,omitempty"`
+	Properties SAppGatewayCertificateProperties `json:"properties,omitempty"`
+
+	cert *x509.Certificate
+}
+
+func (self *SAppGatewayCertificate) GetId() string {
+	return self.ID
+}
+
+func (self *SAppGatewayCertificate) GetName() string {
+	return self.Name
+}
+
+func (self *SAppGatewayCertificate) GetGlobalId() string {
+	return strings.ToLower(self.ID)
+}
+
+func (self *SAppGatewayCertificate) GetStatus() string {
+	return ""
+}
+
+func (self *SAppGatewayCertificate) Refresh() error {
+	gw, err := self.gw.region.GetApplicationGateway(self.gw.ID)
+	if err != nil {
+		return err
+	}
+	cert := gw.getCertificate(self.ID)
+	if cert == nil {
+		return cloudprovider.ErrNotFound
+	}
+	self.cert = nil
+	return jsonutils.Update(self, cert)
+}
+
+func (self *SAppGatewayCertificate) IsEmulated() bool {
+	return false
+}
+
+func (self *SAppGatewayCertificate) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SAppGatewayCertificate) GetProjectId() string {
+	return getResourceGroup(self.ID)
+}
+
+func (self *SAppGatewayCertificate) getCertificate() *x509.Certificate {
+	if self.cert == nil && len(self.Properties.PublicCertData) > 0 {
+		cert, err := parsePKCS7Certificate(self.Properties.PublicCertData)
+		if err != nil {
+			log.Errorf("failed to parse certificate %s: %v", self.Name, err)
+			return nil
+		}
+		self.cert = cert
+	}
+	return self.cert
+}
+
+func (self *SAppGatewayCertificate) GetCommonName() string {
+	if cert := self.getCertificate(); cert != nil {
+		return cert.Subject.CommonName
+	}
+	return ""
+}
+
+func (self *SAppGatewayCertificate) GetSubjectAlternativeNames() string {
+	if cert := self.getCertificate(); cert != nil {
+		return strings.Join(cert.DNSNames, ",")
+	}
+	return ""
+}
+
+func (self *SAppGatewayCertificate) GetFingerprint() string {
+	if cert := self.getCertificate(); cert != nil {
+		d := sha256.Sum256(cert.Raw)
+		return api.LB_TLS_CERT_FINGERPRINT_ALGO_SHA256 + ":" + hex.EncodeToString(d[:])
+	}
+	return ""
+}
+
+func (self *SAppGatewayCertificate) GetExpireTime() time.Time {
+	if cert := self.getCertificate(); cert != nil {
+		return cert.NotAfter
+	}
+	return time.Time{}
+}
+
+// 证书被监听器引用, 需要在应用网关上修改
+func (self *SAppGatewayCertificate) Sync(name, privateKey, publickKey string) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SAppGatewayCertificate) Delete() error {
+	return cloudprovider.ErrNotSupported
+}
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+// 解析base64编码的PKCS#7证书链, 返回其中的服务器证书
+func parsePKCS7Certificate(data string) (*x509.Certificate, error) {
+	der, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	contentInfo := pkcs7ContentInfo{}
+	if _, err := asn1.Unmarshal(der, &contentInfo); err != nil {
+		return nil, err
+	}
+	signedData := pkcs7SignedData{}
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		return nil, err
+	}
+	certs, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found")
+	}
+	for _, cert := range certs {
+		if !cert.IsCA {
+			return cert, nil
+		}
+	}
+	return certs[0], nil
+}
@@ -0,0 +1,433 @@
+package azure
+
+import (
+	"strconv"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+// 应用网关未配置自定义探测时使用默认探测
+const (
+	APP_GATEWAY_DEFAULT_PROBE_INTERVAL  = 30
+	APP_GATEWAY_DEFAULT_PROBE_TIMEOUT   = 30
+	APP_GATEWAY_DEFAULT_PROBE_THRESHOLD = 3
+	APP_GATEWAY_DEFAULT_AFFINITY_COOKIE = "ApplicationGatewayAffinity"
+)
+
+// https://docs.microsoft.com/en-us/rest/api/application-gateway/applicationgateways/get#applicationgatewayhttplistener
+// 监听器通过请求路由规则关联后端地址池及后端http设置
+type SAppGatewayListener struct {
+	gw *SApplicationGateway
+
+	ID         string                            `json:"id,omitempty"`
+	Name       string                            `json:"name,omitempty"`
+	Properties SAppGatewayHttpListenerProperties `json:"properties,omitempty"`
+}
+
+func (self *SAppGatewayListener) GetId() string {
+	return self.ID
+}
+
+func (self *SAppGatewayListener) GetName() string {
+	return self.Name
+}
+
+func (self *SAppGatewayListener) GetGlobalId() string {
+	return strings.ToLower(self.ID)
+}
+
+func (self *SAppGatewayListener) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SAppGatewayListener) Refresh() error {
+	gw, err := self.gw.region.GetApplicationGateway(self.gw.ID)
+	if err != nil {
+		return err
+	}
+	ilistener, err := gw.GetILoadBalancerListenerById(self.ID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, ilistener)
+}
+
+func (self *SAppGatewayListener) IsEmulated() bool {
+	return false
+}
+
+func (self *SAppGatewayListener) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SAppGatewayListener) GetProjectId() string {
+	return getResourceGroup(self.ID)
+}
+
+func (self *SAppGatewayListener) getRoutingRule() *SAppGatewayRequestRoutingRule {
+	return self.gw.getRoutingRule(self.ID)
+}
+
+func (self *SAppGatewayListener) getUrlPathMap() *SAppGatewayUrlPathMap {
+	rule := self.getRoutingRule()
+	if rule != nil && rule.Properties.UrlPathMap != nil {
+		return self.gw.getUrlPathMap(rule.Properties.UrlPathMap.ID)
+	}
+	return nil
+}
+
+// 基于路径的路由规则以路径映射的默认配置作为监听的后端
+func (self *SAppGatewayListener) getBackendHttpSettings() *SAppGatewayBackendHttpSettings {
+	if pathMap := self.getUrlPathMap(); pathMap != nil {
+		if pathMap.Properties.DefaultBackendHttpSettings != nil {
+			return self.gw.getBackendHttpSettings(pathMap.Properties.DefaultBackendHttpSettings.ID)
+		}
+		return nil
+	}
+	if rule := self.getRoutingRule(); rule != nil && rule.Properties.BackendHttpSettings != nil {
+		return self.gw.getBackendHttpSettings(rule.Properties.BackendHttpSettings.ID)
+	}
+	return nil
+}
+
+func (self *SAppGatewayListener) getProbe() *SAppGatewayProbe {
+	settings := self.getBackendHttpSettings()
+	if settings != nil && settings.Properties.Probe != nil {
+		return self.gw.getProbe(settings.Properties.Probe.ID)
+	}
+	return nil
+}
+
+func (self *SAppGatewayListener) GetListenerType() string {
+	if strings.ToLower(self.Properties.Protocol) == "https" {
+		return api.LB_LISTENER_TYPE_HTTPS
+	}
+	return api.LB_LISTENER_TYPE_HTTP
+}
+
+func (self *SAppGatewayListener) GetListenerPort() int {
+	if self.Properties.FrontendPort != nil {
+		if port := self.gw.getFrontendPort(self.Properties.FrontendPort.ID); port != nil {
+			return port.Properties.Port
+		}
+	}
+	return 0
+}
+
+func (self *SAppGatewayListener) GetScheduler() string {
+	return api.LB_SCHEDULER_RR
+}
+
+func (self *SAppGatewayListener) GetAclStatus() string {
+	return api.LB_BOOL_OFF
+}
+
+func (self *SAppGatewayListener) GetAclType() string {
+	return ""
+}
+
+func (self *SAppGatewayListener) GetAclId() string {
+	return ""
+}
+
+func (self *SAppGatewayListener) GetHealthCheck() string {
+	return api.LB_BOOL_ON
+}
+
+func (self *SAppGatewayListener) GetHealthCheckType() string {
+	return api.LB_HEALTH_CHECK_HTTP
+}
+
+func (self *SAppGatewayListener) GetHealthCheckTimeout() int {
+	if probe := self.getProbe(); probe != nil {
+		return probe.Properties.Timeout
+	}
+	return APP_GATEWAY_DEFAULT_PROBE_TIMEOUT
+}
+
+func (self *SAppGatewayListener) GetHealthCheckInterval() int {
+	if probe := self.getProbe(); probe != nil {
+		return probe.Properties.Interval
+	}
+	return APP_GATEWAY_DEFAULT_PROBE_INTERVAL
+}
+
+func (self *SAppGatewayListener) GetHealthCheckRise() int {
+	return 1
+}
+
+func (self *SAppGatewayListener) GetHealthCheckFail() int {
+	if probe := self.getProbe(); probe != nil {
+		return probe.Properties.UnhealthyThreshold
+	}
+	return APP_GATEWAY_DEFAULT_PROBE_THRESHOLD
+}
+
+func (self *SAppGatewayListener) GetHealthCheckReq() string {
+	return ""
+}
+
+func (self *SAppGatewayListener) GetHealthCheckExp() string {
+	return ""
+}
+
+func (self *SAppGatewayListener) GetBackendGroupId() string {
+	if pathMap := self.getUrlPathMap(); pathMap != nil {
+		if pathMap.Properties.DefaultBackendAddressPool != nil {
+			return strings.ToLower(pathMap.Properties.DefaultBackendAddressPool.ID)
+		}
+		return ""
+	}
+	if rule := self.getRoutingRule(); rule != nil && rule.Properties.BackendAddressPool != nil {
+		return strings.ToLower(rule.Properties.BackendAddressPool.ID)
+	}
+	return ""
+}
+
+func (self *SAppGatewayListener) GetBackendServerPort() int {
+	if settings := self.getBackendHttpSettings(); settings != nil {
+		return settings.Properties.Port
+	}
+	return 0
+}
+
+func (self *SAppGatewayListener) GetHealthCheckDomain() string {
+	if probe := self.getProbe(); probe != nil {
+		return probe.Properties.Host
+	}
+	return ""
+}
+
+func (self *SAppGatewayListener) GetHealthCheckURI() string {
+	if probe := self.getProbe(); probe != nil {
+		return probe.Properties.Path
+	}
+	return "/"
+}
+
+// 状态码范围如 200-399 转换为 http_2xx,http_3xx
+func (self *SAppGatewayListener) GetHealthCheckCode() string {
+	probe := self.getProbe()
+	if probe == nil || probe.Properties.Match == nil || len(probe.Properties.Match.StatusCodes) == 0 {
+		return api.LB_HEALTH_CHECK_HTTP_CODE_DEFAULT
+	}
+	return appGatewayStatusCodesToHealthCheckCode(probe.Properties.Match.StatusCodes)
+}
+
+func appGatewayStatusCodesToHealthCheckCode(statusCodes []string) string {
+	httpCodes := []string{
+		api.LB_HEALTH_CHECK_HTTP_CODE_1xx,
+		api.LB_HEALTH_CHECK_HTTP_CODE_2xx,
+		api.LB_HEALTH_CHECK_HTTP_CODE_3xx,
+		api.LB_HEALTH_CHECK_HTTP_CODE_4xx,
+		api.LB_HEALTH_CHECK_HTTP_CODE_5xx,
+	}
+	codes := []string{}
+	for i, httpCode := range httpCodes {
+		for _, statusCode := range statusCodes {
+			segs := strings.SplitN(statusCode, "-", 2)
+			start, err := strconv.Atoi(segs[0])
+			if err != nil {
+				continue
+			}
+			end := start
+			if len(segs) == 2 {
+				if end, err = strconv.Atoi(segs[1]); err != nil {
+					continue
+				}
+			}
+			if start/100 <= i+1 && i+1 <= end/100 {
+				codes = append(codes, httpCode)
+				break
+			}
+		}
+	}
+	if len(codes) == 0 {
+		return api.LB_HEALTH_CHECK_HTTP_CODE_DEFAULT
+	}
+	return strings.Join(codes, ",")
+}
+
+func (self *SAppGatewayListener) CreateILoadBalancerListenerRule(rule *cloudprovider.SLoadbalancerListenerRule) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SAppGatewayListener) GetILoadBalancerListenerRuleById(ruleId string) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	rules, err := self.GetILoadbalancerListenerRules()
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if rules[i].GetGlobalId() == strings.ToLower(ruleId) {
+			return rules[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SAppGatewayListener) GetILoadbalancerListenerRules() ([]cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	irules := []cloudprovider.ICloudLoadbalancerListenerRule{}
+	pathMap := self.getUrlPathMap()
+	if pathMap == nil {
+		return irules, nil
+	}
+	for i := range pathMap.Properties.PathRules {
+		pathMap.Properties.PathRules[i].listener = self
+		irules = append(irules, &pathMap.Properties.PathRules[i])
+	}
+	return irules, nil
+}
+
+func (self *SAppGatewayListener) GetStickySession() string {
+	if settings := self.getBackendHttpSettings(); settings != nil && settings.Properties.CookieBasedAffinity == "Enabled" {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (self *SAppGatewayListener) GetStickySessionType() string {
+	if self.GetStickySession() == api.LB_BOOL_ON {
+		return api.LB_STICKY_SESSION_TYPE_INSERT
+	}
+	return ""
+}
+
+func (self *SAppGatewayListener) GetStickySessionCookie() string {
+	if self.GetStickySession() != api.LB_BOOL_ON {
+		return ""
+	}
+	if settings := self.getBackendHttpSettings(); settings != nil && len(settings.Properties.AffinityCookieName) > 0 {
+		return settings.Properties.AffinityCookieName
+	}
+	return APP_GATEWAY_DEFAULT_AFFINITY_COOKIE
+}
+
+func (self *SAppGatewayListener) GetStickySessionCookieTimeout() int {
+	return 0
+}
+
+// 应用网关默认添加 X-Forwarded-For 头
+func (self *SAppGatewayListener) XForwardedForEnabled() bool {
+	return true
+}
+
+func (self *SAppGatewayListener) GzipEnabled() bool {
+	return false
+}
+
+func (self *SAppGatewayListener) GetCertificateId() string {
+	if self.Properties.SslCertificate != nil {
+		return strings.ToLower(self.Properties.SslCertificate.ID)
+	}
+	return ""
+}
+
+func (self *SAppGatewayListener) GetTLSCipherPolicy() string {
+	if self.GetListenerType() != api.LB_LISTENER_TYPE_HTTPS || self.gw.Properties.SslPolicy == nil {
+		return ""
+	}
+	switch self.gw.Properties.SslPolicy.MinProtocolVersion {
+	case "TLSv1_2":
+		return api.LB_TLS_CIPHER_POLICY_1_2
+	case "TLSv1_1":
+		return api.LB_TLS_CIPHER_POLICY_1_1
+	default:
+		return api.LB_TLS_CIPHER_POLICY_1_0
+	}
+}
+
+func (self *SAppGatewayListener) HTTP2Enabled() bool {
+	return self.gw.Properties.EnableHttp2
+}
+
+func (self *SAppGatewayListener) Start() error {
+	return nil
+}
+
+func (self *SAppGatewayListener) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SAppGatewayListener) Sync(listener *cloudprovider.SLoadbalancerListener) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SAppGatewayListener) Delete() error {
+	return cloudprovider.ErrNotSupported
+}
+
+// https://docs.microsoft.com/en-us/rest/api/application-gateway/applicationgateways/get#applicationgatewaypathrule
+// 路径规则对应监听规则, 域名取自监听器的主机名
+type SAppGatewayPathRule struct {
+	listener *SAppGatewayListener
+
+	ID         string                        `json:"id,omitempty"`
+	Name       string                        `json:"name,omitempty"`
+	Properties SAppGatewayPathRuleProperties `json:"properties,omitempty"`
+}
+
+func (self *SAppGatewayPathRule) GetId() string {
+	return self.ID
+}
+
+func (self *SAppGatewayPathRule) GetName() string {
+	return self.Name
+}
+
+func (self *SAppGatewayPathRule) GetGlobalId() string {
+	return strings.ToLower(self.ID)
+}
+
+func (self *SAppGatewayPathRule) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SAppGatewayPathRule) Refresh() error {
+	if err := self.listener.Refresh(); err != nil {
+		return err
+	}
+	irule, err := self.listener.GetILoadBalancerListenerRuleById(self.ID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, irule)
+}
+
+func (self *SAppGatewayPathRule) IsEmulated() bool {
+	return false
+}
+
+func (self *SAppGatewayPathRule) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SAppGatewayPathRule) GetProjectId() string {
+	return getResourceGroup(self.ID)
+}
+
+func (self *SAppGatewayPathRule) GetDomain() string {
+	return self.listener.Properties.HostName
+}
+
+// 一条路径规则可以包含多个路径, 只取第一个
+func (self *SAppGatewayPathRule) GetPath() string {
+	if len(self.Properties.Paths) > 0 {
+		return self.Properties.Paths[0]
+	}
+	return ""
+}
+
+func (self *SAppGatewayPathRule) GetBackendGroupId() string {
+	if self.Properties.BackendAddressPool != nil {
+		return strings.ToLower(self.Properties.BackendAddressPool.ID)
+	}
+	return ""
+}
+
+func (self *SAppGatewayPathRule) Delete() error {
+	return cloudprovider.ErrNotSupported
+}
@@ -141,6 +141,26 @@ func (self *SClassicVpc) GetIRouteTables() ([]cloudprovider.ICloudRouteTable, er
 	return rts, nil
 }
 
+func (self *SClassicVpc) GetINatGateways() ([]cloudprovider.ICloudNatGateway, error) {
+	return []cloudprovider.ICloudNatGateway{}, nil
+}
+
+func (self *SClassicVpc) CreateINatGateway(opts *cloudprovider.SNatGatewayCreateOptions) (cloudprovider.ICloudNatGateway, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (self *SClassicVpc) GetIVpcPeerings() ([]cloudprovider.ICloudVpcPeering, error) {
+	return []cloudprovider.ICloudVpcPeering{}, nil
+}
+
+func (self *SClassicVpc) GetIVpcPeeringById(id string) (cloudprovider.ICloudVpcPeering, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SClassicVpc) CreateIVpcPeering(opts *cloudprovider.SVpcPeeringCreateOptions) (cloudprovider.ICloudVpcPeering, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
 func (self *SClassicVpc) fetchWires() error {
 	networks := make([]cloudprovider.ICloudNetwork, len(self.Properties.Subnets))
 	wire := SClassicWire{zone: self.region.izones[0].(*SZone), vpc: self}
@@ -24,6 +24,11 @@ type InterfaceIPConfigurationPropertiesFormat struct {
 	Subnet                    Subnet           `json:"subnet,omitempty"`
 	Primary                   *bool            `json:"primary,omitempty"`
 	PublicIPAddress           *PublicIPAddress `json:"publicIPAddress,omitempty"`
+
+	// 更新网卡时需要保留负载均衡的关联关系
+	LoadBalancerBackendAddressPools       []SubResource `json:"loadBalancerBackendAddressPools,omitempty"`
+	LoadBalancerInboundNatRules           []SubResource `json:"loadBalancerInboundNatRules,omitempty"`
+	ApplicationGatewayBackendAddressPools []SubResource `json:"applicationGatewayBackendAddressPools,omitempty"`
 }
 
 type InterfaceIPConfiguration struct {
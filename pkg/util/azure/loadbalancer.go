@@ -0,0 +1,390 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/util/azure/concurrent"
+)
+
+type SLoadBalancerSku struct {
+	Name string `json:"name,omitempty"`
+}
+
+type SFrontendIPConfigurationProperties struct {
+	PrivateIPAddress          string       `json:"privateIPAddress,omitempty"`
+	PrivateIPAllocationMethod string       `json:"privateIPAllocationMethod,omitempty"`
+	Subnet                    *SubResource `json:"subnet,omitempty"`
+	PublicIPAddress           *SubResource `json:"publicIPAddress,omitempty"`
+}
+
+type SFrontendIPConfiguration struct {
+	ID         string                             `json:"id,omitempty"`
+	Name       string                             `json:"name,omitempty"`
+	Properties SFrontendIPConfigurationProperties `json:"properties,omitempty"`
+}
+
+type SLoadBalancerProbeProperties struct {
+	Protocol          string `json:"protocol,omitempty"`
+	Port              int    `json:"port,omitempty"`
+	IntervalInSeconds int    `json:"intervalInSeconds,omitempty"`
+	NumberOfProbes    int    `json:"numberOfProbes,omitempty"`
+	RequestPath       string `json:"requestPath,omitempty"`
+}
+
+type SLoadBalancerProbe struct {
+	ID         string                       `json:"id,omitempty"`
+	Name       string                       `json:"name,omitempty"`
+	Properties SLoadBalancerProbeProperties `json:"properties,omitempty"`
+}
+
+// 未使用的NAT规则与出站规则原样保留, 以免更新负载均衡时被清除
+type SLoadBalancerProperties struct {
+	ProvisioningState        string                     `json:"provisioningState,omitempty"`
+	FrontendIPConfigurations []SFrontendIPConfiguration `json:"frontendIPConfigurations,omitempty"`
+	BackendAddressPools      []SLoadbalancerBackendPool `json:"backendAddressPools"`
+	LoadBalancingRules       []SLoadbalancerListener    `json:"loadBalancingRules"`
+	Probes                   []SLoadBalancerProbe       `json:"probes"`
+	InboundNatRules          jsonutils.JSONObject       `json:"inboundNatRules,omitempty"`
+	InboundNatPools          jsonutils.JSONObject       `json:"inboundNatPools,omitempty"`
+	OutboundRules            jsonutils.JSONObject       `json:"outboundRules,omitempty"`
+}
+
+// https://docs.microsoft.com/en-us/rest/api/load-balancer/loadbalancers
+// 四层负载均衡, 监听对应负载均衡规则, 后端服务器组对应后端地址池
+type SLoadbalancer struct {
+	region *SRegion
+
+	ID         string                  `json:"id,omitempty"`
+	Name       string                  `json:"name,omitempty"`
+	Location   string                  `json:"location,omitempty"`
+	Type       string                  `json:"type,omitempty"`
+	Sku        *SLoadBalancerSku       `json:"sku,omitempty"`
+	Properties SLoadBalancerProperties `json:"properties,omitempty"`
+}
+
+func (self *SLoadbalancer) GetId() string {
+	return self.ID
+}
+
+func (self *SLoadbalancer) GetName() string {
+	return self.Name
+}
+
+func (self *SLoadbalancer) GetGlobalId() string {
+	return strings.ToLower(self.ID)
+}
+
+func (self *SLoadbalancer) GetStatus() string {
+	switch self.Properties.ProvisioningState {
+	case "Succeeded":
+		return api.LB_STATUS_ENABLED
+	case "Updating", "Creating":
+		return api.LB_STATUS_INIT
+	default:
+		return api.LB_STATUS_UNKNOWN
+	}
+}
+
+func (self *SLoadbalancer) Refresh() error {
+	lb, err := self.region.GetLoadbalancer(self.ID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, lb)
+}
+
+func (self *SLoadbalancer) IsEmulated() bool {
+	return false
+}
+
+func (self *SLoadbalancer) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SLoadbalancer) GetProjectId() string {
+	return getResourceGroup(self.ID)
+}
+
+func (self *SLoadbalancer) getFrontend() *SFrontendIPConfiguration {
+	if len(self.Properties.FrontendIPConfigurations) > 0 {
+		return &self.Properties.FrontendIPConfigurations[0]
+	}
+	return nil
+}
+
+func (self *SLoadbalancer) GetAddress() string {
+	frontend := self.getFrontend()
+	if frontend == nil {
+		return ""
+	}
+	if frontend.Properties.PublicIPAddress != nil {
+		eip, err := self.region.GetEip(frontend.Properties.PublicIPAddress.ID)
+		if err != nil {
+			log.Errorf("failed to get public ip of loadbalancer %s: %v", self.Name, err)
+			return ""
+		}
+		return eip.GetIpAddr()
+	}
+	return frontend.Properties.PrivateIPAddress
+}
+
+func (self *SLoadbalancer) GetAddressType() string {
+	if frontend := self.getFrontend(); frontend != nil && frontend.Properties.PublicIPAddress != nil {
+		return api.LB_ADDR_TYPE_INTERNET
+	}
+	return api.LB_ADDR_TYPE_INTRANET
+}
+
+func (self *SLoadbalancer) GetNetworkType() string {
+	return api.LB_NETWORK_TYPE_VPC
+}
+
+func (self *SLoadbalancer) GetNetworkId() string {
+	if frontend := self.getFrontend(); frontend != nil && frontend.Properties.Subnet != nil {
+		return strings.ToLower(frontend.Properties.Subnet.ID)
+	}
+	return ""
+}
+
+func (self *SLoadbalancer) GetVpcId() string {
+	return subnetVpcId(self.GetNetworkId())
+}
+
+func (self *SLoadbalancer) GetZoneId() string {
+	return self.region.getZoneGlobalId()
+}
+
+func (self *SLoadbalancer) GetLoadbalancerSpec() string {
+	if self.Sku != nil {
+		return self.Sku.Name
+	}
+	return ""
+}
+
+func (self *SLoadbalancer) GetChargeType() string {
+	return api.LB_CHARGE_TYPE_BY_TRAFFIC
+}
+
+func (self *SLoadbalancer) Delete() error {
+	return self.region.client.Delete(self.ID)
+}
+
+func (self *SLoadbalancer) Start() error {
+	return nil
+}
+
+func (self *SLoadbalancer) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SLoadbalancer) GetILoadBalancerListeners() ([]cloudprovider.ICloudLoadbalancerListener, error) {
+	ilisteners := make([]cloudprovider.ICloudLoadbalancerListener, len(self.Properties.LoadBalancingRules))
+	for i := range self.Properties.LoadBalancingRules {
+		self.Properties.LoadBalancingRules[i].lb = self
+		ilisteners[i] = &self.Properties.LoadBalancingRules[i]
+	}
+	return ilisteners, nil
+}
+
+func (self *SLoadbalancer) GetILoadBalancerListenerById(listenerId string) (cloudprovider.ICloudLoadbalancerListener, error) {
+	if listener := self.getListener(listenerId); listener != nil {
+		return listener, nil
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SLoadbalancer) CreateILoadBalancerListener(listener *cloudprovider.SLoadbalancerListener) (cloudprovider.ICloudLoadbalancerListener, error) {
+	ret, err := self.createListener(listener)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (self *SLoadbalancer) GetILoadBalancerBackendGroups() ([]cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	igroups := make([]cloudprovider.ICloudLoadbalancerBackendGroup, len(self.Properties.BackendAddressPools))
+	for i := range self.Properties.BackendAddressPools {
+		self.Properties.BackendAddressPools[i].lb = self
+		igroups[i] = &self.Properties.BackendAddressPools[i]
+	}
+	return igroups, nil
+}
+
+func (self *SLoadbalancer) GetILoadBalancerBackendGroupById(groupId string) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	if pool := self.getBackendPool(groupId); pool != nil {
+		return pool, nil
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SLoadbalancer) CreateILoadBalancerBackendGroup(group *cloudprovider.SLoadbalancerBackendGroup) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	ret, err := self.createBackendPool(group)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (self *SLoadbalancer) getListener(listenerId string) *SLoadbalancerListener {
+	for i := range self.Properties.LoadBalancingRules {
+		if strings.ToLower(self.Properties.LoadBalancingRules[i].ID) == strings.ToLower(listenerId) {
+			self.Properties.LoadBalancingRules[i].lb = self
+			return &self.Properties.LoadBalancingRules[i]
+		}
+	}
+	return nil
+}
+
+func (self *SLoadbalancer) getBackendPool(poolId string) *SLoadbalancerBackendPool {
+	for i := range self.Properties.BackendAddressPools {
+		if strings.ToLower(self.Properties.BackendAddressPools[i].ID) == strings.ToLower(poolId) {
+			self.Properties.BackendAddressPools[i].lb = self
+			return &self.Properties.BackendAddressPools[i]
+		}
+	}
+	return nil
+}
+
+func (self *SLoadbalancer) getProbe(probeId string) *SLoadBalancerProbe {
+	for i := range self.Properties.Probes {
+		if strings.ToLower(self.Properties.Probes[i].ID) == strings.ToLower(probeId) {
+			return &self.Properties.Probes[i]
+		}
+	}
+	return nil
+}
+
+// 子资源的名称在负载均衡内唯一, id由负载均衡id及名称组成
+func (self *SLoadbalancer) subResourceId(kind, name string) string {
+	return fmt.Sprintf("%s/%s/%s", self.ID, kind, name)
+}
+
+func (self *SLoadbalancer) uniqSubResourceName(name string, exists func(id string) bool) string {
+	if len(name) == 0 {
+		name = "default"
+	}
+	uniq := name
+	for i := 1; exists(uniq); i++ {
+		uniq = fmt.Sprintf("%s-%d", name, i)
+	}
+	return uniq
+}
+
+// 负载均衡的子资源不能单独修改, 需要整体更新
+func (self *SLoadbalancer) update() error {
+	lb := &SLoadbalancer{}
+	err := self.region.client.Update(jsonutils.Marshal(self), lb)
+	if err != nil {
+		return err
+	}
+	lb.region = self.region
+	*self = *lb
+	return nil
+}
+
+func subnetVpcId(networkId string) string {
+	if idx := strings.Index(networkId, "/subnets/"); idx > 0 {
+		return networkId[:idx]
+	}
+	return ""
+}
+
+func (self *SRegion) getZoneGlobalId() string {
+	zones, err := self.GetIZones()
+	if err != nil || len(zones) == 0 {
+		return ""
+	}
+	return zones[0].GetGlobalId()
+}
+
+// 通过concurrent并发执行请求, 返回第一个失败的错误
+func runConcurrent(parallelism int, requests []*concurrent.Request) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	if parallelism > len(requests) {
+		parallelism = len(requests)
+	}
+	requestChan := make(chan *concurrent.Request)
+	balancer := concurrent.NewBalancer(parallelism)
+	balancer.Init()
+	errorChan, finishedChan := balancer.Run(requestChan)
+	go func() {
+		for _, req := range requests {
+			requestChan <- req
+		}
+		close(requestChan)
+	}()
+	var err error
+	for {
+		select {
+		case e := <-errorChan:
+			if err == nil {
+				err = e
+			}
+		case <-finishedChan:
+			return err
+		}
+	}
+}
+
+func (self *SRegion) GetLoadbalancers() ([]SLoadbalancer, error) {
+	lbs := []SLoadbalancer{}
+	err := self.client.ListAll("Microsoft.Network/loadBalancers", &lbs)
+	if err != nil {
+		return nil, err
+	}
+	result := []SLoadbalancer{}
+	for i := range lbs {
+		if lbs[i].Location == self.Name {
+			lbs[i].region = self
+			result = append(result, lbs[i])
+		}
+	}
+	return result, nil
+}
+
+func (self *SRegion) GetLoadbalancer(lbId string) (*SLoadbalancer, error) {
+	lb := SLoadbalancer{region: self}
+	return &lb, self.client.Get(lbId, []string{}, &lb)
+}
+
+func (self *SRegion) CreateLoadbalancer(loadbalancer *cloudprovider.SLoadbalancer) (*SLoadbalancer, error) {
+	frontend := SFrontendIPConfiguration{Name: "frontend"}
+	if loadbalancer.AddressType == api.LB_ADDR_TYPE_INTERNET {
+		eip, err := self.AllocateEIP(loadbalancer.Name)
+		if err != nil {
+			return nil, err
+		}
+		frontend.Properties.PublicIPAddress = &SubResource{ID: eip.ID}
+	} else {
+		frontend.Properties.Subnet = &SubResource{ID: loadbalancer.NetworkID}
+		frontend.Properties.PrivateIPAllocationMethod = "Dynamic"
+		if len(loadbalancer.Address) > 0 {
+			frontend.Properties.PrivateIPAddress = loadbalancer.Address
+			frontend.Properties.PrivateIPAllocationMethod = "Static"
+		}
+	}
+	lb := SLoadbalancer{
+		region:   self,
+		Name:     loadbalancer.Name,
+		Location: self.Name,
+		Type:     "Microsoft.Network/loadBalancers",
+		Sku:      &SLoadBalancerSku{Name: "Basic"},
+		Properties: SLoadBalancerProperties{
+			FrontendIPConfigurations: []SFrontendIPConfiguration{frontend},
+		},
+	}
+	err := self.client.Create(jsonutils.Marshal(lb), &lb)
+	if err != nil {
+		return nil, err
+	}
+	return &lb, cloudprovider.WaitStatus(&lb, api.LB_STATUS_ENABLED, 5*time.Second, 5*time.Minute)
+}
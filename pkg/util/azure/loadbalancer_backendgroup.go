@@ -0,0 +1,315 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/util/azure/concurrent"
+)
+
+type SLoadBalancerBackendPoolProperties struct {
+	BackendIPConfigurations []SubResource `json:"backendIPConfigurations,omitempty"`
+}
+
+// https://docs.microsoft.com/en-us/rest/api/load-balancer/loadbalancerbackendaddresspools
+// 后端地址池只记录网卡的ip配置, 成员关系保存在网卡上
+type SLoadbalancerBackendPool struct {
+	lb *SLoadbalancer
+
+	ID         string                             `json:"id,omitempty"`
+	Name       string                             `json:"name,omitempty"`
+	Properties SLoadBalancerBackendPoolProperties `json:"properties,omitempty"`
+}
+
+// 后端服务器为加入地址池的网卡ip配置
+type SLoadbalancerBackend struct {
+	pool *SLoadbalancerBackendPool
+
+	ID       string
+	serverId string
+}
+
+func (self *SLoadbalancerBackendPool) GetId() string {
+	return self.ID
+}
+
+func (self *SLoadbalancerBackendPool) GetName() string {
+	return self.Name
+}
+
+func (self *SLoadbalancerBackendPool) GetGlobalId() string {
+	return strings.ToLower(self.ID)
+}
+
+func (self *SLoadbalancerBackendPool) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SLoadbalancerBackendPool) Refresh() error {
+	lb, err := self.lb.region.GetLoadbalancer(self.lb.ID)
+	if err != nil {
+		return err
+	}
+	pool := lb.getBackendPool(self.ID)
+	if pool == nil {
+		return cloudprovider.ErrNotFound
+	}
+	return jsonutils.Update(self, pool)
+}
+
+func (self *SLoadbalancerBackendPool) IsEmulated() bool {
+	return false
+}
+
+func (self *SLoadbalancerBackendPool) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SLoadbalancerBackendPool) GetProjectId() string {
+	return getResourceGroup(self.ID)
+}
+
+func (self *SLoadbalancerBackendPool) IsDefault() bool {
+	return false
+}
+
+func (self *SLoadbalancerBackendPool) GetType() string {
+	return api.LB_BACKENDGROUP_TYPE_NORMAL
+}
+
+// 并发查询网卡所属的虚拟机
+func (self *SLoadbalancerBackendPool) GetILoadbalancerBackends() ([]cloudprovider.ICloudLoadbalancerBackend, error) {
+	backends := make([]SLoadbalancerBackend, len(self.Properties.BackendIPConfigurations))
+	requests := []*concurrent.Request{}
+	for i := range self.Properties.BackendIPConfigurations {
+		backend := &backends[i]
+		backend.pool = self
+		backend.ID = self.Properties.BackendIPConfigurations[i].ID
+		requests = append(requests, &concurrent.Request{
+			ID: backend.ID,
+			Work: func() error {
+				nic, err := self.lb.region.GetNetworkInterfaceDetail(ipConfigurationNicId(backend.ID))
+				if err != nil {
+					return err
+				}
+				if nic.Properties.VirtualMachine != nil {
+					backend.serverId = strings.ToLower(nic.Properties.VirtualMachine.ID)
+				}
+				return nil
+			},
+			ShouldRetry: func(err error) bool {
+				return err != cloudprovider.ErrNotFound
+			},
+		})
+	}
+	if err := runConcurrent(5, requests); err != nil {
+		return nil, err
+	}
+	ibackends := []cloudprovider.ICloudLoadbalancerBackend{}
+	for i := range backends {
+		if len(backends[i].serverId) == 0 {
+			log.Warningf("network interface of %s not attached to any virtual machine", backends[i].ID)
+			continue
+		}
+		ibackends = append(ibackends, &backends[i])
+	}
+	return ibackends, nil
+}
+
+func (self *SLoadbalancerBackendPool) AddBackendServer(serverId string, weight int, port int) (cloudprovider.ICloudLoadbalancerBackend, error) {
+	nic, ipConf, err := self.lb.region.getInstancePrimaryIPConfiguration(serverId)
+	if err != nil {
+		return nil, err
+	}
+	for _, pool := range ipConf.Properties.LoadBalancerBackendAddressPools {
+		if strings.ToLower(pool.ID) == strings.ToLower(self.ID) {
+			return &SLoadbalancerBackend{pool: self, ID: ipConf.ID, serverId: strings.ToLower(serverId)}, nil
+		}
+	}
+	ipConf.Properties.LoadBalancerBackendAddressPools = append(ipConf.Properties.LoadBalancerBackendAddressPools, SubResource{ID: self.ID})
+	if err := self.lb.region.client.Update(jsonutils.Marshal(nic), nil); err != nil {
+		return nil, err
+	}
+	return &SLoadbalancerBackend{pool: self, ID: ipConf.ID, serverId: strings.ToLower(serverId)}, nil
+}
+
+func (self *SLoadbalancerBackendPool) RemoveBackendServer(serverId string, weight int, port int) error {
+	instance, err := self.lb.region.GetInstance(serverId)
+	if err != nil {
+		if err == cloudprovider.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	for _, nicRef := range instance.Properties.NetworkProfile.NetworkInterfaces {
+		if err := self.removeNic(nicRef.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *SLoadbalancerBackendPool) removeNic(nicId string) error {
+	nic, err := self.lb.region.GetNetworkInterfaceDetail(nicId)
+	if err != nil {
+		if err == cloudprovider.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	changed := false
+	for i := range nic.Properties.IPConfigurations {
+		pools := []SubResource{}
+		for _, pool := range nic.Properties.IPConfigurations[i].Properties.LoadBalancerBackendAddressPools {
+			if strings.ToLower(pool.ID) == strings.ToLower(self.ID) {
+				changed = true
+				continue
+			}
+			pools = append(pools, pool)
+		}
+		nic.Properties.IPConfigurations[i].Properties.LoadBalancerBackendAddressPools = pools
+	}
+	if !changed {
+		return nil
+	}
+	return self.lb.region.client.Update(jsonutils.Marshal(nic), nil)
+}
+
+// 删除地址池前需要先从网卡上移除
+func (self *SLoadbalancerBackendPool) Delete() error {
+	for _, ipConf := range self.Properties.BackendIPConfigurations {
+		if err := self.removeNic(ipConfigurationNicId(ipConf.ID)); err != nil {
+			return err
+		}
+	}
+	lb := self.lb
+	pools := []SLoadbalancerBackendPool{}
+	for _, pool := range lb.Properties.BackendAddressPools {
+		if strings.ToLower(pool.ID) != strings.ToLower(self.ID) {
+			pools = append(pools, pool)
+		}
+	}
+	lb.Properties.BackendAddressPools = pools
+	return lb.update()
+}
+
+// 子资源名称不能修改
+func (self *SLoadbalancerBackendPool) Sync(name string) error {
+	if len(name) > 0 && name != self.Name {
+		return cloudprovider.ErrNotSupported
+	}
+	return nil
+}
+
+func (self *SLoadbalancerBackend) GetId() string {
+	return self.ID
+}
+
+func (self *SLoadbalancerBackend) GetName() string {
+	return ipConfigurationNicName(self.ID)
+}
+
+func (self *SLoadbalancerBackend) GetGlobalId() string {
+	return strings.ToLower(self.ID)
+}
+
+func (self *SLoadbalancerBackend) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SLoadbalancerBackend) Refresh() error {
+	return nil
+}
+
+func (self *SLoadbalancerBackend) IsEmulated() bool {
+	return false
+}
+
+func (self *SLoadbalancerBackend) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SLoadbalancerBackend) GetProjectId() string {
+	return getResourceGroup(self.ID)
+}
+
+// 不支持权重, 各后端平均分配
+func (self *SLoadbalancerBackend) GetWeight() int {
+	return 1
+}
+
+// 后端端口由负载均衡规则指定
+func (self *SLoadbalancerBackend) GetPort() int {
+	return 0
+}
+
+func (self *SLoadbalancerBackend) GetBackendType() string {
+	return api.LB_BACKEND_GUEST
+}
+
+func (self *SLoadbalancerBackend) GetBackendRole() string {
+	return api.LB_BACKEND_ROLE_DEFAULT
+}
+
+func (self *SLoadbalancerBackend) GetBackendId() string {
+	return self.serverId
+}
+
+// .../networkInterfaces/{nic}/ipConfigurations/{name} => .../networkInterfaces/{nic}
+func ipConfigurationNicId(ipConfId string) string {
+	if idx := strings.Index(strings.ToLower(ipConfId), "/ipconfigurations/"); idx > 0 {
+		return ipConfId[:idx]
+	}
+	return ipConfId
+}
+
+func ipConfigurationNicName(ipConfId string) string {
+	nicId := ipConfigurationNicId(ipConfId)
+	return nicId[strings.LastIndex(nicId, "/")+1:]
+}
+
+func (self *SRegion) getInstancePrimaryIPConfiguration(instanceId string) (*SInstanceNic, *InterfaceIPConfiguration, error) {
+	instance, err := self.GetInstance(instanceId)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, nicRef := range instance.Properties.NetworkProfile.NetworkInterfaces {
+		nic, err := self.GetNetworkInterfaceDetail(nicRef.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range nic.Properties.IPConfigurations {
+			primary := nic.Properties.IPConfigurations[i].Properties.Primary
+			if primary == nil || *primary {
+				return nic, &nic.Properties.IPConfigurations[i], nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("failed to find ip configuration of instance %s", instanceId)
+}
+
+func (self *SLoadbalancer) createBackendPool(group *cloudprovider.SLoadbalancerBackendGroup) (*SLoadbalancerBackendPool, error) {
+	name := self.uniqSubResourceName(group.Name, func(name string) bool {
+		return self.getBackendPool(self.subResourceId("backendAddressPools", name)) != nil
+	})
+	poolId := self.subResourceId("backendAddressPools", name)
+	self.Properties.BackendAddressPools = append(self.Properties.BackendAddressPools, SLoadbalancerBackendPool{ID: poolId, Name: name})
+	if err := self.update(); err != nil {
+		return nil, err
+	}
+	pool := self.getBackendPool(poolId)
+	if pool == nil {
+		return nil, cloudprovider.ErrNotFound
+	}
+	for _, backend := range group.Backends {
+		if _, err := pool.AddBackendServer(backend.ExternalID, backend.Weight, backend.Port); err != nil {
+			return nil, fmt.Errorf("backend pool %s created, but failed to add backend %s: %v", name, backend.ExternalID, err)
+		}
+	}
+	return pool, nil
+}
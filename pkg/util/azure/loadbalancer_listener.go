@@ -0,0 +1,376 @@
+package azure
+
+import (
+	"strings"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SLoadBalancingRuleProperties struct {
+	FrontendIPConfiguration *SubResource `json:"frontendIPConfiguration,omitempty"`
+	BackendAddressPool      *SubResource `json:"backendAddressPool,omitempty"`
+	Probe                   *SubResource `json:"probe,omitempty"`
+	Protocol                string       `json:"protocol,omitempty"`
+	LoadDistribution        string       `json:"loadDistribution,omitempty"`
+	FrontendPort            int          `json:"frontendPort"`
+	BackendPort             int          `json:"backendPort"`
+	IdleTimeoutInMinutes    int          `json:"idleTimeoutInMinutes,omitempty"`
+	EnableFloatingIP        bool         `json:"enableFloatingIP"`
+	DisableOutboundSnat     bool         `json:"disableOutboundSnat,omitempty"`
+}
+
+// https://docs.microsoft.com/en-us/rest/api/load-balancer/loadbalancerloadbalancingrules
+type SLoadbalancerListener struct {
+	lb *SLoadbalancer
+
+	ID         string                       `json:"id,omitempty"`
+	Name       string                       `json:"name,omitempty"`
+	Properties SLoadBalancingRuleProperties `json:"properties,omitempty"`
+}
+
+func (self *SLoadbalancerListener) GetId() string {
+	return self.ID
+}
+
+func (self *SLoadbalancerListener) GetName() string {
+	return self.Name
+}
+
+func (self *SLoadbalancerListener) GetGlobalId() string {
+	return strings.ToLower(self.ID)
+}
+
+func (self *SLoadbalancerListener) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SLoadbalancerListener) Refresh() error {
+	lb, err := self.lb.region.GetLoadbalancer(self.lb.ID)
+	if err != nil {
+		return err
+	}
+	lb.region = self.lb.region
+	listener := lb.getListener(self.ID)
+	if listener == nil {
+		return cloudprovider.ErrNotFound
+	}
+	return jsonutils.Update(self, listener)
+}
+
+func (self *SLoadbalancerListener) IsEmulated() bool {
+	return false
+}
+
+func (self *SLoadbalancerListener) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SLoadbalancerListener) GetProjectId() string {
+	return getResourceGroup(self.ID)
+}
+
+func (self *SLoadbalancerListener) getProbe() *SLoadBalancerProbe {
+	if self.Properties.Probe != nil {
+		return self.lb.getProbe(self.Properties.Probe.ID)
+	}
+	return nil
+}
+
+func (self *SLoadbalancerListener) GetListenerType() string {
+	if self.Properties.Protocol == "Udp" {
+		return api.LB_LISTENER_TYPE_UDP
+	}
+	return api.LB_LISTENER_TYPE_TCP
+}
+
+func (self *SLoadbalancerListener) GetListenerPort() int {
+	return self.Properties.FrontendPort
+}
+
+// 默认按五元组哈希分配, 会话保持通过源地址哈希实现
+func (self *SLoadbalancerListener) GetScheduler() string {
+	switch self.Properties.LoadDistribution {
+	case "SourceIP", "SourceIPProtocol":
+		return api.LB_SCHEDULER_SCH
+	default:
+		return api.LB_SCHEDULER_TCH
+	}
+}
+
+func loadDistribution(scheduler string) string {
+	if scheduler == api.LB_SCHEDULER_SCH {
+		return "SourceIP"
+	}
+	return "Default"
+}
+
+func (self *SLoadbalancerListener) GetAclStatus() string {
+	return api.LB_BOOL_OFF
+}
+
+func (self *SLoadbalancerListener) GetAclType() string {
+	return ""
+}
+
+func (self *SLoadbalancerListener) GetAclId() string {
+	return ""
+}
+
+func (self *SLoadbalancerListener) GetHealthCheck() string {
+	if self.getProbe() != nil {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (self *SLoadbalancerListener) GetHealthCheckType() string {
+	if probe := self.getProbe(); probe != nil && probe.Properties.Protocol != "Tcp" {
+		return api.LB_HEALTH_CHECK_HTTP
+	}
+	return api.LB_HEALTH_CHECK_TCP
+}
+
+func (self *SLoadbalancerListener) GetHealthCheckTimeout() int {
+	return 0
+}
+
+func (self *SLoadbalancerListener) GetHealthCheckInterval() int {
+	if probe := self.getProbe(); probe != nil {
+		return probe.Properties.IntervalInSeconds
+	}
+	return 0
+}
+
+// 连续探测失败指定次数后摘除, 一次探测成功即恢复
+func (self *SLoadbalancerListener) GetHealthCheckRise() int {
+	if self.getProbe() != nil {
+		return 1
+	}
+	return 0
+}
+
+func (self *SLoadbalancerListener) GetHealthCheckFail() int {
+	if probe := self.getProbe(); probe != nil {
+		return probe.Properties.NumberOfProbes
+	}
+	return 0
+}
+
+func (self *SLoadbalancerListener) GetHealthCheckReq() string {
+	return ""
+}
+
+func (self *SLoadbalancerListener) GetHealthCheckExp() string {
+	return ""
+}
+
+func (self *SLoadbalancerListener) GetBackendGroupId() string {
+	if self.Properties.BackendAddressPool != nil {
+		return strings.ToLower(self.Properties.BackendAddressPool.ID)
+	}
+	return ""
+}
+
+func (self *SLoadbalancerListener) GetBackendServerPort() int {
+	return self.Properties.BackendPort
+}
+
+func (self *SLoadbalancerListener) GetHealthCheckDomain() string {
+	return ""
+}
+
+func (self *SLoadbalancerListener) GetHealthCheckURI() string {
+	if probe := self.getProbe(); probe != nil {
+		return probe.Properties.RequestPath
+	}
+	return ""
+}
+
+// http探测只有返回200才认为健康
+func (self *SLoadbalancerListener) GetHealthCheckCode() string {
+	if self.GetHealthCheckType() == api.LB_HEALTH_CHECK_HTTP {
+		return api.LB_HEALTH_CHECK_HTTP_CODE_2xx
+	}
+	return ""
+}
+
+func (self *SLoadbalancerListener) CreateILoadBalancerListenerRule(rule *cloudprovider.SLoadbalancerListenerRule) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SLoadbalancerListener) GetILoadBalancerListenerRuleById(ruleId string) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SLoadbalancerListener) GetILoadbalancerListenerRules() ([]cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	return []cloudprovider.ICloudLoadbalancerListenerRule{}, nil
+}
+
+func (self *SLoadbalancerListener) GetStickySession() string {
+	return api.LB_BOOL_OFF
+}
+
+func (self *SLoadbalancerListener) GetStickySessionType() string {
+	return ""
+}
+
+func (self *SLoadbalancerListener) GetStickySessionCookie() string {
+	return ""
+}
+
+func (self *SLoadbalancerListener) GetStickySessionCookieTimeout() int {
+	return 0
+}
+
+func (self *SLoadbalancerListener) XForwardedForEnabled() bool {
+	return false
+}
+
+func (self *SLoadbalancerListener) GzipEnabled() bool {
+	return false
+}
+
+func (self *SLoadbalancerListener) GetCertificateId() string {
+	return ""
+}
+
+func (self *SLoadbalancerListener) GetTLSCipherPolicy() string {
+	return ""
+}
+
+func (self *SLoadbalancerListener) HTTP2Enabled() bool {
+	return false
+}
+
+func (self *SLoadbalancerListener) Start() error {
+	return nil
+}
+
+func (self *SLoadbalancerListener) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SLoadbalancerListener) Sync(listener *cloudprovider.SLoadbalancerListener) error {
+	lb := self.lb
+	current := lb.getListener(self.ID)
+	if current == nil {
+		return cloudprovider.ErrNotFound
+	}
+	lb.setListener(current, listener)
+	if err := lb.update(); err != nil {
+		return err
+	}
+	return self.Refresh()
+}
+
+func (self *SLoadbalancerListener) Delete() error {
+	lb := self.lb
+	rules := []SLoadbalancerListener{}
+	for _, rule := range lb.Properties.LoadBalancingRules {
+		if strings.ToLower(rule.ID) != strings.ToLower(self.ID) {
+			rules = append(rules, rule)
+		}
+	}
+	lb.Properties.LoadBalancingRules = rules
+	if self.Properties.Probe != nil {
+		lb.removeProbe(self.Properties.Probe.ID)
+	}
+	return lb.update()
+}
+
+// 探测与规则同名, 不再被其它规则使用时一并删除
+func (self *SLoadbalancer) removeProbe(probeId string) {
+	for _, rule := range self.Properties.LoadBalancingRules {
+		if rule.Properties.Probe != nil && strings.ToLower(rule.Properties.Probe.ID) == strings.ToLower(probeId) {
+			return
+		}
+	}
+	probes := []SLoadBalancerProbe{}
+	for _, probe := range self.Properties.Probes {
+		if strings.ToLower(probe.ID) != strings.ToLower(probeId) {
+			probes = append(probes, probe)
+		}
+	}
+	self.Properties.Probes = probes
+}
+
+func (self *SLoadbalancer) setListener(rule *SLoadbalancerListener, listener *cloudprovider.SLoadbalancerListener) {
+	protocol := "Tcp"
+	if listener.ListenerType == api.LB_LISTENER_TYPE_UDP {
+		protocol = "Udp"
+	}
+	rule.Properties.Protocol = protocol
+	rule.Properties.FrontendPort = listener.ListenerPort
+	rule.Properties.BackendPort = listener.BackendServerPort
+	if rule.Properties.BackendPort == 0 {
+		rule.Properties.BackendPort = listener.ListenerPort
+	}
+	rule.Properties.LoadDistribution = loadDistribution(listener.Scheduler)
+	// 空闲超时以分钟为单位, 范围4-30
+	minutes := (listener.EstablishedTimeout + 59) / 60
+	if minutes < 4 {
+		minutes = 4
+	} else if minutes > 30 {
+		minutes = 30
+	}
+	rule.Properties.IdleTimeoutInMinutes = minutes
+	if frontend := self.getFrontend(); frontend != nil {
+		rule.Properties.FrontendIPConfiguration = &SubResource{ID: frontend.ID}
+	}
+	rule.Properties.BackendAddressPool = nil
+	if len(listener.BackendGroupID) > 0 {
+		rule.Properties.BackendAddressPool = &SubResource{ID: listener.BackendGroupID}
+	}
+
+	probeId := self.subResourceId("probes", rule.Name)
+	if listener.HealthCheck != api.LB_BOOL_ON {
+		rule.Properties.Probe = nil
+		self.removeProbe(probeId)
+		return
+	}
+	probe := self.getProbe(probeId)
+	if probe == nil {
+		self.Properties.Probes = append(self.Properties.Probes, SLoadBalancerProbe{ID: probeId, Name: rule.Name})
+		probe = &self.Properties.Probes[len(self.Properties.Probes)-1]
+	}
+	probe.Properties = SLoadBalancerProbeProperties{
+		Protocol:          "Tcp",
+		Port:              rule.Properties.BackendPort,
+		IntervalInSeconds: listener.HealthCheckInterval,
+		NumberOfProbes:    listener.HealthCheckFail,
+	}
+	if len(listener.HealthCheckURI) > 0 {
+		probe.Properties.Protocol = "Http"
+		probe.Properties.RequestPath = listener.HealthCheckURI
+	}
+	if probe.Properties.IntervalInSeconds < 5 {
+		probe.Properties.IntervalInSeconds = 5
+	}
+	if probe.Properties.NumberOfProbes < 1 {
+		probe.Properties.NumberOfProbes = 2
+	}
+	rule.Properties.Probe = &SubResource{ID: probeId}
+}
+
+func (self *SLoadbalancer) createListener(listener *cloudprovider.SLoadbalancerListener) (*SLoadbalancerListener, error) {
+	name := self.uniqSubResourceName(listener.Name, func(name string) bool {
+		return self.getListener(self.subResourceId("loadBalancingRules", name)) != nil || self.getProbe(self.subResourceId("probes", name)) != nil
+	})
+	rule := SLoadbalancerListener{
+		ID:   self.subResourceId("loadBalancingRules", name),
+		Name: name,
+	}
+	self.setListener(&rule, listener)
+	self.Properties.LoadBalancingRules = append(self.Properties.LoadBalancingRules, rule)
+	if err := self.update(); err != nil {
+		return nil, err
+	}
+	ret := self.getListener(rule.ID)
+	if ret == nil {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return ret, nil
+}
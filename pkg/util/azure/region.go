@@ -520,41 +520,115 @@ func (region *SRegion) SyncSecurityGroup(secgroupId, vpcId, name, desc string, r
 }
 
 func (region *SRegion) GetILoadBalancers() ([]cloudprovider.ICloudLoadbalancer, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	lbs, err := region.GetLoadbalancers()
+	if err != nil {
+		return nil, err
+	}
+	gws, err := region.GetApplicationGateways()
+	if err != nil {
+		return nil, err
+	}
+	ilbs := []cloudprovider.ICloudLoadbalancer{}
+	for i := range lbs {
+		ilbs = append(ilbs, &lbs[i])
+	}
+	for i := range gws {
+		ilbs = append(ilbs, &gws[i])
+	}
+	return ilbs, nil
 }
 
 func (region *SRegion) GetILoadBalancerById(loadbalancerId string) (cloudprovider.ICloudLoadbalancer, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	if isApplicationGatewayId(loadbalancerId) {
+		gw, err := region.GetApplicationGateway(loadbalancerId)
+		if err != nil {
+			return nil, err
+		}
+		return gw, nil
+	}
+	lb, err := region.GetLoadbalancer(loadbalancerId)
+	if err != nil {
+		return nil, err
+	}
+	return lb, nil
 }
 
 func (region *SRegion) GetILoadBalancerAclById(aclId string) (cloudprovider.ICloudLoadbalancerAcl, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return nil, cloudprovider.ErrNotFound
 }
 
 func (region *SRegion) GetILoadBalancerCertificateById(certId string) (cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	gw, err := region.GetApplicationGateway(applicationGatewayId(certId))
+	if err != nil {
+		return nil, err
+	}
+	cert := gw.getCertificate(certId)
+	if cert == nil {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return cert, nil
 }
 
+// 证书需要以PFX格式随应用网关一起更新, 不支持单独创建
 func (region *SRegion) CreateILoadBalancerCertificate(cert *cloudprovider.SLoadbalancerCertificate) (cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return nil, cloudprovider.ErrNotSupported
 }
 
 func (region *SRegion) GetILoadBalancerAcls() ([]cloudprovider.ICloudLoadbalancerAcl, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return []cloudprovider.ICloudLoadbalancerAcl{}, nil
 }
 
 func (region *SRegion) GetILoadBalancerCertificates() ([]cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	gws, err := region.GetApplicationGateways()
+	if err != nil {
+		return nil, err
+	}
+	icerts := []cloudprovider.ICloudLoadbalancerCertificate{}
+	for i := range gws {
+		for j := range gws[i].Properties.SslCertificates {
+			gws[i].Properties.SslCertificates[j].gw = &gws[i]
+			icerts = append(icerts, &gws[i].Properties.SslCertificates[j])
+		}
+	}
+	return icerts, nil
 }
 
 func (region *SRegion) CreateILoadBalancer(loadbalancer *cloudprovider.SLoadbalancer) (cloudprovider.ICloudLoadbalancer, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	lb, err := region.CreateLoadbalancer(loadbalancer)
+	if err != nil {
+		return nil, err
+	}
+	return lb, nil
 }
 
 func (region *SRegion) CreateILoadBalancerAcl(acl *cloudprovider.SLoadbalancerAccessControlList) (cloudprovider.ICloudLoadbalancerAcl, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return nil, cloudprovider.ErrNotSupported
 }
 
 func (region *SRegion) GetSkus(zoneId string) ([]cloudprovider.ICloudSku, error) {
 	return nil, cloudprovider.ErrNotImplemented
 }
+
+func (region *SRegion) GetIBuckets() ([]cloudprovider.ICloudBucket, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (region *SRegion) GetIBucketById(name string) (cloudprovider.ICloudBucket, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (region *SRegion) CreateIBucket(name string, storageClassStr string, acl string) error {
+	return cloudprovider.ErrNotImplemented
+}
+
+func (region *SRegion) DeleteIBucket(name string) error {
+	return cloudprovider.ErrNotImplemented
+}
+
+func (region *SRegion) GetIDBInstances() ([]cloudprovider.ICloudDBInstance, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (region *SRegion) GetIDBInstanceById(instanceId string) (cloudprovider.ICloudDBInstance, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
@@ -0,0 +1,71 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/util/azure"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+func init() {
+	type LoadbalancerListOptions struct {
+		Offset int `help:"List offset"`
+		Limit  int `help:"List limit"`
+	}
+	shellutils.R(&LoadbalancerListOptions{}, "lb-list", "List loadbalancers", func(cli *azure.SRegion, args *LoadbalancerListOptions) error {
+		lbs, err := cli.GetLoadbalancers()
+		if err != nil {
+			return err
+		}
+		printList(lbs, len(lbs), args.Offset, args.Limit, []string{})
+		return nil
+	})
+
+	type LoadbalancerShowOptions struct {
+		ID string `help:"Loadbalancer ID"`
+	}
+	shellutils.R(&LoadbalancerShowOptions{}, "lb-show", "Show loadbalancer", func(cli *azure.SRegion, args *LoadbalancerShowOptions) error {
+		lb, err := cli.GetLoadbalancer(args.ID)
+		if err != nil {
+			return err
+		}
+		printObject(lb)
+		return nil
+	})
+
+	shellutils.R(&LoadbalancerShowOptions{}, "lb-delete", "Delete loadbalancer", func(cli *azure.SRegion, args *LoadbalancerShowOptions) error {
+		lb, err := cli.GetLoadbalancer(args.ID)
+		if err != nil {
+			return err
+		}
+		return lb.Delete()
+	})
+
+	shellutils.R(&LoadbalancerListOptions{}, "appgateway-list", "List application gateways", func(cli *azure.SRegion, args *LoadbalancerListOptions) error {
+		gws, err := cli.GetApplicationGateways()
+		if err != nil {
+			return err
+		}
+		printList(gws, len(gws), args.Offset, args.Limit, []string{})
+		return nil
+	})
+
+	type ApplicationGatewayShowOptions struct {
+		ID string `help:"Application gateway ID"`
+	}
+	shellutils.R(&ApplicationGatewayShowOptions{}, "appgateway-show", "Show application gateway", func(cli *azure.SRegion, args *ApplicationGatewayShowOptions) error {
+		gw, err := cli.GetApplicationGateway(args.ID)
+		if err != nil {
+			return err
+		}
+		printObject(gw)
+		return nil
+	})
+
+	shellutils.R(&LoadbalancerListOptions{}, "lb-cert-list", "List application gateway certificates", func(cli *azure.SRegion, args *LoadbalancerListOptions) error {
+		certs, err := cli.GetILoadBalancerCertificates()
+		if err != nil {
+			return err
+		}
+		printList(certs, len(certs), args.Offset, args.Limit, []string{})
+		return nil
+	})
+}
@@ -145,6 +145,26 @@ func (self *SVpc) GetIRouteTables() ([]cloudprovider.ICloudRouteTable, error) {
 	return rts, nil
 }
 
+func (self *SVpc) GetINatGateways() ([]cloudprovider.ICloudNatGateway, error) {
+	return []cloudprovider.ICloudNatGateway{}, nil
+}
+
+func (self *SVpc) CreateINatGateway(opts *cloudprovider.SNatGatewayCreateOptions) (cloudprovider.ICloudNatGateway, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (self *SVpc) GetIVpcPeerings() ([]cloudprovider.ICloudVpcPeering, error) {
+	return []cloudprovider.ICloudVpcPeering{}, nil
+}
+
+func (self *SVpc) GetIVpcPeeringById(id string) (cloudprovider.ICloudVpcPeering, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SVpc) CreateIVpcPeering(opts *cloudprovider.SVpcPeeringCreateOptions) (cloudprovider.ICloudVpcPeering, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
 func (self *SVpc) fetchWires() error {
 	networks := make([]cloudprovider.ICloudNetwork, len(*self.Properties.Subnets))
 	if len(self.region.izones) == 0 {
@@ -497,6 +497,7 @@ type CGroupCPUSetTask struct {
 	*CGroupTask
 
 	cpuset string
+	mems   string
 }
 
 const (
@@ -509,6 +510,9 @@ func (c *CGroupCPUSetTask) Module() string {
 }
 
 func (c *CGroupCPUSetTask) GetStaticConfig() map[string]string {
+	if len(c.mems) > 0 {
+		return map[string]string{CPUSET_MEMS: c.mems}
+	}
 	return map[string]string{CPUSET_MEMS: GetRootParam(c.Module(), CPUSET_MEMS, "")}
 }
 
@@ -525,6 +529,18 @@ func NewCGroupCPUSetTask(pid string, coreNum int, cpuset string) CGroupCPUSetTas
 	return task
 }
 
+// NewCGroupCPUSetTaskWithMems also binds the memory of the process to the
+// given numa nodes
+func NewCGroupCPUSetTaskWithMems(pid string, coreNum int, cpuset, mems string) CGroupCPUSetTask {
+	task := CGroupCPUSetTask{
+		CGroupTask: NewCGroupTask(pid, coreNum),
+		cpuset:     cpuset,
+		mems:       mems,
+	}
+	task.SetHand(&task)
+	return task
+}
+
 func Init() bool {
 	for _, hand := range []ICGroupTask{&CGroupTask{}, &CGroupCPUTask{}, &CGroupIOTask{}} {
 		if !hand.init() {
@@ -562,7 +578,7 @@ func CgroupDestroy(pid string) bool {
 		&CGroupCPUTask{&CGroupTask{}},
 		&CGroupIOTask{&CGroupTask{}},
 		&CGroupMemoryTask{&CGroupTask{}},
-		&CGroupCPUSetTask{&CGroupTask{}, ""},
+		&CGroupCPUSetTask{CGroupTask: &CGroupTask{}},
 		&CGroupIOHardlimitTask{CGroupIOTask: &CGroupIOTask{&CGroupTask{}}},
 	}
 	for _, hand := range tasks {
@@ -0,0 +1,204 @@
+package cronexpr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// how far Next/Prev search before giving up on an expression that never matches, e.g. "0 0 31 2 *"
+	searchYears = 5
+)
+
+type sField struct {
+	name     string
+	min, max int
+}
+
+var (
+	fieldMinute = sField{name: "minute", min: 0, max: 59}
+	fieldHour   = sField{name: "hour", min: 0, max: 23}
+	fieldDom    = sField{name: "day of month", min: 1, max: 31}
+	fieldMonth  = sField{name: "month", min: 1, max: 12}
+	fieldDow    = sField{name: "day of week", min: 0, max: 7}
+)
+
+// SCronExpr is a standard 5-field cron expression: minute hour day-of-month month day-of-week
+//
+// Each field accepts *, single values, ranges (a-b), steps (*/n, a-b/n) and comma separated lists.
+// Day of week 0 and 7 are both Sunday.  As with cron(8), when both day of month and day of week
+// are restricted, a time matches if either of them matches.
+type SCronExpr struct {
+	expr string
+
+	minutes uint64
+	hours   uint64
+	doms    uint64
+	months  uint64
+	dows    uint64
+
+	domStar bool
+	dowStar bool
+}
+
+func Parse(expr string) (*SCronExpr, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron expression %q: expect 5 fields, got %d", expr, len(parts))
+	}
+	ce := &SCronExpr{expr: strings.Join(parts, " ")}
+	var err error
+	if ce.minutes, err = parseField(parts[0], fieldMinute); err != nil {
+		return nil, err
+	}
+	if ce.hours, err = parseField(parts[1], fieldHour); err != nil {
+		return nil, err
+	}
+	if ce.doms, err = parseField(parts[2], fieldDom); err != nil {
+		return nil, err
+	}
+	if ce.months, err = parseField(parts[3], fieldMonth); err != nil {
+		return nil, err
+	}
+	if ce.dows, err = parseField(parts[4], fieldDow); err != nil {
+		return nil, err
+	}
+	if ce.dows&(1<<7) != 0 {
+		ce.dows |= 1
+	}
+	ce.domStar = parts[2] == "*" || parts[2] == "?"
+	ce.dowStar = parts[4] == "*" || parts[4] == "?"
+	return ce, nil
+}
+
+func parseField(str string, field sField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(str, ",") {
+		if len(item) == 0 {
+			return 0, fmt.Errorf("empty item in %s field %q", field.name, str)
+		}
+		rng, step := item, 1
+		if pos := strings.Index(item, "/"); pos >= 0 {
+			rng = item[:pos]
+			val, err := strconv.Atoi(item[pos+1:])
+			if err != nil || val <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field %q", field.name, item)
+			}
+			step = val
+		}
+		start, end := field.min, field.max
+		if rng != "*" && rng != "?" {
+			var err error
+			if pos := strings.Index(rng, "-"); pos >= 0 {
+				if start, err = parseValue(rng[:pos], field); err != nil {
+					return 0, err
+				}
+				if end, err = parseValue(rng[pos+1:], field); err != nil {
+					return 0, err
+				}
+				if start > end {
+					return 0, fmt.Errorf("invalid range in %s field %q", field.name, item)
+				}
+			} else {
+				if start, err = parseValue(rng, field); err != nil {
+					return 0, err
+				}
+				if step == 1 {
+					end = start
+				}
+			}
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(str string, field sField) (int, error) {
+	val, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", field.name, str)
+	}
+	if val < field.min || val > field.max {
+		return 0, fmt.Errorf("%s value %d out of range [%d, %d]", field.name, val, field.min, field.max)
+	}
+	return val, nil
+}
+
+func (ce *SCronExpr) String() string {
+	return ce.expr
+}
+
+func (ce *SCronExpr) dayMatch(t time.Time) bool {
+	domMatch := ce.doms&(1<<uint(t.Day())) != 0
+	dowMatch := ce.dows&(1<<uint(t.Weekday())) != 0
+	if ce.domStar || ce.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Match reports whether the minute of t is a scheduled point of the expression
+func (ce *SCronExpr) Match(t time.Time) bool {
+	return ce.months&(1<<uint(t.Month())) != 0 &&
+		ce.dayMatch(t) &&
+		ce.hours&(1<<uint(t.Hour())) != 0 &&
+		ce.minutes&(1<<uint(t.Minute())) != 0
+}
+
+// Next returns the earliest scheduled time strictly after t, or zero time if none is found
+func (ce *SCronExpr) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchYears, 0, 0)
+	for t.Before(limit) {
+		if ce.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !ce.dayMatch(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if ce.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if ce.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// Prev returns the latest scheduled time not after t, or zero time if none is found
+func (ce *SCronExpr) Prev(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute)
+	limit := t.AddDate(-searchYears, 0, 0)
+	for t.After(limit) {
+		if ce.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if !ce.dayMatch(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if ce.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if ce.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(-time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,108 @@
+package cronexpr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for _, expr := range []string{
+		"* * * * *",
+		"0 2 * * *",
+		"*/15 0-6,22 * * 1-5",
+		"30 3 1,15 * ?",
+		"0 0 * * 7",
+		"5-50/5 * * 2-11/3 *",
+	} {
+		if _, err := Parse(expr); err != nil {
+			t.Errorf("parse %q: %s", expr, err)
+		}
+	}
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("parse %q: expect error", expr)
+		}
+	}
+}
+
+func TestNextPrev(t *testing.T) {
+	base := time.Date(2019, 2, 27, 10, 20, 30, 0, time.UTC)
+	cases := []struct {
+		expr string
+		next time.Time
+		prev time.Time
+	}{
+		{
+			expr: "* * * * *",
+			next: time.Date(2019, 2, 27, 10, 21, 0, 0, time.UTC),
+			prev: time.Date(2019, 2, 27, 10, 20, 0, 0, time.UTC),
+		},
+		{
+			expr: "0 2 * * *",
+			next: time.Date(2019, 2, 28, 2, 0, 0, 0, time.UTC),
+			prev: time.Date(2019, 2, 27, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			expr: "*/15 * * * *",
+			next: time.Date(2019, 2, 27, 10, 30, 0, 0, time.UTC),
+			prev: time.Date(2019, 2, 27, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			// 2019-02-27 is a Wednesday
+			expr: "0 3 * * 0",
+			next: time.Date(2019, 3, 3, 3, 0, 0, 0, time.UTC),
+			prev: time.Date(2019, 2, 24, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			expr: "0 3 * * 7",
+			next: time.Date(2019, 3, 3, 3, 0, 0, 0, time.UTC),
+			prev: time.Date(2019, 2, 24, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			expr: "0 0 1 * *",
+			next: time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+			prev: time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// either day of month or day of week
+			expr: "0 0 1 * 3",
+			next: time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+			prev: time.Date(2019, 2, 27, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			expr: "0 12 29 2 *",
+			next: time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC),
+			prev: time.Date(2016, 2, 29, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			expr: "0 0 31 2 *",
+		},
+	}
+	for _, c := range cases {
+		ce, err := Parse(c.expr)
+		if err != nil {
+			t.Fatalf("parse %q: %s", c.expr, err)
+		}
+		if got := ce.Next(base); !got.Equal(c.next) {
+			t.Errorf("%q next: want %s, got %s", c.expr, c.next, got)
+		}
+		if got := ce.Prev(base); !got.Equal(c.prev) {
+			t.Errorf("%q prev: want %s, got %s", c.expr, c.prev, got)
+		}
+		if !c.next.IsZero() && !ce.Match(c.next) {
+			t.Errorf("%q: %s should match", c.expr, c.next)
+		}
+	}
+}
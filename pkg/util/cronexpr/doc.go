@@ -0,0 +1 @@
+package cronexpr // import "yunion.io/x/onecloud/pkg/util/cronexpr"
@@ -0,0 +1,114 @@
+package esxi
+
+import (
+	"strings"
+
+	"github.com/vmware/govmomi/vim25/types"
+
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/netutils"
+)
+
+const (
+	// Windows时区索引, 210为中国标准时间
+	WINDOWS_TIMEZONE_CHINA = 210
+	LINUX_TIMEZONE_CHINA   = "Asia/Shanghai"
+
+	WINDOWS_HOSTNAME_MAX_LENGTH = 15
+	LINUX_HOSTNAME_MAX_LENGTH   = 63
+)
+
+type SCustomizeNicConfig struct {
+	IpAddr  string
+	IpMask  int8
+	Gateway string
+	Dns     string
+}
+
+// 主机名只能包含字母数字及中划线
+func toHostname(name string, maxLen int) string {
+	hostname := make([]rune, 0, len(name))
+	for _, c := range name {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' {
+			hostname = append(hostname, c)
+		} else {
+			hostname = append(hostname, '-')
+		}
+	}
+	ret := strings.Trim(string(hostname), "-")
+	if len(ret) > maxLen {
+		ret = strings.Trim(ret[:maxLen], "-")
+	}
+	if len(ret) == 0 {
+		ret = "localhost"
+	}
+	return ret
+}
+
+func newCustomizationIdentity(osType string, name string, password string) types.BaseCustomizationIdentitySettings {
+	if osType == string(WINDOWS) {
+		sysprep := &types.CustomizationSysprep{
+			GuiUnattended: types.CustomizationGuiUnattended{
+				TimeZone:  WINDOWS_TIMEZONE_CHINA,
+				AutoLogon: false,
+			},
+			UserData: types.CustomizationUserData{
+				FullName:     "Administrator",
+				OrgName:      "Administrator",
+				ComputerName: &types.CustomizationFixedName{Name: toHostname(name, WINDOWS_HOSTNAME_MAX_LENGTH)},
+			},
+			Identification: types.CustomizationIdentification{
+				JoinWorkgroup: "WORKGROUP",
+			},
+		}
+		if len(password) > 0 {
+			sysprep.GuiUnattended.Password = &types.CustomizationPassword{
+				Value:     password,
+				PlainText: true,
+			}
+		}
+		return sysprep
+	}
+	// Linux自定义规范不支持设置密码, 密码需由模板内预置的cloud-init等工具设置
+	if len(password) > 0 {
+		log.Warningf("password is not supported by linux customization of %s", name)
+	}
+	hwClockUTC := true
+	return &types.CustomizationLinuxPrep{
+		HostName:   &types.CustomizationFixedName{Name: toHostname(name, LINUX_HOSTNAME_MAX_LENGTH)},
+		Domain:     "localdomain",
+		TimeZone:   LINUX_TIMEZONE_CHINA,
+		HwClockUTC: &hwClockUTC,
+	}
+}
+
+func newCustomizationAdapter(nic *SCustomizeNicConfig) types.CustomizationAdapterMapping {
+	adapter := types.CustomizationAdapterMapping{}
+	if nic == nil || len(nic.IpAddr) == 0 {
+		adapter.Adapter.Ip = &types.CustomizationDhcpIpGenerator{}
+		return adapter
+	}
+	adapter.Adapter.Ip = &types.CustomizationFixedIp{IpAddress: nic.IpAddr}
+	adapter.Adapter.SubnetMask = netutils.Masklen2Mask(nic.IpMask).String()
+	if len(nic.Gateway) > 0 {
+		adapter.Adapter.Gateway = []string{nic.Gateway}
+	}
+	if len(nic.Dns) > 0 {
+		adapter.Adapter.DnsServerList = []string{nic.Dns}
+	}
+	return adapter
+}
+
+// nics为空时不修改网卡配置
+func newCustomizationSpec(osType string, name string, password string, nics []SCustomizeNicConfig) types.CustomizationSpec {
+	spec := types.CustomizationSpec{
+		Identity: newCustomizationIdentity(osType, name, password),
+	}
+	for i := 0; i < len(nics); i += 1 {
+		spec.NicSettingMap = append(spec.NicSettingMap, newCustomizationAdapter(&nics[i]))
+		if len(nics[i].Dns) > 0 && len(spec.GlobalIPSettings.DnsServerList) == 0 {
+			spec.GlobalIPSettings.DnsServerList = []string{nics[i].Dns}
+		}
+	}
+	return spec
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 
@@ -169,7 +170,7 @@ func (self *SHost) fetchVMs() error {
 	return nil
 }
 
-func (self *SHost) GetIVMs() ([]cloudprovider.ICloudVM, error) {
+func (self *SHost) getAllVMs() ([]cloudprovider.ICloudVM, error) {
 	err := self.fetchVMs()
 	if err != nil {
 		return nil, err
@@ -177,6 +178,21 @@ func (self *SHost) GetIVMs() ([]cloudprovider.ICloudVM, error) {
 	return self.vms, nil
 }
 
+// 模板作为镜像同步, 不作为虚拟机返回
+func (self *SHost) GetIVMs() ([]cloudprovider.ICloudVM, error) {
+	vms, err := self.getAllVMs()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]cloudprovider.ICloudVM, 0)
+	for i := 0; i < len(vms); i += 1 {
+		if !vms[i].(*SVirtualMachine).IsTemplate() {
+			ret = append(ret, vms[i])
+		}
+	}
+	return ret, nil
+}
+
 func (self *SHost) GetIVMById(id string) (cloudprovider.ICloudVM, error) {
 	id = self.manager.getPrivateId(id)
 
@@ -521,7 +537,78 @@ func (self *SHost) GetVersion() string {
 }
 
 func (self *SHost) CreateVM(desc *cloudprovider.SManagedVMCreateConfig) (cloudprovider.ICloudVM, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	ctx := context.Background()
+
+	temp, err := self.findTemplate(desc.ExternalImageId)
+	if err != nil {
+		log.Errorf("fail to find template %s: %s", desc.ExternalImageId, err)
+		return nil, err
+	}
+	ds, err := self.pickDatastore(desc.SysDisk.StorageType, desc.SysDisk.SizeGB)
+	if err != nil {
+		return nil, err
+	}
+	vm, err := temp.vm.cloneVM(ctx, self, ds, desc)
+	if err != nil {
+		return nil, err
+	}
+	// 清除缓存的虚拟机列表以便查找到新建的虚拟机
+	self.vms = nil
+	return vm, nil
+}
+
+// 模板可能位于主机可访问的任意存储上
+func (self *SHost) findTemplate(extId string) (*SVMTemplate, error) {
+	istorages, err := self.GetIStorages()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(istorages); i += 1 {
+		cache, ok := istorages[i].GetIStoragecache().(*SDatastoreImageCache)
+		if !ok || cache == nil {
+			continue
+		}
+		temp, err := cache.getTemplateById(extId)
+		if err == nil {
+			return temp, nil
+		}
+		if err != cloudprovider.ErrNotFound {
+			return nil, err
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+// 选择满足存储类型且剩余空间最大的存储
+func (self *SHost) pickDatastore(storageType string, sizeGb int) (*SDatastore, error) {
+	istorages, err := self.GetIStorages()
+	if err != nil {
+		return nil, err
+	}
+	var maxDs *SDatastore
+	var maxFree int64
+	for i := 0; i < len(istorages); i += 1 {
+		ds := istorages[i].(*SDatastore)
+		if ds.GetStatus() != models.STORAGE_ONLINE {
+			continue
+		}
+		if len(storageType) > 0 && ds.GetStorageType() != storageType {
+			continue
+		}
+		free := ds.getDatastore().Summary.FreeSpace
+		if free > maxFree {
+			maxFree = free
+			maxDs = ds
+		}
+	}
+	if maxDs == nil || maxFree < int64(sizeGb)*1024*1024*1024 {
+		return nil, fmt.Errorf("no datastore of type %s with %dGB free space on host %s", storageType, sizeGb, self.GetName())
+	}
+	return maxDs, nil
+}
+
+func (self *SHost) getHostObj() *object.HostSystem {
+	return object.NewHostSystem(self.manager.client.Client, self.getHostSystem().Self)
 }
 
 func (host *SHost) GetIHostNics() ([]cloudprovider.ICloudHostNetInterface, error) {
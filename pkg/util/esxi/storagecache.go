@@ -105,9 +105,52 @@ func (self *SDatastoreImageCache) GetIImages() ([]cloudprovider.ICloudImage, err
 		}
 	}
 
+	templates, err := self.getTemplates()
+	if err != nil {
+		log.Errorf("GetIImages getTemplates fail %s", err)
+		return nil, err
+	}
+	for i := 0; i < len(templates); i += 1 {
+		ret = append(ret, templates[i])
+	}
+
 	return ret, nil
 }
 
+func (self *SDatastoreImageCache) getTemplates() ([]*SVMTemplate, error) {
+	var vms []cloudprovider.ICloudVM
+	var err error
+	if self.host != nil {
+		vms, err = self.host.getAllVMs()
+	} else {
+		vms, err = self.datastore.getVMs()
+	}
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]*SVMTemplate, 0)
+	for i := 0; i < len(vms); i += 1 {
+		vm := vms[i].(*SVirtualMachine)
+		if vm.IsTemplate() {
+			templates = append(templates, NewVMTemplate(self, vm))
+		}
+	}
+	return templates, nil
+}
+
+func (self *SDatastoreImageCache) getTemplateById(extId string) (*SVMTemplate, error) {
+	templates, err := self.getTemplates()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(templates); i += 1 {
+		if templates[i].GetGlobalId() == extId {
+			return templates[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
 func (self *SDatastoreImageCache) GetIImageById(extId string) (cloudprovider.ICloudImage, error) {
 	images, err := self.GetIImages()
 	if err != nil {
@@ -0,0 +1,115 @@
+package esxi
+
+import (
+	"context"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// 虚拟机模板作为存储缓存中的镜像, 创建虚拟机时从模板克隆
+type SVMTemplate struct {
+	cache *SDatastoreImageCache
+	vm    *SVirtualMachine
+}
+
+func NewVMTemplate(cache *SDatastoreImageCache, vm *SVirtualMachine) *SVMTemplate {
+	return &SVMTemplate{cache: cache, vm: vm}
+}
+
+func (self *SVMTemplate) GetId() string {
+	return self.vm.getUuid()
+}
+
+func (self *SVMTemplate) GetName() string {
+	return self.vm.GetName()
+}
+
+func (self *SVMTemplate) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SVMTemplate) GetStatus() string {
+	return models.CACHED_IMAGE_STATUS_READY
+}
+
+func (self *SVMTemplate) GetImageStatus() string {
+	return cloudprovider.IMAGE_STATUS_ACTIVE
+}
+
+func (self *SVMTemplate) Refresh() error {
+	return self.vm.Refresh()
+}
+
+func (self *SVMTemplate) IsEmulated() bool {
+	return false
+}
+
+func (self *SVMTemplate) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+// 模板由vCenter管理员维护, 不随镜像缓存清理
+func (self *SVMTemplate) Delete(ctx context.Context) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SVMTemplate) GetIStoragecache() cloudprovider.ICloudStoragecache {
+	return self.cache
+}
+
+func (self *SVMTemplate) GetImageType() string {
+	return cloudprovider.CachedImageTypeCustomized
+}
+
+func (self *SVMTemplate) GetSize() int64 {
+	var size int64
+	for i := 0; i < len(self.vm.vdisks); i += 1 {
+		size += int64(self.vm.vdisks[i].GetDiskSizeMB()) * 1024 * 1024
+	}
+	return size
+}
+
+func (self *SVMTemplate) GetOsType() string {
+	return self.vm.GetOSType()
+}
+
+func (self *SVMTemplate) GetOsDist() string {
+	return self.vm.GetOSName()
+}
+
+func (self *SVMTemplate) GetOsVersion() string {
+	if osInfo, ok := GuestOsInfo[self.vm.GetGuestId()]; ok {
+		return osInfo.OsVersion
+	}
+	return ""
+}
+
+func (self *SVMTemplate) GetOsArch() string {
+	if osInfo, ok := GuestOsInfo[self.vm.GetGuestId()]; ok {
+		return string(osInfo.OsArch)
+	}
+	return ""
+}
+
+func (self *SVMTemplate) GetMinOsDiskSizeGb() int {
+	if len(self.vm.vdisks) == 0 {
+		return 0
+	}
+	return self.vm.vdisks[0].GetDiskSizeMB() / 1024
+}
+
+func (self *SVMTemplate) GetMinRamSizeMb() int {
+	return 0
+}
+
+func (self *SVMTemplate) GetImageFormat() string {
+	return "vmdk"
+}
+
+func (self *SVMTemplate) GetCreateTime() time.Time {
+	return self.vm.GetCreateTime()
+}
@@ -130,9 +130,10 @@ func (disk *SVirtualDisk) GetTemplateId() string {
 	return ""
 }
 
+// 虚拟机的第一块磁盘为系统盘, 从模板完整克隆的系统盘没有父磁盘
 func (disk *SVirtualDisk) GetDiskType() string {
 	backing := disk.getBackingInfo()
-	if backing.Parent != nil {
+	if backing.Parent != nil || disk.index == 0 {
 		return models.DISK_TYPE_SYS
 	}
 	return models.DISK_TYPE_DATA
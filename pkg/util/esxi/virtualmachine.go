@@ -2,8 +2,11 @@ package esxi
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,6 +27,13 @@ import (
 
 var VIRTUAL_MACHINE_PROPS = []string{"name", "parent", "runtime", "summary", "config", "guest"}
 
+const (
+	// 记录创建时的网卡配置, 重置密码及重装系统时重新执行自定义规范使用
+	VM_EXTRA_CONFIG_NICS = "guestinfo.onecloud.nics"
+)
+
+var instanceTypePattern = regexp.MustCompile(`^ecs\.[^.]+\.c(\d+)m(\d+)$`)
+
 type SVirtualMachine struct {
 	SManagedObject
 
@@ -94,12 +104,311 @@ func (self *SVirtualMachine) GetInstanceType() string {
 	return ""
 }
 
+func (self *SVirtualMachine) IsTemplate() bool {
+	moVM := self.getVirtualMachine()
+	return moVM.Config != nil && moVM.Config.Template
+}
+
 func (self *SVirtualMachine) DeployVM(ctx context.Context, name string, password string, publicKey string, deleteKeypair bool, description string) error {
-	return cloudprovider.ErrNotImplemented
+	if len(publicKey) > 0 || deleteKeypair {
+		log.Warningf("keypair is not supported by vmware customization of %s", self.GetName())
+	}
+	configSpec := types.VirtualMachineConfigSpec{}
+	changed := false
+	if len(name) > 0 && name != self.GetName() {
+		configSpec.Name = name
+		changed = true
+	}
+	if description != self.getVirtualMachine().Config.Annotation {
+		configSpec.Annotation = description
+		changed = true
+	}
+	if changed {
+		err := self.reconfigure(ctx, configSpec)
+		if err != nil {
+			return err
+		}
+	}
+	if len(password) > 0 {
+		if len(name) == 0 {
+			name = self.GetName()
+		}
+		err := self.customize(ctx, name, password)
+		if err != nil {
+			return err
+		}
+	}
+	return self.Refresh()
 }
 
 func (self *SVirtualMachine) RebuildRoot(ctx context.Context, imageId string, passwd string, publicKey string, sysSizeGB int) (string, error) {
-	return "", cloudprovider.ErrNotImplemented
+	ihost := self.GetIHost()
+	if ihost == nil {
+		return "", fmt.Errorf("fail to find host of vm %s", self.GetName())
+	}
+	temp, err := ihost.(*SHost).findTemplate(imageId)
+	if err != nil {
+		log.Errorf("fail to find template %s: %s", imageId, err)
+		return "", err
+	}
+	if len(temp.vm.vdisks) == 0 {
+		return "", fmt.Errorf("template %s has no disk", temp.GetName())
+	}
+	if len(self.vdisks) == 0 {
+		return "", fmt.Errorf("vm %s has no system disk", self.GetName())
+	}
+
+	err = self.rebuildDiskFromTemplate(ctx, &self.vdisks[0], &temp.vm.vdisks[0])
+	if err != nil {
+		return "", err
+	}
+	err = self.Refresh()
+	if err != nil {
+		return "", err
+	}
+	// 操作系统类型随模板变化
+	err = self.doChangeConfig(ctx, int32(self.GetVcpuCount()), int64(self.GetVmemSizeMB()), temp.vm.GetGuestId(), "")
+	if err != nil {
+		return "", err
+	}
+	err = self.resizeSysDisk(ctx, sysSizeGB)
+	if err != nil {
+		return "", err
+	}
+	if len(publicKey) > 0 {
+		log.Warningf("keypair is not supported by vmware customization of %s", self.GetName())
+	}
+	err = self.customize(ctx, self.GetName(), passwd)
+	if err != nil {
+		return "", err
+	}
+	err = self.Refresh()
+	if err != nil {
+		return "", err
+	}
+	return self.vdisks[0].GetGlobalId(), nil
+}
+
+// 先将模板系统盘复制为新的磁盘文件并替换至原设备位置, 替换成功后再删除原磁盘, 避免复制失败时虚拟机丢失系统盘
+func (self *SVirtualMachine) rebuildDiskFromTemplate(ctx context.Context, disk *SVirtualDisk, tempDisk *SVirtualDisk) error {
+	oldFilename := disk.getBackingInfo().FileName
+	filename := fmt.Sprintf("%s-%d.vmdk", strings.TrimSuffix(oldFilename, ".vmdk"), time.Now().Unix())
+
+	dc, err := self.GetDatacenter()
+	if err != nil {
+		return err
+	}
+	dm := object.NewVirtualDiskManager(self.manager.client.Client)
+	task, err := dm.CopyVirtualDisk(ctx, tempDisk.getBackingInfo().FileName, dc.getDcObj(), filename, dc.getDcObj(), nil, false)
+	if err != nil {
+		log.Errorf("CopyVirtualDisk fail %s", err)
+		return err
+	}
+	err = task.Wait(ctx)
+	if err != nil {
+		log.Errorf("CopyVirtualDisk task.Wait fail %s", err)
+		return err
+	}
+
+	err = self.swapDiskFile(ctx, disk, filename)
+	if err != nil {
+		log.Errorf("swap disk file of %s fail %s", self.GetName(), err)
+		if task, err := dm.DeleteVirtualDisk(ctx, filename, dc.getDcObj()); err == nil {
+			task.Wait(ctx)
+		}
+		return err
+	}
+
+	// 此时disk仍指向原磁盘文件, 删除失败仅残留文件, 不影响重装结果
+	err = disk.Delete(ctx)
+	if err != nil {
+		log.Errorf("delete old disk file %s fail %s", oldFilename, err)
+	}
+	return nil
+}
+
+// 修改原磁盘设备的后端文件, 设备位置保持不变, 原磁盘文件保留
+func (self *SVirtualMachine) swapDiskFile(ctx context.Context, disk *SVirtualDisk, filename string) error {
+	thinProvisioned := true
+	backing := &types.VirtualDiskFlatVer2BackingInfo{}
+	backing.FileName = filename
+	backing.DiskMode = "persistent"
+	backing.ThinProvisioned = &thinProvisioned
+
+	device := *disk.getVirtualDisk()
+	device.Backing = backing
+	device.CapacityInKB = 0
+	device.CapacityInBytes = 0
+
+	editSpec := &types.VirtualDeviceConfigSpec{}
+	editSpec.Operation = types.VirtualDeviceConfigSpecOperationEdit
+	editSpec.Device = &device
+
+	configSpec := types.VirtualMachineConfigSpec{}
+	configSpec.DeviceChange = []types.BaseVirtualDeviceConfigSpec{editSpec}
+	return self.reconfigure(ctx, configSpec)
+}
+
+func (self *SVirtualMachine) resizeSysDisk(ctx context.Context, sizeGb int) error {
+	if len(self.vdisks) == 0 || sizeGb*1024 <= self.vdisks[0].GetDiskSizeMB() {
+		return nil
+	}
+	err := self.vdisks[0].Resize(ctx, int64(sizeGb*1024))
+	if err != nil {
+		return err
+	}
+	return self.Refresh()
+}
+
+func (self *SVirtualMachine) reconfigure(ctx context.Context, configSpec types.VirtualMachineConfigSpec) error {
+	vm := self.getVmObj()
+
+	task, err := vm.Reconfigure(ctx, configSpec)
+	if err != nil {
+		log.Errorf("vm.Reconfigure fail %s", err)
+		return err
+	}
+	err = task.Wait(ctx)
+	if err != nil {
+		log.Errorf("task.Wait(ctx) fail %s", err)
+		return err
+	}
+	return self.Refresh()
+}
+
+// 使用创建时记录的网络配置重新执行自定义规范, 未记录时无法保证网络配置不变, 跳过自定义
+func (self *SVirtualMachine) customize(ctx context.Context, name string, password string) error {
+	nics, ok := self.getCustomizeNics()
+	if !ok {
+		log.Warningf("no customization network config found for vm %s, skip customization", self.GetName())
+		return nil
+	}
+	spec := newCustomizationSpec(self.GetOSType(), name, password, nics)
+
+	vm := self.getVmObj()
+
+	task, err := vm.Customize(ctx, spec)
+	if err != nil {
+		log.Errorf("vm.Customize fail %s", err)
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+func (self *SVirtualMachine) getCustomizeNics() ([]SCustomizeNicConfig, bool) {
+	if len(self.vnics) == 0 {
+		return []SCustomizeNicConfig{}, true
+	}
+	for _, opt := range self.getVirtualMachine().Config.ExtraConfig {
+		optVal := opt.GetOptionValue()
+		if optVal.Key != VM_EXTRA_CONFIG_NICS {
+			continue
+		}
+		val, ok := optVal.Value.(string)
+		if !ok {
+			break
+		}
+		nics := make([]SCustomizeNicConfig, 0)
+		if err := json.Unmarshal([]byte(val), &nics); err != nil {
+			log.Errorf("invalid customization nics %s of vm %s: %s", val, self.GetName(), err)
+			break
+		}
+		if len(nics) != len(self.vnics) {
+			break
+		}
+		return nics, true
+	}
+	return nil, false
+}
+
+func (self *SVirtualMachine) cloneVM(ctx context.Context, host *SHost, ds *SDatastore, desc *cloudprovider.SManagedVMCreateConfig) (*SVirtualMachine, error) {
+	dc, err := host.GetDatacenter()
+	if err != nil {
+		return nil, err
+	}
+	folders, err := dc.getDcObj().Folders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := host.getHostObj().ResourcePool(ctx)
+	if err != nil {
+		return nil, err
+	}
+	poolRef := pool.Reference()
+	hostRef := host.getHostSystem().Self
+	dsRef := ds.getDatastore().Self
+
+	// 第一块网卡使用分配的地址, 其余网卡使用DHCP
+	nics := make([]SCustomizeNicConfig, len(self.vnics))
+	if len(nics) > 0 {
+		nics[0] = SCustomizeNicConfig{
+			IpAddr:  desc.IpAddr,
+			IpMask:  desc.IpMask,
+			Gateway: desc.Gateway,
+			Dns:     desc.Dns,
+		}
+	}
+	nicsJson, err := json.Marshal(nics)
+	if err != nil {
+		return nil, err
+	}
+	customSpec := newCustomizationSpec(self.GetOSType(), desc.Name, desc.Password, nics)
+
+	cloneSpec := types.VirtualMachineCloneSpec{
+		Location: types.VirtualMachineRelocateSpec{
+			Pool:      &poolRef,
+			Host:      &hostRef,
+			Datastore: &dsRef,
+		},
+		Config: &types.VirtualMachineConfigSpec{
+			NumCPUs:    int32(desc.Cpu),
+			MemoryMB:   int64(desc.MemoryMB),
+			Annotation: desc.Description,
+			ExtraConfig: []types.BaseOptionValue{
+				&types.OptionValue{Key: VM_EXTRA_CONFIG_NICS, Value: string(nicsJson)},
+			},
+		},
+		Customization: &customSpec,
+		PowerOn:       false,
+		Template:      false,
+	}
+
+	task, err := self.getVmObj().Clone(ctx, folders.VmFolder, desc.Name, cloneSpec)
+	if err != nil {
+		log.Errorf("vm.Clone fail %s", err)
+		return nil, err
+	}
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		log.Errorf("vm.Clone task.WaitForResult fail %s", err)
+		return nil, err
+	}
+	vmRef, ok := info.Result.(types.ManagedObjectReference)
+	if !ok {
+		return nil, fmt.Errorf("invalid clone result %#v", info.Result)
+	}
+	var moVM mo.VirtualMachine
+	err = self.manager.reference2Object(vmRef, VIRTUAL_MACHINE_PROPS, &moVM)
+	if err != nil {
+		return nil, err
+	}
+	vm := NewVirtualMachine(self.manager, &moVM, dc)
+
+	err = vm.resizeSysDisk(ctx, desc.SysDisk.SizeGB)
+	if err != nil {
+		return nil, err
+	}
+	driver := "scsi"
+	if len(vm.vdisks) > 0 && len(vm.vdisks[0].GetDriver()) > 0 {
+		driver = vm.vdisks[0].GetDriver()
+	}
+	for i := 0; i < len(desc.DataDisks); i += 1 {
+		err = vm.CreateDisk(ctx, desc.DataDisks[i].SizeGB*1024, "", driver)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return vm, nil
 }
 
 func (self *SVirtualMachine) rebuildDisk(ctx context.Context, disk *SVirtualDisk) error {
@@ -117,7 +426,10 @@ func (self *SVirtualMachine) rebuildDisk(ctx context.Context, disk *SVirtualDisk
 }
 
 func (self *SVirtualMachine) UpdateVM(ctx context.Context, name string) error {
-	return cloudprovider.ErrNotImplemented
+	if len(name) == 0 || name == self.GetName() {
+		return nil
+	}
+	return self.reconfigure(ctx, types.VirtualMachineConfigSpec{Name: name})
 }
 
 // TODO: detach disk to a separate directory, so as to keep disk independent of VM
@@ -130,8 +442,26 @@ func (self *SVirtualMachine) DetachDisk(ctx context.Context, diskId string) erro
 	return self.doDetachDisk(ctx, vdisk.(*SVirtualDisk), false)
 }
 
+// 磁盘随虚拟机存放且卸载时即删除, 不存在可挂载的独立磁盘, 不允许挂载其他虚拟机的磁盘
 func (self *SVirtualMachine) AttachDisk(ctx context.Context, diskId string) error {
-	return cloudprovider.ErrNotImplemented
+	if _, err := self.GetIDiskById(diskId); err == nil {
+		return nil
+	}
+	dc, err := self.GetDatacenter()
+	if err != nil {
+		return err
+	}
+	istorages, err := dc.GetIStorages()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(istorages); i += 1 {
+		idisk, err := istorages[i].GetIDiskById(diskId)
+		if err == nil {
+			return fmt.Errorf("disk %s is attached to vm %s", diskId, idisk.(*SVirtualDisk).vm.GetName())
+		}
+	}
+	return cloudprovider.ErrNotSupported
 }
 
 func (self *SVirtualMachine) getUuid() string {
@@ -530,8 +860,15 @@ func (self *SVirtualMachine) AssignSecurityGroup(secgroupId string) error {
 	return cloudprovider.ErrNotImplemented
 }
 
-func (dc *SVirtualMachine) ChangeConfig2(ctx context.Context, instanceType string) error {
-	return cloudprovider.ErrNotImplemented
+// ESXi无实例规格, 按本地规格名称(ecs.<family>.c<cpu>m<memGB>)解析配置
+func (self *SVirtualMachine) ChangeConfig2(ctx context.Context, instanceType string) error {
+	m := instanceTypePattern.FindStringSubmatch(instanceType)
+	if m == nil {
+		return fmt.Errorf("invalid instance type %s", instanceType)
+	}
+	ncpu, _ := strconv.Atoi(m[1])
+	vmemGB, _ := strconv.Atoi(m[2])
+	return self.doChangeConfig(ctx, int32(ncpu), int64(vmemGB*1024), "", "")
 }
 
 func (self *SVirtualMachine) SetSecurityGroups(secgroupIds []string) error {
@@ -570,7 +907,7 @@ func (self *SVirtualMachine) fetchHardwareInfo() {
 		if reflectutils.StructContains(devType, etherType) {
 			self.vnics = append(self.vnics, NewVirtualNIC(self, dev, len(self.vnics)))
 		} else if reflectutils.StructContains(devType, diskType) {
-			self.vdisks = append(self.vdisks, NewVirtualDisk(self, dev, len(self.vnics)))
+			self.vdisks = append(self.vdisks, NewVirtualDisk(self, dev, len(self.vdisks)))
 		} else if reflectutils.StructContains(devType, vgaType) {
 			self.vga = NewVirtualVGA(self, dev, 0)
 		} else if reflectutils.StructContains(devType, cdromType) {
@@ -659,9 +996,17 @@ func minDiskKey(devs []SVirtualDisk) int32 {
 }
 
 func (self *SVirtualMachine) CreateDisk(ctx context.Context, sizeMb int, uuid string, driver string) error {
+	index, diskKey, ctlKey, err := self.allocDiskDevKey(driver)
+	if err != nil {
+		return err
+	}
+	return self.createDiskInternal(ctx, sizeMb, uuid, index, diskKey, ctlKey)
+}
+
+func (self *SVirtualMachine) allocDiskDevKey(driver string) (int32, int32, int32, error) {
 	aliasDrivers, ok := driverTable[driver]
 	if !ok {
-		return fmt.Errorf("Unsupported disk driver %s", driver)
+		return 0, 0, 0, fmt.Errorf("Unsupported disk driver %s", driver)
 	}
 	var devs []SVirtualDevice
 	for _, alias := range aliasDrivers {
@@ -671,7 +1016,7 @@ func (self *SVirtualMachine) CreateDisk(ctx context.Context, sizeMb int, uuid st
 		}
 	}
 	if len(devs) == 0 {
-		return fmt.Errorf("Driver %s not found", driver)
+		return 0, 0, 0, fmt.Errorf("Driver %s not found", driver)
 	}
 	ctlKey := minDevKey(devs)
 	sameDisks := make([]SVirtualDisk, 0)
@@ -688,8 +1033,7 @@ func (self *SVirtualMachine) CreateDisk(ctx context.Context, sizeMb int, uuid st
 	if driver == "ide" {
 		ctlKey += int32(index / 2)
 	}
-
-	return self.createDiskInternal(ctx, sizeMb, uuid, int32(index), diskKey, ctlKey)
+	return int32(index), diskKey, ctlKey, nil
 }
 
 func (self *SVirtualMachine) createDiskInternal(ctx context.Context, sizeMb int, uuid string, index int32, diskKey int32, ctlKey int32) error {
@@ -0,0 +1,247 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/disks
+type SDisk struct {
+	storage *SStorage
+
+	SResourceBase
+
+	SizeGb              int
+	Zone                string
+	Status              string
+	SourceImage         string
+	SourceSnapshot      string
+	Type                string
+	Users               []string
+	LastAttachTimestamp string
+	LastDetachTimestamp string
+}
+
+func (self *SDisk) GetStatus() string {
+	switch self.Status {
+	case "CREATING":
+		return models.DISK_ALLOCATING
+	case "RESTORING":
+		return models.DISK_RESET
+	case "READY":
+		return models.DISK_READY
+	case "FAILED":
+		return models.DISK_ALLOC_FAILED
+	case "DELETING":
+		return models.DISK_DEALLOC
+	default:
+		return models.DISK_UNKNOWN
+	}
+}
+
+func (self *SDisk) Refresh() error {
+	disk, err := self.storage.zone.region.GetDisk(self.GetGlobalId())
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, disk)
+}
+
+func (self *SDisk) IsEmulated() bool {
+	return false
+}
+
+func (self *SDisk) GetMetadata() *jsonutils.JSONDict {
+	data := jsonutils.NewDict()
+	data.Add(jsonutils.NewString(models.HYPERVISOR_GOOGLE), "hypervisor")
+	return data
+}
+
+func (self *SDisk) GetProjectId() string {
+	return ""
+}
+
+func (self *SDisk) GetBillingType() string {
+	return models.BILLING_TYPE_POSTPAID
+}
+
+func (self *SDisk) GetExpiredAt() time.Time {
+	return time.Time{}
+}
+
+func (self *SDisk) GetIStorage() (cloudprovider.ICloudStorage, error) {
+	return self.storage, nil
+}
+
+func (self *SDisk) GetDiskFormat() string {
+	return "raw"
+}
+
+func (self *SDisk) GetDiskSizeMB() int {
+	return self.SizeGb * 1024
+}
+
+// 挂载时设置的autoDelete记录在实例上
+func (self *SDisk) GetIsAutoDelete() bool {
+	if len(self.Users) == 0 {
+		return false
+	}
+	instance, err := self.storage.zone.region.GetInstance(self.Users[0])
+	if err != nil {
+		return false
+	}
+	for _, disk := range instance.Disks {
+		if disk.Source == self.SelfLink {
+			return disk.AutoDelete
+		}
+	}
+	return false
+}
+
+func (self *SDisk) GetTemplateId() string {
+	return getGlobalId(self.SourceImage)
+}
+
+func (self *SDisk) GetDiskType() string {
+	if len(self.SourceImage) > 0 {
+		return models.DISK_TYPE_SYS
+	}
+	return models.DISK_TYPE_DATA
+}
+
+func (self *SDisk) GetFsFormat() string {
+	return ""
+}
+
+func (self *SDisk) GetIsNonPersistent() bool {
+	return false
+}
+
+func (self *SDisk) GetDriver() string {
+	return "scsi"
+}
+
+func (self *SDisk) GetCacheMode() string {
+	return "none"
+}
+
+func (self *SDisk) GetMountpoint() string {
+	return ""
+}
+
+func (self *SDisk) GetAccessPath() string {
+	return ""
+}
+
+func (self *SDisk) Delete(ctx context.Context) error {
+	err := self.storage.zone.region.client.delete(self.GetGlobalId())
+	if err == cloudprovider.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+func (self *SDisk) CreateISnapshot(ctx context.Context, name string, desc string) (cloudprovider.ICloudSnapshot, error) {
+	snapshotId, err := self.storage.zone.region.CreateSnapshot(self.GetGlobalId(), name, desc)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := self.storage.zone.region.GetSnapshot(snapshotId)
+	if err != nil {
+		return nil, err
+	}
+	err = cloudprovider.WaitStatus(snapshot, models.SNAPSHOT_READY, 15*time.Second, 3600*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (self *SDisk) GetISnapshot(snapshotId string) (cloudprovider.ICloudSnapshot, error) {
+	snapshot, err := self.storage.zone.region.GetSnapshot(snapshotId)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.GetDiskId() != self.GetGlobalId() {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return snapshot, nil
+}
+
+func (self *SDisk) GetISnapshots() ([]cloudprovider.ICloudSnapshot, error) {
+	snapshots, err := self.storage.zone.region.GetSnapshots(self.GetGlobalId())
+	if err != nil {
+		return nil, err
+	}
+	isnapshots := make([]cloudprovider.ICloudSnapshot, len(snapshots))
+	for i := 0; i < len(snapshots); i++ {
+		isnapshots[i] = &snapshots[i]
+	}
+	return isnapshots, nil
+}
+
+// 磁盘只能扩容
+func (self *SDisk) Resize(ctx context.Context, newSizeMB int64) error {
+	return self.storage.zone.region.ResizeDisk(self.GetGlobalId(), int(newSizeMB/1024))
+}
+
+func (self *SDisk) Reset(ctx context.Context, snapshotId string) (string, error) {
+	return "", cloudprovider.ErrNotSupported
+}
+
+func (self *SDisk) Rebuild(ctx context.Context) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) GetDisk(id string) (*SDisk, error) {
+	disk := SDisk{}
+	err := self.client.get(id, &disk)
+	if err != nil {
+		return nil, err
+	}
+	return &disk, nil
+}
+
+// storageType 为空时返回可用区内所有磁盘
+func (self *SRegion) GetDisks(zoneId string, storageType string) ([]SDisk, error) {
+	disks := []SDisk{}
+	err := self.client.listAll(fmt.Sprintf("zones/%s/disks", zoneId), nil, &disks)
+	if err != nil {
+		return nil, err
+	}
+	if len(storageType) == 0 {
+		return disks, nil
+	}
+	ret := []SDisk{}
+	for i := range disks {
+		if getResourceName(disks[i].Type) == storageType {
+			ret = append(ret, disks[i])
+		}
+	}
+	return ret, nil
+}
+
+func (self *SRegion) CreateDisk(zoneId string, storageType string, name string, sizeGb int, imageId string, desc string) (string, error) {
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.NewString(name), "name")
+	body.Add(jsonutils.NewString(desc), "description")
+	body.Add(jsonutils.NewString(fmt.Sprintf("%d", sizeGb)), "sizeGb")
+	body.Add(jsonutils.NewString(fmt.Sprintf("zones/%s/diskTypes/%s", zoneId, storageType)), "type")
+	if len(imageId) > 0 {
+		body.Add(jsonutils.NewString(getGlobalId(imageId)), "sourceImage")
+	}
+	return self.client.insert(fmt.Sprintf("zones/%s/disks", zoneId), body)
+}
+
+func (self *SRegion) ResizeDisk(diskId string, sizeGb int) error {
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.NewString(fmt.Sprintf("%d", sizeGb)), "sizeGb")
+	_, err := self.client.action(diskId, "resize", nil, body)
+	return err
+}
@@ -0,0 +1 @@
+package google // import "yunion.io/x/onecloud/pkg/util/google"
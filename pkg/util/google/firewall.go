@@ -0,0 +1,296 @@
+package google
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/secrules"
+	"yunion.io/x/pkg/util/stringutils"
+	"yunion.io/x/pkg/utils"
+)
+
+const (
+	// 安全组对应的网络标记前缀
+	SECGROUP_TAG_PREFIX = "secgroup-"
+
+	FIREWALL_DIRECTION_INGRESS = "INGRESS"
+	FIREWALL_DIRECTION_EGRESS  = "EGRESS"
+
+	// 安全组规则转换后的起始优先级, 数值越小优先级越高
+	FIREWALL_PRIORITY_START = 1000
+)
+
+type SFirewallRule struct {
+	IPProtocol string
+	Ports      []string
+}
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/firewalls
+type SFirewall struct {
+	SResourceBase
+
+	Network           string
+	Priority          int
+	Direction         string
+	SourceRanges      []string
+	DestinationRanges []string
+	TargetTags        []string
+	Allowed           []SFirewallRule
+	Denied            []SFirewallRule
+	Disabled          bool
+}
+
+// 安全组即网络标记, 规则为以该标记为目标的防火墙规则
+type SSecurityGroup struct {
+	vpc *SVpc
+
+	Tag string
+}
+
+func generateSecurityGroupTag(name string) string {
+	return SECGROUP_TAG_PREFIX + strings.Replace(stringutils.UUID4(), "-", "", -1)[:16]
+}
+
+func isSecurityGroupTag(tag string) bool {
+	return strings.HasPrefix(tag, SECGROUP_TAG_PREFIX)
+}
+
+func (self *SSecurityGroup) GetId() string {
+	return self.Tag
+}
+
+func (self *SSecurityGroup) GetName() string {
+	return self.Tag
+}
+
+func (self *SSecurityGroup) GetGlobalId() string {
+	return self.Tag
+}
+
+func (self *SSecurityGroup) GetStatus() string {
+	return ""
+}
+
+func (self *SSecurityGroup) Refresh() error {
+	return nil
+}
+
+func (self *SSecurityGroup) IsEmulated() bool {
+	return false
+}
+
+func (self *SSecurityGroup) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SSecurityGroup) GetProjectId() string {
+	return ""
+}
+
+func (self *SSecurityGroup) GetDescription() string {
+	return ""
+}
+
+func (self *SSecurityGroup) GetVpcId() string {
+	return self.vpc.GetGlobalId()
+}
+
+func (self *SSecurityGroup) GetRules() ([]secrules.SecurityRule, error) {
+	firewalls, err := self.vpc.region.GetFirewalls(self.vpc.network.GetGlobalId(), self.Tag)
+	if err != nil {
+		return nil, err
+	}
+	rules := []secrules.SecurityRule{}
+	for i := range firewalls {
+		if firewalls[i].Disabled {
+			continue
+		}
+		_rules, err := firewalls[i].toRules()
+		if err != nil {
+			log.Errorf("convert firewall %s to rules error: %v", firewalls[i].Name, err)
+			continue
+		}
+		rules = append(rules, _rules...)
+	}
+	return rules, nil
+}
+
+func parsePorts(port string) (int, int, error) {
+	if strings.Index(port, "-") > 0 {
+		ports := strings.Split(port, "-")
+		start, err := strconv.Atoi(ports[0])
+		if err != nil {
+			return 0, 0, err
+		}
+		end, err := strconv.Atoi(ports[1])
+		if err != nil {
+			return 0, 0, err
+		}
+		return start, end, nil
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return 0, 0, err
+	}
+	return p, p, nil
+}
+
+// 防火墙优先级范围为[0, 65535], 转换为安全组规则的[1, 100]
+func (self *SFirewall) toRules() ([]secrules.SecurityRule, error) {
+	rules := []secrules.SecurityRule{}
+	priority := 100 - self.Priority*100/65536
+	if priority < 1 {
+		priority = 1
+	}
+	direction := secrules.TSecurityRuleDirection(secrules.DIR_IN)
+	ranges := self.SourceRanges
+	if self.Direction == FIREWALL_DIRECTION_EGRESS {
+		direction = secrules.DIR_OUT
+		ranges = self.DestinationRanges
+	}
+	if len(ranges) == 0 {
+		ranges = []string{"0.0.0.0/0"}
+	}
+	for _, action := range []secrules.TSecurityRuleAction{secrules.SecurityRuleAllow, secrules.SecurityRuleDeny} {
+		fwRules := self.Allowed
+		if action == secrules.SecurityRuleDeny {
+			fwRules = self.Denied
+		}
+		for _, fwRule := range fwRules {
+			protocol := strings.ToLower(fwRule.IPProtocol)
+			if protocol == "all" {
+				protocol = secrules.PROTO_ANY
+			}
+			if !utils.IsInStringArray(protocol, []string{secrules.PROTO_ANY, secrules.PROTO_TCP, secrules.PROTO_UDP, secrules.PROTO_ICMP}) {
+				continue
+			}
+			for _, ipRange := range ranges {
+				_, ipnet, err := net.ParseCIDR(ipRange)
+				if err != nil {
+					return nil, err
+				}
+				rule := secrules.SecurityRule{
+					Priority:    priority,
+					Action:      action,
+					IPNet:       ipnet,
+					Protocol:    protocol,
+					Direction:   direction,
+					Description: self.Description,
+				}
+				if len(fwRule.Ports) == 0 {
+					rules = append(rules, rule)
+					continue
+				}
+				for _, port := range fwRule.Ports {
+					start, end, err := parsePorts(port)
+					if err != nil {
+						return nil, err
+					}
+					rule.PortStart, rule.PortEnd = start, end
+					rules = append(rules, rule)
+				}
+			}
+		}
+	}
+	return rules, nil
+}
+
+func convertSecurityGroupRule(networkId, tag string, rule secrules.SecurityRule, priority int) jsonutils.JSONObject {
+	fwRule := SFirewallRule{IPProtocol: rule.Protocol}
+	if rule.Protocol == secrules.PROTO_ANY {
+		fwRule.IPProtocol = "all"
+	} else if rule.Protocol != secrules.PROTO_ICMP {
+		if len(rule.Ports) > 0 {
+			for _, port := range rule.Ports {
+				fwRule.Ports = append(fwRule.Ports, fmt.Sprintf("%d", port))
+			}
+		} else if rule.PortStart > 0 && rule.PortEnd > 0 {
+			fwRule.Ports = []string{fmt.Sprintf("%d-%d", rule.PortStart, rule.PortEnd)}
+		}
+	}
+	ipRange := "0.0.0.0/0"
+	if rule.IPNet != nil {
+		ipRange = rule.IPNet.String()
+	}
+
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.NewString(fmt.Sprintf("%s-%d", tag, priority)), "name")
+	body.Add(jsonutils.NewString(rule.Description), "description")
+	body.Add(jsonutils.NewString(networkId), "network")
+	body.Add(jsonutils.NewInt(int64(priority)), "priority")
+	body.Add(jsonutils.NewStringArray([]string{tag}), "targetTags")
+	if rule.Direction == secrules.DIR_IN {
+		body.Add(jsonutils.NewString(FIREWALL_DIRECTION_INGRESS), "direction")
+		body.Add(jsonutils.NewStringArray([]string{ipRange}), "sourceRanges")
+	} else {
+		body.Add(jsonutils.NewString(FIREWALL_DIRECTION_EGRESS), "direction")
+		body.Add(jsonutils.NewStringArray([]string{ipRange}), "destinationRanges")
+	}
+	fwRules := jsonutils.NewArray(jsonutils.Marshal(map[string]interface{}{
+		"IPProtocol": fwRule.IPProtocol,
+		"ports":      fwRule.Ports,
+	}))
+	if rule.Action == secrules.SecurityRuleAllow {
+		body.Add(fwRules, "allowed")
+	} else {
+		body.Add(fwRules, "denied")
+	}
+	return body
+}
+
+// networkId 或 tag 为空时不过滤
+func (self *SRegion) GetFirewalls(networkId string, tag string) ([]SFirewall, error) {
+	firewalls := []SFirewall{}
+	err := self.client.listAll("global/firewalls", nil, &firewalls)
+	if err != nil {
+		return nil, err
+	}
+	ret := []SFirewall{}
+	for i := range firewalls {
+		if len(networkId) > 0 && getGlobalId(firewalls[i].Network) != getGlobalId(networkId) {
+			continue
+		}
+		if len(tag) > 0 && !utils.IsInStringArray(tag, firewalls[i].TargetTags) {
+			continue
+		}
+		ret = append(ret, firewalls[i])
+	}
+	return ret, nil
+}
+
+// 删除标记对应的所有防火墙规则后按安全组规则重新创建
+func (self *SRegion) syncFirewalls(networkId string, tag string, rules []secrules.SecurityRule) error {
+	firewalls, err := self.GetFirewalls(networkId, tag)
+	if err != nil {
+		return err
+	}
+	for i := range firewalls {
+		err := self.client.delete(firewalls[i].GetGlobalId())
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.Sort(secrules.SecurityRuleSet(rules))
+	priority := FIREWALL_PRIORITY_START
+	ruleStrs := []string{}
+	for i := 0; i < len(rules); i++ {
+		ruleStr := rules[i].String()
+		if utils.IsInStringArray(ruleStr, ruleStrs) {
+			continue
+		}
+		ruleStrs = append(ruleStrs, ruleStr)
+		body := convertSecurityGroupRule(networkId, tag, rules[i], priority)
+		_, err := self.client.insert("global/firewalls", body)
+		if err != nil {
+			return err
+		}
+		priority++
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package google
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/httputils"
+)
+
+const (
+	CLOUD_PROVIDER_GOOGLE    = models.CLOUD_PROVIDER_GOOGLE
+	CLOUD_PROVIDER_GOOGLE_CN = "谷歌云"
+
+	GOOGLE_DEFAULT_REGION = "asia-east1"
+	GOOGLE_API_VERSION    = "v1"
+
+	GOOGLE_COMPUTE_DOMAIN = "https://www.googleapis.com/compute/v1/"
+	GOOGLE_TOKEN_URL      = "https://oauth2.googleapis.com/token"
+	GOOGLE_AUTH_SCOPE     = "https://www.googleapis.com/auth/compute"
+
+	GOOGLE_MAX_RESULTS = 500
+)
+
+// 服务账号密钥
+// account 格式为 projectId/clientEmail
+// secret 格式为 privateKeyId/privateKey, privateKey为PEM格式
+type SGoogleClient struct {
+	providerId   string
+	providerName string
+	projectId    string
+	clientEmail  string
+	privateKeyId string
+	privateKey   *rsa.PrivateKey
+	debug        bool
+
+	client *http.Client
+
+	tokenLock   sync.Mutex
+	token       string
+	tokenExpire time.Time
+
+	iregions []cloudprovider.ICloudRegion
+}
+
+func parseAccount(account, secret string) (projectId, clientEmail, privateKeyId, privateKey string, err error) {
+	accountInfo := strings.SplitN(account, "/", 2)
+	secretInfo := strings.SplitN(secret, "/", 2)
+	if len(accountInfo) != 2 || len(secretInfo) != 2 {
+		return "", "", "", "", fmt.Errorf("invalid google account or secret")
+	}
+	return accountInfo[0], accountInfo[1], secretInfo[0], secretInfo[1], nil
+}
+
+func NewGoogleClient(providerId, providerName, account, secret string, debug bool) (*SGoogleClient, error) {
+	projectId, clientEmail, privateKeyId, privateKey, err := parseAccount(account, secret)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	client := SGoogleClient{
+		providerId:   providerId,
+		providerName: providerName,
+		projectId:    projectId,
+		clientEmail:  clientEmail,
+		privateKeyId: privateKeyId,
+		privateKey:   key,
+		debug:        debug,
+		client:       httputils.GetDefaultClient(),
+	}
+	err = client.fetchRegions()
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// 使用服务账号签名的JWT换取访问令牌
+// https://developers.google.com/identity/protocols/OAuth2ServiceAccount#authorizingrequests
+func (self *SGoogleClient) getToken() (string, error) {
+	self.tokenLock.Lock()
+	defer self.tokenLock.Unlock()
+
+	if len(self.token) > 0 && time.Now().Add(time.Minute).Before(self.tokenExpire) {
+		return self.token, nil
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   self.clientEmail,
+		"scope": GOOGLE_AUTH_SCOPE,
+		"aud":   GOOGLE_TOKEN_URL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = self.privateKeyId
+	assertion, err := token.SignedString(self.privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := httputils.Request(self.client, context.Background(), httputils.POST, GOOGLE_TOKEN_URL, header, strings.NewReader(form.Encode()), false)
+	_, body, err := httputils.ParseJSONResponse(resp, err, self.debug)
+	if err != nil {
+		return "", err
+	}
+	accessToken, err := body.GetString("access_token")
+	if err != nil {
+		return "", fmt.Errorf("no access_token in response: %s", body)
+	}
+	expiresIn, _ := body.Int("expires_in")
+	self.token = accessToken
+	self.tokenExpire = now.Add(time.Duration(expiresIn) * time.Second)
+	return self.token, nil
+}
+
+func (self *SGoogleClient) jsonRequest(method httputils.THttpMethod, resource string, params map[string]string, body jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	token, err := self.getToken()
+	if err != nil {
+		return nil, err
+	}
+	reqUrl := resource
+	if !strings.HasPrefix(reqUrl, "https://") {
+		reqUrl = GOOGLE_COMPUTE_DOMAIN + resource
+	}
+	if len(params) > 0 {
+		values := url.Values{}
+		for k, v := range params {
+			values.Set(k, v)
+		}
+		reqUrl = fmt.Sprintf("%s?%s", reqUrl, values.Encode())
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	_, resp, err := httputils.JSONRequest(self.client, context.Background(), method, reqUrl, header, body, self.debug)
+	if err != nil {
+		if e, ok := err.(*httputils.JSONClientError); ok && e.Code == 404 {
+			return nil, cloudprovider.ErrNotFound
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (self *SGoogleClient) projectResource(project, resource string) string {
+	if len(project) == 0 {
+		project = self.projectId
+	}
+	return fmt.Sprintf("projects/%s/%s", project, resource)
+}
+
+// resource 为资源的相对路径或完整的selfLink
+func (self *SGoogleClient) get(resource string, retVal interface{}) error {
+	resp, err := self.jsonRequest(httputils.GET, resource, nil, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Unmarshal(retVal)
+}
+
+func (self *SGoogleClient) listAll(resource string, params map[string]string, retVal interface{}) error {
+	return self.listAllInProject("", resource, params, retVal)
+}
+
+func (self *SGoogleClient) listAllInProject(project, resource string, params map[string]string, retVal interface{}) error {
+	items := jsonutils.NewArray()
+	query := map[string]string{"maxResults": fmt.Sprintf("%d", GOOGLE_MAX_RESULTS)}
+	for k, v := range params {
+		query[k] = v
+	}
+	for {
+		resp, err := self.jsonRequest(httputils.GET, self.projectResource(project, resource), query, nil)
+		if err != nil {
+			return err
+		}
+		_items, _ := resp.GetArray("items")
+		items.Add(_items...)
+		nextPageToken, _ := resp.GetString("nextPageToken")
+		if len(nextPageToken) == 0 {
+			break
+		}
+		query["pageToken"] = nextPageToken
+	}
+	return items.Unmarshal(retVal)
+}
+
+// 创建资源, 返回新资源的相对路径
+func (self *SGoogleClient) insert(resource string, body jsonutils.JSONObject) (string, error) {
+	resp, err := self.jsonRequest(httputils.POST, self.projectResource("", resource), nil, body)
+	if err != nil {
+		return "", err
+	}
+	return self.waitOperation(resp)
+}
+
+func (self *SGoogleClient) delete(id string) error {
+	resp, err := self.jsonRequest(httputils.DELETE, id, nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = self.waitOperation(resp)
+	return err
+}
+
+// 对资源执行操作, 例如 start, stop, setTags
+func (self *SGoogleClient) action(id, action string, params map[string]string, body jsonutils.JSONObject) (string, error) {
+	resp, err := self.jsonRequest(httputils.POST, fmt.Sprintf("%s/%s", id, action), params, body)
+	if err != nil {
+		return "", err
+	}
+	return self.waitOperation(resp)
+}
+
+type SOperationError struct {
+	Code    string
+	Message string
+}
+
+type SOperation struct {
+	Name          string
+	Status        string
+	TargetLink    string
+	SelfLink      string
+	HttpErrorCode int
+	Error         struct {
+		Errors []SOperationError
+	}
+}
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/zoneOperations
+func (self *SGoogleClient) waitOperation(resp jsonutils.JSONObject) (string, error) {
+	op := SOperation{}
+	err := resp.Unmarshal(&op)
+	if err != nil {
+		return "", err
+	}
+	startTime := time.Now()
+	for op.Status != "DONE" {
+		if time.Now().Sub(startTime) > 30*time.Minute {
+			return "", cloudprovider.ErrTimeout
+		}
+		time.Sleep(3 * time.Second)
+		err = self.get(op.SelfLink, &op)
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(op.Error.Errors) > 0 {
+		msgs := []string{}
+		for _, e := range op.Error.Errors {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", e.Code, e.Message))
+		}
+		log.Errorf("operation %s failed: %s", op.Name, strings.Join(msgs, ";"))
+		return "", fmt.Errorf("operation %s failed: %s", op.Name, strings.Join(msgs, ";"))
+	}
+	return getGlobalId(op.TargetLink), nil
+}
+
+func (self *SGoogleClient) fetchRegions() error {
+	regions := []SRegion{}
+	err := self.listAll("regions", nil, &regions)
+	if err != nil {
+		return err
+	}
+	zones := []SZone{}
+	err = self.listAll("zones", nil, &zones)
+	if err != nil {
+		return err
+	}
+	self.iregions = make([]cloudprovider.ICloudRegion, len(regions))
+	for i := 0; i < len(regions); i += 1 {
+		regions[i].client = self
+		regions[i].izones = []cloudprovider.ICloudZone{}
+		for j := range zones {
+			if zones[j].Region == regions[i].SelfLink {
+				zone := zones[j]
+				zone.region = &regions[i]
+				regions[i].izones = append(regions[i].izones, &zone)
+			}
+		}
+		self.iregions[i] = &regions[i]
+	}
+	return nil
+}
+
+func (self *SGoogleClient) GetRegions() []SRegion {
+	regions := make([]SRegion, len(self.iregions))
+	for i := 0; i < len(regions); i += 1 {
+		region := self.iregions[i].(*SRegion)
+		regions[i] = *region
+	}
+	return regions
+}
+
+func (self *SGoogleClient) GetSubAccounts() ([]cloudprovider.SSubAccount, error) {
+	subAccount := cloudprovider.SSubAccount{
+		Name:         self.projectId,
+		State:        models.CLOUD_PROVIDER_CONNECTED,
+		Account:      fmt.Sprintf("%s/%s", self.projectId, self.clientEmail),
+		HealthStatus: models.CLOUD_PROVIDER_HEALTH_NORMAL,
+	}
+	return []cloudprovider.SSubAccount{subAccount}, nil
+}
+
+func (self *SGoogleClient) GetIRegions() []cloudprovider.ICloudRegion {
+	return self.iregions
+}
+
+func (self *SGoogleClient) GetIRegionById(id string) (cloudprovider.ICloudRegion, error) {
+	for i := 0; i < len(self.iregions); i += 1 {
+		if self.iregions[i].GetGlobalId() == id {
+			return self.iregions[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SGoogleClient) GetRegion(regionId string) *SRegion {
+	if len(regionId) == 0 {
+		regionId = GOOGLE_DEFAULT_REGION
+	}
+	for i := 0; i < len(self.iregions); i += 1 {
+		if self.iregions[i].GetId() == regionId {
+			return self.iregions[i].(*SRegion)
+		}
+	}
+	return nil
+}
+
+func (self *SGoogleClient) GetIProjects() ([]cloudprovider.ICloudProject, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (self *SGoogleClient) GetVersion() string {
+	return GOOGLE_API_VERSION
+}
+
+// 资源的全局ID为selfLink去掉API前缀后的相对路径
+func getGlobalId(selfLink string) string {
+	return strings.TrimPrefix(selfLink, GOOGLE_COMPUTE_DOMAIN)
+}
+
+// 取selfLink的最后一段, 例如 .../zones/asia-east1-a 返回 asia-east1-a
+func getResourceName(selfLink string) string {
+	segs := strings.Split(selfLink, "/")
+	return segs[len(segs)-1]
+}
+
+type SResourceBase struct {
+	Id                string
+	Name              string
+	SelfLink          string
+	Description       string
+	CreationTimestamp string
+}
+
+func (self *SResourceBase) GetId() string {
+	return self.Id
+}
+
+func (self *SResourceBase) GetName() string {
+	return self.Name
+}
+
+func (self *SResourceBase) GetGlobalId() string {
+	return getGlobalId(self.SelfLink)
+}
+
+func (self *SResourceBase) GetCreateTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, self.CreationTimestamp)
+	return t
+}
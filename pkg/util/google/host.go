@@ -0,0 +1,189 @@
+package google
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+type SHost struct {
+	zone *SZone
+}
+
+func (self *SHost) GetId() string {
+	return fmt.Sprintf("%s-%s", self.zone.region.client.providerId, self.zone.GetId())
+}
+
+func (self *SHost) GetName() string {
+	return fmt.Sprintf("%s-%s", self.zone.region.client.providerName, self.zone.GetId())
+}
+
+func (self *SHost) GetGlobalId() string {
+	return fmt.Sprintf("%s-%s", self.zone.region.client.providerId, self.zone.GetId())
+}
+
+func (self *SHost) GetStatus() string {
+	return models.HOST_STATUS_RUNNING
+}
+
+func (self *SHost) Refresh() error {
+	return nil
+}
+
+func (self *SHost) IsEmulated() bool {
+	return true
+}
+
+func (self *SHost) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SHost) GetIVMs() ([]cloudprovider.ICloudVM, error) {
+	vms, err := self.zone.region.GetInstances(self.zone.GetId())
+	if err != nil {
+		return nil, err
+	}
+	ivms := make([]cloudprovider.ICloudVM, len(vms))
+	for i := 0; i < len(vms); i += 1 {
+		vms[i].host = self
+		ivms[i] = &vms[i]
+	}
+	return ivms, nil
+}
+
+func (self *SHost) GetIVMById(id string) (cloudprovider.ICloudVM, error) {
+	vm, err := self.GetInstanceById(id)
+	if err != nil {
+		return nil, err
+	}
+	return vm, nil
+}
+
+func (self *SHost) GetIWires() ([]cloudprovider.ICloudWire, error) {
+	return self.zone.GetIWires()
+}
+
+func (self *SHost) GetIStorages() ([]cloudprovider.ICloudStorage, error) {
+	return self.zone.GetIStorages()
+}
+
+func (self *SHost) GetIStorageById(id string) (cloudprovider.ICloudStorage, error) {
+	return self.zone.GetIStorageById(id)
+}
+
+func (self *SHost) GetEnabled() bool {
+	return true
+}
+
+func (self *SHost) GetHostStatus() string {
+	return models.HOST_ONLINE
+}
+
+func (self *SHost) GetAccessIp() string {
+	return ""
+}
+
+func (self *SHost) GetAccessMac() string {
+	return ""
+}
+
+func (self *SHost) GetSysInfo() jsonutils.JSONObject {
+	info := jsonutils.NewDict()
+	info.Add(jsonutils.NewString(CLOUD_PROVIDER_GOOGLE), "manufacture")
+	return info
+}
+
+func (self *SHost) GetSN() string {
+	return ""
+}
+
+func (self *SHost) GetCpuCount() int8 {
+	return 0
+}
+
+func (self *SHost) GetNodeCount() int8 {
+	return 0
+}
+
+func (self *SHost) GetCpuDesc() string {
+	return ""
+}
+
+func (self *SHost) GetCpuMhz() int {
+	return 0
+}
+
+func (self *SHost) GetMemSizeMB() int {
+	return 0
+}
+
+func (self *SHost) GetStorageSizeMB() int {
+	return 0
+}
+
+func (self *SHost) GetStorageType() string {
+	return models.DISK_TYPE_HYBRID
+}
+
+func (self *SHost) GetHostType() string {
+	return models.HOST_TYPE_GOOGLE
+}
+
+func (self *SHost) GetIsMaintenance() bool {
+	return false
+}
+
+func (self *SHost) GetVersion() string {
+	return GOOGLE_API_VERSION
+}
+
+func (self *SHost) GetManagerId() string {
+	return self.zone.region.client.providerId
+}
+
+func (self *SHost) GetInstanceById(instanceId string) (*SInstance, error) {
+	instance, err := self.zone.region.GetInstance(instanceId)
+	if err != nil {
+		return nil, err
+	}
+	instance.host = self
+	return instance, nil
+}
+
+func (self *SHost) CreateVM(desc *cloudprovider.SManagedVMCreateConfig) (cloudprovider.ICloudVM, error) {
+	net := self.zone.getNetworkById(desc.ExternalNetworkId)
+	if net == nil {
+		return nil, fmt.Errorf("invalid network ID %s", desc.ExternalNetworkId)
+	}
+
+	secgroupIds := desc.ExternalSecgroupIds
+	if len(secgroupIds) == 0 && len(desc.ExternalSecgroupId) > 0 {
+		secgroupIds = []string{desc.ExternalSecgroupId}
+	}
+
+	// 未指定实例类型时使用自定义机型
+	// https://cloud.google.com/compute/docs/instances/creating-instance-with-custom-machine-type
+	instanceType := desc.InstanceType
+	if len(instanceType) == 0 {
+		instanceType = fmt.Sprintf("custom-%d-%d", desc.Cpu, desc.MemoryMB)
+	}
+
+	vmId, err := self.zone.region.CreateInstance(self.zone.GetId(), desc.Name, desc.Description, instanceType,
+		desc.ExternalImageId, desc.SysDisk, desc.DataDisks, net.SelfLink, desc.IpAddr, secgroupIds, desc.PublicKey, desc.UserData)
+	if err != nil {
+		return nil, err
+	}
+
+	vm, err := self.GetInstanceById(vmId)
+	if err != nil {
+		return nil, err
+	}
+	return vm, nil
+}
+
+func (self *SHost) GetIHostNics() ([]cloudprovider.ICloudHostNetInterface, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
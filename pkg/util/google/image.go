@@ -0,0 +1,206 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/utils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+const (
+	ImageStatusPending = "PENDING"
+	ImageStatusReady   = "READY"
+	ImageStatusFailed  = "FAILED"
+)
+
+// 公共镜像所在的项目
+// https://cloud.google.com/compute/docs/images#os-compute-support
+var PublicImageProjects = []string{
+	"centos-cloud",
+	"cos-cloud",
+	"debian-cloud",
+	"rhel-cloud",
+	"suse-cloud",
+	"ubuntu-os-cloud",
+	"windows-cloud",
+}
+
+// 镜像名称前缀与发行版的对应关系
+var imageOsDists = map[string]string{
+	"centos":  "CentOS",
+	"cos":     "COS",
+	"debian":  "Debian",
+	"rhel":    "RHEL",
+	"sles":    "SUSE",
+	"ubuntu":  "Ubuntu",
+	"windows": "Windows Server",
+}
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/images
+type SImage struct {
+	storageCache *SStoragecache
+
+	SResourceBase
+
+	Status           string
+	SourceType       string
+	DiskSizeGb       int
+	ArchiveSizeBytes int64
+	Family           string
+	Licenses         []string
+	SourceDisk       string
+	SourceSnapshot   string
+	Deprecated       struct {
+		State string
+	}
+}
+
+func (self *SImage) GetStatus() string {
+	switch self.Status {
+	case ImageStatusPending:
+		return models.CACHED_IMAGE_STATUS_CACHING
+	case ImageStatusReady:
+		return models.CACHED_IMAGE_STATUS_READY
+	default:
+		return models.CACHED_IMAGE_STATUS_CACHE_FAILED
+	}
+}
+
+func (self *SImage) GetImageStatus() string {
+	switch self.Status {
+	case ImageStatusPending:
+		return cloudprovider.IMAGE_STATUS_QUEUED
+	case ImageStatusReady:
+		return cloudprovider.IMAGE_STATUS_ACTIVE
+	default:
+		return cloudprovider.IMAGE_STATUS_KILLED
+	}
+}
+
+func (self *SImage) Refresh() error {
+	image, err := self.storageCache.region.GetImage(self.GetGlobalId())
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, image)
+}
+
+func (self *SImage) IsEmulated() bool {
+	return false
+}
+
+func (self *SImage) GetMetadata() *jsonutils.JSONDict {
+	data := jsonutils.NewDict()
+	data.Add(jsonutils.NewString(self.GetOsArch()), "os_arch")
+	data.Add(jsonutils.NewString(self.GetOsType()), "os_name")
+	if dist := self.GetOsDist(); len(dist) > 0 {
+		data.Add(jsonutils.NewString(dist), "os_distribution")
+	}
+	if version := self.GetOsVersion(); len(version) > 0 {
+		data.Add(jsonutils.NewString(version), "os_version")
+	}
+	return data
+}
+
+func (self *SImage) getProject() string {
+	segs := strings.Split(self.GetGlobalId(), "/")
+	if len(segs) > 1 && segs[0] == "projects" {
+		return segs[1]
+	}
+	return ""
+}
+
+func (self *SImage) GetImageType() string {
+	if utils.IsInStringArray(self.getProject(), PublicImageProjects) {
+		return cloudprovider.CachedImageTypeSystem
+	}
+	return cloudprovider.CachedImageTypeCustomized
+}
+
+func (self *SImage) GetSize() int64 {
+	return int64(self.DiskSizeGb) * 1024 * 1024 * 1024
+}
+
+func (self *SImage) GetOsType() string {
+	if strings.HasPrefix(self.Name, "windows") {
+		return "Windows"
+	}
+	return "Linux"
+}
+
+func (self *SImage) GetOsDist() string {
+	prefix := strings.Split(self.Name, "-")[0]
+	return imageOsDists[prefix]
+}
+
+// 镜像名称中第一个数字段为版本, 例如 debian-9-stretch-v20190312 为 9
+func (self *SImage) GetOsVersion() string {
+	for _, seg := range strings.Split(self.Name, "-") {
+		if len(seg) > 0 && seg[0] >= '0' && seg[0] <= '9' {
+			return seg
+		}
+	}
+	return ""
+}
+
+func (self *SImage) GetOsArch() string {
+	return "x86_64"
+}
+
+func (self *SImage) GetMinOsDiskSizeGb() int {
+	return self.DiskSizeGb
+}
+
+func (self *SImage) GetMinRamSizeMb() int {
+	return 0
+}
+
+func (self *SImage) GetImageFormat() string {
+	return "raw"
+}
+
+func (self *SImage) Delete(ctx context.Context) error {
+	return self.storageCache.region.client.delete(self.GetGlobalId())
+}
+
+func (self *SImage) GetIStoragecache() cloudprovider.ICloudStoragecache {
+	return self.storageCache
+}
+
+func (self *SRegion) GetImage(id string) (*SImage, error) {
+	image := SImage{}
+	err := self.client.get(id, &image)
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// project 为空时返回本项目的自定义镜像, 已弃用的镜像不返回
+func (self *SRegion) GetImages(project string) ([]SImage, error) {
+	images := []SImage{}
+	err := self.client.listAllInProject(project, "global/images", nil, &images)
+	if err != nil {
+		return nil, err
+	}
+	ret := []SImage{}
+	for i := range images {
+		if len(images[i].Deprecated.State) == 0 {
+			ret = append(ret, images[i])
+		}
+	}
+	return ret, nil
+}
+
+func (self *SRegion) createImage(snapshotId, name, desc string) (string, error) {
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.NewString(name), "name")
+	body.Add(jsonutils.NewString(desc), "description")
+	body.Add(jsonutils.NewString(fmt.Sprintf("%s%s", GOOGLE_COMPUTE_DOMAIN, getGlobalId(snapshotId))), "sourceSnapshot")
+	return self.client.insert("global/images", body)
+}
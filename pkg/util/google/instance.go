@@ -0,0 +1,499 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/util/ansible"
+	"yunion.io/x/onecloud/pkg/util/billing"
+)
+
+const (
+	InstanceStatusProvisioning = "PROVISIONING"
+	InstanceStatusStaging      = "STAGING"
+	InstanceStatusRunning      = "RUNNING"
+	InstanceStatusStopping     = "STOPPING"
+	InstanceStatusStopped      = "STOPPED"
+	InstanceStatusSuspending   = "SUSPENDING"
+	InstanceStatusSuspended    = "SUSPENDED"
+	// 谷歌云的TERMINATED表示实例已关机, 并非已删除
+	InstanceStatusTerminated = "TERMINATED"
+)
+
+type SAccessConfig struct {
+	Type  string
+	Name  string
+	NatIP string
+}
+
+type SNetworkInterface struct {
+	Name          string
+	Network       string
+	Subnetwork    string
+	NetworkIP     string
+	AccessConfigs []SAccessConfig
+}
+
+type SAttachedDisk struct {
+	Type       string
+	Mode       string
+	Source     string
+	DeviceName string
+	Index      int
+	Boot       bool
+	AutoDelete bool
+	Licenses   []string
+}
+
+type SMetadataItem struct {
+	Key   string
+	Value string
+}
+
+type SInstanceMetadata struct {
+	Fingerprint string
+	Items       []SMetadataItem
+}
+
+type SInstanceTags struct {
+	Fingerprint string
+	Items       []string
+}
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/machineTypes
+type SMachineType struct {
+	SResourceBase
+
+	GuestCpus int
+	MemoryMb  int
+}
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/instances
+type SInstance struct {
+	host *SHost
+
+	machineType *SMachineType
+
+	SResourceBase
+
+	MachineType       string
+	Status            string
+	Zone              string
+	CpuPlatform       string
+	Tags              SInstanceTags
+	NetworkInterfaces []SNetworkInterface
+	Disks             []SAttachedDisk
+	Metadata          SInstanceMetadata
+}
+
+func (self *SInstance) GetStatus() string {
+	switch self.Status {
+	case InstanceStatusRunning:
+		return models.VM_RUNNING
+	case InstanceStatusProvisioning, InstanceStatusStaging:
+		return models.VM_STARTING
+	case InstanceStatusStopping, InstanceStatusSuspending:
+		return models.VM_STOPPING
+	case InstanceStatusStopped, InstanceStatusSuspended, InstanceStatusTerminated:
+		return models.VM_READY
+	default:
+		return models.VM_UNKNOWN
+	}
+}
+
+func (self *SInstance) Refresh() error {
+	instance, err := self.host.zone.region.GetInstance(self.GetGlobalId())
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, instance)
+}
+
+func (self *SInstance) IsEmulated() bool {
+	return false
+}
+
+func (self *SInstance) GetInstanceType() string {
+	return getResourceName(self.MachineType)
+}
+
+func (self *SInstance) GetMetadata() *jsonutils.JSONDict {
+	data := jsonutils.NewDict()
+	data.Add(jsonutils.NewString(self.host.zone.GetGlobalId()), "zone_ext_id")
+	secgroupIds := jsonutils.NewArray()
+	for _, tag := range self.getSecurityGroupTags() {
+		secgroupIds.Add(jsonutils.NewString(tag))
+	}
+	data.Add(secgroupIds, "secgroupIds")
+	return data
+}
+
+func (self *SInstance) GetProjectId() string {
+	return ""
+}
+
+func (self *SInstance) GetBillingType() string {
+	return models.BILLING_TYPE_POSTPAID
+}
+
+func (self *SInstance) GetExpiredAt() time.Time {
+	return time.Time{}
+}
+
+func (self *SInstance) GetIHost() cloudprovider.ICloudHost {
+	return self.host
+}
+
+func (self *SInstance) GetIDisks() ([]cloudprovider.ICloudDisk, error) {
+	idisks := []cloudprovider.ICloudDisk{}
+	for _, attached := range self.Disks {
+		disk, err := self.host.zone.region.GetDisk(attached.Source)
+		if err != nil {
+			return nil, err
+		}
+		storage, err := self.host.zone.getStorageByType(getResourceName(disk.Type))
+		if err != nil {
+			return nil, err
+		}
+		disk.storage = storage
+		// 将系统盘放到第0个位置
+		if attached.Boot {
+			idisks = append([]cloudprovider.ICloudDisk{disk}, idisks...)
+		} else {
+			idisks = append(idisks, disk)
+		}
+	}
+	return idisks, nil
+}
+
+func (self *SInstance) GetINics() ([]cloudprovider.ICloudNic, error) {
+	nics := make([]cloudprovider.ICloudNic, len(self.NetworkInterfaces))
+	for i := range self.NetworkInterfaces {
+		nics[i] = &SInstanceNic{instance: self, SNetworkInterface: self.NetworkInterfaces[i]}
+	}
+	return nics, nil
+}
+
+// 弹性公网IP暂不支持
+func (self *SInstance) GetIEIP() (cloudprovider.ICloudEIP, error) {
+	return nil, nil
+}
+
+func (self *SInstance) getMachineType() *SMachineType {
+	if self.machineType == nil {
+		machineType, err := self.host.zone.region.GetMachineType(self.MachineType)
+		if err != nil {
+			log.Errorf("GetMachineType %s error: %v", self.MachineType, err)
+			return &SMachineType{}
+		}
+		self.machineType = machineType
+	}
+	return self.machineType
+}
+
+func (self *SInstance) GetVcpuCount() int8 {
+	return int8(self.getMachineType().GuestCpus)
+}
+
+func (self *SInstance) GetVmemSizeMB() int {
+	return self.getMachineType().MemoryMb
+}
+
+func (self *SInstance) GetBootOrder() string {
+	return "dcn"
+}
+
+func (self *SInstance) GetVga() string {
+	return "std"
+}
+
+func (self *SInstance) GetVdi() string {
+	return "vnc"
+}
+
+// 系统盘的许可证中包含操作系统信息
+func (self *SInstance) GetOSType() string {
+	if strings.Contains(strings.ToLower(self.GetOSName()), "windows") {
+		return "Windows"
+	}
+	return "Linux"
+}
+
+func (self *SInstance) GetOSName() string {
+	for _, disk := range self.Disks {
+		if disk.Boot && len(disk.Licenses) > 0 {
+			return getResourceName(disk.Licenses[0])
+		}
+	}
+	return ""
+}
+
+func (self *SInstance) GetBios() string {
+	return "BIOS"
+}
+
+func (self *SInstance) GetMachine() string {
+	return "pc"
+}
+
+func (self *SInstance) getSecurityGroupTags() []string {
+	tags := []string{}
+	for _, tag := range self.Tags.Items {
+		if isSecurityGroupTag(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (self *SInstance) AssignSecurityGroup(secgroupId string) error {
+	return self.SetSecurityGroups(append(self.getSecurityGroupTags(), secgroupId))
+}
+
+// 仅替换安全组对应的网络标记, 保留其他标记
+func (self *SInstance) SetSecurityGroups(secgroupIds []string) error {
+	tags := []string{}
+	for _, tag := range self.Tags.Items {
+		if !isSecurityGroupTag(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	tags = append(tags, secgroupIds...)
+	return self.host.zone.region.SetInstanceTags(self.GetGlobalId(), self.Tags.Fingerprint, tags)
+}
+
+func (self *SInstance) GetHypervisor() string {
+	return models.HYPERVISOR_GOOGLE
+}
+
+func (self *SInstance) StartVM(ctx context.Context) error {
+	if self.GetStatus() == models.VM_RUNNING {
+		return nil
+	}
+	_, err := self.host.zone.region.client.action(self.GetGlobalId(), "start", nil, nil)
+	return err
+}
+
+func (self *SInstance) StopVM(ctx context.Context, isForce bool) error {
+	if self.GetStatus() == models.VM_READY {
+		return nil
+	}
+	_, err := self.host.zone.region.client.action(self.GetGlobalId(), "stop", nil, nil)
+	return err
+}
+
+func (self *SInstance) DeleteVM(ctx context.Context) error {
+	err := self.host.zone.region.client.delete(self.GetGlobalId())
+	if err == cloudprovider.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// 实例名称创建后不可修改
+func (self *SInstance) UpdateVM(ctx context.Context, name string) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SInstance) UpdateUserData(userData string) error {
+	return self.host.zone.region.SetInstanceMetadata(self.GetGlobalId(), self.Metadata, map[string]string{
+		"user-data":          userData,
+		"user-data-encoding": "base64",
+	})
+}
+
+func (self *SInstance) RebuildRoot(ctx context.Context, imageId string, passwd string, publicKey string, sysSizeGB int) (string, error) {
+	return "", cloudprovider.ErrNotSupported
+}
+
+// 谷歌云不支持设置密码, 仅更新登录公钥
+func (self *SInstance) DeployVM(ctx context.Context, name string, password string, publicKey string, deleteKeypair bool, description string) error {
+	if len(publicKey) == 0 && !deleteKeypair {
+		return nil
+	}
+	sshKeys := ""
+	if len(publicKey) > 0 {
+		sshKeys = fmt.Sprintf("%s:%s", ansible.PUBLIC_CLOUD_ANSIBLE_USER, publicKey)
+	}
+	return self.host.zone.region.SetInstanceMetadata(self.GetGlobalId(), self.Metadata, map[string]string{"ssh-keys": sshKeys})
+}
+
+// 调整配置需要实例处于关机状态
+func (self *SInstance) ChangeConfig(ctx context.Context, ncpu int, vmem int) error {
+	return self.ChangeConfig2(ctx, fmt.Sprintf("custom-%d-%d", ncpu, vmem))
+}
+
+func (self *SInstance) ChangeConfig2(ctx context.Context, instanceType string) error {
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.NewString(fmt.Sprintf("zones/%s/machineTypes/%s", self.host.zone.GetId(), instanceType)), "machineType")
+	_, err := self.host.zone.region.client.action(self.GetGlobalId(), "setMachineType", nil, body)
+	return err
+}
+
+func (self *SInstance) GetVNCInfo() (jsonutils.JSONObject, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SInstance) AttachDisk(ctx context.Context, diskId string) error {
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.NewString(getGlobalId(diskId)), "source")
+	_, err := self.host.zone.region.client.action(self.GetGlobalId(), "attachDisk", nil, body)
+	return err
+}
+
+func (self *SInstance) DetachDisk(ctx context.Context, diskId string) error {
+	for _, disk := range self.Disks {
+		if getGlobalId(disk.Source) == getGlobalId(diskId) {
+			_, err := self.host.zone.region.client.action(self.GetGlobalId(), "detachDisk", map[string]string{"deviceName": disk.DeviceName}, nil)
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *SInstance) CreateDisk(ctx context.Context, sizeMb int, uuid string, driver string) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SInstance) Renew(bc billing.SBillingCycle) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SInstance) GetError() error {
+	return nil
+}
+
+func (self *SRegion) GetInstance(id string) (*SInstance, error) {
+	instance := SInstance{}
+	err := self.client.get(id, &instance)
+	if err != nil {
+		return nil, err
+	}
+	return &instance, nil
+}
+
+func (self *SRegion) GetInstances(zoneId string) ([]SInstance, error) {
+	instances := []SInstance{}
+	err := self.client.listAll(fmt.Sprintf("zones/%s/instances", zoneId), nil, &instances)
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+func (self *SRegion) GetMachineType(id string) (*SMachineType, error) {
+	machineType := SMachineType{}
+	err := self.client.get(id, &machineType)
+	if err != nil {
+		return nil, err
+	}
+	return &machineType, nil
+}
+
+func (self *SRegion) CreateInstance(zoneId, name, desc, instanceType, imageId string, sysDisk cloudprovider.SDiskInfo, dataDisks []cloudprovider.SDiskInfo,
+	networkId, ipAddr string, secgroupIds []string, publicKey, userData string) (string, error) {
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.NewString(name), "name")
+	body.Add(jsonutils.NewString(desc), "description")
+	body.Add(jsonutils.NewString(fmt.Sprintf("zones/%s/machineTypes/%s", zoneId, instanceType)), "machineType")
+
+	disks := jsonutils.NewArray()
+	sysDiskParams := jsonutils.NewDict()
+	sysDiskParams.Add(jsonutils.NewString(getGlobalId(imageId)), "sourceImage")
+	if sysDisk.SizeGB > 0 {
+		sysDiskParams.Add(jsonutils.NewString(fmt.Sprintf("%d", sysDisk.SizeGB)), "diskSizeGb")
+	}
+	if len(sysDisk.StorageType) > 0 {
+		sysDiskParams.Add(jsonutils.NewString(fmt.Sprintf("zones/%s/diskTypes/%s", zoneId, sysDisk.StorageType)), "diskType")
+	}
+	disks.Add(jsonutils.Marshal(map[string]interface{}{
+		"boot":             true,
+		"autoDelete":       true,
+		"initializeParams": sysDiskParams,
+	}))
+	for _, dataDisk := range dataDisks {
+		dataDiskParams := jsonutils.NewDict()
+		dataDiskParams.Add(jsonutils.NewString(fmt.Sprintf("%d", dataDisk.SizeGB)), "diskSizeGb")
+		if len(dataDisk.StorageType) > 0 {
+			dataDiskParams.Add(jsonutils.NewString(fmt.Sprintf("zones/%s/diskTypes/%s", zoneId, dataDisk.StorageType)), "diskType")
+		}
+		if len(dataDisk.Name) > 0 {
+			dataDiskParams.Add(jsonutils.NewString(dataDisk.Name), "diskName")
+		}
+		disks.Add(jsonutils.Marshal(map[string]interface{}{
+			"autoDelete":       true,
+			"initializeParams": dataDiskParams,
+		}))
+	}
+	body.Add(disks, "disks")
+
+	nic := jsonutils.NewDict()
+	nic.Add(jsonutils.NewString(networkId), "subnetwork")
+	if len(ipAddr) > 0 {
+		nic.Add(jsonutils.NewString(ipAddr), "networkIP")
+	}
+	// 分配临时公网IP, 否则实例无法访问外网
+	accessConfig := jsonutils.NewDict()
+	accessConfig.Add(jsonutils.NewString("ONE_TO_ONE_NAT"), "type")
+	accessConfig.Add(jsonutils.NewString("External NAT"), "name")
+	nic.Add(jsonutils.NewArray(accessConfig), "accessConfigs")
+	body.Add(jsonutils.NewArray(nic), "networkInterfaces")
+
+	if len(secgroupIds) > 0 {
+		tags := jsonutils.NewDict()
+		tags.Add(jsonutils.NewStringArray(secgroupIds), "items")
+		body.Add(tags, "tags")
+	}
+
+	items := jsonutils.NewArray()
+	if len(publicKey) > 0 {
+		items.Add(jsonutils.Marshal(SMetadataItem{Key: "ssh-keys", Value: fmt.Sprintf("%s:%s", ansible.PUBLIC_CLOUD_ANSIBLE_USER, publicKey)}))
+	}
+	if len(userData) > 0 {
+		items.Add(jsonutils.Marshal(SMetadataItem{Key: "user-data", Value: userData}))
+		items.Add(jsonutils.Marshal(SMetadataItem{Key: "user-data-encoding", Value: "base64"}))
+	}
+	if items.Length() > 0 {
+		metadata := jsonutils.NewDict()
+		metadata.Add(items, "items")
+		body.Add(metadata, "metadata")
+	}
+
+	return self.client.insert(fmt.Sprintf("zones/%s/instances", zoneId), body)
+}
+
+// 修改网络标记需要提供当前的fingerprint
+func (self *SRegion) SetInstanceTags(instanceId string, fingerprint string, tags []string) error {
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.NewStringArray(tags), "items")
+	body.Add(jsonutils.NewString(fingerprint), "fingerprint")
+	_, err := self.client.action(instanceId, "setTags", nil, body)
+	return err
+}
+
+// 合并元数据, 值为空时删除对应的key
+func (self *SRegion) SetInstanceMetadata(instanceId string, metadata SInstanceMetadata, update map[string]string) error {
+	items := jsonutils.NewArray()
+	for _, item := range metadata.Items {
+		if _, ok := update[item.Key]; !ok {
+			items.Add(jsonutils.Marshal(item))
+		}
+	}
+	for k, v := range update {
+		if len(v) > 0 {
+			items.Add(jsonutils.Marshal(SMetadataItem{Key: k, Value: v}))
+		}
+	}
+	body := jsonutils.NewDict()
+	body.Add(items, "items")
+	body.Add(jsonutils.NewString(metadata.Fingerprint), "fingerprint")
+	_, err := self.client.action(instanceId, "setMetadata", nil, body)
+	return err
+}
@@ -0,0 +1,35 @@
+package google
+
+import (
+	"yunion.io/x/pkg/util/netutils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SInstanceNic struct {
+	instance *SInstance
+
+	SNetworkInterface
+}
+
+func (self *SInstanceNic) GetIP() string {
+	return self.NetworkIP
+}
+
+// 谷歌云不返回网卡的MAC地址, 按IP生成
+func (self *SInstanceNic) GetMAC() string {
+	ip, _ := netutils.NewIPV4Addr(self.NetworkIP)
+	return ip.ToMac("00:16:")
+}
+
+func (self *SInstanceNic) GetDriver() string {
+	return "virtio"
+}
+
+func (self *SInstanceNic) GetINetwork() cloudprovider.ICloudNetwork {
+	network := self.instance.host.zone.getNetworkById(getGlobalId(self.Subnetwork))
+	if network == nil {
+		return nil
+	}
+	return network
+}
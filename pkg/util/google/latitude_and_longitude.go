@@ -0,0 +1,28 @@
+package google
+
+import "yunion.io/x/onecloud/pkg/cloudprovider"
+
+// https://cloud.google.com/compute/docs/regions-zones/
+// ref: https://countrycode.org
+var LatitudeAndLongitude = map[string]cloudprovider.SGeographicInfo{
+	"asia-east1":              {Latitude: 24.051796, Longitude: 120.516135, City: "Changhua", CountryCode: "TW"},
+	"asia-east2":              {Latitude: 22.396428, Longitude: 114.109497, City: "Hongkong", CountryCode: "CN"},
+	"asia-northeast1":         {Latitude: 35.689487, Longitude: 139.691706, City: "Tokyo", CountryCode: "JP"},
+	"asia-northeast2":         {Latitude: 34.693738, Longitude: 135.502165, City: "Osaka", CountryCode: "JP"},
+	"asia-south1":             {Latitude: 19.075984, Longitude: 72.877656, City: "Mumbai", CountryCode: "IN"},
+	"asia-southeast1":         {Latitude: 1.360386, Longitude: 103.821195, City: "Singapore", CountryCode: "SG"},
+	"australia-southeast1":    {Latitude: -33.868820, Longitude: 151.209296, City: "Sydney", CountryCode: "AU"},
+	"europe-north1":           {Latitude: 60.569370, Longitude: 27.187910, City: "Hamina", CountryCode: "FI"},
+	"europe-west1":            {Latitude: 50.470604, Longitude: 3.817160, City: "St. Ghislain", CountryCode: "BE"},
+	"europe-west2":            {Latitude: 51.507351, Longitude: -0.127758, City: "London", CountryCode: "GB"},
+	"europe-west3":            {Latitude: 50.110922, Longitude: 8.682127, City: "Frankfurt", CountryCode: "DE"},
+	"europe-west4":            {Latitude: 53.438660, Longitude: 6.835480, City: "Eemshaven", CountryCode: "NL"},
+	"europe-west6":            {Latitude: 47.376887, Longitude: 8.541694, City: "Zurich", CountryCode: "CH"},
+	"northamerica-northeast1": {Latitude: 45.501689, Longitude: -73.567256, City: "Montreal", CountryCode: "CA"},
+	"southamerica-east1":      {Latitude: -23.550520, Longitude: -46.633309, City: "Sao Paulo", CountryCode: "BR"},
+	"us-central1":             {Latitude: 41.262128, Longitude: -95.861391, City: "Council Bluffs", CountryCode: "US"},
+	"us-east1":                {Latitude: 33.196003, Longitude: -80.013137, City: "Moncks Corner", CountryCode: "US"},
+	"us-east4":                {Latitude: 39.043757, Longitude: -77.487442, City: "Ashburn", CountryCode: "US"},
+	"us-west1":                {Latitude: 45.601506, Longitude: -121.184159, City: "The Dalles", CountryCode: "US"},
+	"us-west2":                {Latitude: 34.052234, Longitude: -118.243685, City: "Los Angeles", CountryCode: "US"},
+}
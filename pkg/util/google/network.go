@@ -0,0 +1,128 @@
+package google
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/util/netutils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/subnetworks
+type SNetwork struct {
+	wire *SWire
+
+	SResourceBase
+
+	Network               string
+	IpCidrRange           string
+	GatewayAddress        string
+	Region                string
+	PrivateIpGoogleAccess bool
+	Fingerprint           string
+}
+
+func (self *SNetwork) GetStatus() string {
+	return models.NETWORK_STATUS_AVAILABLE
+}
+
+func (self *SNetwork) Refresh() error {
+	network, err := self.wire.region.GetNetwork(self.GetGlobalId())
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, network)
+}
+
+func (self *SNetwork) IsEmulated() bool {
+	return false
+}
+
+func (self *SNetwork) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SNetwork) GetProjectId() string {
+	return ""
+}
+
+func (self *SNetwork) GetIWire() cloudprovider.ICloudWire {
+	return self.wire
+}
+
+// 谷歌云保留子网的前两个和最后两个地址
+// https://cloud.google.com/vpc/docs/vpc#reserved_ip_addresses_in_every_subnet
+func (self *SNetwork) GetIpStart() string {
+	pref, _ := netutils.NewIPV4Prefix(self.IpCidrRange)
+	startIp := pref.Address.NetAddr(pref.MaskLen) // 0
+	startIp = startIp.StepUp()                    // 1
+	startIp = startIp.StepUp()                    // 2
+	return startIp.String()
+}
+
+func (self *SNetwork) GetIpEnd() string {
+	pref, _ := netutils.NewIPV4Prefix(self.IpCidrRange)
+	endIp := pref.Address.BroadcastAddr(pref.MaskLen) // 255
+	endIp = endIp.StepDown()                          // 254
+	endIp = endIp.StepDown()                          // 253
+	return endIp.String()
+}
+
+func (self *SNetwork) GetIpMask() int8 {
+	pref, _ := netutils.NewIPV4Prefix(self.IpCidrRange)
+	return pref.MaskLen
+}
+
+func (self *SNetwork) GetGateway() string {
+	return self.GatewayAddress
+}
+
+func (self *SNetwork) GetServerType() string {
+	return models.NETWORK_TYPE_GUEST
+}
+
+func (self *SNetwork) GetIsPublic() bool {
+	return true
+}
+
+func (self *SNetwork) Delete() error {
+	return self.wire.region.client.delete(self.GetGlobalId())
+}
+
+func (self *SNetwork) GetAllocTimeoutSeconds() int {
+	return 120 // 2 minutes
+}
+
+func (self *SRegion) GetNetwork(id string) (*SNetwork, error) {
+	network := SNetwork{}
+	err := self.client.get(id, &network)
+	if err != nil {
+		return nil, err
+	}
+	return &network, nil
+}
+
+// networkId 为空时返回地域内所有子网
+func (self *SRegion) GetNetworks(networkId string) ([]SNetwork, error) {
+	params := map[string]string{}
+	if len(networkId) > 0 {
+		params["filter"] = fmt.Sprintf(`network="%s%s"`, GOOGLE_COMPUTE_DOMAIN, getGlobalId(networkId))
+	}
+	networks := []SNetwork{}
+	err := self.client.listAll(fmt.Sprintf("regions/%s/subnetworks", self.Name), params, &networks)
+	if err != nil {
+		return nil, err
+	}
+	return networks, nil
+}
+
+func (self *SRegion) createNetwork(network string, name string, cidr string, desc string) (string, error) {
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.NewString(name), "name")
+	body.Add(jsonutils.NewString(desc), "description")
+	body.Add(jsonutils.NewString(network), "network")
+	body.Add(jsonutils.NewString(cidr), "ipCidrRange")
+	return self.client.insert(fmt.Sprintf("regions/%s/subnetworks", self.Name), body)
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/httperrors"
+	"yunion.io/x/onecloud/pkg/mcclient"
+	"yunion.io/x/onecloud/pkg/util/google"
+)
+
+type SGoogleProviderFactory struct {
+}
+
+func (self *SGoogleProviderFactory) GetId() string {
+	return google.CLOUD_PROVIDER_GOOGLE
+}
+
+func (self *SGoogleProviderFactory) GetName() string {
+	return google.CLOUD_PROVIDER_GOOGLE_CN
+}
+
+func (self *SGoogleProviderFactory) ValidateChangeBandwidth(instanceId string, bandwidth int64) error {
+	return fmt.Errorf("Changing %s bandwidth is not supported", google.CLOUD_PROVIDER_GOOGLE)
+}
+
+func (self *SGoogleProviderFactory) IsPublicCloud() bool {
+	return true
+}
+
+func (self *SGoogleProviderFactory) IsOnPremise() bool {
+	return false
+}
+
+func (self *SGoogleProviderFactory) IsSupportPrepaidResources() bool {
+	return false
+}
+
+func (self *SGoogleProviderFactory) NeedSyncSkuFromCloud() bool {
+	return false
+}
+
+// 凭证取自服务账号的JSON密钥文件
+func validateCredential(data jsonutils.JSONObject) (string, string, error) {
+	keys := []string{"gcp_project_id", "gcp_client_email", "gcp_private_key_id", "gcp_private_key"}
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i], _ = data.GetString(key)
+		if len(values[i]) == 0 {
+			return "", "", httperrors.NewMissingParameterError(key)
+		}
+	}
+	account := fmt.Sprintf("%s/%s", values[0], values[1])
+	secret := fmt.Sprintf("%s/%s", values[2], values[3])
+	return account, secret, nil
+}
+
+func (self *SGoogleProviderFactory) ValidateCreateCloudaccountData(ctx context.Context, userCred mcclient.TokenCredential, data *jsonutils.JSONDict) error {
+	account, secret, err := validateCredential(data)
+	if err != nil {
+		return err
+	}
+	data.Set("account", jsonutils.NewString(account))
+	data.Set("secret", jsonutils.NewString(secret))
+	return nil
+}
+
+func (self *SGoogleProviderFactory) ValidateUpdateCloudaccountCredential(ctx context.Context, userCred mcclient.TokenCredential, data jsonutils.JSONObject, cloudaccount string) (*cloudprovider.SCloudaccount, error) {
+	account, secret, err := validateCredential(data)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudprovider.SCloudaccount{
+		Account: account,
+		Secret:  secret,
+	}, nil
+}
+
+func (self *SGoogleProviderFactory) GetProvider(providerId, providerName, url, account, secret string) (cloudprovider.ICloudProvider, error) {
+	client, err := google.NewGoogleClient(providerId, providerName, account, secret, false)
+	if err != nil {
+		return nil, err
+	}
+	return &SGoogleProvider{
+		SBaseProvider: cloudprovider.NewBaseProvider(self),
+		client:        client,
+	}, nil
+}
+
+func init() {
+	factory := SGoogleProviderFactory{}
+	cloudprovider.RegisterFactory(&factory)
+}
+
+type SGoogleProvider struct {
+	cloudprovider.SBaseProvider
+	client *google.SGoogleClient
+}
+
+func (self *SGoogleProvider) GetVersion() string {
+	return self.client.GetVersion()
+}
+
+func (self *SGoogleProvider) GetSysInfo() (jsonutils.JSONObject, error) {
+	regions := self.client.GetIRegions()
+	info := jsonutils.NewDict()
+	info.Add(jsonutils.NewInt(int64(len(regions))), "region_count")
+	info.Add(jsonutils.NewString(google.GOOGLE_API_VERSION), "api_version")
+	return info, nil
+}
+
+func (self *SGoogleProvider) GetIRegions() []cloudprovider.ICloudRegion {
+	return self.client.GetIRegions()
+}
+
+func (self *SGoogleProvider) GetIRegionById(extId string) (cloudprovider.ICloudRegion, error) {
+	return self.client.GetIRegionById(extId)
+}
+
+func (self *SGoogleProvider) GetBalance() (float64, error) {
+	return 0.0, nil
+}
+
+func (self *SGoogleProvider) GetSubAccounts() ([]cloudprovider.SSubAccount, error) {
+	return self.client.GetSubAccounts()
+}
+
+func (self *SGoogleProvider) GetIProjects() ([]cloudprovider.ICloudProject, error) {
+	return self.client.GetIProjects()
+}
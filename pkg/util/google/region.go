@@ -0,0 +1,356 @@
+package google
+
+import (
+	"fmt"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/util/secrules"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/regions
+type SRegion struct {
+	client *SGoogleClient
+
+	izones []cloudprovider.ICloudZone
+	ivpcs  []cloudprovider.ICloudVpc
+
+	storageCache *SStoragecache
+
+	SResourceBase
+
+	Status string
+	Zones  []string
+}
+
+func (self *SRegion) GetClient() *SGoogleClient {
+	return self.client
+}
+
+func (self *SRegion) GetName() string {
+	return fmt.Sprintf("%s %s", CLOUD_PROVIDER_GOOGLE_CN, self.Name)
+}
+
+func (self *SRegion) GetId() string {
+	return self.Name
+}
+
+func (self *SRegion) GetGlobalId() string {
+	return fmt.Sprintf("%s/%s", CLOUD_PROVIDER_GOOGLE, self.Name)
+}
+
+func (self *SRegion) GetStatus() string {
+	if self.Status == "UP" {
+		return models.CLOUD_REGION_STATUS_INSERVER
+	}
+	return models.CLOUD_REGION_STATUS_OUTOFSERVICE
+}
+
+func (self *SRegion) Refresh() error {
+	return nil
+}
+
+func (self *SRegion) IsEmulated() bool {
+	return false
+}
+
+func (self *SRegion) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SRegion) GetGeographicInfo() cloudprovider.SGeographicInfo {
+	if info, ok := LatitudeAndLongitude[self.Name]; ok {
+		return info
+	}
+	return cloudprovider.SGeographicInfo{}
+}
+
+func (self *SRegion) GetProvider() string {
+	return CLOUD_PROVIDER_GOOGLE
+}
+
+// 谷歌云的网络是全局资源, 子网属于地域, 每个地域为每个网络生成一个VPC及地域级的wire
+func (self *SRegion) fetchInfrastructure() error {
+	networks, err := self.GetGlobalNetworks()
+	if err != nil {
+		return err
+	}
+	subnets, err := self.GetNetworks("")
+	if err != nil {
+		return err
+	}
+	for j := 0; j < len(self.izones); j += 1 {
+		self.izones[j].(*SZone).iwires = nil
+	}
+	self.ivpcs = make([]cloudprovider.ICloudVpc, 0)
+	for i := range networks {
+		vpc := &SVpc{region: self, network: &networks[i]}
+		wire := &SWire{region: self, vpc: vpc}
+		vpc.addWire(wire)
+		for j := range subnets {
+			if subnets[j].Network == networks[i].SelfLink {
+				subnets[j].wire = wire
+				wire.addNetwork(&subnets[j])
+			}
+		}
+		// 没有该地域子网的网络同样同步, 便于在其中创建子网
+		self.ivpcs = append(self.ivpcs, vpc)
+		for j := 0; j < len(self.izones); j += 1 {
+			zone := self.izones[j].(*SZone)
+			zone.addWire(wire)
+		}
+	}
+	return nil
+}
+
+func (self *SRegion) GetIZones() ([]cloudprovider.ICloudZone, error) {
+	return self.izones, nil
+}
+
+func (self *SRegion) GetIVpcs() ([]cloudprovider.ICloudVpc, error) {
+	if self.ivpcs == nil {
+		err := self.fetchInfrastructure()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return self.ivpcs, nil
+}
+
+func (self *SRegion) GetIVpcById(id string) (cloudprovider.ICloudVpc, error) {
+	ivpcs, err := self.GetIVpcs()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(ivpcs); i += 1 {
+		if ivpcs[i].GetGlobalId() == id {
+			return ivpcs[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) GetIZoneById(id string) (cloudprovider.ICloudZone, error) {
+	for i := 0; i < len(self.izones); i += 1 {
+		if self.izones[i].GetGlobalId() == id {
+			return self.izones[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) getZoneById(id string) (*SZone, error) {
+	for i := 0; i < len(self.izones); i += 1 {
+		zone := self.izones[i].(*SZone)
+		if zone.GetId() == id {
+			return zone, nil
+		}
+	}
+	return nil, fmt.Errorf("no such zone %s", id)
+}
+
+// 弹性公网IP暂不支持
+func (self *SRegion) GetIEips() ([]cloudprovider.ICloudEIP, error) {
+	return []cloudprovider.ICloudEIP{}, nil
+}
+
+func (self *SRegion) GetIEipById(id string) (cloudprovider.ICloudEIP, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) CreateEIP(name string, bwMbps int, chargeType string, bgpType string) (cloudprovider.ICloudEIP, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) CreateIVpc(name string, desc string, cidr string) (cloudprovider.ICloudVpc, error) {
+	networkId, err := self.CreateGlobalNetwork(name, desc)
+	if err != nil {
+		return nil, err
+	}
+	network, err := self.GetGlobalNetwork(networkId)
+	if err != nil {
+		return nil, err
+	}
+	vpc := &SVpc{region: self, network: network}
+	vpc.addWire(&SWire{region: self, vpc: vpc})
+	self.ivpcs = nil
+	return vpc, nil
+}
+
+func (self *SRegion) GetIHosts() ([]cloudprovider.ICloudHost, error) {
+	iHosts := make([]cloudprovider.ICloudHost, 0)
+	for i := 0; i < len(self.izones); i += 1 {
+		iZoneHost, err := self.izones[i].GetIHosts()
+		if err != nil {
+			return nil, err
+		}
+		iHosts = append(iHosts, iZoneHost...)
+	}
+	return iHosts, nil
+}
+
+func (self *SRegion) GetIHostById(id string) (cloudprovider.ICloudHost, error) {
+	for i := 0; i < len(self.izones); i += 1 {
+		ihost, err := self.izones[i].GetIHostById(id)
+		if err == nil {
+			return ihost, nil
+		} else if err != cloudprovider.ErrNotFound {
+			return nil, err
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) GetIStorages() ([]cloudprovider.ICloudStorage, error) {
+	iStores := make([]cloudprovider.ICloudStorage, 0)
+	for i := 0; i < len(self.izones); i += 1 {
+		iZoneStores, err := self.izones[i].GetIStorages()
+		if err != nil {
+			return nil, err
+		}
+		iStores = append(iStores, iZoneStores...)
+	}
+	return iStores, nil
+}
+
+func (self *SRegion) GetIStorageById(id string) (cloudprovider.ICloudStorage, error) {
+	for i := 0; i < len(self.izones); i += 1 {
+		istore, err := self.izones[i].GetIStorageById(id)
+		if err == nil {
+			return istore, nil
+		} else if err != cloudprovider.ErrNotFound {
+			return nil, err
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+// 快照是全局资源, 按源磁盘所在可用区划分地域
+func (self *SRegion) GetISnapshots() ([]cloudprovider.ICloudSnapshot, error) {
+	snapshots, err := self.GetSnapshots("")
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]cloudprovider.ICloudSnapshot, len(snapshots))
+	for i := 0; i < len(snapshots); i += 1 {
+		ret[i] = &snapshots[i]
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetISnapshotById(snapshotId string) (cloudprovider.ICloudSnapshot, error) {
+	snapshot, err := self.GetSnapshot(snapshotId)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// 防火墙规则属于网络, 通过网络标记作用于虚拟机, 安全组即为网络标记
+func (self *SRegion) DeleteSecurityGroup(vpcId, secgroupId string) error {
+	firewalls, err := self.GetFirewalls(vpcNetworkId(vpcId), secgroupId)
+	if err != nil {
+		return err
+	}
+	for i := range firewalls {
+		if err := self.client.delete(firewalls[i].GetGlobalId()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *SRegion) SyncSecurityGroup(secgroupId string, vpcId string, name string, desc string, rules []secrules.SecurityRule) (string, error) {
+	if len(secgroupId) == 0 {
+		secgroupId = generateSecurityGroupTag(name)
+	}
+	return secgroupId, self.syncFirewalls(vpcNetworkId(vpcId), secgroupId, rules)
+}
+
+func (self *SRegion) GetILoadBalancers() ([]cloudprovider.ICloudLoadbalancer, error) {
+	return []cloudprovider.ICloudLoadbalancer{}, nil
+}
+
+func (self *SRegion) GetILoadBalancerAcls() ([]cloudprovider.ICloudLoadbalancerAcl, error) {
+	return []cloudprovider.ICloudLoadbalancerAcl{}, nil
+}
+
+func (self *SRegion) GetILoadBalancerCertificates() ([]cloudprovider.ICloudLoadbalancerCertificate, error) {
+	return []cloudprovider.ICloudLoadbalancerCertificate{}, nil
+}
+
+func (self *SRegion) GetILoadBalancerById(loadbalancerId string) (cloudprovider.ICloudLoadbalancer, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) GetILoadBalancerAclById(aclId string) (cloudprovider.ICloudLoadbalancerAcl, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) GetILoadBalancerCertificateById(certId string) (cloudprovider.ICloudLoadbalancerCertificate, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) CreateILoadBalancer(loadbalancer *cloudprovider.SLoadbalancer) (cloudprovider.ICloudLoadbalancer, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) CreateILoadBalancerAcl(acl *cloudprovider.SLoadbalancerAccessControlList) (cloudprovider.ICloudLoadbalancerAcl, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) CreateILoadBalancerCertificate(cert *cloudprovider.SLoadbalancerCertificate) (cloudprovider.ICloudLoadbalancerCertificate, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) GetSkus(zoneId string) ([]cloudprovider.ICloudSku, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (self *SRegion) isZoneOfRegion(zoneLink string) bool {
+	zoneName := getResourceName(zoneLink)
+	for i := 0; i < len(self.izones); i += 1 {
+		if self.izones[i].GetId() == zoneName {
+			return true
+		}
+	}
+	return false
+}
+
+// 磁盘等可用区资源的selfLink中包含 zones/<zone>/
+func zoneOfResource(selfLink string) string {
+	segs := strings.Split(getGlobalId(selfLink), "/")
+	for i := 0; i < len(segs)-1; i += 1 {
+		if segs[i] == "zones" {
+			return segs[i+1]
+		}
+	}
+	return ""
+}
+
+func (self *SRegion) GetIBuckets() ([]cloudprovider.ICloudBucket, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (self *SRegion) GetIBucketById(name string) (cloudprovider.ICloudBucket, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (self *SRegion) CreateIBucket(name string, storageClassStr string, acl string) error {
+	return cloudprovider.ErrNotImplemented
+}
+
+func (self *SRegion) DeleteIBucket(name string) error {
+	return cloudprovider.ErrNotImplemented
+}
+
+func (self *SRegion) GetIDBInstances() ([]cloudprovider.ICloudDBInstance, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (self *SRegion) GetIDBInstanceById(instanceId string) (cloudprovider.ICloudDBInstance, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
@@ -0,0 +1,62 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/util/google"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+func init() {
+	type DiskListOptions struct {
+		ZONE        string `help:"Zone ID"`
+		StorageType string `help:"Storage type" choices:"pd-standard|pd-ssd"`
+	}
+	shellutils.R(&DiskListOptions{}, "disk-list", "List disks", func(cli *google.SRegion, args *DiskListOptions) error {
+		disks, err := cli.GetDisks(args.ZONE, args.StorageType)
+		if err != nil {
+			return err
+		}
+		printList(disks, 0, 0, 0, nil)
+		return nil
+	})
+
+	type DiskShowOptions struct {
+		ID string `help:"Disk ID"`
+	}
+	shellutils.R(&DiskShowOptions{}, "disk-show", "Show disk", func(cli *google.SRegion, args *DiskShowOptions) error {
+		disk, err := cli.GetDisk(args.ID)
+		if err != nil {
+			return err
+		}
+		printObject(disk)
+		return nil
+	})
+
+	type DiskCreateOptions struct {
+		ZONE        string `help:"Zone ID"`
+		NAME        string `help:"Disk name"`
+		SIZE        int    `help:"Disk size in GB"`
+		StorageType string `help:"Storage type" default:"pd-standard" choices:"pd-standard|pd-ssd"`
+		Image       string `help:"Source image ID"`
+		Desc        string `help:"Disk description"`
+	}
+	shellutils.R(&DiskCreateOptions{}, "disk-create", "Create disk", func(cli *google.SRegion, args *DiskCreateOptions) error {
+		id, err := cli.CreateDisk(args.ZONE, args.StorageType, args.NAME, args.SIZE, args.Image, args.Desc)
+		if err != nil {
+			return err
+		}
+		disk, err := cli.GetDisk(id)
+		if err != nil {
+			return err
+		}
+		printObject(disk)
+		return nil
+	})
+
+	type DiskResizeOptions struct {
+		ID   string `help:"Disk ID"`
+		SIZE int    `help:"New disk size in GB"`
+	}
+	shellutils.R(&DiskResizeOptions{}, "disk-resize", "Resize disk", func(cli *google.SRegion, args *DiskResizeOptions) error {
+		return cli.ResizeDisk(args.ID, args.SIZE)
+	})
+}
@@ -0,0 +1 @@
+package shell // import "yunion.io/x/onecloud/pkg/util/google/shell"
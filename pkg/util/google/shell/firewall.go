@@ -0,0 +1,21 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/util/google"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+func init() {
+	type FirewallListOptions struct {
+		Network string `help:"Global network ID"`
+		Tag     string `help:"Target network tag"`
+	}
+	shellutils.R(&FirewallListOptions{}, "firewall-list", "List firewalls", func(cli *google.SRegion, args *FirewallListOptions) error {
+		firewalls, err := cli.GetFirewalls(args.Network, args.Tag)
+		if err != nil {
+			return err
+		}
+		printList(firewalls, 0, 0, 0, nil)
+		return nil
+	})
+}
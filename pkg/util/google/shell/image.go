@@ -0,0 +1,32 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/util/google"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+func init() {
+	type ImageListOptions struct {
+		Project string `help:"Image project, e.g. debian-cloud, default is own project"`
+	}
+	shellutils.R(&ImageListOptions{}, "image-list", "List images", func(cli *google.SRegion, args *ImageListOptions) error {
+		images, err := cli.GetImages(args.Project)
+		if err != nil {
+			return err
+		}
+		printList(images, 0, 0, 0, nil)
+		return nil
+	})
+
+	type ImageShowOptions struct {
+		ID string `help:"Image ID"`
+	}
+	shellutils.R(&ImageShowOptions{}, "image-show", "Show image", func(cli *google.SRegion, args *ImageShowOptions) error {
+		image, err := cli.GetImage(args.ID)
+		if err != nil {
+			return err
+		}
+		printObject(image)
+		return nil
+	})
+}
@@ -0,0 +1,53 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/util/google"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+func init() {
+	type InstanceListOptions struct {
+		ZONE string `help:"Zone ID"`
+	}
+	shellutils.R(&InstanceListOptions{}, "instance-list", "List instances", func(cli *google.SRegion, args *InstanceListOptions) error {
+		instances, err := cli.GetInstances(args.ZONE)
+		if err != nil {
+			return err
+		}
+		printList(instances, 0, 0, 0, nil)
+		return nil
+	})
+
+	type InstanceShowOptions struct {
+		ID string `help:"Instance ID"`
+	}
+	shellutils.R(&InstanceShowOptions{}, "instance-show", "Show instance", func(cli *google.SRegion, args *InstanceShowOptions) error {
+		instance, err := cli.GetInstance(args.ID)
+		if err != nil {
+			return err
+		}
+		printObject(instance)
+		return nil
+	})
+
+	type InstanceSetTagsOptions struct {
+		ID          string   `help:"Instance ID"`
+		FINGERPRINT string   `help:"Current tags fingerprint"`
+		Tag         []string `help:"Network tags"`
+	}
+	shellutils.R(&InstanceSetTagsOptions{}, "instance-set-tags", "Set network tags of instance", func(cli *google.SRegion, args *InstanceSetTagsOptions) error {
+		return cli.SetInstanceTags(args.ID, args.FINGERPRINT, args.Tag)
+	})
+
+	type MachineTypeShowOptions struct {
+		ID string `help:"Machine type ID, e.g. zones/asia-east1-a/machineTypes/n1-standard-1"`
+	}
+	shellutils.R(&MachineTypeShowOptions{}, "machine-type-show", "Show machine type", func(cli *google.SRegion, args *MachineTypeShowOptions) error {
+		machineType, err := cli.GetMachineType(args.ID)
+		if err != nil {
+			return err
+		}
+		printObject(machineType)
+		return nil
+	})
+}
@@ -0,0 +1,60 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/util/google"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+func init() {
+	type GlobalNetworkListOptions struct {
+	}
+	shellutils.R(&GlobalNetworkListOptions{}, "global-network-list", "List global networks", func(cli *google.SRegion, args *GlobalNetworkListOptions) error {
+		networks, err := cli.GetGlobalNetworks()
+		if err != nil {
+			return err
+		}
+		printList(networks, 0, 0, 0, nil)
+		return nil
+	})
+
+	type GlobalNetworkCreateOptions struct {
+		NAME string `help:"Network name"`
+		Desc string `help:"Network description"`
+	}
+	shellutils.R(&GlobalNetworkCreateOptions{}, "global-network-create", "Create global network", func(cli *google.SRegion, args *GlobalNetworkCreateOptions) error {
+		id, err := cli.CreateGlobalNetwork(args.NAME, args.Desc)
+		if err != nil {
+			return err
+		}
+		network, err := cli.GetGlobalNetwork(id)
+		if err != nil {
+			return err
+		}
+		printObject(network)
+		return nil
+	})
+
+	type NetworkListOptions struct {
+		Network string `help:"Global network ID"`
+	}
+	shellutils.R(&NetworkListOptions{}, "network-list", "List subnetworks of region", func(cli *google.SRegion, args *NetworkListOptions) error {
+		networks, err := cli.GetNetworks(args.Network)
+		if err != nil {
+			return err
+		}
+		printList(networks, 0, 0, 0, nil)
+		return nil
+	})
+
+	type NetworkShowOptions struct {
+		ID string `help:"Subnetwork ID"`
+	}
+	shellutils.R(&NetworkShowOptions{}, "network-show", "Show subnetwork", func(cli *google.SRegion, args *NetworkShowOptions) error {
+		network, err := cli.GetNetwork(args.ID)
+		if err != nil {
+			return err
+		}
+		printObject(network)
+		return nil
+	})
+}
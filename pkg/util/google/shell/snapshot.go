@@ -0,0 +1,38 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/util/google"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+func init() {
+	type SnapshotListOptions struct {
+		Disk string `help:"Source disk ID"`
+	}
+	shellutils.R(&SnapshotListOptions{}, "snapshot-list", "List snapshots of region", func(cli *google.SRegion, args *SnapshotListOptions) error {
+		snapshots, err := cli.GetSnapshots(args.Disk)
+		if err != nil {
+			return err
+		}
+		printList(snapshots, 0, 0, 0, nil)
+		return nil
+	})
+
+	type SnapshotCreateOptions struct {
+		DISK string `help:"Source disk ID"`
+		NAME string `help:"Snapshot name"`
+		Desc string `help:"Snapshot description"`
+	}
+	shellutils.R(&SnapshotCreateOptions{}, "snapshot-create", "Create snapshot", func(cli *google.SRegion, args *SnapshotCreateOptions) error {
+		id, err := cli.CreateSnapshot(args.DISK, args.NAME, args.Desc)
+		if err != nil {
+			return err
+		}
+		snapshot, err := cli.GetSnapshot(id)
+		if err != nil {
+			return err
+		}
+		printObject(snapshot)
+		return nil
+	})
+}
@@ -0,0 +1,19 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/util/google"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+func init() {
+	type ZoneListOptions struct {
+	}
+	shellutils.R(&ZoneListOptions{}, "zone-list", "List zones", func(cli *google.SRegion, args *ZoneListOptions) error {
+		zones, err := cli.GetIZones()
+		if err != nil {
+			return err
+		}
+		printList(zones, 0, 0, 0, nil)
+		return nil
+	})
+}
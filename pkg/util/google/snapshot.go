@@ -0,0 +1,120 @@
+package google
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/snapshots
+type SSnapshot struct {
+	region *SRegion
+
+	SResourceBase
+
+	Status       string
+	SourceDisk   string
+	DiskSizeGb   int32
+	StorageBytes int64
+	Licenses     []string
+}
+
+func (self *SSnapshot) GetStatus() string {
+	switch self.Status {
+	case "READY":
+		return models.SNAPSHOT_READY
+	case "CREATING", "UPLOADING":
+		return models.SNAPSHOT_CREATING
+	case "DELETING":
+		return models.SNAPSHOT_DELETING
+	case "FAILED":
+		return models.SNAPSHOT_FAILED
+	default:
+		return models.SNAPSHOT_UNKNOWN
+	}
+}
+
+func (self *SSnapshot) Refresh() error {
+	snapshot, err := self.region.GetSnapshot(self.GetGlobalId())
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, snapshot)
+}
+
+func (self *SSnapshot) IsEmulated() bool {
+	return false
+}
+
+func (self *SSnapshot) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SSnapshot) GetProjectId() string {
+	return ""
+}
+
+func (self *SSnapshot) GetSize() int32 {
+	return self.DiskSizeGb
+}
+
+func (self *SSnapshot) GetDiskId() string {
+	return getGlobalId(self.SourceDisk)
+}
+
+// 系统盘的快照会继承镜像的许可证
+func (self *SSnapshot) GetDiskType() string {
+	if len(self.Licenses) > 0 {
+		return models.DISK_TYPE_SYS
+	}
+	return models.DISK_TYPE_DATA
+}
+
+func (self *SSnapshot) Delete() error {
+	return self.region.client.delete(self.GetGlobalId())
+}
+
+func (self *SRegion) GetSnapshot(id string) (*SSnapshot, error) {
+	snapshot := SSnapshot{}
+	err := self.client.get(id, &snapshot)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.region = self
+	return &snapshot, nil
+}
+
+// 快照是全局资源, 仅返回源磁盘位于本地域的快照
+func (self *SRegion) GetSnapshots(diskId string) ([]SSnapshot, error) {
+	params := map[string]string{}
+	if len(diskId) > 0 {
+		params["filter"] = fmt.Sprintf(`sourceDisk="%s%s"`, GOOGLE_COMPUTE_DOMAIN, getGlobalId(diskId))
+	}
+	snapshots := []SSnapshot{}
+	err := self.client.listAll("global/snapshots", params, &snapshots)
+	if err != nil {
+		return nil, err
+	}
+	ret := []SSnapshot{}
+	for i := range snapshots {
+		if self.isZoneOfRegion(zoneOfResource(snapshots[i].SourceDisk)) {
+			snapshots[i].region = self
+			ret = append(ret, snapshots[i])
+		}
+	}
+	return ret, nil
+}
+
+// createSnapshot操作的目标是磁盘, 快照ID需要按名称拼接
+func (self *SRegion) CreateSnapshot(diskId string, name string, desc string) (string, error) {
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.NewString(name), "name")
+	body.Add(jsonutils.NewString(desc), "description")
+	_, err := self.client.action(diskId, "createSnapshot", nil, body)
+	if err != nil {
+		return "", err
+	}
+	return self.client.projectResource("", fmt.Sprintf("global/snapshots/%s", name)), nil
+}
@@ -0,0 +1,123 @@
+package google
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/diskTypes
+type SStorage struct {
+	zone        *SZone
+	storageType string // pd-standard 或 pd-ssd
+}
+
+func (self *SStorage) GetId() string {
+	return fmt.Sprintf("%s-%s-%s", self.zone.region.client.providerId, self.zone.GetId(), self.storageType)
+}
+
+func (self *SStorage) GetName() string {
+	return fmt.Sprintf("%s-%s-%s", self.zone.region.client.providerName, self.zone.GetId(), self.storageType)
+}
+
+func (self *SStorage) GetGlobalId() string {
+	return fmt.Sprintf("%s-%s-%s", self.zone.region.client.providerId, self.zone.GetGlobalId(), self.storageType)
+}
+
+func (self *SStorage) GetStatus() string {
+	return models.STORAGE_ONLINE
+}
+
+func (self *SStorage) Refresh() error {
+	return nil
+}
+
+func (self *SStorage) IsEmulated() bool {
+	return true
+}
+
+func (self *SStorage) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SStorage) GetIStoragecache() cloudprovider.ICloudStoragecache {
+	return self.zone.region.getStoragecache()
+}
+
+func (self *SStorage) GetIZone() cloudprovider.ICloudZone {
+	return self.zone
+}
+
+func (self *SStorage) GetIDisks() ([]cloudprovider.ICloudDisk, error) {
+	disks, err := self.zone.region.GetDisks(self.zone.GetId(), self.storageType)
+	if err != nil {
+		return nil, err
+	}
+	idisks := make([]cloudprovider.ICloudDisk, len(disks))
+	for i := 0; i < len(disks); i += 1 {
+		disks[i].storage = self
+		idisks[i] = &disks[i]
+	}
+	return idisks, nil
+}
+
+func (self *SStorage) GetStorageType() string {
+	return self.storageType
+}
+
+func (self *SStorage) GetMediumType() string {
+	if self.storageType == models.STORAGE_GOOGLE_PD_SSD {
+		return models.DISK_TYPE_SSD
+	}
+	return models.DISK_TYPE_ROTATE
+}
+
+func (self *SStorage) GetCapacityMB() int {
+	return 0 // unlimited
+}
+
+func (self *SStorage) GetStorageConf() jsonutils.JSONObject {
+	conf := jsonutils.NewDict()
+	return conf
+}
+
+func (self *SStorage) GetEnabled() bool {
+	return true
+}
+
+func (self *SStorage) GetManagerId() string {
+	return self.zone.region.client.providerId
+}
+
+func (self *SStorage) CreateIDisk(name string, sizeGb int, desc string) (cloudprovider.ICloudDisk, error) {
+	diskId, err := self.zone.region.CreateDisk(self.zone.GetId(), self.storageType, name, sizeGb, "", desc)
+	if err != nil {
+		return nil, err
+	}
+	disk, err := self.zone.region.GetDisk(diskId)
+	if err != nil {
+		return nil, err
+	}
+	disk.storage = self
+	return disk, nil
+}
+
+func (self *SStorage) GetIDiskById(idStr string) (cloudprovider.ICloudDisk, error) {
+	disk, err := self.zone.region.GetDisk(idStr)
+	if err != nil {
+		return nil, err
+	}
+	disk.storage = self
+	return disk, nil
+}
+
+func (self *SStorage) GetMountPoint() string {
+	return ""
+}
+
+func (self *SStorage) IsSysDiskStore() bool {
+	return true
+}
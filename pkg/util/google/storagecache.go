@@ -0,0 +1,147 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+	"yunion.io/x/onecloud/pkg/mcclient"
+)
+
+// 谷歌云的镜像是全局资源, 每个地域模拟一个镜像缓存
+type SStoragecache struct {
+	region *SRegion
+
+	iimages []cloudprovider.ICloudImage
+}
+
+func (self *SStoragecache) fetchImages() error {
+	images := []SImage{}
+	for _, project := range append(PublicImageProjects, "") {
+		_images, err := self.region.GetImages(project)
+		if err != nil {
+			return err
+		}
+		images = append(images, _images...)
+	}
+	self.iimages = make([]cloudprovider.ICloudImage, len(images))
+	for i := range images {
+		images[i].storageCache = self
+		self.iimages[i] = &images[i]
+	}
+	return nil
+}
+
+func (self *SStoragecache) GetId() string {
+	return fmt.Sprintf("%s-%s", self.region.client.providerId, self.region.GetId())
+}
+
+func (self *SStoragecache) GetName() string {
+	return fmt.Sprintf("%s-%s", self.region.client.providerName, self.region.GetId())
+}
+
+func (self *SStoragecache) GetGlobalId() string {
+	return fmt.Sprintf("%s-%s", self.region.client.providerId, self.region.GetGlobalId())
+}
+
+func (self *SStoragecache) GetStatus() string {
+	return "available"
+}
+
+func (self *SStoragecache) Refresh() error {
+	return nil
+}
+
+func (self *SStoragecache) IsEmulated() bool {
+	return false
+}
+
+func (self *SStoragecache) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SStoragecache) GetIImages() ([]cloudprovider.ICloudImage, error) {
+	if self.iimages == nil {
+		err := self.fetchImages()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return self.iimages, nil
+}
+
+func (self *SStoragecache) GetIImageById(extId string) (cloudprovider.ICloudImage, error) {
+	image, err := self.region.GetImage(extId)
+	if err != nil {
+		return nil, err
+	}
+	image.storageCache = self
+	return image, nil
+}
+
+func (self *SStoragecache) GetPath() string {
+	return ""
+}
+
+func (self *SStoragecache) GetManagerId() string {
+	return self.region.client.providerId
+}
+
+func (self *SStoragecache) CreateIImage(snapshotId, imageName, osType, imageDesc string) (cloudprovider.ICloudImage, error) {
+	imageId, err := self.region.createImage(snapshotId, imageName, imageDesc)
+	if err != nil {
+		return nil, err
+	}
+	image, err := self.region.GetImage(imageId)
+	if err != nil {
+		return nil, err
+	}
+	image.storageCache = self
+	err = cloudprovider.WaitStatus(image, models.CACHED_IMAGE_STATUS_READY, 15*time.Second, 3600*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+func (self *SStoragecache) DownloadImage(userCred mcclient.TokenCredential, imageId string, extId string, path string) (jsonutils.JSONObject, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+// 上传镜像需要先将镜像文件上传至对象存储, 暂不支持
+func (self *SStoragecache) UploadImage(ctx context.Context, userCred mcclient.TokenCredential, imageId string, osArch, osType, osDist, osVersion string, extId string, isForce bool) (string, error) {
+	if len(extId) > 0 {
+		image, err := self.region.GetImage(extId)
+		if err != nil {
+			log.Errorf("GetImage %s error %s", extId, err)
+		} else if image.GetImageStatus() == cloudprovider.IMAGE_STATUS_ACTIVE && !isForce {
+			return extId, nil
+		}
+	}
+	return "", cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) getStoragecache() *SStoragecache {
+	if self.storageCache == nil {
+		self.storageCache = &SStoragecache{region: self}
+	}
+	return self.storageCache
+}
+
+func (self *SRegion) GetIStoragecaches() ([]cloudprovider.ICloudStoragecache, error) {
+	storageCache := self.getStoragecache()
+	return []cloudprovider.ICloudStoragecache{storageCache}, nil
+}
+
+func (self *SRegion) GetIStoragecacheById(idstr string) (cloudprovider.ICloudStoragecache, error) {
+	storageCache := self.getStoragecache()
+	if storageCache.GetGlobalId() == idstr {
+		return storageCache, nil
+	}
+	return nil, cloudprovider.ErrNotFound
+}
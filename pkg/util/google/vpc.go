@@ -0,0 +1,206 @@
+package google
+
+import (
+	"fmt"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/networks
+type SGlobalNetwork struct {
+	SResourceBase
+
+	IPv4Range             string
+	GatewayIPv4           string
+	AutoCreateSubnetworks bool
+	Subnetworks           []string
+}
+
+// 谷歌云的网络是全局的, 每个地域对应一个VPC
+type SVpc struct {
+	region  *SRegion
+	network *SGlobalNetwork
+
+	iwires    []cloudprovider.ICloudWire
+	secgroups []cloudprovider.ICloudSecurityGroup
+}
+
+func (self *SVpc) addWire(wire *SWire) {
+	if self.iwires == nil {
+		self.iwires = make([]cloudprovider.ICloudWire, 0)
+	}
+	self.iwires = append(self.iwires, wire)
+}
+
+// 安全组即网络标记, 从该网络的防火墙规则中收集
+func (self *SVpc) fetchSecurityGroups() error {
+	firewalls, err := self.region.GetFirewalls(self.network.GetGlobalId(), "")
+	if err != nil {
+		return err
+	}
+	tags := []string{}
+	for i := range firewalls {
+		for _, tag := range firewalls[i].TargetTags {
+			if !isSecurityGroupTag(tag) {
+				continue
+			}
+			find := false
+			for _, _tag := range tags {
+				if _tag == tag {
+					find = true
+					break
+				}
+			}
+			if !find {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	self.secgroups = make([]cloudprovider.ICloudSecurityGroup, len(tags))
+	for i, tag := range tags {
+		self.secgroups[i] = &SSecurityGroup{vpc: self, Tag: tag}
+	}
+	return nil
+}
+
+func (self *SVpc) GetId() string {
+	return self.network.Name
+}
+
+func (self *SVpc) GetName() string {
+	return self.network.Name
+}
+
+func (self *SVpc) GetGlobalId() string {
+	return fmt.Sprintf("%s/%s", self.region.GetId(), self.network.GetGlobalId())
+}
+
+func (self *SVpc) GetStatus() string {
+	return models.VPC_STATUS_AVAILABLE
+}
+
+func (self *SVpc) Refresh() error {
+	network, err := self.region.GetGlobalNetwork(self.network.GetGlobalId())
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self.network, network)
+}
+
+func (self *SVpc) IsEmulated() bool {
+	return false
+}
+
+func (self *SVpc) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SVpc) GetRegion() cloudprovider.ICloudRegion {
+	return self.region
+}
+
+func (self *SVpc) GetIsDefault() bool {
+	return self.network.Name == "default"
+}
+
+// 子网模式的网络没有统一的网段
+func (self *SVpc) GetCidrBlock() string {
+	return self.network.IPv4Range
+}
+
+func (self *SVpc) GetIWires() ([]cloudprovider.ICloudWire, error) {
+	return self.iwires, nil
+}
+
+func (self *SVpc) GetISecurityGroups() ([]cloudprovider.ICloudSecurityGroup, error) {
+	if self.secgroups == nil {
+		err := self.fetchSecurityGroups()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return self.secgroups, nil
+}
+
+func (self *SVpc) GetIRouteTables() ([]cloudprovider.ICloudRouteTable, error) {
+	rts := []cloudprovider.ICloudRouteTable{}
+	return rts, nil
+}
+
+func (self *SVpc) GetINatGateways() ([]cloudprovider.ICloudNatGateway, error) {
+	return []cloudprovider.ICloudNatGateway{}, nil
+}
+
+func (self *SVpc) CreateINatGateway(opts *cloudprovider.SNatGatewayCreateOptions) (cloudprovider.ICloudNatGateway, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (self *SVpc) GetIVpcPeerings() ([]cloudprovider.ICloudVpcPeering, error) {
+	return []cloudprovider.ICloudVpcPeering{}, nil
+}
+
+func (self *SVpc) GetIVpcPeeringById(id string) (cloudprovider.ICloudVpcPeering, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SVpc) CreateIVpcPeering(opts *cloudprovider.SVpcPeeringCreateOptions) (cloudprovider.ICloudVpcPeering, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (self *SVpc) GetManagerId() string {
+	return self.region.client.providerId
+}
+
+// 网络是全局的, 删除任一地域的VPC都会删除整个网络
+func (self *SVpc) Delete() error {
+	return self.region.client.delete(self.network.GetGlobalId())
+}
+
+func (self *SVpc) GetIWireById(wireId string) (cloudprovider.ICloudWire, error) {
+	for i := 0; i < len(self.iwires); i += 1 {
+		if self.iwires[i].GetGlobalId() == wireId {
+			return self.iwires[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) GetGlobalNetworks() ([]SGlobalNetwork, error) {
+	networks := []SGlobalNetwork{}
+	err := self.client.listAll("global/networks", nil, &networks)
+	if err != nil {
+		return nil, err
+	}
+	return networks, nil
+}
+
+func (self *SRegion) GetGlobalNetwork(id string) (*SGlobalNetwork, error) {
+	network := SGlobalNetwork{}
+	err := self.client.get(id, &network)
+	if err != nil {
+		return nil, err
+	}
+	return &network, nil
+}
+
+// 以子网模式创建网络, 子网通过wire在各地域创建
+func (self *SRegion) CreateGlobalNetwork(name string, desc string) (string, error) {
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.NewString(name), "name")
+	body.Add(jsonutils.NewString(desc), "description")
+	body.Add(jsonutils.JSONFalse, "autoCreateSubnetworks")
+	return self.client.insert("global/networks", body)
+}
+
+// VPC的全局ID格式为 <region>/<network>, 去掉地域前缀得到网络的ID
+func vpcNetworkId(vpcId string) string {
+	segs := strings.SplitN(vpcId, "/", 2)
+	if len(segs) == 2 {
+		return segs[1]
+	}
+	return vpcId
+}
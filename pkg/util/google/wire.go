@@ -0,0 +1,116 @@
+package google
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+// 谷歌云的子网属于地域, 每个VPC在地域内只有一个wire
+type SWire struct {
+	region *SRegion
+	vpc    *SVpc
+
+	inetworks []cloudprovider.ICloudNetwork
+}
+
+func (self *SWire) GetId() string {
+	return fmt.Sprintf("%s-%s", self.vpc.GetId(), self.region.GetId())
+}
+
+func (self *SWire) GetName() string {
+	return self.GetId()
+}
+
+func (self *SWire) GetGlobalId() string {
+	return fmt.Sprintf("%s-%s", self.vpc.GetGlobalId(), self.region.GetId())
+}
+
+func (self *SWire) GetStatus() string {
+	return "available"
+}
+
+func (self *SWire) Refresh() error {
+	return nil
+}
+
+func (self *SWire) IsEmulated() bool {
+	return true
+}
+
+func (self *SWire) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SWire) GetIVpc() cloudprovider.ICloudVpc {
+	return self.vpc
+}
+
+func (self *SWire) GetIZone() cloudprovider.ICloudZone {
+	return nil
+}
+
+func (self *SWire) GetINetworks() ([]cloudprovider.ICloudNetwork, error) {
+	if self.inetworks == nil {
+		return []cloudprovider.ICloudNetwork{}, nil
+	}
+	return self.inetworks, nil
+}
+
+func (self *SWire) GetBandwidth() int {
+	return 10000
+}
+
+func (self *SWire) GetINetworkById(netid string) (cloudprovider.ICloudNetwork, error) {
+	networks, err := self.GetINetworks()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(networks); i += 1 {
+		if networks[i].GetGlobalId() == netid {
+			return networks[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SWire) CreateINetwork(name string, cidr string, desc string) (cloudprovider.ICloudNetwork, error) {
+	networkId, err := self.region.createNetwork(self.vpc.network.SelfLink, name, cidr, desc)
+	if err != nil {
+		return nil, err
+	}
+	network, err := self.region.GetNetwork(networkId)
+	if err != nil {
+		return nil, err
+	}
+	network.wire = self
+	self.addNetwork(network)
+	return network, nil
+}
+
+func (self *SWire) addNetwork(network *SNetwork) {
+	if self.inetworks == nil {
+		self.inetworks = make([]cloudprovider.ICloudNetwork, 0)
+	}
+	for i := 0; i < len(self.inetworks); i += 1 {
+		if self.inetworks[i].GetGlobalId() == network.GetGlobalId() {
+			return
+		}
+	}
+	self.inetworks = append(self.inetworks, network)
+}
+
+func (self *SWire) getNetworkById(networkId string) *SNetwork {
+	networks, err := self.GetINetworks()
+	if err != nil {
+		return nil
+	}
+	for i := 0; i < len(networks); i += 1 {
+		if networks[i].GetGlobalId() == networkId {
+			return networks[i].(*SNetwork)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package google
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+var StorageTypes = []string{
+	models.STORAGE_GOOGLE_PD_STANDARD,
+	models.STORAGE_GOOGLE_PD_SSD,
+}
+
+// https://cloud.google.com/compute/docs/reference/rest/v1/zones
+type SZone struct {
+	region *SRegion
+	host   *SHost
+
+	iwires    []cloudprovider.ICloudWire
+	istorages []cloudprovider.ICloudStorage
+
+	SResourceBase
+
+	Status string
+	Region string
+}
+
+func (self *SZone) addWire(wire *SWire) {
+	if self.iwires == nil {
+		self.iwires = make([]cloudprovider.ICloudWire, 0)
+	}
+	self.iwires = append(self.iwires, wire)
+}
+
+func (self *SZone) fetchStorages() error {
+	self.istorages = make([]cloudprovider.ICloudStorage, len(StorageTypes))
+	for i, storageType := range StorageTypes {
+		storage := SStorage{zone: self, storageType: storageType}
+		self.istorages[i] = &storage
+	}
+	return nil
+}
+
+func (self *SZone) getHost() *SHost {
+	if self.host == nil {
+		self.host = &SHost{zone: self}
+	}
+	return self.host
+}
+
+func (self *SZone) GetId() string {
+	return self.Name
+}
+
+func (self *SZone) GetName() string {
+	return fmt.Sprintf("%s %s", CLOUD_PROVIDER_GOOGLE_CN, self.Name)
+}
+
+func (self *SZone) GetGlobalId() string {
+	return fmt.Sprintf("%s/%s", self.region.GetGlobalId(), self.Name)
+}
+
+func (self *SZone) GetStatus() string {
+	if self.Status == "DOWN" {
+		return models.ZONE_SOLDOUT
+	}
+	return models.ZONE_ENABLE
+}
+
+func (self *SZone) Refresh() error {
+	return nil
+}
+
+func (self *SZone) IsEmulated() bool {
+	return false
+}
+
+func (self *SZone) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SZone) GetIRegion() cloudprovider.ICloudRegion {
+	return self.region
+}
+
+func (self *SZone) GetIHosts() ([]cloudprovider.ICloudHost, error) {
+	return []cloudprovider.ICloudHost{self.getHost()}, nil
+}
+
+func (self *SZone) GetIHostById(id string) (cloudprovider.ICloudHost, error) {
+	host := self.getHost()
+	if host.GetGlobalId() == id {
+		return host, nil
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SZone) GetIStorages() ([]cloudprovider.ICloudStorage, error) {
+	if self.istorages == nil {
+		self.fetchStorages()
+	}
+	return self.istorages, nil
+}
+
+func (self *SZone) GetIStorageById(id string) (cloudprovider.ICloudStorage, error) {
+	if self.istorages == nil {
+		self.fetchStorages()
+	}
+	for i := 0; i < len(self.istorages); i += 1 {
+		if self.istorages[i].GetGlobalId() == id {
+			return self.istorages[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SZone) GetIWires() ([]cloudprovider.ICloudWire, error) {
+	if self.iwires == nil {
+		if _, err := self.region.GetIVpcs(); err != nil {
+			return nil, err
+		}
+	}
+	return self.iwires, nil
+}
+
+func (self *SZone) getStorageByType(storageType string) (*SStorage, error) {
+	storages, err := self.GetIStorages()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(storages); i += 1 {
+		storage := storages[i].(*SStorage)
+		if storage.storageType == storageType {
+			return storage, nil
+		}
+	}
+	return nil, fmt.Errorf("No such storage %s", storageType)
+}
+
+func (self *SZone) getNetworkById(networkId string) *SNetwork {
+	wires, err := self.GetIWires()
+	if err != nil {
+		return nil
+	}
+	for i := 0; i < len(wires); i += 1 {
+		wire := wires[i].(*SWire)
+		net := wire.getNetworkById(networkId)
+		if net != nil {
+			return net
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package huawei
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/util/huawei/obs"
+)
+
+type SBucket struct {
+	region *SRegion
+
+	Name         string
+	Location     string
+	CreationDate time.Time
+
+	acl          string
+	storageClass string
+}
+
+func (b *SBucket) GetId() string {
+	return b.Name
+}
+
+func (b *SBucket) GetName() string {
+	return b.Name
+}
+
+func (b *SBucket) GetGlobalId() string {
+	return b.Name
+}
+
+func (b *SBucket) GetStatus() string {
+	return api.BUCKET_STATUS_READY
+}
+
+func (b *SBucket) Refresh() error {
+	b.acl = ""
+	b.storageClass = ""
+	return nil
+}
+
+func (b *SBucket) IsEmulated() bool {
+	return false
+}
+
+func (b *SBucket) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (b *SBucket) GetLocation() string {
+	return b.Location
+}
+
+func (b *SBucket) GetCreateAt() time.Time {
+	return b.CreationDate
+}
+
+func (b *SBucket) GetStorageClass() string {
+	if len(b.storageClass) == 0 {
+		obscli, err := b.region.getOBSClient()
+		if err != nil {
+			log.Errorf("b.region.getOBSClient fail %s", err)
+			return ""
+		}
+		output, err := obscli.GetBucketStoragePolicy(b.Name)
+		if err != nil {
+			log.Errorf("GetBucketStoragePolicy %s fail %s", b.Name, err)
+			return ""
+		}
+		b.storageClass = output.StorageClass
+	}
+	return b.storageClass
+}
+
+// 根据授权列表推算canned ACL
+func (b *SBucket) GetAcl() cloudprovider.TBucketACLType {
+	if len(b.acl) == 0 {
+		obscli, err := b.region.getOBSClient()
+		if err != nil {
+			log.Errorf("b.region.getOBSClient fail %s", err)
+			return cloudprovider.ACLDefault
+		}
+		output, err := obscli.GetBucketAcl(b.Name)
+		if err != nil {
+			log.Errorf("GetBucketAcl %s fail %s", b.Name, err)
+			return cloudprovider.ACLDefault
+		}
+		acl := cloudprovider.ACLPrivate
+		allRead, allWrite, authRead := false, false, false
+		for _, grant := range output.Grants {
+			uri := string(grant.Grantee.URI)
+			if strings.HasSuffix(uri, string(obs.GroupAllUsers)) {
+				switch grant.Permission {
+				case obs.PermissionRead:
+					allRead = true
+				case obs.PermissionWrite:
+					allWrite = true
+				}
+			} else if strings.HasSuffix(uri, string(obs.GroupAuthenticatedUsers)) {
+				if grant.Permission == obs.PermissionRead {
+					authRead = true
+				}
+			}
+		}
+		if allRead && allWrite {
+			acl = cloudprovider.ACLPublicReadWrite
+		} else if allRead {
+			acl = cloudprovider.ACLPublicRead
+		} else if authRead {
+			acl = cloudprovider.ACLAuthRead
+		}
+		b.acl = string(acl)
+	}
+	return cloudprovider.TBucketACLType(b.acl)
+}
+
+func (b *SBucket) SetAcl(acl cloudprovider.TBucketACLType) error {
+	obscli, err := b.region.getOBSClient()
+	if err != nil {
+		return err
+	}
+	input := &obs.SetBucketAclInput{}
+	input.Bucket = b.Name
+	input.ACL = obs.AclType(acl)
+	_, err = obscli.SetBucketAcl(input)
+	if err != nil {
+		return err
+	}
+	b.acl = string(acl)
+	return nil
+}
+
+func (b *SBucket) ListObjects(prefix string, marker string, delimiter string, maxCount int) (cloudprovider.SListObjectResult, error) {
+	result := cloudprovider.SListObjectResult{}
+	obscli, err := b.region.getOBSClient()
+	if err != nil {
+		return result, err
+	}
+	input := &obs.ListObjectsInput{}
+	input.Bucket = b.Name
+	input.Prefix = prefix
+	input.Marker = marker
+	input.Delimiter = delimiter
+	if maxCount > 0 {
+		input.MaxKeys = maxCount
+	}
+	output, err := obscli.ListObjects(input)
+	if err != nil {
+		return result, err
+	}
+	result.Objects = make([]cloudprovider.SCloudObject, 0)
+	for _, object := range output.Contents {
+		result.Objects = append(result.Objects, cloudprovider.SCloudObject{
+			Key:          object.Key,
+			SizeBytes:    object.Size,
+			StorageClass: string(object.StorageClass),
+			ETag:         object.ETag,
+			LastModified: object.LastModified,
+		})
+	}
+	result.CommonPrefixes = output.CommonPrefixes
+	result.IsTruncated = output.IsTruncated
+	result.NextMarker = output.NextMarker
+	return result, nil
+}
+
+func str2StorageClass(storageClassStr string) (obs.StorageClassType, error) {
+	switch strings.ToUpper(storageClassStr) {
+	case string(obs.StorageClassStandard), "":
+		return obs.StorageClassStandard, nil
+	case string(obs.StorageClassWarm):
+		return obs.StorageClassWarm, nil
+	case string(obs.StorageClassCold):
+		return obs.StorageClassCold, nil
+	default:
+		return "", fmt.Errorf("unsupported storage class %s", storageClassStr)
+	}
+}
+
+// OBS的ListBuckets返回账号下所有区域的bucket, 需按Location过滤
+func (self *SRegion) GetBuckets() ([]SBucket, error) {
+	obscli, err := self.getOBSClient()
+	if err != nil {
+		return nil, err
+	}
+	output, err := obscli.ListBuckets(&obs.ListBucketsInput{QueryLocation: true})
+	if err != nil {
+		return nil, err
+	}
+	buckets := make([]SBucket, 0)
+	for _, bucket := range output.Buckets {
+		if bucket.Location != self.GetId() {
+			continue
+		}
+		buckets = append(buckets, SBucket{
+			region:       self,
+			Name:         bucket.Name,
+			Location:     bucket.Location,
+			CreationDate: bucket.CreationDate,
+		})
+	}
+	return buckets, nil
+}
+
+func (self *SRegion) GetIBuckets() ([]cloudprovider.ICloudBucket, error) {
+	buckets, err := self.GetBuckets()
+	if err != nil {
+		return nil, err
+	}
+	ibuckets := make([]cloudprovider.ICloudBucket, len(buckets))
+	for i := 0; i < len(buckets); i++ {
+		ibuckets[i] = &buckets[i]
+	}
+	return ibuckets, nil
+}
+
+func (self *SRegion) GetIBucketById(name string) (cloudprovider.ICloudBucket, error) {
+	buckets, err := self.GetBuckets()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(buckets); i++ {
+		if buckets[i].Name == name {
+			return &buckets[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) CreateIBucket(name string, storageClassStr string, acl string) error {
+	obscli, err := self.getOBSClient()
+	if err != nil {
+		return err
+	}
+	storageClass, err := str2StorageClass(storageClassStr)
+	if err != nil {
+		return err
+	}
+	input := &obs.CreateBucketInput{}
+	input.Bucket = name
+	input.Location = self.GetId()
+	input.StorageClass = storageClass
+	if len(acl) > 0 {
+		input.ACL = obs.AclType(acl)
+	}
+	_, err = obscli.CreateBucket(input)
+	return err
+}
+
+func (self *SRegion) DeleteIBucket(name string) error {
+	obscli, err := self.getOBSClient()
+	if err != nil {
+		return err
+	}
+	_, err = obscli.DeleteBucket(name)
+	if err != nil {
+		if obsErr, ok := err.(obs.ObsError); ok && obsErr.StatusCode == 404 {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
@@ -22,12 +22,26 @@ type Client struct {
 	Disks              *modules.SDiskManager
 	Domains            *modules.SDomainManager
 	Eips               *modules.SEipManager
+	Elbs               *modules.SLoadbalancerManager
+	ElbBackends        *modules.SElbBackendManager
+	ElbBackendGroup    *modules.SElbBackendGroupManager
+	ElbCertificates    *modules.SElbCertificateManager
+	ElbHealthCheck     *modules.SElbHealthCheckManager
+	ElbListeners       *modules.SElbListenersManager
+	ElbPolicies        *modules.SElbPoliciesManager
+	ElbPolicyRules     *modules.SElbPolicyRuleManager
+	ElbWhitelist       *modules.SElbWhitelistManager
 	Flavors            *modules.SFlavorManager
 	Images             *modules.SImageManager
 	OpenStackImages    *modules.SImageManager
 	Interface          *modules.SInterfaceManager
 	Jobs               *modules.SJobManager
 	Keypairs           *modules.SKeypairManager
+	NatGateways        *modules.SNatGatewayManager
+	SNatRules          *modules.SSNatRuleManager
+	DNatRules          *modules.SDNatRuleManager
+	DBInstances        *modules.SDBInstanceManager
+	DBInstanceBackups  *modules.SDBInstanceBackupManager
 	Orders             *modules.SOrderManager
 	Port               *modules.SPortManager
 	Projects           *modules.SProjectManager
@@ -42,6 +56,8 @@ type Client struct {
 	Subnets            *modules.SSubnetManager
 	Users              *modules.SUserManager
 	Vpcs               *modules.SVpcManager
+	VpcPeerings        *modules.SVpcPeeringManager
+	VpcRoutes          *modules.SVpcRouteManager
 	Zones              *modules.SZoneManager
 }
 
@@ -104,6 +120,22 @@ func (self *Client) initManagers() {
 		self.Bandwidths = modules.NewBandwidthManager(self.regionId, self.projectId, self.signer, self.debug)
 		self.Port = modules.NewPortManager(self.regionId, self.projectId, self.signer, self.debug)
 		self.Flavors = modules.NewFlavorManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.Elbs = modules.NewLoadbalancerManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.ElbBackends = modules.NewElbBackendManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.ElbBackendGroup = modules.NewElbBackendGroupManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.ElbCertificates = modules.NewElbCertificateManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.ElbHealthCheck = modules.NewElbHealthCheckManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.ElbListeners = modules.NewElbListenersManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.ElbPolicies = modules.NewElbPoliciesManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.ElbPolicyRules = modules.NewElbPolicyRuleManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.ElbWhitelist = modules.NewElbWhitelistManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.NatGateways = modules.NewNatGatewayManager(self.regionId, self.signer, self.debug)
+		self.SNatRules = modules.NewSNatRuleManager(self.regionId, self.signer, self.debug)
+		self.DNatRules = modules.NewDNatRuleManager(self.regionId, self.signer, self.debug)
+		self.DBInstances = modules.NewDBInstanceManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.DBInstanceBackups = modules.NewDBInstanceBackupManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.VpcPeerings = modules.NewVpcPeeringManager(self.regionId, self.projectId, self.signer, self.debug)
+		self.VpcRoutes = modules.NewVpcRouteManager(self.regionId, self.projectId, self.signer, self.debug)
 	}
 
 	self.init = true
@@ -0,0 +1,23 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SDBInstanceBackupManager struct {
+	SResourceManager
+}
+
+func NewDBInstanceBackupManager(regionId string, projectId string, signer auth.Signer, debug bool) *SDBInstanceBackupManager {
+	return &SDBInstanceBackupManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameRDS,
+		Region:        regionId,
+		ProjectId:     projectId,
+		version:       "v3",
+		Keyword:       "backup",
+		KeywordPlural: "backups",
+
+		ResourceKeyword: "backups",
+	}}
+}
@@ -0,0 +1,23 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SDBInstanceManager struct {
+	SResourceManager
+}
+
+func NewDBInstanceManager(regionId string, projectId string, signer auth.Signer, debug bool) *SDBInstanceManager {
+	return &SDBInstanceManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameRDS,
+		Region:        regionId,
+		ProjectId:     projectId,
+		version:       "v3",
+		Keyword:       "instance",
+		KeywordPlural: "instances",
+
+		ResourceKeyword: "instances",
+	}}
+}
@@ -0,0 +1,24 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SDNatRuleManager struct {
+	SResourceManager
+}
+
+// NAT网关接口不需要project_id
+func NewDNatRuleManager(regionId string, signer auth.Signer, debug bool) *SDNatRuleManager {
+	return &SDNatRuleManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameNAT,
+		Region:        regionId,
+		ProjectId:     "",
+		version:       "v2.0",
+		Keyword:       "dnat_rule",
+		KeywordPlural: "dnat_rules",
+
+		ResourceKeyword: "dnat_rules",
+	}}
+}
@@ -0,0 +1,23 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SElbBackendGroupManager struct {
+	SResourceManager
+}
+
+func NewElbBackendGroupManager(regionId string, projectId string, signer auth.Signer, debug bool) *SElbBackendGroupManager {
+	return &SElbBackendGroupManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameELB,
+		Region:        regionId,
+		ProjectId:     projectId,
+		version:       "v2",
+		Keyword:       "pool",
+		KeywordPlural: "pools",
+
+		ResourceKeyword: "elb/pools",
+	}}
+}
@@ -0,0 +1,24 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SElbBackendManager struct {
+	SResourceManager
+}
+
+// 后端服务器需要在后端服务器组(pool)上下文中访问: /v2/{project_id}/elb/pools/{pool_id}/members
+func NewElbBackendManager(regionId string, projectId string, signer auth.Signer, debug bool) *SElbBackendManager {
+	return &SElbBackendManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameELB,
+		Region:        regionId,
+		ProjectId:     projectId,
+		version:       "v2",
+		Keyword:       "member",
+		KeywordPlural: "members",
+
+		ResourceKeyword: "members",
+	}}
+}
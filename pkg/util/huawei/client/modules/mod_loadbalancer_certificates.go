@@ -0,0 +1,24 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SElbCertificateManager struct {
+	SResourceManager
+}
+
+// 证书接口的请求与响应没有外层的certificate关键字
+func NewElbCertificateManager(regionId string, projectId string, signer auth.Signer, debug bool) *SElbCertificateManager {
+	return &SElbCertificateManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameELB,
+		Region:        regionId,
+		ProjectId:     projectId,
+		version:       "v2",
+		Keyword:       "",
+		KeywordPlural: "certificates",
+
+		ResourceKeyword: "elb/certificates",
+	}}
+}
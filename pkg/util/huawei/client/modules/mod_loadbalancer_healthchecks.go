@@ -0,0 +1,23 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SElbHealthCheckManager struct {
+	SResourceManager
+}
+
+func NewElbHealthCheckManager(regionId string, projectId string, signer auth.Signer, debug bool) *SElbHealthCheckManager {
+	return &SElbHealthCheckManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameELB,
+		Region:        regionId,
+		ProjectId:     projectId,
+		version:       "v2",
+		Keyword:       "healthmonitor",
+		KeywordPlural: "healthmonitors",
+
+		ResourceKeyword: "elb/healthmonitors",
+	}}
+}
@@ -0,0 +1,23 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SElbListenersManager struct {
+	SResourceManager
+}
+
+func NewElbListenersManager(regionId string, projectId string, signer auth.Signer, debug bool) *SElbListenersManager {
+	return &SElbListenersManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameELB,
+		Region:        regionId,
+		ProjectId:     projectId,
+		version:       "v2",
+		Keyword:       "listener",
+		KeywordPlural: "listeners",
+
+		ResourceKeyword: "elb/listeners",
+	}}
+}
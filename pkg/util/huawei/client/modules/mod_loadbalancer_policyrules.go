@@ -0,0 +1,24 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SElbPolicyRuleManager struct {
+	SResourceManager
+}
+
+// 转发规则需要在转发策略上下文中访问: /v2/{project_id}/elb/l7policies/{l7policy_id}/rules
+func NewElbPolicyRuleManager(regionId string, projectId string, signer auth.Signer, debug bool) *SElbPolicyRuleManager {
+	return &SElbPolicyRuleManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameELB,
+		Region:        regionId,
+		ProjectId:     projectId,
+		version:       "v2",
+		Keyword:       "rule",
+		KeywordPlural: "rules",
+
+		ResourceKeyword: "rules",
+	}}
+}
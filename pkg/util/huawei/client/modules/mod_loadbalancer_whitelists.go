@@ -0,0 +1,23 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SElbWhitelistManager struct {
+	SResourceManager
+}
+
+func NewElbWhitelistManager(regionId string, projectId string, signer auth.Signer, debug bool) *SElbWhitelistManager {
+	return &SElbWhitelistManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameELB,
+		Region:        regionId,
+		ProjectId:     projectId,
+		version:       "v2",
+		Keyword:       "whitelist",
+		KeywordPlural: "whitelists",
+
+		ResourceKeyword: "elb/whitelists",
+	}}
+}
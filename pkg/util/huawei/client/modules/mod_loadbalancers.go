@@ -0,0 +1,23 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SLoadbalancerManager struct {
+	SResourceManager
+}
+
+func NewLoadbalancerManager(regionId string, projectId string, signer auth.Signer, debug bool) *SLoadbalancerManager {
+	return &SLoadbalancerManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameELB,
+		Region:        regionId,
+		ProjectId:     projectId,
+		version:       "v2",
+		Keyword:       "loadbalancer",
+		KeywordPlural: "loadbalancers",
+
+		ResourceKeyword: "elb/loadbalancers",
+	}}
+}
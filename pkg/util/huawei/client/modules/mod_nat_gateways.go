@@ -0,0 +1,24 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SNatGatewayManager struct {
+	SResourceManager
+}
+
+// NAT网关接口不需要project_id
+func NewNatGatewayManager(regionId string, signer auth.Signer, debug bool) *SNatGatewayManager {
+	return &SNatGatewayManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameNAT,
+		Region:        regionId,
+		ProjectId:     "",
+		version:       "v2.0",
+		Keyword:       "nat_gateway",
+		KeywordPlural: "nat_gateways",
+
+		ResourceKeyword: "nat_gateways",
+	}}
+}
@@ -0,0 +1,24 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SSNatRuleManager struct {
+	SResourceManager
+}
+
+// NAT网关接口不需要project_id
+func NewSNatRuleManager(regionId string, signer auth.Signer, debug bool) *SSNatRuleManager {
+	return &SSNatRuleManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager(signer, debug),
+		ServiceName:   ServiceNameNAT,
+		Region:        regionId,
+		ProjectId:     "",
+		version:       "v2.0",
+		Keyword:       "snat_rule",
+		KeywordPlural: "snat_rules",
+
+		ResourceKeyword: "snat_rules",
+	}}
+}
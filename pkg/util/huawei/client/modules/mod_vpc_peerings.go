@@ -0,0 +1,29 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SVpcPeeringManager struct {
+	SResourceManager
+}
+
+// 对等连接接口为v2.0版本, url中不携带project信息, 与port接口一样通过header指定X-Project-ID
+func NewVpcPeeringManager(regionId string, projectId string, signer auth.Signer, debug bool) *SVpcPeeringManager {
+	var requestHook portProject
+	if len(projectId) > 0 {
+		requestHook = portProject{projectId: projectId}
+	}
+
+	return &SVpcPeeringManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager2(signer, debug, &requestHook),
+		ServiceName:   ServiceNameVPC,
+		Region:        regionId,
+		ProjectId:     "",
+		version:       "v2.0",
+		Keyword:       "peering",
+		KeywordPlural: "peerings",
+
+		ResourceKeyword: "vpc/peerings",
+	}}
+}
@@ -0,0 +1,28 @@
+package modules
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei/client/auth"
+)
+
+type SVpcRouteManager struct {
+	SResourceManager
+}
+
+func NewVpcRouteManager(regionId string, projectId string, signer auth.Signer, debug bool) *SVpcRouteManager {
+	var requestHook portProject
+	if len(projectId) > 0 {
+		requestHook = portProject{projectId: projectId}
+	}
+
+	return &SVpcRouteManager{SResourceManager: SResourceManager{
+		SBaseManager:  NewBaseManager2(signer, debug, &requestHook),
+		ServiceName:   ServiceNameVPC,
+		Region:        regionId,
+		ProjectId:     "",
+		version:       "v2.0",
+		Keyword:       "route",
+		KeywordPlural: "routes",
+
+		ResourceKeyword: "vpc/routes",
+	}}
+}
@@ -0,0 +1,406 @@
+package huawei
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// 华为云RDS时间格式, 如 2018-08-20T02:33:49+0800
+const rdsTimeFormat = "2006-01-02T15:04:05Z0700"
+
+type SDatastore struct {
+	Type    string
+	Version string
+}
+
+type SVolume struct {
+	Type string
+	Size int
+}
+
+type SChargeInfo struct {
+	// prePaid: 包年包月, postPaid: 按需计费
+	ChargeMode string `json:"charge_mode"`
+}
+
+type SDBInstanceNode struct {
+	Id               string
+	Name             string
+	Role             string
+	Status           string
+	AvailabilityZone string `json:"availability_zone"`
+}
+
+type SDBInstance struct {
+	region *SRegion
+
+	Id         string
+	Name       string
+	Status     string
+	PrivateIps []string `json:"private_ips"`
+	PublicIps  []string `json:"public_ips"`
+	Port       int
+	// Single: 单机, Ha: 主备, Replica: 只读
+	Type       string
+	Datastore  SDatastore
+	Created    string
+	Volume     SVolume
+	Nodes      []SDBInstanceNode
+	FlavorRef  string `json:"flavor_ref"`
+	Cpu        string
+	Mem        string
+	VpcId      string      `json:"vpc_id"`
+	SubnetId   string      `json:"subnet_id"`
+	ChargeInfo SChargeInfo `json:"charge_info"`
+}
+
+func (self *SDBInstance) GetId() string {
+	return self.Id
+}
+
+func (self *SDBInstance) GetName() string {
+	if len(self.Name) > 0 {
+		return self.Name
+	}
+	return self.Id
+}
+
+func (self *SDBInstance) GetGlobalId() string {
+	return self.Id
+}
+
+func (self *SDBInstance) GetStatus() string {
+	switch self.Status {
+	case "BUILD":
+		return api.DBINSTANCE_STATUS_DEPLOYING
+	case "ACTIVE", "BACKING UP", "STORAGE FULL":
+		return api.DBINSTANCE_STATUS_RUNNING
+	case "REBOOTING":
+		return api.DBINSTANCE_STATUS_REBOOTING
+	case "MODIFYING", "MODIFYING INSTANCE TYPE", "MODIFYING DATABASE PORT", "RESTORING", "SWITCHOVER", "MIGRATING":
+		return api.DBINSTANCE_STATUS_MAINTENANCE
+	default:
+		return api.DBINSTANCE_STATUS_UNKNOWN
+	}
+}
+
+func (self *SDBInstance) Refresh() error {
+	instance, err := self.region.GetDBInstance(self.Id)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, instance)
+}
+
+func (self *SDBInstance) IsEmulated() bool {
+	return false
+}
+
+func (self *SDBInstance) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SDBInstance) GetBillingType() string {
+	if self.ChargeInfo.ChargeMode == "prePaid" {
+		return models.BILLING_TYPE_PREPAID
+	}
+	return models.BILLING_TYPE_POSTPAID
+}
+
+func (self *SDBInstance) GetExpiredAt() time.Time {
+	if self.ChargeInfo.ChargeMode == "prePaid" {
+		res, err := self.region.GetOrderResourceDetail(self.Id)
+		if err == nil {
+			return res.ExpireTime
+		}
+	}
+	return time.Time{}
+}
+
+func (self *SDBInstance) GetEngine() string {
+	switch strings.ToLower(self.Datastore.Type) {
+	case "mysql":
+		return api.DBINSTANCE_ENGINE_MYSQL
+	case "postgresql":
+		return api.DBINSTANCE_ENGINE_POSTGRESQL
+	case "sqlserver":
+		return api.DBINSTANCE_ENGINE_SQLSERVER
+	}
+	return self.Datastore.Type
+}
+
+func (self *SDBInstance) GetEngineVersion() string {
+	return self.Datastore.Version
+}
+
+func (self *SDBInstance) GetInstanceType() string {
+	return self.FlavorRef
+}
+
+func (self *SDBInstance) GetVcpuCount() int {
+	cpu, _ := strconv.Atoi(self.Cpu)
+	return cpu
+}
+
+func (self *SDBInstance) GetVmemSizeMB() int {
+	mem, _ := strconv.Atoi(self.Mem)
+	return mem * 1024
+}
+
+func (self *SDBInstance) GetDiskSizeGB() int {
+	return self.Volume.Size
+}
+
+func (self *SDBInstance) GetCategory() string {
+	return strings.ToLower(self.Type)
+}
+
+func (self *SDBInstance) GetStorageType() string {
+	return self.Volume.Type
+}
+
+func (self *SDBInstance) GetCreateTime() time.Time {
+	created, err := time.Parse(rdsTimeFormat, self.Created)
+	if err != nil {
+		return time.Time{}
+	}
+	return created
+}
+
+func (self *SDBInstance) GetPort() int {
+	return self.Port
+}
+
+func (self *SDBInstance) GetConnectionStr() string {
+	if len(self.PublicIps) > 0 {
+		return fmt.Sprintf("%s:%d", self.PublicIps[0], self.Port)
+	}
+	return ""
+}
+
+func (self *SDBInstance) GetInternalConnectionStr() string {
+	if len(self.PrivateIps) > 0 {
+		return fmt.Sprintf("%s:%d", self.PrivateIps[0], self.Port)
+	}
+	return ""
+}
+
+func (self *SDBInstance) GetVpcId() string {
+	return self.VpcId
+}
+
+func (self *SDBInstance) GetNetworkId() string {
+	return self.SubnetId
+}
+
+func (self *SDBInstance) GetZoneId() string {
+	if len(self.Nodes) == 0 {
+		return ""
+	}
+	zones, err := self.region.GetIZones()
+	if err != nil {
+		return ""
+	}
+	for _, zone := range zones {
+		if zone.GetId() == self.Nodes[0].AvailabilityZone {
+			return zone.GetGlobalId()
+		}
+	}
+	return ""
+}
+
+func (self *SDBInstance) GetIDBInstanceBackups() ([]cloudprovider.ICloudDBInstanceBackup, error) {
+	backups, err := self.region.GetDBInstanceBackups(self.Id)
+	if err != nil {
+		return nil, err
+	}
+	ibackups := make([]cloudprovider.ICloudDBInstanceBackup, len(backups))
+	for i := 0; i < len(backups); i++ {
+		ibackups[i] = &backups[i]
+	}
+	return ibackups, nil
+}
+
+func (self *SDBInstance) Start() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SDBInstance) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SDBInstance) Reboot() error {
+	return self.region.RebootDBInstance(self.Id)
+}
+
+func (self *SDBInstance) Delete() error {
+	return self.region.DeleteDBInstance(self.Id)
+}
+
+// RDS列表接口单页最多返回100条, 且不支持marker
+func (self *SRegion) listDBResources(doList listFunc, queries map[string]string, result interface{}) error {
+	offset := 0
+	for {
+		queries["limit"] = "100"
+		queries["offset"] = fmt.Sprintf("%d", offset)
+		_, part, err := doListPart(doList, queries, result)
+		if err != nil {
+			return err
+		}
+		if part < 100 {
+			break
+		}
+		offset += part
+	}
+	return nil
+}
+
+func (self *SRegion) GetDBInstances() ([]SDBInstance, error) {
+	instances := make([]SDBInstance, 0)
+	err := self.listDBResources(self.ecsClient.DBInstances.List, map[string]string{}, &instances)
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+func (self *SRegion) GetDBInstance(instanceId string) (*SDBInstance, error) {
+	instances := make([]SDBInstance, 0)
+	err := doListAll(self.ecsClient.DBInstances.List, map[string]string{"id": instanceId}, &instances)
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	instances[0].region = self
+	return &instances[0], nil
+}
+
+func (self *SRegion) RebootDBInstance(instanceId string) error {
+	params := jsonutils.NewDict()
+	params.Add(jsonutils.NewDict(), "restart")
+	_, err := self.ecsClient.DBInstances.PerformAction2("action", instanceId, params, "")
+	return err
+}
+
+func (self *SRegion) DeleteDBInstance(instanceId string) error {
+	return DoDelete(self.ecsClient.DBInstances.Delete, instanceId, nil, nil)
+}
+
+func (self *SRegion) GetIDBInstances() ([]cloudprovider.ICloudDBInstance, error) {
+	instances, err := self.GetDBInstances()
+	if err != nil {
+		return nil, err
+	}
+	iinstances := make([]cloudprovider.ICloudDBInstance, len(instances))
+	for i := 0; i < len(instances); i++ {
+		instances[i].region = self
+		iinstances[i] = &instances[i]
+	}
+	return iinstances, nil
+}
+
+func (self *SRegion) GetIDBInstanceById(instanceId string) (cloudprovider.ICloudDBInstance, error) {
+	instance, err := self.GetDBInstance(instanceId)
+	if err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+type SDBInstanceBackup struct {
+	Id         string
+	Name       string
+	InstanceId string `json:"instance_id"`
+	// auto: 自动备份, manual: 手动备份
+	Type string
+	// 单位KB
+	Size int64
+	// BUILDING, COMPLETED, FAILED, DELETING
+	Status    string
+	BeginTime string `json:"begin_time"`
+	EndTime   string `json:"end_time"`
+}
+
+func (self *SDBInstanceBackup) GetId() string {
+	return self.Id
+}
+
+func (self *SDBInstanceBackup) GetName() string {
+	if len(self.Name) > 0 {
+		return self.Name
+	}
+	return self.Id
+}
+
+func (self *SDBInstanceBackup) GetGlobalId() string {
+	return self.Id
+}
+
+func (self *SDBInstanceBackup) GetStatus() string {
+	switch self.Status {
+	case "COMPLETED":
+		return api.DBINSTANCE_BACKUP_STATUS_READY
+	case "BUILDING":
+		return api.DBINSTANCE_BACKUP_STATUS_CREATING
+	case "FAILED":
+		return api.DBINSTANCE_BACKUP_STATUS_FAILED
+	default:
+		return api.DBINSTANCE_BACKUP_STATUS_UNKNOWN
+	}
+}
+
+func (self *SDBInstanceBackup) Refresh() error {
+	return nil
+}
+
+func (self *SDBInstanceBackup) IsEmulated() bool {
+	return false
+}
+
+func (self *SDBInstanceBackup) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SDBInstanceBackup) GetDBInstanceId() string {
+	return self.InstanceId
+}
+
+func (self *SDBInstanceBackup) GetStartTime() time.Time {
+	begin, _ := time.Parse(rdsTimeFormat, self.BeginTime)
+	return begin
+}
+
+func (self *SDBInstanceBackup) GetEndTime() time.Time {
+	end, _ := time.Parse(rdsTimeFormat, self.EndTime)
+	return end
+}
+
+func (self *SDBInstanceBackup) GetBackupSizeMb() int {
+	return int(self.Size / 1024)
+}
+
+func (self *SDBInstanceBackup) GetBackupMode() string {
+	if self.Type == "manual" {
+		return api.DBINSTANCE_BACKUP_MODE_MANUAL
+	}
+	return api.DBINSTANCE_BACKUP_MODE_AUTOMATED
+}
+
+func (self *SRegion) GetDBInstanceBackups(instanceId string) ([]SDBInstanceBackup, error) {
+	backups := make([]SDBInstanceBackup, 0)
+	err := self.listDBResources(self.ecsClient.DBInstanceBackups.List, map[string]string{"instance_id": instanceId}, &backups)
+	if err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
@@ -0,0 +1,299 @@
+package huawei
+
+import (
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SElbRef struct {
+	ID string `json:"id"`
+}
+
+// https://support.huaweicloud.com/api-elb/zh-cn_topic_0096561535.html
+// 共享型负载均衡, vip位于子网中, 公网访问需要为vip端口绑定弹性公网ip
+type SLoadbalancer struct {
+	region  *SRegion
+	network *SNetwork
+
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description"`
+	ProvisioningStatus string    `json:"provisioning_status"`
+	OperatingStatus    string    `json:"operating_status"`
+	AdminStateUp       bool      `json:"admin_state_up"`
+	Provider           string    `json:"provider"`
+	VipAddress         string    `json:"vip_address"`
+	VipSubnetID        string    `json:"vip_subnet_id"`
+	VipPortID          string    `json:"vip_port_id"`
+	TenantID           string    `json:"tenant_id"`
+	ProjectID          string    `json:"project_id"`
+	Listeners          []SElbRef `json:"listeners"`
+	Pools              []SElbRef `json:"pools"`
+	CreatedAt          string    `json:"created_at"`
+	UpdatedAt          string    `json:"updated_at"`
+}
+
+func (self *SLoadbalancer) GetId() string {
+	return self.ID
+}
+
+func (self *SLoadbalancer) GetName() string {
+	if len(self.Name) == 0 {
+		return self.ID
+	}
+	return self.Name
+}
+
+func (self *SLoadbalancer) GetGlobalId() string {
+	return self.ID
+}
+
+func (self *SLoadbalancer) GetStatus() string {
+	switch self.ProvisioningStatus {
+	case "ACTIVE":
+		if self.OperatingStatus == "FROZEN" {
+			return api.LB_STATUS_DISABLED
+		}
+		return api.LB_STATUS_ENABLED
+	case "PENDING_CREATE":
+		return api.LB_STATUS_INIT
+	default:
+		return api.LB_STATUS_UNKNOWN
+	}
+}
+
+func (self *SLoadbalancer) Refresh() error {
+	lb, err := self.region.GetLoadbalancer(self.ID)
+	if err != nil {
+		return err
+	}
+	self.network = nil
+	return jsonutils.Update(self, lb)
+}
+
+func (self *SLoadbalancer) IsEmulated() bool {
+	return false
+}
+
+func (self *SLoadbalancer) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SLoadbalancer) GetProjectId() string {
+	return ""
+}
+
+// vip_subnet_id是子网的neutron_subnet_id, 需要反查对应的vpc子网
+func (self *SLoadbalancer) getNetwork() *SNetwork {
+	if self.network == nil {
+		networks, err := self.region.GetNetwroks("")
+		if err != nil {
+			log.Errorf("failed to get networks: %v", err)
+			return nil
+		}
+		for i := range networks {
+			if networks[i].NeutronSubnetID == self.VipSubnetID {
+				self.network = &networks[i]
+				break
+			}
+		}
+	}
+	return self.network
+}
+
+func (self *SLoadbalancer) GetAddress() string {
+	return self.VipAddress
+}
+
+func (self *SLoadbalancer) GetAddressType() string {
+	return api.LB_ADDR_TYPE_INTRANET
+}
+
+func (self *SLoadbalancer) GetNetworkType() string {
+	return api.LB_NETWORK_TYPE_VPC
+}
+
+func (self *SLoadbalancer) GetNetworkId() string {
+	if network := self.getNetwork(); network != nil {
+		return network.GetId()
+	}
+	return ""
+}
+
+func (self *SLoadbalancer) GetVpcId() string {
+	if network := self.getNetwork(); network != nil {
+		return network.VpcID
+	}
+	return ""
+}
+
+func (self *SLoadbalancer) GetZoneId() string {
+	zones, err := self.region.GetIZones()
+	if err != nil || len(zones) == 0 {
+		return ""
+	}
+	if network := self.getNetwork(); network != nil && len(network.AvailabilityZone) > 0 {
+		for _, zone := range zones {
+			if zone.GetId() == network.AvailabilityZone {
+				return zone.GetGlobalId()
+			}
+		}
+	}
+	return zones[0].GetGlobalId()
+}
+
+func (self *SLoadbalancer) GetLoadbalancerSpec() string {
+	return ""
+}
+
+func (self *SLoadbalancer) GetChargeType() string {
+	return api.LB_CHARGE_TYPE_BY_TRAFFIC
+}
+
+// 共享型负载均衡不支持级联删除, 需要先删除监听器及后端服务器组
+func (self *SLoadbalancer) Delete() error {
+	listeners, err := self.region.GetElbListeners(self.ID)
+	if err != nil {
+		return err
+	}
+	for i := range listeners {
+		listeners[i].lb = self
+		if err := listeners[i].Delete(); err != nil {
+			return err
+		}
+	}
+	pools, err := self.region.GetElbBackendGroups(self.ID)
+	if err != nil {
+		return err
+	}
+	for i := range pools {
+		pools[i].lb = self
+		if err := pools[i].Delete(); err != nil {
+			return err
+		}
+	}
+	return DoDelete(self.region.ecsClient.Elbs.Delete, self.ID, nil, nil)
+}
+
+// 共享型负载均衡的admin_state_up只能为true
+func (self *SLoadbalancer) Start() error {
+	return nil
+}
+
+func (self *SLoadbalancer) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SLoadbalancer) GetILoadBalancerListeners() ([]cloudprovider.ICloudLoadbalancerListener, error) {
+	listeners, err := self.region.GetElbListeners(self.ID)
+	if err != nil {
+		return nil, err
+	}
+	ilisteners := make([]cloudprovider.ICloudLoadbalancerListener, len(listeners))
+	for i := range listeners {
+		listeners[i].lb = self
+		ilisteners[i] = &listeners[i]
+	}
+	return ilisteners, nil
+}
+
+func (self *SLoadbalancer) GetILoadBalancerListenerById(listenerId string) (cloudprovider.ICloudLoadbalancerListener, error) {
+	listener, err := self.region.GetElbListener(listenerId)
+	if err != nil {
+		return nil, err
+	}
+	listener.lb = self
+	return listener, nil
+}
+
+func (self *SLoadbalancer) CreateILoadBalancerListener(listener *cloudprovider.SLoadbalancerListener) (cloudprovider.ICloudLoadbalancerListener, error) {
+	ret, err := self.region.CreateElbListener(self, listener)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (self *SLoadbalancer) GetILoadBalancerBackendGroups() ([]cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	pools, err := self.region.GetElbBackendGroups(self.ID)
+	if err != nil {
+		return nil, err
+	}
+	igroups := make([]cloudprovider.ICloudLoadbalancerBackendGroup, len(pools))
+	for i := range pools {
+		pools[i].lb = self
+		igroups[i] = &pools[i]
+	}
+	return igroups, nil
+}
+
+func (self *SLoadbalancer) GetILoadBalancerBackendGroupById(groupId string) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	pool, err := self.region.GetElbBackendGroup(groupId)
+	if err != nil {
+		return nil, err
+	}
+	pool.lb = self
+	return pool, nil
+}
+
+func (self *SLoadbalancer) CreateILoadBalancerBackendGroup(group *cloudprovider.SLoadbalancerBackendGroup) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	ret, err := self.region.CreateElbBackendGroup(self, group)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetLoadbalancers() ([]SLoadbalancer, error) {
+	lbs := make([]SLoadbalancer, 0)
+	err := doListAll(self.ecsClient.Elbs.List, map[string]string{}, &lbs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lbs {
+		lbs[i].region = self
+	}
+	return lbs, nil
+}
+
+func (self *SRegion) GetLoadbalancer(lbId string) (*SLoadbalancer, error) {
+	lb := &SLoadbalancer{region: self}
+	err := DoGet(self.ecsClient.Elbs.Get, lbId, nil, lb)
+	if err != nil {
+		return nil, err
+	}
+	return lb, nil
+}
+
+func (self *SRegion) CreateLoadbalancer(loadbalancer *cloudprovider.SLoadbalancer) (*SLoadbalancer, error) {
+	network, err := self.getNetwork(loadbalancer.NetworkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find network %s: %v", loadbalancer.NetworkID, err)
+	}
+	params := map[string]map[string]interface{}{
+		"loadbalancer": {
+			"name":           loadbalancer.Name,
+			"vip_subnet_id":  network.NeutronSubnetID,
+			"admin_state_up": true,
+		},
+	}
+	if len(loadbalancer.Address) > 0 {
+		params["loadbalancer"]["vip_address"] = loadbalancer.Address
+	}
+	lb := &SLoadbalancer{region: self}
+	err = DoCreate(self.ecsClient.Elbs.Create, jsonutils.Marshal(params), lb)
+	if err != nil {
+		return nil, err
+	}
+	err = cloudprovider.WaitStatus(lb, api.LB_STATUS_ENABLED, 5*time.Second, 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	return lb, nil
+}
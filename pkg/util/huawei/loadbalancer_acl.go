@@ -0,0 +1,138 @@
+package huawei
+
+import (
+	"strings"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+// https://support.huaweicloud.com/api-elb/zh-cn_topic_0096561582.html
+// 白名单属于监听器, 每个监听器最多一个白名单
+type SElbACL struct {
+	region *SRegion
+
+	ID              string `json:"id"`
+	TenantID        string `json:"tenant_id"`
+	ListenerID      string `json:"listener_id"`
+	EnableWhitelist bool   `json:"enable_whitelist"`
+	Whitelist       string `json:"whitelist"`
+}
+
+func (self *SElbACL) GetId() string {
+	return self.ID
+}
+
+func (self *SElbACL) GetName() string {
+	return self.ID
+}
+
+func (self *SElbACL) GetGlobalId() string {
+	return self.ID
+}
+
+func (self *SElbACL) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbACL) Refresh() error {
+	acl, err := self.region.GetElbACL(self.ID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, acl)
+}
+
+func (self *SElbACL) IsEmulated() bool {
+	return false
+}
+
+func (self *SElbACL) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SElbACL) GetProjectId() string {
+	return ""
+}
+
+func (self *SElbACL) GetAclEntries() []cloudprovider.SLoadbalancerAccessControlListEntry {
+	entries := []cloudprovider.SLoadbalancerAccessControlListEntry{}
+	for _, cidr := range strings.Split(self.Whitelist, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if len(cidr) > 0 {
+			entries = append(entries, cloudprovider.SLoadbalancerAccessControlListEntry{CIDR: cidr})
+		}
+	}
+	return entries
+}
+
+func (self *SElbACL) Sync(acl *cloudprovider.SLoadbalancerAccessControlList) error {
+	cidrs := []string{}
+	for _, entry := range acl.Entrys {
+		cidrs = append(cidrs, entry.CIDR)
+	}
+	return self.update(self.EnableWhitelist, strings.Join(cidrs, ","))
+}
+
+func (self *SElbACL) Delete() error {
+	return DoDelete(self.region.ecsClient.ElbWhitelist.Delete, self.ID, nil, nil)
+}
+
+func (self *SElbACL) update(enable bool, whitelist string) error {
+	params := map[string]map[string]interface{}{
+		"whitelist": {
+			"enable_whitelist": enable,
+			"whitelist":        whitelist,
+		},
+	}
+	err := DoUpdate(self.region.ecsClient.ElbWhitelist.Update, self.ID, jsonutils.Marshal(params), nil)
+	if err != nil {
+		return err
+	}
+	self.EnableWhitelist = enable
+	self.Whitelist = whitelist
+	return nil
+}
+
+func (self *SRegion) GetElbACLs(listenerId string) ([]SElbACL, error) {
+	querys := map[string]string{}
+	if len(listenerId) > 0 {
+		querys["listener_id"] = listenerId
+	}
+	acls := make([]SElbACL, 0)
+	err := doListAll(self.ecsClient.ElbWhitelist.List, querys, &acls)
+	if err != nil {
+		return nil, err
+	}
+	for i := range acls {
+		acls[i].region = self
+	}
+	return acls, nil
+}
+
+func (self *SRegion) GetElbACL(aclId string) (*SElbACL, error) {
+	acl := &SElbACL{region: self}
+	err := DoGet(self.ecsClient.ElbWhitelist.Get, aclId, nil, acl)
+	if err != nil {
+		return nil, err
+	}
+	return acl, nil
+}
+
+func (self *SRegion) CreateElbACL(listenerId string, whitelist string) (*SElbACL, error) {
+	params := map[string]map[string]interface{}{
+		"whitelist": {
+			"listener_id":      listenerId,
+			"enable_whitelist": true,
+			"whitelist":        whitelist,
+		},
+	}
+	acl := &SElbACL{region: self}
+	err := DoCreate(self.ecsClient.ElbWhitelist.Create, jsonutils.Marshal(params), acl)
+	if err != nil {
+		return nil, err
+	}
+	return acl, nil
+}
@@ -0,0 +1,174 @@
+package huawei
+
+import (
+	"fmt"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/util/huawei/client/modules"
+)
+
+// https://support.huaweicloud.com/api-elb/zh-cn_topic_0096561557.html
+type SElbBackend struct {
+	pool     *SElbBackendGroup
+	serverId string
+
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	TenantID        string `json:"tenant_id"`
+	ProjectID       string `json:"project_id"`
+	Address         string `json:"address"`
+	ProtocolPort    int    `json:"protocol_port"`
+	Weight          int    `json:"weight"`
+	SubnetID        string `json:"subnet_id"`
+	AdminStateUp    bool   `json:"admin_state_up"`
+	OperatingStatus string `json:"operating_status"`
+}
+
+func (self *SElbBackend) GetId() string {
+	return self.ID
+}
+
+func (self *SElbBackend) GetName() string {
+	if len(self.Name) == 0 {
+		return self.ID
+	}
+	return self.Name
+}
+
+func (self *SElbBackend) GetGlobalId() string {
+	return self.ID
+}
+
+func (self *SElbBackend) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbBackend) Refresh() error {
+	member, err := self.pool.lb.region.GetElbBackend(self.pool.ID, self.ID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, member)
+}
+
+func (self *SElbBackend) IsEmulated() bool {
+	return false
+}
+
+func (self *SElbBackend) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SElbBackend) GetProjectId() string {
+	return ""
+}
+
+func (self *SElbBackend) GetWeight() int {
+	return self.Weight
+}
+
+func (self *SElbBackend) GetPort() int {
+	return self.ProtocolPort
+}
+
+func (self *SElbBackend) GetBackendType() string {
+	return api.LB_BACKEND_GUEST
+}
+
+func (self *SElbBackend) GetBackendRole() string {
+	return api.LB_BACKEND_ROLE_DEFAULT
+}
+
+// 后端服务器只记录ip地址, 通过端口反查所属的虚拟机
+func (self *SElbBackend) GetBackendId() string {
+	if len(self.serverId) > 0 {
+		return self.serverId
+	}
+	ports, err := self.pool.lb.region.getPortsByIp(self.Address)
+	if err != nil {
+		log.Errorf("failed to find port of backend %s(%s): %v", self.ID, self.Address, err)
+		return ""
+	}
+	for _, port := range ports {
+		if !strings.HasPrefix(port.DeviceOwner, "compute:") {
+			continue
+		}
+		for _, ip := range port.FixedIps {
+			if ip.IpAddress == self.Address && (len(self.SubnetID) == 0 || ip.SubnetID == self.SubnetID) {
+				self.serverId = port.DeviceID
+				return self.serverId
+			}
+		}
+	}
+	return ""
+}
+
+func (self *SElbBackend) Delete() error {
+	ctx := self.pool.lb.region.elbBackendContext(self.pool.ID)
+	_, err := self.pool.lb.region.ecsClient.ElbBackends.DeleteInContext(ctx, self.ID, nil)
+	return err
+}
+
+func (self *SRegion) elbBackendContext(poolId string) *modules.SManagerContext {
+	return &modules.SManagerContext{InstanceManager: self.ecsClient.ElbBackendGroup, InstanceId: poolId}
+}
+
+func (self *SRegion) getPortsByIp(address string) ([]Port, error) {
+	ports := make([]Port, 0)
+	querys := map[string]string{"fixed_ips": "ip_address=" + address}
+	err := doListAllWithMarker(self.ecsClient.Port.List, querys, &ports)
+	return ports, err
+}
+
+func (self *SRegion) GetElbBackends(poolId string) ([]SElbBackend, error) {
+	members := make([]SElbBackend, 0)
+	ctx := self.elbBackendContext(poolId)
+	err := DoListInContext(self.ecsClient.ElbBackends.ListInContext, ctx, map[string]string{}, &members)
+	return members, err
+}
+
+func (self *SRegion) GetElbBackend(poolId, memberId string) (*SElbBackend, error) {
+	member := &SElbBackend{}
+	ret, err := self.ecsClient.ElbBackends.GetInContext(self.elbBackendContext(poolId), memberId, nil)
+	if err := unmarshalResult(ret, err, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (self *SRegion) CreateElbBackend(pool *SElbBackendGroup, serverId string, weight int, port int) (*SElbBackend, error) {
+	ports, err := self.GetPorts(serverId)
+	if err != nil {
+		return nil, err
+	}
+	// 优先使用与vip位于同一子网的网卡地址
+	var address *PortIP
+	for i := range ports {
+		for j := range ports[i].FixedIps {
+			if address == nil || ports[i].FixedIps[j].SubnetID == pool.lb.VipSubnetID {
+				address = &ports[i].FixedIps[j]
+			}
+		}
+	}
+	if address == nil {
+		return nil, fmt.Errorf("failed to find ip address of server %s", serverId)
+	}
+	params := map[string]map[string]interface{}{
+		"member": {
+			"address":       address.IpAddress,
+			"subnet_id":     address.SubnetID,
+			"protocol_port": port,
+			"weight":        weight,
+		},
+	}
+	member := &SElbBackend{pool: pool, serverId: serverId}
+	ret, err := self.ecsClient.ElbBackends.CreateInContext(self.elbBackendContext(pool.ID), jsonutils.Marshal(params))
+	if err := unmarshalResult(ret, err, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
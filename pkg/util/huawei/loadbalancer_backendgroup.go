@@ -0,0 +1,367 @@
+package huawei
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SElbSessionPersistence struct {
+	Type               string `json:"type"`
+	CookieName         string `json:"cookie_name"`
+	PersistenceTimeout int    `json:"persistence_timeout"`
+}
+
+// https://support.huaweicloud.com/api-elb/zh-cn_topic_0096561551.html
+type SElbBackendGroup struct {
+	lb *SLoadbalancer
+	hc *SElbHealthCheck
+
+	ID                 string                  `json:"id"`
+	Name               string                  `json:"name"`
+	Description        string                  `json:"description"`
+	TenantID           string                  `json:"tenant_id"`
+	ProjectID          string                  `json:"project_id"`
+	Protocol           string                  `json:"protocol"`
+	LbAlgorithm        string                  `json:"lb_algorithm"`
+	AdminStateUp       bool                    `json:"admin_state_up"`
+	SessionPersistence *SElbSessionPersistence `json:"session_persistence"`
+	HealthmonitorID    string                  `json:"healthmonitor_id"`
+	Members            []SElbRef               `json:"members"`
+	Listeners          []SElbRef               `json:"listeners"`
+	Loadbalancers      []SElbRef               `json:"loadbalancers"`
+}
+
+// https://support.huaweicloud.com/api-elb/zh-cn_topic_0096561565.html
+type SElbHealthCheck struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Delay         int       `json:"delay"`
+	Timeout       int       `json:"timeout"`
+	MaxRetries    int       `json:"max_retries"`
+	MonitorPort   int       `json:"monitor_port"`
+	DomainName    string    `json:"domain_name"`
+	HttpMethod    string    `json:"http_method"`
+	UrlPath       string    `json:"url_path"`
+	ExpectedCodes string    `json:"expected_codes"`
+	AdminStateUp  bool      `json:"admin_state_up"`
+	Pools         []SElbRef `json:"pools"`
+}
+
+func (self *SElbBackendGroup) GetId() string {
+	return self.ID
+}
+
+func (self *SElbBackendGroup) GetName() string {
+	if len(self.Name) == 0 {
+		return self.ID
+	}
+	return self.Name
+}
+
+func (self *SElbBackendGroup) GetGlobalId() string {
+	return self.ID
+}
+
+func (self *SElbBackendGroup) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbBackendGroup) Refresh() error {
+	pool, err := self.lb.region.GetElbBackendGroup(self.ID)
+	if err != nil {
+		return err
+	}
+	self.hc = nil
+	return jsonutils.Update(self, pool)
+}
+
+func (self *SElbBackendGroup) IsEmulated() bool {
+	return false
+}
+
+func (self *SElbBackendGroup) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SElbBackendGroup) GetProjectId() string {
+	return ""
+}
+
+func (self *SElbBackendGroup) IsDefault() bool {
+	return false
+}
+
+func (self *SElbBackendGroup) GetType() string {
+	return api.LB_BACKENDGROUP_TYPE_NORMAL
+}
+
+func (self *SElbBackendGroup) GetILoadbalancerBackends() ([]cloudprovider.ICloudLoadbalancerBackend, error) {
+	members, err := self.lb.region.GetElbBackends(self.ID)
+	if err != nil {
+		return nil, err
+	}
+	ibackends := make([]cloudprovider.ICloudLoadbalancerBackend, len(members))
+	for i := range members {
+		members[i].pool = self
+		ibackends[i] = &members[i]
+	}
+	return ibackends, nil
+}
+
+func (self *SElbBackendGroup) AddBackendServer(serverId string, weight int, port int) (cloudprovider.ICloudLoadbalancerBackend, error) {
+	member, err := self.lb.region.CreateElbBackend(self, serverId, weight, port)
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (self *SElbBackendGroup) RemoveBackendServer(serverId string, weight int, port int) error {
+	members, err := self.lb.region.GetElbBackends(self.ID)
+	if err != nil {
+		return err
+	}
+	for i := range members {
+		members[i].pool = self
+		if members[i].ProtocolPort == port && members[i].GetBackendId() == serverId {
+			return members[i].Delete()
+		}
+	}
+	return nil
+}
+
+// 删除后端服务器组前需要先删除健康检查及后端服务器
+func (self *SElbBackendGroup) Delete() error {
+	if len(self.HealthmonitorID) > 0 {
+		err := DoDelete(self.lb.region.ecsClient.ElbHealthCheck.Delete, self.HealthmonitorID, nil, nil)
+		if err != nil && err != cloudprovider.ErrNotFound {
+			return err
+		}
+	}
+	members, err := self.lb.region.GetElbBackends(self.ID)
+	if err != nil {
+		return err
+	}
+	for i := range members {
+		members[i].pool = self
+		if err := members[i].Delete(); err != nil {
+			return err
+		}
+	}
+	return DoDelete(self.lb.region.ecsClient.ElbBackendGroup.Delete, self.ID, nil, nil)
+}
+
+func (self *SElbBackendGroup) Sync(name string) error {
+	params := map[string]map[string]interface{}{
+		"pool": {
+			"name": name,
+		},
+	}
+	_, err := self.lb.region.ecsClient.ElbBackendGroup.Update(self.ID, jsonutils.Marshal(params))
+	return err
+}
+
+func (self *SElbBackendGroup) getHealthCheck() *SElbHealthCheck {
+	if self.hc == nil && len(self.HealthmonitorID) > 0 {
+		hc, err := self.lb.region.GetElbHealthCheck(self.HealthmonitorID)
+		if err != nil {
+			log.Errorf("failed to get health check %s of backend group %s: %v", self.HealthmonitorID, self.ID, err)
+			return nil
+		}
+		self.hc = hc
+	}
+	return self.hc
+}
+
+func (self *SElbBackendGroup) getScheduler() string {
+	switch self.LbAlgorithm {
+	case "LEAST_CONNECTIONS":
+		return api.LB_SCHEDULER_WLC
+	case "SOURCE_IP":
+		return api.LB_SCHEDULER_SCH
+	default:
+		return api.LB_SCHEDULER_WRR
+	}
+}
+
+func elbLbAlgorithm(scheduler string) string {
+	switch scheduler {
+	case api.LB_SCHEDULER_WLC:
+		return "LEAST_CONNECTIONS"
+	case api.LB_SCHEDULER_SCH, api.LB_SCHEDULER_TCH:
+		return "SOURCE_IP"
+	default:
+		return "ROUND_ROBIN"
+	}
+}
+
+// 后端服务器组协议需要与监听协议匹配
+func elbPoolProtocol(listenerProtocol string) string {
+	switch listenerProtocol {
+	case ELB_PROTOCOL_TCP, ELB_PROTOCOL_UDP:
+		return listenerProtocol
+	default:
+		return ELB_PROTOCOL_HTTP
+	}
+}
+
+// 监听的调度算法与会话保持同步到默认后端服务器组
+func (self *SElbBackendGroup) syncListener(listener *cloudprovider.SLoadbalancerListener) error {
+	params := map[string]interface{}{}
+	if len(listener.Scheduler) > 0 {
+		params["lb_algorithm"] = elbLbAlgorithm(listener.Scheduler)
+	}
+	if listener.StickySession == api.LB_BOOL_ON {
+		persistence := map[string]interface{}{}
+		switch {
+		case self.Protocol != ELB_PROTOCOL_HTTP:
+			persistence["type"] = "SOURCE_IP"
+		case listener.StickySessionType == api.LB_STICKY_SESSION_TYPE_SERVER:
+			persistence["type"] = "APP_COOKIE"
+			persistence["cookie_name"] = listener.StickySessionCookie
+		default:
+			persistence["type"] = "HTTP_COOKIE"
+		}
+		if persistence["type"] != "APP_COOKIE" && listener.StickySessionCookieTimeout > 0 {
+			// 会话保持时间以分钟为单位, 向上取整
+			persistence["persistence_timeout"] = (listener.StickySessionCookieTimeout + 59) / 60
+		}
+		params["session_persistence"] = persistence
+	}
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.Marshal(params), "pool")
+	if listener.StickySession != api.LB_BOOL_ON {
+		// 关闭会话保持需要显式传null
+		body.Add(jsonutils.JSONNull, "pool", "session_persistence")
+	}
+	_, err := self.lb.region.ecsClient.ElbBackendGroup.Update(self.ID, body)
+	return err
+}
+
+func (self *SElbBackendGroup) syncHealthCheck(listener *cloudprovider.SLoadbalancerListener) error {
+	region := self.lb.region
+	if listener.HealthCheck != api.LB_BOOL_ON {
+		if len(self.HealthmonitorID) == 0 {
+			return nil
+		}
+		return DoDelete(region.ecsClient.ElbHealthCheck.Delete, self.HealthmonitorID, nil, nil)
+	}
+
+	params := map[string]interface{}{}
+	if listener.HealthCheckInterval > 0 {
+		params["delay"] = listener.HealthCheckInterval
+	}
+	if listener.HealthCheckTimeout > 0 {
+		params["timeout"] = listener.HealthCheckTimeout
+	}
+	if listener.HealthCheckRise > 0 {
+		params["max_retries"] = listener.HealthCheckRise
+	}
+	hcType := elbHealthCheckType(self.Protocol)
+	if hcType == "HTTP" {
+		if len(listener.HealthCheckDomain) > 0 {
+			params["domain_name"] = listener.HealthCheckDomain
+		}
+		if len(listener.HealthCheckURI) > 0 {
+			params["url_path"] = listener.HealthCheckURI
+		}
+		if len(listener.HealthCheckHttpCode) > 0 {
+			params["expected_codes"] = elbHealthCheckCodeToExpectedCodes(listener.HealthCheckHttpCode)
+		}
+	}
+
+	if hc := self.getHealthCheck(); hc != nil && hc.Type == hcType {
+		_, err := region.ecsClient.ElbHealthCheck.Update(hc.ID, jsonutils.Marshal(map[string]interface{}{"healthmonitor": params}))
+		return err
+	} else if hc != nil {
+		// 健康检查类型不能修改, 需要重建
+		if err := DoDelete(region.ecsClient.ElbHealthCheck.Delete, hc.ID, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	params["pool_id"] = self.ID
+	params["type"] = hcType
+	for k, v := range map[string]int{"delay": 5, "timeout": 10, "max_retries": 3} {
+		if _, ok := params[k]; !ok {
+			params[k] = v
+		}
+	}
+	hc := &SElbHealthCheck{}
+	err := DoCreate(region.ecsClient.ElbHealthCheck.Create, jsonutils.Marshal(map[string]interface{}{"healthmonitor": params}), hc)
+	if err != nil {
+		return err
+	}
+	self.HealthmonitorID = hc.ID
+	self.hc = hc
+	return nil
+}
+
+// 健康检查类型跟随后端服务器组协议
+func elbHealthCheckType(poolProtocol string) string {
+	switch poolProtocol {
+	case ELB_PROTOCOL_HTTP:
+		return "HTTP"
+	case ELB_PROTOCOL_UDP:
+		return "UDP_CONNECT"
+	default:
+		return "TCP"
+	}
+}
+
+func (self *SRegion) GetElbBackendGroups(lbId string) ([]SElbBackendGroup, error) {
+	querys := map[string]string{}
+	if len(lbId) > 0 {
+		querys["loadbalancer_id"] = lbId
+	}
+	pools := make([]SElbBackendGroup, 0)
+	err := doListAll(self.ecsClient.ElbBackendGroup.List, querys, &pools)
+	return pools, err
+}
+
+func (self *SRegion) GetElbBackendGroup(poolId string) (*SElbBackendGroup, error) {
+	pool := &SElbBackendGroup{}
+	err := DoGet(self.ecsClient.ElbBackendGroup.Get, poolId, nil, pool)
+	if err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+func (self *SRegion) GetElbHealthCheck(hcId string) (*SElbHealthCheck, error) {
+	hc := &SElbHealthCheck{}
+	err := DoGet(self.ecsClient.ElbHealthCheck.Get, hcId, nil, hc)
+	if err != nil {
+		return nil, err
+	}
+	return hc, nil
+}
+
+// 后端服务器组创建时还不知道会被哪个监听使用, 统一使用HTTP协议, 只能被HTTP及HTTPS监听引用
+func (self *SRegion) CreateElbBackendGroup(lb *SLoadbalancer, group *cloudprovider.SLoadbalancerBackendGroup) (*SElbBackendGroup, error) {
+	params := map[string]map[string]interface{}{
+		"pool": {
+			"name":            group.Name,
+			"loadbalancer_id": lb.ID,
+			"protocol":        ELB_PROTOCOL_HTTP,
+			"lb_algorithm":    "ROUND_ROBIN",
+		},
+	}
+	pool := &SElbBackendGroup{lb: lb}
+	err := DoCreate(self.ecsClient.ElbBackendGroup.Create, jsonutils.Marshal(params), pool)
+	if err != nil {
+		return nil, err
+	}
+	for _, backend := range group.Backends {
+		if _, err := self.CreateElbBackend(pool, backend.ExternalID, backend.Weight, backend.Port); err != nil {
+			return nil, fmt.Errorf("backend group %s created, but failed to add backend %s: %v", pool.ID, backend.ExternalID, err)
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,181 @@
+package huawei
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+// https://support.huaweicloud.com/api-elb/zh-cn_topic_0096561584.html
+// 只同步服务器证书, CA证书用于双向认证, 不对应本地证书
+type SElbCertificate struct {
+	region *SRegion
+	cert   *x509.Certificate
+
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Domain      string `json:"domain"`
+	Certificate string `json:"certificate"`
+	PrivateKey  string `json:"private_key"`
+	ExpireTime  string `json:"expire_time"`
+	CreateTime  string `json:"create_time"`
+	UpdateTime  string `json:"update_time"`
+}
+
+func (self *SElbCertificate) GetId() string {
+	return self.ID
+}
+
+func (self *SElbCertificate) GetName() string {
+	if len(self.Name) == 0 {
+		return self.ID
+	}
+	return self.Name
+}
+
+func (self *SElbCertificate) GetGlobalId() string {
+	return self.ID
+}
+
+func (self *SElbCertificate) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbCertificate) Refresh() error {
+	cert, err := self.region.GetElbCertificate(self.ID)
+	if err != nil {
+		return err
+	}
+	self.cert = nil
+	return jsonutils.Update(self, cert)
+}
+
+func (self *SElbCertificate) IsEmulated() bool {
+	return false
+}
+
+func (self *SElbCertificate) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SElbCertificate) GetProjectId() string {
+	return ""
+}
+
+func (self *SElbCertificate) getCert() *x509.Certificate {
+	if self.cert == nil {
+		block, _ := pem.Decode([]byte(self.Certificate))
+		if block == nil {
+			log.Errorf("invalid certificate content of %s", self.ID)
+			return nil
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			log.Errorf("failed to parse certificate %s: %v", self.ID, err)
+			return nil
+		}
+		self.cert = cert
+	}
+	return self.cert
+}
+
+func (self *SElbCertificate) GetCommonName() string {
+	if cert := self.getCert(); cert != nil {
+		return cert.Subject.CommonName
+	}
+	return self.Domain
+}
+
+func (self *SElbCertificate) GetSubjectAlternativeNames() string {
+	if cert := self.getCert(); cert != nil {
+		return strings.Join(cert.DNSNames, ",")
+	}
+	return ""
+}
+
+func (self *SElbCertificate) GetFingerprint() string {
+	if cert := self.getCert(); cert != nil {
+		d := sha256.Sum256(cert.Raw)
+		return api.LB_TLS_CERT_FINGERPRINT_ALGO_SHA256 + ":" + hex.EncodeToString(d[:])
+	}
+	return ""
+}
+
+func (self *SElbCertificate) GetExpireTime() time.Time {
+	if cert := self.getCert(); cert != nil {
+		return cert.NotAfter
+	}
+	return time.Time{}
+}
+
+func (self *SElbCertificate) Sync(name, privateKey, publickKey string) error {
+	params := map[string]interface{}{}
+	if len(name) > 0 {
+		params["name"] = name
+	}
+	if len(privateKey) > 0 && len(publickKey) > 0 {
+		params["private_key"] = privateKey
+		params["certificate"] = publickKey
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	_, err := self.region.ecsClient.ElbCertificates.Update(self.ID, jsonutils.Marshal(params))
+	return err
+}
+
+func (self *SElbCertificate) Delete() error {
+	return DoDelete(self.region.ecsClient.ElbCertificates.Delete, self.ID, nil, nil)
+}
+
+func (self *SRegion) GetElbCertificates() ([]SElbCertificate, error) {
+	certs := make([]SElbCertificate, 0)
+	err := doListAll(self.ecsClient.ElbCertificates.List, map[string]string{}, &certs)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]SElbCertificate, 0, len(certs))
+	for i := range certs {
+		if certs[i].Type == "client" {
+			continue
+		}
+		certs[i].region = self
+		ret = append(ret, certs[i])
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetElbCertificate(certId string) (*SElbCertificate, error) {
+	cert := &SElbCertificate{region: self}
+	err := DoGet(self.ecsClient.ElbCertificates.Get, certId, nil, cert)
+	if err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+func (self *SRegion) CreateElbCertificate(cert *cloudprovider.SLoadbalancerCertificate) (*SElbCertificate, error) {
+	params := map[string]interface{}{
+		"name":        cert.Name,
+		"type":        "server",
+		"certificate": cert.Certificate,
+		"private_key": cert.PrivateKey,
+	}
+	ret := &SElbCertificate{region: self}
+	err := DoCreate(self.ecsClient.ElbCertificates.Create, jsonutils.Marshal(params), ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,560 @@
+package huawei
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+const (
+	ELB_PROTOCOL_TCP              = "TCP"
+	ELB_PROTOCOL_UDP              = "UDP"
+	ELB_PROTOCOL_HTTP             = "HTTP"
+	ELB_PROTOCOL_TERMINATED_HTTPS = "TERMINATED_HTTPS"
+)
+
+var elbTlsCiphersPolicies = map[string]string{
+	api.LB_TLS_CIPHER_POLICY_1_0:        "tls-1-0",
+	api.LB_TLS_CIPHER_POLICY_1_1:        "tls-1-1",
+	api.LB_TLS_CIPHER_POLICY_1_2:        "tls-1-2",
+	api.LB_TLS_CIPHER_POLICY_1_2_strict: "tls-1-2-strict",
+}
+
+// https://support.huaweicloud.com/api-elb/zh-cn_topic_0096561545.html
+type SElbListener struct {
+	lb   *SLoadbalancer
+	pool *SElbBackendGroup
+	acl  *SElbACL
+
+	ID                     string          `json:"id"`
+	Name                   string          `json:"name"`
+	Description            string          `json:"description"`
+	TenantID               string          `json:"tenant_id"`
+	ProjectID              string          `json:"project_id"`
+	Protocol               string          `json:"protocol"`
+	ProtocolPort           int             `json:"protocol_port"`
+	AdminStateUp           bool            `json:"admin_state_up"`
+	ConnectionLimit        int             `json:"connection_limit"`
+	Http2Enable            bool            `json:"http2_enable"`
+	DefaultPoolID          string          `json:"default_pool_id"`
+	DefaultTlsContainerRef string          `json:"default_tls_container_ref"`
+	SniContainerRefs       []string        `json:"sni_container_refs"`
+	TlsCiphersPolicy       string          `json:"tls_ciphers_policy"`
+	InsertHeaders          map[string]bool `json:"insert_headers"`
+	Loadbalancers          []SElbRef       `json:"loadbalancers"`
+	CreatedAt              string          `json:"created_at"`
+	UpdatedAt              string          `json:"updated_at"`
+}
+
+func (self *SElbListener) GetId() string {
+	return self.ID
+}
+
+func (self *SElbListener) GetName() string {
+	if len(self.Name) == 0 {
+		return self.ID
+	}
+	return self.Name
+}
+
+func (self *SElbListener) GetGlobalId() string {
+	return self.ID
+}
+
+func (self *SElbListener) GetStatus() string {
+	if !self.AdminStateUp {
+		return api.LB_STATUS_DISABLED
+	}
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbListener) Refresh() error {
+	listener, err := self.lb.region.GetElbListener(self.ID)
+	if err != nil {
+		return err
+	}
+	self.pool = nil
+	self.acl = nil
+	return jsonutils.Update(self, listener)
+}
+
+func (self *SElbListener) IsEmulated() bool {
+	return false
+}
+
+func (self *SElbListener) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SElbListener) GetProjectId() string {
+	return ""
+}
+
+func (self *SElbListener) getPool() *SElbBackendGroup {
+	if self.pool == nil && len(self.DefaultPoolID) > 0 {
+		pool, err := self.lb.region.GetElbBackendGroup(self.DefaultPoolID)
+		if err != nil {
+			log.Errorf("failed to get default backend group %s of listener %s: %v", self.DefaultPoolID, self.ID, err)
+			return nil
+		}
+		pool.lb = self.lb
+		self.pool = pool
+	}
+	return self.pool
+}
+
+func (self *SElbListener) getHealthCheck() *SElbHealthCheck {
+	if pool := self.getPool(); pool != nil {
+		return pool.getHealthCheck()
+	}
+	return nil
+}
+
+func (self *SElbListener) getAcl() *SElbACL {
+	if self.acl == nil {
+		acls, err := self.lb.region.GetElbACLs(self.ID)
+		if err != nil {
+			log.Errorf("failed to get whitelist of listener %s: %v", self.ID, err)
+			return nil
+		}
+		if len(acls) > 0 {
+			acls[0].region = self.lb.region
+			self.acl = &acls[0]
+		}
+	}
+	return self.acl
+}
+
+func (self *SElbListener) GetListenerType() string {
+	switch self.Protocol {
+	case ELB_PROTOCOL_HTTP:
+		return api.LB_LISTENER_TYPE_HTTP
+	case ELB_PROTOCOL_TERMINATED_HTTPS:
+		return api.LB_LISTENER_TYPE_HTTPS
+	case ELB_PROTOCOL_UDP:
+		return api.LB_LISTENER_TYPE_UDP
+	default:
+		return api.LB_LISTENER_TYPE_TCP
+	}
+}
+
+func (self *SElbListener) GetListenerPort() int {
+	return self.ProtocolPort
+}
+
+func (self *SElbListener) GetScheduler() string {
+	if pool := self.getPool(); pool != nil {
+		return pool.getScheduler()
+	}
+	return ""
+}
+
+func (self *SElbListener) GetAclStatus() string {
+	if acl := self.getAcl(); acl != nil && acl.EnableWhitelist {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (self *SElbListener) GetAclType() string {
+	return api.LB_ACL_TYPE_WHITE
+}
+
+func (self *SElbListener) GetAclId() string {
+	if acl := self.getAcl(); acl != nil {
+		return acl.GetGlobalId()
+	}
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheck() string {
+	if hc := self.getHealthCheck(); hc != nil && hc.AdminStateUp {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (self *SElbListener) GetHealthCheckType() string {
+	if hc := self.getHealthCheck(); hc != nil {
+		switch hc.Type {
+		case "HTTP":
+			return api.LB_HEALTH_CHECK_HTTP
+		case "UDP_CONNECT":
+			return api.LB_HEALTH_CHECK_UDP
+		}
+	}
+	return api.LB_HEALTH_CHECK_TCP
+}
+
+func (self *SElbListener) GetHealthCheckTimeout() int {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.Timeout
+	}
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckInterval() int {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.Delay
+	}
+	return 0
+}
+
+// 华为云健康检查的成功与失败次数相同
+func (self *SElbListener) GetHealthCheckRise() int {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.MaxRetries
+	}
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckFail() int {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.MaxRetries
+	}
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckReq() string {
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheckExp() string {
+	return ""
+}
+
+func (self *SElbListener) GetBackendGroupId() string {
+	return self.DefaultPoolID
+}
+
+func (self *SElbListener) GetBackendServerPort() int {
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckDomain() string {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.DomainName
+	}
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheckURI() string {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.UrlPath
+	}
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheckCode() string {
+	if hc := self.getHealthCheck(); hc != nil && len(hc.ExpectedCodes) > 0 {
+		return elbExpectedCodesToHealthCheckCode(hc.ExpectedCodes)
+	}
+	return ""
+}
+
+func (self *SElbListener) CreateILoadBalancerListenerRule(rule *cloudprovider.SLoadbalancerListenerRule) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	policy, err := self.lb.region.CreateElbListenerPolicy(self, rule)
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (self *SElbListener) GetILoadBalancerListenerRuleById(ruleId string) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	policy, err := self.lb.region.GetElbListenerPolicy(ruleId)
+	if err != nil {
+		return nil, err
+	}
+	policy.listener = self
+	return policy, nil
+}
+
+func (self *SElbListener) GetILoadbalancerListenerRules() ([]cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	policies, err := self.lb.region.GetElbListenerPolicies(self.ID)
+	if err != nil {
+		return nil, err
+	}
+	irules := make([]cloudprovider.ICloudLoadbalancerListenerRule, len(policies))
+	for i := range policies {
+		policies[i].listener = self
+		irules[i] = &policies[i]
+	}
+	return irules, nil
+}
+
+func (self *SElbListener) GetStickySession() string {
+	if pool := self.getPool(); pool != nil && pool.SessionPersistence != nil {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (self *SElbListener) GetStickySessionType() string {
+	if pool := self.getPool(); pool != nil && pool.SessionPersistence != nil && pool.SessionPersistence.Type == "APP_COOKIE" {
+		return api.LB_STICKY_SESSION_TYPE_SERVER
+	}
+	return api.LB_STICKY_SESSION_TYPE_INSERT
+}
+
+func (self *SElbListener) GetStickySessionCookie() string {
+	if pool := self.getPool(); pool != nil && pool.SessionPersistence != nil {
+		return pool.SessionPersistence.CookieName
+	}
+	return ""
+}
+
+// 会话保持时间以分钟为单位
+func (self *SElbListener) GetStickySessionCookieTimeout() int {
+	if pool := self.getPool(); pool != nil && pool.SessionPersistence != nil {
+		return pool.SessionPersistence.PersistenceTimeout * 60
+	}
+	return 0
+}
+
+// 七层监听总是会添加X-Forwarded-For头
+func (self *SElbListener) XForwardedForEnabled() bool {
+	return self.Protocol == ELB_PROTOCOL_HTTP || self.Protocol == ELB_PROTOCOL_TERMINATED_HTTPS
+}
+
+func (self *SElbListener) GzipEnabled() bool {
+	return false
+}
+
+func (self *SElbListener) GetCertificateId() string {
+	return self.DefaultTlsContainerRef
+}
+
+func (self *SElbListener) GetTLSCipherPolicy() string {
+	for k, v := range elbTlsCiphersPolicies {
+		if v == self.TlsCiphersPolicy {
+			return k
+		}
+	}
+	return ""
+}
+
+func (self *SElbListener) HTTP2Enabled() bool {
+	return self.Http2Enable
+}
+
+// 共享型负载均衡的监听器admin_state_up只能为true
+func (self *SElbListener) Start() error {
+	return nil
+}
+
+func (self *SElbListener) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElbListener) Sync(listener *cloudprovider.SLoadbalancerListener) error {
+	params := map[string]interface{}{
+		"listener": elbListenerParams(listener),
+	}
+	_, err := self.lb.region.ecsClient.ElbListeners.Update(self.ID, jsonutils.Marshal(params))
+	if err != nil {
+		return err
+	}
+	if err := self.Refresh(); err != nil {
+		return err
+	}
+	return self.sync(listener)
+}
+
+func (self *SElbListener) Delete() error {
+	policies, err := self.lb.region.GetElbListenerPolicies(self.ID)
+	if err != nil {
+		return err
+	}
+	for i := range policies {
+		policies[i].listener = self
+		if err := policies[i].Delete(); err != nil {
+			return err
+		}
+	}
+	if acl := self.getAcl(); acl != nil {
+		if err := acl.Delete(); err != nil {
+			return err
+		}
+	}
+	return DoDelete(self.lb.region.ecsClient.ElbListeners.Delete, self.ID, nil, nil)
+}
+
+// 调度算法, 会话保持与健康检查属于默认后端服务器组, 访问控制为监听器的白名单
+func (self *SElbListener) sync(listener *cloudprovider.SLoadbalancerListener) error {
+	if pool := self.getPool(); pool != nil {
+		if err := pool.syncListener(listener); err != nil {
+			return err
+		}
+		if err := pool.syncHealthCheck(listener); err != nil {
+			return err
+		}
+	}
+	return self.syncAcl(listener)
+}
+
+// 白名单属于监听器, 引用其它监听器的白名单时复制其条目
+func (self *SElbListener) syncAcl(listener *cloudprovider.SLoadbalancerListener) error {
+	acl := self.getAcl()
+	enable := listener.AccessControlListStatus == api.LB_BOOL_ON
+	if !enable {
+		if acl == nil || !acl.EnableWhitelist {
+			return nil
+		}
+		return acl.update(false, acl.Whitelist)
+	}
+	whitelist := ""
+	if acl != nil {
+		whitelist = acl.Whitelist
+	}
+	if len(listener.AccessControlListID) > 0 && (acl == nil || listener.AccessControlListID != acl.ID) {
+		ref, err := self.lb.region.GetElbACL(listener.AccessControlListID)
+		if err != nil {
+			return fmt.Errorf("failed to find whitelist %s: %v", listener.AccessControlListID, err)
+		}
+		whitelist = ref.Whitelist
+	}
+	if acl == nil {
+		_, err := self.lb.region.CreateElbACL(self.ID, whitelist)
+		return err
+	}
+	return acl.update(true, whitelist)
+}
+
+func elbListenerParams(listener *cloudprovider.SLoadbalancerListener) map[string]interface{} {
+	params := map[string]interface{}{
+		"name":        listener.Name,
+		"description": listener.Description,
+	}
+	if len(listener.BackendGroupID) > 0 {
+		params["default_pool_id"] = listener.BackendGroupID
+	}
+	if listener.ListenerType == api.LB_LISTENER_TYPE_HTTPS {
+		if len(listener.CertificateID) > 0 {
+			params["default_tls_container_ref"] = listener.CertificateID
+		}
+		params["http2_enable"] = listener.EnableHTTP2
+		if policy, ok := elbTlsCiphersPolicies[listener.TLSCipherPolicy]; ok {
+			params["tls_ciphers_policy"] = policy
+		}
+	}
+	return params
+}
+
+func elbListenerProtocol(listenerType string) string {
+	switch listenerType {
+	case api.LB_LISTENER_TYPE_HTTP:
+		return ELB_PROTOCOL_HTTP
+	case api.LB_LISTENER_TYPE_HTTPS:
+		return ELB_PROTOCOL_TERMINATED_HTTPS
+	case api.LB_LISTENER_TYPE_UDP:
+		return ELB_PROTOCOL_UDP
+	default:
+		return ELB_PROTOCOL_TCP
+	}
+}
+
+// http_2xx,http_3xx => 200-399
+func elbHealthCheckCodeToExpectedCodes(codes string) string {
+	low, high := 0, 0
+	for _, code := range strings.Split(codes, ",") {
+		code = strings.TrimSpace(code)
+		if len(code) != len(api.LB_HEALTH_CHECK_HTTP_CODE_2xx) {
+			continue
+		}
+		c := int(code[5]-'0') * 100
+		if c < 100 || c > 500 {
+			continue
+		}
+		if low == 0 || c < low {
+			low = c
+		}
+		if c+99 > high {
+			high = c + 99
+		}
+	}
+	if low == 0 {
+		return "200-399"
+	}
+	return fmt.Sprintf("%d-%d", low, high)
+}
+
+// 200,202 或 200-399 => http_2xx,http_3xx
+func elbExpectedCodesToHealthCheckCode(expected string) string {
+	classes := map[int]bool{}
+	for _, seg := range strings.Split(expected, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(seg), "-", 2)
+		low, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		high := low
+		if len(bounds) == 2 {
+			high, err = strconv.Atoi(bounds[1])
+			if err != nil {
+				continue
+			}
+		}
+		for c := low / 100; c <= high/100; c++ {
+			classes[c] = true
+		}
+	}
+	codes := []string{}
+	for c := 1; c <= 5; c++ {
+		if classes[c] {
+			codes = append(codes, fmt.Sprintf("http_%dxx", c))
+		}
+	}
+	return strings.Join(codes, ",")
+}
+
+func (self *SRegion) GetElbListeners(lbId string) ([]SElbListener, error) {
+	querys := map[string]string{}
+	if len(lbId) > 0 {
+		querys["loadbalancer_id"] = lbId
+	}
+	listeners := make([]SElbListener, 0)
+	err := doListAll(self.ecsClient.ElbListeners.List, querys, &listeners)
+	return listeners, err
+}
+
+func (self *SRegion) GetElbListener(listenerId string) (*SElbListener, error) {
+	listener := &SElbListener{}
+	err := DoGet(self.ecsClient.ElbListeners.Get, listenerId, nil, listener)
+	if err != nil {
+		return nil, err
+	}
+	return listener, nil
+}
+
+// 监听器协议需要与默认后端服务器组的协议一致: TCP/TCP, UDP/UDP, HTTP及TERMINATED_HTTPS/HTTP
+func (self *SRegion) CreateElbListener(lb *SLoadbalancer, listener *cloudprovider.SLoadbalancerListener) (*SElbListener, error) {
+	protocol := elbListenerProtocol(listener.ListenerType)
+	if len(listener.BackendGroupID) > 0 {
+		pool, err := self.GetElbBackendGroup(listener.BackendGroupID)
+		if err != nil {
+			return nil, err
+		}
+		if elbPoolProtocol(protocol) != pool.Protocol {
+			return nil, fmt.Errorf("backend group %s protocol %s is incompatible with listener protocol %s", pool.GetName(), pool.Protocol, protocol)
+		}
+	}
+	params := elbListenerParams(listener)
+	params["loadbalancer_id"] = lb.ID
+	params["protocol"] = protocol
+	params["protocol_port"] = listener.ListenerPort
+	ret := &SElbListener{lb: lb}
+	err := DoCreate(self.ecsClient.ElbListeners.Create, jsonutils.Marshal(map[string]interface{}{"listener": params}), ret)
+	if err != nil {
+		return nil, err
+	}
+	if err := ret.sync(listener); err != nil {
+		return nil, fmt.Errorf("listener %s created, but failed to sync: %v", ret.ID, err)
+	}
+	return ret, nil
+}
@@ -0,0 +1,191 @@
+package huawei
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/util/huawei/client/modules"
+)
+
+// https://support.huaweicloud.com/api-elb/zh-cn_topic_0116649234.html
+// 转发策略只用于七层监听, 域名与路径通过转发规则匹配
+type SElbListenerPolicy struct {
+	listener *SElbListener
+	rules    []SElbListenerPolicyRule
+
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description"`
+	TenantID           string    `json:"tenant_id"`
+	ProjectID          string    `json:"project_id"`
+	ListenerID         string    `json:"listener_id"`
+	Action             string    `json:"action"`
+	RedirectPoolID     string    `json:"redirect_pool_id"`
+	RedirectListenerID string    `json:"redirect_listener_id"`
+	Position           int       `json:"position"`
+	AdminStateUp       bool      `json:"admin_state_up"`
+	ProvisioningStatus string    `json:"provisioning_status"`
+	Rules              []SElbRef `json:"rules"`
+}
+
+// https://support.huaweicloud.com/api-elb/zh-cn_topic_0116649240.html
+type SElbListenerPolicyRule struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	CompareType string `json:"compare_type"`
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Invert      bool   `json:"invert"`
+}
+
+func (self *SElbListenerPolicy) GetId() string {
+	return self.ID
+}
+
+func (self *SElbListenerPolicy) GetName() string {
+	if len(self.Name) == 0 {
+		return self.ID
+	}
+	return self.Name
+}
+
+func (self *SElbListenerPolicy) GetGlobalId() string {
+	return self.ID
+}
+
+func (self *SElbListenerPolicy) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbListenerPolicy) Refresh() error {
+	policy, err := self.listener.lb.region.GetElbListenerPolicy(self.ID)
+	if err != nil {
+		return err
+	}
+	self.rules = nil
+	return jsonutils.Update(self, policy)
+}
+
+func (self *SElbListenerPolicy) IsEmulated() bool {
+	return false
+}
+
+func (self *SElbListenerPolicy) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SElbListenerPolicy) GetProjectId() string {
+	return ""
+}
+
+func (self *SElbListenerPolicy) getRules() []SElbListenerPolicyRule {
+	if self.rules == nil {
+		rules, err := self.listener.lb.region.GetElbListenerPolicyRules(self.ID)
+		if err != nil {
+			log.Errorf("failed to get rules of l7policy %s: %v", self.ID, err)
+			return nil
+		}
+		self.rules = rules
+	}
+	return self.rules
+}
+
+func (self *SElbListenerPolicy) getRuleValue(ruleType string) string {
+	for _, rule := range self.getRules() {
+		if rule.Type == ruleType {
+			return rule.Value
+		}
+	}
+	return ""
+}
+
+func (self *SElbListenerPolicy) GetDomain() string {
+	return self.getRuleValue("HOST_NAME")
+}
+
+func (self *SElbListenerPolicy) GetPath() string {
+	return self.getRuleValue("PATH")
+}
+
+func (self *SElbListenerPolicy) GetBackendGroupId() string {
+	return self.RedirectPoolID
+}
+
+func (self *SElbListenerPolicy) Delete() error {
+	return DoDelete(self.listener.lb.region.ecsClient.ElbPolicies.Delete, self.ID, nil, nil)
+}
+
+func (self *SRegion) elbPolicyRuleContext(policyId string) *modules.SManagerContext {
+	return &modules.SManagerContext{InstanceManager: self.ecsClient.ElbPolicies, InstanceId: policyId}
+}
+
+func (self *SRegion) GetElbListenerPolicies(listenerId string) ([]SElbListenerPolicy, error) {
+	querys := map[string]string{}
+	if len(listenerId) > 0 {
+		querys["listener_id"] = listenerId
+	}
+	policies := make([]SElbListenerPolicy, 0)
+	err := doListAll(self.ecsClient.ElbPolicies.List, querys, &policies)
+	return policies, err
+}
+
+func (self *SRegion) GetElbListenerPolicy(policyId string) (*SElbListenerPolicy, error) {
+	policy := &SElbListenerPolicy{}
+	err := DoGet(self.ecsClient.ElbPolicies.Get, policyId, nil, policy)
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (self *SRegion) GetElbListenerPolicyRules(policyId string) ([]SElbListenerPolicyRule, error) {
+	rules := make([]SElbListenerPolicyRule, 0)
+	err := DoListInContext(self.ecsClient.ElbPolicyRules.ListInContext, self.elbPolicyRuleContext(policyId), map[string]string{}, &rules)
+	return rules, err
+}
+
+func (self *SRegion) CreateElbListenerPolicy(listener *SElbListener, rule *cloudprovider.SLoadbalancerListenerRule) (*SElbListenerPolicy, error) {
+	params := map[string]map[string]interface{}{
+		"l7policy": {
+			"name":             rule.Name,
+			"listener_id":      listener.ID,
+			"action":           "REDIRECT_TO_POOL",
+			"redirect_pool_id": rule.BackendGroupID,
+		},
+	}
+	policy := &SElbListenerPolicy{listener: listener}
+	err := DoCreate(self.ecsClient.ElbPolicies.Create, jsonutils.Marshal(params), policy)
+	if err != nil {
+		return nil, err
+	}
+	rules := map[string]string{}
+	if len(rule.Domain) > 0 {
+		rules["HOST_NAME"] = rule.Domain
+	}
+	if len(rule.Path) > 0 {
+		rules["PATH"] = rule.Path
+	}
+	ctx := self.elbPolicyRuleContext(policy.ID)
+	for ruleType, value := range rules {
+		compareType := "EQUAL_TO"
+		if ruleType == "PATH" {
+			compareType = "STARTS_WITH"
+		}
+		params := map[string]map[string]interface{}{
+			"rule": {
+				"type":         ruleType,
+				"compare_type": compareType,
+				"value":        value,
+			},
+		}
+		_, err := self.ecsClient.ElbPolicyRules.CreateInContext(ctx, jsonutils.Marshal(params))
+		if err != nil {
+			return nil, fmt.Errorf("l7policy %s created, but failed to add %s rule: %v", policy.ID, ruleType, err)
+		}
+	}
+	return policy, nil
+}
@@ -0,0 +1,133 @@
+package huawei
+
+import (
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+// https://support.huaweicloud.com/api-nat/nat_api_0013.html
+type SNatDEntry struct {
+	gateway *SNatGateway
+
+	ID                  string `json:"id"`
+	TenantID            string `json:"tenant_id"`
+	NatGatewayID        string `json:"nat_gateway_id"`
+	PortID              string `json:"port_id"`
+	PrivateIP           string `json:"private_ip"`
+	InternalServicePort int    `json:"internal_service_port"`
+	FloatingIPID        string `json:"floating_ip_id"`
+	FloatingIPAddress   string `json:"floating_ip_address"`
+	ExternalServicePort int    `json:"external_service_port"`
+	Protocol            string `json:"protocol"`
+	Status              string `json:"status"`
+	AdminStateUp        bool   `json:"admin_state_up"`
+	CreatedAt           string `json:"created_at"`
+}
+
+func (entry *SNatDEntry) GetId() string {
+	return entry.ID
+}
+
+func (entry *SNatDEntry) GetName() string {
+	return entry.ID
+}
+
+func (entry *SNatDEntry) GetGlobalId() string {
+	return entry.ID
+}
+
+func (entry *SNatDEntry) GetStatus() string {
+	return convertNatStatus(entry.Status)
+}
+
+func (entry *SNatDEntry) Refresh() error {
+	new, err := entry.gateway.vpc.region.GetNatDEntry(entry.ID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(entry, new)
+}
+
+func (entry *SNatDEntry) IsEmulated() bool {
+	return false
+}
+
+func (entry *SNatDEntry) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (entry *SNatDEntry) GetIpProtocol() string {
+	return entry.Protocol
+}
+
+func (entry *SNatDEntry) GetExternalIp() string {
+	return entry.FloatingIPAddress
+}
+
+func (entry *SNatDEntry) GetExternalPort() int {
+	return entry.ExternalServicePort
+}
+
+func (entry *SNatDEntry) GetInternalIp() string {
+	return entry.PrivateIP
+}
+
+func (entry *SNatDEntry) GetInternalPort() int {
+	return entry.InternalServicePort
+}
+
+func (entry *SNatDEntry) Delete() error {
+	return DoDelete(entry.gateway.vpc.region.ecsClient.DNatRules.Delete, entry.ID, nil, nil)
+}
+
+func (self *SRegion) GetNatDEntries(natGwId string) ([]SNatDEntry, error) {
+	querys := map[string]string{}
+	if len(natGwId) > 0 {
+		querys["nat_gateway_id"] = natGwId
+	}
+	entries := make([]SNatDEntry, 0)
+	err := doListAllWithMarker(self.ecsClient.DNatRules.List, querys, &entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (self *SRegion) GetNatDEntry(entryId string) (*SNatDEntry, error) {
+	entry := &SNatDEntry{}
+	err := DoGet(self.ecsClient.DNatRules.Get, entryId, nil, entry)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (self *SRegion) CreateNatDEntry(natGwId string, rule cloudprovider.SNatDRule) (*SNatDEntry, error) {
+	dnatRule := map[string]interface{}{
+		"nat_gateway_id": natGwId,
+		"floating_ip_id": rule.ExternalIPID,
+		"private_ip":     rule.InternalIP,
+	}
+	switch rule.Protocol {
+	case api.NAT_DNAT_PROTOCOL_TCP, api.NAT_DNAT_PROTOCOL_UDP:
+		dnatRule["protocol"] = rule.Protocol
+		dnatRule["internal_service_port"] = rule.InternalPort
+		dnatRule["external_service_port"] = rule.ExternalPort
+	default:
+		// 全端口映射时端口必须为0
+		dnatRule["protocol"] = api.NAT_DNAT_PROTOCOL_ANY
+		dnatRule["internal_service_port"] = 0
+		dnatRule["external_service_port"] = 0
+	}
+	params := map[string]map[string]interface{}{
+		"dnat_rule": dnatRule,
+	}
+	entry := &SNatDEntry{}
+	err := DoCreate(self.ecsClient.DNatRules.Create, jsonutils.Marshal(params), entry)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,235 @@
+package huawei
+
+import (
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+// https://support.huaweicloud.com/api-nat/nat_api_0003.html
+type SNatGateway struct {
+	vpc *SVpc
+
+	ID                string `json:"id"`
+	TenantID          string `json:"tenant_id"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	Spec              string `json:"spec"`
+	Status            string `json:"status"`
+	AdminStateUp      bool   `json:"admin_state_up"`
+	RouterID          string `json:"router_id"`
+	InternalNetworkID string `json:"internal_network_id"`
+	CreatedAt         string `json:"created_at"`
+}
+
+func (gateway *SNatGateway) GetId() string {
+	return gateway.ID
+}
+
+func (gateway *SNatGateway) GetName() string {
+	if len(gateway.Name) > 0 {
+		return gateway.Name
+	}
+	return gateway.ID
+}
+
+func (gateway *SNatGateway) GetGlobalId() string {
+	return gateway.ID
+}
+
+func (gateway *SNatGateway) GetStatus() string {
+	return convertNatStatus(gateway.Status)
+}
+
+func (gateway *SNatGateway) Refresh() error {
+	new, err := gateway.vpc.region.GetNatGateway(gateway.ID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(gateway, new)
+}
+
+func (gateway *SNatGateway) IsEmulated() bool {
+	return false
+}
+
+func (gateway *SNatGateway) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (gateway *SNatGateway) GetBillingType() string {
+	return models.BILLING_TYPE_POSTPAID
+}
+
+func (gateway *SNatGateway) GetExpiredAt() time.Time {
+	return time.Time{}
+}
+
+// 华为云NAT网关规格: 1 小型, 2 中型, 3 大型, 4 超大型
+func (gateway *SNatGateway) GetNatSpec() string {
+	switch gateway.Spec {
+	case "1":
+		return api.NAT_SPEC_SMALL
+	case "2":
+		return api.NAT_SPEC_MIDDLE
+	case "3":
+		return api.NAT_SPEC_LARGE
+	case "4":
+		return api.NAT_SPEC_XLARGE
+	}
+	return gateway.Spec
+}
+
+func (gateway *SNatGateway) GetINatSEntries() ([]cloudprovider.ICloudNatSEntry, error) {
+	entries, err := gateway.vpc.region.GetNatSEntries(gateway.ID)
+	if err != nil {
+		return nil, err
+	}
+	ientries := make([]cloudprovider.ICloudNatSEntry, len(entries))
+	for i := 0; i < len(entries); i++ {
+		entries[i].gateway = gateway
+		ientries[i] = &entries[i]
+	}
+	return ientries, nil
+}
+
+func (gateway *SNatGateway) GetINatDEntries() ([]cloudprovider.ICloudNatDEntry, error) {
+	entries, err := gateway.vpc.region.GetNatDEntries(gateway.ID)
+	if err != nil {
+		return nil, err
+	}
+	ientries := make([]cloudprovider.ICloudNatDEntry, len(entries))
+	for i := 0; i < len(entries); i++ {
+		entries[i].gateway = gateway
+		ientries[i] = &entries[i]
+	}
+	return ientries, nil
+}
+
+func (gateway *SNatGateway) GetINatSEntryById(id string) (cloudprovider.ICloudNatSEntry, error) {
+	entry, err := gateway.vpc.region.GetNatSEntry(id)
+	if err != nil {
+		return nil, err
+	}
+	entry.gateway = gateway
+	return entry, nil
+}
+
+func (gateway *SNatGateway) GetINatDEntryById(id string) (cloudprovider.ICloudNatDEntry, error) {
+	entry, err := gateway.vpc.region.GetNatDEntry(id)
+	if err != nil {
+		return nil, err
+	}
+	entry.gateway = gateway
+	return entry, nil
+}
+
+func (gateway *SNatGateway) CreateINatSEntry(rule cloudprovider.SNatSRule) (cloudprovider.ICloudNatSEntry, error) {
+	entry, err := gateway.vpc.region.CreateNatSEntry(gateway.ID, rule)
+	if err != nil {
+		return nil, err
+	}
+	entry.gateway = gateway
+	return entry, nil
+}
+
+func (gateway *SNatGateway) CreateINatDEntry(rule cloudprovider.SNatDRule) (cloudprovider.ICloudNatDEntry, error) {
+	entry, err := gateway.vpc.region.CreateNatDEntry(gateway.ID, rule)
+	if err != nil {
+		return nil, err
+	}
+	entry.gateway = gateway
+	return entry, nil
+}
+
+func (gateway *SNatGateway) Delete() error {
+	return DoDelete(gateway.vpc.region.ecsClient.NatGateways.Delete, gateway.ID, nil, nil)
+}
+
+func (self *SVpc) CreateINatGateway(opts *cloudprovider.SNatGatewayCreateOptions) (cloudprovider.ICloudNatGateway, error) {
+	gateway, err := self.region.CreateNatGateway(self.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+	gateway.vpc = self
+	return gateway, nil
+}
+
+// https://support.huaweicloud.com/api-nat/nat_api_0001.html
+func (self *SRegion) CreateNatGateway(vpcId string, opts *cloudprovider.SNatGatewayCreateOptions) (*SNatGateway, error) {
+	if len(opts.NetworkId) == 0 {
+		return nil, fmt.Errorf("network is required to create nat gateway")
+	}
+	// internal_network_id为子网对应的neutron网络ID
+	network, err := self.getNetwork(opts.NetworkId)
+	if err != nil {
+		return nil, err
+	}
+	spec := "1"
+	switch opts.NatSpec {
+	case api.NAT_SPEC_MIDDLE:
+		spec = "2"
+	case api.NAT_SPEC_LARGE:
+		spec = "3"
+	case api.NAT_SPEC_XLARGE:
+		spec = "4"
+	}
+	params := map[string]map[string]interface{}{
+		"nat_gateway": {
+			"name":                opts.Name,
+			"description":         opts.Desc,
+			"router_id":           vpcId,
+			"internal_network_id": network.NeutronNetworkID,
+			"spec":                spec,
+		},
+	}
+	gateway := &SNatGateway{}
+	err = DoCreate(self.ecsClient.NatGateways.Create, jsonutils.Marshal(params), gateway)
+	if err != nil {
+		return nil, err
+	}
+	return gateway, nil
+}
+
+func (self *SRegion) GetNatGateways(vpcId string) ([]SNatGateway, error) {
+	querys := map[string]string{}
+	if len(vpcId) > 0 {
+		querys["router_id"] = vpcId
+	}
+	gateways := make([]SNatGateway, 0)
+	err := doListAllWithMarker(self.ecsClient.NatGateways.List, querys, &gateways)
+	if err != nil {
+		return nil, err
+	}
+	return gateways, nil
+}
+
+func (self *SRegion) GetNatGateway(natGwId string) (*SNatGateway, error) {
+	gateway := &SNatGateway{}
+	err := DoGet(self.ecsClient.NatGateways.Get, natGwId, nil, gateway)
+	if err != nil {
+		return nil, err
+	}
+	return gateway, nil
+}
+
+// NAT网关及SNAT, DNAT规则状态: ACTIVE, PENDING_CREATE, PENDING_UPDATE, PENDING_DELETE, EIP_FREEZED, INACTIVE
+func convertNatStatus(status string) string {
+	switch status {
+	case "ACTIVE":
+		return api.NAT_STATUS_AVAILABLE
+	case "PENDING_CREATE":
+		return api.NAT_STATUS_ALLOCATE
+	case "PENDING_UPDATE":
+		return api.NAT_STATUS_DEPLOYING
+	case "PENDING_DELETE":
+		return api.NAT_STATUS_DELETING
+	default:
+		return api.NAT_STATUS_UNKNOWN
+	}
+}
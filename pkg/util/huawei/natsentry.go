@@ -0,0 +1,116 @@
+package huawei
+
+import (
+	"yunion.io/x/jsonutils"
+
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+// https://support.huaweicloud.com/api-nat/nat_api_0008.html
+type SNatSEntry struct {
+	gateway *SNatGateway
+
+	ID                string `json:"id"`
+	TenantID          string `json:"tenant_id"`
+	NatGatewayID      string `json:"nat_gateway_id"`
+	NetworkID         string `json:"network_id"`
+	Cidr              string `json:"cidr"`
+	SourceType        int    `json:"source_type"`
+	FloatingIPID      string `json:"floating_ip_id"`
+	FloatingIPAddress string `json:"floating_ip_address"`
+	Status            string `json:"status"`
+	AdminStateUp      bool   `json:"admin_state_up"`
+	CreatedAt         string `json:"created_at"`
+}
+
+func (entry *SNatSEntry) GetId() string {
+	return entry.ID
+}
+
+func (entry *SNatSEntry) GetName() string {
+	return entry.ID
+}
+
+func (entry *SNatSEntry) GetGlobalId() string {
+	return entry.ID
+}
+
+func (entry *SNatSEntry) GetStatus() string {
+	return convertNatStatus(entry.Status)
+}
+
+func (entry *SNatSEntry) Refresh() error {
+	new, err := entry.gateway.vpc.region.GetNatSEntry(entry.ID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(entry, new)
+}
+
+func (entry *SNatSEntry) IsEmulated() bool {
+	return false
+}
+
+func (entry *SNatSEntry) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (entry *SNatSEntry) GetIP() string {
+	return entry.FloatingIPAddress
+}
+
+func (entry *SNatSEntry) GetSourceCIDR() string {
+	return entry.Cidr
+}
+
+func (entry *SNatSEntry) GetNetworkId() string {
+	return entry.NetworkID
+}
+
+func (entry *SNatSEntry) Delete() error {
+	return DoDelete(entry.gateway.vpc.region.ecsClient.SNatRules.Delete, entry.ID, nil, nil)
+}
+
+func (self *SRegion) GetNatSEntries(natGwId string) ([]SNatSEntry, error) {
+	querys := map[string]string{}
+	if len(natGwId) > 0 {
+		querys["nat_gateway_id"] = natGwId
+	}
+	entries := make([]SNatSEntry, 0)
+	err := doListAllWithMarker(self.ecsClient.SNatRules.List, querys, &entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (self *SRegion) GetNatSEntry(entryId string) (*SNatSEntry, error) {
+	entry := &SNatSEntry{}
+	err := DoGet(self.ecsClient.SNatRules.Get, entryId, nil, entry)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (self *SRegion) CreateNatSEntry(natGwId string, rule cloudprovider.SNatSRule) (*SNatSEntry, error) {
+	snatRule := map[string]interface{}{
+		"nat_gateway_id": natGwId,
+		"floating_ip_id": rule.ExternalIPID,
+	}
+	if len(rule.NetworkID) > 0 {
+		snatRule["network_id"] = rule.NetworkID
+	} else {
+		snatRule["cidr"] = rule.SourceCIDR
+		snatRule["source_type"] = 0
+	}
+	params := map[string]map[string]interface{}{
+		"snat_rule": snatRule,
+	}
+	entry := &SNatSEntry{}
+	err := DoCreate(self.ecsClient.SNatRules.Create, jsonutils.Marshal(params), entry)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
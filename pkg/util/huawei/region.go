@@ -127,31 +127,72 @@ func (self *SRegion) GetGeographicInfo() cloudprovider.SGeographicInfo {
 }
 
 func (self *SRegion) GetILoadBalancers() ([]cloudprovider.ICloudLoadbalancer, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	lbs, err := self.GetLoadbalancers()
+	if err != nil {
+		return nil, err
+	}
+	ilbs := make([]cloudprovider.ICloudLoadbalancer, len(lbs))
+	for i := range lbs {
+		ilbs[i] = &lbs[i]
+	}
+	return ilbs, nil
 }
 
 func (region *SRegion) GetILoadBalancerById(loadbalancerId string) (cloudprovider.ICloudLoadbalancer, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	lb, err := region.GetLoadbalancer(loadbalancerId)
+	if err != nil {
+		return nil, err
+	}
+	return lb, nil
 }
 
 func (region *SRegion) GetILoadBalancerAclById(aclId string) (cloudprovider.ICloudLoadbalancerAcl, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	acl, err := region.GetElbACL(aclId)
+	if err != nil {
+		return nil, err
+	}
+	return acl, nil
 }
 
 func (region *SRegion) GetILoadBalancerCertificateById(certId string) (cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	cert, err := region.GetElbCertificate(certId)
+	if err != nil {
+		return nil, err
+	}
+	return cert, nil
 }
 
 func (region *SRegion) CreateILoadBalancerCertificate(cert *cloudprovider.SLoadbalancerCertificate) (cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	ret, err := region.CreateElbCertificate(cert)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
+// 访问控制列表对应监听器的白名单
 func (self *SRegion) GetILoadBalancerAcls() ([]cloudprovider.ICloudLoadbalancerAcl, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	acls, err := self.GetElbACLs("")
+	if err != nil {
+		return nil, err
+	}
+	iacls := make([]cloudprovider.ICloudLoadbalancerAcl, len(acls))
+	for i := range acls {
+		iacls[i] = &acls[i]
+	}
+	return iacls, nil
 }
 
 func (self *SRegion) GetILoadBalancerCertificates() ([]cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	certs, err := self.GetElbCertificates()
+	if err != nil {
+		return nil, err
+	}
+	icerts := make([]cloudprovider.ICloudLoadbalancerCertificate, len(certs))
+	for i := range certs {
+		icerts[i] = &certs[i]
+	}
+	return icerts, nil
 }
 
 // https://support.huaweicloud.com/api-iam/zh-cn_topic_0057845622.html
@@ -639,11 +680,16 @@ func (self *SRegion) addSecurityGroupRule(secGrpId, direction, portStart, portEn
 }
 
 func (region *SRegion) CreateILoadBalancer(loadbalancer *cloudprovider.SLoadbalancer) (cloudprovider.ICloudLoadbalancer, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	lb, err := region.CreateLoadbalancer(loadbalancer)
+	if err != nil {
+		return nil, err
+	}
+	return lb, nil
 }
 
+// 白名单只能随监听器创建
 func (region *SRegion) CreateILoadBalancerAcl(acl *cloudprovider.SLoadbalancerAccessControlList) (cloudprovider.ICloudLoadbalancerAcl, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return nil, cloudprovider.ErrNotSupported
 }
 
 func (region *SRegion) GetSkus(zoneId string) ([]cloudprovider.ICloudSku, error) {
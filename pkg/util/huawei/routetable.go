@@ -0,0 +1,153 @@
+package huawei
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+// https://support.huaweicloud.com/api-vpc/zh-cn_topic_0057690289.html
+type SRoute struct {
+	Id          string
+	Destination string
+	Nexthop     string
+	// 目前仅支持peering
+	Type     string
+	VpcId    string `json:"vpc_id"`
+	TenantId string `json:"tenant_id"`
+}
+
+func (self *SRoute) GetType() string {
+	return "custom"
+}
+
+func (self *SRoute) GetCidr() string {
+	return self.Destination
+}
+
+func (self *SRoute) GetNextHopType() string {
+	if self.Type == "peering" {
+		return api.ROUTE_NEXT_HOP_TYPE_VPC_PEERING
+	}
+	return self.Type
+}
+
+func (self *SRoute) GetNextHop() string {
+	return self.Nexthop
+}
+
+// 华为云VPC没有路由表资源, 将VPC下的路由模拟为一张路由表
+type SRouteTable struct {
+	vpc *SVpc
+
+	Routes []SRoute
+}
+
+func (self *SRouteTable) GetId() string {
+	return self.vpc.ID
+}
+
+func (self *SRouteTable) GetName() string {
+	return self.vpc.GetName()
+}
+
+func (self *SRouteTable) GetGlobalId() string {
+	return self.vpc.ID
+}
+
+func (self *SRouteTable) GetStatus() string {
+	return ""
+}
+
+func (self *SRouteTable) Refresh() error {
+	routes, err := self.vpc.region.GetVpcRoutes(self.vpc.ID)
+	if err != nil {
+		return err
+	}
+	self.Routes = routes
+	return nil
+}
+
+func (self *SRouteTable) IsEmulated() bool {
+	return true
+}
+
+func (self *SRouteTable) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SRouteTable) GetManagerId() string {
+	return self.vpc.region.client.providerId
+}
+
+func (self *SRouteTable) GetDescription() string {
+	return ""
+}
+
+func (self *SRouteTable) GetRegionId() string {
+	return self.vpc.region.GetId()
+}
+
+func (self *SRouteTable) GetVpcId() string {
+	return self.vpc.ID
+}
+
+func (self *SRouteTable) GetType() string {
+	return "system"
+}
+
+func (self *SRouteTable) GetIRoutes() ([]cloudprovider.ICloudRoute, error) {
+	iroutes := make([]cloudprovider.ICloudRoute, len(self.Routes))
+	for i := 0; i < len(self.Routes); i++ {
+		iroutes[i] = &self.Routes[i]
+	}
+	return iroutes, nil
+}
+
+func (self *SRouteTable) CreateRoute(route cloudprovider.SRouteSet) error {
+	if route.NextHopType != api.ROUTE_NEXT_HOP_TYPE_VPC_PEERING {
+		return fmt.Errorf("unsupported next hop type %s", route.NextHopType)
+	}
+	err := self.vpc.region.CreateVpcRoute(self.vpc.ID, route.Destination, route.NextHop)
+	if err != nil {
+		return err
+	}
+	return self.Refresh()
+}
+
+func (self *SRouteTable) RemoveRoute(route cloudprovider.SRouteSet) error {
+	for i := 0; i < len(self.Routes); i++ {
+		if self.Routes[i].Destination == route.Destination {
+			err := DoDelete(self.vpc.region.ecsClient.VpcRoutes.Delete, self.Routes[i].Id, nil, nil)
+			if err != nil {
+				return err
+			}
+			return self.Refresh()
+		}
+	}
+	return cloudprovider.ErrNotFound
+}
+
+func (self *SRegion) GetVpcRoutes(vpcId string) ([]SRoute, error) {
+	querys := map[string]string{"vpc_id": vpcId}
+	routes := make([]SRoute, 0)
+	err := doListAllWithMarker(self.ecsClient.VpcRoutes.List, querys, &routes)
+	if err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+func (self *SRegion) CreateVpcRoute(vpcId string, destination string, nexthop string) error {
+	routeObj := jsonutils.NewDict()
+	routeObj.Set("type", jsonutils.NewString("peering"))
+	routeObj.Set("nexthop", jsonutils.NewString(nexthop))
+	routeObj.Set("destination", jsonutils.NewString(destination))
+	routeObj.Set("vpc_id", jsonutils.NewString(vpcId))
+	params := jsonutils.NewDict()
+	params.Set("route", routeObj)
+	return DoCreate(self.ecsClient.VpcRoutes.Create, params, nil)
+}
@@ -0,0 +1,90 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/util/huawei"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+func init() {
+	type LoadbalancerListOptions struct {
+	}
+	shellutils.R(&LoadbalancerListOptions{}, "lb-list", "List loadbalancers", func(cli *huawei.SRegion, args *LoadbalancerListOptions) error {
+		lbs, err := cli.GetLoadbalancers()
+		if err != nil {
+			return err
+		}
+		printList(lbs, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerListenerListOptions struct {
+		LB string `help:"Loadbalancer ID"`
+	}
+	shellutils.R(&LoadbalancerListenerListOptions{}, "lb-listener-list", "List loadbalancer listeners", func(cli *huawei.SRegion, args *LoadbalancerListenerListOptions) error {
+		listeners, err := cli.GetElbListeners(args.LB)
+		if err != nil {
+			return err
+		}
+		printList(listeners, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerPolicyListOptions struct {
+		LISTENER string `help:"Listener ID"`
+	}
+	shellutils.R(&LoadbalancerPolicyListOptions{}, "lb-l7policy-list", "List loadbalancer l7 policies", func(cli *huawei.SRegion, args *LoadbalancerPolicyListOptions) error {
+		policies, err := cli.GetElbListenerPolicies(args.LISTENER)
+		if err != nil {
+			return err
+		}
+		printList(policies, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerBackendGroupListOptions struct {
+		LB string `help:"Loadbalancer ID"`
+	}
+	shellutils.R(&LoadbalancerBackendGroupListOptions{}, "lb-backendgroup-list", "List loadbalancer backend groups", func(cli *huawei.SRegion, args *LoadbalancerBackendGroupListOptions) error {
+		pools, err := cli.GetElbBackendGroups(args.LB)
+		if err != nil {
+			return err
+		}
+		printList(pools, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerBackendListOptions struct {
+		POOL string `help:"Backend group ID"`
+	}
+	shellutils.R(&LoadbalancerBackendListOptions{}, "lb-backend-list", "List backends of loadbalancer backend group", func(cli *huawei.SRegion, args *LoadbalancerBackendListOptions) error {
+		members, err := cli.GetElbBackends(args.POOL)
+		if err != nil {
+			return err
+		}
+		printList(members, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerWhitelistListOptions struct {
+		Listener string `help:"Listener ID"`
+	}
+	shellutils.R(&LoadbalancerWhitelistListOptions{}, "lb-whitelist-list", "List loadbalancer listener whitelists", func(cli *huawei.SRegion, args *LoadbalancerWhitelistListOptions) error {
+		acls, err := cli.GetElbACLs(args.Listener)
+		if err != nil {
+			return err
+		}
+		printList(acls, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerCertificateListOptions struct {
+	}
+	shellutils.R(&LoadbalancerCertificateListOptions{}, "lb-cert-list", "List loadbalancer certificates", func(cli *huawei.SRegion, args *LoadbalancerCertificateListOptions) error {
+		certs, err := cli.GetElbCertificates()
+		if err != nil {
+			return err
+		}
+		printList(certs, 0, 0, 0, nil)
+		return nil
+	})
+}
@@ -152,10 +152,27 @@ func (self *SVpc) GetISecurityGroups() ([]cloudprovider.ICloudSecurityGroup, err
 }
 
 func (self *SVpc) GetIRouteTables() ([]cloudprovider.ICloudRouteTable, error) {
-	rts := []cloudprovider.ICloudRouteTable{}
+	routes, err := self.region.GetVpcRoutes(self.ID)
+	if err != nil {
+		return nil, err
+	}
+	rts := []cloudprovider.ICloudRouteTable{&SRouteTable{vpc: self, Routes: routes}}
 	return rts, nil
 }
 
+func (self *SVpc) GetINatGateways() ([]cloudprovider.ICloudNatGateway, error) {
+	gateways, err := self.region.GetNatGateways(self.ID)
+	if err != nil {
+		return nil, err
+	}
+	igateways := make([]cloudprovider.ICloudNatGateway, len(gateways))
+	for i := 0; i < len(gateways); i++ {
+		gateways[i].vpc = self
+		igateways[i] = &gateways[i]
+	}
+	return igateways, nil
+}
+
 func (self *SVpc) GetManagerId() string {
 	return self.region.client.providerId
 }
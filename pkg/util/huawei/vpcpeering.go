@@ -0,0 +1,182 @@
+package huawei
+
+import (
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SVpcInfo struct {
+	VpcId    string `json:"vpc_id"`
+	TenantId string `json:"tenant_id"`
+}
+
+// https://support.huaweicloud.com/api-vpc/zh-cn_topic_0060595555.html
+type SVpcPeering struct {
+	region *SRegion
+
+	Id          string
+	Name        string
+	Description string
+	// PENDING_ACCEPTANCE, REJECTED, EXPIRED, DELETED, ACTIVE
+	Status         string
+	RequestVpcInfo SVpcInfo `json:"request_vpc_info"`
+	AcceptVpcInfo  SVpcInfo `json:"accept_vpc_info"`
+}
+
+func (self *SVpcPeering) GetId() string {
+	return self.Id
+}
+
+func (self *SVpcPeering) GetName() string {
+	if len(self.Name) > 0 {
+		return self.Name
+	}
+	return self.Id
+}
+
+func (self *SVpcPeering) GetGlobalId() string {
+	return self.Id
+}
+
+func (self *SVpcPeering) GetStatus() string {
+	switch self.Status {
+	case "PENDING_ACCEPTANCE":
+		return api.VPC_PEERING_STATUS_PENDING_ACCEPT
+	case "ACTIVE":
+		return api.VPC_PEERING_STATUS_ACTIVE
+	case "REJECTED":
+		return api.VPC_PEERING_STATUS_REJECTED
+	case "EXPIRED":
+		return api.VPC_PEERING_STATUS_EXPIRED
+	case "DELETED":
+		return api.VPC_PEERING_STATUS_DELETING
+	default:
+		return api.VPC_PEERING_STATUS_UNKNOWN
+	}
+}
+
+func (self *SVpcPeering) Refresh() error {
+	peering, err := self.region.GetVpcPeering(self.Id)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, peering)
+}
+
+func (self *SVpcPeering) IsEmulated() bool {
+	return false
+}
+
+func (self *SVpcPeering) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SVpcPeering) GetDescription() string {
+	return self.Description
+}
+
+func (self *SVpcPeering) GetVpcId() string {
+	return self.RequestVpcInfo.VpcId
+}
+
+func (self *SVpcPeering) GetPeerVpcId() string {
+	return self.AcceptVpcInfo.VpcId
+}
+
+func (self *SVpcPeering) GetPeerAccountId() string {
+	if self.AcceptVpcInfo.TenantId == self.RequestVpcInfo.TenantId {
+		return ""
+	}
+	return self.AcceptVpcInfo.TenantId
+}
+
+// 华为云对等连接仅支持同区域
+func (self *SVpcPeering) GetPeerRegionId() string {
+	return self.region.GetId()
+}
+
+// 跨租户的对等连接需要对端租户接受
+func (self *SVpcPeering) Accept() error {
+	return DoUpdateWithSpec(self.region.ecsClient.VpcPeerings.UpdateInContextWithSpec, self.Id, "accept", nil)
+}
+
+func (self *SVpcPeering) Delete() error {
+	return DoDelete(self.region.ecsClient.VpcPeerings.Delete, self.Id, nil, nil)
+}
+
+func (self *SRegion) GetVpcPeerings(vpcId string) ([]SVpcPeering, error) {
+	querys := map[string]string{}
+	if len(vpcId) > 0 {
+		querys["vpc_id"] = vpcId
+	}
+	peerings := make([]SVpcPeering, 0)
+	err := doListAllWithMarker(self.ecsClient.VpcPeerings.List, querys, &peerings)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(peerings); i++ {
+		peerings[i].region = self
+	}
+	return peerings, nil
+}
+
+func (self *SRegion) GetVpcPeering(peeringId string) (*SVpcPeering, error) {
+	peering := SVpcPeering{region: self}
+	err := DoGet(self.ecsClient.VpcPeerings.Get, peeringId, nil, &peering)
+	if err != nil {
+		return nil, err
+	}
+	return &peering, nil
+}
+
+func (self *SRegion) CreateVpcPeering(vpcId string, opts *cloudprovider.SVpcPeeringCreateOptions) (*SVpcPeering, error) {
+	if len(opts.PeerRegionId) > 0 && opts.PeerRegionId != self.GetId() {
+		return nil, cloudprovider.ErrNotSupported
+	}
+	acceptInfo := jsonutils.Marshal(map[string]string{"vpc_id": opts.PeerVpcId}).(*jsonutils.JSONDict)
+	if len(opts.PeerAccountId) > 0 {
+		acceptInfo.Set("tenant_id", jsonutils.NewString(opts.PeerAccountId))
+	}
+	peeringObj := jsonutils.NewDict()
+	peeringObj.Set("name", jsonutils.NewString(opts.Name))
+	if len(opts.Desc) > 0 {
+		peeringObj.Set("description", jsonutils.NewString(opts.Desc))
+	}
+	peeringObj.Set("request_vpc_info", jsonutils.Marshal(map[string]string{"vpc_id": vpcId}))
+	peeringObj.Set("accept_vpc_info", acceptInfo)
+	params := jsonutils.NewDict()
+	params.Set("peering", peeringObj)
+
+	peering := SVpcPeering{region: self}
+	err := DoCreate(self.ecsClient.VpcPeerings.Create, params, &peering)
+	if err != nil {
+		return nil, err
+	}
+	return &peering, nil
+}
+
+func (self *SVpc) GetIVpcPeerings() ([]cloudprovider.ICloudVpcPeering, error) {
+	peerings, err := self.region.GetVpcPeerings(self.ID)
+	if err != nil {
+		return nil, err
+	}
+	ipeerings := make([]cloudprovider.ICloudVpcPeering, 0)
+	for i := 0; i < len(peerings); i++ {
+		// 只同步本端发起的对等连接, 避免同一连接在两端VPC下重复
+		if peerings[i].RequestVpcInfo.VpcId != self.ID {
+			continue
+		}
+		ipeerings = append(ipeerings, &peerings[i])
+	}
+	return ipeerings, nil
+}
+
+func (self *SVpc) GetIVpcPeeringById(id string) (cloudprovider.ICloudVpcPeering, error) {
+	return self.region.GetVpcPeering(id)
+}
+
+func (self *SVpc) CreateIVpcPeering(opts *cloudprovider.SVpcPeeringCreateOptions) (cloudprovider.ICloudVpcPeering, error) {
+	return self.region.CreateVpcPeering(self.ID, opts)
+}
@@ -1,11 +1,16 @@
 package influxdb
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"yunion.io/x/jsonutils"
 	"yunion.io/x/log"
@@ -28,14 +33,17 @@ func NewInfluxdb(accessUrl string) *SInfluxdb {
 	return &inst
 }
 
-type dbResult struct {
+type SDBResult struct {
 	Name    string
 	Columns []string
 	Values  [][]jsonutils.JSONObject
 }
 
-func (db *SInfluxdb) query(sql string) ([][]dbResult, error) {
+func (db *SInfluxdb) query(sql string) ([][]SDBResult, error) {
 	nurl := fmt.Sprintf("%s/query?q=%s", db.accessUrl, url.QueryEscape(sql))
+	if len(db.dbName) > 0 {
+		nurl = fmt.Sprintf("%s&db=%s", nurl, url.QueryEscape(db.dbName))
+	}
 	_, body, err := httputils.JSONRequest(db.client, context.Background(), "POST", nurl, nil, nil, false)
 	if err != nil {
 		return nil, err
@@ -45,11 +53,11 @@ func (db *SInfluxdb) query(sql string) ([][]dbResult, error) {
 	if err != nil {
 		return nil, err
 	}
-	rets := make([][]dbResult, len(results))
+	rets := make([][]SDBResult, len(results))
 	for i := range results {
 		series, err := results[i].Get("series")
 		if err == nil {
-			ret := make([]dbResult, 0)
+			ret := make([]SDBResult, 0)
 			err = series.Unmarshal(&ret)
 			if err != nil {
 				return nil, err
@@ -60,6 +68,11 @@ func (db *SInfluxdb) query(sql string) ([][]dbResult, error) {
 	return rets, nil
 }
 
+// Query runs the statements in sql, one result set per statement is returned
+func (db *SInfluxdb) Query(sql string) ([][]SDBResult, error) {
+	return db.query(sql)
+}
+
 func (db *SInfluxdb) SetDatabase(dbName string) error {
 	dbs, err := db.GetDatabases()
 	if err != nil {
@@ -70,7 +83,6 @@ func (db *SInfluxdb) SetDatabase(dbName string) error {
 		if err != nil {
 			return err
 		}
-		return nil
 	}
 	db.dbName = dbName
 	return nil
@@ -171,3 +183,96 @@ func (db *SInfluxdb) SetRetentionPolicy(rp SRetentionPolicy) error {
 		return db.CreateRetentionPolicy(rp)
 	}
 }
+
+// SPoint is a data point in influxdb line protocol
+type SPoint struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	Time        time.Time
+}
+
+var (
+	lineEscaper       = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper        = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+	fieldValueEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
+
+// String formats the point in line protocol with nanosecond precision.
+// Tags and fields are sorted by key, empty tag values are omitted
+func (p *SPoint) String() string {
+	var buf strings.Builder
+	buf.WriteString(lineEscaper.Replace(p.Measurement))
+	tagKeys := make([]string, 0, len(p.Tags))
+	for k, v := range p.Tags {
+		if len(v) > 0 {
+			tagKeys = append(tagKeys, k)
+		}
+	}
+	sort.Strings(tagKeys)
+	for _, k := range tagKeys {
+		buf.WriteString(",")
+		buf.WriteString(tagEscaper.Replace(k))
+		buf.WriteString("=")
+		buf.WriteString(tagEscaper.Replace(p.Tags[k]))
+	}
+	fieldKeys := make([]string, 0, len(p.Fields))
+	for k := range p.Fields {
+		fieldKeys = append(fieldKeys, k)
+	}
+	sort.Strings(fieldKeys)
+	for i, k := range fieldKeys {
+		if i == 0 {
+			buf.WriteString(" ")
+		} else {
+			buf.WriteString(",")
+		}
+		buf.WriteString(tagEscaper.Replace(k))
+		buf.WriteString("=")
+		switch v := p.Fields[k].(type) {
+		case int:
+			buf.WriteString(fmt.Sprintf("%di", v))
+		case int64:
+			buf.WriteString(fmt.Sprintf("%di", v))
+		case float64:
+			buf.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			buf.WriteString(strconv.FormatBool(v))
+		default:
+			buf.WriteString(`"`)
+			buf.WriteString(fieldValueEscaper.Replace(fmt.Sprintf("%v", v)))
+			buf.WriteString(`"`)
+		}
+	}
+	if !p.Time.IsZero() {
+		buf.WriteString(" ")
+		buf.WriteString(strconv.FormatInt(p.Time.UnixNano(), 10))
+	}
+	return buf.String()
+}
+
+// Write writes points to the current database
+func (db *SInfluxdb) Write(points []SPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	for i := range points {
+		buf.WriteString(points[i].String())
+		buf.WriteString("\n")
+	}
+	nurl := fmt.Sprintf("%s/write?precision=ns", db.accessUrl)
+	if len(db.dbName) > 0 {
+		nurl = fmt.Sprintf("%s&db=%s", nurl, url.QueryEscape(db.dbName))
+	}
+	resp, err := httputils.Request(db.client, context.Background(), "POST", nurl, nil, &buf, false)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("influxdb write: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
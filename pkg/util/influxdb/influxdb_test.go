@@ -2,6 +2,7 @@ package influxdb
 
 import (
 	"testing"
+	"time"
 )
 
 func TestInfluxdb(t *testing.T) {
@@ -28,3 +29,25 @@ func TestInfluxdb(t *testing.T) {
 	}
 	t.Logf("%#v", rps)
 }
+
+func TestPointString(t *testing.T) {
+	p := SPoint{
+		Measurement: "lb access",
+		Tags: map[string]string{
+			"listener_id": "lbl,0",
+			"empty":       "",
+		},
+		Fields: map[string]interface{}{
+			"status":  200,
+			"latency": int64(12),
+			"ratio":   0.5,
+			"ok":      true,
+			"request": `GET "/" HTTP/1.1`,
+		},
+		Time: time.Unix(1, 2),
+	}
+	want := `lb\ access,listener_id=lbl\,0 latency=12i,ok=true,ratio=0.5,request="GET \"/\" HTTP/1.1",status=200i 1000000002`
+	if got := p.String(); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
@@ -24,6 +24,7 @@ import (
 const (
 	ACT_ADDTAG                       = "添加标签"
 	ACT_ALLOCATE                     = "分配"
+	ACT_ACCEPT                       = "接受"
 	ACT_BM_CONVERT_HYPER             = "转换为宿主机"
 	ACT_BM_MAINTENANCE               = "进入离线状态"
 	ACT_BM_UNCONVERT_HYPER           = "转换为受管物理机"
@@ -75,6 +76,7 @@ const (
 	ACT_SYNC_CONF                    = "同步配置"
 	ACT_CREATE_BACKUP                = "创建备份机"
 	ACT_SWITCH_TO_BACKUP             = "主备切换"
+	ACT_VM_CLONE                     = "克隆"
 
 	ACT_IMAGE_SAVE = "上传镜像"
 
@@ -0,0 +1,318 @@
+package openstack
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+const (
+	OCTAVIA_SERVICE = "load-balancer"
+
+	LB_PROVISIONING_STATUS_ACTIVE         = "ACTIVE"
+	LB_PROVISIONING_STATUS_PENDING_CREATE = "PENDING_CREATE"
+	LB_PROVISIONING_STATUS_PENDING_UPDATE = "PENDING_UPDATE"
+	LB_PROVISIONING_STATUS_PENDING_DELETE = "PENDING_DELETE"
+	LB_PROVISIONING_STATUS_ERROR          = "ERROR"
+)
+
+type SOctaviaRef struct {
+	ID string
+}
+
+// https://docs.openstack.org/api-ref/load-balancer/v2/index.html#load-balancers
+type SLoadbalancer struct {
+	region *SRegion
+
+	ID                 string
+	Name               string
+	Description        string
+	ProjectID          string
+	AdminStateUp       bool
+	ProvisioningStatus string
+	OperatingStatus    string
+	VipAddress         string
+	VipNetworkID       string
+	VipSubnetID        string
+	VipPortID          string
+	Provider           string
+	FlavorID           string
+	AvailabilityZone   string
+	Listeners          []SOctaviaRef
+	Pools              []SOctaviaRef
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+func (lb *SLoadbalancer) GetId() string {
+	return lb.ID
+}
+
+func (lb *SLoadbalancer) GetName() string {
+	if len(lb.Name) > 0 {
+		return lb.Name
+	}
+	return lb.ID
+}
+
+func (lb *SLoadbalancer) GetGlobalId() string {
+	return lb.ID
+}
+
+func (lb *SLoadbalancer) GetStatus() string {
+	switch lb.ProvisioningStatus {
+	case LB_PROVISIONING_STATUS_ACTIVE, LB_PROVISIONING_STATUS_PENDING_UPDATE:
+		if !lb.AdminStateUp {
+			return api.LB_STATUS_DISABLED
+		}
+		return api.LB_STATUS_ENABLED
+	case LB_PROVISIONING_STATUS_PENDING_CREATE:
+		return api.LB_STATUS_INIT
+	case LB_PROVISIONING_STATUS_PENDING_DELETE:
+		return api.LB_STATUS_DELETING
+	default:
+		return api.LB_STATUS_UNKNOWN
+	}
+}
+
+func (lb *SLoadbalancer) Refresh() error {
+	new, err := lb.region.GetLoadbalancer(lb.ID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(lb, new)
+}
+
+func (lb *SLoadbalancer) IsEmulated() bool {
+	return false
+}
+
+func (lb *SLoadbalancer) GetMetadata() *jsonutils.JSONDict {
+	data := jsonutils.NewDict()
+	data.Add(jsonutils.NewString(lb.Provider), "provider")
+	data.Add(jsonutils.NewString(lb.OperatingStatus), "operating_status")
+	return data
+}
+
+func (lb *SLoadbalancer) GetProjectId() string {
+	return lb.ProjectID
+}
+
+func (lb *SLoadbalancer) GetAddress() string {
+	return lb.VipAddress
+}
+
+// vip总是位于租户网络中, 公网访问需要为vip端口绑定浮动ip
+func (lb *SLoadbalancer) GetAddressType() string {
+	return api.LB_ADDR_TYPE_INTRANET
+}
+
+func (lb *SLoadbalancer) GetNetworkType() string {
+	return api.LB_NETWORK_TYPE_VPC
+}
+
+func (lb *SLoadbalancer) GetNetworkId() string {
+	return lb.VipSubnetID
+}
+
+func (lb *SLoadbalancer) GetVpcId() string {
+	return lb.VipNetworkID
+}
+
+func (lb *SLoadbalancer) GetZoneId() string {
+	zones, err := lb.region.GetIZones()
+	if err != nil || len(zones) == 0 {
+		return ""
+	}
+	return zones[0].GetGlobalId()
+}
+
+func (lb *SLoadbalancer) GetLoadbalancerSpec() string {
+	return lb.FlavorID
+}
+
+func (lb *SLoadbalancer) GetChargeType() string {
+	return ""
+}
+
+func (lb *SLoadbalancer) Delete() error {
+	_, err := lb.region.Delete(OCTAVIA_SERVICE, fmt.Sprintf("/v2/lbaas/loadbalancers/%s?cascade=true", lb.ID), "")
+	if err != nil {
+		return err
+	}
+	return cloudprovider.WaitDeleted(lb, 5*time.Second, 300*time.Second)
+}
+
+func (lb *SLoadbalancer) setAdminState(up bool) error {
+	params := map[string]map[string]interface{}{
+		"loadbalancer": {
+			"admin_state_up": up,
+		},
+	}
+	_, _, err := lb.region.Update(OCTAVIA_SERVICE, "/v2/lbaas/loadbalancers/"+lb.ID, "", jsonutils.Marshal(params))
+	if err != nil {
+		return err
+	}
+	return lb.region.waitLoadbalancerActive(lb.ID)
+}
+
+func (lb *SLoadbalancer) Start() error {
+	return lb.setAdminState(true)
+}
+
+func (lb *SLoadbalancer) Stop() error {
+	return lb.setAdminState(false)
+}
+
+func (lb *SLoadbalancer) GetILoadBalancerListeners() ([]cloudprovider.ICloudLoadbalancerListener, error) {
+	listeners, err := lb.region.GetLoadbalancerListeners(lb.ID)
+	if err != nil {
+		return nil, err
+	}
+	ilisteners := make([]cloudprovider.ICloudLoadbalancerListener, len(listeners))
+	for i := range listeners {
+		listeners[i].lb = lb
+		ilisteners[i] = &listeners[i]
+	}
+	return ilisteners, nil
+}
+
+func (lb *SLoadbalancer) GetILoadBalancerListenerById(listenerId string) (cloudprovider.ICloudLoadbalancerListener, error) {
+	listener, err := lb.region.GetLoadbalancerListener(listenerId)
+	if err != nil {
+		return nil, err
+	}
+	listener.lb = lb
+	return listener, nil
+}
+
+func (lb *SLoadbalancer) CreateILoadBalancerListener(listener *cloudprovider.SLoadbalancerListener) (cloudprovider.ICloudLoadbalancerListener, error) {
+	lblis, err := lb.region.CreateLoadbalancerListener(lb, listener)
+	if err != nil {
+		return nil, err
+	}
+	return lblis, nil
+}
+
+func (lb *SLoadbalancer) GetILoadBalancerBackendGroups() ([]cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	pools, err := lb.region.GetLoadbalancerPools(lb.ID)
+	if err != nil {
+		return nil, err
+	}
+	igroups := make([]cloudprovider.ICloudLoadbalancerBackendGroup, len(pools))
+	for i := range pools {
+		pools[i].lb = lb
+		igroups[i] = &pools[i]
+	}
+	return igroups, nil
+}
+
+func (lb *SLoadbalancer) GetILoadBalancerBackendGroupById(groupId string) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	pool, err := lb.region.GetLoadbalancerPool(groupId)
+	if err != nil {
+		return nil, err
+	}
+	pool.lb = lb
+	return pool, nil
+}
+
+func (lb *SLoadbalancer) CreateILoadBalancerBackendGroup(group *cloudprovider.SLoadbalancerBackendGroup) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	pool, err := lb.region.CreateLoadbalancerPool(lb, group)
+	if err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+func (region *SRegion) GetLoadbalancers() ([]SLoadbalancer, error) {
+	_, resp, err := region.List(OCTAVIA_SERVICE, "/v2/lbaas/loadbalancers", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	lbs := []SLoadbalancer{}
+	if err := resp.Unmarshal(&lbs, "loadbalancers"); err != nil {
+		return nil, err
+	}
+	for i := range lbs {
+		lbs[i].region = region
+	}
+	return lbs, nil
+}
+
+func (region *SRegion) GetLoadbalancer(lbId string) (*SLoadbalancer, error) {
+	_, resp, err := region.Get(OCTAVIA_SERVICE, "/v2/lbaas/loadbalancers/"+lbId, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	lb := &SLoadbalancer{region: region}
+	if err := resp.Unmarshal(lb, "loadbalancer"); err != nil {
+		return nil, err
+	}
+	if lb.ProvisioningStatus == "DELETED" {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return lb, nil
+}
+
+// octavia的变更都是异步的, 负载均衡处于PENDING_*状态时不接受新的变更
+func (region *SRegion) waitLoadbalancerActive(lbId string) error {
+	startTime := time.Now()
+	for time.Now().Sub(startTime) < 5*time.Minute {
+		lb, err := region.GetLoadbalancer(lbId)
+		if err != nil {
+			return err
+		}
+		switch lb.ProvisioningStatus {
+		case LB_PROVISIONING_STATUS_ACTIVE:
+			return nil
+		case LB_PROVISIONING_STATUS_ERROR:
+			return fmt.Errorf("loadbalancer %s provisioning status is %s", lbId, lb.ProvisioningStatus)
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("timeout for waiting loadbalancer %s active", lbId)
+}
+
+func (region *SRegion) CreateLoadbalancer(loadbalancer *cloudprovider.SLoadbalancer) (*SLoadbalancer, error) {
+	if len(loadbalancer.NetworkID) == 0 {
+		return nil, fmt.Errorf("openstack loadbalancer %s requires a network", loadbalancer.Name)
+	}
+	params := map[string]map[string]interface{}{
+		"loadbalancer": {
+			"name":          loadbalancer.Name,
+			"vip_subnet_id": loadbalancer.NetworkID,
+		},
+	}
+	if len(loadbalancer.Address) > 0 {
+		params["loadbalancer"]["vip_address"] = loadbalancer.Address
+	}
+	if len(loadbalancer.LoadbalancerSpec) > 0 {
+		params["loadbalancer"]["flavor_id"] = loadbalancer.LoadbalancerSpec
+	}
+	_, resp, err := region.Post(OCTAVIA_SERVICE, "/v2/lbaas/loadbalancers", "", jsonutils.Marshal(params))
+	if err != nil {
+		return nil, err
+	}
+	lb := &SLoadbalancer{region: region}
+	if err := resp.Unmarshal(lb, "loadbalancer"); err != nil {
+		return nil, err
+	}
+	if err := region.waitLoadbalancerActive(lb.ID); err != nil {
+		return nil, err
+	}
+	return lb, lb.Refresh()
+}
+
+func octaviaQuery(base string, key, value string) string {
+	if len(value) == 0 {
+		return base
+	}
+	params := url.Values{}
+	params.Set(key, value)
+	return fmt.Sprintf("%s?%s", base, params.Encode())
+}
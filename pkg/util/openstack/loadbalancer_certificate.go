@@ -0,0 +1,283 @@
+package openstack
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+const (
+	BARBICAN_SERVICE = "key-manager"
+)
+
+type SBarbicanSecretRef struct {
+	Name      string
+	SecretRef string
+}
+
+// https://docs.openstack.org/barbican/latest/api/reference/containers.html
+// 证书以certificate类型的容器保存, octavia通过容器地址引用证书
+type SLoadbalancerCertificate struct {
+	region *SRegion
+	cert   *x509.Certificate
+
+	fingerprint string
+
+	Name         string
+	Type         string
+	Status       string
+	ContainerRef string
+	SecretRefs   []SBarbicanSecretRef
+	CreatorID    string
+	Created      time.Time
+	Updated      time.Time
+}
+
+func (cert *SLoadbalancerCertificate) GetId() string {
+	return barbicanRefId(cert.ContainerRef)
+}
+
+func (cert *SLoadbalancerCertificate) GetName() string {
+	if len(cert.Name) > 0 {
+		return cert.Name
+	}
+	return cert.GetId()
+}
+
+func (cert *SLoadbalancerCertificate) GetGlobalId() string {
+	return cert.ContainerRef
+}
+
+func (cert *SLoadbalancerCertificate) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (cert *SLoadbalancerCertificate) Refresh() error {
+	new, err := cert.region.GetLoadbalancerCertificate(cert.GetId())
+	if err != nil {
+		return err
+	}
+	*cert = *new
+	return nil
+}
+
+func (cert *SLoadbalancerCertificate) IsEmulated() bool {
+	return false
+}
+
+func (cert *SLoadbalancerCertificate) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (cert *SLoadbalancerCertificate) GetProjectId() string {
+	return ""
+}
+
+func (cert *SLoadbalancerCertificate) GetCommonName() string {
+	if cert.cert != nil {
+		return cert.cert.Subject.CommonName
+	}
+	return ""
+}
+
+func (cert *SLoadbalancerCertificate) GetSubjectAlternativeNames() string {
+	if cert.cert != nil {
+		return strings.Join(cert.cert.DNSNames, ",")
+	}
+	return ""
+}
+
+func (cert *SLoadbalancerCertificate) GetFingerprint() string {
+	return cert.fingerprint
+}
+
+func (cert *SLoadbalancerCertificate) GetExpireTime() time.Time {
+	if cert.cert != nil {
+		return cert.cert.NotAfter
+	}
+	return time.Time{}
+}
+
+// barbican的容器与密钥创建后不可修改
+func (cert *SLoadbalancerCertificate) Sync(name, privateKey, publickKey string) error {
+	if len(privateKey) > 0 || len(publickKey) > 0 || (len(name) > 0 && name != cert.Name) {
+		return cloudprovider.ErrNotSupported
+	}
+	return nil
+}
+
+func (cert *SLoadbalancerCertificate) Delete() error {
+	_, err := cert.region.Delete(BARBICAN_SERVICE, "/v1/containers/"+cert.GetId(), "")
+	if err != nil {
+		return err
+	}
+	for _, secret := range cert.SecretRefs {
+		_, err := cert.region.Delete(BARBICAN_SERVICE, "/v1/secrets/"+barbicanRefId(secret.SecretRef), "")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cert *SLoadbalancerCertificate) getSecretRef(name string) string {
+	for _, secret := range cert.SecretRefs {
+		if secret.Name == name {
+			return secret.SecretRef
+		}
+	}
+	return ""
+}
+
+func (cert *SLoadbalancerCertificate) fetchCertificate() error {
+	ref := cert.getSecretRef("certificate")
+	if len(ref) == 0 {
+		return nil
+	}
+	payload, err := cert.region.getBarbicanSecretPayload(barbicanRefId(ref))
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode([]byte(payload))
+	if block == nil {
+		return fmt.Errorf("invalid certificate payload of container %s", cert.ContainerRef)
+	}
+	d := sha256.Sum256(block.Bytes)
+	cert.fingerprint = api.LB_TLS_CERT_FINGERPRINT_ALGO_SHA256 + ":" + hex.EncodeToString(d[:])
+	cert.cert, err = x509.ParseCertificate(block.Bytes)
+	return err
+}
+
+func barbicanRefId(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func (region *SRegion) getBarbicanSecretPayload(secretId string) (string, error) {
+	cli := region.client
+	session := cli.client.NewSession(context.Background(), region.Name, "", cli.endpointType, cli.tokenCredential, "")
+	header := http.Header{}
+	header.Set("Accept", "text/plain")
+	resp, err := session.RawRequest(BARBICAN_SERVICE, "", "GET", fmt.Sprintf("/v1/secrets/%s/payload", secretId), header, strings.NewReader(""))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to get payload of secret %s: %s", secretId, string(data))
+	}
+	return string(data), nil
+}
+
+func (region *SRegion) GetLoadbalancerCertificates() ([]SLoadbalancerCertificate, error) {
+	certs := []SLoadbalancerCertificate{}
+	offset := 0
+	for {
+		url := fmt.Sprintf("/v1/containers?type=certificate&limit=100&offset=%d", offset)
+		_, resp, err := region.List(BARBICAN_SERVICE, url, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		part := []SLoadbalancerCertificate{}
+		if err := resp.Unmarshal(&part, "containers"); err != nil {
+			return nil, err
+		}
+		for i := range part {
+			part[i].region = region
+			if err := part[i].fetchCertificate(); err != nil {
+				return nil, err
+			}
+		}
+		certs = append(certs, part...)
+		total, _ := resp.Int("total")
+		offset += len(part)
+		if len(part) == 0 || int64(offset) >= total {
+			break
+		}
+	}
+	return certs, nil
+}
+
+func (region *SRegion) GetLoadbalancerCertificate(containerId string) (*SLoadbalancerCertificate, error) {
+	_, resp, err := region.Get(BARBICAN_SERVICE, "/v1/containers/"+barbicanRefId(containerId), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	cert := &SLoadbalancerCertificate{region: region}
+	if err := resp.Unmarshal(cert); err != nil {
+		return nil, err
+	}
+	return cert, cert.fetchCertificate()
+}
+
+type barbicanSecretPayload struct {
+	name       string
+	secretType string
+	payload    string
+}
+
+func (region *SRegion) createBarbicanSecret(name, secretType, payload string) (string, error) {
+	params := map[string]string{
+		"name":                 name,
+		"secret_type":          secretType,
+		"payload":              payload,
+		"payload_content_type": "text/plain",
+	}
+	_, resp, err := region.Post(BARBICAN_SERVICE, "/v1/secrets", "", jsonutils.Marshal(params))
+	if err != nil {
+		return "", err
+	}
+	return resp.GetString("secret_ref")
+}
+
+// 证书链单独保存为intermediates密钥
+func (region *SRegion) CreateLoadbalancerCertificate(cert *cloudprovider.SLoadbalancerCertificate) (*SLoadbalancerCertificate, error) {
+	block, rest := pem.Decode([]byte(cert.Certificate))
+	if block == nil {
+		return nil, fmt.Errorf("invalid certificate")
+	}
+	payloads := []barbicanSecretPayload{
+		{"certificate", "certificate", string(pem.EncodeToMemory(block))},
+		{"private_key", "private", cert.PrivateKey},
+	}
+	if chain := strings.TrimSpace(string(rest)); len(chain) > 0 {
+		payloads = append(payloads, barbicanSecretPayload{"intermediates", "opaque", chain})
+	}
+	secretRefs := []map[string]string{}
+	for _, p := range payloads {
+		ref, err := region.createBarbicanSecret(fmt.Sprintf("%s-%s", cert.Name, p.name), p.secretType, p.payload)
+		if err != nil {
+			return nil, err
+		}
+		secretRefs = append(secretRefs, map[string]string{"name": p.name, "secret_ref": ref})
+	}
+	params := map[string]interface{}{
+		"name":        cert.Name,
+		"type":        "certificate",
+		"secret_refs": secretRefs,
+	}
+	_, resp, err := region.Post(BARBICAN_SERVICE, "/v1/containers", "", jsonutils.Marshal(params))
+	if err != nil {
+		return nil, err
+	}
+	containerRef, err := resp.GetString("container_ref")
+	if err != nil {
+		return nil, err
+	}
+	return region.GetLoadbalancerCertificate(containerRef)
+}
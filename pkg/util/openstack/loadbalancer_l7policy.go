@@ -0,0 +1,220 @@
+package openstack
+
+import (
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/utils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+// https://docs.openstack.org/api-ref/load-balancer/v2/index.html#l7-rules
+type SLoadbalancerL7Rule struct {
+	ID          string
+	Type        string
+	CompareType string
+	Key         string
+	Value       string
+	Invert      bool
+}
+
+// https://docs.openstack.org/api-ref/load-balancer/v2/index.html#l7-policies
+// 转发规则对应REDIRECT_TO_POOL类型的L7策略, 域名与路径为策略下的L7规则
+type SLoadbalancerL7Policy struct {
+	listener *SLoadbalancerListener
+	rules    []SLoadbalancerL7Rule
+
+	ID                 string
+	Name               string
+	Description        string
+	ProjectID          string
+	AdminStateUp       bool
+	ProvisioningStatus string
+	OperatingStatus    string
+	ListenerID         string
+	Action             string
+	Position           int
+	RedirectPoolID     string
+	RedirectUrl        string
+	Rules              []SOctaviaRef
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+func (policy *SLoadbalancerL7Policy) GetId() string {
+	return policy.ID
+}
+
+func (policy *SLoadbalancerL7Policy) GetName() string {
+	if len(policy.Name) > 0 {
+		return policy.Name
+	}
+	return policy.ID
+}
+
+func (policy *SLoadbalancerL7Policy) GetGlobalId() string {
+	return policy.ID
+}
+
+func (policy *SLoadbalancerL7Policy) GetStatus() string {
+	if !policy.AdminStateUp {
+		return api.LB_STATUS_DISABLED
+	}
+	return api.LB_STATUS_ENABLED
+}
+
+func (policy *SLoadbalancerL7Policy) Refresh() error {
+	new, err := policy.listener.lb.region.GetLoadbalancerL7Policy(policy.ID)
+	if err != nil {
+		return err
+	}
+	policy.rules = nil
+	return jsonutils.Update(policy, new)
+}
+
+func (policy *SLoadbalancerL7Policy) IsEmulated() bool {
+	return false
+}
+
+func (policy *SLoadbalancerL7Policy) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (policy *SLoadbalancerL7Policy) GetProjectId() string {
+	return policy.ProjectID
+}
+
+func (policy *SLoadbalancerL7Policy) getRules() []SLoadbalancerL7Rule {
+	if policy.rules == nil && len(policy.Rules) > 0 {
+		rules, err := policy.listener.lb.region.GetLoadbalancerL7Rules(policy.ID)
+		if err != nil {
+			log.Errorf("failed to get rules of l7policy %s: %v", policy.ID, err)
+			return nil
+		}
+		policy.rules = rules
+	}
+	return policy.rules
+}
+
+func (policy *SLoadbalancerL7Policy) getRuleValue(ruleType, compareType string) string {
+	for _, rule := range policy.getRules() {
+		if rule.Type == ruleType && rule.CompareType == compareType && !rule.Invert {
+			return rule.Value
+		}
+	}
+	return ""
+}
+
+func (policy *SLoadbalancerL7Policy) GetDomain() string {
+	return policy.getRuleValue("HOST_NAME", "EQUAL_TO")
+}
+
+func (policy *SLoadbalancerL7Policy) GetPath() string {
+	return policy.getRuleValue("PATH", "STARTS_WITH")
+}
+
+func (policy *SLoadbalancerL7Policy) GetBackendGroupId() string {
+	return policy.RedirectPoolID
+}
+
+func (policy *SLoadbalancerL7Policy) Delete() error {
+	region := policy.listener.lb.region
+	_, err := region.Delete(OCTAVIA_SERVICE, "/v2/lbaas/l7policies/"+policy.ID, "")
+	if err != nil {
+		return err
+	}
+	return region.waitLoadbalancerActive(policy.listener.lb.ID)
+}
+
+func (region *SRegion) GetLoadbalancerL7Policies(listenerId string) ([]SLoadbalancerL7Policy, error) {
+	_, resp, err := region.List(OCTAVIA_SERVICE, octaviaQuery("/v2/lbaas/l7policies", "listener_id", listenerId), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	policies := []SLoadbalancerL7Policy{}
+	if err := resp.Unmarshal(&policies, "l7policies"); err != nil {
+		return nil, err
+	}
+	result := []SLoadbalancerL7Policy{}
+	for i := range policies {
+		if utils.IsInStringArray(policies[i].Action, []string{"REDIRECT_TO_POOL", "REDIRECT_TO_URL", "REJECT"}) {
+			result = append(result, policies[i])
+		}
+	}
+	return result, nil
+}
+
+func (region *SRegion) GetLoadbalancerL7Policy(policyId string) (*SLoadbalancerL7Policy, error) {
+	_, resp, err := region.Get(OCTAVIA_SERVICE, "/v2/lbaas/l7policies/"+policyId, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	policy := &SLoadbalancerL7Policy{}
+	return policy, resp.Unmarshal(policy, "l7policy")
+}
+
+func (region *SRegion) GetLoadbalancerL7Rules(policyId string) ([]SLoadbalancerL7Rule, error) {
+	_, resp, err := region.List(OCTAVIA_SERVICE, fmt.Sprintf("/v2/lbaas/l7policies/%s/rules", policyId), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	rules := []SLoadbalancerL7Rule{}
+	return rules, resp.Unmarshal(&rules, "rules")
+}
+
+func (region *SRegion) CreateLoadbalancerL7Policy(listener *SLoadbalancerListener, rule *cloudprovider.SLoadbalancerListenerRule) (*SLoadbalancerL7Policy, error) {
+	rules := []map[string]string{}
+	if len(rule.Domain) > 0 {
+		rules = append(rules, map[string]string{
+			"type":         "HOST_NAME",
+			"compare_type": "EQUAL_TO",
+			"value":        rule.Domain,
+		})
+	}
+	if len(rule.Path) > 0 {
+		rules = append(rules, map[string]string{
+			"type":         "PATH",
+			"compare_type": "STARTS_WITH",
+			"value":        rule.Path,
+		})
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("listener rule %s requires domain or path", rule.Name)
+	}
+	policyParams := map[string]interface{}{
+		"name":        rule.Name,
+		"listener_id": listener.ID,
+		"rules":       rules,
+	}
+	switch rule.Action {
+	case api.LB_RULE_ACTION_REDIRECT:
+		scheme := rule.RedirectScheme
+		if len(scheme) == 0 {
+			scheme = listener.GetListenerType()
+		}
+		policyParams["action"] = "REDIRECT_TO_URL"
+		policyParams["redirect_url"] = fmt.Sprintf("%s://%s%s", scheme, rule.RedirectHost, rule.RedirectPath)
+		policyParams["redirect_http_code"] = rule.RedirectCode
+	case api.LB_RULE_ACTION_FIXED_RESPONSE:
+		policyParams["action"] = "REJECT"
+	default:
+		policyParams["action"] = "REDIRECT_TO_POOL"
+		policyParams["redirect_pool_id"] = rule.BackendGroupID
+	}
+	params := map[string]map[string]interface{}{
+		"l7policy": policyParams,
+	}
+	_, resp, err := region.Post(OCTAVIA_SERVICE, "/v2/lbaas/l7policies", "", jsonutils.Marshal(params))
+	if err != nil {
+		return nil, err
+	}
+	policy := &SLoadbalancerL7Policy{listener: listener}
+	if err := resp.Unmarshal(policy, "l7policy"); err != nil {
+		return nil, err
+	}
+	return policy, region.waitLoadbalancerActive(listener.lb.ID)
+}
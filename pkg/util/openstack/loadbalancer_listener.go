@@ -0,0 +1,434 @@
+package openstack
+
+import (
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+const (
+	LB_PROTOCOL_TCP              = "TCP"
+	LB_PROTOCOL_UDP              = "UDP"
+	LB_PROTOCOL_HTTP             = "HTTP"
+	LB_PROTOCOL_HTTPS            = "HTTPS"
+	LB_PROTOCOL_TERMINATED_HTTPS = "TERMINATED_HTTPS"
+)
+
+// https://docs.openstack.org/api-ref/load-balancer/v2/index.html#listeners
+type SLoadbalancerListener struct {
+	lb   *SLoadbalancer
+	pool *SLoadbalancerPool
+
+	ID                     string
+	Name                   string
+	Description            string
+	ProjectID              string
+	AdminStateUp           bool
+	ProvisioningStatus     string
+	OperatingStatus        string
+	Protocol               string
+	ProtocolPort           int
+	ConnectionLimit        int
+	DefaultPoolID          string
+	DefaultTlsContainerRef string
+	SniContainerRefs       []string
+	InsertHeaders          map[string]string
+	L7policies             []SOctaviaRef
+	Loadbalancers          []SOctaviaRef
+	TimeoutClientData      int
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+}
+
+func (listener *SLoadbalancerListener) GetId() string {
+	return listener.ID
+}
+
+func (listener *SLoadbalancerListener) GetName() string {
+	if len(listener.Name) > 0 {
+		return listener.Name
+	}
+	return listener.ID
+}
+
+func (listener *SLoadbalancerListener) GetGlobalId() string {
+	return listener.ID
+}
+
+func (listener *SLoadbalancerListener) GetStatus() string {
+	if !listener.AdminStateUp {
+		return api.LB_STATUS_DISABLED
+	}
+	return api.LB_STATUS_ENABLED
+}
+
+func (listener *SLoadbalancerListener) Refresh() error {
+	new, err := listener.lb.region.GetLoadbalancerListener(listener.ID)
+	if err != nil {
+		return err
+	}
+	listener.pool = nil
+	return jsonutils.Update(listener, new)
+}
+
+func (listener *SLoadbalancerListener) IsEmulated() bool {
+	return false
+}
+
+func (listener *SLoadbalancerListener) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (listener *SLoadbalancerListener) GetProjectId() string {
+	return listener.ProjectID
+}
+
+func (listener *SLoadbalancerListener) getPool() *SLoadbalancerPool {
+	if listener.pool == nil && len(listener.DefaultPoolID) > 0 {
+		pool, err := listener.lb.region.GetLoadbalancerPool(listener.DefaultPoolID)
+		if err != nil {
+			log.Errorf("failed to get default pool %s of listener %s: %v", listener.DefaultPoolID, listener.ID, err)
+			return nil
+		}
+		pool.lb = listener.lb
+		listener.pool = pool
+	}
+	return listener.pool
+}
+
+func (listener *SLoadbalancerListener) getHealthMonitor() *SLoadbalancerHealthMonitor {
+	if pool := listener.getPool(); pool != nil {
+		return pool.getHealthMonitor()
+	}
+	return nil
+}
+
+func (listener *SLoadbalancerListener) GetListenerType() string {
+	switch listener.Protocol {
+	case LB_PROTOCOL_HTTP:
+		return api.LB_LISTENER_TYPE_HTTP
+	case LB_PROTOCOL_HTTPS, LB_PROTOCOL_TERMINATED_HTTPS:
+		return api.LB_LISTENER_TYPE_HTTPS
+	case LB_PROTOCOL_UDP:
+		return api.LB_LISTENER_TYPE_UDP
+	default:
+		return api.LB_LISTENER_TYPE_TCP
+	}
+}
+
+func (listener *SLoadbalancerListener) GetListenerPort() int {
+	return listener.ProtocolPort
+}
+
+func (listener *SLoadbalancerListener) GetScheduler() string {
+	if pool := listener.getPool(); pool != nil {
+		return pool.getScheduler()
+	}
+	return ""
+}
+
+func (listener *SLoadbalancerListener) GetAclStatus() string {
+	return api.LB_BOOL_OFF
+}
+
+func (listener *SLoadbalancerListener) GetAclType() string {
+	return ""
+}
+
+func (listener *SLoadbalancerListener) GetAclId() string {
+	return ""
+}
+
+func (listener *SLoadbalancerListener) GetHealthCheck() string {
+	if hm := listener.getHealthMonitor(); hm != nil && hm.AdminStateUp {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (listener *SLoadbalancerListener) GetHealthCheckType() string {
+	if hm := listener.getHealthMonitor(); hm != nil {
+		switch hm.Type {
+		case "HTTP", "HTTPS":
+			return api.LB_HEALTH_CHECK_HTTP
+		case "UDP-CONNECT":
+			return api.LB_HEALTH_CHECK_UDP
+		}
+	}
+	return api.LB_HEALTH_CHECK_TCP
+}
+
+func (listener *SLoadbalancerListener) GetHealthCheckTimeout() int {
+	if hm := listener.getHealthMonitor(); hm != nil {
+		return hm.Timeout
+	}
+	return 0
+}
+
+func (listener *SLoadbalancerListener) GetHealthCheckInterval() int {
+	if hm := listener.getHealthMonitor(); hm != nil {
+		return hm.Delay
+	}
+	return 0
+}
+
+func (listener *SLoadbalancerListener) GetHealthCheckRise() int {
+	if hm := listener.getHealthMonitor(); hm != nil {
+		return hm.MaxRetries
+	}
+	return 0
+}
+
+func (listener *SLoadbalancerListener) GetHealthCheckFail() int {
+	if hm := listener.getHealthMonitor(); hm != nil {
+		return hm.MaxRetriesDown
+	}
+	return 0
+}
+
+func (listener *SLoadbalancerListener) GetHealthCheckReq() string {
+	return ""
+}
+
+func (listener *SLoadbalancerListener) GetHealthCheckExp() string {
+	return ""
+}
+
+func (listener *SLoadbalancerListener) GetBackendGroupId() string {
+	return listener.DefaultPoolID
+}
+
+func (listener *SLoadbalancerListener) GetBackendServerPort() int {
+	return 0
+}
+
+func (listener *SLoadbalancerListener) GetHealthCheckDomain() string {
+	return ""
+}
+
+func (listener *SLoadbalancerListener) GetHealthCheckURI() string {
+	if hm := listener.getHealthMonitor(); hm != nil {
+		return hm.UrlPath
+	}
+	return ""
+}
+
+func (listener *SLoadbalancerListener) GetHealthCheckCode() string {
+	if hm := listener.getHealthMonitor(); hm != nil && len(hm.ExpectedCodes) > 0 {
+		return expectedCodesToHealthCheckCode(hm.ExpectedCodes)
+	}
+	return ""
+}
+
+func (listener *SLoadbalancerListener) GetStickySession() string {
+	if pool := listener.getPool(); pool != nil && pool.SessionPersistence != nil {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (listener *SLoadbalancerListener) GetStickySessionType() string {
+	if pool := listener.getPool(); pool != nil && pool.SessionPersistence != nil && pool.SessionPersistence.Type == "APP_COOKIE" {
+		return api.LB_STICKY_SESSION_TYPE_SERVER
+	}
+	return api.LB_STICKY_SESSION_TYPE_INSERT
+}
+
+func (listener *SLoadbalancerListener) GetStickySessionCookie() string {
+	if pool := listener.getPool(); pool != nil && pool.SessionPersistence != nil {
+		return pool.SessionPersistence.CookieName
+	}
+	return ""
+}
+
+func (listener *SLoadbalancerListener) GetStickySessionCookieTimeout() int {
+	return 0
+}
+
+func (listener *SLoadbalancerListener) XForwardedForEnabled() bool {
+	return listener.InsertHeaders["X-Forwarded-For"] == "true"
+}
+
+func (listener *SLoadbalancerListener) GzipEnabled() bool {
+	return false
+}
+
+func (listener *SLoadbalancerListener) GetCertificateId() string {
+	return listener.DefaultTlsContainerRef
+}
+
+func (listener *SLoadbalancerListener) GetTLSCipherPolicy() string {
+	return ""
+}
+
+func (listener *SLoadbalancerListener) HTTP2Enabled() bool {
+	return false
+}
+
+func (listener *SLoadbalancerListener) setAdminState(up bool) error {
+	params := map[string]map[string]interface{}{
+		"listener": {
+			"admin_state_up": up,
+		},
+	}
+	_, _, err := listener.lb.region.Update(OCTAVIA_SERVICE, "/v2/lbaas/listeners/"+listener.ID, "", jsonutils.Marshal(params))
+	if err != nil {
+		return err
+	}
+	return listener.lb.region.waitLoadbalancerActive(listener.lb.ID)
+}
+
+func (listener *SLoadbalancerListener) Start() error {
+	return listener.setAdminState(true)
+}
+
+func (listener *SLoadbalancerListener) Stop() error {
+	return listener.setAdminState(false)
+}
+
+func (listener *SLoadbalancerListener) Sync(lblis *cloudprovider.SLoadbalancerListener) error {
+	params := map[string]map[string]interface{}{
+		"listener": listenerParams(lblis),
+	}
+	_, _, err := listener.lb.region.Update(OCTAVIA_SERVICE, "/v2/lbaas/listeners/"+listener.ID, "", jsonutils.Marshal(params))
+	if err != nil {
+		return err
+	}
+	if err := listener.lb.region.waitLoadbalancerActive(listener.lb.ID); err != nil {
+		return err
+	}
+	if err := listener.Refresh(); err != nil {
+		return err
+	}
+	return listener.syncPool(lblis)
+}
+
+func (listener *SLoadbalancerListener) Delete() error {
+	_, err := listener.lb.region.Delete(OCTAVIA_SERVICE, "/v2/lbaas/listeners/"+listener.ID, "")
+	if err != nil {
+		return err
+	}
+	return listener.lb.region.waitLoadbalancerActive(listener.lb.ID)
+}
+
+func (listener *SLoadbalancerListener) GetILoadbalancerListenerRules() ([]cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	policies, err := listener.lb.region.GetLoadbalancerL7Policies(listener.ID)
+	if err != nil {
+		return nil, err
+	}
+	irules := make([]cloudprovider.ICloudLoadbalancerListenerRule, len(policies))
+	for i := range policies {
+		policies[i].listener = listener
+		irules[i] = &policies[i]
+	}
+	return irules, nil
+}
+
+func (listener *SLoadbalancerListener) GetILoadBalancerListenerRuleById(ruleId string) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	policy, err := listener.lb.region.GetLoadbalancerL7Policy(ruleId)
+	if err != nil {
+		return nil, err
+	}
+	policy.listener = listener
+	return policy, nil
+}
+
+func (listener *SLoadbalancerListener) CreateILoadBalancerListenerRule(rule *cloudprovider.SLoadbalancerListenerRule) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	policy, err := listener.lb.region.CreateLoadbalancerL7Policy(listener, rule)
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// 调度算法, 会话保持与健康检查属于默认后端服务器组
+func (listener *SLoadbalancerListener) syncPool(lblis *cloudprovider.SLoadbalancerListener) error {
+	listener.pool = nil
+	pool := listener.getPool()
+	if pool == nil {
+		return nil
+	}
+	if err := pool.syncListener(lblis); err != nil {
+		return err
+	}
+	return pool.syncHealthMonitor(lblis)
+}
+
+func listenerParams(lblis *cloudprovider.SLoadbalancerListener) map[string]interface{} {
+	params := map[string]interface{}{
+		"name":        lblis.Name,
+		"description": lblis.Description,
+	}
+	if len(lblis.BackendGroupID) > 0 {
+		params["default_pool_id"] = lblis.BackendGroupID
+	}
+	if lblis.ListenerType == api.LB_LISTENER_TYPE_HTTPS && len(lblis.CertificateID) > 0 {
+		params["default_tls_container_ref"] = lblis.CertificateID
+	}
+	if lblis.ListenerType == api.LB_LISTENER_TYPE_HTTP || lblis.ListenerType == api.LB_LISTENER_TYPE_HTTPS {
+		xff := "false"
+		if lblis.XForwardedFor {
+			xff = "true"
+		}
+		params["insert_headers"] = map[string]string{"X-Forwarded-For": xff}
+	}
+	return params
+}
+
+func listenerProtocol(listenerType string) string {
+	switch listenerType {
+	case api.LB_LISTENER_TYPE_HTTP:
+		return LB_PROTOCOL_HTTP
+	case api.LB_LISTENER_TYPE_HTTPS:
+		return LB_PROTOCOL_TERMINATED_HTTPS
+	case api.LB_LISTENER_TYPE_UDP:
+		return LB_PROTOCOL_UDP
+	default:
+		return LB_PROTOCOL_TCP
+	}
+}
+
+func (region *SRegion) GetLoadbalancerListeners(lbId string) ([]SLoadbalancerListener, error) {
+	_, resp, err := region.List(OCTAVIA_SERVICE, octaviaQuery("/v2/lbaas/listeners", "loadbalancer_id", lbId), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	listeners := []SLoadbalancerListener{}
+	return listeners, resp.Unmarshal(&listeners, "listeners")
+}
+
+func (region *SRegion) GetLoadbalancerListener(listenerId string) (*SLoadbalancerListener, error) {
+	_, resp, err := region.Get(OCTAVIA_SERVICE, "/v2/lbaas/listeners/"+listenerId, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	listener := &SLoadbalancerListener{}
+	return listener, resp.Unmarshal(listener, "listener")
+}
+
+func (region *SRegion) CreateLoadbalancerListener(lb *SLoadbalancer, lblis *cloudprovider.SLoadbalancerListener) (*SLoadbalancerListener, error) {
+	params := listenerParams(lblis)
+	params["loadbalancer_id"] = lb.ID
+	params["protocol"] = listenerProtocol(lblis.ListenerType)
+	params["protocol_port"] = lblis.ListenerPort
+	_, resp, err := region.Post(OCTAVIA_SERVICE, "/v2/lbaas/listeners", "", jsonutils.Marshal(map[string]interface{}{"listener": params}))
+	if err != nil {
+		return nil, err
+	}
+	listener := &SLoadbalancerListener{lb: lb}
+	if err := resp.Unmarshal(listener, "listener"); err != nil {
+		return nil, err
+	}
+	if err := region.waitLoadbalancerActive(lb.ID); err != nil {
+		return nil, err
+	}
+	if err := listener.syncPool(lblis); err != nil {
+		return nil, fmt.Errorf("listener %s created, but failed to sync backend group: %v", listener.ID, err)
+	}
+	return listener, nil
+}
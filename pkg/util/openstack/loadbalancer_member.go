@@ -0,0 +1,176 @@
+package openstack
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+)
+
+// https://docs.openstack.org/api-ref/load-balancer/v2/index.html#members
+type SLoadbalancerMember struct {
+	pool     *SLoadbalancerPool
+	serverId string
+
+	ID                 string
+	Name               string
+	ProjectID          string
+	AdminStateUp       bool
+	ProvisioningStatus string
+	OperatingStatus    string
+	Address            string
+	ProtocolPort       int
+	Weight             int
+	SubnetID           string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+func (member *SLoadbalancerMember) GetId() string {
+	return member.ID
+}
+
+func (member *SLoadbalancerMember) GetName() string {
+	if len(member.Name) > 0 {
+		return member.Name
+	}
+	return member.ID
+}
+
+func (member *SLoadbalancerMember) GetGlobalId() string {
+	return member.ID
+}
+
+func (member *SLoadbalancerMember) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (member *SLoadbalancerMember) Refresh() error {
+	new, err := member.pool.lb.region.GetLoadbalancerMember(member.pool.ID, member.ID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(member, new)
+}
+
+func (member *SLoadbalancerMember) IsEmulated() bool {
+	return false
+}
+
+func (member *SLoadbalancerMember) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (member *SLoadbalancerMember) GetProjectId() string {
+	return member.ProjectID
+}
+
+func (member *SLoadbalancerMember) GetWeight() int {
+	return member.Weight
+}
+
+func (member *SLoadbalancerMember) GetPort() int {
+	return member.ProtocolPort
+}
+
+func (member *SLoadbalancerMember) GetBackendType() string {
+	return api.LB_BACKEND_GUEST
+}
+
+func (member *SLoadbalancerMember) GetBackendRole() string {
+	return api.LB_BACKEND_ROLE_DEFAULT
+}
+
+// 成员只记录ip地址, 通过neutron端口反查所属的虚拟机
+func (member *SLoadbalancerMember) GetBackendId() string {
+	if len(member.serverId) > 0 {
+		return member.serverId
+	}
+	params := url.Values{}
+	params.Add("fixed_ips", "ip_address="+member.Address)
+	if len(member.SubnetID) > 0 {
+		params.Add("fixed_ips", "subnet_id="+member.SubnetID)
+	}
+	ports, err := member.pool.lb.region.getPorts(params)
+	if err != nil {
+		log.Errorf("failed to find port of member %s(%s): %v", member.ID, member.Address, err)
+		return ""
+	}
+	for _, port := range ports {
+		if strings.HasPrefix(port.DeviceOwner, "compute:") {
+			member.serverId = port.DeviceID
+			break
+		}
+	}
+	return member.serverId
+}
+
+func (member *SLoadbalancerMember) Delete() error {
+	region := member.pool.lb.region
+	_, err := region.Delete(OCTAVIA_SERVICE, fmt.Sprintf("/v2/lbaas/pools/%s/members/%s", member.pool.ID, member.ID), "")
+	if err != nil {
+		return err
+	}
+	return region.waitLoadbalancerActive(member.pool.lb.ID)
+}
+
+func (region *SRegion) GetLoadbalancerMembers(poolId string) ([]SLoadbalancerMember, error) {
+	_, resp, err := region.List(OCTAVIA_SERVICE, fmt.Sprintf("/v2/lbaas/pools/%s/members", poolId), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	members := []SLoadbalancerMember{}
+	return members, resp.Unmarshal(&members, "members")
+}
+
+func (region *SRegion) GetLoadbalancerMember(poolId, memberId string) (*SLoadbalancerMember, error) {
+	_, resp, err := region.Get(OCTAVIA_SERVICE, fmt.Sprintf("/v2/lbaas/pools/%s/members/%s", poolId, memberId), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	member := &SLoadbalancerMember{}
+	return member, resp.Unmarshal(member, "member")
+}
+
+func (region *SRegion) CreateLoadbalancerMember(pool *SLoadbalancerPool, serverId string, weight int, port int) (*SLoadbalancerMember, error) {
+	params := url.Values{}
+	params.Set("device_id", serverId)
+	ports, err := region.getPorts(params)
+	if err != nil {
+		return nil, err
+	}
+	// 优先使用与vip位于同一子网的网卡地址
+	var address *FixedIPs
+	for i := range ports {
+		for j := range ports[i].FixedIps {
+			if address == nil || ports[i].FixedIps[j].SubnetID == pool.lb.VipSubnetID {
+				address = &ports[i].FixedIps[j]
+			}
+		}
+	}
+	if address == nil {
+		return nil, fmt.Errorf("failed to find ip address of server %s", serverId)
+	}
+	body := map[string]map[string]interface{}{
+		"member": {
+			"address":       address.IpAddress,
+			"subnet_id":     address.SubnetID,
+			"protocol_port": port,
+			"weight":        weight,
+		},
+	}
+	_, resp, err := region.Post(OCTAVIA_SERVICE, fmt.Sprintf("/v2/lbaas/pools/%s/members", pool.ID), "", jsonutils.Marshal(body))
+	if err != nil {
+		return nil, err
+	}
+	member := &SLoadbalancerMember{pool: pool, serverId: serverId}
+	if err := resp.Unmarshal(member, "member"); err != nil {
+		return nil, err
+	}
+	return member, region.waitLoadbalancerActive(pool.lb.ID)
+}
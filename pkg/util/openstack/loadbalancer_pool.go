@@ -0,0 +1,424 @@
+package openstack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+)
+
+type SSessionPersistence struct {
+	Type       string
+	CookieName string
+}
+
+// https://docs.openstack.org/api-ref/load-balancer/v2/index.html#pools
+type SLoadbalancerPool struct {
+	lb *SLoadbalancer
+	hm *SLoadbalancerHealthMonitor
+
+	ID                 string
+	Name               string
+	Description        string
+	ProjectID          string
+	AdminStateUp       bool
+	ProvisioningStatus string
+	OperatingStatus    string
+	Protocol           string
+	LbAlgorithm        string
+	SessionPersistence *SSessionPersistence
+	HealthmonitorID    string
+	Members            []SOctaviaRef
+	Listeners          []SOctaviaRef
+	Loadbalancers      []SOctaviaRef
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// https://docs.openstack.org/api-ref/load-balancer/v2/index.html#health-monitor
+type SLoadbalancerHealthMonitor struct {
+	ID             string
+	Name           string
+	Type           string
+	Delay          int
+	Timeout        int
+	MaxRetries     int
+	MaxRetriesDown int
+	HttpMethod     string
+	UrlPath        string
+	ExpectedCodes  string
+	AdminStateUp   bool
+	Pools          []SOctaviaRef
+}
+
+func (pool *SLoadbalancerPool) GetId() string {
+	return pool.ID
+}
+
+func (pool *SLoadbalancerPool) GetName() string {
+	if len(pool.Name) > 0 {
+		return pool.Name
+	}
+	return pool.ID
+}
+
+func (pool *SLoadbalancerPool) GetGlobalId() string {
+	return pool.ID
+}
+
+func (pool *SLoadbalancerPool) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (pool *SLoadbalancerPool) Refresh() error {
+	new, err := pool.lb.region.GetLoadbalancerPool(pool.ID)
+	if err != nil {
+		return err
+	}
+	pool.hm = nil
+	return jsonutils.Update(pool, new)
+}
+
+func (pool *SLoadbalancerPool) IsEmulated() bool {
+	return false
+}
+
+func (pool *SLoadbalancerPool) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (pool *SLoadbalancerPool) GetProjectId() string {
+	return pool.ProjectID
+}
+
+func (pool *SLoadbalancerPool) IsDefault() bool {
+	return false
+}
+
+func (pool *SLoadbalancerPool) GetType() string {
+	return api.LB_BACKENDGROUP_TYPE_NORMAL
+}
+
+func (pool *SLoadbalancerPool) GetILoadbalancerBackends() ([]cloudprovider.ICloudLoadbalancerBackend, error) {
+	members, err := pool.lb.region.GetLoadbalancerMembers(pool.ID)
+	if err != nil {
+		return nil, err
+	}
+	ibackends := make([]cloudprovider.ICloudLoadbalancerBackend, len(members))
+	for i := range members {
+		members[i].pool = pool
+		ibackends[i] = &members[i]
+	}
+	return ibackends, nil
+}
+
+func (pool *SLoadbalancerPool) AddBackendServer(serverId string, weight int, port int) (cloudprovider.ICloudLoadbalancerBackend, error) {
+	member, err := pool.lb.region.CreateLoadbalancerMember(pool, serverId, weight, port)
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (pool *SLoadbalancerPool) RemoveBackendServer(serverId string, weight int, port int) error {
+	members, err := pool.lb.region.GetLoadbalancerMembers(pool.ID)
+	if err != nil {
+		return err
+	}
+	for i := range members {
+		members[i].pool = pool
+		if members[i].ProtocolPort == port && members[i].GetBackendId() == serverId {
+			return members[i].Delete()
+		}
+	}
+	return nil
+}
+
+func (pool *SLoadbalancerPool) Delete() error {
+	_, err := pool.lb.region.Delete(OCTAVIA_SERVICE, "/v2/lbaas/pools/"+pool.ID, "")
+	if err != nil {
+		return err
+	}
+	return pool.lb.region.waitLoadbalancerActive(pool.lb.ID)
+}
+
+func (pool *SLoadbalancerPool) Sync(name string) error {
+	params := map[string]map[string]interface{}{
+		"pool": {
+			"name": name,
+		},
+	}
+	_, _, err := pool.lb.region.Update(OCTAVIA_SERVICE, "/v2/lbaas/pools/"+pool.ID, "", jsonutils.Marshal(params))
+	if err != nil {
+		return err
+	}
+	return pool.lb.region.waitLoadbalancerActive(pool.lb.ID)
+}
+
+func (pool *SLoadbalancerPool) getHealthMonitor() *SLoadbalancerHealthMonitor {
+	if pool.hm == nil && len(pool.HealthmonitorID) > 0 {
+		hm, err := pool.lb.region.GetLoadbalancerHealthMonitor(pool.HealthmonitorID)
+		if err != nil {
+			log.Errorf("failed to get health monitor %s of pool %s: %v", pool.HealthmonitorID, pool.ID, err)
+			return nil
+		}
+		pool.hm = hm
+	}
+	return pool.hm
+}
+
+func (pool *SLoadbalancerPool) getScheduler() string {
+	switch pool.LbAlgorithm {
+	case "LEAST_CONNECTIONS":
+		return api.LB_SCHEDULER_WLC
+	case "SOURCE_IP":
+		return api.LB_SCHEDULER_SCH
+	case "SOURCE_IP_PORT":
+		return api.LB_SCHEDULER_TCH
+	default:
+		return api.LB_SCHEDULER_WRR
+	}
+}
+
+func lbAlgorithm(scheduler string) string {
+	switch scheduler {
+	case api.LB_SCHEDULER_WLC:
+		return "LEAST_CONNECTIONS"
+	case api.LB_SCHEDULER_SCH:
+		return "SOURCE_IP"
+	case api.LB_SCHEDULER_TCH:
+		return "SOURCE_IP_PORT"
+	default:
+		return "ROUND_ROBIN"
+	}
+}
+
+// 监听的调度算法与会话保持同步到默认后端服务器组
+func (pool *SLoadbalancerPool) syncListener(lblis *cloudprovider.SLoadbalancerListener) error {
+	params := map[string]interface{}{}
+	if len(lblis.Scheduler) > 0 {
+		params["lb_algorithm"] = lbAlgorithm(lblis.Scheduler)
+	}
+	if lblis.StickySession == api.LB_BOOL_ON {
+		persistence := map[string]string{}
+		switch {
+		case lblis.ListenerType != api.LB_LISTENER_TYPE_HTTP && lblis.ListenerType != api.LB_LISTENER_TYPE_HTTPS:
+			persistence["type"] = "SOURCE_IP"
+		case lblis.StickySessionType == api.LB_STICKY_SESSION_TYPE_SERVER:
+			persistence["type"] = "APP_COOKIE"
+			persistence["cookie_name"] = lblis.StickySessionCookie
+		default:
+			persistence["type"] = "HTTP_COOKIE"
+		}
+		params["session_persistence"] = persistence
+	}
+	body := jsonutils.NewDict()
+	body.Add(jsonutils.Marshal(params), "pool")
+	if lblis.StickySession != api.LB_BOOL_ON {
+		// 关闭会话保持需要显式传null
+		body.Add(jsonutils.JSONNull, "pool", "session_persistence")
+	}
+	_, _, err := pool.lb.region.Update(OCTAVIA_SERVICE, "/v2/lbaas/pools/"+pool.ID, "", body)
+	if err != nil {
+		return err
+	}
+	return pool.lb.region.waitLoadbalancerActive(pool.lb.ID)
+}
+
+func (pool *SLoadbalancerPool) syncHealthMonitor(lblis *cloudprovider.SLoadbalancerListener) error {
+	region := pool.lb.region
+	if lblis.HealthCheck != api.LB_BOOL_ON {
+		if len(pool.HealthmonitorID) == 0 {
+			return nil
+		}
+		_, err := region.Delete(OCTAVIA_SERVICE, "/v2/lbaas/healthmonitors/"+pool.HealthmonitorID, "")
+		if err != nil {
+			return err
+		}
+		return region.waitLoadbalancerActive(pool.lb.ID)
+	}
+
+	params := map[string]interface{}{}
+	if lblis.HealthCheckInterval > 0 {
+		params["delay"] = lblis.HealthCheckInterval
+	}
+	if lblis.HealthCheckTimeout > 0 {
+		// octavia要求超时时间不大于检查间隔
+		timeout := lblis.HealthCheckTimeout
+		if lblis.HealthCheckInterval > 0 && timeout > lblis.HealthCheckInterval {
+			timeout = lblis.HealthCheckInterval
+		}
+		params["timeout"] = timeout
+	}
+	if lblis.HealthCheckRise > 0 {
+		params["max_retries"] = lblis.HealthCheckRise
+	}
+	if lblis.HealthCheckFail > 0 {
+		params["max_retries_down"] = lblis.HealthCheckFail
+	}
+	hmType := healthMonitorType(lblis.ListenerType)
+	if hmType == "HTTP" {
+		if len(lblis.HealthCheckURI) > 0 {
+			params["url_path"] = lblis.HealthCheckURI
+		}
+		if len(lblis.HealthCheckHttpCode) > 0 {
+			params["expected_codes"] = healthCheckCodeToExpectedCodes(lblis.HealthCheckHttpCode)
+		}
+	}
+
+	if hm := pool.getHealthMonitor(); hm != nil && hm.Type == hmType {
+		_, _, err := region.Update(OCTAVIA_SERVICE, "/v2/lbaas/healthmonitors/"+hm.ID, "", jsonutils.Marshal(map[string]interface{}{"healthmonitor": params}))
+		if err != nil {
+			return err
+		}
+		return region.waitLoadbalancerActive(pool.lb.ID)
+	} else if hm != nil {
+		// 健康检查类型不能修改, 需要重建
+		if _, err := region.Delete(OCTAVIA_SERVICE, "/v2/lbaas/healthmonitors/"+hm.ID, ""); err != nil {
+			return err
+		}
+		if err := region.waitLoadbalancerActive(pool.lb.ID); err != nil {
+			return err
+		}
+	}
+
+	params["pool_id"] = pool.ID
+	params["type"] = hmType
+	for k, v := range map[string]int{"delay": 5, "timeout": 5, "max_retries": 3} {
+		if _, ok := params[k]; !ok {
+			params[k] = v
+		}
+	}
+	_, _, err := region.Post(OCTAVIA_SERVICE, "/v2/lbaas/healthmonitors", "", jsonutils.Marshal(map[string]interface{}{"healthmonitor": params}))
+	if err != nil {
+		return err
+	}
+	return region.waitLoadbalancerActive(pool.lb.ID)
+}
+
+func healthMonitorType(listenerType string) string {
+	switch listenerType {
+	case api.LB_LISTENER_TYPE_HTTP, api.LB_LISTENER_TYPE_HTTPS:
+		return "HTTP"
+	case api.LB_LISTENER_TYPE_UDP:
+		return "UDP-CONNECT"
+	default:
+		return "TCP"
+	}
+}
+
+// http_2xx,http_3xx => 200-399
+func healthCheckCodeToExpectedCodes(codes string) string {
+	low, high := 0, 0
+	for _, code := range strings.Split(codes, ",") {
+		code = strings.TrimSpace(code)
+		if len(code) != len(api.LB_HEALTH_CHECK_HTTP_CODE_2xx) {
+			continue
+		}
+		c := int(code[5]-'0') * 100
+		if c < 100 || c > 500 {
+			continue
+		}
+		if low == 0 || c < low {
+			low = c
+		}
+		if c+99 > high {
+			high = c + 99
+		}
+	}
+	if low == 0 {
+		return "200-399"
+	}
+	return fmt.Sprintf("%d-%d", low, high)
+}
+
+// 200,202 或 200-399 => http_2xx,http_3xx
+func expectedCodesToHealthCheckCode(expected string) string {
+	classes := map[int]bool{}
+	for _, seg := range strings.Split(expected, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(seg), "-", 2)
+		low, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		high := low
+		if len(bounds) == 2 {
+			high, err = strconv.Atoi(bounds[1])
+			if err != nil {
+				continue
+			}
+		}
+		for c := low / 100; c <= high/100; c++ {
+			classes[c] = true
+		}
+	}
+	codes := []string{}
+	for c := 1; c <= 5; c++ {
+		if classes[c] {
+			codes = append(codes, fmt.Sprintf("http_%dxx", c))
+		}
+	}
+	return strings.Join(codes, ",")
+}
+
+func (region *SRegion) GetLoadbalancerPools(lbId string) ([]SLoadbalancerPool, error) {
+	_, resp, err := region.List(OCTAVIA_SERVICE, octaviaQuery("/v2/lbaas/pools", "loadbalancer_id", lbId), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	pools := []SLoadbalancerPool{}
+	return pools, resp.Unmarshal(&pools, "pools")
+}
+
+func (region *SRegion) GetLoadbalancerPool(poolId string) (*SLoadbalancerPool, error) {
+	_, resp, err := region.Get(OCTAVIA_SERVICE, "/v2/lbaas/pools/"+poolId, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	pool := &SLoadbalancerPool{}
+	return pool, resp.Unmarshal(pool, "pool")
+}
+
+func (region *SRegion) GetLoadbalancerHealthMonitor(hmId string) (*SLoadbalancerHealthMonitor, error) {
+	_, resp, err := region.Get(OCTAVIA_SERVICE, "/v2/lbaas/healthmonitors/"+hmId, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	hm := &SLoadbalancerHealthMonitor{}
+	return hm, resp.Unmarshal(hm, "healthmonitor")
+}
+
+// 后端服务器组创建时还不知道会被哪个监听使用, 统一使用HTTP协议, 可被TCP、HTTP及终结型HTTPS监听引用
+func (region *SRegion) CreateLoadbalancerPool(lb *SLoadbalancer, group *cloudprovider.SLoadbalancerBackendGroup) (*SLoadbalancerPool, error) {
+	params := map[string]map[string]interface{}{
+		"pool": {
+			"name":            group.Name,
+			"loadbalancer_id": lb.ID,
+			"protocol":        LB_PROTOCOL_HTTP,
+			"lb_algorithm":    "ROUND_ROBIN",
+		},
+	}
+	_, resp, err := region.Post(OCTAVIA_SERVICE, "/v2/lbaas/pools", "", jsonutils.Marshal(params))
+	if err != nil {
+		return nil, err
+	}
+	pool := &SLoadbalancerPool{lb: lb}
+	if err := resp.Unmarshal(pool, "pool"); err != nil {
+		return nil, err
+	}
+	if err := region.waitLoadbalancerActive(lb.ID); err != nil {
+		return nil, err
+	}
+	for _, backend := range group.Backends {
+		if _, err := region.CreateLoadbalancerMember(pool, backend.ExternalID, backend.Weight, backend.Port); err != nil {
+			return nil, err
+		}
+	}
+	return pool, nil
+}
@@ -54,11 +54,15 @@ type SPort struct {
 }
 
 func (region *SRegion) GetPorts(macAddress string) ([]SPort, error) {
-	base := fmt.Sprintf("/v2.0/ports")
 	params := url.Values{}
 	if len(macAddress) > 0 {
 		params.Set("mac_address", macAddress)
 	}
+	return region.getPorts(params)
+}
+
+func (region *SRegion) getPorts(params url.Values) ([]SPort, error) {
+	base := fmt.Sprintf("/v2.0/ports")
 	url := fmt.Sprintf("%s?%s", base, params.Encode())
 	_, resp, err := region.List("network", url, "", nil)
 	if err != nil {
@@ -0,0 +1,78 @@
+package shell
+
+import (
+	"yunion.io/x/onecloud/pkg/util/openstack"
+	"yunion.io/x/onecloud/pkg/util/shellutils"
+)
+
+func init() {
+	type LoadbalancerListOptions struct {
+	}
+	shellutils.R(&LoadbalancerListOptions{}, "lb-list", "List loadbalancers", func(cli *openstack.SRegion, args *LoadbalancerListOptions) error {
+		lbs, err := cli.GetLoadbalancers()
+		if err != nil {
+			return err
+		}
+		printList(lbs, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerListenerListOptions struct {
+		LB string `help:"Loadbalancer ID"`
+	}
+	shellutils.R(&LoadbalancerListenerListOptions{}, "lb-listener-list", "List loadbalancer listeners", func(cli *openstack.SRegion, args *LoadbalancerListenerListOptions) error {
+		listeners, err := cli.GetLoadbalancerListeners(args.LB)
+		if err != nil {
+			return err
+		}
+		printList(listeners, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerL7PolicyListOptions struct {
+		LISTENER string `help:"Listener ID"`
+	}
+	shellutils.R(&LoadbalancerL7PolicyListOptions{}, "lb-l7policy-list", "List loadbalancer l7 policies", func(cli *openstack.SRegion, args *LoadbalancerL7PolicyListOptions) error {
+		policies, err := cli.GetLoadbalancerL7Policies(args.LISTENER)
+		if err != nil {
+			return err
+		}
+		printList(policies, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerPoolListOptions struct {
+		LB string `help:"Loadbalancer ID"`
+	}
+	shellutils.R(&LoadbalancerPoolListOptions{}, "lb-pool-list", "List loadbalancer pools", func(cli *openstack.SRegion, args *LoadbalancerPoolListOptions) error {
+		pools, err := cli.GetLoadbalancerPools(args.LB)
+		if err != nil {
+			return err
+		}
+		printList(pools, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerMemberListOptions struct {
+		POOL string `help:"Pool ID"`
+	}
+	shellutils.R(&LoadbalancerMemberListOptions{}, "lb-member-list", "List members of loadbalancer pool", func(cli *openstack.SRegion, args *LoadbalancerMemberListOptions) error {
+		members, err := cli.GetLoadbalancerMembers(args.POOL)
+		if err != nil {
+			return err
+		}
+		printList(members, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerCertificateListOptions struct {
+	}
+	shellutils.R(&LoadbalancerCertificateListOptions{}, "lb-cert-list", "List barbican certificate containers", func(cli *openstack.SRegion, args *LoadbalancerCertificateListOptions) error {
+		certs, err := cli.GetLoadbalancerCertificates()
+		if err != nil {
+			return err
+		}
+		printList(certs, 0, 0, 0, nil)
+		return nil
+	})
+}
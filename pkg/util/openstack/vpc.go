@@ -119,6 +119,26 @@ func (vpc *SVpc) GetIRouteTables() ([]cloudprovider.ICloudRouteTable, error) {
 	return rts, nil
 }
 
+func (vpc *SVpc) GetINatGateways() ([]cloudprovider.ICloudNatGateway, error) {
+	return []cloudprovider.ICloudNatGateway{}, nil
+}
+
+func (vpc *SVpc) CreateINatGateway(opts *cloudprovider.SNatGatewayCreateOptions) (cloudprovider.ICloudNatGateway, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (vpc *SVpc) GetIVpcPeerings() ([]cloudprovider.ICloudVpcPeering, error) {
+	return []cloudprovider.ICloudVpcPeering{}, nil
+}
+
+func (vpc *SVpc) GetIVpcPeeringById(id string) (cloudprovider.ICloudVpcPeering, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (vpc *SVpc) CreateIVpcPeering(opts *cloudprovider.SVpcPeeringCreateOptions) (cloudprovider.ICloudVpcPeering, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
 func (vpc *SVpc) fetchWires() error {
 	if len(vpc.region.izones) == 0 {
 		if err := vpc.region.fetchZones(); err != nil {
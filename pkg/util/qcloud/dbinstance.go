@@ -0,0 +1,389 @@
+package qcloud
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+type SDBInstance struct {
+	region *SRegion
+
+	InstanceId   string
+	InstanceName string
+	// 1: 主实例, 2: 灾备实例, 3: 只读实例
+	InstanceType int
+	// 0: 创建中, 1: 运行中, 4: 隔离中, 5: 已隔离
+	Status int
+	// 0: 无任务, 1: 升级中, 2: 数据导入中, 3: 开放Wan中, 4: 关闭Wan中
+	TaskStatus    int
+	Cpu           int
+	Memory        int
+	Volume        int
+	DeviceType    string
+	EngineVersion string
+	// 0: 包年包月, 1: 按量计费
+	PayType      int
+	CreateTime   time.Time
+	DeadlineTime string
+	Vip          string
+	Vport        int
+	WanStatus    int
+	WanDomain    string
+	WanPort      int
+	UniqVpcId    string
+	UniqSubnetId string
+	Zone         string
+}
+
+func (self *SDBInstance) GetId() string {
+	return self.InstanceId
+}
+
+func (self *SDBInstance) GetName() string {
+	if len(self.InstanceName) > 0 {
+		return self.InstanceName
+	}
+	return self.InstanceId
+}
+
+func (self *SDBInstance) GetGlobalId() string {
+	return self.InstanceId
+}
+
+func (self *SDBInstance) GetStatus() string {
+	switch self.Status {
+	case 0:
+		return api.DBINSTANCE_STATUS_DEPLOYING
+	case 1:
+		if self.TaskStatus != 0 {
+			return api.DBINSTANCE_STATUS_MAINTENANCE
+		}
+		return api.DBINSTANCE_STATUS_RUNNING
+	case 4, 5:
+		return api.DBINSTANCE_STATUS_DELETING
+	default:
+		return api.DBINSTANCE_STATUS_UNKNOWN
+	}
+}
+
+func (self *SDBInstance) Refresh() error {
+	instance, err := self.region.GetDBInstance(self.InstanceId)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, instance)
+}
+
+func (self *SDBInstance) IsEmulated() bool {
+	return false
+}
+
+func (self *SDBInstance) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SDBInstance) GetBillingType() string {
+	if self.PayType == 0 {
+		return models.BILLING_TYPE_PREPAID
+	}
+	return models.BILLING_TYPE_POSTPAID
+}
+
+func (self *SDBInstance) GetExpiredAt() time.Time {
+	// 按量计费实例的到期时间为 0000-00-00 00:00:00
+	expired, err := time.Parse("2006-01-02 15:04:05", self.DeadlineTime)
+	if err != nil {
+		return time.Time{}
+	}
+	return expired
+}
+
+func (self *SDBInstance) GetEngine() string {
+	return api.DBINSTANCE_ENGINE_MYSQL
+}
+
+func (self *SDBInstance) GetEngineVersion() string {
+	return self.EngineVersion
+}
+
+func (self *SDBInstance) GetInstanceType() string {
+	return fmt.Sprintf("%dC%dM", self.Cpu, self.Memory)
+}
+
+func (self *SDBInstance) GetVcpuCount() int {
+	return self.Cpu
+}
+
+func (self *SDBInstance) GetVmemSizeMB() int {
+	return self.Memory
+}
+
+func (self *SDBInstance) GetDiskSizeGB() int {
+	return self.Volume
+}
+
+func (self *SDBInstance) GetCategory() string {
+	// UNIVERSAL: 通用型, EXCLUSIVE: 独享型, BASIC: 基础版
+	return strings.ToLower(self.DeviceType)
+}
+
+func (self *SDBInstance) GetStorageType() string {
+	return ""
+}
+
+func (self *SDBInstance) GetCreateTime() time.Time {
+	return self.CreateTime
+}
+
+func (self *SDBInstance) GetPort() int {
+	return self.Vport
+}
+
+func (self *SDBInstance) GetConnectionStr() string {
+	if self.WanStatus == 1 && len(self.WanDomain) > 0 {
+		return fmt.Sprintf("%s:%d", self.WanDomain, self.WanPort)
+	}
+	return ""
+}
+
+func (self *SDBInstance) GetInternalConnectionStr() string {
+	if len(self.Vip) > 0 {
+		return fmt.Sprintf("%s:%d", self.Vip, self.Vport)
+	}
+	return ""
+}
+
+func (self *SDBInstance) GetVpcId() string {
+	return self.UniqVpcId
+}
+
+func (self *SDBInstance) GetNetworkId() string {
+	return self.UniqSubnetId
+}
+
+func (self *SDBInstance) GetZoneId() string {
+	zone, err := self.region.getZoneById(self.Zone)
+	if err != nil {
+		return ""
+	}
+	return zone.GetGlobalId()
+}
+
+func (self *SDBInstance) GetIDBInstanceBackups() ([]cloudprovider.ICloudDBInstanceBackup, error) {
+	backups, err := self.region.GetDBInstanceBackups(self.InstanceId)
+	if err != nil {
+		return nil, err
+	}
+	ibackups := make([]cloudprovider.ICloudDBInstanceBackup, len(backups))
+	for i := 0; i < len(backups); i++ {
+		backups[i].instanceId = self.InstanceId
+		ibackups[i] = &backups[i]
+	}
+	return ibackups, nil
+}
+
+func (self *SDBInstance) Start() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SDBInstance) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SDBInstance) Reboot() error {
+	return self.region.RestartDBInstance(self.InstanceId)
+}
+
+func (self *SDBInstance) Delete() error {
+	return self.region.IsolateDBInstance(self.InstanceId)
+}
+
+func (self *SRegion) GetDBInstances(instanceIds []string, offset int, limit int) ([]SDBInstance, int, error) {
+	if limit > 100 || limit <= 0 {
+		limit = 100
+	}
+	params := make(map[string]string)
+	params["Limit"] = fmt.Sprintf("%d", limit)
+	params["Offset"] = fmt.Sprintf("%d", offset)
+	for i, instanceId := range instanceIds {
+		params[fmt.Sprintf("InstanceIds.%d", i)] = instanceId
+	}
+	body, err := self.cdbRequest("DescribeDBInstances", params)
+	if err != nil {
+		return nil, 0, err
+	}
+	instances := make([]SDBInstance, 0)
+	err = body.Unmarshal(&instances, "Items")
+	if err != nil {
+		return nil, 0, err
+	}
+	total, _ := body.Float("TotalCount")
+	return instances, int(total), nil
+}
+
+func (self *SRegion) GetDBInstance(instanceId string) (*SDBInstance, error) {
+	instances, _, err := self.GetDBInstances([]string{instanceId}, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	instances[0].region = self
+	return &instances[0], nil
+}
+
+func (self *SRegion) RestartDBInstance(instanceId string) error {
+	params := map[string]string{"InstanceIds.0": instanceId}
+	_, err := self.cdbRequest("RestartDBInstances", params)
+	return err
+}
+
+func (self *SRegion) IsolateDBInstance(instanceId string) error {
+	// 按量计费实例隔离后会被自动释放
+	params := map[string]string{"InstanceId": instanceId}
+	_, err := self.cdbRequest("IsolateDBInstance", params)
+	return err
+}
+
+func (self *SRegion) GetIDBInstances() ([]cloudprovider.ICloudDBInstance, error) {
+	instances := make([]SDBInstance, 0)
+	for {
+		parts, total, err := self.GetDBInstances(nil, len(instances), 100)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, parts...)
+		if len(instances) >= total || len(parts) == 0 {
+			break
+		}
+	}
+	iinstances := make([]cloudprovider.ICloudDBInstance, len(instances))
+	for i := 0; i < len(instances); i++ {
+		instances[i].region = self
+		iinstances[i] = &instances[i]
+	}
+	return iinstances, nil
+}
+
+func (self *SRegion) GetIDBInstanceById(instanceId string) (cloudprovider.ICloudDBInstance, error) {
+	instance, err := self.GetDBInstance(instanceId)
+	if err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+type SDBInstanceBackup struct {
+	instanceId string
+
+	BackupId   int64
+	Name       string
+	Size       int64
+	Date       time.Time
+	StartTime  time.Time
+	FinishTime time.Time
+	// SUCCESS, FAILED, RUNNING
+	Status string
+	// manual 或 automatic
+	Way string
+}
+
+func (self *SDBInstanceBackup) GetId() string {
+	return fmt.Sprintf("%d", self.BackupId)
+}
+
+func (self *SDBInstanceBackup) GetName() string {
+	if len(self.Name) > 0 {
+		return self.Name
+	}
+	return self.GetId()
+}
+
+func (self *SDBInstanceBackup) GetGlobalId() string {
+	return fmt.Sprintf("%s/%d", self.instanceId, self.BackupId)
+}
+
+func (self *SDBInstanceBackup) GetStatus() string {
+	switch self.Status {
+	case "SUCCESS":
+		return api.DBINSTANCE_BACKUP_STATUS_READY
+	case "RUNNING":
+		return api.DBINSTANCE_BACKUP_STATUS_CREATING
+	case "FAILED":
+		return api.DBINSTANCE_BACKUP_STATUS_FAILED
+	default:
+		return api.DBINSTANCE_BACKUP_STATUS_UNKNOWN
+	}
+}
+
+func (self *SDBInstanceBackup) Refresh() error {
+	return nil
+}
+
+func (self *SDBInstanceBackup) IsEmulated() bool {
+	return false
+}
+
+func (self *SDBInstanceBackup) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (self *SDBInstanceBackup) GetDBInstanceId() string {
+	return self.instanceId
+}
+
+func (self *SDBInstanceBackup) GetStartTime() time.Time {
+	if !self.StartTime.IsZero() {
+		return self.StartTime
+	}
+	return self.Date
+}
+
+func (self *SDBInstanceBackup) GetEndTime() time.Time {
+	return self.FinishTime
+}
+
+func (self *SDBInstanceBackup) GetBackupSizeMb() int {
+	return int(self.Size / 1024 / 1024)
+}
+
+func (self *SDBInstanceBackup) GetBackupMode() string {
+	if self.Way == "manual" {
+		return api.DBINSTANCE_BACKUP_MODE_MANUAL
+	}
+	return api.DBINSTANCE_BACKUP_MODE_AUTOMATED
+}
+
+func (self *SRegion) GetDBInstanceBackups(instanceId string) ([]SDBInstanceBackup, error) {
+	backups := make([]SDBInstanceBackup, 0)
+	for {
+		params := map[string]string{
+			"InstanceId": instanceId,
+			"Offset":     fmt.Sprintf("%d", len(backups)),
+			"Limit":      "100",
+		}
+		body, err := self.cdbRequest("DescribeBackups", params)
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]SDBInstanceBackup, 0)
+		err = body.Unmarshal(&parts, "Items")
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, parts...)
+		total, _ := body.Float("TotalCount")
+		if len(backups) >= int(total) || len(parts) == 0 {
+			break
+		}
+	}
+	return backups, nil
+}
@@ -0,0 +1,110 @@
+package qcloud
+
+import (
+	"fmt"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+)
+
+// 腾讯云DNAT规则没有ID, 由协议、公网IP和公网端口唯一确定
+type SDNatEntry struct {
+	gateway *SNatGateway
+
+	IpProtocol       string
+	PublicIpAddress  string
+	PublicPort       int
+	PrivateIpAddress string
+	PrivatePort      int
+	Description      string
+}
+
+func (entry *SDNatEntry) GetId() string {
+	return fmt.Sprintf("%s/%s/%s/%d", entry.gateway.NatGatewayId, entry.GetIpProtocol(), entry.PublicIpAddress, entry.PublicPort)
+}
+
+func (entry *SDNatEntry) GetName() string {
+	if len(entry.Description) > 0 {
+		return entry.Description
+	}
+	return entry.GetId()
+}
+
+func (entry *SDNatEntry) GetGlobalId() string {
+	return entry.GetId()
+}
+
+func (entry *SDNatEntry) GetStatus() string {
+	return api.NAT_STATUS_AVAILABLE
+}
+
+func (entry *SDNatEntry) Refresh() error {
+	err := entry.gateway.Refresh()
+	if err != nil {
+		return err
+	}
+	ientry, err := entry.gateway.GetINatDEntryById(entry.GetGlobalId())
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(entry, ientry)
+}
+
+func (entry *SDNatEntry) IsEmulated() bool {
+	return false
+}
+
+func (entry *SDNatEntry) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+func (entry *SDNatEntry) GetIpProtocol() string {
+	return strings.ToLower(entry.IpProtocol)
+}
+
+func (entry *SDNatEntry) GetExternalIp() string {
+	return entry.PublicIpAddress
+}
+
+func (entry *SDNatEntry) GetExternalPort() int {
+	return entry.PublicPort
+}
+
+func (entry *SDNatEntry) GetInternalIp() string {
+	return entry.PrivateIpAddress
+}
+
+func (entry *SDNatEntry) GetInternalPort() int {
+	return entry.PrivatePort
+}
+
+func (entry *SDNatEntry) Delete() error {
+	return entry.gateway.vpc.region.DeleteDNatEntry(entry.gateway.NatGatewayId, entry)
+}
+
+func (region *SRegion) CreateDNatEntry(natGwId string, entry *SDNatEntry) error {
+	params := make(map[string]string)
+	params["NatGatewayId"] = natGwId
+	params["DestinationIpPortTranslationNatRules.0.IpProtocol"] = strings.ToUpper(entry.IpProtocol)
+	params["DestinationIpPortTranslationNatRules.0.PublicIpAddress"] = entry.PublicIpAddress
+	params["DestinationIpPortTranslationNatRules.0.PublicPort"] = fmt.Sprintf("%d", entry.PublicPort)
+	params["DestinationIpPortTranslationNatRules.0.PrivateIpAddress"] = entry.PrivateIpAddress
+	params["DestinationIpPortTranslationNatRules.0.PrivatePort"] = fmt.Sprintf("%d", entry.PrivatePort)
+	if len(entry.Description) > 0 {
+		params["DestinationIpPortTranslationNatRules.0.Description"] = entry.Description
+	}
+	_, err := region.vpcRequest("CreateNatGatewayDestinationIpPortTranslationNatRule", params)
+	return err
+}
+
+func (region *SRegion) DeleteDNatEntry(natGwId string, entry *SDNatEntry) error {
+	params := make(map[string]string)
+	params["NatGatewayId"] = natGwId
+	params["DestinationIpPortTranslationNatRules.0.IpProtocol"] = strings.ToUpper(entry.IpProtocol)
+	params["DestinationIpPortTranslationNatRules.0.PublicIpAddress"] = entry.PublicIpAddress
+	params["DestinationIpPortTranslationNatRules.0.PublicPort"] = fmt.Sprintf("%d", entry.PublicPort)
+	_, err := region.vpcRequest("DeleteNatGatewayDestinationIpPortTranslationNatRule", params)
+	return err
+}
@@ -0,0 +1,275 @@
+package qcloud
+
+import (
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+
+	api "yunion.io/x/onecloud/pkg/apis/compute"
+	"yunion.io/x/onecloud/pkg/cloudprovider"
+	"yunion.io/x/onecloud/pkg/compute/models"
+)
+
+type SPublicIpAddress struct {
+	AddressId       string
+	PublicIpAddress string
+}
+
+type SNatGateway struct {
+	vpc *SVpc
+
+	NatGatewayId                           string
+	NatGatewayName                         string
+	CreatedTime                            time.Time
+	State                                  string
+	InternetMaxBandwidthOut                int
+	MaxConcurrentConnection                int
+	NetworkState                           string
+	PublicIpAddressSet                     []SPublicIpAddress
+	DestinationIpPortTranslationNatRuleSet []SDNatEntry
+	VpcId                                  string
+	Zone                                   string
+}
+
+func (gateway *SNatGateway) GetId() string {
+	return gateway.NatGatewayId
+}
+
+func (gateway *SNatGateway) GetName() string {
+	if len(gateway.NatGatewayName) > 0 {
+		return gateway.NatGatewayName
+	}
+	return gateway.NatGatewayId
+}
+
+func (gateway *SNatGateway) GetGlobalId() string {
+	return gateway.NatGatewayId
+}
+
+func (gateway *SNatGateway) GetStatus() string {
+	switch gateway.State {
+	case "PENDING":
+		return api.NAT_STATUS_ALLOCATE
+	case "AVAILABLE":
+		return api.NAT_STATUS_AVAILABLE
+	case "UPDATING":
+		return api.NAT_STATUS_DEPLOYING
+	case "DELETING":
+		return api.NAT_STATUS_DELETING
+	case "FAILED":
+		return api.NAT_STATUS_CREATE_FAILED
+	default:
+		return api.NAT_STATUS_UNKNOWN
+	}
+}
+
+func (gateway *SNatGateway) Refresh() error {
+	new, err := gateway.vpc.region.GetNatGateway(gateway.NatGatewayId)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(gateway, new)
+}
+
+func (gateway *SNatGateway) IsEmulated() bool {
+	return false
+}
+
+func (gateway *SNatGateway) GetMetadata() *jsonutils.JSONDict {
+	return nil
+}
+
+// 腾讯云NAT网关只支持按量计费
+func (gateway *SNatGateway) GetBillingType() string {
+	return models.BILLING_TYPE_POSTPAID
+}
+
+func (gateway *SNatGateway) GetExpiredAt() time.Time {
+	return time.Time{}
+}
+
+// 腾讯云NAT网关规格按最大并发连接数区分: 100万, 300万, 1000万
+func (gateway *SNatGateway) GetNatSpec() string {
+	switch gateway.MaxConcurrentConnection {
+	case 1000000:
+		return api.NAT_SPEC_SMALL
+	case 3000000:
+		return api.NAT_SPEC_MIDDLE
+	case 10000000:
+		return api.NAT_SPEC_LARGE
+	}
+	return fmt.Sprintf("%d", gateway.MaxConcurrentConnection)
+}
+
+// 腾讯云NAT网关通过路由表实现SNAT, 没有单独的SNAT规则
+func (gateway *SNatGateway) GetINatSEntries() ([]cloudprovider.ICloudNatSEntry, error) {
+	return []cloudprovider.ICloudNatSEntry{}, nil
+}
+
+func (gateway *SNatGateway) GetINatSEntryById(id string) (cloudprovider.ICloudNatSEntry, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (gateway *SNatGateway) CreateINatSEntry(rule cloudprovider.SNatSRule) (cloudprovider.ICloudNatSEntry, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (gateway *SNatGateway) GetINatDEntries() ([]cloudprovider.ICloudNatDEntry, error) {
+	ientries := make([]cloudprovider.ICloudNatDEntry, len(gateway.DestinationIpPortTranslationNatRuleSet))
+	for i := 0; i < len(gateway.DestinationIpPortTranslationNatRuleSet); i++ {
+		gateway.DestinationIpPortTranslationNatRuleSet[i].gateway = gateway
+		ientries[i] = &gateway.DestinationIpPortTranslationNatRuleSet[i]
+	}
+	return ientries, nil
+}
+
+func (gateway *SNatGateway) GetINatDEntryById(id string) (cloudprovider.ICloudNatDEntry, error) {
+	entries, err := gateway.GetINatDEntries()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(entries); i++ {
+		if entries[i].GetGlobalId() == id {
+			return entries[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (gateway *SNatGateway) CreateINatDEntry(rule cloudprovider.SNatDRule) (cloudprovider.ICloudNatDEntry, error) {
+	entry := &SDNatEntry{
+		gateway:          gateway,
+		IpProtocol:       rule.Protocol,
+		PublicIpAddress:  rule.ExternalIP,
+		PublicPort:       rule.ExternalPort,
+		PrivateIpAddress: rule.InternalIP,
+		PrivatePort:      rule.InternalPort,
+		Description:      rule.Name,
+	}
+	err := gateway.vpc.region.CreateDNatEntry(gateway.NatGatewayId, entry)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (gateway *SNatGateway) Delete() error {
+	return gateway.vpc.region.DeleteNatGateway(gateway.NatGatewayId)
+}
+
+func (region *SRegion) GetNatGateways(vpcId string, natGwId string, offset int, limit int) ([]SNatGateway, int, error) {
+	if limit > 50 || limit <= 0 {
+		limit = 50
+	}
+	params := make(map[string]string)
+	params["Limit"] = fmt.Sprintf("%d", limit)
+	params["Offset"] = fmt.Sprintf("%d", offset)
+	if len(natGwId) > 0 {
+		params["NatGatewayIds.0"] = natGwId
+	}
+	if len(vpcId) > 0 {
+		params["Filters.0.Name"] = "vpc-id"
+		params["Filters.0.Values.0"] = vpcId
+	}
+
+	body, err := region.vpcRequest("DescribeNatGateways", params)
+	if err != nil {
+		log.Errorf("DescribeNatGateways fail %s", err)
+		return nil, 0, err
+	}
+
+	gateways := make([]SNatGateway, 0)
+	err = body.Unmarshal(&gateways, "NatGatewaySet")
+	if err != nil {
+		log.Errorf("Unmarshal NatGatewaySet fail %s", err)
+		return nil, 0, err
+	}
+	total, _ := body.Float("TotalCount")
+	return gateways, int(total), nil
+}
+
+func (region *SRegion) GetNatGateway(natGwId string) (*SNatGateway, error) {
+	gateways, total, err := region.GetNatGateways("", natGwId, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if total != 1 || len(gateways) != 1 {
+		return nil, cloudprovider.ErrNotFound
+	}
+	return &gateways[0], nil
+}
+
+func (self *SVpc) CreateINatGateway(opts *cloudprovider.SNatGatewayCreateOptions) (cloudprovider.ICloudNatGateway, error) {
+	natGwId, err := self.region.CreateNatGateway(self.VpcId, opts)
+	if err != nil {
+		return nil, err
+	}
+	gateway, err := self.region.GetNatGateway(natGwId)
+	if err != nil {
+		return nil, err
+	}
+	gateway.vpc = self
+	return gateway, nil
+}
+
+func (region *SRegion) CreateNatGateway(vpcId string, opts *cloudprovider.SNatGatewayCreateOptions) (string, error) {
+	params := make(map[string]string)
+	params["VpcId"] = vpcId
+	params["NatGatewayName"] = opts.Name
+	// 出带宽上限(Mbps), 使用默认值
+	params["InternetMaxBandwidthOut"] = "100"
+	switch opts.NatSpec {
+	case api.NAT_SPEC_MIDDLE:
+		params["MaxConcurrentConnection"] = "3000000"
+	case api.NAT_SPEC_LARGE, api.NAT_SPEC_XLARGE:
+		params["MaxConcurrentConnection"] = "10000000"
+	default:
+		params["MaxConcurrentConnection"] = "1000000"
+	}
+	// 至少需要一个弹性IP, 未指定时自动申请
+	if len(opts.EipAddr) > 0 {
+		params["PublicIpAddresses.0"] = opts.EipAddr
+	} else {
+		params["AddressCount"] = "1"
+	}
+	body, err := region.vpcRequest("CreateNatGateway", params)
+	if err != nil {
+		return "", err
+	}
+	gateways := make([]SNatGateway, 0)
+	err = body.Unmarshal(&gateways, "NatGatewaySet")
+	if err != nil {
+		return "", err
+	}
+	if len(gateways) != 1 {
+		return "", fmt.Errorf("CreateNatGateway returns %d nat gateways", len(gateways))
+	}
+	return gateways[0].NatGatewayId, nil
+}
+
+func (region *SRegion) DeleteNatGateway(natGwId string) error {
+	params := make(map[string]string)
+	params["NatGatewayId"] = natGwId
+	_, err := region.vpcRequest("DeleteNatGateway", params)
+	return err
+}
+
+func (self *SVpc) getNatGateways() ([]SNatGateway, error) {
+	gateways := make([]SNatGateway, 0)
+	for {
+		parts, total, err := self.region.GetNatGateways(self.VpcId, "", len(gateways), 50)
+		if err != nil {
+			return nil, err
+		}
+		gateways = append(gateways, parts...)
+		if len(gateways) >= total {
+			break
+		}
+	}
+	for i := 0; i < len(gateways); i++ {
+		gateways[i].vpc = self
+	}
+	return gateways, nil
+}
@@ -27,6 +27,7 @@ const (
 	QCLOUD_API_VERSION         = "2017-03-12"
 	QCLOUD_CLB_API_VERSION     = "2018-03-17"
 	QCLOUD_BILLING_API_VERSION = "2018-07-09"
+	QCLOUD_CDB_API_VERSION     = "2017-03-20"
 )
 
 type SQcloudClient struct {
@@ -103,6 +104,12 @@ func wssRequest(client *common.Client, apiName string, params map[string]string,
 	return _phpJsonRequest(client, &wssJsonResponse{}, domain, "/v2/index.php", "", apiName, params, debug)
 }
 
+// 云数据库MySQL服务 api 3.0
+func cdbRequest(client *common.Client, apiName string, params map[string]string, debug bool) (jsonutils.JSONObject, error) {
+	domain := apiDomain("cdb", params)
+	return _jsonRequest(client, domain, QCLOUD_CDB_API_VERSION, apiName, params, debug, true)
+}
+
 func billingRequest(client *common.Client, apiName string, params map[string]string, debug bool) (jsonutils.JSONObject, error) {
 	domain := "billing.tencentcloudapi.com"
 	return _jsonRequest(client, domain, QCLOUD_BILLING_API_VERSION, apiName, params, debug, true)
@@ -343,6 +350,14 @@ func (client *SQcloudClient) wssRequest(apiName string, params map[string]string
 	return wssRequest(cli, apiName, params, client.Debug)
 }
 
+func (client *SQcloudClient) cdbRequest(apiName string, params map[string]string) (jsonutils.JSONObject, error) {
+	cli, err := client.getDefaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return cdbRequest(cli, apiName, params, client.Debug)
+}
+
 func (client *SQcloudClient) billingRequest(apiName string, params map[string]string) (jsonutils.JSONObject, error) {
 	cli, err := client.getDefaultClient()
 	if err != nil {
@@ -0,0 +1,149 @@
+package sysutils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"yunion.io/x/onecloud/pkg/cloudcommon/types"
+	"yunion.io/x/onecloud/pkg/util/fileutils2"
+)
+
+const (
+	NUMA_NODE_PATH = "/sys/devices/system/node"
+)
+
+// ParseCpuList parses the cpu list format of the kernel, e.g. 0-3,8-11
+func ParseCpuList(cpuList string) ([]int, error) {
+	cpus := make([]int, 0)
+	cpuList = strings.TrimSpace(cpuList)
+	if len(cpuList) == 0 {
+		return cpus, nil
+	}
+	for _, seg := range strings.Split(cpuList, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(seg), "-", 2)
+		start, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu list %q: %v", cpuList, err)
+		}
+		end := start
+		if len(bounds) == 2 {
+			end, err = strconv.Atoi(bounds[1])
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid cpu list %q", cpuList)
+			}
+		}
+		for i := start; i <= end; i++ {
+			cpus = append(cpus, i)
+		}
+	}
+	return cpus, nil
+}
+
+// FormatCpuList is the reverse of ParseCpuList
+func FormatCpuList(cpus []int) string {
+	segs := make([]string, 0)
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if j == i {
+			segs = append(segs, strconv.Itoa(cpus[i]))
+		} else {
+			segs = append(segs, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(segs, ",")
+}
+
+// ParseNodeMeminfo returns the values in MB of the node meminfo lines like
+// "Node 0 MemTotal:       32857760 kB"
+func ParseNodeMeminfo(lines []string) map[string]int {
+	ret := make(map[string]int)
+	for _, line := range lines {
+		parts := strings.Fields(line)
+		if len(parts) < 4 || parts[0] != "Node" {
+			continue
+		}
+		val, err := strconv.Atoi(parts[3])
+		if err != nil {
+			continue
+		}
+		if len(parts) > 4 && strings.ToLower(parts[4]) == "kb" {
+			val /= 1024
+		}
+		ret[strings.TrimSuffix(parts[2], ":")] = val
+	}
+	return ret
+}
+
+func readNodeMeminfo(nodeId int) (map[string]int, error) {
+	content, err := fileutils2.FileGetContents(path.Join(NUMA_NODE_PATH, fmt.Sprintf("node%d", nodeId), "meminfo"))
+	if err != nil {
+		return nil, err
+	}
+	return ParseNodeMeminfo(strings.Split(content, "\n")), nil
+}
+
+// DetectNumaNodes reads the numa topology of the host from sysfs, it returns
+// nothing on hosts without numa support
+func DetectNumaNodes() ([]*types.SNumaNode, error) {
+	files, err := ioutil.ReadDir(NUMA_NODE_PATH)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*types.SNumaNode, 0)
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name(), "node") {
+			continue
+		}
+		nodeId, err := strconv.Atoi(strings.TrimPrefix(f.Name(), "node"))
+		if err != nil {
+			continue
+		}
+		cpuList, err := fileutils2.FileGetContents(path.Join(NUMA_NODE_PATH, f.Name(), "cpulist"))
+		if err != nil {
+			return nil, err
+		}
+		cpus, err := ParseCpuList(cpuList)
+		if err != nil {
+			return nil, err
+		}
+		meminfo, err := readNodeMeminfo(nodeId)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, &types.SNumaNode{
+			NodeId:    nodeId,
+			Cpus:      cpus,
+			MemSizeMb: meminfo["MemTotal"],
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeId < nodes[j].NodeId })
+	return nodes, nil
+}
+
+// GetNumaNodeFreeMemMb returns the free memory of a numa node in MB
+func GetNumaNodeFreeMemMb(nodeId int) (int, error) {
+	meminfo, err := readNodeMeminfo(nodeId)
+	if err != nil {
+		return 0, err
+	}
+	return meminfo["MemFree"], nil
+}
+
+// GetNumaNodeFreeHugepages returns the number of free hugepages of the given
+// size on a numa node
+func GetNumaNodeFreeHugepages(nodeId int, pageSizeKb int) (int, error) {
+	content, err := fileutils2.FileGetContents(path.Join(NUMA_NODE_PATH, fmt.Sprintf("node%d", nodeId),
+		"hugepages", fmt.Sprintf("hugepages-%dkB", pageSizeKb), "free_hugepages"))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(content))
+}
@@ -0,0 +1,64 @@
+package sysutils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCpuList(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:  "EmptyInput",
+			input: "\n",
+			want:  []int{},
+		},
+		{
+			name:  "NormalInput",
+			input: "0-3,8-9,12\n",
+			want:  []int{0, 1, 2, 3, 8, 9, 12},
+		},
+		{
+			name:    "InvalidInput",
+			input:   "3-1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCpuList(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCpuList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCpuList() = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && len(got) > 0 {
+				if back, _ := ParseCpuList(FormatCpuList(got)); !reflect.DeepEqual(back, got) {
+					t.Errorf("FormatCpuList() = %s", FormatCpuList(got))
+				}
+			}
+		})
+	}
+}
+
+func TestParseNodeMeminfo(t *testing.T) {
+	lines := []string{
+		"Node 1 MemTotal:       32857760 kB",
+		"Node 1 MemFree:         2097152 kB",
+		"Node 1 HugePages_Total:     512",
+		"",
+	}
+	want := map[string]int{
+		"MemTotal":        32087,
+		"MemFree":         2048,
+		"HugePages_Total": 512,
+	}
+	if got := ParseNodeMeminfo(lines); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseNodeMeminfo() = %v, want %v", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package sysutils
 
 import (
+	"net"
 	"reflect"
 	"testing"
 
@@ -54,7 +55,7 @@ func TestParseDMISysinfo(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    args
-		want    *types.DMIInfo
+		want    *types.SDMISystemInfo
 		wantErr bool
 	}{
 		{
@@ -75,7 +76,7 @@ func TestParseDMISysinfo(t *testing.T) {
 				"        UUID: bca177cc-2bce-11b2-a85c-e98996f19d2f",
 				"        SKU Number: LENOVO_MT_20J6_BU_Think_FM_ThinkPad T470p",
 			}},
-			want: &types.DMIInfo{
+			want: &types.SDMISystemInfo{
 				Manufacture: "LENOVO",
 				Model:       "20J6CTO1WW",
 				Version:     "ThinkPad T470p",
@@ -90,7 +91,7 @@ func TestParseDMISysinfo(t *testing.T) {
 				"        Version: None",
 				"        Serial Number: PF112JKK",
 			}},
-			want: &types.DMIInfo{
+			want: &types.SDMISystemInfo{
 				Model:   "20J6CTO1WW",
 				Version: "",
 				SN:      "PF112JKK",
@@ -119,7 +120,7 @@ func TestParseCPUInfo(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    args
-		want    *types.CPUInfo
+		want    *types.SCPUInfo
 		wantErr bool
 	}{
 		{
@@ -131,7 +132,7 @@ func TestParseCPUInfo(t *testing.T) {
 				"processor       : 1",
 				"cache size      : 16384 KB",
 			}},
-			want: &types.CPUInfo{
+			want: &types.SCPUInfo{
 				Model: "Intel(R) Xeon(R) CPU E5-2680 v2 @ 2.80GHz",
 				Count: 2,
 				Freq:  2793,
@@ -161,14 +162,14 @@ func TestParseDMICPUInfo(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want *types.DMICPUInfo
+		want *types.SDMICPUInfo
 	}{
 		{
 			name: "NormalInput",
 			args: args{
 				lines: []string{"Processor Information"},
 			},
-			want: &types.DMICPUInfo{Nodes: 1},
+			want: &types.SDMICPUInfo{Nodes: 1},
 		},
 	}
 	for _, tt := range tests {
@@ -187,7 +188,7 @@ func TestParseDMIMemInfo(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want *types.DMIMemInfo
+		want *types.SDMIMemInfo
 	}{
 		{
 			name: "NormalInputMB",
@@ -196,7 +197,7 @@ func TestParseDMIMemInfo(t *testing.T) {
 					"        Size: 16384 MB",
 					"        Size: No Module Installed"},
 			},
-			want: &types.DMIMemInfo{Total: 16384},
+			want: &types.SDMIMemInfo{Total: 16384},
 		},
 		{
 			name: "NormalInputGB",
@@ -205,7 +206,7 @@ func TestParseDMIMemInfo(t *testing.T) {
 					"        Size: 16 GB",
 					"        Size: No Module Installed"},
 			},
-			want: &types.DMIMemInfo{Total: 16 * 1024},
+			want: &types.SDMIMemInfo{Total: 16 * 1024},
 		},
 	}
 	for _, tt := range tests {
@@ -217,6 +218,14 @@ func TestParseDMIMemInfo(t *testing.T) {
 	}
 }
 
+func mustParseMac(s string) net.HardwareAddr {
+	mac, err := net.ParseMAC(s)
+	if err != nil {
+		panic(err)
+	}
+	return mac
+}
+
 func TestParseNicInfo(t *testing.T) {
 	type args struct {
 		lines []string
@@ -224,7 +233,7 @@ func TestParseNicInfo(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want []*types.NicDevInfo
+		want []*types.SNicDevInfo
 	}{
 		{
 			name: "NormalInput",
@@ -234,9 +243,9 @@ func TestParseNicInfo(t *testing.T) {
 					"eth1 00:22:25:0b:ab:50 0 0 1500",
 				},
 			},
-			want: []*types.NicDevInfo{
-				{Dev: "eth0", Mac: "00:22:25:0b:ab:49", Speed: 0, Up: true, Mtu: 1500},
-				{Dev: "eth1", Mac: "00:22:25:0b:ab:50", Speed: 0, Up: false, Mtu: 1500},
+			want: []*types.SNicDevInfo{
+				{Dev: "eth0", Mac: mustParseMac("00:22:25:0b:ab:49"), Speed: 0, Up: true, Mtu: 1500},
+				{Dev: "eth1", Mac: mustParseMac("00:22:25:0b:ab:50"), Speed: 0, Up: false, Mtu: 1500},
 			},
 		},
 	}